@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// estimate prints the emissions of an instance type, calculated with the
+// emission factors only: it needs neither cloud credentials nor a running
+// exporter
+//
+//	aether estimate --provider aws --type m5.xlarge --region eu-west-1 --utilization 40 --hours 720
+func estimate(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("estimate", flag.ContinueOnError)
+	provider := fs.String("provider", "", "the cloud provider: aws, gcp or azure")
+	kind := fs.String("type", "", "the instance type, e.g. m5.xlarge")
+	region := fs.String("region", "", "the region of the instance")
+	utilization := fs.Float64("utilization", 50, "the CPU utilization in percent")
+	hours := fs.Float64("hours", 1, "how many hours the instance runs")
+	dataPath := fs.String("factors", "", "a local copy of the emission factors (the data/v1 directory of the emissions-data repo), pulled when empty")
+	output := fs.String("output", "text", "the output format: text or json")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *provider == "" || *kind == "" || *region == "" {
+		return errors.New("--provider, --type and --region are required")
+	}
+	if *hours <= 0 {
+		return errors.New("--hours must be positive")
+	}
+
+	if *dataPath != "" {
+		factors.DataPath = *dataPath
+	} else if _, err := calculator.LoadFactors(ctx); err != nil {
+		return fmt.Errorf("failed pulling the emission factors: %w", err)
+	}
+
+	e, err := calculator.EstimateEmissions(ctx, &calculator.EstimateRequest{
+		Provider:    v1.Provider(*provider),
+		Kind:        *kind,
+		Region:      *region,
+		Utilization: *utilization,
+		Duration:    time.Duration(*hours * float64(time.Hour)),
+	})
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	case "text":
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Instance:\t%s %s in %s\n", e.Provider, e.Kind, e.Region)
+		fmt.Fprintf(w, "Usage:\t%g%% CPU over %g hours\n", e.Utilization, *hours)
+		fmt.Fprintf(w, "Factors:\t%g vCPU, %g PUE, %g gCO2eq/kWh\n", e.VCPU, e.PUE, e.GridCO2e)
+		fmt.Fprintf(w, "Operational:\t%.2f gCO2eq\n", e.Operational)
+		fmt.Fprintf(w, "Embodied:\t%.2f gCO2eq\n", e.Embodied)
+		fmt.Fprintf(w, "Total:\t%.2f gCO2eq\n", e.Total)
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown output %q", *output)
+	}
+}
+
+// runEstimate runs the estimate subcommand and returns its exit code
+func runEstimate(ctx context.Context, args []string) int {
+	return runCommand(ctx, func(ctx context.Context) error {
+		return estimate(ctx, args, os.Stdout)
+	})
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// factorsDiff prints the emission factors that changed between two versions
+// of the emissions data repo, and their expected impact on the stored
+// emissions, so that upgrading the factors can be reviewed beforehand. The
+// versions are tags or branches of the repo, or local directories
+//
+//	aether factors diff --store /var/lib/aether/emissions.jsonl v1.4.0 v1.5.0
+func factorsDiff(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("factors diff", flag.ContinueOnError)
+	path := fs.String("store", "", "the store file the impact is estimated with, skipped when empty")
+	window := fs.String("window", "7d", "how far back the stored emissions are considered")
+	output := fs.String("output", "text", "the output format: text or json")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return errors.New("expected the two versions to compare, e.g. v1.4.0 v1.5.0")
+	}
+
+	switch *output {
+	case "text", "json":
+	default:
+		return fmt.Errorf("unknown output %q", *output)
+	}
+
+	d, err := store.ParseDuration(*window)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp("", "aether-factors-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	paths := make([]string, 2)
+	for i, version := range fs.Args() {
+		paths[i], err = factorsVersion(version, filepath.Join(tmp, strconv.Itoa(i)))
+		if err != nil {
+			return err
+		}
+	}
+
+	changes, err := calculator.DiffFactors(paths[0], paths[1])
+	if err != nil {
+		return err
+	}
+
+	var impact *calculator.FactorsImpact
+	if *path != "" {
+		s, err := store.Open(ctx, &config.StoreConfig{Path: *path})
+		if err != nil {
+			return err
+		}
+
+		to := time.Now()
+		i := calculator.EstimateImpact(changes, s.Select(to.Add(-d), to, nil))
+		impact = &i
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Changes []calculator.FactorChange `json:"changes"`
+			Impact  *calculator.FactorsImpact `json:"impact,omitempty"`
+		}{changes, impact})
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tFACTOR\tKEY\tOLD\tNEW\tCHANGE\t")
+	for _, c := range changes {
+		change := c.Status
+		if c.Status == calculator.FactorChanged && c.Old != 0 {
+			change = fmt.Sprintf("%+.1f%%", (c.New/c.Old-1)*100)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%g\t%g\t%s\t\n", c.Provider, c.Factor, c.Key, c.Old, c.New, change)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "the emission factors are the same")
+	}
+
+	if impact != nil {
+		fmt.Fprintf(out, "\nImpact on the emissions of the last %s:\n", *window)
+		fmt.Fprintf(out, "  %d instances affected, %d no longer supported\n", impact.Instances, impact.Unsupported)
+		fmt.Fprintf(out, "  %.2f gCO2eq as calculated, %.2f gCO2eq with the new factors", impact.Old, impact.New)
+		if impact.Old != 0 {
+			fmt.Fprintf(out, " (%+.1f%%)", (impact.New/impact.Old-1)*100)
+		}
+		fmt.Fprintln(out)
+	}
+
+	return nil
+}
+
+// factorsExportMissing prints the entries of the instance kinds a running
+// exporter is missing from the emission factors, to be completed and
+// contributed to the emissions data repo
+//
+//	aether factors export-missing --url http://localhost:8080 > missing.yaml
+func factorsExportMissing(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("factors export-missing", flag.ContinueOnError)
+	addr := fs.String("url", "http://127.0.0.1:8080", "the address of the exporter API")
+	token := fs.String("token", os.Getenv("AETHER_TOKEN"), "the bearer token of the API, defaults to $AETHER_TOKEN")
+	provider := fs.String("provider", "", "only export the kinds of this provider")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(*addr, "/")+"/api/v1/datasets/misses", http.NoBody)
+	if err != nil {
+		return err
+	}
+	if *token != "" {
+		req.Header.Set("Authorization", "Bearer "+*token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the exporter returned %s", resp.Status)
+	}
+
+	var body struct {
+		Misses []calculator.Miss `json:"misses"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+
+	misses := body.Misses[:0]
+	for _, m := range body.Misses {
+		if *provider == "" || m.Provider.String() == *provider {
+			misses = append(misses, m)
+		}
+	}
+
+	return calculator.WriteEmbodiedStubs(out, misses)
+}
+
+// factorsVersion returns the directory of the emission factors of a version,
+// they are checked out to dir unless the version is a local directory
+func factorsVersion(version, dir string) (string, error) {
+	if fi, err := os.Stat(version); err == nil && fi.IsDir() {
+		return version, nil
+	}
+
+	if err := factors.CloneAndUpdateFactorsData(); err != nil {
+		return "", fmt.Errorf("failed pulling the emission factors: %w", err)
+	}
+
+	if err := factors.CheckoutFactorsVersion(version, dir); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// runFactors runs the factors subcommands and returns their exit code
+func runFactors(ctx context.Context, args []string) int {
+	var run func(ctx context.Context, args []string, out io.Writer) error
+	switch {
+	case len(args) > 0 && args[0] == "diff":
+		run = factorsDiff
+	case len(args) > 0 && args[0] == "export-missing":
+		run = factorsExportMissing
+	default:
+		fmt.Fprintln(os.Stderr, "usage: aether factors diff [flags] <old version> <new version>")
+		fmt.Fprintln(os.Stderr, "       aether factors export-missing [flags]")
+		return 1
+	}
+
+	return runCommand(ctx, func(ctx context.Context) error {
+		return run(ctx, args[1:], os.Stdout)
+	})
+}
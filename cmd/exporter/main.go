@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -12,13 +14,35 @@ import (
 	"log/slog"
 
 	"github.com/re-cinq/aether/pkg/api"
+	"github.com/re-cinq/aether/pkg/attribution"
 	"github.com/re-cinq/aether/pkg/bus"
 	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/cardinality"
 	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/cost"
+	"github.com/re-cinq/aether/pkg/dedup"
 	"github.com/re-cinq/aether/pkg/exporter"
+	"github.com/re-cinq/aether/pkg/grouping"
 	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/notify"
+	"github.com/re-cinq/aether/pkg/operator"
+	amazon "github.com/re-cinq/aether/pkg/providers/aws"
+	"github.com/re-cinq/aether/pkg/providers/digitalocean"
+	"github.com/re-cinq/aether/pkg/providers/gcp"
+	"github.com/re-cinq/aether/pkg/providers/plugin"
+	"github.com/re-cinq/aether/pkg/providers/scaleway"
+	"github.com/re-cinq/aether/pkg/replay"
+	"github.com/re-cinq/aether/pkg/report"
+	"github.com/re-cinq/aether/pkg/rightsizing"
+	"github.com/re-cinq/aether/pkg/saas"
+	"github.com/re-cinq/aether/pkg/scheduling"
+	"github.com/re-cinq/aether/pkg/sci"
 	"github.com/re-cinq/aether/pkg/scraper"
+	"github.com/re-cinq/aether/pkg/statement"
+	"github.com/re-cinq/aether/pkg/store"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/re-cinq/aether/pkg/units"
 )
 
 const shutdownTTL = time.Second * 15
@@ -67,43 +91,414 @@ func main() {
 		return
 	}
 
-	// At this point load the config
+	// Estimate the emissions of an instance type and exit
+	if len(args) > 1 && args[1] == "estimate" {
+		os.Exit(runEstimate(ctx, args[2:]))
+	}
+
+	// Render the top emitters of a running exporter and exit
+	if len(args) > 1 && args[1] == "top" {
+		os.Exit(runTop(ctx, args[2:]))
+	}
+
+	// Report on the stored emissions of a date range and exit
+	if len(args) > 1 && args[1] == "report" {
+		os.Exit(runReport(ctx, args[2:]))
+	}
+
+	// Compare two versions of the emission factors, or export the kinds missing
+	// from them, and exit
+	if len(args) > 1 && args[1] == "factors" {
+		os.Exit(runFactors(ctx, args[2:]))
+	}
+
+	// Estimate the emissions of a Terraform plan and exit
+	if len(args) > 1 && args[1] == "terraform" {
+		os.Exit(runTerraform(ctx, args[2:]))
+	}
+
+	// Verify a signed emissions statement and exit
+	if len(args) > 1 && args[1] == "statement" {
+		os.Exit(runStatement(ctx, args[2:]))
+	}
+
+	// Calculate the emissions of a recording and exit
+	if len(args) > 1 && args[1] == "replay" {
+		os.Exit(runReplay(ctx, args[2:]))
+	}
+
+	// Write the policies granting the permissions the config needs and exit
+	if len(args) > 1 && args[1] == "permissions" {
+		os.Exit(runPermissions(ctx, args[2:]))
+	}
+
+	// At this point load the config, the components are given the config
+	// they need when they're created
 	config.InitConfig(ctx)
+	cfg := config.AppConfig()
+
+	setLogLevel(lvl, cfg.LogLevel)
 
-	setLogLevel(lvl, config.AppConfig().LogLevel)
+	// The unit and precision of the emissions of the metrics and the API
+	metricsConfig, apiConfig := cfg.Units.MetricsOutput(), cfg.Units.APIOutput()
+	metricsOutput, err := units.New(&metricsConfig)
+	if err != nil {
+		logger.Error("invalid units of the metrics", "error", err)
+		os.Exit(1)
+	}
+	// The labels, the amount of the series and the mode of the metrics
+	instancesLimiter, err := cardinality.New("instances", &cfg.Exporters.Instances, exporter.FixedLabels()...)
+	if err != nil {
+		logger.Error("invalid exporters", "error", err)
+		os.Exit(1)
+	}
+	podsLimiter, err := cardinality.New("pods", &cfg.Exporters.Pods, "unit")
+	if err != nil {
+		logger.Error("invalid exporters", "error", err)
+		os.Exit(1)
+	}
+	exportMode, err := exporter.ParseMode(cfg.Exporters.Mode)
+	if err != nil {
+		logger.Error("invalid exporters", "error", err)
+		os.Exit(1)
+	}
+	staleness := cfg.Exporters.Staleness
+	if staleness == 0 {
+		staleness = 3 * cfg.ProvidersConfig.Interval
+	}
+
+	// The accounts are scraped every interval or when the metrics are
+	switch cfg.ProvidersConfig.Mode {
+	case "", scraper.ModeSchedule, scraper.ModePull:
+	default:
+		logger.Error("invalid providers config", "error", fmt.Errorf("invalid mode %q, expected schedule or pull", cfg.ProvidersConfig.Mode))
+		os.Exit(1)
+	}
+
+	apiOutput, err := units.New(&apiConfig)
+	if err != nil {
+		logger.Error("invalid units of the API", "error", err)
+		os.Exit(1)
+	}
+
+	// The rules grouping the instances by application and team
+	groups, err := grouping.New(&cfg.Grouping)
+	if err != nil {
+		logger.Error("invalid grouping rules", "error", err)
+		os.Exit(1)
+	}
 
 	// Init the application bus
 	b := bus.New()
 
+	// The repo the emission factors are pulled from
+	factors.SetRepoURL(cfg.Factors.Repository)
+
+	calcOptions := []calculator.HandlerOption{
+		calculator.WithInterval(cfg.ProvidersConfig.Interval),
+		calculator.WithStaleness(staleness),
+		// The on-premises sites, e.g. the AWS Outposts, and the custom regions
+		calculator.WithLocations(calculator.NewLocations(cfg)),
+		calculator.WithMissesWebhook(cfg.Misses.Webhook),
+		calculator.WithGrouping(groups),
+	}
+
+	// Count the instances reported by several collectors once
+	if cfg.Deduplication.Enabled {
+		calcOptions = append(calcOptions, calculator.WithDeduplication(
+			dedup.New(&cfg.Deduplication, cfg.ProvidersConfig.Interval),
+		))
+	}
+
+	calc := calculator.NewHandler(ctx, b, calcOptions...)
+	config.OnChange(calc.Configure)
+
+	// Record the collected instances before their emissions are calculated
+	if path := cfg.Recording.Record; path != "" {
+		recorder, err := replay.NewRecorder(ctx, path, cfg.ProvidersConfig.Interval, calc)
+		if err != nil {
+			logger.Error("failed opening the recording", "error", err)
+			os.Exit(1)
+		}
+		b.Subscribe(
+			v1.MetricsCollectedEvent,
+			recorder,
+		)
+	}
+
 	// Subscribe to the metrics collections
 	b.Subscribe(
 		v1.MetricsCollectedEvent,
-		calculator.NewHandler(ctx, b),
+		calc,
+	)
+
+	// Sum up the emissions of the accounts once their collection completed
+	b.Subscribe(
+		v1.CollectionCompletedEvent,
+		calc,
 	)
 
 	// Subscribe to update the prometheus exporter
 	b.Subscribe(
 		v1.EmissionsCalculatedEvent,
-		exporter.NewHandler(ctx, b),
+		exporter.NewHandler(ctx, b, metricsOutput, instancesLimiter, exportMode, staleness),
 	)
 
+	// Store the calculated emissions for querying
+	st, err := store.New(ctx, &cfg.Store, store.WithInterval(cfg.ProvidersConfig.Interval))
+	if err != nil {
+		logger.Error("failed loading the store", "error", err)
+		os.Exit(1)
+	}
+	b.Subscribe(
+		v1.EmissionsCalculatedEvent,
+		st,
+	)
+
+	// The emission factors of the providers missing from the emissions-data
+	// repo, e.g. OCI
+	for provider, p := range cfg.Providers {
+		if p.Factors != "" {
+			factors.RegisterDataPath(provider, p.Factors)
+		}
+	}
+
+	// The ones of DigitalOcean and Scaleway are embedded, unless overridden
+	embedded := []struct {
+		provider v1.Provider
+		name     string
+		write    func(dir string) error
+	}{
+		{provider: v1.DigitalOcean, name: "DigitalOcean", write: digitalocean.WriteFactors},
+		{provider: v1.Scaleway, name: "Scaleway", write: scaleway.WriteFactors},
+	}
+	for _, e := range embedded {
+		p, ok := cfg.Providers[e.provider]
+		if !ok || p.Factors != "" {
+			continue
+		}
+
+		dir, err := os.MkdirTemp("", "aether-"+e.provider.String()+"-factors")
+		if err == nil {
+			err = e.write(dir)
+		}
+		if err != nil {
+			logger.Error("failed writing the "+e.name+" emission factors", "error", err)
+			os.Exit(1)
+		}
+		factors.RegisterDataPath(e.provider, dir)
+	}
+
+	// Scrape the providers implemented by plugins
+	for i := range cfg.Plugins {
+		provider, factory, err := plugin.Register(&cfg.Plugins[i])
+		if err != nil {
+			logger.Error("failed registering the plugin", "error", err)
+			os.Exit(1)
+		}
+		scraper.RegisterFactory(provider, factory)
+	}
+
+	// Scheduler manager
+	scrape := scraper.NewManager(ctx, b, cfg)
+
+	apiOptions := []api.Option{
+		api.WithStatus(scrape),
+		api.WithStore(st),
+		api.WithCalculations(calc),
+		api.WithScrapeController(scrape),
+		api.WithFactorsRefresher(calc),
+		api.WithImports(st),
+		api.WithUnits(apiOutput),
+	}
+
+	// Scrape the accounts when Prometheus scrapes the metrics
+	if cfg.ProvidersConfig.Mode == scraper.ModePull {
+		apiOptions = append(apiOptions, api.WithPull(scrape))
+	}
+
+	// Sign the emissions statements
+	if statements := cfg.APIConfig.Statements; statements.SigningKey != "" {
+		key, err := statement.LoadPrivateKey(statements.SigningKey)
+		if err != nil {
+			logger.Error("failed loading the statements signing key", "error", err)
+			os.Exit(1)
+		}
+		apiOptions = append(apiOptions, api.WithStatements(key, statements.Issuer, version))
+	}
+
+	// Estimate the savings of shifting the workloads to the greener hours
+	if path := cfg.Shifting.Profiles; path != "" {
+		profiles, err := report.LoadProfiles(path)
+		if err != nil {
+			logger.Error("failed loading the grid intensity profiles", "error", err)
+			os.Exit(1)
+		}
+		apiOptions = append(apiOptions, api.WithShiftingProfiles(profiles))
+	}
+
+	// Join the emissions with the spend of the instances
+	var costs *cost.Collector
+	if cfg.Costs.Enabled {
+		costs, err = cost.New(ctx, &cfg.Costs, st)
+		if err != nil {
+			logger.Error("failed setting up the costs", "error", err)
+			os.Exit(1)
+		}
+		costs.Start(ctx)
+		apiOptions = append(apiOptions, api.WithCosts(costs))
+	}
+
+	// Estimate the carbon savings of the rightsizing recommendations of the
+	// providers
+	var recommendations *rightsizing.Collector
+	if cfg.Rightsizing.Enabled {
+		var sources []rightsizing.Source
+		for i := range cfg.Providers[v1.AWS].Accounts {
+			src, err := amazon.NewRecommender(ctx, &cfg.Providers[v1.AWS].Accounts[i])
+			if err != nil {
+				logger.Error("failed setting up the rightsizing recommendations", "error", err)
+				os.Exit(1)
+			}
+			sources = append(sources, src)
+		}
+		for i := range cfg.Providers[v1.GCP].Accounts {
+			src, err := gcp.NewRecommender(ctx, &cfg.Providers[v1.GCP].Accounts[i])
+			if err != nil {
+				logger.Error("failed setting up the rightsizing recommendations", "error", err)
+				os.Exit(1)
+			}
+			sources = append(sources, src)
+		}
+
+		recommendations = rightsizing.New(ctx, &cfg.Rightsizing, sources, calc.EstimateEmissions)
+		recommendations.Start(ctx)
+		apiOptions = append(apiOptions, api.WithRightsizing(recommendations))
+	}
+
+	// Divide the emissions of the applications by their functional unit
+	var intensity *sci.Collector
+	if len(cfg.FunctionalUnits.Units) > 0 {
+		intensity, err = sci.New(ctx, &cfg.FunctionalUnits, st)
+		if err != nil {
+			logger.Error("invalid functional units", "error", err)
+			os.Exit(1)
+		}
+		intensity.Start(ctx)
+	}
+
+	// Store the emissions of the usage of the SaaS vendors
+	var vendors *saas.Collector
+	if len(cfg.SaaS.Connectors) > 0 {
+		vendors, err = saas.New(ctx, &cfg.SaaS, st, calc.GridIntensity)
+		if err != nil {
+			logger.Error("invalid saas connectors", "error", err)
+			os.Exit(1)
+		}
+		vendors.Start(ctx)
+	}
+
+	// Render the executive summaries at the end of their periods
+	var summaries *report.Scheduler
+	if len(cfg.Reports.Schedules) > 0 {
+		summaries, err = report.NewScheduler(ctx, &cfg.Reports, st)
+		if err != nil {
+			logger.Error("invalid reports", "error", err)
+			os.Exit(1)
+		}
+		summaries.Start(ctx)
+	}
+
+	// Post the budget breaches, the anomalies and the weekly summaries, and
+	// the alerts to Alertmanager
+	var notifier *notify.Notifier
+	if len(cfg.Notifications.Channels) > 0 || len(cfg.Notifications.Alertmanager.URLs) > 0 {
+		notifier, err = notify.New(ctx, &cfg.Notifications, st, scrape)
+		if err != nil {
+			logger.Error("invalid notifications", "error", err)
+			os.Exit(1)
+		}
+		notifier.Start(ctx)
+	}
+
+	// Attribute the emissions of the Kubernetes nodes to their pods
+	if cfg.Attribution.Enabled {
+		agent, err := attribution.New(ctx, &cfg.Attribution, b, cfg.ProvidersConfig.Interval, metricsOutput, podsLimiter)
+		if err != nil {
+			logger.Error("failed starting the attribution agent", "error", err)
+			os.Exit(1)
+		}
+		b.Subscribe(
+			v1.EmissionsCalculatedEvent,
+			agent,
+		)
+		agent.Start(ctx)
+		apiOptions = append(apiOptions, api.WithPods(agent))
+	}
+
 	// Start the bus
 	b.Start(ctx)
 	logger.Info("bus started")
 
 	// Create the API object
-	server := api.New()
+	server := api.New(&cfg.APIConfig, apiOptions...)
+
+	// Publish a recording instead of scraping the providers
+	var player *replay.Player
+	if path := cfg.Recording.Replay; path != "" {
+		records, err := replay.Load(ctx, path)
+		if err != nil {
+			logger.Error("failed loading the recording", "error", err)
+			os.Exit(1)
+		}
+		player, err = replay.NewPlayer(ctx, b, records, cfg.ProvidersConfig.Interval)
+		if err != nil {
+			logger.Error("failed replaying the recording", "error", err)
+			os.Exit(1)
+		}
+		player.Start(ctx)
+		logger.Info("replaying the recording", "path", path)
+	} else {
+		// Start the scheduler manager and keep the scrapers in sync with the
+		// config file
+		scrape.Start(ctx)
+		config.OnChange(scrape.Reload)
+		logger.Info("scrapers started")
+	}
 
-	// Scheduler manager
-	scrape := scraper.NewManager(ctx, b)
+	// Reconcile the CarbonPolicy resources
+	var op *operator.Operator
+	if cfg.Operator.Enabled {
+		op, err = operator.New(ctx, &cfg.Operator, scrape, st)
+		if err != nil {
+			logger.Error("failed starting the operator", "error", err)
+			os.Exit(1)
+		}
+		op.Start(ctx)
+		logger.Info("operator started")
+	}
 
-	// Start the scheduler manager
-	scrape.Start(ctx)
-	logger.Info("scrapers started")
+	// Label the nodes with the grid intensity of their region
+	var labeler *scheduling.NodeLabeler
+	if cfg.NodeLabels.Enabled {
+		labeler, err = scheduling.NewNodeLabeler(ctx, &cfg.NodeLabels, calc.GridIntensity)
+		if err != nil {
+			logger.Error("failed starting the node labeler", "error", err)
+			os.Exit(1)
+		}
+		labeler.Start(ctx)
+	}
 
 	// Start the API
 	go server.Start(ctx)
 
+	// Start the profiling server
+	var debug *api.Debug
+	if cfg.APIConfig.Debug.Enabled {
+		debug = api.NewDebug(&cfg.APIConfig.Debug)
+		go debug.Start(ctx)
+	}
+
 	// Print the start
 	logger.Info("started", "time", time.Since(start))
 
@@ -119,6 +514,47 @@ func main() {
 		// Shutdown the API server
 		server.Stop(cancelCtx)
 
+		if debug != nil {
+			debug.Stop(cancelCtx)
+		}
+
+		if labeler != nil {
+			labeler.Stop(cancelCtx)
+		}
+
+		if costs != nil {
+			costs.Stop(cancelCtx)
+		}
+
+		if recommendations != nil {
+			recommendations.Stop(cancelCtx)
+		}
+
+		if intensity != nil {
+			intensity.Stop(cancelCtx)
+		}
+
+		if vendors != nil {
+			vendors.Stop(cancelCtx)
+		}
+
+		if summaries != nil {
+			summaries.Stop(cancelCtx)
+		}
+
+		if notifier != nil {
+			notifier.Stop(cancelCtx)
+		}
+
+		// Stop reconciling the policies before the scrapers are stopped
+		if op != nil {
+			op.Stop(cancelCtx)
+		}
+
+		if player != nil {
+			player.Stop(cancelCtx)
+		}
+
 		// Stop all the scraping
 		scrape.Stop(ctx)
 
@@ -175,3 +611,17 @@ func setLogLevel(lvl *slog.LevelVar, level string) {
 		lvl.Set(slog.LevelInfo)
 	}
 }
+
+// runCommand runs a subcommand and returns its exit code. The output is kept
+// clean, the warnings and the errors go to stderr
+func runCommand(ctx context.Context, run func(ctx context.Context) error) int {
+	ctx = log.WithContext(ctx, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	if err := run(ctx); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/permissions"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// writePermissions writes the least-privilege policies the features enabled
+// in the config need. With --provider, the document of the provider alone is
+// written, ready to be applied with the provider CLI, otherwise the ones of
+// every configured provider keyed by provider
+//
+//	aether permissions --provider aws > policy.json
+//	aether permissions --provider oci --group carbon-exporters
+func writePermissions(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("permissions", flag.ContinueOnError)
+	providerFlag := fs.String("provider", "", "the provider the policy is written for: aws, gcp, azure, oci, digitalocean or scaleway. All the configured ones when empty")
+	group := fs.String("group", permissions.DefaultGroup, "the OCI group granted the policy statements")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	config.InitConfig(ctx)
+	cfg := config.AppConfig()
+	opts := permissions.Options{Group: *group}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	if *providerFlag != "" {
+		provider, ok := v1.Providers[*providerFlag]
+		if !ok {
+			return fmt.Errorf("unknown provider %q", *providerFlag)
+		}
+
+		doc, err := permissions.Document(cfg, provider, opts)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(doc)
+	}
+
+	providers := permissions.Providers(cfg)
+	if len(providers) == 0 {
+		return errors.New("no account of aws, gcp, azure, oci, digitalocean or scaleway is configured")
+	}
+
+	docs := make(map[v1.Provider]any, len(providers))
+	for _, provider := range providers {
+		doc, err := permissions.Document(cfg, provider, opts)
+		if err != nil {
+			return err
+		}
+		docs[provider] = doc
+	}
+	return enc.Encode(docs)
+}
+
+// runPermissions runs the permissions subcommand and returns its exit code
+func runPermissions(ctx context.Context, args []string) int {
+	return runCommand(ctx, func(ctx context.Context) error {
+		return writePermissions(ctx, args, os.Stdout)
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/replay"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// replayRecording prints the emissions of the instances of a recording,
+// calculated again the way the exporter does, so that a calculation can be
+// reproduced without the cloud credentials
+//
+//	aether replay --factors v1.4.0 recording.jsonl
+//	aether replay s3://bucket/recordings
+func replayRecording(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	version := fs.String("factors", "", "the emission factors: a version of the emissions data repo or a local directory, the latest when empty")
+	interval := fs.Duration("interval", 0, "the interval the emissions are calculated over, the recorded one when zero")
+	output := fs.String("output", "text", "the output format: text or json")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("expected the recording to replay")
+	}
+
+	switch *output {
+	case "text", "json":
+	default:
+		return fmt.Errorf("unknown output %q", *output)
+	}
+
+	records, err := replay.Load(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *version != "" {
+		tmp, err := os.MkdirTemp("", "aether-factors-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+
+		if factors.DataPath, err = factorsVersion(*version, tmp); err != nil {
+			return err
+		}
+	} else if _, err := calculator.LoadFactors(ctx); err != nil {
+		return fmt.Errorf("failed pulling the emission factors: %w", err)
+	}
+
+	results := replay.Calculate(ctx, records, *interval)
+
+	if *output == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	var operational, embodied float64
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tPROVIDER\tNAME\tREGION\tKIND\tOPERATIONAL\tEMBODIED\tERROR\t")
+	for _, r := range results {
+		s := r.Sample
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.4f\t%.4f\t%s\t\n",
+			s.Time.Format(time.RFC3339), s.Provider, s.Name, s.Region, s.Kind, s.Operational, s.Embodied, r.Error)
+		operational += s.Operational
+		embodied += s.Embodied
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n%d instances: %.2f gCO2eq operational, %.2f gCO2eq embodied\n", len(results), operational, embodied)
+	return nil
+}
+
+// runReplay runs the replay subcommand and returns its exit code
+func runReplay(ctx context.Context, args []string) int {
+	return runCommand(ctx, func(ctx context.Context) error {
+		return replayRecording(ctx, args, os.Stdout)
+	})
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/report"
+	"github.com/re-cinq/aether/pkg/store"
+)
+
+// writeReport writes the emissions of a date range, grouped by a label, read from
+// the store persisted by the exporter. With --shifting, it writes the
+// instances whose load could be shifted to the greenest hours instead, and
+// with --executive the branded summary of the range for the leadership
+//
+//	aether report --from 2024-01-01 --to 2024-01-31 --group-by team --format csv
+//	aether report --from 2024-01-01 --to 2024-01-31 --shifting --profiles intensity.csv
+//	aether report --from 2024-01-01 --to 2024-01-31 --executive --format pdf --output summary.pdf
+func writeReport(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fromFlag := fs.String("from", "", "the first day of the report, as 2006-01-02 or RFC3339")
+	toFlag := fs.String("to", "", "the last day of the report, included, as 2006-01-02 or RFC3339. Defaults to now")
+	groupBy := fs.String("group-by", "provider", "the field or label the emissions are grouped by")
+	format := fs.String("format", "csv", "the output format: csv, json or html, or pdf for the executive summary")
+	path := fs.String("store", "", "the store file, read from the config when empty")
+	output := fs.String("output", "", "the file the report is written to, stdout when empty")
+	shifting := fs.Bool("shifting", false, "report the workloads that could be shifted to the greenest hours of the day")
+	profilesPath := fs.String("profiles", "", "the hourly grid intensity of the regions the shifting savings are estimated with, read from the config when empty")
+	timezone := fs.String("timezone", "", "the IANA time zone the days start in, e.g. Europe/Berlin, read from the config when empty")
+	executive := fs.Bool("executive", false, "write the executive summary: the total, the trend and the top groups and providers")
+	top := fs.Int("top", 5, "the groups listed by the executive summary, the others are summed")
+	organization := fs.String("organization", "", "the organization named by the executive summary, read from the config when empty")
+	logo := fs.String("logo", "", "the PNG or JPEG logo of the executive summary, read from the config when empty")
+	color := fs.String("color", "", "the color of the executive summary as #rrggbb, read from the config when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *fromFlag == "" {
+		return errors.New("--from is required")
+	}
+
+	switch *format {
+	case "csv", "json", "html":
+	case "pdf":
+		if !*executive {
+			return errors.New("the pdf format is the one of the executive summary, add --executive")
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	if *executive && *shifting {
+		return errors.New("--executive and --shifting can't be combined")
+	}
+	if *executive && *format == "csv" {
+		return errors.New("the executive summary is written as html, pdf or json")
+	}
+
+	// the file can be read without a config, the whole of it is reported on
+	cfg := config.StoreConfig{Path: *path}
+	if *path == "" {
+		config.InitConfig(ctx)
+		cfg = config.AppConfig().Store
+
+		if *profilesPath == "" {
+			*profilesPath = config.AppConfig().Shifting.Profiles
+		}
+
+		brand := config.AppConfig().Reports.Brand
+		for v, value := range map[*string]string{organization: brand.Organization, logo: brand.Logo, color: brand.Color} {
+			if *v == "" {
+				*v = value
+			}
+		}
+	}
+	if *timezone != "" {
+		cfg.Timezone = *timezone
+	}
+
+	var (
+		profiles report.Profiles
+		err      error
+	)
+	if *shifting && *profilesPath != "" {
+		profiles, err = report.LoadProfiles(*profilesPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	s, err := store.Open(ctx, &cfg)
+	if err != nil {
+		return err
+	}
+
+	from, _, err := store.ParseDate(*fromFlag, s.Location())
+	if err != nil {
+		return err
+	}
+
+	to := time.Now().In(s.Location())
+	if *toFlag != "" {
+		var dateOnly bool
+		to, dateOnly, err = store.ParseDate(*toFlag, s.Location())
+		if err != nil {
+			return err
+		}
+		// the last day is included
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+	}
+
+	if !from.Before(to) {
+		return errors.New("--from must be before --to")
+	}
+
+	var r interface {
+		Write(w io.Writer, format string) error
+	}
+	switch {
+	case *executive:
+		r, err = report.NewExecutive(s, &report.ExecutiveRequest{
+			From:    from,
+			To:      to,
+			GroupBy: *groupBy,
+			Top:     *top,
+			Brand: report.Brand{
+				Organization: *organization,
+				Logo:         *logo,
+				Color:        *color,
+			},
+		}, time.Now())
+		if err != nil {
+			return err
+		}
+	case *shifting:
+		r = report.NewShifting(s.Select(from, to, nil), from, to, profiles)
+	default:
+		r = report.New(s, from, to, *groupBy)
+	}
+
+	if *output == "" {
+		return r.Write(out, *format)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Write(f, *format); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// runReport runs the report subcommand and returns its exit code
+func runReport(ctx context.Context, args []string) int {
+	return runCommand(ctx, func(ctx context.Context) error {
+		return writeReport(ctx, args, os.Stdout)
+	})
+}
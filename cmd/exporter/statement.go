@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/re-cinq/aether/pkg/statement"
+)
+
+// verifyStatement checks that a statement returned by /api/v1/statement is
+// signed by the public key, and prints it
+//
+//	aether statement verify --key public.pem statement.json
+func verifyStatement(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("statement verify", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "the Ed25519 public key, as a PEM file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *keyPath == "" {
+		return errors.New("--key is required")
+	}
+
+	key, err := statement.LoadPublicKey(*keyPath)
+	if err != nil {
+		return err
+	}
+
+	// the envelope is read from stdin without a file
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var e statement.Envelope
+	if err := json.NewDecoder(in).Decode(&e); err != nil {
+		return fmt.Errorf("failed decoding the envelope: %w", err)
+	}
+
+	s, err := statement.Verify(&e, key)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// runStatement runs the statement subcommands and returns their exit code
+func runStatement(ctx context.Context, args []string) int {
+	if len(args) == 0 || args[0] != "verify" {
+		fmt.Fprintln(os.Stderr, "usage: aether statement verify --key <public key> [statement]")
+		return 1
+	}
+
+	return runCommand(ctx, func(context.Context) error {
+		return verifyStatement(args[1:], os.Stdin, os.Stdout)
+	})
+}
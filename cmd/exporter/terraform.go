@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/terraform"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// terraformPlan estimates the emissions of the instances created and deleted
+// by a Terraform plan, e.g. to comment them on the pull request of the change
+//
+//	terraform show -json plan.tfplan > plan.json
+//	aether terraform --plan plan.json --output markdown
+func terraformPlan(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("terraform", flag.ContinueOnError)
+	planPath := fs.String("plan", "-", "the JSON plan, as written by terraform show -json, - reads it from stdin")
+	region := fs.String("region", "", "the region of the instances whose region is not in the plan")
+	utilization := fs.Float64("utilization", 50, "the CPU utilization in percent")
+	hours := fs.Float64("hours", 730, "how many hours the instances run, a month by default")
+	dataPath := fs.String("factors", "", "a local copy of the emission factors (the data/v1 directory of the emissions-data repo), pulled when empty")
+	output := fs.String("output", "text", "the output format: text, json or markdown")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch *output {
+	case "text", "json", "markdown":
+	default:
+		return fmt.Errorf("unknown output %q", *output)
+	}
+
+	if *planPath != "-" {
+		f, err := os.Open(*planPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	changes, err := terraform.ParsePlan(in, *region)
+	if err != nil {
+		return err
+	}
+
+	if *dataPath != "" {
+		factors.DataPath = *dataPath
+	} else if _, err := calculator.LoadFactors(ctx); err != nil {
+		return fmt.Errorf("failed pulling the emission factors: %w", err)
+	}
+
+	e, err := terraform.EstimatePlan(ctx, changes, *utilization, time.Duration(*hours*float64(time.Hour)))
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	case "markdown":
+		fmt.Fprintf(out, "### Emissions of the plan\n\n")
+		fmt.Fprintf(out, "Estimated at %g%% CPU over %g hours: **%+.2f kgCO2eq** (%.2f added, %.2f removed).\n\n",
+			e.Utilization, *hours, e.Delta/1000, e.Added/1000, e.Removed/1000)
+		if len(e.Instances) == 0 {
+			fmt.Fprintln(out, "The plan doesn't change any instance.")
+			return nil
+		}
+		fmt.Fprintf(out, "| Resource | Action | Instance | Region | kgCO2eq |\n")
+		fmt.Fprintf(out, "| --- | --- | --- | --- | --- |\n")
+		for _, i := range e.Instances {
+			fmt.Fprintf(out, "| `%s` | %s | %s %s | %s | %s |\n", i.Address, i.Action, i.Provider, i.Kind, i.Region, instanceEmissions(&i))
+		}
+		return nil
+	default:
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RESOURCE\tACTION\tPROVIDER\tTYPE\tREGION\tkgCO2eq\t")
+		for _, i := range e.Instances {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", i.Address, i.Action, i.Provider, i.Kind, i.Region, instanceEmissions(&i))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\nAdded %.2f kgCO2eq, removed %.2f kgCO2eq, change %+.2f kgCO2eq over %g hours\n",
+			e.Added/1000, e.Removed/1000, e.Delta/1000, *hours)
+		return nil
+	}
+}
+
+// instanceEmissions formats the signed emissions of an instance in kgCO2eq,
+// or why they couldn't be estimated
+func instanceEmissions(i *terraform.InstanceEstimate) string {
+	if i.Error != "" {
+		return "unknown: " + i.Error
+	}
+	if i.Action == terraform.Delete {
+		return fmt.Sprintf("%+.2f", -i.Emissions/1000)
+	}
+	return fmt.Sprintf("%+.2f", i.Emissions/1000)
+}
+
+// runTerraform runs the terraform subcommand and returns its exit code
+func runTerraform(ctx context.Context, args []string) int {
+	return runCommand(ctx, func(ctx context.Context) error {
+		return terraformPlan(ctx, args, os.Stdin, os.Stdout)
+	})
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/attribution"
+	"github.com/re-cinq/aether/pkg/store"
+	"github.com/re-cinq/aether/pkg/units"
+)
+
+// The escape sequence moving the cursor home and clearing the terminal
+const clearScreen = "\033[H\033[2J"
+
+// topOptions are the flags of the top subcommand
+type topOptions struct {
+	url      string
+	token    string
+	view     string
+	sort     string
+	limit    int
+	interval time.Duration
+
+	// The filters of the instances
+	provider string
+	region   string
+	labels   []string
+}
+
+// top renders the highest emitting instances or namespaces of a running
+// exporter, refreshed every interval until interrupted
+//
+//	aether top --url http://localhost:8080 --view namespaces
+func top(ctx context.Context, args []string, out io.Writer) error {
+	o := topOptions{}
+
+	fs := flag.NewFlagSet("top", flag.ContinueOnError)
+	fs.StringVar(&o.url, "url", "http://127.0.0.1:8080", "the address of the exporter API")
+	fs.StringVar(&o.token, "token", os.Getenv("AETHER_TOKEN"), "the bearer token of the API, defaults to $AETHER_TOKEN")
+	fs.StringVar(&o.view, "view", "instances", "what to list: instances or namespaces")
+	fs.StringVar(&o.sort, "sort", "-emissions", "the order: emissions, -emissions, name or -name")
+	fs.IntVar(&o.limit, "limit", 20, "how many rows are shown")
+	fs.DurationVar(&o.interval, "interval", 5*time.Second, "how often the view is refreshed, 0 renders it once")
+	fs.StringVar(&o.provider, "provider", "", "only show the instances of this provider")
+	fs.StringVar(&o.region, "region", "", "only show the instances of this region")
+	fs.Func("label", "only show the instances with this label, formatted as key=value. Can be repeated", func(s string) error {
+		if k, _, ok := strings.Cut(s, "="); !ok || k == "" {
+			return errors.New("labels must be formatted as key=value")
+		}
+		o.labels = append(o.labels, s)
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var render func(ctx context.Context, w io.Writer) error
+	switch o.view {
+	case "instances":
+		render = o.renderInstances
+	case "namespaces":
+		render = o.renderNamespaces
+	default:
+		return fmt.Errorf("unknown view %q", o.view)
+	}
+
+	switch o.sort {
+	case "emissions", "-emissions", "name", "-name":
+	default:
+		return fmt.Errorf("unknown sort %q", o.sort)
+	}
+
+	if o.interval <= 0 {
+		return render(ctx, out)
+	}
+
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		fmt.Fprint(out, clearScreen)
+		fmt.Fprintf(out, "aether top - %s - %s - every %s\n\n", o.url, time.Now().Format(time.TimeOnly), o.interval)
+
+		// the errors are shown until the next refresh
+		if err := render(ctx, out); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintln(out, "error:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// renderInstances renders the latest emissions of the instances
+func (o *topOptions) renderInstances(ctx context.Context, out io.Writer) error {
+	q := url.Values{}
+	q.Set("limit", strconv.Itoa(o.limit))
+	q.Set("sort", o.sort)
+	if o.provider != "" {
+		q.Set("provider", o.provider)
+	}
+	if o.region != "" {
+		q.Set("region", o.region)
+	}
+	for _, l := range o.labels {
+		q.Add("label", l)
+	}
+
+	var resp struct {
+		Instances []store.Sample `json:"instances"`
+		Unit      units.Unit     `json:"unit"`
+	}
+	if err := o.get(ctx, "/api/v1/instances", q, &resp); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PROVIDER\tREGION\tKIND\tNAME\tOPERATIONAL\tEMBODIED\tTOTAL %s\t\n", unitOf(resp.Unit))
+	for i := range resp.Instances {
+		s := &resp.Instances[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.2f\t%.2f\t%.2f\t\n",
+			s.Provider, s.Region, s.Kind, s.Name, s.Operational, s.Embodied, s.Operational+s.Embodied)
+	}
+	return w.Flush()
+}
+
+// namespaceRow is the emissions of the pods of a namespace
+type namespaceRow struct {
+	name      string
+	pods      int
+	emissions float64
+}
+
+// renderNamespaces renders the emissions of the namespaces, the sum of the
+// emissions attributed to their pods
+func (o *topOptions) renderNamespaces(ctx context.Context, out io.Writer) error {
+	namespaces := make(map[string]*namespaceRow)
+	var unit units.Unit
+
+	q := url.Values{}
+	q.Set("limit", "1000")
+	for {
+		var resp struct {
+			Pods []attribution.Pod `json:"pods"`
+			Unit units.Unit        `json:"unit"`
+			Next string            `json:"next"`
+		}
+		if err := o.get(ctx, "/api/v1/pods", q, &resp); err != nil {
+			return err
+		}
+
+		unit = resp.Unit
+		for _, p := range resp.Pods {
+			row, ok := namespaces[p.Namespace]
+			if !ok {
+				row = &namespaceRow{name: p.Namespace}
+				namespaces[p.Namespace] = row
+			}
+			row.pods++
+			row.emissions += p.Emissions
+		}
+
+		if resp.Next == "" {
+			break
+		}
+		q.Set("cursor", resp.Next)
+	}
+
+	rows := make([]*namespaceRow, 0, len(namespaces))
+	for _, row := range namespaces {
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch o.sort {
+		case "emissions":
+			if a.emissions != b.emissions {
+				return a.emissions < b.emissions
+			}
+		case "-emissions":
+			if a.emissions != b.emissions {
+				return a.emissions > b.emissions
+			}
+		case "-name":
+			return a.name > b.name
+		}
+		return a.name < b.name
+	})
+
+	if len(rows) > o.limit {
+		rows = rows[:o.limit]
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NAMESPACE\tPODS\tEMISSIONS %s\t\n", unitOf(unit))
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t\n", row.name, row.pods, row.emissions)
+	}
+	return w.Flush()
+}
+
+// get decodes the JSON body returned by the API
+func (o *topOptions) get(ctx context.Context, path string, q url.Values, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(o.url, "/")+path+"?"+q.Encode(), http.NoBody)
+	if err != nil {
+		return err
+	}
+	if o.token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, body.Error)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// runTop runs the top subcommand and returns its exit code
+func runTop(ctx context.Context, args []string) int {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return runCommand(ctx, func(ctx context.Context) error {
+		return top(ctx, args, os.Stdout)
+	})
+}
+
+// unitOf returns the unit of the emissions returned by the API, the
+// exporters which don't return it use gCO2e
+func unitOf(u units.Unit) units.Unit {
+	if u == "" {
+		return units.Grams
+	}
+	return u
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/attribution"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/re-cinq/aether/pkg/units"
+	"github.com/stretchr/testify/require"
+)
+
+// topServer serves the instances and the pods of the top tests, filtered
+// and sorted like the API, and the pods in pages of two
+func topServer(t *testing.T) *httptest.Server {
+	samples := []store.Sample{
+		{Provider: v1.AWS, Region: "eu-west-1", Kind: "m5.large", Name: "web", Operational: 10, Embodied: 2, Labels: v1.Labels{"team": "shop"}},
+		{Provider: v1.AWS, Region: "us-east-1", Kind: "m5.xlarge", Name: "db", Operational: 30, Embodied: 5, Labels: v1.Labels{"team": "data"}},
+		{Provider: v1.GCP, Region: "europe-west1", Kind: "n2-standard-2", Name: "api", Operational: 20, Embodied: 1, Labels: v1.Labels{"team": "shop"}},
+	}
+	pods := []attribution.Pod{
+		{Namespace: "shop", Name: "web-1", Emissions: 1.5},
+		{Namespace: "data", Name: "db-0", Emissions: 4},
+		{Namespace: "shop", Name: "web-2", Emissions: 1.5},
+		{Namespace: "monitoring", Name: "prometheus-0", Emissions: 2},
+		{Namespace: "kube-system", Name: "coredns", Emissions: 0.5},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/instances", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid token"})
+			return
+		}
+
+		q := req.URL.Query()
+		var out []store.Sample
+		for _, s := range samples {
+			if p := q.Get("provider"); p != "" && s.Provider.String() != p {
+				continue
+			}
+			if r := q.Get("region"); r != "" && s.Region != r {
+				continue
+			}
+			matches := true
+			for _, l := range q["label"] {
+				k, v, _ := strings.Cut(l, "=")
+				matches = matches && s.Labels[k] == v
+			}
+			if matches {
+				out = append(out, s)
+			}
+		}
+
+		sort.Slice(out, func(i, j int) bool {
+			a, b := out[i].Operational+out[i].Embodied, out[j].Operational+out[j].Embodied
+			switch q.Get("sort") {
+			case "emissions":
+				return a < b
+			case "name":
+				return out[i].Name < out[j].Name
+			case "-name":
+				return out[i].Name > out[j].Name
+			}
+			return a > b
+		})
+		if limit, _ := strconv.Atoi(q.Get("limit")); limit > 0 && len(out) > limit {
+			out = out[:limit]
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"instances": out, "unit": units.Kilograms})
+	})
+	mux.HandleFunc("/api/v1/pods", func(w http.ResponseWriter, req *http.Request) {
+		start, _ := strconv.Atoi(req.URL.Query().Get("cursor"))
+		end := min(start+2, len(pods))
+
+		next := ""
+		if end < len(pods) {
+			next = strconv.Itoa(end)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"pods": pods[start:end], "next": next})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// rows returns the fields of every line of a table
+func rows(table string) [][]string {
+	var out [][]string
+	for _, line := range strings.Split(strings.TrimSpace(table), "\n") {
+		out = append(out, strings.Fields(line))
+	}
+	return out
+}
+
+func TestTop(t *testing.T) {
+	srv := topServer(t)
+
+	type testcase struct {
+		name string
+		args []string
+		rows [][]string
+		err  string
+	}
+
+	for _, test := range []testcase{
+		{
+			name: "the instances emitting the most first",
+			args: []string{"--view", "instances"},
+			rows: [][]string{
+				{"PROVIDER", "REGION", "KIND", "NAME", "OPERATIONAL", "EMBODIED", "TOTAL", "kgCO2e"},
+				{"aws", "us-east-1", "m5.xlarge", "db", "30.00", "5.00", "35.00"},
+				{"gcp", "europe-west1", "n2-standard-2", "api", "20.00", "1.00", "21.00"},
+				{"aws", "eu-west-1", "m5.large", "web", "10.00", "2.00", "12.00"},
+			},
+		},
+		{
+			name: "the instances sorted by name and limited",
+			args: []string{"--sort", "name", "--limit", "2"},
+			rows: [][]string{
+				{"PROVIDER", "REGION", "KIND", "NAME", "OPERATIONAL", "EMBODIED", "TOTAL", "kgCO2e"},
+				{"gcp", "europe-west1", "n2-standard-2", "api", "20.00", "1.00", "21.00"},
+				{"aws", "us-east-1", "m5.xlarge", "db", "30.00", "5.00", "35.00"},
+			},
+		},
+		{
+			name: "the instances filtered by provider and label",
+			args: []string{"--provider", "aws", "--label", "team=shop"},
+			rows: [][]string{
+				{"PROVIDER", "REGION", "KIND", "NAME", "OPERATIONAL", "EMBODIED", "TOTAL", "kgCO2e"},
+				{"aws", "eu-west-1", "m5.large", "web", "10.00", "2.00", "12.00"},
+			},
+		},
+		{
+			name: "the instances filtered by region",
+			args: []string{"--region", "europe-west1", "--sort", "emissions"},
+			rows: [][]string{
+				{"PROVIDER", "REGION", "KIND", "NAME", "OPERATIONAL", "EMBODIED", "TOTAL", "kgCO2e"},
+				{"gcp", "europe-west1", "n2-standard-2", "api", "20.00", "1.00", "21.00"},
+			},
+		},
+		{
+			name: "the namespaces of every page emitting the most first",
+			args: []string{"--view", "namespaces"},
+			rows: [][]string{
+				{"NAMESPACE", "PODS", "EMISSIONS", "gCO2e"},
+				{"data", "1", "4.00"},
+				{"shop", "2", "3.00"},
+				{"monitoring", "1", "2.00"},
+				{"kube-system", "1", "0.50"},
+			},
+		},
+		{
+			name: "the namespaces sorted by name in reverse and limited",
+			args: []string{"--view", "namespaces", "--sort", "-name", "--limit", "2"},
+			rows: [][]string{
+				{"NAMESPACE", "PODS", "EMISSIONS", "gCO2e"},
+				{"shop", "2", "3.00"},
+				{"monitoring", "1", "2.00"},
+			},
+		},
+		{
+			name: "the namespaces emitting the least first",
+			args: []string{"--view", "namespaces", "--sort", "emissions"},
+			rows: [][]string{
+				{"NAMESPACE", "PODS", "EMISSIONS", "gCO2e"},
+				{"kube-system", "1", "0.50"},
+				{"monitoring", "1", "2.00"},
+				{"shop", "2", "3.00"},
+				{"data", "1", "4.00"},
+			},
+		},
+		{
+			name: "the API rejects the token",
+			args: []string{"--token", "wrong"},
+			err:  "/api/v1/instances returned 401 Unauthorized: invalid token",
+		},
+		{
+			name: "unknown view",
+			args: []string{"--view", "nodes"},
+			err:  `unknown view "nodes"`,
+		},
+		{
+			name: "unknown sort",
+			args: []string{"--sort", "kind"},
+			err:  `unknown sort "kind"`,
+		},
+		{
+			name: "malformed label",
+			args: []string{"--label", "team"},
+			err:  "labels must be formatted as key=value",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			args := append([]string{"--url", srv.URL, "--token", "secret", "--interval", "0"}, test.args...)
+
+			var out strings.Builder
+			err := top(context.Background(), args, &out)
+			if test.err != "" {
+				assert.ErrorContains(err, test.err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(test.rows, rows(out.String()))
+		})
+	}
+}
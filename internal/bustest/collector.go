@@ -0,0 +1,21 @@
+// Package bustest helps the tests of the handlers publishing on the bus
+package bustest
+
+import (
+	"context"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Collector receives the published instances, subscribed to the events
+// carrying one
+type Collector chan v1.Instance
+
+// Handle sends the instance of the event to the channel
+func (c Collector) Handle(ctx context.Context, e *bus.Event) {
+	c <- e.Data.(v1.Instance)
+}
+
+// Stop is used to fulfill the EventHandler interface
+func (c Collector) Stop(ctx context.Context) {}
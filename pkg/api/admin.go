@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/re-cinq/aether/pkg/scraper"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// scrapeController runs on-demand operations on the scrapers
+type scrapeController interface {
+	Trigger(provider v1.Provider, account string) error
+	FlushCaches()
+}
+
+// factorsRefresher reloads the emission factors
+type factorsRefresher interface {
+	RefreshFactors(ctx context.Context) error
+}
+
+// adminRouter registers the admin endpoints, all of them require the
+// configured bearer token
+func (a *API) adminRouter(r *mux.Router) {
+	admin := r.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(a.requireToken)
+
+	if a.scrapers != nil {
+		admin.HandleFunc("/scrape/{provider}/{account}", a.triggerScrape).Methods("POST")
+		admin.HandleFunc("/caches/flush", a.flushCaches).Methods("POST")
+	}
+
+	if a.factors != nil {
+		admin.HandleFunc("/factors/refresh", a.refreshFactors).Methods("POST")
+	}
+
+	if a.imports != nil {
+		admin.HandleFunc("/import", a.importHandler).Methods("POST")
+	}
+}
+
+// requireToken rejects the requests without the admin bearer token
+func (a *API) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.adminToken)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// triggerScrape runs a scrape of the account without waiting for the next
+// interval
+func (a *API) triggerScrape(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+
+	provider, ok := v1.Providers[vars["provider"]]
+	if !ok {
+		writeError(w, http.StatusBadRequest, v1.ErrParsingProvider)
+		return
+	}
+
+	if err := a.scrapers.Trigger(provider, vars["account"]); err != nil {
+		if errors.Is(err, scraper.ErrAccountNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "triggered"})
+}
+
+// flushCaches drops the data cached by the scrapers
+func (a *API) flushCaches(w http.ResponseWriter, req *http.Request) {
+	a.scrapers.FlushCaches()
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "flushed"})
+}
+
+// refreshFactors reloads the emission factors
+func (a *API) refreshFactors(w http.ResponseWriter, req *http.Request) {
+	if err := a.factors.RefreshFactors(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "refreshed"})
+}
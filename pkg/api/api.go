@@ -11,8 +11,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/version"
+	"github.com/re-cinq/aether/pkg/calculator"
 	"github.com/re-cinq/aether/pkg/config"
 	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/report"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/re-cinq/aether/pkg/units"
 )
 
 const readHeaderTimeout = 2 * time.Second
@@ -23,16 +27,150 @@ type API struct {
 
 	addr        string
 	metricsPath string
+
+	// Listener security
+	tls  config.TLSConfig
+	auth config.AuthConfig
+
+	// Used to report the status of the scrapers
+	status statusReader
+
+	// Used to query the stored emissions
+	store   emissionsStore
+	graphQL bool
+
+	// The unit and precision of the emissions of the responses
+	output units.Output
+
+	// Whether the web dashboard is served
+	ui bool
+
+	// Whether the Kubernetes external metrics API is served and the grid
+	// intensity it uses
+	externalMetrics bool
+	intensity       func(provider v1.Provider, region string) (float64, error)
+
+	// Used to explain the calculations of the instances and estimate the
+	// emissions of instance types
+	calculations calculationReader
+	estimate     func(ctx context.Context, req *calculator.EstimateRequest) (*calculator.Estimate, error)
+
+	// Used to list the emissions of the Kubernetes pods
+	pods podsReader
+
+	// Used to sign the emissions statements
+	statements *statementSigner
+
+	// Used to join the emissions with the spend of the instances
+	costs costReader
+
+	// Used to list the rightsizing recommendations of the providers
+	rightsizing recommendationReader
+
+	// The hourly grid intensity of the regions the workload shifting
+	// savings are estimated with
+	profiles report.Profiles
+
+	// Used to refresh the emissions before serving the metrics
+	refresher refresher
+
+	// Used by the admin endpoints
+	adminToken string
+	scrapers   scrapeController
+	factors    factorsRefresher
+	imports    emissionsWriter
+}
+
+// Option is used to configure the API
+type Option func(*API)
+
+// WithStatus exposes the status of the scrapers on /api/v1/status
+func WithStatus(s statusReader) Option {
+	return func(a *API) {
+		a.status = s
+	}
 }
 
-// New returns an instance of a configured API
-func New() *API {
+// WithStore exposes the stored emissions on /api/v1/query,
+// /api/v1/instances and /api/v1/shifting
+func WithStore(s emissionsStore) Option {
+	return func(a *API) {
+		a.store = s
+	}
+}
+
+// WithUnits outputs the emissions of the query, instances, pods, jobs,
+// overhead and estimate responses in the unit of the output, they're
+// returned in gCO2e otherwise
+func WithUnits(o units.Output) Option {
+	return func(a *API) {
+		a.output = o
+	}
+}
+
+// WithCalculations exposes the latest calculation of every instance on
+// /api/v1/instances/{id}, the lookups missing from the emission factors on
+// /api/v1/datasets/misses and the estimates of instance types on
+// /api/v1/estimate. The estimates and the grid intensity use its custom
+// regions
+func WithCalculations(c calculationReader) Option {
+	return func(a *API) {
+		a.calculations = c
+		a.intensity = c.GridIntensity
+		a.estimate = c.EstimateEmissions
+	}
+}
+
+// WithPods exposes the emissions attributed to the Kubernetes pods on
+// /api/v1/pods, the overhead of the cluster on /api/v1/overhead and the
+// completed jobs on /api/v1/jobs
+func WithPods(p podsReader) Option {
+	return func(a *API) {
+		a.pods = p
+	}
+}
+
+// WithScrapeController enables the admin endpoints to trigger scrapes and
+// flush the caches of the scrapers
+func WithScrapeController(c scrapeController) Option {
+	return func(a *API) {
+		a.scrapers = c
+	}
+}
+
+// WithFactorsRefresher enables the admin endpoint to refresh the emission
+// factors
+func WithFactorsRefresher(f factorsRefresher) Option {
+	return func(a *API) {
+		a.factors = f
+	}
+}
+
+// WithImports enables the admin endpoint importing the emissions computed by
+// third parties into the store
+func WithImports(w emissionsWriter) Option {
+	return func(a *API) {
+		a.imports = w
+	}
+}
+
+// New returns an instance of an API configured by cfg
+func New(cfg *config.APIConfig, opts ...Option) *API {
 	api := &API{
-		metricsPath: config.AppConfig().APIConfig.MetricsPath,
-		addr: fmt.Sprintf("%s:%s",
-			config.AppConfig().APIConfig.Address,
-			config.AppConfig().APIConfig.Port,
-		),
+		metricsPath:     cfg.MetricsPath,
+		adminToken:      cfg.AdminToken,
+		graphQL:         cfg.GraphQL,
+		ui:              cfg.UI,
+		intensity:       calculator.GridIntensity,
+		estimate:        calculator.EstimateEmissions,
+		tls:             cfg.TLS,
+		externalMetrics: cfg.ExternalMetrics,
+		auth:            cfg.Auth,
+		addr:            fmt.Sprintf("%s:%s", cfg.Address, cfg.Port),
+	}
+
+	for _, opt := range opts {
+		opt(api)
 	}
 
 	api.setup()
@@ -45,12 +183,96 @@ func (a *API) router() *mux.Router {
 	r := mux.NewRouter()
 	r.StrictSlash(true)
 
+	// The health probe is public and the admin endpoints use their own token
+	r.Use(authenticate(a.auth, "/healthz", "/api/v1/admin"))
+
 	// HealthCheck
 	r.HandleFunc("/healthz", healthProbe).Methods("GET")
 
+	// OpenAPI document
+	r.HandleFunc("/api/openapi.json", openAPIHandler).Methods("GET")
+
 	// Prometheus exporter
 	prometheus.MustRegister(version.NewCollector("cloud_carbon_exporter"))
-	r.Handle(a.metricsPath, promhttp.Handler()).Methods("GET")
+	var metrics http.Handler = promhttp.Handler()
+	if a.refresher != nil {
+		metrics = a.pull(metrics)
+	}
+	r.Handle(a.metricsPath, metrics).Methods("GET")
+
+	// Scrapers status
+	if a.status != nil {
+		r.HandleFunc("/api/v1/status", a.statusHandler).Methods("GET")
+	}
+
+	// Emissions queries
+	if a.store != nil {
+		r.HandleFunc("/api/v1/query", a.queryHandler).Methods("GET")
+		r.HandleFunc("/api/v1/emissions/range", a.rangeHandler).Methods("GET")
+		r.HandleFunc("/api/v1/instances", a.instancesHandler).Methods("GET")
+		r.HandleFunc("/api/v1/shifting", a.shiftingHandler).Methods("GET")
+
+		if a.graphQL {
+			r.Handle("/api/graphql", a.graphQLHandler()).Methods("POST")
+		}
+	}
+
+	// Instance calculations
+	if a.calculations != nil {
+		r.HandleFunc("/api/v1/instances/{id}", a.instanceHandler).Methods("GET")
+		r.HandleFunc("/api/v1/datasets", a.datasetsHandler).Methods("GET")
+		r.HandleFunc("/api/v1/datasets/misses", a.missesHandler).Methods("GET")
+		r.HandleFunc("/api/v1/estimate", a.estimateHandler).Methods("GET")
+	}
+
+	// Signed emissions statements
+	if a.store != nil && a.calculations != nil && a.statements != nil {
+		r.HandleFunc("/api/v1/statement", a.statementHandler).Methods("GET")
+		r.HandleFunc("/api/v1/statement/schema", statementSchemaHandler).Methods("GET")
+	}
+
+	// Emissions per unit spent
+	if a.store != nil && a.costs != nil {
+		r.HandleFunc("/api/v1/costs", a.costsHandler).Methods("GET")
+	}
+
+	// Utilization-weighted efficiency of the compute instances
+	if a.store != nil {
+		r.HandleFunc("/api/v1/efficiency", a.efficiencyHandler).Methods("GET")
+	}
+
+	// Estimates in the format of Cloud Carbon Footprint
+	if a.store != nil {
+		r.HandleFunc("/api/ccf/footprint", a.ccfHandler).Methods("GET")
+	}
+
+	// Rightsizing recommendations with their carbon savings
+	if a.rightsizing != nil {
+		r.HandleFunc("/api/v1/recommendations/rightsizing", a.rightsizingHandler).Methods("GET")
+	}
+
+	// Kubernetes pods emissions
+	if a.pods != nil {
+		r.HandleFunc("/api/v1/pods", a.podsHandler).Methods("GET")
+		r.HandleFunc("/api/v1/overhead", a.overheadHandler).Methods("GET")
+		r.HandleFunc("/api/v1/jobs", a.jobsHandler).Methods("GET")
+	}
+
+	// Kubernetes external metrics
+	if a.externalMetrics {
+		a.externalMetricsRouter(r)
+	}
+
+	// Web dashboard
+	if a.ui {
+		r.Handle("/", http.RedirectHandler("/ui/", http.StatusFound)).Methods("GET")
+		r.PathPrefix("/ui/").Handler(uiHandler()).Methods("GET")
+	}
+
+	// Admin endpoints, only enabled when a token is configured
+	if a.adminToken != "" {
+		a.adminRouter(r)
+	}
 
 	return r
 }
@@ -71,7 +293,7 @@ func (a *API) Start(ctx context.Context) {
 	logger.Info("server started", "address", a.addr)
 
 	// Listen to it
-	if err := a.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := serve(a.Server, a.tls); err != nil && err != http.ErrServerClosed {
 		logger.Error("failed to listen on address", "address", a.addr, "error", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/re-cinq/aether/pkg/config"
+)
+
+// authenticate returns a middleware rejecting the requests without valid
+// credentials. The requests to the paths starting with one of the skipped
+// prefixes are not authenticated.
+// If no credentials are configured all the requests are accepted
+func authenticate(cfg config.AuthConfig, skip ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			for _, prefix := range skip {
+				if strings.HasPrefix(req.URL.Path, prefix) {
+					next.ServeHTTP(w, req)
+					return
+				}
+			}
+
+			if !authorized(cfg, req) {
+				if cfg.Username != "" {
+					w.Header().Set("WWW-Authenticate", `Basic realm="aether"`)
+				}
+				writeError(w, http.StatusUnauthorized, errors.New("invalid or missing credentials"))
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// authorized returns whether the request has a valid bearer token or valid
+// basic auth credentials
+func authorized(cfg config.AuthConfig, req *http.Request) bool {
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		for _, t := range cfg.Tokens {
+			if equal(token, t) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if user, password, ok := req.BasicAuth(); ok && cfg.Username != "" {
+		// both are compared so that the time does not reveal which one is wrong
+		validUser := equal(user, cfg.Username)
+		validPassword := equal(password, cfg.Password)
+		return validUser && validPassword
+	}
+
+	return false
+}
+
+// equal compares the secrets in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// tlsConfig returns the TLS config of a listener, or nil if TLS is disabled.
+// When a client CA is set the clients must present a certificate signed by it
+func tlsConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	t := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in the client CA %s", cfg.ClientCAFile)
+		}
+
+		t.ClientCAs = pool
+		t.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return t, nil
+}
+
+// serve starts the server, with TLS if it's configured
+func serve(srv *http.Server, cfg config.TLSConfig) error {
+	t, err := tlsConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	if t == nil {
+		return srv.ListenAndServe()
+	}
+
+	srv.TLSConfig = t
+	return srv.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+}
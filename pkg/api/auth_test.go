@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cfg := config.AuthConfig{
+		Tokens:   []string{"token"},
+		Username: "user",
+		Password: "password",
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config.AuthConfig
+		path   string
+		header func(req *http.Request)
+		code   int
+	}{
+		{
+			name: "no credentials configured",
+			path: "/metrics",
+			code: http.StatusOK,
+		},
+		{
+			name: "missing credentials",
+			cfg:  cfg,
+			path: "/metrics",
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "valid token",
+			cfg:  cfg,
+			path: "/metrics",
+			header: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer token")
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "invalid token",
+			cfg:  cfg,
+			path: "/metrics",
+			header: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer wrong")
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "valid basic auth",
+			cfg:  cfg,
+			path: "/metrics",
+			header: func(req *http.Request) {
+				req.SetBasicAuth("user", "password")
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "invalid basic auth",
+			cfg:  cfg,
+			path: "/metrics",
+			header: func(req *http.Request) {
+				req.SetBasicAuth("user", "wrong")
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "skipped path",
+			cfg:  cfg,
+			path: "/healthz",
+			code: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.header != nil {
+				test.header(req)
+			}
+
+			rec := httptest.NewRecorder()
+			authenticate(test.cfg, "/healthz")(ok).ServeHTTP(rec, req)
+
+			assert.Equal(test.code, rec.Code)
+		})
+	}
+}
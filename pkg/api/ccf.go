@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/re-cinq/aether/pkg/ccf"
+	"github.com/re-cinq/aether/pkg/cost"
+)
+
+// ccfHandler returns the estimates of a period in the format of the
+// footprint endpoint of Cloud Carbon Footprint, with its start, end and
+// groupBy parameters, so that its client can read them from
+// /api/ccf/footprint. A date only end is included
+func (a *API) ccfHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	if q.Get("start") == "" || q.Get("end") == "" {
+		writeError(w, http.StatusBadRequest, errors.New("the start and end dates are required"))
+		return
+	}
+
+	loc := a.store.Location()
+	from, to, err := parseDates(q.Get("start"), q.Get("end"), loc)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	groupBy := q.Get("groupBy")
+	if groupBy == "" {
+		groupBy = ccf.GroupByDay
+	}
+	if err := ccf.ValidGroupBy(groupBy); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var costs []cost.Cost
+	if a.costs != nil {
+		costs = a.costs.Costs(from, to)
+	}
+
+	writeJSON(w, http.StatusOK, ccf.Export(a.store.Select(from, to, nil), costs, &ccf.Request{
+		From:      from,
+		To:        to,
+		GroupBy:   groupBy,
+		Location:  loc,
+		Intensity: a.intensity,
+	}))
+}
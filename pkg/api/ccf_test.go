@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/ccf"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCCFHandler(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	for _, sample := range []store.Sample{
+		{Time: time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC), Provider: v1.AWS, Service: "ec2", Name: "a", Region: "eu-west-1", Operational: 1_000_000},
+		{Time: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), Provider: v1.AWS, Service: "ec2", Name: "a", Region: "eu-west-1", Operational: 2_000_000},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	a := &API{}
+	WithStore(s)(a)
+
+	tests := []struct {
+		name    string
+		query   string
+		code    int
+		periods int
+	}{
+		{name: "days", query: "start=2024-01-01&end=2024-01-31", code: http.StatusOK, periods: 2},
+		{name: "month", query: "start=2024-01-01&end=2024-01-31&groupBy=month", code: http.StatusOK, periods: 1},
+		{name: "end excluded", query: "start=2024-01-01&end=2024-01-31T00:00:00Z", code: http.StatusOK, periods: 1},
+		{name: "missing end", query: "start=2024-01-01", code: http.StatusBadRequest},
+		{name: "invalid groupBy", query: "start=2024-01-01&end=2024-01-31&groupBy=hour", code: http.StatusBadRequest},
+		{name: "empty period", query: "start=2024-01-31&end=2024-01-01", code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			w := httptest.NewRecorder()
+			a.ccfHandler(w, httptest.NewRequest(http.MethodGet, "/api/ccf/footprint?"+test.query, http.NoBody))
+			assert.Equal(test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var results []ccf.EstimationResult
+			assert.NoError(json.Unmarshal(w.Body.Bytes(), &results))
+			assert.Len(results, test.periods)
+			assert.Equal("AWS", results[0].ServiceEstimates[0].CloudProvider)
+			assert.Positive(results[0].ServiceEstimates[0].CO2e)
+		})
+	}
+}
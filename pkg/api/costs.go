@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/cost"
+)
+
+// costReader returns the spend read from the billing exports
+type costReader interface {
+	Costs(from, to time.Time) []cost.Cost
+}
+
+// WithCosts joins the stored emissions with the spend of the instances on
+// /api/v1/costs
+func WithCosts(c costReader) Option {
+	return func(a *API) {
+		a.costs = c
+	}
+}
+
+// costsHandler returns the emissions and the spend of the instances over a
+// period, grouped by a field or a label, the provider by default
+func (a *API) costsHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	from, to, err := parsePeriod(q, a.store.Location())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	groupBy := q.Get("groupBy")
+	if groupBy == "" {
+		groupBy = "provider"
+	}
+
+	samples := a.store.Select(from, to, nil)
+	writeJSON(w, http.StatusOK, cost.Join(samples, a.costs.Costs(from, to), from, to, groupBy))
+}
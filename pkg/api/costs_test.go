@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/cost"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCosts []cost.Cost
+
+func (f fakeCosts) Costs(from, to time.Time) []cost.Cost {
+	var out []cost.Cost
+	for _, c := range f {
+		if !c.Day.Before(from) && c.Day.Before(to) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func TestCostsHandler(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, sample := range []store.Sample{
+		{Time: day.Add(time.Hour), Provider: v1.AWS, Name: "i-a", Labels: v1.Labels{"team": "data"}, Operational: 100},
+		{Time: day.Add(time.Hour), Provider: v1.AWS, Name: "i-b", Labels: v1.Labels{"team": "web"}, Operational: 30},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	a := &API{}
+	WithStore(s)(a)
+	WithCosts(fakeCosts{
+		{Provider: v1.AWS, Resource: "i-a", Currency: "USD", Day: day, Amount: 4},
+		{Provider: v1.AWS, Resource: "i-b", Currency: "USD", Day: day, Amount: 1},
+	})(a)
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		rows  []cost.Row
+	}{
+		{
+			name:  "by provider",
+			query: "from=2024-01-01&to=2024-01-31",
+			code:  http.StatusOK,
+			rows: []cost.Row{
+				{Group: "aws", Instances: 2, Emissions: 130, Cost: 5, Currency: "USD", GCO2ePerDollar: 26},
+			},
+		},
+		{
+			name:  "by label",
+			query: "from=2024-01-01&to=2024-01-31&groupBy=team",
+			code:  http.StatusOK,
+			rows: []cost.Row{
+				{Group: "data", Instances: 1, Emissions: 100, Cost: 4, Currency: "USD", GCO2ePerDollar: 25},
+				{Group: "web", Instances: 1, Emissions: 30, Cost: 1, Currency: "USD", GCO2ePerDollar: 30},
+			},
+		},
+		{name: "end excluded", query: "from=2024-01-01&to=2024-01-31T00:00:00Z", code: http.StatusOK, rows: []cost.Row{}},
+		{name: "missing period", query: "groupBy=team", code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			w := httptest.NewRecorder()
+			a.costsHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/costs?"+test.query, http.NoBody))
+			assert.Equal(test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var v cost.View
+			assert.NoError(json.Unmarshal(w.Body.Bytes(), &v))
+			assert.Equal(test.rows, v.Rows)
+		})
+	}
+}
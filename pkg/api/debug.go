@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+)
+
+// Debug is the server exposing the profiling endpoints and the Go runtime
+// metrics. It listens on its own port, so that it's not exposed together
+// with the API
+type Debug struct {
+	*http.Server
+
+	addr string
+
+	// Listener security
+	tls  config.TLSConfig
+	auth config.AuthConfig
+}
+
+// NewDebug returns an instance of a debug server configured by cfg
+func NewDebug(cfg *config.DebugConfig) *Debug {
+	d := &Debug{
+		addr: fmt.Sprintf("%s:%s", cfg.Address, cfg.Port),
+		tls:  cfg.TLS,
+		auth: cfg.Auth,
+	}
+
+	d.Server = &http.Server{
+		Addr:              d.addr,
+		Handler:           d.router(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	return d
+}
+
+// router configures the routes for the debug server
+func (d *Debug) router() *mux.Router {
+	r := mux.NewRouter()
+	r.Use(authenticate(d.auth))
+
+	// Profiling
+	r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	r.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+
+	// Go runtime metrics (goroutines, heap, GC, scheduler)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(
+			collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll),
+		),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	r.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{})).Methods("GET")
+
+	return r
+}
+
+// Start is a blocking event that starts the debug server
+func (d *Debug) Start(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	logger.Info("debug server started", "address", d.addr)
+
+	if err := serve(d.Server, d.tls); err != nil && err != http.ErrServerClosed {
+		logger.Error("failed to listen on address", "address", d.addr, "error", err)
+	}
+}
+
+// Stop sends a termination signal to the debug server
+func (d *Debug) Stop(ctx context.Context) {
+	if err := d.Server.Shutdown(ctx); err != nil {
+		log.FromContext(ctx).Error("failed to gracefully shutdown the debug server", "error", err)
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/re-cinq/aether/pkg/efficiency"
+)
+
+// efficiencyHandler returns the efficiency of the compute instances over a
+// period and the period of the same length before, grouped by a field or a
+// label, the provider by default
+func (a *API) efficiencyHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	from, to, err := parsePeriod(q, a.store.Location())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	groupBy := q.Get("groupBy")
+	if groupBy == "" {
+		groupBy = "provider"
+	}
+
+	samples := a.store.Select(from.Add(-to.Sub(from)), to, nil)
+	writeJSON(w, http.StatusOK, efficiency.Compute(samples, from, to, groupBy))
+}
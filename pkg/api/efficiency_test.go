@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/efficiency"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEfficiencyHandler(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, sample := range []store.Sample{
+		{Time: day.Add(time.Hour), Provider: v1.AWS, Name: "i-a", Labels: v1.Labels{"team": "data"}, Operational: 100, VCPU: 4, Utilization: 25, Hours: 1},
+		{Time: day.Add(time.Hour), Provider: v1.AWS, Name: "i-b", Labels: v1.Labels{"team": "web"}, Operational: 30, VCPU: 2, Utilization: 50, Hours: 1},
+		// the month before
+		{Time: day.AddDate(0, -1, 0), Provider: v1.AWS, Name: "i-a", Labels: v1.Labels{"team": "data"}, Operational: 60, VCPU: 4, Utilization: 10, Hours: 1},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	a := &API{}
+	WithStore(s)(a)
+
+	tests := []struct {
+		name   string
+		query  string
+		code   int
+		groups []string
+		total  float64
+	}{
+		{name: "by provider", query: "from=2024-01-01&to=2024-01-31", code: http.StatusOK, groups: []string{"aws"}, total: 130},
+		{name: "by label", query: "from=2024-01-01&to=2024-01-31&groupBy=team", code: http.StatusOK, groups: []string{"data", "web"}, total: 130},
+		{name: "missing period", query: "groupBy=team", code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			w := httptest.NewRecorder()
+			a.efficiencyHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/efficiency?"+test.query, http.NoBody))
+			assert.Equal(test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var v efficiency.View
+			assert.NoError(json.Unmarshal(w.Body.Bytes(), &v))
+
+			groups := make([]string, 0, len(v.Rows))
+			for _, row := range v.Rows {
+				groups = append(groups, row.Group)
+			}
+			assert.Equal(test.groups, groups)
+			assert.Equal(test.total, v.Total.Emissions)
+			assert.Equal(6.0, v.Total.VCPUHours)
+			assert.InDelta(100.0/3, v.Total.Utilization, 1e-9)
+
+			// the period of the same length before
+			assert.Equal(60.0, v.Previous.Emissions)
+			assert.Equal(10.0, v.Previous.Utilization)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/re-cinq/aether/pkg/units"
+)
+
+// The utilization used when none is set, CI jobs mostly keep their runner
+// busy
+const defaultUtilization = 50
+
+// estimateResponse is the body returned by the estimate endpoint as JSON
+type estimateResponse struct {
+	*calculator.Estimate
+
+	// The unit of the emissions
+	Unit units.Unit `json:"unit"`
+}
+
+// estimateHandler estimates the emissions of an instance type over a
+// duration, e.g. a CI runner over the duration of a job. The estimate is
+// returned as JSON or formatted for the CI systems:
+//   - github: name=value lines to append to $GITHUB_OUTPUT
+//   - gitlab: a metrics report for artifacts:reports:metrics
+//   - markdown: a table to append to $GITHUB_STEP_SUMMARY or a comment
+func (a *API) estimateHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	r := calculator.EstimateRequest{
+		Provider:    v1.Provider(q.Get("provider")),
+		Kind:        q.Get("type"),
+		Region:      q.Get("region"),
+		Utilization: defaultUtilization,
+	}
+	if r.Provider == "" || r.Kind == "" || r.Region == "" {
+		writeError(w, http.StatusBadRequest, errors.New("the provider, type and region are required"))
+		return
+	}
+
+	var err error
+	if r.Duration, err = store.ParseDuration(q.Get("duration")); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if u := q.Get("utilization"); u != "" {
+		r.Utilization, err = strconv.ParseFloat(u, 64)
+		if err != nil || r.Utilization < 0 || r.Utilization > 100 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid utilization %q, must be between 0 and 100", u))
+			return
+		}
+	}
+
+	format := q.Get("format")
+	switch format {
+	case "", "json", "github", "gitlab", "markdown":
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown format %q", format))
+		return
+	}
+
+	e, err := a.estimate(req.Context(), &r)
+	switch v1.Code(err) {
+	case v1.CodeKindNotFound, v1.CodeRegionNotFound:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	e.Operational = a.output.Convert(e.Operational)
+	e.Embodied = a.output.Convert(e.Embodied)
+	e.Total = a.output.Convert(e.Total)
+	unit := a.output.Unit()
+
+	switch format {
+	case "github":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "operational=%.4f\nembodied=%.4f\ntotal=%.4f\n", e.Operational, e.Embodied, e.Total)
+	case "gitlab":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		// the unit is the suffix of the metric names
+		suffix := strings.ToLower(string(unit))
+		fmt.Fprintf(w, "emissions_operational_%s %g\nemissions_embodied_%s %g\nemissions_total_%s %g\n",
+			suffix, e.Operational, suffix, e.Embodied, suffix, e.Total)
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		fmt.Fprintf(w, "| Instance | Region | Duration | Operational | Embodied | Total |\n")
+		fmt.Fprintf(w, "| --- | --- | --- | --- | --- | --- |\n")
+		fmt.Fprintf(w, "| %s %s | %s | %s | %.2f %s | %.2f %s | %.2f %s |\n",
+			e.Provider, e.Kind, e.Region, e.Duration, e.Operational, unit, e.Embodied, unit, e.Total, unit)
+	default:
+		writeJSON(w, http.StatusOK, estimateResponse{Estimate: e, Unit: unit})
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/units"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateHandler(t *testing.T) {
+	a := &API{
+		estimate: func(ctx context.Context, req *calculator.EstimateRequest) (*calculator.Estimate, error) {
+			if req.Kind != "m5.xlarge" {
+				return nil, calculator.ErrUnknownKind
+			}
+			return &calculator.Estimate{
+				Provider:    req.Provider,
+				Kind:        req.Kind,
+				Region:      req.Region,
+				Utilization: req.Utilization,
+				Duration:    req.Duration,
+				Operational: 9,
+				Embodied:    6,
+				Total:       15,
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		body  string
+	}{
+		{
+			name:  "json",
+			query: "provider=aws&type=m5.xlarge&region=eu-west-1&duration=15m",
+			code:  http.StatusOK,
+			body:  `"utilization":50,"duration":900000000000`,
+		},
+		{
+			name:  "json unit",
+			query: "provider=aws&type=m5.xlarge&region=eu-west-1&duration=15m",
+			code:  http.StatusOK,
+			body:  `"total":15,"unit":"gCO2e"`,
+		},
+		{
+			name:  "github",
+			query: "provider=aws&type=m5.xlarge&region=eu-west-1&duration=15m&format=github",
+			code:  http.StatusOK,
+			body:  "operational=9.0000\nembodied=6.0000\ntotal=15.0000\n",
+		},
+		{
+			name:  "gitlab",
+			query: "provider=aws&type=m5.xlarge&region=eu-west-1&duration=15m&format=gitlab",
+			code:  http.StatusOK,
+			body:  "emissions_total_gco2e 15\n",
+		},
+		{
+			name:  "markdown",
+			query: "provider=aws&type=m5.xlarge&region=eu-west-1&duration=1h&utilization=80&format=markdown",
+			code:  http.StatusOK,
+			body:  "| aws m5.xlarge | eu-west-1 | 1h0m0s | 9.00 gCO2e | 6.00 gCO2e | 15.00 gCO2e |",
+		},
+		{
+			name:  "missing region",
+			query: "provider=aws&type=m5.xlarge&duration=15m",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "invalid utilization",
+			query: "provider=aws&type=m5.xlarge&region=eu-west-1&duration=15m&utilization=200",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "unknown type",
+			query: "provider=aws&type=m5.huge&region=eu-west-1&duration=15m",
+			code:  http.StatusBadRequest,
+			body:  `"code":"kind_not_found"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			w := httptest.NewRecorder()
+			a.estimateHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/estimate?"+test.query, http.NoBody))
+			assert.Equal(test.code, w.Code, w.Body.String())
+			assert.Contains(w.Body.String(), test.body)
+		})
+	}
+
+	// the emissions are converted to the unit of the output
+	assert := require.New(t)
+	precision := 3
+	var err error
+	a.output, err = units.New(&config.OutputConfig{Unit: "kgCO2e", Precision: &precision})
+	assert.NoError(err)
+
+	w := httptest.NewRecorder()
+	a.estimateHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/estimate?provider=aws&type=m5.xlarge&region=eu-west-1&duration=15m&format=gitlab", http.NoBody))
+	assert.Equal("emissions_operational_kgco2e 0.009\nemissions_embodied_kgco2e 0.006\nemissions_total_kgco2e 0.015\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	a.estimateHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/estimate?provider=aws&type=m5.xlarge&region=eu-west-1&duration=15m", http.NoBody))
+	assert.Contains(w.Body.String(), `"total":0.015,"unit":"kgCO2e"`)
+}
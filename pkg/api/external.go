@@ -0,0 +1,199 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+)
+
+// The Kubernetes external metrics API, served through the API aggregation
+// so that the HPA and KEDA can scale on the emissions
+const externalMetricsPath = "/apis/external.metrics.k8s.io/v1beta1"
+
+// The metrics served by the external metrics API
+const (
+	// The grid intensity of a region, the selector must set the provider
+	// and the region
+	gridIntensityMetric = "grid_intensity"
+
+	// The emissions of the pods of the namespace of the request, the
+	// selector matches the pod labels
+	namespaceEmissionsMetric = "namespace_emissions"
+
+	// The latest emissions of the instances matched by the selector
+	emissionsMetric = "emissions"
+)
+
+// externalMetricValue is a value of an external metric
+type externalMetricValue struct {
+	MetricName   string            `json:"metricName"`
+	MetricLabels map[string]string `json:"metricLabels"`
+	Timestamp    metav1.Time       `json:"timestamp"`
+	Value        resource.Quantity `json:"value"`
+}
+
+// externalMetricValueList is the body returned for an external metric
+type externalMetricValueList struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        metav1.ListMeta       `json:"metadata"`
+	Items           []externalMetricValue `json:"items"`
+}
+
+// externalMetricsRouter serves the discovery and the values of the
+// external metrics
+func (a *API) externalMetricsRouter(r *mux.Router) {
+	r.HandleFunc(externalMetricsPath, a.externalMetricsDiscovery).Methods("GET")
+	r.HandleFunc(externalMetricsPath+"/namespaces/{namespace}/{metric}", a.externalMetricHandler).Methods("GET")
+}
+
+// externalMetricNames returns the metrics that can be served
+func (a *API) externalMetricNames() []string {
+	metrics := []string{gridIntensityMetric}
+	if a.pods != nil {
+		metrics = append(metrics, namespaceEmissionsMetric)
+	}
+	if a.store != nil {
+		metrics = append(metrics, emissionsMetric)
+	}
+	return metrics
+}
+
+// externalMetricsDiscovery lists the served metrics, as expected by the
+// API aggregation
+func (a *API) externalMetricsDiscovery(w http.ResponseWriter, req *http.Request) {
+	list := metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: "external.metrics.k8s.io/v1beta1",
+	}
+
+	for _, m := range a.externalMetricNames() {
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:       m,
+			Namespaced: true,
+			Kind:       "ExternalMetricValueList",
+			Verbs:      metav1.Verbs{"get"},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// externalMetricHandler returns the values of an external metric
+func (a *API) externalMetricHandler(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+
+	selector, err := k8slabels.Parse(req.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid label selector: %w", err))
+		return
+	}
+
+	var items []externalMetricValue
+	switch metric := vars["metric"]; {
+	case metric == gridIntensityMetric:
+		items, err = a.gridIntensityValues(selector)
+	case metric == namespaceEmissionsMetric && a.pods != nil:
+		items = a.namespaceEmissionsValues(vars["namespace"], selector)
+	case metric == emissionsMetric && a.store != nil:
+		items = a.emissionsValues(selector)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown metric %s", metric))
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, externalMetricValueList{
+		TypeMeta: metav1.TypeMeta{Kind: "ExternalMetricValueList", APIVersion: "external.metrics.k8s.io/v1beta1"},
+		Items:    items,
+	})
+}
+
+// gridIntensityValues returns the grid intensity of the selected region
+func (a *API) gridIntensityValues(selector k8slabels.Selector) ([]externalMetricValue, error) {
+	provider, ok := selector.RequiresExactMatch("provider")
+	if !ok {
+		return nil, fmt.Errorf("the selector of %s must match the provider", gridIntensityMetric)
+	}
+
+	region, ok := selector.RequiresExactMatch("region")
+	if !ok {
+		return nil, fmt.Errorf("the selector of %s must match the region", gridIntensityMetric)
+	}
+
+	intensity, err := a.intensity(v1.Provider(provider), region)
+	if err != nil {
+		return nil, err
+	}
+
+	return []externalMetricValue{
+		newExternalMetricValue(gridIntensityMetric, map[string]string{"provider": provider, "region": region}, intensity),
+	}, nil
+}
+
+// namespaceEmissionsValues returns the emissions of the selected pods of
+// the namespace
+func (a *API) namespaceEmissionsValues(namespace string, selector k8slabels.Selector) []externalMetricValue {
+	var total float64
+	for _, p := range a.pods.Pods() {
+		if p.Namespace == namespace && selector.Matches(k8slabels.Set(p.Labels)) {
+			total += p.Emissions
+		}
+	}
+
+	return []externalMetricValue{
+		newExternalMetricValue(namespaceEmissionsMetric, map[string]string{"namespace": namespace}, total),
+	}
+}
+
+// emissionsValues returns the latest emissions of the selected instances
+func (a *API) emissionsValues(selector k8slabels.Selector) []externalMetricValue {
+	var total float64
+	for _, s := range a.store.Latest(func(s *store.Sample) bool {
+		return selector.Matches(sampleLabels{s})
+	}) {
+		total += s.Operational + s.Embodied
+	}
+
+	return []externalMetricValue{
+		newExternalMetricValue(emissionsMetric, map[string]string{}, total),
+	}
+}
+
+// newExternalMetricValue returns the value of a metric, with a milli
+// precision
+func newExternalMetricValue(name string, metricLabels map[string]string, value float64) externalMetricValue {
+	return externalMetricValue{
+		MetricName:   name,
+		MetricLabels: metricLabels,
+		Timestamp:    metav1.NewTime(time.Now()),
+		Value:        *resource.NewMilliQuantity(int64(math.Round(value*1000)), resource.DecimalSI),
+	}
+}
+
+// sampleLabels matches the label selectors with the fields and the labels
+// of a sample
+type sampleLabels struct {
+	*store.Sample
+}
+
+// Has returns whether the sample has a value for the label
+func (s sampleLabels) Has(label string) bool {
+	return s.Label(label) != ""
+}
+
+// Get returns the value of the label
+func (s sampleLabels) Get(label string) string {
+	return s.Label(label)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/re-cinq/aether/pkg/attribution"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePods []attribution.Pod
+
+func (p fakePods) Pods() []attribution.Pod        { return p }
+func (p fakePods) Overhead() attribution.Overhead { return attribution.Overhead{} }
+func (p fakePods) Jobs() []attribution.Job        { return nil }
+
+func TestExternalMetrics(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+	assert.NoError(s.Add(ctx, store.Sample{Provider: v1.AWS, Name: "a", Region: "eu-west-1", Operational: 1.5, Embodied: 0.25}))
+	assert.NoError(s.Add(ctx, store.Sample{Provider: v1.AWS, Name: "b", Region: "us-east-1", Operational: 4}))
+
+	a := &API{
+		store: s,
+		pods: fakePods{
+			{Namespace: "web", Name: "a", Labels: v1.Labels{"label_app": "shop"}, Emissions: 1},
+			{Namespace: "web", Name: "b", Labels: v1.Labels{"label_app": "blog"}, Emissions: 2},
+			{Namespace: "batch", Name: "c", Emissions: 4},
+		},
+		intensity: func(provider v1.Provider, region string) (float64, error) {
+			return 215.5, nil
+		},
+	}
+
+	r := mux.NewRouter()
+	a.externalMetricsRouter(r)
+
+	tests := []struct {
+		name   string
+		path   string
+		code   int
+		metric string
+		value  string
+	}{
+		{
+			name:   "grid intensity",
+			path:   "/namespaces/default/grid_intensity?labelSelector=provider%3Daws,region%3Deu-west-1",
+			code:   http.StatusOK,
+			metric: "grid_intensity",
+			value:  "215500m",
+		},
+		{
+			name: "grid intensity without region",
+			path: "/namespaces/default/grid_intensity?labelSelector=provider%3Daws",
+			code: http.StatusBadRequest,
+		},
+		{
+			name:   "namespace emissions",
+			path:   "/namespaces/web/namespace_emissions",
+			code:   http.StatusOK,
+			metric: "namespace_emissions",
+			value:  "3",
+		},
+		{
+			name:   "namespace emissions of selected pods",
+			path:   "/namespaces/web/namespace_emissions?labelSelector=label_app%3Dshop",
+			code:   http.StatusOK,
+			metric: "namespace_emissions",
+			value:  "1",
+		},
+		{
+			name:   "emissions",
+			path:   "/namespaces/default/emissions?labelSelector=region%3Deu-west-1",
+			code:   http.StatusOK,
+			metric: "emissions",
+			value:  "1750m",
+		},
+		{
+			name: "invalid selector",
+			path: "/namespaces/default/emissions?labelSelector=%3D%3D",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown metric",
+			path: "/namespaces/default/cpu",
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, externalMetricsPath+test.path, http.NoBody))
+			assert.Equal(test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var list struct {
+				Kind  string `json:"kind"`
+				Items []struct {
+					MetricName string `json:"metricName"`
+					Value      string `json:"value"`
+				} `json:"items"`
+			}
+			assert.NoError(json.Unmarshal(w.Body.Bytes(), &list))
+			assert.Equal("ExternalMetricValueList", list.Kind)
+			assert.Len(list.Items, 1)
+			assert.Equal(test.metric, list.Items[0].MetricName)
+			assert.Equal(test.value, list.Items[0].Value)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, externalMetricsPath, http.NoBody))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Contains(w.Body.String(), `"name":"namespace_emissions"`)
+}
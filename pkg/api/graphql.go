@@ -0,0 +1,333 @@
+package api
+
+import (
+	_ "embed"
+	"sort"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// graphQLSchema is the GraphQL schema served on /api/graphql
+//
+//go:embed schema.graphql
+var graphQLSchema string
+
+// graphQLHandler returns the handler of the GraphQL API, over the same data
+// as the REST API
+// NOTE: the schema is embedded, so it panics only if it's invalid
+func (a *API) graphQLHandler() *relay.Handler {
+	schema := graphql.MustParseSchema(
+		graphQLSchema,
+		&queryResolver{api: a},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(10),
+	)
+
+	return &relay.Handler{Schema: schema}
+}
+
+// queryResolver resolves the root queries
+type queryResolver struct {
+	api *API
+}
+
+type labelInput struct {
+	Key   string
+	Value string
+}
+
+type instancesArgs struct {
+	Provider *string
+	Service  *string
+	Region   *string
+	Zone     *string
+	Kind     *string
+	Labels   *[]labelInput
+	Sort     *string
+	First    *int32
+	After    *string
+}
+
+// Instances lists the latest emissions of every instance
+func (q *queryResolver) Instances(args instancesArgs) (*instanceConnection, error) {
+	var matchers []store.Matcher
+	for label, value := range map[string]*string{
+		"provider": args.Provider,
+		"service":  args.Service,
+		"region":   args.Region,
+		"zone":     args.Zone,
+		"kind":     args.Kind,
+	} {
+		if value != nil {
+			matchers = append(matchers, store.Matcher{Label: label, Value: *value})
+		}
+	}
+	if args.Labels != nil {
+		for _, l := range *args.Labels {
+			matchers = append(matchers, store.Matcher{Label: l.Key, Value: l.Value})
+		}
+	}
+
+	var by string
+	if args.Sort != nil {
+		by = *args.Sort
+	}
+	less, err := sortInstances(by)
+	if err != nil {
+		return nil, err
+	}
+
+	p := page{limit: defaultPageSize}
+	if args.First != nil {
+		if *args.First < 1 {
+			return nil, errInvalidPage
+		}
+		p.limit = min(int(*args.First), maxPageSize)
+	}
+	if args.After != nil {
+		if p.offset, err = decodeCursor(*args.After); err != nil {
+			return nil, err
+		}
+	}
+
+	samples := q.api.store.Latest(func(s *store.Sample) bool {
+		for i := range matchers {
+			if !matchers[i].Matches(s) {
+				return false
+			}
+		}
+		return true
+	})
+	sort.Slice(samples, func(i, j int) bool {
+		return less(&samples[i], &samples[j])
+	})
+
+	items, next := paginate(samples, p)
+
+	c := &instanceConnection{Items: make([]*instanceResolver, 0, len(items))}
+	for i := range items {
+		c.Items = append(c.Items, &instanceResolver{api: q.api, sample: items[i]})
+	}
+	if next != "" {
+		c.Next = &next
+	}
+
+	return c, nil
+}
+
+// Instance returns the latest emissions of an instance
+func (q *queryResolver) Instance(args struct{ Provider, Name string }) *instanceResolver {
+	samples := q.api.store.Latest(func(s *store.Sample) bool {
+		return s.Provider.String() == args.Provider && s.Name == args.Name
+	})
+	if len(samples) == 0 {
+		return nil
+	}
+
+	return &instanceResolver{api: q.api, sample: samples[0]}
+}
+
+// Aggregate runs a query over the stored emissions
+func (q *queryResolver) Aggregate(args struct{ Query string }) ([]*aggregateResult, error) {
+	query, err := store.ParseQuery(args.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := q.api.store.Query(query)
+	out := make([]*aggregateResult, 0, len(results))
+	for _, r := range results {
+		out = append(out, &aggregateResult{
+			Labels: labels(r.Labels),
+			Value:  r.Value,
+		})
+	}
+
+	return out, nil
+}
+
+// Status returns the status of the scraping of every account
+func (q *queryResolver) Status() []*scrapeStatus {
+	if q.api.status == nil {
+		return []*scrapeStatus{}
+	}
+
+	statuses := q.api.status.Status()
+	out := make([]*scrapeStatus, 0, len(statuses))
+	for _, s := range statuses {
+		out = append(out, &scrapeStatus{
+			Provider:            s.Provider.String(),
+			Account:             s.Account,
+			Degraded:            s.Degraded,
+			LastAttempt:         graphql.Time{Time: s.LastAttempt},
+			LastSuccess:         graphql.Time{Time: s.LastSuccess},
+			LastDuration:        s.LastDuration.Seconds(),
+			Instances:           int32(s.Instances),
+			ConsecutiveFailures: int32(s.ConsecutiveFailures),
+			LastError:           optional(s.LastError),
+		})
+	}
+
+	return out
+}
+
+type instanceConnection struct {
+	Items []*instanceResolver
+	Next  *string
+}
+
+// instanceResolver resolves the fields of an instance, including the nested
+// history and breakdown
+type instanceResolver struct {
+	api    *API
+	sample store.Sample
+}
+
+func (i *instanceResolver) Provider() string     { return i.sample.Provider.String() }
+func (i *instanceResolver) Service() string      { return i.sample.Service }
+func (i *instanceResolver) Name() string         { return i.sample.Name }
+func (i *instanceResolver) Region() string       { return i.sample.Region }
+func (i *instanceResolver) Zone() string         { return i.sample.Zone }
+func (i *instanceResolver) Kind() string         { return i.sample.Kind }
+func (i *instanceResolver) Labels() []*label     { return labels(i.sample.Labels) }
+func (i *instanceResolver) Time() graphql.Time   { return graphql.Time{Time: i.sample.Time} }
+func (i *instanceResolver) Operational() float64 { return i.sample.Operational }
+func (i *instanceResolver) Embodied() float64    { return i.sample.Embodied }
+func (i *instanceResolver) Emissions() float64 {
+	return i.sample.Operational + i.sample.Embodied
+}
+
+// History returns the samples of the instance over the range
+func (i *instanceResolver) History(args struct{ Range *string }) ([]*sample, error) {
+	r := "24h"
+	if args.Range != nil {
+		r = *args.Range
+	}
+
+	d, err := store.ParseDuration(r)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	samples := i.api.store.Select(now.Add(-d), now, func(s *store.Sample) bool {
+		return s.Provider == i.sample.Provider && s.Name == i.sample.Name
+	})
+
+	out := make([]*sample, 0, len(samples))
+	for _, s := range samples {
+		out = append(out, &sample{
+			Time:        graphql.Time{Time: s.Time},
+			Operational: s.Operational,
+			Embodied:    s.Embodied,
+		})
+	}
+
+	return out, nil
+}
+
+// Breakdown returns the latest calculation of the instance
+func (i *instanceResolver) Breakdown() *breakdown {
+	if i.api.calculations == nil {
+		return nil
+	}
+
+	b, err := i.api.calculations.Breakdown(i.sample.Provider, i.sample.Labels[v1.AccountLabel], i.sample.Name)
+	if err != nil {
+		return nil
+	}
+
+	out := &breakdown{
+		CalculatedAt:         graphql.Time{Time: b.CalculatedAt},
+		GridCO2e:             b.GridCO2e,
+		PUE:                  b.PUE,
+		VCPU:                 b.VCPU,
+		EmbodiedHourlyFactor: b.EmbodiedFactor,
+		Embodied:             b.Embodied,
+	}
+
+	for _, m := range b.Metrics {
+		out.Metrics = append(out.Metrics, &metricBreakdown{
+			Name:      m.Name,
+			Usage:     m.Usage,
+			Emissions: m.Emissions,
+			Steps:     m.Steps,
+			Error:     optional(m.Error),
+		})
+	}
+
+	return out
+}
+
+type label struct {
+	Key   string
+	Value string
+}
+
+type sample struct {
+	Time        graphql.Time
+	Operational float64
+	Embodied    float64
+}
+
+type aggregateResult struct {
+	Labels []*label
+	Value  float64
+}
+
+type breakdown struct {
+	CalculatedAt         graphql.Time
+	GridCO2e             float64
+	PUE                  float64
+	VCPU                 float64
+	EmbodiedHourlyFactor float64
+	Metrics              []*metricBreakdown
+	Embodied             calculator.Step
+}
+
+type metricBreakdown struct {
+	Name      string
+	Usage     float64
+	Emissions float64
+	Steps     []calculator.Step
+	Error     *string
+}
+
+type scrapeStatus struct {
+	Provider            string
+	Account             string
+	Degraded            bool
+	LastAttempt         graphql.Time
+	LastSuccess         graphql.Time
+	LastDuration        float64
+	Instances           int32
+	ConsecutiveFailures int32
+	LastError           *string
+}
+
+// labels converts the labels to a list sorted by key
+func labels(l v1.Labels) []*label {
+	out := make([]*label, 0, len(l))
+	for k, v := range l {
+		out = append(out, &label{Key: k, Value: v})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Key < out[j].Key
+	})
+
+	return out
+}
+
+// optional returns nil for empty strings
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQL(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	now := time.Now().UTC()
+	for _, sample := range []store.Sample{
+		{Time: now.Add(-2 * time.Hour), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "x"}, Operational: 1},
+		{Time: now.Add(-time.Hour), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "x"}, Operational: 2},
+		{Time: now.Add(-time.Hour), Provider: v1.GCP, Name: "b", Labels: v1.Labels{"team": "y"}, Operational: 5},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	h := (&API{store: s}).graphQLHandler()
+
+	resp := h.Schema.Exec(ctx, `{
+		instances(labels: [{key: "team", value: "x"}]) {
+			items { name emissions history(range: "1d") { operational } }
+			next
+		}
+		aggregate(query: "sum(emissions) by (team)") { labels { key value } value }
+	}`, "", nil)
+	assert.Empty(resp.Errors)
+
+	var data struct {
+		Instances struct {
+			Items []struct {
+				Name      string
+				Emissions float64
+				History   []struct{ Operational float64 }
+			}
+			Next *string
+		}
+		Aggregate []struct {
+			Labels []struct{ Key, Value string }
+			Value  float64
+		}
+	}
+	assert.NoError(json.Unmarshal(resp.Data, &data))
+
+	assert.Len(data.Instances.Items, 1)
+	assert.Equal("a", data.Instances.Items[0].Name)
+	assert.Equal(2.0, data.Instances.Items[0].Emissions)
+	assert.Len(data.Instances.Items[0].History, 2)
+	assert.Nil(data.Instances.Next)
+
+	assert.Len(data.Aggregate, 2)
+	assert.Equal(5.0, data.Aggregate[0].Value)
+	assert.Equal("y", data.Aggregate[0].Labels[0].Value)
+}
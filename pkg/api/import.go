@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The limits of an import request
+const (
+	maxImportBytes   = 10 << 20
+	maxImportRecords = 10000
+)
+
+// defaultImportSource is the source of the records without one
+const defaultImportSource = "import"
+
+// emissionsWriter records the imported emissions
+type emissionsWriter interface {
+	Add(ctx context.Context, sample store.Sample) error
+}
+
+// importRequest is the body of the import endpoint
+type importRequest struct {
+	Records []importRecord `json:"records"`
+}
+
+// importRecord is the emissions or the energy of a resource computed by a
+// third party over a period ending at its time
+type importRecord struct {
+	Time     time.Time   `json:"time"`
+	Source   string      `json:"source"`
+	Provider v1.Provider `json:"provider"`
+	Service  string      `json:"service"`
+	Name     string      `json:"name"`
+	Region   string      `json:"region"`
+	Zone     string      `json:"zone"`
+	Kind     string      `json:"kind"`
+	Labels   v1.Labels   `json:"labels"`
+
+	// The emissions in gCO2eq, the operational ones are calculated from the
+	// energy when unset
+	Operational *float64 `json:"operational"`
+	Embodied    float64  `json:"embodied"`
+
+	// The energy in kWh and the grid intensity in gCO2eq/kWh, the one of
+	// the region of the provider when unset
+	Energy        float64 `json:"energy"`
+	GridIntensity float64 `json:"gridIntensity"`
+}
+
+// importResponse is the body returned by the import endpoint
+type importResponse struct {
+	Imported int `json:"imported"`
+}
+
+// importHandler stores the emissions computed by third parties, so that they
+// are queried and reported like the calculated ones. The records are all
+// validated before any of them is stored
+func (a *API) importHandler(w http.ResponseWriter, req *http.Request) {
+	var body importRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxImportBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid import: %w", err))
+		return
+	}
+
+	if len(body.Records) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no records to import"))
+		return
+	}
+	if len(body.Records) > maxImportRecords {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%d records exceed the maximum of %d per request", len(body.Records), maxImportRecords))
+		return
+	}
+
+	samples := make([]store.Sample, 0, len(body.Records))
+	for i := range body.Records {
+		s, err := a.importSample(&body.Records[i])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("record %d: %w", i, err))
+			return
+		}
+		samples = append(samples, s)
+	}
+
+	for i := range samples {
+		if err := a.imports.Add(req.Context(), samples[i]); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("failed storing record %d: %w", i, err))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, importResponse{Imported: len(samples)})
+}
+
+// importSample validates the record and returns its sample
+func (a *API) importSample(r *importRecord) (store.Sample, error) {
+	switch {
+	case r.Time.IsZero():
+		return store.Sample{}, errors.New("missing time")
+	case r.Provider == "":
+		return store.Sample{}, errors.New("missing provider")
+	case r.Name == "":
+		return store.Sample{}, errors.New("missing name")
+	case r.Operational == nil && r.Energy == 0:
+		return store.Sample{}, errors.New("missing operational emissions or energy")
+	}
+
+	for _, v := range []float64{r.Embodied, r.Energy, r.GridIntensity} {
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return store.Sample{}, fmt.Errorf("invalid value %g", v)
+		}
+	}
+
+	// the intensity is only known when it converts the energy
+	var operational, intensity float64
+	if r.Operational != nil {
+		operational = *r.Operational
+		if operational < 0 || math.IsNaN(operational) || math.IsInf(operational, 0) {
+			return store.Sample{}, fmt.Errorf("invalid operational emissions %g", operational)
+		}
+	} else {
+		intensity = r.GridIntensity
+		if intensity == 0 {
+			var err error
+			intensity, err = a.intensity(r.Provider, r.Region)
+			if err != nil {
+				return store.Sample{}, fmt.Errorf("no grid intensity for the energy: %w", err)
+			}
+		}
+		operational = r.Energy * intensity
+	}
+
+	source := r.Source
+	if source == "" {
+		source = defaultImportSource
+	}
+
+	return store.Sample{
+		Time:        r.Time.UTC(),
+		Provider:    r.Provider,
+		Service:     r.Service,
+		Name:        r.Name,
+		Region:      r.Region,
+		Zone:        r.Zone,
+		Kind:        r.Kind,
+		Labels:      r.Labels.With(v1.SourceLabel, source),
+		Operational: operational,
+		Embodied:    r.Embodied,
+
+		GridIntensity: intensity,
+	}, nil
+}
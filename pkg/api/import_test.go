@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportHandler(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		body     string
+		code     int
+		expected []store.Sample
+	}{
+		{
+			name: "emissions and energy",
+			body: `{"records": [
+				{"time": "2024-01-31T01:00:00Z", "source": "snowflake", "provider": "aws", "service": "warehouse", "name": "ANALYTICS", "region": "eu-west-1", "labels": {"team": "data"}, "operational": 120, "embodied": 30},
+				{"time": "2024-01-31T01:00:00+01:00", "provider": "aws", "name": "vendor-a", "region": "eu-west-1", "energy": 2},
+				{"time": "2024-01-31T01:00:00Z", "provider": "gcp", "name": "cluster-0", "energy": 0.5, "gridIntensity": 300}
+			]}`,
+			code: http.StatusOK,
+			expected: []store.Sample{
+				{Time: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), Provider: v1.AWS, Name: "vendor-a", Region: "eu-west-1", Labels: v1.Labels{v1.SourceLabel: "import"}, Operational: 200, GridIntensity: 100},
+				{Time: time.Date(2024, 1, 31, 1, 0, 0, 0, time.UTC), Provider: v1.AWS, Service: "warehouse", Name: "ANALYTICS", Region: "eu-west-1", Labels: v1.Labels{"team": "data", v1.SourceLabel: "snowflake"}, Operational: 120, Embodied: 30},
+				{Time: time.Date(2024, 1, 31, 1, 0, 0, 0, time.UTC), Provider: v1.GCP, Name: "cluster-0", Labels: v1.Labels{v1.SourceLabel: "import"}, Operational: 150, GridIntensity: 300},
+			},
+		},
+		{name: "missing emissions", body: `{"records": [{"time": "2024-01-31T01:00:00Z", "provider": "aws", "name": "a"}]}`, code: http.StatusBadRequest},
+		{name: "unknown provider", body: `{"records": [{"time": "2024-01-31T01:00:00Z", "provider": "snowflake", "name": "a", "operational": 1}]}`, code: http.StatusBadRequest},
+		{name: "negative emissions", body: `{"records": [{"time": "2024-01-31T01:00:00Z", "provider": "aws", "name": "a", "operational": -1}]}`, code: http.StatusBadRequest},
+		{name: "unknown region", body: `{"records": [{"time": "2024-01-31T01:00:00Z", "provider": "aws", "name": "a", "region": "moon-1", "energy": 1}]}`, code: http.StatusBadRequest},
+		{name: "unknown field", body: `{"records": [{"time": "2024-01-31T01:00:00Z", "provider": "aws", "name": "a", "co2": 1}]}`, code: http.StatusBadRequest},
+		{name: "no records", body: `{"records": []}`, code: http.StatusBadRequest},
+		// nothing is stored when a record is invalid
+		{
+			name: "invalid record",
+			body: `{"records": [
+				{"time": "2024-01-31T01:00:00Z", "provider": "aws", "name": "a", "operational": 1},
+				{"provider": "aws", "name": "b", "operational": 1}
+			]}`,
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			s, err := store.New(ctx, &config.StoreConfig{})
+			assert.NoError(err)
+
+			a := &API{
+				intensity: func(provider v1.Provider, region string) (float64, error) {
+					if region != "eu-west-1" {
+						return 0, v1.ErrRegionNotFound
+					}
+					return 100, nil
+				},
+			}
+			WithImports(s)(a)
+
+			w := httptest.NewRecorder()
+			a.importHandler(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/import", strings.NewReader(test.body)))
+			assert.Equal(test.code, w.Code, w.Body.String())
+
+			samples := s.Select(time.Time{}, time.Now(), nil)
+			assert.Equal(test.expected, samples)
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Add(context.Context, store.Sample) error {
+	return errors.New("stream unavailable")
+}
+
+func TestImportHandlerFailure(t *testing.T) {
+	assert := require.New(t)
+
+	a := &API{}
+	WithImports(failingWriter{})(a)
+
+	w := httptest.NewRecorder()
+	a.importHandler(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/import",
+		strings.NewReader(`{"records": [{"time": "2024-01-31T01:00:00Z", "provider": "aws", "name": "a", "operational": 1}]}`)))
+	assert.Equal(http.StatusInternalServerError, w.Code)
+	assert.Contains(w.Body.String(), "stream unavailable")
+}
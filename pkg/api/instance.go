@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/re-cinq/aether/pkg/calculator"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// calculationReader returns the latest calculation of an instance, the
+// emission factors in use and the lookups missing from them, and calculates
+// with the custom regions of the config
+type calculationReader interface {
+	Breakdown(provider v1.Provider, account, name string) (calculator.Breakdown, error)
+	Dataset() calculator.Dataset
+	Misses() []calculator.Miss
+	GridIntensity(provider v1.Provider, region string) (float64, error)
+	EstimateEmissions(ctx context.Context, req *calculator.EstimateRequest) (*calculator.Estimate, error)
+}
+
+// instanceHandler returns the latest metrics of the instance and the
+// breakdown of its most recent emissions calculation, the provider and the
+// account query parameters select it when several instances have the name
+func (a *API) instanceHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	query := req.URL.Query()
+
+	breakdown, err := a.calculations.Breakdown(v1.Provider(query.Get("provider")), query.Get("account"), id)
+	switch {
+	case errors.Is(err, calculator.ErrAmbiguousInstance):
+		writeError(w, http.StatusConflict, fmt.Errorf("instance %s: %w", id, err))
+		return
+	case err != nil:
+		writeError(w, http.StatusNotFound, fmt.Errorf("instance %s: %w", id, err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, breakdown)
+}
+
+// datasetsResponse is the body returned by the datasets endpoint
+type datasetsResponse struct {
+	EmissionFactors calculator.Dataset `json:"emissionFactors"`
+}
+
+// datasetsHandler returns the versions of the datasets used by the
+// calculations
+func (a *API) datasetsHandler(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, datasetsResponse{
+		EmissionFactors: a.calculations.Dataset(),
+	})
+}
+
+// missesResponse is the body returned by the misses endpoint
+type missesResponse struct {
+	Misses []calculator.Miss `json:"misses"`
+}
+
+// missesHandler returns the instance kinds and regions missing from the
+// emission factors, their emissions aren't calculated
+func (a *API) missesHandler(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, missesResponse{
+		Misses: a.calculations.Misses(),
+	})
+}
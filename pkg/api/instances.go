@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/re-cinq/aether/pkg/store"
+	"github.com/re-cinq/aether/pkg/units"
+)
+
+// instancesResponse is the body returned by the instances endpoint
+type instancesResponse struct {
+	Instances []store.Sample `json:"instances"`
+
+	// The unit of the emissions
+	Unit units.Unit `json:"unit"`
+
+	// The cursor of the next page, empty on the last one
+	Next string `json:"next,omitempty"`
+}
+
+// The fields the instances can be filtered by
+var instanceFilters = []string{"provider", "service", "region", "zone", "kind", "quality"}
+
+// instancesHandler lists the latest emissions of every instance
+// The instances can be filtered by field (provider=aws) and label
+// (label=team=data), sorted by emissions or name and paginated
+func (a *API) instancesHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	p, err := parsePage(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var matchers []store.Matcher
+	for _, f := range instanceFilters {
+		if v := q.Get(f); v != "" {
+			matchers = append(matchers, store.Matcher{Label: f, Value: v})
+		}
+	}
+	for _, l := range q["label"] {
+		key, value, ok := strings.Cut(l, "=")
+		if !ok || key == "" {
+			writeError(w, http.StatusBadRequest, errors.New("labels must be formatted as key=value"))
+			return
+		}
+		matchers = append(matchers, store.Matcher{Label: key, Value: value})
+	}
+
+	less, err := sortInstances(q.Get("sort"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	instances := a.store.Latest(func(s *store.Sample) bool {
+		for i := range matchers {
+			if !matchers[i].Matches(s) {
+				return false
+			}
+		}
+		return true
+	})
+
+	sort.Slice(instances, func(i, j int) bool {
+		return less(&instances[i], &instances[j])
+	})
+
+	items, next := paginate(instances, p)
+	for i := range items {
+		items[i].Operational = a.output.Convert(items[i].Operational)
+		items[i].Embodied = a.output.Convert(items[i].Embodied)
+	}
+
+	writeJSON(w, http.StatusOK, instancesResponse{
+		Instances: items,
+		Unit:      a.output.Unit(),
+		Next:      next,
+	})
+}
+
+// sortInstances returns the ordering of the instances, the highest emissions
+// first by default. Ties are broken by provider and name, so that the order
+// is stable across pages
+func sortInstances(by string) (func(a, b *store.Sample) bool, error) {
+	tie := func(a, b *store.Sample) bool {
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Name < b.Name
+	}
+
+	emissions := func(s *store.Sample) float64 {
+		return s.Operational + s.Embodied
+	}
+
+	switch by {
+	case "", "-emissions":
+		return func(a, b *store.Sample) bool {
+			if emissions(a) != emissions(b) {
+				return emissions(a) > emissions(b)
+			}
+			return tie(a, b)
+		}, nil
+	case "emissions":
+		return func(a, b *store.Sample) bool {
+			if emissions(a) != emissions(b) {
+				return emissions(a) < emissions(b)
+			}
+			return tie(a, b)
+		}, nil
+	case "name":
+		return tie, nil
+	case "-name":
+		return func(a, b *store.Sample) bool {
+			return tie(b, a)
+		}, nil
+	default:
+		return nil, errors.New("sort must be one of emissions, -emissions, name or -name")
+	}
+}
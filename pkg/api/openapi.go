@@ -0,0 +1,23 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// The Go client is generated from the OpenAPI document, the TypeScript types
+// are generated by the CI
+//go:generate go run github.com/deepmap/oapi-codegen/v2/cmd/oapi-codegen@v2.1.0 -config oapi-codegen.yaml openapi.json
+
+// openAPISpec is the OpenAPI document describing the API
+// NOTE: it must be updated whenever a route is added or changed
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// openAPIHandler returns the OpenAPI document
+func openAPIHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openAPISpec)
+}
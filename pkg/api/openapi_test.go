@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/re-cinq/aether/pkg/attribution"
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/cost"
+	"github.com/re-cinq/aether/pkg/rightsizing"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct{}
+
+func (fakeBackend) Status() []v1.ScrapeStatus           { return nil }
+func (fakeBackend) Query(q *store.Query) []store.Result { return nil }
+func (fakeBackend) QueryRange(*store.Query, time.Time, time.Time, time.Duration) (*store.Matrix, error) {
+	return &store.Matrix{}, nil
+}
+func (fakeBackend) Select(time.Time, time.Time, func(*store.Sample) bool) []store.Sample {
+	return nil
+}
+func (fakeBackend) Latest(func(*store.Sample) bool) []store.Sample { return nil }
+func (fakeBackend) Location() *time.Location                       { return time.UTC }
+func (fakeBackend) Trigger(p v1.Provider, account string) error    { return nil }
+func (fakeBackend) FlushCaches()                                   {}
+func (fakeBackend) Add(context.Context, store.Sample) error        { return nil }
+func (fakeBackend) RefreshFactors(ctx context.Context) error       { return nil }
+func (fakeBackend) Dataset() calculator.Dataset                    { return calculator.Dataset{} }
+func (fakeBackend) Misses() []calculator.Miss                      { return nil }
+func (fakeBackend) GridIntensity(v1.Provider, string) (float64, error) {
+	return 0, calculator.ErrUnknownRegion
+}
+func (fakeBackend) EstimateEmissions(context.Context, *calculator.EstimateRequest) (*calculator.Estimate, error) {
+	return nil, calculator.ErrUnknownKind
+}
+func (fakeBackend) Pods() []attribution.Pod                { return nil }
+func (fakeBackend) Overhead() attribution.Overhead         { return attribution.Overhead{} }
+func (fakeBackend) Jobs() []attribution.Job                { return nil }
+func (fakeBackend) Costs(time.Time, time.Time) []cost.Cost { return nil }
+func (fakeBackend) Recommendations(v1.Provider) []rightsizing.Recommendation {
+	return nil
+}
+func (fakeBackend) Breakdown(v1.Provider, string, string) (calculator.Breakdown, error) {
+	return calculator.Breakdown{}, calculator.ErrBreakdownNotFound
+}
+
+// TestOpenAPIRoutes makes sure every route of the API is documented
+func TestOpenAPIRoutes(t *testing.T) {
+	assert := require.New(t)
+
+	var spec struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	assert.NoError(json.Unmarshal(openAPISpec, &spec))
+
+	a := &API{metricsPath: "/metrics", adminToken: "token", graphQL: true, ui: true, externalMetrics: true}
+	for _, opt := range []Option{
+		WithStatus(fakeBackend{}),
+		WithStore(fakeBackend{}),
+		WithCalculations(fakeBackend{}),
+		WithPods(fakeBackend{}),
+		WithScrapeController(fakeBackend{}),
+		WithFactorsRefresher(fakeBackend{}),
+		WithStatements(nil, "", ""),
+		WithCosts(fakeBackend{}),
+		WithImports(fakeBackend{}),
+		WithRightsizing(fakeBackend{}),
+	} {
+		opt(a)
+	}
+
+	err := a.router().Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || path == a.metricsPath {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			// subrouters have no methods
+			return nil
+		}
+
+		for _, m := range methods {
+			_, ok := spec.Paths[path][strings.ToLower(m)]
+			assert.True(ok, "%s %s is not documented", m, path)
+		}
+		return nil
+	})
+	assert.NoError(err)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// The amount of items returned when no limit is requested
+	defaultPageSize = 100
+
+	// The maximum amount of items returned at once
+	maxPageSize = 1000
+)
+
+// errInvalidPage is returned when the limit or the cursor are invalid
+var errInvalidPage = errors.New("invalid limit or cursor")
+
+// page is the part of a list requested by a client
+type page struct {
+	limit  int
+	offset int
+}
+
+// parsePage reads the limit and cursor query parameters
+func parsePage(req *http.Request) (page, error) {
+	p := page{limit: defaultPageSize}
+	q := req.URL.Query()
+
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			return page{}, errInvalidPage
+		}
+		p.limit = min(n, maxPageSize)
+	}
+
+	if c := q.Get("cursor"); c != "" {
+		n, err := decodeCursor(c)
+		if err != nil {
+			return page{}, err
+		}
+		p.offset = n
+	}
+
+	return p, nil
+}
+
+// decodeCursor returns the offset the cursor points to
+func decodeCursor(c string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return 0, errInvalidPage
+	}
+
+	n, err := strconv.Atoi(string(b))
+	if err != nil || n < 0 {
+		return 0, errInvalidPage
+	}
+
+	return n, nil
+}
+
+// paginate returns the requested page of the items and the cursor of the
+// next one, which is empty on the last page.
+// The items must be sorted the same way on every request
+func paginate[T any](items []T, p page) ([]T, string) {
+	if p.offset >= len(items) {
+		return []T{}, ""
+	}
+
+	end := min(p.offset+p.limit, len(items))
+
+	var next string
+	if end < len(items) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+
+	return items[p.offset:end], next
+}
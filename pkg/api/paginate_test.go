@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	assert := require.New(t)
+	items := []int{1, 2, 3, 4, 5}
+
+	p, err := parsePage(httptest.NewRequest("GET", "/?limit=2", nil))
+	assert.NoError(err)
+
+	var all []int
+	for {
+		page, next := paginate(items, p)
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+
+		p, err = parsePage(httptest.NewRequest("GET", "/?limit=2&cursor="+next, nil))
+		assert.NoError(err)
+	}
+	assert.Equal(items, all)
+
+	// the limit is capped
+	p, err = parsePage(httptest.NewRequest("GET", "/?limit=100000", nil))
+	assert.NoError(err)
+	assert.Equal(maxPageSize, p.limit)
+
+	_, err = parsePage(httptest.NewRequest("GET", "/?limit=0", nil))
+	assert.ErrorIs(err, errInvalidPage)
+
+	_, err = parsePage(httptest.NewRequest("GET", "/?cursor=!!", nil))
+	assert.ErrorIs(err, errInvalidPage)
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/re-cinq/aether/pkg/attribution"
+	"github.com/re-cinq/aether/pkg/units"
+)
+
+// podsReader returns the emissions attributed to the Kubernetes pods, the
+// overhead of the cluster and the completed jobs
+type podsReader interface {
+	Pods() []attribution.Pod
+	Overhead() attribution.Overhead
+	Jobs() []attribution.Job
+}
+
+// podsResponse is the body returned by the pods endpoint
+type podsResponse struct {
+	Pods []attribution.Pod `json:"pods"`
+
+	// The unit of the emissions
+	Unit units.Unit `json:"unit"`
+
+	// The cursor of the next page, empty on the last one
+	Next string `json:"next,omitempty"`
+}
+
+// jobsResponse is the body returned by the jobs endpoint
+type jobsResponse struct {
+	Jobs []attribution.Job `json:"jobs"`
+
+	// The unit of the emissions
+	Unit units.Unit `json:"unit"`
+
+	// The cursor of the next page, empty on the last one
+	Next string `json:"next,omitempty"`
+}
+
+// overheadResponse is the body returned by the overhead endpoint
+type overheadResponse struct {
+	attribution.Overhead
+
+	// The emissions attributed to the pods
+	Pods float64 `json:"pods"`
+
+	// The emissions of the cluster, the pods and the overhead
+	Total float64 `json:"total"`
+
+	// The unit of the emissions
+	Unit units.Unit `json:"unit"`
+}
+
+// podsHandler lists the emissions attributed to the pods, the highest
+// first. The pods can be filtered by namespace, node and label
+// (label=team=data) and are paginated
+func (a *API) podsHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	p, err := parsePage(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	labels := make(map[string]string)
+	for _, l := range q["label"] {
+		key, value, ok := strings.Cut(l, "=")
+		if !ok || key == "" {
+			writeError(w, http.StatusBadRequest, errors.New("labels must be formatted as key=value"))
+			return
+		}
+		labels[key] = value
+	}
+
+	namespace, node := q.Get("namespace"), q.Get("node")
+
+	var pods []attribution.Pod
+	for _, pod := range a.pods.Pods() {
+		if namespace != "" && pod.Namespace != namespace || node != "" && pod.Node != node {
+			continue
+		}
+
+		matches := true
+		for k, v := range labels {
+			if pod.Labels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			pods = append(pods, pod)
+		}
+	}
+
+	// ties are broken by namespace and name, so that the order is stable
+	// across pages
+	sort.SliceStable(pods, func(i, j int) bool {
+		return pods[i].Emissions > pods[j].Emissions
+	})
+
+	items, next := paginate(pods, p)
+	for i := range items {
+		items[i].Emissions = a.output.Convert(items[i].Emissions)
+	}
+
+	writeJSON(w, http.StatusOK, podsResponse{
+		Pods: items,
+		Unit: a.output.Unit(),
+		Next: next,
+	})
+}
+
+// overheadHandler returns the emissions of the cluster that aren't
+// attributed to the pods, along with the total of the pods
+func (a *API) overheadHandler(w http.ResponseWriter, req *http.Request) {
+	overhead := a.pods.Overhead()
+
+	var pods float64
+	for _, pod := range a.pods.Pods() {
+		pods += pod.Emissions
+	}
+
+	// the total is converted from the sum in gCO2e, so that it isn't off
+	// by the rounding of its parts
+	writeJSON(w, http.StatusOK, overheadResponse{
+		Overhead: attribution.Overhead{
+			Cluster:      overhead.Cluster,
+			System:       a.output.Convert(overhead.System),
+			Unallocated:  a.output.Convert(overhead.Unallocated),
+			ControlPlane: a.output.Convert(overhead.ControlPlane),
+		},
+		Pods:  a.output.Convert(pods),
+		Total: a.output.Convert(pods + overhead.Total()),
+		Unit:  a.output.Unit(),
+	})
+}
+
+// jobsHandler lists the emissions of the completed jobs, the latest first.
+// The jobs can be filtered by namespace and CronJob and are paginated
+func (a *API) jobsHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	p, err := parsePage(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	namespace, cronJob := q.Get("namespace"), q.Get("cronJob")
+
+	var jobs []attribution.Job
+	for _, job := range a.pods.Jobs() {
+		if namespace != "" && job.Namespace != namespace || cronJob != "" && job.CronJob != cronJob {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	items, next := paginate(jobs, p)
+	for i := range items {
+		items[i].Emissions = a.output.Convert(items[i].Emissions)
+	}
+
+	writeJSON(w, http.StatusOK, jobsResponse{
+		Jobs: items,
+		Unit: a.output.Unit(),
+		Next: next,
+	})
+}
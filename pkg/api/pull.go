@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/log"
+)
+
+// The timeout of the refresh when Prometheus doesn't send its own, and the
+// time kept to serve the metrics before Prometheus gives up
+const (
+	defaultPullTimeout = 10 * time.Second
+	pullTimeoutMargin  = 500 * time.Millisecond
+)
+
+// refresher scrapes the accounts whose emissions aren't fresh
+type refresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// WithPull refreshes the emissions before serving the metrics, so that they
+// are scraped when Prometheus scrapes the exporter
+func WithPull(r refresher) Option {
+	return func(a *API) {
+		a.refresher = r
+	}
+}
+
+// pull refreshes the emissions before serving the metrics. They are served
+// anyway when the refresh fails or times out, the stale ones included
+func (a *API) pull(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), pullTimeout(req))
+		defer cancel()
+
+		if err := a.refresher.Refresh(ctx); err != nil {
+			log.FromContext(req.Context()).Warn("serving the metrics before they were refreshed", "error", err)
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// pullTimeout returns how long the refresh can take, within the timeout of
+// the Prometheus scrape
+func pullTimeout(req *http.Request) time.Duration {
+	seconds, err := strconv.ParseFloat(req.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"), 64)
+	if err != nil || seconds <= 0 {
+		return defaultPullTimeout
+	}
+
+	timeout := time.Duration(seconds*float64(time.Second)) - pullTimeoutMargin
+	if timeout <= 0 {
+		return time.Duration(seconds * float64(time.Second) / 2)
+	}
+	return timeout
+}
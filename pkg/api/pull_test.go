@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRefresher struct {
+	timeout time.Duration
+	err     error
+}
+
+func (f *fakeRefresher) Refresh(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	f.timeout = time.Until(deadline)
+	return f.err
+}
+
+func TestPull(t *testing.T) {
+	assert := require.New(t)
+
+	r := &fakeRefresher{}
+	a := &API{refresher: r}
+	metrics := a.pull(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// the refresh times out before Prometheus does
+	req := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", "5")
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, req)
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.InDelta(4500*time.Millisecond, r.timeout, float64(100*time.Millisecond))
+
+	// the metrics are served when the refresh fails
+	r.err = errors.New("failed")
+	rec = httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.InDelta(defaultPullTimeout, r.timeout, float64(100*time.Millisecond))
+}
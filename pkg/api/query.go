@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+	"github.com/re-cinq/aether/pkg/units"
+)
+
+// emissionsStore returns the stored emissions
+type emissionsStore interface {
+	Query(q *store.Query) []store.Result
+	QueryRange(q *store.Query, from, to time.Time, step time.Duration) (*store.Matrix, error)
+	Latest(filter func(*store.Sample) bool) []store.Sample
+	Select(from, to time.Time, filter func(*store.Sample) bool) []store.Sample
+
+	// The time zone the dates of the periods start in
+	Location() *time.Location
+}
+
+// queryResponse is the body returned by the query endpoint
+type queryResponse struct {
+	Query   string         `json:"query"`
+	Results []store.Result `json:"results"`
+
+	// The unit of the values, empty when they're counts
+	Unit units.Unit `json:"unit,omitempty"`
+
+	// The cursor of the next page, empty on the last one
+	Next string `json:"next,omitempty"`
+}
+
+// queryHandler runs the query passed with the q parameter, for example:
+// /api/v1/query?q=sum(emissions) by (team) where provider=aws and range=30d
+func (a *API) queryHandler(w http.ResponseWriter, req *http.Request) {
+	expr := req.URL.Query().Get("q")
+	if expr == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing query parameter q"))
+		return
+	}
+
+	q, err := store.ParseQuery(expr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := parsePage(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := queryResponse{Query: expr}
+	resp.Results, resp.Next = paginate(a.store.Query(q), p)
+
+	// the counts of instances have no unit
+	switch {
+	case q.Function == "count":
+	case q.Field == store.IntensityField:
+		resp.Unit = intensityUnit
+	default:
+		resp.Unit = a.output.Unit()
+		for i := range resp.Results {
+			resp.Results[i].Value = a.output.Convert(resp.Results[i].Value)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+	"github.com/re-cinq/aether/pkg/units"
+)
+
+// defaultRangeQuery is the query of the range queries without one
+const defaultRangeQuery = "sum(emissions)"
+
+// intensityUnit is the unit of the grid intensities, they aren't converted
+// to the output unit
+const intensityUnit units.Unit = "gCO2e/kWh"
+
+// rangeResponse is the body returned by the range endpoint, in the format
+// of the range queries of Prometheus
+type rangeResponse struct {
+	Status string    `json:"status"`
+	Data   rangeData `json:"data"`
+}
+
+type rangeData struct {
+	ResultType string        `json:"resultType"`
+	Result     []rangeSeries `json:"result"`
+
+	// The samples read: raw, hourly or daily
+	Resolution string `json:"resolution"`
+
+	// The unit of the values, empty when they're counts
+	Unit units.Unit `json:"unit,omitempty"`
+}
+
+// rangeSeries are the values of a group, as pairs of a unix timestamp in
+// seconds and of the value as a string
+type rangeSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][2]any          `json:"values"`
+}
+
+// rangeHandler runs the query passed with the q parameter over every step
+// of the range, for example:
+// /api/v1/emissions/range?q=sum(emissions) by (team)&start=2024-01-01&end=2024-02-01&step=1d
+// The range defaults to the one of the query ending now
+func (a *API) rangeHandler(w http.ResponseWriter, req *http.Request) {
+	params := req.URL.Query()
+
+	expr := params.Get("q")
+	if expr == "" {
+		expr = defaultRangeQuery
+	}
+
+	q, err := store.ParseQuery(expr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if params.Get("step") == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing query parameter step"))
+		return
+	}
+	step, err := store.ParseDuration(params.Get("step"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	loc := a.store.Location()
+	end := time.Now().UTC()
+	if v := params.Get("end"); v != "" {
+		if end, err = parseTime(v, loc); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	start := q.Start(end, loc)
+	if v := params.Get("start"); v != "" {
+		if start, err = parseTime(v, loc); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	m, err := a.store.QueryRange(q, start, end, step)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := rangeResponse{
+		Status: "success",
+		Data: rangeData{
+			ResultType: "matrix",
+			Result:     make([]rangeSeries, 0, len(m.Series)),
+			Resolution: m.Resolution,
+		},
+	}
+
+	// the counts of instances have no unit
+	convert := func(v float64) float64 { return v }
+	switch {
+	case q.Function == "count":
+	case q.Field == store.IntensityField:
+		resp.Data.Unit = intensityUnit
+	default:
+		resp.Data.Unit = a.output.Unit()
+		convert = a.output.Convert
+	}
+
+	for _, s := range m.Series {
+		series := rangeSeries{
+			Metric: s.Labels,
+			Values: make([][2]any, 0, len(s.Points)),
+		}
+		for _, p := range s.Points {
+			series.Values = append(series.Values, [2]any{
+				float64(p.Time.UnixMilli()) / 1000,
+				strconv.FormatFloat(convert(p.Value), 'f', -1, 64),
+			})
+		}
+		resp.Data.Result = append(resp.Data.Result, series)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseTime parses a unix timestamp in seconds, like Prometheus, or a date
+// or a time like the other endpoints
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+
+	t, _, err := store.ParseDate(s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected a unix timestamp, 2006-01-02 or RFC3339", s)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeHandler(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, sample := range []store.Sample{
+		{Time: day.Add(10 * time.Minute), Provider: v1.AWS, Name: "i-a", Labels: v1.Labels{"team": "data"}, Operational: 100, GridIntensity: 300},
+		{Time: day.Add(70 * time.Minute), Provider: v1.AWS, Name: "i-a", Labels: v1.Labels{"team": "data"}, Operational: 50, GridIntensity: 200},
+		{Time: day.Add(20 * time.Minute), Provider: v1.AWS, Name: "i-b", Labels: v1.Labels{"team": "web"}, Operational: 30.5, GridIntensity: 400},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	a := &API{}
+	WithStore(s)(a)
+
+	tests := []struct {
+		name     string
+		query    string
+		code     int
+		expected rangeResponse
+	}{
+		{
+			name:  "hourly by team",
+			query: "q=sum(emissions)+by+(team)&start=2024-01-31&end=2024-01-31T02:00:00Z&step=1h",
+			code:  http.StatusOK,
+			expected: rangeResponse{Status: "success", Data: rangeData{
+				ResultType: "matrix",
+				Resolution: "hourly",
+				Unit:       "gCO2e",
+				Result: []rangeSeries{
+					{Metric: map[string]string{"team": "data"}, Values: [][2]any{{1706659200.0, "100"}, {1706662800.0, "50"}}},
+					{Metric: map[string]string{"team": "web"}, Values: [][2]any{{1706659200.0, "30.5"}}},
+				},
+			}},
+		},
+		{
+			name:  "unix timestamps and raw samples",
+			query: "q=count(emissions)&start=1706659800&end=1706663400&step=30m",
+			code:  http.StatusOK,
+			expected: rangeResponse{Status: "success", Data: rangeData{
+				ResultType: "matrix",
+				Resolution: "raw",
+				Result: []rangeSeries{
+					{Metric: map[string]string{}, Values: [][2]any{{1706659800.0, "2"}}},
+				},
+			}},
+		},
+		{
+			name:  "grid intensity by team",
+			query: "q=avg(intensity)+by+(team)&start=2024-01-31&end=2024-01-31T02:00:00Z&step=1h",
+			code:  http.StatusOK,
+			expected: rangeResponse{Status: "success", Data: rangeData{
+				ResultType: "matrix",
+				Resolution: "hourly",
+				Unit:       "gCO2e/kWh",
+				Result: []rangeSeries{
+					{Metric: map[string]string{"team": "data"}, Values: [][2]any{{1706659200.0, "300"}, {1706662800.0, "200"}}},
+					{Metric: map[string]string{"team": "web"}, Values: [][2]any{{1706659200.0, "400"}}},
+				},
+			}},
+		},
+		{name: "summed grid intensity", query: "q=sum(intensity)&step=1h", code: http.StatusBadRequest},
+		{name: "missing step", query: "start=2024-01-31&end=2024-02-01", code: http.StatusBadRequest},
+		{name: "invalid step", query: "step=often", code: http.StatusBadRequest},
+		{name: "invalid time", query: "step=1h&start=yesterday", code: http.StatusBadRequest},
+		{name: "too many points", query: "step=1s&start=2024-01-01&end=2024-02-01", code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			w := httptest.NewRecorder()
+			a.rangeHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/emissions/range?"+test.query, http.NoBody))
+			assert.Equal(test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var resp rangeResponse
+			assert.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(test.expected, resp)
+		})
+	}
+}
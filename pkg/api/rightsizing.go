@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/re-cinq/aether/pkg/rightsizing"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// recommendationReader returns the rightsizing recommendations pulled from
+// the recommenders of the providers
+type recommendationReader interface {
+	Recommendations(provider v1.Provider) []rightsizing.Recommendation
+}
+
+// WithRightsizing exposes the rightsizing recommendations of the providers
+// with their carbon savings on /api/v1/recommendations/rightsizing
+func WithRightsizing(r recommendationReader) Option {
+	return func(a *API) {
+		a.rightsizing = r
+	}
+}
+
+// rightsizingResponse is the body returned by the rightsizing endpoint
+type rightsizingResponse struct {
+	Recommendations []rightsizing.Recommendation `json:"recommendations"`
+}
+
+// rightsizingHandler returns the rightsizing recommendations, of the
+// provider passed with the provider parameter if any, the highest carbon
+// savings first
+func (a *API) rightsizingHandler(w http.ResponseWriter, req *http.Request) {
+	provider := v1.Provider(req.URL.Query().Get("provider"))
+	if _, ok := v1.Providers[provider.String()]; provider != "" && !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown provider %q", provider))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rightsizingResponse{
+		Recommendations: a.rightsizing.Recommendations(provider),
+	})
+}
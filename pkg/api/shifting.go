@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/re-cinq/aether/pkg/report"
+)
+
+// WithShiftingProfiles estimates the savings of the workload shifting
+// recommendations with the hourly grid intensity of the regions
+func WithShiftingProfiles(p report.Profiles) Option {
+	return func(a *API) {
+		a.profiles = p
+	}
+}
+
+// shiftingHandler returns the instances whose load could be shifted to the
+// greenest hours of the day over a period
+func (a *API) shiftingHandler(w http.ResponseWriter, req *http.Request) {
+	from, to, err := parsePeriod(req.URL.Query(), a.store.Location())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	samples := a.store.Select(from, to, nil)
+	writeJSON(w, http.StatusOK, report.NewShifting(samples, from, to, a.profiles))
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/report"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftingHandler(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	// a nightly batch at 2 am
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for h := 0; h < 24; h++ {
+		operational := 1.0
+		if h == 2 {
+			operational = 10
+		}
+		assert.NoError(s.Add(ctx, store.Sample{Time: day.Add(time.Duration(h) * time.Hour), Provider: v1.AWS, Name: "a", Region: "eu-west-1", Operational: operational}))
+	}
+
+	var profile [24]float64
+	for h := range profile {
+		profile[h] = 100
+	}
+	profile[2], profile[14] = 200, 50
+
+	a := &API{}
+	WithStore(s)(a)
+	WithShiftingProfiles(report.Profiles{"eu-west-1": profile})(a)
+
+	w := httptest.NewRecorder()
+	a.shiftingHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/shifting?from=2024-01-01&to=2024-01-01", http.NoBody))
+	assert.Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var r report.Shifting
+	assert.NoError(json.Unmarshal(w.Body.Bytes(), &r))
+	assert.Len(r.Recommendations, 1)
+	assert.Equal(report.PatternBatch, r.Recommendations[0].Pattern)
+	assert.Equal([]int{14}, r.Recommendations[0].GreenHours)
+	assert.Positive(r.Savings)
+
+	w = httptest.NewRecorder()
+	a.shiftingHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/shifting", http.NoBody))
+	assert.Equal(http.StatusBadRequest, w.Code)
+}
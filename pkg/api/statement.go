@@ -0,0 +1,105 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/statement"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// statementSigner signs the emissions statements
+type statementSigner struct {
+	key     ed25519.PrivateKey
+	issuer  string
+	version string
+}
+
+// WithStatements issues emissions statements signed with the key on
+// /api/v1/statement, the version is the one of the exporter
+func WithStatements(key ed25519.PrivateKey, issuer, version string) Option {
+	return func(a *API) {
+		a.statements = &statementSigner{
+			key:     key,
+			issuer:  issuer,
+			version: version,
+		}
+	}
+}
+
+// statementHandler returns the signed emissions statement of an account over
+// a period. A date only period end is included
+func (a *API) statementHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	from, to, err := parsePeriod(q, a.store.Location())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	account := q.Get("account")
+	samples := a.store.Select(from, to, func(s *store.Sample) bool {
+		return account == "" || s.Label(v1.AccountLabel) == account
+	})
+
+	s := statement.New(samples, &statement.Request{
+		Account: account,
+		From:    from,
+		To:      to,
+		Issuer:  a.statements.issuer,
+		Dataset: a.calculations.Dataset(),
+		Version: a.statements.version,
+	}, time.Now())
+
+	e, err := statement.Sign(s, a.statements.key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e)
+}
+
+// parsePeriod returns the period of the from and to parameters, the dates
+// start in the location and a date only end is included
+func parsePeriod(q url.Values, loc *time.Location) (from, to time.Time, err error) {
+	if q.Get("from") == "" || q.Get("to") == "" {
+		return from, to, errors.New("the from and to dates are required")
+	}
+
+	return parseDates(q.Get("from"), q.Get("to"), loc)
+}
+
+// parseDates returns the period between the two dates, the dates start in
+// the location and a date only end is included
+func parseDates(start, end string, loc *time.Location) (from, to time.Time, err error) {
+	from, _, err = store.ParseDate(start, loc)
+	if err != nil {
+		return from, to, err
+	}
+
+	to, dateOnly, err := store.ParseDate(end, loc)
+	if err != nil {
+		return from, to, err
+	}
+	if dateOnly {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return from, to, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+// statementSchemaHandler returns the JSON schema of the statements
+func statementSchemaHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	_, _ = w.Write(statement.Schema)
+}
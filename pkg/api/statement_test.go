@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/statement"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementHandler(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	day := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	for _, sample := range []store.Sample{
+		{Time: day, Provider: v1.AWS, Name: "a", Labels: v1.Labels{v1.AccountLabel: "prod"}, Operational: 10},
+		{Time: day, Provider: v1.AWS, Name: "b", Labels: v1.Labels{v1.AccountLabel: "dev"}, Operational: 5},
+		// after the period
+		{Time: day.Add(24 * time.Hour), Provider: v1.AWS, Name: "a", Labels: v1.Labels{v1.AccountLabel: "prod"}, Operational: 10},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+
+	a := &API{}
+	for _, opt := range []Option{
+		WithStore(s),
+		WithCalculations(fakeBackend{}),
+		WithStatements(key, "ACME", "1.0.0"),
+	} {
+		opt(a)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		code      int
+		emissions float64
+	}{
+		{name: "account", query: "account=prod&from=2024-01-01&to=2024-01-31", code: http.StatusOK, emissions: 10},
+		{name: "all accounts", query: "from=2024-01-01&to=2024-01-31", code: http.StatusOK, emissions: 15},
+		{name: "end excluded", query: "from=2024-01-01&to=2024-01-31T00:00:00Z", code: http.StatusOK},
+		{name: "missing period", query: "account=prod", code: http.StatusBadRequest},
+		{name: "invalid date", query: "from=yesterday&to=2024-01-31", code: http.StatusBadRequest},
+		{name: "empty period", query: "from=2024-01-31&to=2024-01-01", code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			w := httptest.NewRecorder()
+			a.statementHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/statement?"+test.query, http.NoBody))
+			assert.Equal(test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var e statement.Envelope
+			assert.NoError(json.Unmarshal(w.Body.Bytes(), &e))
+
+			st, err := statement.Verify(&e, pub)
+			assert.NoError(err)
+			assert.Equal("ACME", st.Issuer)
+			assert.Equal(test.emissions, st.Emissions.Total)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	statementSchemaHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/statement/schema", http.NoBody))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(statement.Schema, w.Body.Bytes())
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// statusReader returns the status of the scrapers
+type statusReader interface {
+	Status() []v1.ScrapeStatus
+}
+
+// statusResponse is the body returned by the status endpoint
+type statusResponse struct {
+	Accounts []v1.ScrapeStatus `json:"accounts"`
+
+	// The cursor of the next page, empty on the last one
+	Next string `json:"next,omitempty"`
+}
+
+// statusHandler returns the status of the scraping of every account
+// The accounts can be filtered by provider and paginated
+func (a *API) statusHandler(w http.ResponseWriter, req *http.Request) {
+	p, err := parsePage(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	accounts := a.status.Status()
+	if provider := req.URL.Query().Get("provider"); provider != "" {
+		filtered := accounts[:0]
+		for _, s := range accounts {
+			if s.Provider.String() == provider {
+				filtered = append(filtered, s)
+			}
+		}
+		accounts = filtered
+	}
+
+	items, next := paginate(accounts, p)
+	writeJSON(w, http.StatusOK, statusResponse{
+		Accounts: items,
+		Next:     next,
+	})
+}
+
+// writeJSON writes the value as the JSON body of the response
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	// the headers are already sent, nothing else can be done on failure
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// errorResponse is the body of the failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+
+	// The code of the error, when it has one
+	Code v1.ErrorCode `json:"code,omitempty"`
+}
+
+// writeError writes the error and its code as the JSON body of the response
+func writeError(w http.ResponseWriter, code int, err error) {
+	r := errorResponse{Error: err.Error()}
+	if c := v1.Code(err); c != v1.CodeInternal {
+		r.Code = c
+	}
+
+	writeJSON(w, code, r)
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// The web dashboard, it only uses the REST API
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the web dashboard
+func uiHandler() http.Handler {
+	// the directory is embedded, so it always exists
+	sub, _ := fs.Sub(uiFiles, "ui")
+	return http.StripPrefix("/ui/", http.FileServer(http.FS(sub)))
+}
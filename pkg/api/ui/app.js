@@ -0,0 +1,106 @@
+// The dashboard only uses the REST API, see /api/openapi.json
+(function () {
+  "use strict";
+
+  const refreshInterval = 60 * 1000;
+
+  async function get(path) {
+    const resp = await fetch(path, { credentials: "same-origin" });
+    if (!resp.ok) {
+      throw new Error(path + ": " + resp.status);
+    }
+    return resp.json();
+  }
+
+  function cell(value, className) {
+    const td = document.createElement("td");
+    td.textContent = value === undefined || value === null ? "" : value;
+    if (className) {
+      td.className = className;
+    }
+    return td;
+  }
+
+  function fill(id, rows, columns) {
+    const body = document.querySelector("#" + id + " tbody");
+    body.replaceChildren();
+
+    if (rows.length === 0) {
+      const tr = document.createElement("tr");
+      const td = cell("No data yet", "empty");
+      td.colSpan = columns;
+      tr.appendChild(td);
+      body.appendChild(tr);
+      return;
+    }
+
+    rows.forEach((cells) => {
+      const tr = document.createElement("tr");
+      cells.forEach((c) => tr.appendChild(c));
+      body.appendChild(tr);
+    });
+  }
+
+  function grams(v) {
+    return v.toLocaleString(undefined, { maximumFractionDigits: 2 });
+  }
+
+  function time(t) {
+    const d = new Date(t);
+    return d.getFullYear() > 1 ? d.toLocaleString() : "never";
+  }
+
+  async function totals(range) {
+    const q = "sum(emissions) by (provider, region) where range=" + range;
+    const data = await get("/api/v1/query?limit=50&q=" + encodeURIComponent(q));
+    fill("totals", data.results.map((r) => [
+      cell(r.labels.provider),
+      cell(r.labels.region),
+      cell(grams(r.value), "num"),
+    ]), 3);
+  }
+
+  async function top() {
+    const data = await get("/api/v1/instances?limit=10&sort=-emissions");
+    fill("top", data.instances.map((i) => [
+      cell(i.name),
+      cell(i.provider),
+      cell(i.region),
+      cell(i.kind),
+      cell(grams(i.operational + i.embodied), "num"),
+    ]), 5);
+  }
+
+  async function status() {
+    const data = await get("/api/v1/status");
+    fill("status", data.accounts.map((s) => [
+      cell(s.provider),
+      cell(s.account),
+      cell(s.degraded ? "degraded" : "healthy", s.degraded ? "degraded" : ""),
+      cell(time(s.lastSuccess)),
+      cell(s.instances, "num"),
+      cell(s.lastError),
+    ]), 6);
+  }
+
+  async function datasets() {
+    const data = await get("/api/v1/datasets");
+    const f = data.emissionFactors;
+    fill("datasets", [[
+      cell(f.source),
+      cell(f.version ? f.version.substring(0, 12) : "unknown"),
+      cell(time(f.refreshedAt)),
+    ]], 3);
+  }
+
+  function refresh() {
+    const range = document.getElementById("range").value;
+    [totals(range), top(), status(), datasets()].forEach((p) =>
+      p.catch((err) => console.error(err))
+    );
+  }
+
+  document.getElementById("range").addEventListener("change", refresh);
+  refresh();
+  setInterval(refresh, refreshInterval);
+})();
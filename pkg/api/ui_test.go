@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUI(t *testing.T) {
+	assert := require.New(t)
+
+	h := uiHandler()
+
+	for _, path := range []string{"/ui/", "/ui/app.js", "/ui/style.css"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(http.StatusOK, rec.Code, path)
+	}
+}
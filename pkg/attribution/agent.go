@@ -0,0 +1,624 @@
+// Package attribution splits the emissions of the Kubernetes nodes across
+// the pods running on them
+package attribution
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/cardinality"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/kube"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/relabel"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/re-cinq/aether/pkg/units"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	nodesResource      = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	podsResource       = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	podMetricsResource = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+)
+
+// The annotation GKE sets to the id of the instance of the node
+const gceInstanceIDAnnotation = "container.googleapis.com/instance_id"
+
+// The lifespan of the servers estimated from their inventory in years, when
+// not configured
+const defaultLifespan = 6
+
+// Agent attributes the calculated emissions of the instances backing the
+// Kubernetes nodes to their pods. The CPU emissions are split by the CPU
+// usage of the pods, the memory emissions by their memory usage and the
+// remaining ones, including the embodied emissions, by their CPU usage
+type Agent struct {
+	client dynamic.Interface
+
+	// The bus the nodes estimated from their inventory are published on
+	bus *bus.Bus
+
+	// How often the pods usage is collected
+	interval time.Duration
+
+	// The pod labels and annotations copied onto the emissions
+	labels      []string
+	annotations []string
+
+	// The relabeling of the pods
+	rules relabel.Rules
+
+	// The cluster whose overhead is reported and the namespaces of its
+	// system pods
+	cluster    string
+	namespaces map[string]bool
+
+	// The hourly emissions of the managed control plane and the scraping
+	// interval the emissions are calculated over
+	controlPlane     float64
+	scrapingInterval time.Duration
+
+	// The unit and precision of the emissions of the metrics
+	output units.Output
+
+	// The labels exported and the limit of the series of the pods, none
+	// when nil
+	limiter *cardinality.Limiter
+
+	// The emissions attributed to the pods and the overhead by the last run
+	pods     []Pod
+	overhead Overhead
+	podsMu   sync.RWMutex
+
+	// The emissions of the running jobs, the key is namespace/name, and the
+	// completed ones, the latest last
+	running   map[string]*Job
+	completed []Job
+	lastRun   time.Time
+	jobsMu    sync.RWMutex
+
+	// The latest calculated emissions of the instances, the key is the
+	// instance name
+	instances map[string]v1.Instance
+	mu        sync.Mutex
+
+	// The lifespan in years of the nodes without a cloud instance, zero
+	// when they aren't estimated from their inventory, and when they were
+	// last published
+	lifespan  float64
+	published time.Time
+
+	logger *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns an agent connected to the cluster set in the config, the
+// emissions of the instances are calculated over the scraping interval and
+// the ones of the metrics are exported in the unit of the output, their
+// series limited by the limiter unless it's nil. The nodes estimated from
+// their inventory are published on the bus
+func New(ctx context.Context, cfg *config.AttributionConfig, b *bus.Bus, scrapingInterval time.Duration, output units.Output, limiter *cardinality.Limiter) (*Agent, error) {
+	client, err := kube.NewClient(cfg.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := newAgent(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.bus = b
+	a.scrapingInterval = scrapingInterval
+	a.output = output
+	a.limiter = limiter
+
+	return a, nil
+}
+
+// newAgent returns an agent using the client
+func newAgent(ctx context.Context, client dynamic.Interface, cfg *config.AttributionConfig) (*Agent, error) {
+	rules, err := relabel.New(cfg.RelabelConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	namespaces := make(map[string]bool, len(cfg.Overhead.Namespaces))
+	for _, ns := range cfg.Overhead.Namespaces {
+		namespaces[ns] = true
+	}
+
+	var lifespan float64
+	if cfg.Inventory.Enabled {
+		lifespan = cfg.Inventory.Lifespan
+		if lifespan <= 0 {
+			lifespan = defaultLifespan
+		}
+	}
+
+	return &Agent{
+		client:       client,
+		lifespan:     lifespan,
+		interval:     interval,
+		labels:       cfg.Labels,
+		annotations:  cfg.Annotations,
+		rules:        rules,
+		cluster:      cfg.Cluster,
+		namespaces:   namespaces,
+		controlPlane: cfg.Overhead.ControlPlane,
+		instances:    make(map[string]v1.Instance),
+		running:      make(map[string]*Job),
+		logger:       log.FromContext(ctx),
+	}, nil
+}
+
+// Pods returns the emissions attributed to the pods by the last run,
+// sorted by namespace and name
+func (a *Agent) Pods() []Pod {
+	a.podsMu.RLock()
+	defer a.podsMu.RUnlock()
+
+	return a.pods
+}
+
+// Overhead returns the emissions of the cluster that weren't attributed to
+// the pods by the last run
+func (a *Agent) Overhead() Overhead {
+	a.podsMu.RLock()
+	defer a.podsMu.RUnlock()
+
+	return a.overhead
+}
+
+// Start attributes the emissions every interval
+// NOTE: this is not a blocking call
+func (a *Agent) Start(ctx context.Context) {
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.done = make(chan struct{})
+
+	go func() {
+		defer close(a.done)
+
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// a slow attribution is cancelled instead of delaying the
+				// next ones
+				tick, cancel := context.WithTimeout(ctx, a.interval)
+				if err := a.attribute(tick); err != nil {
+					a.logger.Error("failed attributing the emissions to the pods", "error", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Handle is used to fulfill the EventHandler interface and records the
+// latest emissions of the instances
+func (a *Agent) Handle(ctx context.Context, e *bus.Event) {
+	if e.Type != v1.EmissionsCalculatedEvent {
+		return
+	}
+
+	instance, ok := e.Data.(v1.Instance)
+	if !ok {
+		a.logger.Error("attribution agent got an unknown event", "event", e)
+		return
+	}
+	// the nodes estimated from their inventory are estimated at every run
+	if instance.Service == inventoryService {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.instances[instance.Name] = instance
+}
+
+// Stop is used to fulfill the EventHandler interface and stops the
+// attribution, it can be called more than once
+func (a *Agent) Stop(ctx context.Context) {
+	if a.cancel == nil {
+		return
+	}
+
+	a.cancel()
+
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+	}
+}
+
+// usage is the resource usage of a pod
+type usage struct {
+	namespace, name, node string
+
+	// The labels and annotations copied from the pod
+	labels v1.Labels
+
+	// The Job the pod belongs to, if any
+	job string
+
+	// CPU cores and memory bytes
+	cpu, memory float64
+}
+
+// Pod is the emissions attributed to a pod
+type Pod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Node      string `json:"node"`
+
+	// The Job the pod belongs to, if any
+	Job string `json:"job,omitempty"`
+
+	// The labels and annotations of the pod, after the relabeling
+	Labels v1.Labels `json:"labels,omitempty"`
+
+	// The emissions over the scraping interval in gCO2eq
+	Emissions float64 `json:"emissions"`
+}
+
+// Overhead is the emissions of a cluster that aren't attributed to the
+// pods, reported as a virtual instance of the cluster so that the emissions
+// of the pods and the overhead add up to the emissions of the cluster
+// The emissions are over the scraping interval in gCO2eq
+type Overhead struct {
+	Cluster string `json:"cluster"`
+
+	// The emissions of the pods of the system namespaces
+	System float64 `json:"system"`
+
+	// The emissions of the nodes that aren't attributed to any pod: the
+	// nodes running no pods and the pods dropped by the relabeling
+	Unallocated float64 `json:"unallocated"`
+
+	// The emissions of the managed control plane
+	ControlPlane float64 `json:"controlPlane"`
+}
+
+// Total returns the emissions of the overhead
+func (o *Overhead) Total() float64 {
+	return o.System + o.Unallocated + o.ControlPlane
+}
+
+// attribute splits the emissions of every node across its pods and updates
+// the metrics
+func (a *Agent) attribute(ctx context.Context) error {
+	nodes, err := a.nodeInstances(ctx)
+	if err != nil {
+		return err
+	}
+
+	pods, err := a.podUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	emissions := make(map[string]v1.Instance, len(nodes))
+	for name, n := range nodes {
+		for _, instance := range n.names {
+			if i, ok := a.instances[instance]; ok {
+				emissions[name] = i
+				break
+			}
+		}
+	}
+	a.mu.Unlock()
+
+	// the nodes without a cloud instance are estimated from their inventory
+	a.estimate(ctx, nodes, emissions)
+
+	overhead := Overhead{
+		Cluster:      a.cluster,
+		ControlPlane: a.controlPlane * a.scrapingInterval.Hours(),
+	}
+
+	// the emissions of the nodes running no pods are not attributed
+	running := make(map[string]bool, len(pods))
+	for i := range pods {
+		running[pods[i].node] = true
+	}
+	for node := range emissions {
+		if !running[node] {
+			instance := emissions[node]
+			cpu, memory, other := instanceEmissions(&instance)
+			overhead.Unallocated += cpu + memory + other
+		}
+	}
+
+	attributed := split(emissions, pods)
+	a.accumulateJobs(attributed)
+
+	var out []Pod
+	namespaces := make(map[string]float64)
+	for _, p := range attributed {
+		if a.namespaces[p.Namespace] {
+			overhead.System += p.Emissions
+			continue
+		}
+
+		labels, ok := a.relabel(&p)
+		if !ok {
+			overhead.Unallocated += p.Emissions
+			continue
+		}
+		p.Labels = labels
+
+		out = append(out, p)
+		namespaces[p.Namespace] += p.Emissions
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Name < out[j].Name
+	})
+
+	a.podsMu.Lock()
+	a.pods = out
+	a.overhead = overhead
+	a.podsMu.Unlock()
+
+	podEmissions.set(out, a.output, a.limiter)
+
+	unit := string(a.output.Unit())
+	overheadEmissions.Reset()
+	overheadEmissions.WithLabelValues(overhead.Cluster, "system", unit).Set(a.output.Convert(overhead.System))
+	overheadEmissions.WithLabelValues(overhead.Cluster, "unallocated", unit).Set(a.output.Convert(overhead.Unallocated))
+	overheadEmissions.WithLabelValues(overhead.Cluster, "control_plane", unit).Set(a.output.Convert(overhead.ControlPlane))
+
+	namespaceEmissions.Reset()
+	for namespace, value := range namespaces {
+		namespaceEmissions.WithLabelValues(namespace, unit).Set(a.output.Convert(value))
+	}
+
+	return a.completeJobs(ctx)
+}
+
+// relabel applies the relabeling to the labels of the pod, which can use
+// its namespace, pod and node labels. Those can't be changed, so that
+// every pod keeps its own series
+func (a *Agent) relabel(p *Pod) (v1.Labels, bool) {
+	labels := make(v1.Labels, len(p.Labels)+3)
+	for k, v := range p.Labels {
+		labels[k] = v
+	}
+	labels["namespace"] = p.Namespace
+	labels["pod"] = p.Name
+	labels["node"] = p.Node
+
+	labels, ok := a.rules.Process(labels)
+	if !ok {
+		return nil, false
+	}
+
+	delete(labels, "namespace")
+	delete(labels, "pod")
+	delete(labels, "node")
+
+	return labels, true
+}
+
+// split attributes the emissions of the instances, the key is the node
+// name, to the pods running on them
+func split(instances map[string]v1.Instance, pods []usage) []Pod {
+	type total struct {
+		cpu, memory float64
+		pods        int
+	}
+
+	totals := make(map[string]*total)
+	for _, p := range pods {
+		t, ok := totals[p.node]
+		if !ok {
+			t = &total{}
+			totals[p.node] = t
+		}
+		t.cpu += p.cpu
+		t.memory += p.memory
+		t.pods++
+	}
+
+	var out []Pod
+	for _, p := range pods {
+		instance, ok := instances[p.node]
+		if !ok {
+			continue
+		}
+
+		cpu, memory, other := instanceEmissions(&instance)
+
+		t := totals[p.node]
+		cpuShare := share(p.cpu, t.cpu, t.pods)
+		memoryShare := share(p.memory, t.memory, t.pods)
+
+		out = append(out, Pod{
+			Namespace: p.namespace,
+			Name:      p.name,
+			Node:      p.node,
+			Job:       p.job,
+			Labels:    p.labels,
+			Emissions: (cpu+other)*cpuShare + memory*memoryShare,
+		})
+	}
+
+	return out
+}
+
+// instanceEmissions returns the CPU, memory and the remaining emissions of an
+// instance, including the embodied ones
+func instanceEmissions(instance *v1.Instance) (cpu, memory, other float64) {
+	for _, m := range instance.Metrics {
+		switch m.ResourceType {
+		case v1.CPU:
+			cpu += m.Emissions.Value
+		case v1.Memory:
+			memory += m.Emissions.Value
+		default:
+			other += m.Emissions.Value
+		}
+	}
+	other += instance.EmbodiedEmissions.Value
+
+	return cpu, memory, other
+}
+
+// share returns the part of the total used by a pod, the pods share the
+// node equally when none of them uses the resource
+func share(value, total float64, pods int) float64 {
+	if total <= 0 {
+		return 1 / float64(pods)
+	}
+	return value / total
+}
+
+// node is a Kubernetes node
+type node struct {
+	// The names its instance can have
+	names []string
+
+	// The hardware of the nodes without a cloud instance, nil when unknown
+	inventory *inventory
+}
+
+// nodeInstances returns the names the instance of every node can have and
+// the inventory of the nodes when they're estimated, the key is the node
+// name
+func (a *Agent) nodeInstances(ctx context.Context) (map[string]node, error) {
+	list, err := a.client.Resource(nodesResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the nodes: %w", err)
+	}
+
+	nodes := make(map[string]node, len(list.Items))
+	for i := range list.Items {
+		obj := &list.Items[i]
+
+		var n node
+
+		// GKE: the instances are named after their numeric id
+		if id, ok := obj.GetAnnotations()[gceInstanceIDAnnotation]; ok {
+			n.names = append(n.names, id)
+		}
+
+		// e.g. aws:///eu-west-1a/i-0123456789abcdef0
+		providerID, _, _ := unstructured.NestedString(obj.Object, "spec", "providerID")
+		if i := strings.LastIndex(providerID, "/"); i >= 0 && i < len(providerID)-1 {
+			n.names = append(n.names, providerID[i+1:])
+		}
+		n.names = append(n.names, obj.GetName())
+
+		if a.lifespan > 0 {
+			n.inventory = nodeInventory(obj)
+		}
+
+		nodes[obj.GetName()] = n
+	}
+
+	return nodes, nil
+}
+
+// podUsage returns the usage of the running pods, as reported by the
+// metrics server
+func (a *Agent) podUsage(ctx context.Context) ([]usage, error) {
+	pods, err := a.client.Resource(podsResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the pods: %w", err)
+	}
+
+	// the pod metrics don't contain the node and the labels of the pod
+	running := make(map[string]usage, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		node, _, _ := unstructured.NestedString(pod.Object, "spec", "nodeName")
+		phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase")
+		if node == "" || phase != "Running" {
+			continue
+		}
+
+		u := usage{namespace: pod.GetNamespace(), name: pod.GetName(), node: node}
+		copyLabels(&u.labels, "label_", pod.GetLabels(), a.labels)
+		copyLabels(&u.labels, "annotation_", pod.GetAnnotations(), a.annotations)
+		for _, owner := range pod.GetOwnerReferences() {
+			if owner.Kind == "Job" {
+				u.job = owner.Name
+			}
+		}
+		running[pod.GetNamespace()+"/"+pod.GetName()] = u
+	}
+
+	metrics, err := a.client.Resource(podMetricsResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the pod metrics, make sure the metrics server is installed: %w", err)
+	}
+
+	var out []usage
+	for i := range metrics.Items {
+		m := &metrics.Items[i]
+		u, ok := running[m.GetNamespace()+"/"+m.GetName()]
+		if !ok {
+			continue
+		}
+
+		containers, _, _ := unstructured.NestedSlice(m.Object, "containers")
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			u.cpu += quantity(container, "cpu")
+			u.memory += quantity(container, "memory")
+		}
+
+		out = append(out, u)
+	}
+
+	return out, nil
+}
+
+// copyLabels copies the selected keys to the labels, prefixed and converted
+// to valid label names
+func copyLabels(labels *v1.Labels, prefix string, from map[string]string, keys []string) {
+	for _, key := range keys {
+		if v, ok := from[key]; ok {
+			labels.Add(prefix+relabel.LabelName(key), v)
+		}
+	}
+}
+
+// quantity returns the usage of a resource of a container, 0 when invalid
+func quantity(container map[string]interface{}, name string) float64 {
+	s, _, _ := unstructured.NestedString(container, "usage", name)
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0
+	}
+	return q.AsApproximateFloat64()
+}
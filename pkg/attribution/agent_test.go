@@ -0,0 +1,305 @@
+package attribution
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/cardinality"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/hardware"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/re-cinq/aether/pkg/units"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newObject(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func newPod(namespace, name, node, phase string, labels map[string]string) *unstructured.Unstructured {
+	obj := newObject("v1", "Pod", namespace, name, map[string]interface{}{
+		"spec":   map[string]interface{}{"nodeName": node},
+		"status": map[string]interface{}{"phase": phase},
+	})
+	obj.SetLabels(labels)
+	obj.SetAnnotations(map[string]string{"example.com/cost-center": "cc-" + name})
+	return obj
+}
+
+func newPodMetrics(namespace, name string, usage ...map[string]interface{}) *unstructured.Unstructured {
+	var containers []interface{}
+	for _, u := range usage {
+		containers = append(containers, map[string]interface{}{"usage": u})
+	}
+	return newObject("metrics.k8s.io/v1beta1", "PodMetrics", namespace, name, map[string]interface{}{
+		"containers": containers,
+	})
+}
+
+func TestAttribute(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			nodesResource:      "NodeList",
+			podsResource:       "PodList",
+			podMetricsResource: "PodMetricsList",
+		},
+		newObject("v1", "Node", "", "ip-10-0-0-1", map[string]interface{}{
+			"spec": map[string]interface{}{"providerID": "aws:///eu-north-1a/i-0123"},
+		}),
+		newPod("shop", "web", "ip-10-0-0-1", "Running", map[string]string{"team": "checkout", "app.kubernetes.io/name": "web"}),
+		newPod("shop", "worker", "ip-10-0-0-1", "Running", map[string]string{"app.kubernetes.io/name": "worker"}),
+		newPod("shop", "done", "ip-10-0-0-1", "Succeeded", nil),
+	)
+
+	// the resource of the pod metrics can't be guessed from their kind
+	for _, m := range []*unstructured.Unstructured{
+		newPodMetrics("shop", "web",
+			map[string]interface{}{"cpu": "2", "memory": "256Mi"},
+			map[string]interface{}{"cpu": "1", "memory": "256Mi"},
+		),
+		newPodMetrics("shop", "worker", map[string]interface{}{"cpu": "1", "memory": "1536Mi"}),
+		newPodMetrics("shop", "done", map[string]interface{}{"cpu": "1", "memory": "1Gi"}),
+	} {
+		assert.NoError(client.Tracker().Create(podMetricsResource, m, m.GetNamespace()))
+	}
+
+	a, err := newAgent(ctx, client, &config.AttributionConfig{
+		Labels:      []string{"team", "app.kubernetes.io/name"},
+		Annotations: []string{"example.com/cost-center"},
+		RelabelConfigs: []config.RelabelConfig{
+			{SourceLabels: []string{"label_app_kubernetes_io_name"}, TargetLabel: "app"},
+			{Action: "labeldrop", Regex: "label_app_.*"},
+			// the pod name can't be changed
+			{TargetLabel: "pod", Replacement: "other"},
+		},
+	})
+	assert.NoError(err)
+
+	instance := v1.Instance{Name: "i-0123", Provider: v1.AWS, Metrics: v1.Metrics{}}
+	instance.Metrics.Upsert(&v1.Metric{Name: "cpu", ResourceType: v1.CPU, Emissions: v1.NewResourceEmission(80, v1.GCO2eqkWh)})
+	instance.Metrics.Upsert(&v1.Metric{Name: "memory", ResourceType: v1.Memory, Emissions: v1.NewResourceEmission(40, v1.GCO2eqkWh)})
+	instance.EmbodiedEmissions = v1.NewResourceEmission(20, v1.GCO2eqkWh)
+	a.Handle(ctx, &bus.Event{Type: v1.EmissionsCalculatedEvent, Data: instance})
+
+	assert.NoError(a.attribute(ctx))
+
+	// web uses 3/4 of the CPU and 1/4 of the memory, only the running pods
+	// are attributed emissions
+	assert.NoError(testutil.CollectAndCompare(podEmissions, strings.NewReader(`
+# HELP pod_emissions co2eq of a pod over the scraping interval, attributed from the emissions of its node by CPU and memory usage
+# TYPE pod_emissions gauge
+pod_emissions{annotation_example_com_cost_center="cc-web",app="web",label_team="checkout",namespace="shop",node="ip-10-0-0-1",pod="web",unit="gCO2e"} 85
+pod_emissions{annotation_example_com_cost_center="cc-worker",app="worker",label_team="",namespace="shop",node="ip-10-0-0-1",pod="worker",unit="gCO2e"} 55
+`)))
+	assert.InDelta(140, testutil.ToFloat64(namespaceEmissions.WithLabelValues("shop", "gCO2e")), 0.001)
+
+	assert.Len(a.Pods(), 2)
+	assert.Equal(v1.Labels{"annotation_example_com_cost_center": "cc-web", "app": "web", "label_team": "checkout"}, a.Pods()[0].Labels)
+
+	// the pods past the limit are summed into the buckets of their names
+	limiter, err := cardinality.New("pods", &config.CardinalityConfig{
+		Allow:       []string{"namespace", "pod"},
+		MaxSeries:   1,
+		Overflow:    cardinality.OverflowHash,
+		HashLabels:  []string{"pod"},
+		HashBuckets: 1,
+	}, "unit")
+	assert.NoError(err)
+	podEmissions.set(a.Pods(), a.output, limiter)
+
+	assert.NoError(testutil.CollectAndCompare(podEmissions, strings.NewReader(`
+# HELP pod_emissions co2eq of a pod over the scraping interval, attributed from the emissions of its node by CPU and memory usage
+# TYPE pod_emissions gauge
+pod_emissions{namespace="shop",pod="web",unit="gCO2e"} 85
+pod_emissions{namespace="shop",pod="hash_0",unit="gCO2e"} 55
+`)))
+}
+
+func TestShare(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(0.25, share(1, 4, 2))
+
+	// the node is split equally when the pods use none of the resource
+	assert.Equal(0.5, share(0, 0, 2))
+}
+
+func TestOverhead(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			nodesResource:      "NodeList",
+			podsResource:       "PodList",
+			podMetricsResource: "PodMetricsList",
+		},
+		newObject("v1", "Node", "", "node-a", nil),
+		newObject("v1", "Node", "", "node-b", nil),
+		newPod("shop", "web", "node-a", "Running", nil),
+		newPod("kube-system", "dns", "node-a", "Running", nil),
+		newPod("debug", "shell", "node-a", "Running", nil),
+	)
+
+	for _, m := range []*unstructured.Unstructured{
+		newPodMetrics("shop", "web", map[string]interface{}{"cpu": "2", "memory": "1Gi"}),
+		newPodMetrics("kube-system", "dns", map[string]interface{}{"cpu": "1", "memory": "1Gi"}),
+		newPodMetrics("debug", "shell", map[string]interface{}{"cpu": "1", "memory": "2Gi"}),
+	} {
+		assert.NoError(client.Tracker().Create(podMetricsResource, m, m.GetNamespace()))
+	}
+
+	a, err := newAgent(ctx, client, &config.AttributionConfig{
+		Cluster: "prod",
+		Overhead: config.OverheadConfig{
+			Namespaces:   []string{"kube-system"},
+			ControlPlane: 6,
+		},
+		RelabelConfigs: []config.RelabelConfig{
+			{SourceLabels: []string{"namespace"}, Regex: "debug", Action: "drop"},
+		},
+	})
+	assert.NoError(err)
+	a.scrapingInterval = 30 * time.Minute
+
+	for _, name := range []string{"node-a", "node-b"} {
+		instance := v1.Instance{Name: name, Provider: v1.GCP, Metrics: v1.Metrics{}}
+		instance.Metrics.Upsert(&v1.Metric{Name: "cpu", ResourceType: v1.CPU, Emissions: v1.NewResourceEmission(40, v1.GCO2eqkWh)})
+		instance.Metrics.Upsert(&v1.Metric{Name: "memory", ResourceType: v1.Memory, Emissions: v1.NewResourceEmission(20, v1.GCO2eqkWh)})
+		a.Handle(ctx, &bus.Event{Type: v1.EmissionsCalculatedEvent, Data: instance})
+	}
+
+	assert.NoError(a.attribute(ctx))
+
+	// web: 40*2/4 + 20*1/4, dns: 40*1/4 + 20*1/4, shell: 40*1/4 + 20*2/4
+	assert.Len(a.Pods(), 1)
+	assert.InDelta(25, a.Pods()[0].Emissions, 0.001)
+
+	overhead := a.Overhead()
+	assert.Equal("prod", overhead.Cluster)
+	assert.InDelta(15, overhead.System, 0.001)
+	assert.InDelta(80, overhead.Unallocated, 0.001)
+	assert.InDelta(3, overhead.ControlPlane, 0.001)
+
+	// the pods and the overhead add up to the nodes and the control plane
+	assert.InDelta(123, a.Pods()[0].Emissions+overhead.Total(), 0.001)
+	assert.InDelta(80, testutil.ToFloat64(overheadEmissions.WithLabelValues("prod", "unallocated", "gCO2e")), 0.001)
+
+	// the metrics are exported in the unit of the output
+	a.output, err = units.New(&config.OutputConfig{Unit: "kgCO2e"})
+	assert.NoError(err)
+	assert.NoError(a.attribute(ctx))
+	assert.InDelta(0.08, testutil.ToFloat64(overheadEmissions.WithLabelValues("prod", "unallocated", "kgCO2e")), 0.000001)
+	assert.InDelta(25, a.Pods()[0].Emissions, 0.001)
+}
+
+func TestInventory(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	metal := newObject("v1", "Node", "", "metal-1", map[string]interface{}{
+		"status": map[string]interface{}{"capacity": map[string]interface{}{"cpu": "80", "memory": "384Gi"}},
+	})
+	metal.SetLabels(map[string]string{
+		cpuModelLabel:       "Intel-Xeon-Gold-6230",
+		dimmsLabel:          "12",
+		dimmSizeLabel:       "32",
+		ssdsLabel:           "2",
+		ssdSizeLabel:        "960",
+		multithreadingLabel: "true",
+		regionLabel:         "dc-1",
+	})
+	// the annotations can contain spaces
+	metal.SetAnnotations(map[string]string{cpuModelLabel: "Intel(R) Xeon(R) Gold 6230 CPU @ 2.10GHz"})
+
+	// the cloud nodes are never estimated
+	cloud := newObject("v1", "Node", "", "ip-10-0-0-1", map[string]interface{}{
+		"spec": map[string]interface{}{"providerID": "aws:///eu-north-1a/i-0123"},
+	})
+	cloud.SetLabels(map[string]string{cpuModelLabel: "Intel-Xeon-Platinum-8280"})
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			nodesResource:      "NodeList",
+			podsResource:       "PodList",
+			podMetricsResource: "PodMetricsList",
+		},
+		metal,
+		cloud,
+		newObject("v1", "Node", "", "kind-worker", nil),
+		newPod("shop", "web", "metal-1", "Running", nil),
+	)
+	m := newPodMetrics("shop", "web", map[string]interface{}{"cpu": "1", "memory": "1Gi"})
+	assert.NoError(client.Tracker().Create(podMetricsResource, m, m.GetNamespace()))
+
+	a, err := newAgent(ctx, client, &config.AttributionConfig{
+		Cluster:   "prod",
+		Inventory: config.InventoryConfig{Enabled: true},
+	})
+	assert.NoError(err)
+	a.scrapingInterval = time.Hour
+
+	b := bus.New()
+	events := make(chan v1.Instance, 10)
+	b.Subscribe(v1.EmissionsCalculatedEvent, bustest.Collector(events))
+	b.Start(ctx)
+	defer b.Stop(ctx)
+	a.bus = b
+
+	nodes, err := a.nodeInstances(ctx)
+	assert.NoError(err)
+	assert.Nil(nodes["ip-10-0-0-1"].inventory)
+	assert.Nil(nodes["kind-worker"].inventory)
+
+	// 2 sockets of 20 cores with hyper-threading and 384 GB of memory
+	i := nodes["metal-1"].inventory
+	assert.NotNil(i)
+	assert.Equal(2, i.Sockets)
+	assert.Equal(384.0, i.Memory)
+	assert.Equal("dc-1", i.region)
+
+	embodied, known := (&hardware.Inventory{
+		CPUModel: "Xeon Gold 6230", Sockets: 2, DIMMs: 12, DIMMSize: 32, SSDs: 2, SSDSize: 960,
+	}).Embodied()
+	assert.True(known)
+	hourly := embodied * 1000 / (defaultLifespan * 24 * 365)
+
+	assert.NoError(a.attribute(ctx))
+	assert.Len(a.Pods(), 1)
+	assert.InDelta(hourly, a.Pods()[0].Emissions, 1e-9)
+
+	// the node is published like the scraped instances
+	instance := <-events
+	assert.Equal(v1.Prometheus, instance.Provider)
+	assert.Equal(inventoryService, instance.Service)
+	assert.Equal("metal-1", instance.Name)
+	assert.Equal("dc-1", instance.Region)
+	assert.Equal(v1.Labels{"cluster": "prod"}, instance.Labels)
+	assert.InDelta(hourly, instance.EmbodiedEmissions.Value, 1e-9)
+	assert.Equal(v1.PowerCurve, instance.EmbodiedEmissions.Quality.Power)
+
+	// it's not recorded as the instance of the node, nor published before
+	// the next scraping interval
+	a.Handle(ctx, &bus.Event{Type: v1.EmissionsCalculatedEvent, Data: instance})
+	assert.Empty(a.instances)
+	assert.NoError(a.attribute(ctx))
+	assert.NoError(b.Wait(ctx))
+	assert.Empty(events)
+}
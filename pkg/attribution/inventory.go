@@ -0,0 +1,206 @@
+package attribution
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/hardware"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The labels of the hardware inventory, set by node-feature-discovery from
+// the feature files of its local source, e.g. written from dmidecode by an
+// init container. The annotations of the same names take precedence, their
+// values can contain spaces
+const (
+	inventoryPrefix = "feature.node.kubernetes.io/"
+	cpuModelLabel   = inventoryPrefix + "smbios-cpu-model"
+	socketsLabel    = inventoryPrefix + "smbios-cpu-sockets"
+	coresLabel      = inventoryPrefix + "smbios-cpu-cores"
+	dimmsLabel      = inventoryPrefix + "smbios-dimm-count"
+	dimmSizeLabel   = inventoryPrefix + "smbios-dimm-size-gb"
+	ssdsLabel       = inventoryPrefix + "smbios-ssd-count"
+	ssdSizeLabel    = inventoryPrefix + "smbios-ssd-size-gb"
+	hddsLabel       = inventoryPrefix + "smbios-hdd-count"
+
+	// The label of the cpu source of node-feature-discovery
+	multithreadingLabel = inventoryPrefix + "cpu-hardware_multithreading"
+)
+
+// The service of the instances of the nodes estimated from their inventory
+const inventoryService = "Kubernetes node"
+
+// The labels of the location of a node
+const (
+	regionLabel = "topology.kubernetes.io/region"
+	zoneLabel   = "topology.kubernetes.io/zone"
+)
+
+// cloudSchemes are the schemes of the provider IDs of the nodes backed by
+// the instances of a cloud provider, they're never estimated
+var cloudSchemes = map[string]bool{
+	"aws":          true,
+	"gce":          true,
+	"azure":        true,
+	"digitalocean": true,
+	"oci":          true,
+	"scaleway":     true,
+}
+
+// inventory is the hardware of a node without a cloud instance
+type inventory struct {
+	hardware.Inventory
+
+	region, zone string
+}
+
+// nodeInventory returns the hardware inventory of the node, nil when it's a
+// cloud node or when node-feature-discovery reported none of it
+func nodeInventory(node *unstructured.Unstructured) *inventory {
+	providerID, _, _ := unstructured.NestedString(node.Object, "spec", "providerID")
+	if scheme, _, ok := strings.Cut(providerID, "://"); ok && cloudSchemes[scheme] {
+		return nil
+	}
+	if _, ok := node.GetAnnotations()[gceInstanceIDAnnotation]; ok {
+		return nil
+	}
+
+	get := func(key string) string {
+		if v, ok := node.GetAnnotations()[key]; ok {
+			return v
+		}
+		return node.GetLabels()[key]
+	}
+
+	if get(cpuModelLabel) == "" && get(dimmsLabel) == "" {
+		return nil
+	}
+
+	i := &inventory{
+		Inventory: hardware.Inventory{
+			CPUModel: get(cpuModelLabel),
+			Sockets:  atoi(get(socketsLabel)),
+			Cores:    atoi(get(coresLabel)),
+			DIMMs:    atoi(get(dimmsLabel)),
+			DIMMSize: atof(get(dimmSizeLabel)),
+			SSDs:     atoi(get(ssdsLabel)),
+			SSDSize:  atof(get(ssdSizeLabel)),
+			HDDs:     atoi(get(hddsLabel)),
+		},
+		region: node.GetLabels()[regionLabel],
+		zone:   node.GetLabels()[zoneLabel],
+	}
+
+	capacity := func(name string) float64 {
+		s, _, _ := unstructured.NestedString(node.Object, "status", "capacity", name)
+		q, err := resource.ParseQuantity(s)
+		if err != nil {
+			return 0
+		}
+		return q.AsApproximateFloat64()
+	}
+
+	// the memory modules are unknown without SMBIOS
+	i.Memory = capacity("memory") / (1 << 30)
+
+	// the sockets are the logical CPUs over the threads of every CPU
+	if cores := i.CoresPerSocket(); i.Sockets == 0 && cores > 0 {
+		threads := 1
+		if get(multithreadingLabel) == "true" {
+			threads = 2
+		}
+		i.Sockets = int(math.Ceil(capacity("cpu") / float64(cores*threads)))
+	}
+
+	return i
+}
+
+// atoi returns the count of a label, zero when invalid
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// atof returns the size of a label, zero when invalid
+func atof(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f
+}
+
+// inventoryInstance returns the instance of a node estimated from its
+// inventory, with its embodied emissions over the scraping interval spread
+// over the lifespan of the servers
+func (a *Agent) inventoryInstance(name string, i *inventory) v1.Instance {
+	embodied, known := i.Embodied()
+
+	quality := v1.Quality{Power: v1.PowerFallback}
+	if known {
+		quality.Power = v1.PowerCurve
+	}
+
+	instance := v1.Instance{
+		Provider: v1.Prometheus,
+		Service:  inventoryService,
+		Name:     name,
+		Region:   i.region,
+		Zone:     i.zone,
+		Kind:     i.CPUModel,
+		Metrics:  v1.Metrics{},
+		Labels:   v1.Labels{"cluster": a.cluster},
+		// kgCO2e to gCO2e spread over every hour of the lifespan
+		EmbodiedEmissions: v1.NewResourceEmission(
+			embodied*1000/(a.lifespan*24*365)*a.scrapingInterval.Hours(),
+			v1.GCO2eqkWh,
+		),
+	}
+	instance.EmbodiedEmissions.Quality = quality
+
+	return instance
+}
+
+// estimate adds the instances of the nodes without a cloud instance to the
+// emissions, the key is the node name, and publishes them once every
+// scraping interval so that they're stored and exported like the scraped
+// ones
+func (a *Agent) estimate(ctx context.Context, nodes map[string]node, emissions map[string]v1.Instance) {
+	if a.lifespan <= 0 {
+		return
+	}
+
+	var estimated []v1.Instance
+	for name, n := range nodes {
+		if _, ok := emissions[name]; ok || n.inventory == nil {
+			continue
+		}
+		instance := a.inventoryInstance(name, n.inventory)
+		emissions[name] = instance
+		estimated = append(estimated, instance)
+	}
+
+	now := time.Now()
+	if a.bus == nil || now.Sub(a.published) < a.scrapingInterval {
+		return
+	}
+	a.published = now
+
+	for i := range estimated {
+		if err := a.bus.PublishContext(ctx, &bus.Event{
+			Type: v1.EmissionsCalculatedEvent,
+			Data: estimated[i],
+		}); err != nil {
+			a.logger.Error("failed publishing the node estimated from its inventory", "node", estimated[i].Name, "error", err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package attribution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	jobsResource   = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	eventsResource = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+)
+
+// How many completed jobs are kept
+const maxCompletedJobs = 1000
+
+// The statuses of the completed jobs
+const (
+	JobComplete = "Complete"
+	JobFailed   = "Failed"
+
+	// The job was deleted before its completion was seen
+	JobUnknown = "Unknown"
+)
+
+// Job is the emissions of the pods of a Job over its lifetime
+type Job struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// The CronJob that created the job, if any
+	CronJob string `json:"cronJob,omitempty"`
+
+	// Complete, Failed or Unknown, empty while running
+	Status string `json:"status,omitempty"`
+
+	// When the pods of the job were first and last seen running
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// The emissions in gCO2eq
+	Emissions float64 `json:"emissions"`
+}
+
+// Jobs returns the completed jobs, the latest first
+func (a *Agent) Jobs() []Job {
+	a.jobsMu.RLock()
+	defer a.jobsMu.RUnlock()
+
+	out := make([]Job, len(a.completed))
+	for i := range a.completed {
+		out[len(out)-1-i] = a.completed[i]
+	}
+	return out
+}
+
+// accumulateJobs adds the emissions of the pods since the last run to their
+// jobs. The emissions of the pods are over the scraping interval, they are
+// scaled to the time elapsed since the last run
+func (a *Agent) accumulateJobs(pods []Pod) {
+	a.jobsMu.Lock()
+	defer a.jobsMu.Unlock()
+
+	now := time.Now()
+	elapsed := a.interval
+	if !a.lastRun.IsZero() {
+		elapsed = now.Sub(a.lastRun)
+	}
+	a.lastRun = now
+
+	ratio := 1.0
+	if a.scrapingInterval > 0 {
+		ratio = elapsed.Seconds() / a.scrapingInterval.Seconds()
+	}
+
+	for i := range pods {
+		p := &pods[i]
+		if p.Job == "" {
+			continue
+		}
+
+		key := p.Namespace + "/" + p.Job
+		job, ok := a.running[key]
+		if !ok {
+			job = &Job{Namespace: p.Namespace, Name: p.Job, Start: now}
+			a.running[key] = job
+		}
+		job.End = now
+		job.Emissions += p.Emissions * ratio
+	}
+}
+
+// completeJobs records the running jobs which completed, with an event on
+// the Job reporting its emissions
+func (a *Agent) completeJobs(ctx context.Context) error {
+	a.jobsMu.RLock()
+	tracked := len(a.running)
+	a.jobsMu.RUnlock()
+
+	if tracked == 0 {
+		return nil
+	}
+
+	list, err := a.client.Resource(jobsResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed listing the jobs: %w", err)
+	}
+
+	a.jobsMu.Lock()
+	defer a.jobsMu.Unlock()
+
+	seen := make(map[string]bool, len(list.Items))
+	for i := range list.Items {
+		obj := &list.Items[i]
+		key := obj.GetNamespace() + "/" + obj.GetName()
+		seen[key] = true
+
+		job, ok := a.running[key]
+		if !ok {
+			continue
+		}
+
+		job.Status = jobStatus(obj)
+		if job.Status == "" {
+			continue
+		}
+
+		for _, owner := range obj.GetOwnerReferences() {
+			if owner.Kind == "CronJob" {
+				job.CronJob = owner.Name
+			}
+		}
+
+		a.complete(key)
+
+		if err := a.recordEvent(ctx, obj, job); err != nil {
+			a.logger.Error("failed recording the emissions of the job", "namespace", job.Namespace, "job", job.Name, "error", err)
+		}
+	}
+
+	// the jobs can be deleted as soon as they complete
+	for key, job := range a.running {
+		if !seen[key] {
+			job.Status = JobUnknown
+			a.complete(key)
+		}
+	}
+
+	return nil
+}
+
+// complete moves a running job to the completed ones
+// NOTE: the caller must hold the lock
+func (a *Agent) complete(key string) {
+	job := a.running[key]
+	delete(a.running, key)
+
+	a.completed = append(a.completed, *job)
+	if len(a.completed) > maxCompletedJobs {
+		a.completed = append([]Job(nil), a.completed[len(a.completed)-maxCompletedJobs:]...)
+	}
+
+	a.logger.Info("job completed", "namespace", job.Namespace, "job", job.Name, "status", job.Status, "emissions", job.Emissions)
+}
+
+// jobStatus returns whether the job completed or failed, empty if it's
+// still running
+func jobStatus(obj *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["status"] != "True" {
+			continue
+		}
+
+		switch condition["type"] {
+		case JobComplete:
+			return JobComplete
+		case JobFailed:
+			return JobFailed
+		}
+	}
+
+	return ""
+}
+
+// recordEvent creates an event on the job with its emissions, shown by
+// kubectl describe job
+func (a *Agent) recordEvent(ctx context.Context, obj *unstructured.Unstructured, job *Job) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	event := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]interface{}{
+			"name":      fmt.Sprintf("%s.%x", job.Name, time.Now().UnixNano()),
+			"namespace": job.Namespace,
+		},
+		"involvedObject": map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"namespace":  job.Namespace,
+			"name":       job.Name,
+			"uid":        string(obj.GetUID()),
+		},
+		"reason":         "Emissions",
+		"message":        fmt.Sprintf("The job emitted %.2f gCO2eq", job.Emissions),
+		"type":           "Normal",
+		"source":         map[string]interface{}{"component": "aether"},
+		"firstTimestamp": now,
+		"lastTimestamp":  now,
+		"count":          int64(1),
+	}}
+
+	_, err := a.client.Resource(eventsResource).Namespace(job.Namespace).Create(ctx, event, metav1.CreateOptions{})
+	return err
+}
@@ -0,0 +1,90 @@
+package attribution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestJobs(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	pod := newPod("batch", "train-x1", "node-a", "Running", nil)
+	pod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "train"}})
+
+	job := newObject("batch/v1", "Job", "batch", "train", map[string]interface{}{})
+	job.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "nightly"}})
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			nodesResource:      "NodeList",
+			podsResource:       "PodList",
+			podMetricsResource: "PodMetricsList",
+			jobsResource:       "JobList",
+			eventsResource:     "EventList",
+		},
+		newObject("v1", "Node", "", "node-a", nil),
+		newPod("web", "shop", "node-a", "Running", nil),
+		pod,
+		job,
+	)
+
+	for _, m := range []*unstructured.Unstructured{
+		newPodMetrics("web", "shop", map[string]interface{}{"cpu": "1"}),
+		newPodMetrics("batch", "train-x1", map[string]interface{}{"cpu": "3"}),
+	} {
+		assert.NoError(client.Tracker().Create(podMetricsResource, m, m.GetNamespace()))
+	}
+
+	a, err := newAgent(ctx, client, &config.AttributionConfig{Interval: time.Minute})
+	assert.NoError(err)
+	a.scrapingInterval = time.Minute
+
+	instance := v1.Instance{Name: "node-a", Provider: v1.GCP, Metrics: v1.Metrics{}}
+	instance.Metrics.Upsert(&v1.Metric{Name: "cpu", ResourceType: v1.CPU, Emissions: v1.NewResourceEmission(40, v1.GCO2eqkWh)})
+	a.Handle(ctx, &bus.Event{Type: v1.EmissionsCalculatedEvent, Data: instance})
+
+	// the job uses 3/4 of the node over a whole scraping interval
+	assert.NoError(a.attribute(ctx))
+	assert.Empty(a.Jobs())
+	assert.Equal("train", a.Pods()[0].Job)
+
+	// then over half of it before completing
+	a.lastRun = time.Now().Add(-30 * time.Second)
+	assert.NoError(unstructured.SetNestedSlice(job.Object, []interface{}{
+		map[string]interface{}{"type": "Complete", "status": "True"},
+	}, "status", "conditions"))
+	_, err = client.Resource(jobsResource).Namespace("batch").UpdateStatus(ctx, job, metav1.UpdateOptions{})
+	assert.NoError(err)
+
+	assert.NoError(a.attribute(ctx))
+
+	jobs := a.Jobs()
+	assert.Len(jobs, 1)
+	assert.Equal("train", jobs[0].Name)
+	assert.Equal("nightly", jobs[0].CronJob)
+	assert.Equal(JobComplete, jobs[0].Status)
+	assert.InDelta(45, jobs[0].Emissions, 0.1)
+
+	events, err := client.Resource(eventsResource).Namespace("batch").List(ctx, metav1.ListOptions{})
+	assert.NoError(err)
+	assert.Len(events.Items, 1)
+	message, _, _ := unstructured.NestedString(events.Items[0].Object, "message")
+	assert.Contains(message, "gCO2eq")
+
+	// the jobs deleted before their completion is seen are recorded too
+	a.running["batch/gone"] = &Job{Namespace: "batch", Name: "gone"}
+	assert.NoError(a.completeJobs(ctx))
+	assert.Equal(JobUnknown, a.Jobs()[0].Status)
+}
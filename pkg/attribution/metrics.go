@@ -0,0 +1,144 @@
+package attribution
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/re-cinq/aether/pkg/cardinality"
+	"github.com/re-cinq/aether/pkg/units"
+)
+
+var (
+	// The emissions of the pods, attributed from the emissions of their node
+	podEmissions = &podCollector{}
+
+	// The emissions of the namespaces, the sum of the emissions of their pods
+	namespaceEmissions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "namespace_emissions",
+			Help: "co2eq of the pods of a namespace over the scraping interval",
+		},
+		[]string{"namespace", "unit"},
+	)
+
+	// The emissions of the clusters that aren't attributed to the pods
+	overheadEmissions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_overhead_emissions",
+			Help: "co2eq of a cluster not attributed to the pods over the scraping interval, by type: system, unallocated or control_plane",
+		},
+		[]string{"cluster", "type", "unit"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		podEmissions,
+		namespaceEmissions,
+		overheadEmissions,
+	)
+}
+
+// podCollector exports the pod_emissions metric, its label names depend on
+// the copied pod labels and the relabeling, so they are only known once the
+// pods are attributed
+type podCollector struct {
+	pods    []Pod
+	output  units.Output
+	limiter *cardinality.Limiter
+	mu      sync.RWMutex
+}
+
+// set replaces the exported pods, the unit of their emissions and the
+// limiter of their series
+func (c *podCollector) set(pods []Pod, output units.Output, limiter *cardinality.Limiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pods = pods
+	c.output = output
+	c.limiter = limiter
+}
+
+// Describe sends no descriptors, which makes it an unchecked collector
+func (c *podCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect sends the emissions of every pod
+// All the series have the same label names, the labels a pod doesn't have
+// are empty. The pods whose labels are the same after the limiter are summed
+func (c *podCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	type series struct {
+		labels map[string]string
+		value  float64
+	}
+
+	all := make([]series, 0, len(c.pods))
+	seen := make(map[string]bool)
+	for i := range c.pods {
+		p := &c.pods[i]
+
+		labels := make(map[string]string, len(p.Labels)+4)
+		for name, value := range p.Labels {
+			if model.LabelName(name).IsValid() {
+				labels[name] = value
+			}
+		}
+		// the fixed labels take precedence
+		labels["namespace"] = p.Namespace
+		labels["pod"] = p.Name
+		labels["node"] = p.Node
+		labels["unit"] = string(c.output.Unit())
+
+		if c.limiter != nil {
+			var ok bool
+			if labels, ok = c.limiter.Labels(labels); !ok {
+				continue
+			}
+		}
+
+		for name := range labels {
+			seen[name] = true
+		}
+		all = append(all, series{labels: labels, value: p.Emissions})
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	desc := prometheus.NewDesc(
+		"pod_emissions",
+		"co2eq of a pod over the scraping interval, attributed from the emissions of its node by CPU and memory usage",
+		names,
+		nil,
+	)
+
+	sums := make(map[string]float64, len(all))
+	values := make(map[string][]string, len(all))
+	order := make([]string, 0, len(all))
+	for _, s := range all {
+		v := make([]string, len(names))
+		for i, name := range names {
+			v[i] = s.labels[name]
+		}
+
+		key := strings.Join(v, "\xff")
+		if _, ok := sums[key]; !ok {
+			values[key] = v
+			order = append(order, key)
+		}
+		sums[key] += s.value
+	}
+
+	for _, key := range order {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, c.output.Convert(sums[key]), values[key]...)
+	}
+}
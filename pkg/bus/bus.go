@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 )
 
 var (
@@ -34,6 +35,12 @@ type Bus struct {
 	// syncing functionality
 	wg sync.WaitGroup
 	mu sync.RWMutex
+
+	// The events published and not handled yet, and the channel closed once
+	// they all are
+	pending   int
+	idle      chan struct{}
+	pendingMu sync.Mutex
 }
 
 // Event is the main type that gets sent thr9ough the bus
@@ -41,6 +48,11 @@ type Bus struct {
 type Event struct {
 	Type EventType
 	Data interface{}
+
+	// The time by which the event must be handled, zero when it has none.
+	// The events past their deadline are dropped instead of handled late,
+	// and the handlers are given a context with the deadline
+	Deadline time.Time
 }
 
 // EventHandler is an interface that callers can use
@@ -110,11 +122,75 @@ func (b *Bus) Publish(e *Event) error {
 		return errors.New("bus has shutdown")
 	}
 
+	b.add()
 	b.queue <- e
 
 	return nil
 }
 
+// PublishContext publishes the event with the deadline of the context, if
+// any. It waits for room in the queue until the context is done
+func (b *Bus) PublishContext(ctx context.Context, e *Event) error {
+	// if shutdown we no longer allow publishing
+	if b.shutdown {
+		return errors.New("bus has shutdown")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		e.Deadline = deadline
+	}
+
+	b.add()
+	select {
+	case b.queue <- e:
+		return nil
+	case <-ctx.Done():
+		b.done()
+		return ctx.Err()
+	}
+}
+
+// Wait waits until the events published, and the ones published by their
+// handlers, are handled or the context is done
+func (b *Bus) Wait(ctx context.Context) error {
+	b.pendingMu.Lock()
+	if b.pending == 0 {
+		b.pendingMu.Unlock()
+		return nil
+	}
+	idle := b.idle
+	b.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// add counts an event published
+func (b *Bus) add() {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	if b.pending == 0 {
+		b.idle = make(chan struct{})
+	}
+	b.pending++
+}
+
+// done counts an event handled
+func (b *Bus) done() {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	b.pending--
+	if b.pending == 0 {
+		close(b.idle)
+	}
+}
+
 // Start Bus by starting the workers
 func (b *Bus) Start(ctx context.Context) {
 	for i := 0; i < b.workers; i++ {
@@ -146,8 +222,10 @@ func (b *Bus) worker(ctx context.Context) {
 				// the likelihood of race conditions
 				e := *event
 
-				sub.Handle(ctx, &e)
+				handle(ctx, sub, &e)
 			}
+
+			b.done()
 		case <-ctx.Done():
 			// if context is canceled
 			// we should stop the worker
@@ -156,6 +234,23 @@ func (b *Bus) worker(ctx context.Context) {
 	}
 }
 
+// handle passes the event to the handler with the deadline of the event,
+// the event is dropped if it's past its deadline
+func handle(ctx context.Context, h EventHandler, e *Event) {
+	if !e.Deadline.IsZero() {
+		if !time.Now().Before(e.Deadline) {
+			expiredEvents.Inc()
+			return
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, e.Deadline)
+		defer cancel()
+	}
+
+	h.Handle(ctx, e)
+}
+
 // Stop the Bus gracefully by waiting for workers to exit
 func (b *Bus) Stop(ctx context.Context) {
 	b.mu.Lock()
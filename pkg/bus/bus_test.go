@@ -3,7 +3,9 @@ package bus
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -73,3 +75,98 @@ func TestBus(t *testing.T) {
 
 	b.Stop(ctx)
 }
+
+// deadlineHandler records the deadlines of the contexts of the events
+type deadlineHandler struct {
+	deadlines chan time.Time
+}
+
+func (h *deadlineHandler) Handle(ctx context.Context, e *Event) {
+	deadline, _ := ctx.Deadline()
+	h.deadlines <- deadline
+}
+
+func (h *deadlineHandler) Stop(ctx context.Context) {}
+
+func TestBusDeadline(t *testing.T) {
+	assert := require.New(t)
+
+	var topic EventType = 1
+
+	h := &deadlineHandler{deadlines: make(chan time.Time, 10)}
+
+	b := New(WithWorkers(1), WithBufferSize(10))
+	b.Subscribe(topic, h)
+	b.Start(context.Background())
+
+	// past its deadline, dropped
+	assert.NoError(b.Publish(&Event{Type: topic, Deadline: time.Now().Add(-time.Second)}))
+
+	// with the deadline of the publisher
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	assert.NoError(b.PublishContext(ctx, &Event{Type: topic}))
+
+	// without deadline
+	assert.NoError(b.Publish(&Event{Type: topic}))
+
+	assert.True(deadline.Equal(<-h.deadlines))
+	assert.True((<-h.deadlines).IsZero())
+
+	b.Stop(context.Background())
+	assert.Empty(h.deadlines)
+
+	// a full queue is not waited for once the context is done
+	full := New(WithBufferSize(0))
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(full.PublishContext(ctx, &Event{Type: topic}), context.DeadlineExceeded)
+}
+
+// chainHandler publishes an event of the next topic, like the calculator
+type chainHandler struct {
+	bus     *Bus
+	next    EventType
+	handled atomic.Int32
+}
+
+func (h *chainHandler) Handle(ctx context.Context, e *Event) {
+	time.Sleep(10 * time.Millisecond)
+	h.handled.Add(1)
+	if e.Type != h.next {
+		_ = h.bus.PublishContext(ctx, &Event{Type: h.next})
+	}
+}
+
+func (h *chainHandler) Stop(ctx context.Context) {}
+
+func TestBusWait(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	var collected, calculated EventType = 1, 2
+
+	b := New(WithWorkers(2), WithBufferSize(10))
+	h := &chainHandler{bus: b, next: calculated}
+	b.Subscribe(collected, h)
+	b.Subscribe(calculated, h)
+	b.Start(ctx)
+
+	assert.NoError(b.Wait(ctx))
+
+	// the events published by the handlers are waited for
+	for range 3 {
+		assert.NoError(b.Publish(&Event{Type: collected}))
+	}
+	assert.NoError(b.Wait(ctx))
+	assert.Equal(int32(6), h.handled.Load())
+
+	assert.NoError(b.Publish(&Event{Type: collected}))
+	timeout, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(b.Wait(timeout), context.DeadlineExceeded)
+
+	assert.NoError(b.Wait(ctx))
+	b.Stop(ctx)
+}
@@ -0,0 +1,16 @@
+package bus
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// The amount of events dropped because they were past their deadline
+var expiredEvents = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "cloud_carbon",
+		Name:      "bus_expired_events_total",
+		Help:      "The amount of events dropped by the bus because they were past their deadline",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(expiredEvents)
+}
@@ -0,0 +1,63 @@
+package calculator
+
+import (
+	"strconv"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// defaultAcceleratorEmbodied is the embodied emissions in kgCO2e of the
+// accelerators of unknown type, CCF's additional manufacturing emissions of
+// a GPU
+const defaultAcceleratorEmbodied = 150.0
+
+// Accelerators are the accelerators attached to an instance, GPUs or TPUs,
+// and their hourly embodied emissions, included in its embodied factor
+type Accelerators struct {
+	Type           string  `json:"type"`
+	Count          float64 `json:"count"`
+	EmbodiedFactor float64 `json:"embodiedHourlyFactor"`
+}
+
+// acceleratorParameters adds the embodied emissions of the accelerators of
+// the instance on top of its share of the server. The accelerators are the
+// ones of its labels, set by the providers which know them, or the ones of
+// its kind in the emission factors. Their embodied emissions are spread over
+// the lifespan of the servers of the region, they aren't shared with other
+// instances
+func (l *Locations) acceleratorParameters(p *parameters, emFactors *factors.EmissionFactors, provider v1.Provider, region, kind string, labels v1.Labels) {
+	accelerator, count := labels[v1.AcceleratorLabel], 1.0
+	if c, err := strconv.ParseFloat(labels[v1.AcceleratorCountLabel], 64); err == nil {
+		count = c
+	}
+	if accelerator == "" {
+		k, ok := emFactors.KindAccelerators[kind]
+		if !ok {
+			return
+		}
+		accelerator, count = k.Accelerator, k.Count
+	}
+	if count <= 0 {
+		return
+	}
+
+	embodied := defaultAcceleratorEmbodied
+	if a, ok := emFactors.Accelerators[accelerator]; ok {
+		embodied = a.EmbodiedKgCO2e
+	}
+
+	lifespan := float64(serverLifespan)
+	if r, ok := l.customRegion(provider, region); ok && r.Lifespan > 0 {
+		lifespan = r.Lifespan
+	}
+
+	// kgCO2e to gCO2e spread over every hour of the lifespan
+	factor := count * embodied * 1000 / (lifespan * 24 * 365)
+	p.embodiedFactor += factor
+	p.accelerators = &Accelerators{
+		Type:           accelerator,
+		Count:          count,
+		EmbodiedFactor: factor,
+	}
+}
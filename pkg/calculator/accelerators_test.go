@@ -0,0 +1,85 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceleratorParameters(t *testing.T) {
+	assert := require.New(t)
+
+	l := NewLocations(&config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {Regions: map[string]config.RegionConfig{
+				"edge-paris-1": {Lifespan: 3},
+			}},
+		},
+	})
+
+	emFactors := &factors.EmissionFactors{
+		Provider: v1.AWS,
+		Accelerators: factors.AcceleratorData{
+			"a100": {Type: "a100", EmbodiedKgCO2e: 219},
+		},
+		KindAccelerators: factors.KindAcceleratorData{
+			"p4d.24xlarge": {Kind: "p4d.24xlarge", Accelerator: "a100", Count: 8},
+		},
+	}
+
+	type testcase struct {
+		description string
+		region      string
+		kind        string
+		labels      v1.Labels
+		expected    *Accelerators
+	}
+	tt := []testcase{
+		{
+			description: "the accelerators of the kind",
+			kind:        "p4d.24xlarge",
+			// 8 * 219 kg over 6 years
+			expected: &Accelerators{Type: "a100", Count: 8, EmbodiedFactor: 33.333333333333336},
+		},
+		{
+			description: "the accelerators of the labels come first",
+			kind:        "p4d.24xlarge",
+			labels:      v1.Labels{v1.AcceleratorLabel: "a100", v1.AcceleratorCountLabel: "2"},
+			expected:    &Accelerators{Type: "a100", Count: 2, EmbodiedFactor: 8.333333333333334},
+		},
+		{
+			description: "a single accelerator of unknown type without count",
+			kind:        "custom",
+			labels:      v1.Labels{v1.AcceleratorLabel: "tpu-v4"},
+			// the default 150 kg over 6 years
+			expected: &Accelerators{Type: "tpu-v4", Count: 1, EmbodiedFactor: 2.853881278538813},
+		},
+		{
+			description: "spread over the lifespan of the region",
+			region:      "edge-paris-1",
+			kind:        "p4d.24xlarge",
+			expected:    &Accelerators{Type: "a100", Count: 8, EmbodiedFactor: 66.66666666666667},
+		},
+		{
+			description: "no accelerators",
+			kind:        "m5.large",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			p := parameters{embodiedFactor: 10}
+			l.acceleratorParameters(&p, emFactors, v1.AWS, tc.region, tc.kind, tc.labels)
+			assert.Equal(tc.expected, p.accelerators)
+
+			expected := 10.0
+			if tc.expected != nil {
+				expected += tc.expected.EmbodiedFactor
+			}
+			assert.InDelta(expected, p.embodiedFactor, 1e-9)
+		})
+	}
+}
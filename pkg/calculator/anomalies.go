@@ -0,0 +1,59 @@
+package calculator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The types of the physically implausible values of the calculations
+const (
+	// The utilization isn't a percentage, e.g. the samples of the
+	// burstable instances
+	AnomalyUtilization = "utilization_out_of_range"
+
+	// The power is negative, e.g. the spline of the wattage curve
+	// overshooting below zero at a low utilization
+	AnomalyNegativePower = "negative_power"
+
+	// The power exceeds the highest wattage of the curve, e.g. the spline
+	// overshooting between its points
+	AnomalyPowerAboveMax = "power_above_max"
+
+	// The operational emissions are negative
+	AnomalyNegativeEmissions = "negative_emissions"
+)
+
+// The physically implausible values, which are clamped
+var calculationAnomalies = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "calculation_anomalies_total",
+		Help: "The physically implausible values of the calculations which were clamped, by type",
+	},
+	[]string{"provider", "type"},
+)
+
+func init() {
+	prometheus.MustRegister(calculationAnomalies)
+}
+
+// Anomaly is a physically implausible value of a calculation, which was
+// replaced by the closest plausible one
+type Anomaly struct {
+	Type    string  `json:"type"`
+	Value   float64 `json:"value"`
+	Clamped float64 `json:"clamped"`
+}
+
+// clamp returns the value within [lower, upper], the values out of it are
+// recorded as an anomaly of the type below or above
+func (p *parameters) clamp(value, lower, upper float64, below, above string) float64 {
+	switch {
+	case value < lower:
+		p.anomalies = append(p.anomalies, Anomaly{Type: below, Value: value, Clamped: lower})
+		return lower
+	case value > upper:
+		p.anomalies = append(p.anomalies, Anomaly{Type: above, Value: value, Clamped: upper})
+		return upper
+	default:
+		return value
+	}
+}
@@ -0,0 +1,93 @@
+package calculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClamp(t *testing.T) {
+	assert := require.New(t)
+
+	p := parameters{}
+	assert.Equal(5.0, p.clamp(5, 0, 10, "below", "above"))
+	assert.Empty(p.anomalies)
+
+	assert.Equal(0.0, p.clamp(-1, 0, 10, "below", "above"))
+	assert.Equal(10.0, p.clamp(12, 0, 10, "below", "above"))
+	assert.Equal([]Anomaly{
+		{Type: "below", Value: -1, Clamped: 0},
+		{Type: "above", Value: 12, Clamped: 10},
+	}, p.anomalies)
+}
+
+func TestCPUAnomalies(t *testing.T) {
+	// the spline overshoots the highest wattage, 101 W, between 50% and
+	// 100% of utilization
+	wattage := []data.Wattage{
+		{Percentage: 0, Wattage: 1},
+		{Percentage: 10, Wattage: 2},
+		{Percentage: 50, Wattage: 100},
+		{Percentage: 100, Wattage: 101},
+	}
+
+	for _, test := range []struct {
+		description string
+		usage       float64
+		anomalies   []string
+		// the power in W
+		power float64
+	}{
+		{
+			description: "a plausible utilization",
+			usage:       100,
+			power:       101,
+		},
+		{
+			description: "the utilization is clamped to 100%",
+			usage:       150,
+			anomalies:   []string{AnomalyUtilization},
+			power:       101,
+		},
+		{
+			description: "the power is clamped to the highest wattage",
+			usage:       70,
+			anomalies:   []string{AnomalyPowerAboveMax},
+			power:       101,
+		},
+		{
+			description: "a negative utilization is clamped to idle",
+			usage:       -5,
+			anomalies:   []string{AnomalyUtilization},
+			power:       1,
+		},
+	} {
+		t.Run(test.description, func(t *testing.T) {
+			assert := require.New(t)
+
+			p := parameters{
+				pue:      1,
+				gridCO2e: 1000,
+				wattage:  wattage,
+				vCPU:     1,
+				metric:   v1.Metric{Name: v1.CPU.String(), Usage: test.usage},
+			}
+
+			// 1 vCPU over an hour at 1000 gCO2eq/kWh, the emissions are
+			// the power in W
+			emissions, err := cpu(context.TODO(), time.Hour, &p)
+			assert.NoError(err)
+			assert.InDelta(test.power, emissions, 1e-9)
+
+			var types []string
+			for _, a := range p.anomalies {
+				types = append(types, a.Type)
+			}
+			assert.Equal(test.anomalies, types)
+		})
+	}
+}
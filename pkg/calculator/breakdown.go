@@ -0,0 +1,214 @@
+package calculator
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+)
+
+// Breakdown explains the most recent calculation of an instance: the inputs
+// that were used and every step of the math
+type Breakdown struct {
+	Provider v1.Provider `json:"provider"`
+	Account  string      `json:"account,omitempty"`
+	Name     string      `json:"name"`
+	Region   string      `json:"region"`
+	Zone     string      `json:"zone"`
+	Kind     string      `json:"kind"`
+
+	// When the calculation ran and the interval it covers. The instance
+	// is observed for a part of the interval only when it was launched or
+	// terminated during it, the emissions are prorated
+	CalculatedAt time.Time     `json:"calculatedAt"`
+	Interval     time.Duration `json:"interval"`
+	Observed     time.Duration `json:"observed"`
+
+	// The factors used by the calculation, the wattage is shared by the
+	// breakdowns of the same wattage curve and must not be modified
+	GridCO2e       float64        `json:"gridCO2e"`
+	GridFallback   string         `json:"gridFallback,omitempty"`
+	PUE            float64        `json:"pue"`
+	PUESource      string         `json:"pueSource"`
+	VCPU           float64        `json:"vCPU"`
+	Architecture   string         `json:"architecture,omitempty"`
+	Wattage        []WattagePoint `json:"wattage"`
+	EmbodiedFactor float64        `json:"embodiedHourlyFactor"`
+	Accelerators   *Accelerators  `json:"accelerators,omitempty"`
+
+	// The uplift in % of the embodied factor for the shared networking and
+	// storage infrastructure of the data centers, included in it
+	EmbodiedOverhead float64 `json:"embodiedOverhead,omitempty"`
+
+	// The emissions of every metric
+	Metrics []MetricBreakdown `json:"metrics"`
+
+	// The embodied emissions over the interval
+	Embodied        Step       `json:"embodied"`
+	EmbodiedQuality v1.Quality `json:"embodiedQuality"`
+}
+
+// WattagePoint is a point of the wattage curve, the power drawn at a given
+// utilization
+type WattagePoint struct {
+	Percentage int     `json:"percentage"`
+	Watts      float64 `json:"watts"`
+}
+
+// MetricBreakdown explains the operational emissions of a metric
+type MetricBreakdown struct {
+	Name       string  `json:"name"`
+	Usage      float64 `json:"usage"`
+	UnitAmount float64 `json:"unitAmount"`
+	Unit       string  `json:"unit"`
+
+	// The steps of the calculation, the last one is the result
+	Steps []Step `json:"steps"`
+
+	// The operational emissions in gCO2eq and how they were calculated
+	Emissions float64    `json:"emissions"`
+	Quality   v1.Quality `json:"quality"`
+
+	// Why the emissions could not be calculated
+	Error string `json:"error,omitempty"`
+
+	// The physically implausible values which were clamped
+	Anomalies []Anomaly `json:"anomalies,omitempty"`
+}
+
+// Step is a single step of a calculation
+type Step struct {
+	Description string  `json:"description"`
+	Formula     string  `json:"formula"`
+	Value       float64 `json:"value"`
+}
+
+// formula formats the formula of a step, every %g of the layout is replaced
+// by the next value as fmt.Sprintf would, the rest is copied as is. Unlike
+// fmt.Sprintf the values aren't boxed, the formulas of every calculation
+// only allocate their string
+func formula(layout string, values ...float64) string {
+	var buf [128]byte
+	out := buf[:0]
+
+	for _, v := range values {
+		i := strings.Index(layout, "%g")
+		if i < 0 {
+			break
+		}
+		out = append(out, layout[:i]...)
+		out = strconv.AppendFloat(out, v, 'g', -1, 64)
+		layout = layout[i+2:]
+	}
+	out = append(out, layout...)
+
+	return string(out)
+}
+
+// wattagePoints converts the wattage curve of the dataset
+func wattagePoints(wattage []data.Wattage) []WattagePoint {
+	points := make([]WattagePoint, 0, len(wattage))
+	for _, w := range wattage {
+		points = append(points, WattagePoint{
+			Percentage: w.Percentage,
+			Watts:      w.Wattage,
+		})
+	}
+	return points
+}
+
+var (
+	// ErrBreakdownNotFound is returned when no instance of the name was
+	// calculated within the staleness window
+	ErrBreakdownNotFound = errors.New("no calculation found for the instance")
+
+	// ErrAmbiguousInstance is returned when instances of several providers
+	// or accounts have the name
+	ErrAmbiguousInstance = errors.New("several instances have the name, set their provider and account")
+)
+
+// instanceKey identifies an instance, the names are only unique within an
+// account of a provider
+type instanceKey struct {
+	provider v1.Provider
+	account  string
+}
+
+// breakdowns keeps the latest breakdown of every instance, the ones not
+// calculated within the staleness are evicted
+type breakdowns struct {
+	latest    map[string]map[instanceKey]Breakdown
+	staleness time.Duration
+	evicted   time.Time
+	mu        sync.RWMutex
+}
+
+// set stores the breakdown of the instance and evicts the stale ones, at
+// most once per staleness
+func (b *breakdowns) set(br *Breakdown) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.latest == nil {
+		b.latest = make(map[string]map[instanceKey]Breakdown)
+	}
+	named, ok := b.latest[br.Name]
+	if !ok {
+		named = make(map[instanceKey]Breakdown)
+		b.latest[br.Name] = named
+	}
+	named[instanceKey{provider: br.Provider, account: br.Account}] = *br
+
+	if b.staleness > 0 && br.CalculatedAt.Sub(b.evicted) >= b.staleness {
+		b.evict(br.CalculatedAt.Add(-b.staleness))
+		b.evicted = br.CalculatedAt
+	}
+}
+
+// evict removes the breakdowns calculated before the cutoff
+func (b *breakdowns) evict(cutoff time.Time) {
+	for name, named := range b.latest {
+		for key, br := range named {
+			if br.CalculatedAt.Before(cutoff) {
+				delete(named, key)
+			}
+		}
+		if len(named) == 0 {
+			delete(b.latest, name)
+		}
+	}
+}
+
+// get returns the latest breakdown of the instance, the provider and the
+// account are only needed when several instances have the name
+func (b *breakdowns) get(provider v1.Provider, account, name string) (Breakdown, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var found []Breakdown
+	for key, br := range b.latest[name] {
+		if provider != "" && key.provider != provider {
+			continue
+		}
+		if account != "" && key.account != account {
+			continue
+		}
+		if b.staleness > 0 && time.Since(br.CalculatedAt) > b.staleness {
+			continue
+		}
+		found = append(found, br)
+	}
+
+	switch len(found) {
+	case 0:
+		return Breakdown{}, ErrBreakdownNotFound
+	case 1:
+		return found[0], nil
+	default:
+		return Breakdown{}, ErrAmbiguousInstance
+	}
+}
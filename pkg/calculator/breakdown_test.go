@@ -0,0 +1,86 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakdowns(t *testing.T) {
+	now := time.Now().UTC()
+
+	b := breakdowns{staleness: 15 * time.Minute}
+	b.set(&Breakdown{Provider: v1.AWS, Account: "prod", Name: "web", GridCO2e: 1, CalculatedAt: now.Add(-time.Minute)})
+	b.set(&Breakdown{Provider: v1.AWS, Account: "dev", Name: "web", GridCO2e: 2, CalculatedAt: now.Add(-time.Minute)})
+	b.set(&Breakdown{Provider: v1.GCP, Account: "prod", Name: "db", GridCO2e: 3, CalculatedAt: now.Add(-time.Hour)})
+	b.set(&Breakdown{Provider: v1.GCP, Account: "prod", Name: "api", GridCO2e: 4, CalculatedAt: now})
+
+	type testcase struct {
+		name     string
+		provider v1.Provider
+		account  string
+		instance string
+		gridCO2e float64
+		err      error
+	}
+
+	for _, test := range []testcase{
+		{
+			name:     "the name is unique",
+			instance: "api",
+			gridCO2e: 4,
+		},
+		{
+			name:     "the name is shared by several accounts",
+			instance: "web",
+			err:      ErrAmbiguousInstance,
+		},
+		{
+			name:     "the account selects the instance",
+			account:  "dev",
+			instance: "web",
+			gridCO2e: 2,
+		},
+		{
+			name:     "the provider and the account select the instance",
+			provider: v1.AWS,
+			account:  "prod",
+			instance: "web",
+			gridCO2e: 1,
+		},
+		{
+			name:     "another provider has no instance of the name",
+			provider: v1.GCP,
+			instance: "web",
+			err:      ErrBreakdownNotFound,
+		},
+		{
+			name:     "the stale breakdowns are evicted",
+			instance: "db",
+			err:      ErrBreakdownNotFound,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			br, err := b.get(test.provider, test.account, test.instance)
+			if test.err != nil {
+				assert.ErrorIs(err, test.err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(test.gridCO2e, br.GridCO2e)
+		})
+	}
+
+	// the stale breakdowns are removed once the staleness has elapsed
+	// since the last eviction
+	assert := require.New(t)
+	assert.Contains(b.latest, "db")
+	b.set(&Breakdown{Provider: v1.GCP, Account: "prod", Name: "api", CalculatedAt: now.Add(16 * time.Minute)})
+	assert.NotContains(b.latest, "db")
+	assert.NotContains(b.latest, "web")
+	assert.Contains(b.latest, "api")
+}
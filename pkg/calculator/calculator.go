@@ -4,35 +4,81 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
-	"github.com/cnkei/gospline"
 	"github.com/re-cinq/aether/pkg/log"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
 	data "github.com/re-cinq/emissions-data/pkg/types/v2"
 )
 
 type parameters struct {
 	gridCO2e       float64
+	gridFallback   string
 	pue            float64
+	pueSource      string
 	wattage        []data.Wattage
-	metric         *v1.Metric
+	metric         v1.Metric
 	vCPU           float64
 	embodiedFactor float64
+
+	// The uplift of the embodied factor in % for the shared networking and
+	// storage infrastructure, included in it
+	embodiedOverhead float64
+
+	// The architecture whose wattage is used, empty for the defaults of
+	// the provider
+	architecture string
+
+	// Where the wattage and the embodied factor come from
+	power v1.PowerSource
+
+	// The memory coefficient of the provider in kWh per GB, the DIMMs of
+	// the hosts of the instance family when known, and the memory of the
+	// instance type in GB, zero when unknown
+	memoryKW float64
+	memory   *factors.MemorySpecs
+	memoryGB float64
+
+	// The accelerators of the instance whose embodied emissions are
+	// included in the embodied factor, nil without accelerators
+	accelerators *Accelerators
+
+	// The power and the energy of the I/O of the disks by media
+	disks map[string]diskFactors
+
+	// The networking coefficient of the provider in kWh per GB, and the
+	// ones of the destination classes of the flows
+	networkKW    float64
+	destinations map[string]float64
+
+	// The steps of the last calculation, used to explain it, and the
+	// implausible values it clamped
+	steps     []Step
+	anomalies []Anomaly
 }
 
 // operationalEmissions determines the correct function to run to calculate the
-// operational emissions for the metric type
+// operational emissions for the metric type. The metrics of the resources
+// which can be several, e.g. the disks named after their ID or the flows by
+// destination, have their type set, the other ones are named after it
 func operationalEmissions(ctx context.Context, interval time.Duration, p *parameters) (float64, error) {
-	switch p.metric.Name {
+	name := p.metric.Name
+	switch p.metric.ResourceType {
+	case v1.Storage, v1.Network:
+		name = p.metric.ResourceType.String()
+	}
+
+	switch name {
 	case v1.CPU.String():
 		return cpu(ctx, interval, p)
 	case v1.Memory.String():
-		return 0, errors.New("error memory is not yet being calculated")
+		return memory(ctx, interval, p)
 	case v1.Storage.String():
-		return 0, errors.New("error storage is not yet being calculated")
+		return storage(ctx, interval, p)
 	case v1.Network.String():
-		return 0, errors.New("error networking is not yet being calculated")
+		return network(ctx, interval, p)
 	default:
 		return 0, fmt.Errorf("error metric not supported: %+v", p.metric.Name)
 	}
@@ -45,6 +91,10 @@ func operationalEmissions(ctx context.Context, interval time.Duration, p *parame
 // turbostress to stress test the CPU on baremetal servers as inspired by Teads.
 // More information can be found in our docs/METHODOLOGIES.md
 func cpu(ctx context.Context, interval time.Duration, p *parameters) (float64, error) {
+	if p.metric.Power > 0 {
+		return measuredCPU(ctx, interval, p)
+	}
+
 	vCPU := p.vCPU
 	// vCPU are virtual CPUs that are mapped to physical cores (a core is a physical
 	// component to the CPU the VM is running on). If vCPU from the dataset (p.vCPU)
@@ -55,6 +105,11 @@ func cpu(ctx context.Context, interval time.Duration, p *parameters) (float64, e
 		}
 		vCPU = p.metric.UnitAmount
 	}
+	p.steps = append(p.steps, Step{
+		Description: "virtual CPUs of the instance",
+		Formula:     "vCPU",
+		Value:       vCPU,
+	})
 
 	// vCPUHours represents the count of virtual CPUs within a specific time frame.
 	// To get vCPUHours, we first get the interval in hours and multiply that by the
@@ -62,44 +117,89 @@ func cpu(ctx context.Context, interval time.Duration, p *parameters) (float64, e
 	// For example, if the machine has 4 vCPUs and an interval of time of 5 minutes
 	// The hourly time is 5/60 (0.083333333) * 4 vCPU = 0.33333334
 	vCPUHours := (interval.Minutes() / float64(60)) * vCPU
+	p.steps = append(p.steps, Step{
+		Description: "vCPU hours over the interval",
+		Formula:     formula("(%g min / 60) * %g vCPU", interval.Minutes(), vCPU),
+		Value:       vCPUHours,
+	})
 
 	// usageCPUkw is the CPU energy consumption in kilowatts.
 	// If pkgWatt values exist from the dataset, then use cubic spline interpolation
 	// to calculate the wattage based on utilization.
-	usageCPUkw, err := cubicSplineInterpolation(p.wattage, p.metric.Usage)
+	usage := p.clamp(p.metric.Usage, 0, 100, AnomalyUtilization, AnomalyUtilization)
+	usageCPUkw, err := cubicSplineInterpolation(p.wattage, usage)
 	if err != nil {
 		return 0, err
 	}
 
+	// the spline can overshoot the points of the curve, the power is
+	// bounded by the idle and the highest wattage
+	usageCPUkw = p.clamp(usageCPUkw, 0, curveOf(p.wattage).max/1000, AnomalyNegativePower, AnomalyPowerAboveMax)
+	p.steps = append(p.steps, Step{
+		Description: "CPU power in kW interpolated from the wattage curve",
+		Formula:     formula("spline(wattage, %g%) / 1000", usage),
+		Value:       usageCPUkw,
+	})
+
 	// Operational Emissions are calculated by multiplying the usageCPUkw, vCPUHours, PUE,
 	// and region gridCO2e. The PUE is collected from the providers. The CO2e grid data
 	// is the grid carbon intensity coefficient for the region at the specified time.
-	logger := log.FromContext(ctx)
-	logger.Debug(fmt.Sprintf("CPU calculation: %+v, %+v, %+v, %+v", usageCPUkw, vCPUHours, p.pue, p.gridCO2e))
-	return usageCPUkw * vCPUHours * p.pue * p.gridCO2e, nil
+	// the arguments are only formatted when they're logged
+	if logger := log.FromContext(ctx); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf("CPU calculation: %+v, %+v, %+v, %+v", usageCPUkw, vCPUHours, p.pue, p.gridCO2e))
+	}
+	emissions := usageCPUkw * vCPUHours * p.pue * p.gridCO2e
+	p.steps = append(p.steps, Step{
+		Description: "operational emissions in gCO2eq",
+		Formula:     formula("%g kW * %g vCPUh * %g PUE * %g gCO2eq/kWh", usageCPUkw, vCPUHours, p.pue, p.gridCO2e),
+		Value:       emissions,
+	})
+
+	return emissions, nil
+}
+
+// measuredCPU calculates the CO2e operational emissions of a Cloud VM
+// instance from the power measured on it, which already covers every vCPU
+func measuredCPU(ctx context.Context, interval time.Duration, p *parameters) (float64, error) {
+	hours := interval.Minutes() / float64(60)
+	p.steps = append(p.steps, Step{
+		Description: "hours over the interval",
+		Formula:     formula("%g min / 60", interval.Minutes()),
+		Value:       hours,
+	})
+
+	usageCPUkw := p.metric.Power / 1000
+	p.steps = append(p.steps, Step{
+		Description: "CPU power in kW measured on the instance",
+		Formula:     formula("%g W / 1000", p.metric.Power),
+		Value:       usageCPUkw,
+	})
+
+	if logger := log.FromContext(ctx); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf("measured CPU calculation: %+v, %+v, %+v, %+v", usageCPUkw, hours, p.pue, p.gridCO2e))
+	}
+	emissions := usageCPUkw * hours * p.pue * p.gridCO2e
+	p.steps = append(p.steps, Step{
+		Description: "operational emissions in gCO2eq",
+		Formula:     formula("%g kW * %g h * %g PUE * %g gCO2eq/kWh", usageCPUkw, hours, p.pue, p.gridCO2e),
+		Value:       emissions,
+	})
+
+	return emissions, nil
 }
 
 // cubicSplineInterpolation is a piecewise cubic polynomials that takes the
 // four measured wattage data points at 0%, 10%, 50%, and 100% utilization
 // and interpolates a value for the usage (%) value and returns the energy
-// in kilowatts.
+// in kilowatts. The splines are cached by wattage curve.
 func cubicSplineInterpolation(wattage []data.Wattage, value float64) (float64, error) {
 	if len(wattage) == 0 {
 		return 0, errors.New("error: cannot calculate CPU energy, no wattage found")
 	}
 
-	// split the wattage slice into a slice of
-	// float percentages and a slice of wattages
-	var x, y = []float64{}, []float64{}
-	for _, w := range wattage {
-		x = append(x, float64(w.Percentage))
-		y = append(y, w.Wattage)
-	}
-
-	s := gospline.NewCubicSpline(x, y)
-	// s.At returns the cubic spline value in Wattage
+	// At returns the cubic spline value in Wattage
 	// divide by 1000 to get kilowatts.
-	return s.At(value) / 1000, nil
+	return curveOf(wattage).spline.At(value) / 1000, nil
 }
 
 // EmbodiedEmissions are the released emissions of production and destruction of the
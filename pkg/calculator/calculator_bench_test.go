@@ -0,0 +1,50 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// BenchmarkCalculate calculates the emissions of a collection cycle of a
+// fleet of instances
+func BenchmarkCalculate(b *testing.B) {
+	corpusFactors(b)
+
+	fleet := make([]v1.Instance, 1000)
+	for i := range fleet {
+		fleet[i] = v1.Instance{
+			Name:     fmt.Sprintf("i-%d", i),
+			Provider: v1.AWS,
+			Region:   "eu-west-1",
+			Kind:     "m5.xlarge",
+			Metrics: v1.Metrics{
+				v1.CPU.String(): {
+					Name:       v1.CPU.String(),
+					Usage:      float64(i % 100),
+					UnitAmount: 4,
+				},
+			},
+		}
+	}
+
+	// like the handler, which logs at the info level
+	ctx := log.WithContext(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		for i := range fleet {
+			if _, err := Calculate(ctx, &fleet[i], 5*time.Minute); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
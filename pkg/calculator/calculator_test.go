@@ -11,7 +11,7 @@ import (
 )
 
 func params() *parameters {
-	m := &v1.Metric{
+	m := v1.Metric{
 		Name:         "basic",
 		ResourceType: v1.CPU,
 		Usage:        27,
@@ -166,6 +166,19 @@ func TestCalculateCPU(t *testing.T) {
 				expRes:   0.013218390243902438,
 			}
 		}(),
+
+		func() *testcase {
+			// the measured power of the whole instance is used
+			// instead of the wattage curve of its vCPUs
+			p := params()
+			p.metric.Power = 20
+			return &testcase{
+				name:     "measured power",
+				interval: 5 * time.Minute,
+				params:   p,
+				expRes:   0.013999999999999997,
+			}
+		}(),
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			res, err := cpu(context.TODO(), test.interval, test.params)
@@ -257,3 +270,14 @@ func TestCubicSplineInterpolation(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateCPUSteps(t *testing.T) {
+	p := params()
+	res, err := cpu(context.TODO(), 5*time.Minute, p)
+	assert.Nil(t, err)
+
+	// the steps explain the calculation, the last one is the result
+	assert.Len(t, p.steps, 4)
+	assert.Equal(t, 2.0, p.steps[0].Value)
+	assert.Equal(t, res, p.steps[len(p.steps)-1].Value)
+}
@@ -0,0 +1,96 @@
+package calculator
+
+import (
+	"sync"
+
+	"github.com/cnkei/gospline"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+)
+
+// maxCurvePoints is the most points of the wattage curves which are cached,
+// the curves of the datasets have 2 to 4
+const maxCurvePoints = 8
+
+// curveKey identifies a wattage curve
+type curveKey struct {
+	n      int
+	points [maxCurvePoints]data.Wattage
+}
+
+// curve is a wattage curve with its interpolation. The curves are shared by
+// the calculations using the same wattage and must not be modified
+type curve struct {
+	wattage []data.Wattage
+	points  []WattagePoint
+	spline  gospline.Spline
+
+	// The highest wattage of the curve
+	max float64
+}
+
+// curves caches the curves by their wattage, there are only a few of them
+// for thousands of instances
+var curves = struct {
+	byKey map[curveKey]*curve
+	mu    sync.RWMutex
+}{
+	byKey: make(map[curveKey]*curve),
+}
+
+// curveOf returns the curve of the wattage, which must not be empty. It's
+// only built the first time the wattage is used, the wattage is copied
+func curveOf(wattage []data.Wattage) *curve {
+	if len(wattage) > maxCurvePoints {
+		return newCurve(wattage)
+	}
+
+	key := curveKey{n: len(wattage)}
+	copy(key.points[:], wattage)
+
+	curves.mu.RLock()
+	c, ok := curves.byKey[key]
+	curves.mu.RUnlock()
+
+	if ok {
+		return c
+	}
+
+	curves.mu.Lock()
+	defer curves.mu.Unlock()
+
+	if c, ok := curves.byKey[key]; ok {
+		return c
+	}
+	c = newCurve(wattage)
+	curves.byKey[key] = c
+
+	return c
+}
+
+// newCurve builds the curve of the wattage
+func newCurve(wattage []data.Wattage) *curve {
+	c := &curve{
+		wattage: append([]data.Wattage(nil), wattage...),
+		points:  wattagePoints(wattage),
+	}
+
+	// split the wattage slice into a slice of
+	// float percentages and a slice of wattages
+	x := make([]float64, 0, len(wattage))
+	y := make([]float64, 0, len(wattage))
+	for _, w := range wattage {
+		x = append(x, float64(w.Percentage))
+		y = append(y, w.Wattage)
+		c.max = max(c.max, w.Wattage)
+	}
+	c.spline = gospline.NewCubicSpline(x, y)
+
+	// the segments of the spline are computed on their first use, they're
+	// all computed now so that the spline is only read afterwards and is
+	// safe for concurrent use
+	for _, v := range x[:len(x)-1] {
+		c.spline.At(v)
+	}
+
+	return c
+}
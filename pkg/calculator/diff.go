@@ -0,0 +1,228 @@
+package calculator
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"sort"
+
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// The providers whose emission factors are compared
+var diffProviders = []v1.Provider{v1.AWS, v1.Azure, v1.GCP}
+
+// The statuses of a factor change
+const (
+	FactorAdded   = "added"
+	FactorRemoved = "removed"
+	FactorChanged = "changed"
+)
+
+// The factors that are compared
+const (
+	// The grid intensity of a region in gCO2eq/kWh
+	FactorGrid = "grid"
+	// The embodied emissions of an instance type in kgCO2eq
+	FactorEmbodied = "embodied"
+	// The vCPUs of an instance type
+	FactorVCPU = "vcpu"
+	// The provider defaults
+	FactorPUE      = "pue"
+	FactorMinWatts = "minWatts"
+	FactorMaxWatts = "maxWatts"
+)
+
+// FactorChange is an emission factor that differs between two versions of
+// the emission factors
+type FactorChange struct {
+	Provider v1.Provider `json:"provider"`
+	Factor   string      `json:"factor"`
+
+	// The region or the instance type, empty for the provider defaults
+	Key string `json:"key,omitempty"`
+
+	Status string  `json:"status"`
+	Old    float64 `json:"old"`
+	New    float64 `json:"new"`
+}
+
+// DiffFactors returns the emission factors that differ between the datasets
+// in the directories, sorted by provider, factor and key
+func DiffFactors(oldPath, newPath string) ([]FactorChange, error) {
+	var changes []FactorChange
+
+	for _, provider := range diffProviders {
+		oldFactors, err := diffFactors(provider, oldPath)
+		if err != nil {
+			return nil, err
+		}
+
+		newFactors, err := diffFactors(provider, newPath)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, diffValues(provider, FactorPUE, defaultValue(oldFactors.AveragePUE), defaultValue(newFactors.AveragePUE))...)
+		changes = append(changes, diffValues(provider, FactorMinWatts, defaultValue(oldFactors.MinWatts), defaultValue(newFactors.MinWatts))...)
+		changes = append(changes, diffValues(provider, FactorMaxWatts, defaultValue(oldFactors.MaxWatts), defaultValue(newFactors.MaxWatts))...)
+		changes = append(changes, diffValues(provider, FactorGrid, gridValues(oldFactors), gridValues(newFactors))...)
+		changes = append(changes, diffValues(provider, FactorEmbodied, embodiedValues(oldFactors, false), embodiedValues(newFactors, false))...)
+		changes = append(changes, diffValues(provider, FactorVCPU, embodiedValues(oldFactors, true), embodiedValues(newFactors, true))...)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Factor != b.Factor {
+			return a.Factor < b.Factor
+		}
+		return a.Key < b.Key
+	})
+
+	return changes, nil
+}
+
+// diffFactors returns the emission factors of the provider, they are empty
+// when the dataset has none
+func diffFactors(provider v1.Provider, dataPath string) (*factors.EmissionFactors, error) {
+	ef, err := factors.GetProviderEmissionFactors(provider, dataPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &factors.EmissionFactors{
+			Provider:         provider,
+			ProviderDefaults: &factors.ProviderDefaults{},
+		}, nil
+	}
+
+	return ef, err
+}
+
+// defaultValue returns the value of a provider default, a zero value is not
+// set
+func defaultValue(v float64) map[string]float64 {
+	if v == 0 {
+		return nil
+	}
+	return map[string]float64{"": v}
+}
+
+// gridValues returns the grid intensity of the regions in gCO2eq/kWh
+func gridValues(ef *factors.EmissionFactors) map[string]float64 {
+	values := make(map[string]float64, len(ef.Coefficient))
+	for region := range ef.Coefficient {
+		v, _ := factorsIntensity(ef, region)
+		// drop the noise of the conversion from tonnes
+		values[region] = math.Round(v*1e6) / 1e6
+	}
+	return values
+}
+
+// embodiedValues returns the embodied emissions, or the vCPUs, of the
+// instance types
+func embodiedValues(ef *factors.EmissionFactors, vCPU bool) map[string]float64 {
+	values := make(map[string]float64, len(ef.Embodied))
+	for kind, e := range ef.Embodied {
+		if vCPU {
+			values[kind] = e.VCPU
+		} else {
+			values[kind] = e.TotalEmbodiedKiloWattCO2e
+		}
+	}
+	return values
+}
+
+// diffValues compares the values of a factor
+func diffValues(provider v1.Provider, factor string, oldValues, newValues map[string]float64) []FactorChange {
+	var changes []FactorChange
+
+	for key, o := range oldValues {
+		n, ok := newValues[key]
+		switch {
+		case !ok:
+			changes = append(changes, FactorChange{Provider: provider, Factor: factor, Key: key, Status: FactorRemoved, Old: o})
+		case o != n:
+			changes = append(changes, FactorChange{Provider: provider, Factor: factor, Key: key, Status: FactorChanged, Old: o, New: n})
+		}
+	}
+
+	for key, n := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			changes = append(changes, FactorChange{Provider: provider, Factor: factor, Key: key, Status: FactorAdded, New: n})
+		}
+	}
+
+	return changes
+}
+
+// FactorsImpact is the expected change of the emissions of the workloads
+// when the emission factors are upgraded
+type FactorsImpact struct {
+	// How many instances have a changed factor
+	Instances int `json:"instances"`
+
+	// How many instances lost their region or type, they can't be
+	// calculated with the new factors
+	Unsupported int `json:"unsupported"`
+
+	// The emissions in gCO2eq, as calculated and with the new factors
+	Old float64 `json:"old"`
+	New float64 `json:"new"`
+}
+
+// EstimateImpact estimates the emissions of the samples with the changed
+// factors: the operational emissions scale with the grid intensity and the
+// PUE, the embodied emissions with the embodied emissions of the type. The
+// wattage is not accounted, as it depends on the usage
+func EstimateImpact(changes []FactorChange, samples []store.Sample) FactorsImpact {
+	lookup := make(map[[3]string]*FactorChange, len(changes))
+	for i := range changes {
+		c := &changes[i]
+		lookup[[3]string{c.Provider.String(), c.Factor, c.Key}] = c
+	}
+
+	// ratio returns the ratio of the new value to the old one, and whether
+	// the value was removed, in which case the emissions are left as is
+	ratio := func(provider v1.Provider, factor, key string) (float64, bool) {
+		c, ok := lookup[[3]string{provider.String(), factor, key}]
+		if !ok || c.Status == FactorAdded || c.Old == 0 {
+			return 1, false
+		}
+		if c.Status == FactorRemoved {
+			return 1, true
+		}
+		return c.New / c.Old, false
+	}
+
+	var impact FactorsImpact
+	affected := make(map[string]bool)
+	unsupported := make(map[string]bool)
+
+	for i := range samples {
+		s := &samples[i]
+		key := s.Provider.String() + "/" + s.Name
+
+		grid, gridRemoved := ratio(s.Provider, FactorGrid, s.Region)
+		pue, _ := ratio(s.Provider, FactorPUE, "")
+		embodied, kindRemoved := ratio(s.Provider, FactorEmbodied, s.Kind)
+
+		if gridRemoved || kindRemoved {
+			unsupported[key] = true
+		}
+
+		impact.Old += s.Operational + s.Embodied
+		impact.New += s.Operational*grid*pue + s.Embodied*embodied
+
+		if grid != 1 || pue != 1 || embodied != 1 || unsupported[key] {
+			affected[key] = true
+		}
+	}
+
+	impact.Instances = len(affected)
+	impact.Unsupported = len(unsupported)
+
+	return impact
+}
@@ -0,0 +1,71 @@
+package calculator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFactors(t *testing.T) {
+	assert := require.New(t)
+
+	write := func(files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			assert.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		}
+		return dir
+	}
+
+	oldPath := write(map[string]string{
+		"aws-default.yaml":  "name: aws\naveragePUE: 1.5\n",
+		"aws-grid.yaml":     "- region: north\n  co2e: 0.0001\n- region: south\n  co2e: 0.0004\n- region: east\n  co2e: 0.0002\n",
+		"aws-use.yaml":      "- architecture: A\n  minwatts: 10\n  maxwatts: 20\n",
+		"aws-embodied.yaml": "- type: t-2\n  total: 1000\n  vCPU: 2\n  architecture: A\n",
+	})
+	newPath := write(map[string]string{
+		"aws-default.yaml":  "name: aws\naveragePUE: 1.5\n",
+		"aws-grid.yaml":     "- region: north\n  co2e: 0.00005\n- region: south\n  co2e: 0.0004\n- region: west\n  co2e: 0.0003\n",
+		"aws-use.yaml":      "- architecture: A\n  minwatts: 10\n  maxwatts: 20\n",
+		"aws-embodied.yaml": "- type: t-2\n  total: 1200\n  vCPU: 2\n  architecture: A\n",
+		// a new provider
+		"gcp-default.yaml":  "name: gcp\naveragePUE: 1.1\n",
+		"gcp-grid.yaml":     "[]\n",
+		"gcp-use.yaml":      "[]\n",
+		"gcp-embodied.yaml": "[]\n",
+	})
+
+	changes, err := DiffFactors(oldPath, newPath)
+	assert.NoError(err)
+	assert.Len(changes, 5)
+	assert.Equal(FactorChange{Provider: v1.AWS, Factor: FactorEmbodied, Key: "t-2", Status: FactorChanged, Old: 1000, New: 1200}, changes[0])
+	assert.Equal(FactorChange{Provider: v1.AWS, Factor: FactorGrid, Key: "east", Status: FactorRemoved, Old: 200}, changes[1])
+	assert.Equal(FactorGrid, changes[2].Factor)
+	assert.Equal("north", changes[2].Key)
+	assert.InDelta(50, changes[2].New, 0.0001)
+	assert.Equal(FactorChange{Provider: v1.AWS, Factor: FactorGrid, Key: "west", Status: FactorAdded, New: 300}, changes[3])
+	assert.Equal(FactorChange{Provider: v1.GCP, Factor: FactorPUE, Status: FactorAdded, New: 1.1}, changes[4])
+
+	// nothing changed
+	changes, err = DiffFactors(oldPath, oldPath)
+	assert.NoError(err)
+	assert.Empty(changes)
+
+	changes, err = DiffFactors(oldPath, newPath)
+	assert.NoError(err)
+	impact := EstimateImpact(changes, []store.Sample{
+		{Provider: v1.AWS, Name: "a", Region: "north", Kind: "t-2", Operational: 10, Embodied: 5},
+		{Provider: v1.AWS, Name: "a", Region: "north", Kind: "t-2", Operational: 10, Embodied: 5},
+		{Provider: v1.AWS, Name: "b", Region: "south", Kind: "t-3", Operational: 10},
+		{Provider: v1.AWS, Name: "c", Region: "east", Kind: "t-3", Operational: 10},
+	})
+	assert.Equal(2, impact.Instances)
+	assert.Equal(1, impact.Unsupported)
+	assert.InDelta(50, impact.Old, 0.0001)
+	// a's operational is halved and its embodied increased by 20%
+	assert.InDelta(5+6+5+6+10+10, impact.New, 0.0001)
+}
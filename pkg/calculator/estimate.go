@@ -0,0 +1,121 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// ErrUnknownKind is returned when the emission factors have no data for the
+// instance type
+var ErrUnknownKind error = v1.ErrKindNotFound
+
+// EstimateRequest describes the usage of an instance type to estimate the
+// emissions of, e.g. a CI runner over the duration of a job
+type EstimateRequest struct {
+	Provider v1.Provider
+	Kind     string
+	Region   string
+
+	// The CPU utilization in percent
+	Utilization float64
+
+	// How long the instance is used
+	Duration time.Duration
+}
+
+// Estimate is the emissions of an instance type over a duration, calculated
+// like the ones of the scraped instances
+type Estimate struct {
+	Provider    v1.Provider   `json:"provider"`
+	Kind        string        `json:"kind"`
+	Region      string        `json:"region"`
+	Utilization float64       `json:"utilization"`
+	Duration    time.Duration `json:"duration"`
+
+	// The factors used by the calculation, the grid intensity is the
+	// average of the fallback level when the region has none
+	GridCO2e     float64 `json:"gridCO2e"`
+	GridFallback string  `json:"gridFallback,omitempty"`
+	PUE          float64 `json:"pue"`
+	PUESource    string  `json:"pueSource"`
+	VCPU         float64 `json:"vCPU"`
+
+	// The emissions in gCO2eq
+	Operational float64 `json:"operational"`
+	Embodied    float64 `json:"embodied"`
+	Total       float64 `json:"total"`
+}
+
+// EstimateEmissions returns the emissions of the instance type with the
+// emission factors in use
+func EstimateEmissions(ctx context.Context, req *EstimateRequest) (*Estimate, error) {
+	return (*Locations)(nil).EstimateEmissions(ctx, req)
+}
+
+// EstimateEmissions returns the emissions of the instance type with the
+// emission factors in use and the custom regions
+func (l *Locations) EstimateEmissions(ctx context.Context, req *EstimateRequest) (*Estimate, error) {
+	if req.Duration <= 0 {
+		return nil, errors.New("the duration must be positive")
+	}
+	if req.Utilization < 0 || req.Utilization > 100 {
+		return nil, fmt.Errorf("invalid utilization %g, must be between 0 and 100", req.Utilization)
+	}
+
+	emFactors, err := emissionFactors.provider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	gridCO2e, fallback, err := l.fallbackIntensity(emFactors, req.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := kindParameters(emFactors, emissionFactors.instance, req.Kind, "")
+	if err != nil {
+		return nil, err
+	}
+	params.gridCO2e = gridCO2e
+	l.regionParameters(&params, req.Provider, req.Region)
+	l.acceleratorParameters(&params, emFactors, req.Provider, req.Region, req.Kind, nil)
+
+	// the v1 dataset doesn't set the vCPUs of the wattage, they are
+	// collected with the metrics otherwise
+	vCPU := params.vCPU
+	if vCPU == 0 {
+		vCPU = emFactors.Embodied[req.Kind].VCPU
+	}
+
+	params.metric = v1.Metric{
+		Name:       v1.CPU.String(),
+		Usage:      req.Utilization,
+		UnitAmount: vCPU,
+	}
+
+	operational, err := cpu(ctx, req.Duration, &params)
+	if err != nil {
+		return nil, err
+	}
+	embodied := embodiedEmissions(req.Duration, params.embodiedFactor)
+
+	return &Estimate{
+		Provider:     req.Provider,
+		Kind:         req.Kind,
+		Region:       req.Region,
+		Utilization:  req.Utilization,
+		Duration:     req.Duration,
+		GridCO2e:     gridCO2e,
+		GridFallback: fallback,
+		PUE:          params.pue,
+		PUESource:    params.pueSource,
+		VCPU:         vCPU,
+		Operational:  operational,
+		Embodied:     embodied,
+		Total:        operational + embodied,
+	}, nil
+}
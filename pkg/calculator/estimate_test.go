@@ -0,0 +1,61 @@
+package calculator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateEmissions(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"test-default.yaml":  "name: test\naveragePUE: 1.5\n",
+		"test-grid.yaml":     "- region: north\n  co2e: 0.0001\n",
+		"test-use.yaml":      "- architecture: A\n  minwatts: 10\n  maxwatts: 20\n",
+		"test-embodied.yaml": "- type: t-2\n  total: 315360\n  vCPU: 2\n  totalVCPU: 4\n  architecture: A\n",
+	} {
+		assert.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	dataPath := factors.DataPath
+	factors.DataPath = dir
+	defer func() { factors.DataPath = dataPath }()
+
+	ctx := context.Background()
+	req := &EstimateRequest{
+		Provider:    "test",
+		Kind:        "t-2",
+		Region:      "north",
+		Utilization: 50,
+		Duration:    2 * time.Hour,
+	}
+
+	// 0.015 kW * 4 vCPUh * 1.5 PUE * 100 gCO2eq/kWh and
+	// 315360 / (24 * 365 * 6) * 2/4 vCPU per hour
+	e, err := EstimateEmissions(ctx, req)
+	assert.NoError(err)
+	assert.Equal(2.0, e.VCPU)
+	assert.InDelta(100, e.GridCO2e, 0.0001)
+	assert.InDelta(9, e.Operational, 0.0001)
+	assert.InDelta(6, e.Embodied, 0.0001)
+	assert.InDelta(15, e.Total, 0.0001)
+
+	req.Kind = "t-4"
+	_, err = EstimateEmissions(ctx, req)
+	assert.ErrorIs(err, ErrUnknownKind)
+
+	req.Kind, req.Region = "t-2", "south"
+	_, err = EstimateEmissions(ctx, req)
+	assert.ErrorIs(err, ErrUnknownRegion)
+
+	req.Region, req.Utilization = "north", 120
+	_, err = EstimateEmissions(ctx, req)
+	assert.Error(err)
+}
@@ -0,0 +1,249 @@
+package calculator
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// update rewrites the golden files of the corpus with the results of the
+// calculations, the changes of the numbers are then reviewed in the diff:
+//
+//	go test ./pkg/calculator -run TestGolden -update
+var update = flag.Bool("update", false, "update the golden files of the corpus")
+
+// corpus is the directory of the cases of the golden tests, every case has
+// an input file and its expected results in <case>.golden.yaml
+const corpus = "testdata/corpus"
+
+// goldenCase is an instance as collected by the scrapers
+type goldenCase struct {
+	Description string        `yaml:"description"`
+	Interval    time.Duration `yaml:"interval"`
+	Instance    struct {
+		Provider string            `yaml:"provider"`
+		Name     string            `yaml:"name"`
+		Region   string            `yaml:"region"`
+		Zone     string            `yaml:"zone"`
+		Kind     string            `yaml:"kind"`
+		Platform string            `yaml:"cpuPlatform"`
+		Labels   map[string]string `yaml:"labels"`
+		Metrics  []struct {
+			Name       string  `yaml:"name"`
+			Usage      float64 `yaml:"usage"`
+			UnitAmount float64 `yaml:"unitAmount"`
+
+			// How long the metric was observed when it's shorter
+			// than the interval
+			Observed time.Duration `yaml:"observed"`
+
+			// The media and the I/O of the disks
+			Media      string  `yaml:"media"`
+			IOPS       float64 `yaml:"iops"`
+			Throughput float64 `yaml:"throughput"`
+		} `yaml:"metrics"`
+	} `yaml:"instance"`
+}
+
+// instance returns the instance of the case
+func (c *goldenCase) instance() *v1.Instance {
+	i := v1.NewInstance(c.Instance.Name, v1.Provider(c.Instance.Provider))
+	i.Region = c.Instance.Region
+	i.Zone = c.Instance.Zone
+	i.Kind = c.Instance.Kind
+	i.CPUPlatform = c.Instance.Platform
+	for k, v := range c.Instance.Labels {
+		i.Labels.Add(k, v)
+	}
+
+	for _, m := range c.Instance.Metrics {
+		metric := v1.Metric{
+			Name:       m.Name,
+			Usage:      m.Usage,
+			UnitAmount: m.UnitAmount,
+			IOPS:       m.IOPS,
+			Throughput: m.Throughput,
+			Labels:     v1.Labels{},
+		}
+		if m.Media != "" {
+			metric.Labels[v1.MediaLabel] = m.Media
+		}
+		if m.Observed > 0 {
+			metric.Observed = v1.NewWindow(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), m.Observed)
+		}
+		switch m.Name {
+		case v1.CPU.String():
+			metric.ResourceType = v1.CPU
+			metric.Unit = v1.VCPU
+		case v1.Memory.String():
+			metric.ResourceType = v1.Memory
+			metric.Unit = v1.GB
+		case v1.Storage.String():
+			metric.ResourceType = v1.Storage
+			metric.Unit = v1.GB
+		case v1.Network.String():
+			metric.ResourceType = v1.Network
+			metric.Unit = v1.GB
+		}
+		i.Metrics.Upsert(&metric)
+	}
+
+	return i
+}
+
+// golden are the expected results of a case
+type golden struct {
+	Error       string         `yaml:"error,omitempty"`
+	Operational float64        `yaml:"operational"`
+	Embodied    float64        `yaml:"embodied"`
+	Factors     goldenFactors  `yaml:"factors"`
+	Metrics     []goldenMetric `yaml:"metrics"`
+	Steps       []goldenStep   `yaml:"embodiedSteps"`
+}
+
+type goldenFactors struct {
+	GridCO2e       float64        `yaml:"gridCO2e"`
+	PUE            float64        `yaml:"pue"`
+	VCPU           float64        `yaml:"vCPU"`
+	Architecture   string         `yaml:"architecture"`
+	Wattage        []WattagePoint `yaml:"wattage"`
+	EmbodiedFactor float64        `yaml:"embodiedHourlyFactor"`
+	Accelerators   *Accelerators  `yaml:"accelerators,omitempty"`
+}
+
+type goldenMetric struct {
+	Name      string       `yaml:"name"`
+	Emissions float64      `yaml:"emissions"`
+	Error     string       `yaml:"error,omitempty"`
+	Steps     []goldenStep `yaml:"steps"`
+	Anomalies []Anomaly    `yaml:"anomalies,omitempty"`
+}
+
+type goldenStep struct {
+	Description string  `yaml:"description"`
+	Formula     string  `yaml:"formula"`
+	Value       float64 `yaml:"value"`
+}
+
+// round keeps 10 significant digits, the last digits of the results depend
+// on the platform
+func round(f float64) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'g', 10, 64), 64)
+	return r
+}
+
+func goldenSteps(steps []Step) []goldenStep {
+	var s []goldenStep
+	for _, step := range steps {
+		s = append(s, goldenStep{
+			Description: step.Description,
+			Formula:     step.Formula,
+			Value:       round(step.Value),
+		})
+	}
+	return s
+}
+
+// newGolden returns the results of the calculation
+func newGolden(b *Breakdown, err error) golden {
+	if err != nil {
+		return golden{Error: err.Error()}
+	}
+
+	g := golden{
+		Embodied: round(b.Embodied.Value),
+		Factors: goldenFactors{
+			GridCO2e:       b.GridCO2e,
+			PUE:            b.PUE,
+			VCPU:           b.VCPU,
+			Architecture:   b.Architecture,
+			Wattage:        b.Wattage,
+			EmbodiedFactor: round(b.EmbodiedFactor),
+			Accelerators:   b.Accelerators,
+		},
+		Steps: goldenSteps([]Step{b.Embodied}),
+	}
+
+	var operational float64
+	for _, m := range b.Metrics {
+		operational += m.Emissions
+		g.Metrics = append(g.Metrics, goldenMetric{
+			Name:      m.Name,
+			Emissions: round(m.Emissions),
+			Error:     m.Error,
+			Steps:     goldenSteps(m.Steps),
+			Anomalies: m.Anomalies,
+		})
+	}
+	g.Operational = round(operational)
+
+	return g
+}
+
+// corpusFactors uses the emission factors of the corpus and not the
+// dataset loaded by the other tests until the test ends
+func corpusFactors(t testing.TB) {
+	dataPath := factors.DataPath
+	factors.DataPath = filepath.Join(corpus, "factors")
+
+	instances := emissionFactors.swapInstances(nil)
+
+	t.Cleanup(func() {
+		factors.DataPath = dataPath
+		emissionFactors.swapInstances(instances)
+	})
+}
+
+// TestGolden calculates the emissions of the instances of the corpus and
+// compares them with the golden files, a change of the methodology shows
+// up as a diff of the numbers
+func TestGolden(t *testing.T) {
+	corpusFactors(t)
+
+	cases, err := filepath.Glob(filepath.Join(corpus, "*.yaml"))
+	require.NoError(t, err)
+
+	for _, path := range cases {
+		if strings.HasSuffix(path, ".golden.yaml") {
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			assert := require.New(t)
+
+			content, err := os.ReadFile(path)
+			assert.NoError(err)
+
+			var c goldenCase
+			assert.NoError(yaml.UnmarshalStrict(content, &c))
+			assert.NotEmpty(c.Description)
+			assert.Positive(c.Interval)
+
+			result, err := yaml.Marshal(newGolden(
+				Calculate(context.Background(), c.instance(), c.Interval),
+			))
+			assert.NoError(err)
+
+			goldenPath := filepath.Join(corpus, name+".golden.yaml")
+			if *update {
+				assert.NoError(os.WriteFile(goldenPath, result, 0o644))
+				return
+			}
+
+			expected, err := os.ReadFile(goldenPath)
+			assert.NoError(err, "run the test with -update to create the golden file")
+			assert.Equal(string(expected), string(result))
+		})
+	}
+}
@@ -4,52 +4,314 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
-
-	"gopkg.in/yaml.v2"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/re-cinq/aether/pkg/bus"
 	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/dedup"
+	"github.com/re-cinq/aether/pkg/grouping"
 	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/taxonomy"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
 	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
 	data "github.com/re-cinq/emissions-data/pkg/types/v2"
 )
 
-var awsInstances map[string]data.Instance
-
 // AWS, GCP and Azure have increased their server lifespan to 6 years (2024)
 // https://sustainability.aboutamazon.com/products-services/the-cloud?energyType=true
 // https://www.theregister.com/2024/01/31/alphabet_q4_2023/
 // https://www.theregister.com/2022/08/02/microsoft_server_life_extension/
 const serverLifespan = 6
 
+// loadFactors pulls the emission factors of the handlers
+var loadFactors = LoadFactors
+
 // CalculatorHandler is used to handle events when metrics have been collected
 type CalculatorHandler struct {
 	Bus    *bus.Bus
 	logger *slog.Logger
+
+	// The latest calculation of every instance
+	breakdowns breakdowns
+
+	// The emission factors in use, the error of their last pull and when
+	// it was attempted
+	dataset   Dataset
+	pullErr   error
+	pulledAt  time.Time
+	datasetMu sync.RWMutex
+
+	// Held while the factors are pulled again after a failed pull
+	retryMu sync.Mutex
+
+	// The on-premises sites and the custom regions of the config
+	locations   *Locations
+	locationsMu sync.RWMutex
+
+	// The interval the emissions are calculated over, the scrape interval
+	interval time.Duration
+
+	// Whether the factors are pulled when the handler is created
+	pull bool
+
+	// The instance kinds and regions missing from the emission factors, and
+	// the webhook they are posted to when first seen
+	misses        misses
+	missesWebhook string
+
+	// Set the application and team labels of the instances
+	grouping grouping.Rules
+
+	// Counts the instances reported by several collectors once
+	dedup *dedup.Resolver
+
+	// The emissions of the current collection of every account
+	summaries summaries
+}
+
+// HandlerOption configures the CalculatorHandler
+type HandlerOption func(*CalculatorHandler)
+
+// WithInterval calculates the emissions over the interval, the scrape
+// interval of the collected metrics
+func WithInterval(interval time.Duration) HandlerOption {
+	return func(c *CalculatorHandler) {
+		c.interval = interval
+	}
+}
+
+// WithDataset uses the emission factors already in factors.DataPath instead
+// of pulling the latest ones, e.g. the factors of the tests
+func WithDataset(dataset Dataset) HandlerOption {
+	return func(c *CalculatorHandler) {
+		c.dataset = dataset
+		c.pull = false
+	}
+}
+
+// WithMissesWebhook posts the instance kinds and regions missing from the
+// emission factors to the webhook when they are first seen
+func WithMissesWebhook(webhook string) HandlerOption {
+	return func(c *CalculatorHandler) {
+		c.missesWebhook = webhook
+	}
+}
+
+// WithGrouping sets the application and team labels of the instances with
+// the rules before their emissions are calculated
+func WithGrouping(rules grouping.Rules) HandlerOption {
+	return func(c *CalculatorHandler) {
+		c.grouping = rules
+	}
+}
+
+// WithDeduplication only calculates the emissions of the instances counted
+// by the resolver, the ones reported by a collector of higher precedence
+// are skipped
+func WithDeduplication(r *dedup.Resolver) HandlerOption {
+	return func(c *CalculatorHandler) {
+		c.dedup = r
+	}
+}
+
+// WithLocations calculates the emissions of the instances of the
+// on-premises sites and the custom regions with their own factors
+func WithLocations(l *Locations) HandlerOption {
+	return func(c *CalculatorHandler) {
+		c.locations = l
+	}
+}
+
+// WithStaleness evicts the breakdowns of the instances not calculated within
+// the staleness, e.g. the terminated ones
+func WithStaleness(staleness time.Duration) HandlerOption {
+	return func(c *CalculatorHandler) {
+		c.breakdowns.staleness = staleness
+	}
+}
+
+// Dataset describes the emission factors used by the calculations
+type Dataset struct {
+	// The source of the emission factors
+	Source string `json:"source"`
+
+	// The commit of the emissions data repo
+	Version string `json:"version"`
+
+	// When the factors were last refreshed
+	RefreshedAt time.Time `json:"refreshedAt"`
+
+	// The factors overridden by the config
+	Overrides []Override `json:"overrides,omitempty"`
+
+	// Why the last pull of the factors failed, the ones loaded before, if
+	// any, are still used
+	Error string `json:"error,omitempty"`
 }
 
 // NewHandler returns a new configuered instance of CalculatorHandler
-// as well as setups the factor datasets
-func NewHandler(ctx context.Context, b *bus.Bus) *CalculatorHandler {
+// as well as setups the factor datasets. When they can't be pulled the
+// handler is degraded, the pull is retried by the next collected metrics or
+// by RefreshFactors
+func NewHandler(ctx context.Context, b *bus.Bus, opts ...HandlerOption) *CalculatorHandler {
+	logger := log.FromContext(ctx)
+
+	c := &CalculatorHandler{
+		Bus:    b,
+		logger: logger,
+		pull:   true,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if !c.pull {
+		return c
+	}
+
+	if err := c.RefreshFactors(ctx); err != nil {
+		logger.Error("error with emissions repo, pulling the emission factors again at the next collection", "error", err)
+	}
+
+	return c
+}
+
+// RefreshFactors pulls the latest emission factors, they are used by the
+// calculations of the next collected metrics. When they can't be pulled the
+// factors loaded before are still used and v1.ErrDatasetStale is returned
+func (c *CalculatorHandler) RefreshFactors(ctx context.Context) error {
+	dataset, err := loadFactors(log.WithContext(ctx, c.logger))
+
+	c.datasetMu.Lock()
+	defer c.datasetMu.Unlock()
+
+	c.pullErr = err
+	c.pulledAt = time.Now()
+	if err != nil {
+		if !c.dataset.RefreshedAt.IsZero() {
+			return fmt.Errorf("%w: %w", v1.ErrDatasetStale, err)
+		}
+		return err
+	}
+	c.dataset = dataset
+
+	return nil
+}
+
+// retryFactors pulls the emission factors again when they were never
+// pulled, at most once every interval. The metrics collected meanwhile are
+// calculated with the factors already on disk, if any
+func (c *CalculatorHandler) retryFactors(ctx context.Context) {
+	c.datasetMu.RLock()
+	retry := c.pull && c.dataset.RefreshedAt.IsZero() && time.Since(c.pulledAt) >= c.interval
+	c.datasetMu.RUnlock()
+
+	// the other collected metrics don't wait for the pull
+	if !retry || !c.retryMu.TryLock() {
+		return
+	}
+	defer c.retryMu.Unlock()
+
+	if err := c.RefreshFactors(ctx); err != nil {
+		c.logger.Error("error with emissions repo, pulling the emission factors again at the next collection", "error", err)
+		return
+	}
+	c.logger.Info("pulled the emission factors")
+}
+
+// LoadFactors pulls the latest emission factors used by the calculations
+// and returns their dataset
+func LoadFactors(ctx context.Context) (Dataset, error) {
 	logger := log.FromContext(ctx)
 
-	err := factors.CloneAndUpdateFactorsData()
+	if err := factors.CloneAndUpdateFactorsData(); err != nil {
+		return Dataset{}, err
+	}
+	emissionFactors.reset()
+
+	version, err := factors.FactorsDataVersion()
 	if err != nil {
-		logger.Error("error with emissions repo", "error", err)
-		return nil
+		logger.Warn("failed reading the emission factors version", "error", err)
+	}
+
+	dataset := Dataset{
+		Source:      factors.RepoURL(),
+		Version:     version,
+		RefreshedAt: time.Now().UTC(),
 	}
 
-	awsInstances, err = getProviderEC2EmissionFactors(v1.AWS)
+	instances, err := loadInstances(ctx, v1.AWS)
 	if err != nil {
 		logger.Error("unable to get v2 Emission Factors, falling back to v1", "error", err)
+		return dataset, nil
 	}
 
-	return &CalculatorHandler{
-		Bus:    b,
-		logger: logger,
+	emissionFactors.swapInstances(instances)
+
+	return dataset, nil
+}
+
+// Dataset returns the emission factors in use
+func (c *CalculatorHandler) Dataset() Dataset {
+	c.datasetMu.RLock()
+	defer c.datasetMu.RUnlock()
+
+	d := c.dataset
+	d.Overrides = c.Locations().Overrides()
+	if c.pullErr != nil {
+		d.Error = c.pullErr.Error()
 	}
+	return d
+}
+
+// Locations returns the on-premises sites and the custom regions the
+// emissions are calculated with
+func (c *CalculatorHandler) Locations() *Locations {
+	c.locationsMu.RLock()
+	defer c.locationsMu.RUnlock()
+
+	return c.locations
+}
+
+// Configure updates the on-premises sites and the custom regions of every
+// provider, e.g. when the config file is reloaded
+func (c *CalculatorHandler) Configure(cfg *config.ApplicationConfig) {
+	l := NewLocations(cfg)
+
+	c.locationsMu.Lock()
+	c.locations = l
+	c.locationsMu.Unlock()
+}
+
+// GridIntensity returns the grid carbon intensity of the region of the
+// provider in gCO2eq/kWh, the one of the custom region or of the emission
+// factors in use
+func (c *CalculatorHandler) GridIntensity(provider v1.Provider, region string) (float64, error) {
+	return c.Locations().GridIntensity(provider, region)
+}
+
+// EstimateEmissions returns the emissions of the instance type with the
+// emission factors in use and the custom regions
+func (c *CalculatorHandler) EstimateEmissions(ctx context.Context, req *EstimateRequest) (*Estimate, error) {
+	return c.Locations().EstimateEmissions(ctx, req)
+}
+
+// Breakdown returns the most recent calculation of the instance, the
+// provider and the account are only needed when several instances have the
+// name
+func (c *CalculatorHandler) Breakdown(provider v1.Provider, account, name string) (Breakdown, error) {
+	return c.breakdowns.get(provider, account, name)
+}
+
+// Misses returns the instance kinds and regions missing from the emission
+// factors, the most frequent first
+func (c *CalculatorHandler) Misses() []Miss {
+	return c.misses.list()
 }
 
 // Stop is used to fulfill the EventHandler interface and all clean up
@@ -57,120 +319,397 @@ func NewHandler(ctx context.Context, b *bus.Bus) *CalculatorHandler {
 func (c *CalculatorHandler) Stop(ctx context.Context) {}
 
 // Handle is used to fulfill the EventHandler interface and recives an event
-// when handler is subscribed to it. Currently only handles v1.MetricsCollectedEvent
+// when handler is subscribed to it. Currently handles v1.MetricsCollectedEvent
+// and v1.CollectionCompletedEvent
 func (c *CalculatorHandler) Handle(ctx context.Context, e *bus.Event) {
 	switch e.Type {
 	case v1.MetricsCollectedEvent:
-		c.handleEvent(e)
+		c.handleEvent(ctx, e)
+	case v1.CollectionCompletedEvent:
+		c.handleCollection(ctx, e)
 	default:
 		return
 	}
 }
 
-func getProviderEC2EmissionFactors(provider v1.Provider) (map[string]data.Instance, error) {
-	url := "https://raw.githubusercontent.com/re-cinq/emissions-data/main/data/v2/%s-instances.yaml"
-	yamlURL := fmt.Sprintf(url, provider)
-	resp, err := http.Get(yamlURL)
-	if err != nil {
-		return nil, err
+// handleEvent is the business logic for handeling a v1.MetricsCollectedEvent
+// and runs the emissions calculations on the metrics that where received.
+// The calculated instance is published with the deadline of the context
+func (c *CalculatorHandler) handleEvent(ctx context.Context, e *bus.Event) {
+	instance, ok := e.Data.(v1.Instance)
+	if !ok {
+		c.logger.Error("EmissionCalculator got an unknown event", "event", e)
+		return
 	}
-	defer resp.Body.Close()
+	c.retryFactors(ctx)
 
-	decoder := yaml.NewDecoder(resp.Body)
-	err = decoder.Decode(&awsInstances)
+	if !c.dedup.Counted(&instance) {
+		c.logger.Debug("instance counted by another collector", "instance", instance.Name, "provider", instance.Provider)
+		c.summarize(ctx, &instance, nil, nil)
+		return
+	}
+	c.grouping.Apply(&instance)
+	taxonomy.Apply(&instance)
+
+	breakdown, err := c.Locations().Calculate(log.WithContext(ctx, c.logger), &instance, c.interval)
 	if err != nil {
-		return nil, err
+		c.handleMiss(&instance, err)
+		c.summarize(ctx, &instance, nil, err)
+		return
 	}
+	// the regions calculated with a fallback are still missing
+	if breakdown.GridFallback != "" {
+		c.reportMiss(Miss{
+			Provider: instance.Provider,
+			Type:     MissRegion,
+			Value:    instance.Region,
+			Instance: instance.Name,
+		}, v1.CodeRegionNotFound)
+	}
+	c.breakdowns.set(breakdown)
+	c.summarize(ctx, &instance, breakdown, nil)
 
-	return awsInstances, nil
+	// We publish the interface on the bus once its been calculated
+	if err := c.Bus.PublishContext(ctx, &bus.Event{
+		Type: v1.EmissionsCalculatedEvent,
+		Data: instance,
+	}); err != nil {
+		c.logger.Error("failed publishing instance after calculation", "instance", instance.Name, "error", err)
+	}
 }
 
-// handleEvent is the business logic for handeling a v1.MetricsCollectedEvent
-// and runs the emissions calculations on the metrics that where received
-func (c *CalculatorHandler) handleEvent(e *bus.Event) {
-	interval := config.AppConfig().ProvidersConfig.Interval
-
-	instance, ok := e.Data.(v1.Instance)
+// handleCollection publishes the summaries of the accounts whose collection
+// completed, once the emissions of all their instances are calculated
+func (c *CalculatorHandler) handleCollection(ctx context.Context, e *bus.Event) {
+	collection, ok := e.Data.(v1.Collection)
 	if !ok {
 		c.logger.Error("EmissionCalculator got an unknown event", "event", e)
 		return
 	}
 
+	for _, summary := range c.summaries.complete(&collection) {
+		c.publishSummary(ctx, &summary)
+	}
+}
+
+// summarize adds the instance to the summary of its account, and publishes
+// the summary when it was the last instance of the collection
+func (c *CalculatorHandler) summarize(ctx context.Context, instance *v1.Instance, breakdown *Breakdown, err error) {
+	if summary, ok := c.summaries.add(instance, breakdown, err); ok {
+		c.publishSummary(ctx, &summary)
+	}
+}
+
+// publishSummary publishes the summary of a collection of an account
+func (c *CalculatorHandler) publishSummary(ctx context.Context, summary *v1.AccountSummary) {
+	if err := c.Bus.PublishContext(ctx, &bus.Event{
+		Type: v1.AccountSummaryEvent,
+		Data: *summary,
+	}); err != nil {
+		c.logger.Error("failed publishing the account summary", "provider", summary.Provider, "account", summary.Account, "error", err)
+	}
+}
+
+// handleMiss counts the lookups missing from the emission factors, they are
+// only logged and posted to the webhook the first time
+func (c *CalculatorHandler) handleMiss(instance *v1.Instance, err error) {
+	code := v1.Code(err)
+	calculationErrors.WithLabelValues(instance.Provider.String(), string(code)).Inc()
+
+	miss, ok := missOf(instance, err)
+	if !ok {
+		c.logger.Error("failed calculating the emissions", "instance", instance.Name, "error", err, "code", code)
+		return
+	}
+	c.reportMiss(miss, code)
+}
+
+// reportMiss counts a lookup missing from the emission factors, it's only
+// logged and posted to the webhook the first time
+func (c *CalculatorHandler) reportMiss(miss Miss, code v1.ErrorCode) {
+	if !c.misses.add(miss, time.Now().UTC()) {
+		return
+	}
+	c.logger.Warn("missing from the emission factors", "provider", miss.Provider, miss.Type, miss.Value, "instance", miss.Instance, "code", code)
+
+	if c.missesWebhook == "" {
+		return
+	}
+
+	go func() {
+		if err := notifyMiss(context.Background(), c.missesWebhook, miss); err != nil {
+			c.logger.Error("failed posting the miss to the webhook", "error", err)
+		}
+	}()
+}
+
+// Calculate sets the operational emissions of the metrics and the embodied
+// emissions of the instance over the interval, and returns how they were
+// calculated. The metrics which can't be calculated are left out
+func Calculate(ctx context.Context, instance *v1.Instance, interval time.Duration) (*Breakdown, error) {
+	return (*Locations)(nil).Calculate(ctx, instance, interval)
+}
+
+// Calculate is the package Calculate with the on-premises sites and the
+// custom regions
+func (l *Locations) Calculate(ctx context.Context, instance *v1.Instance, interval time.Duration) (*Breakdown, error) {
 	// Gets PUE, grid data, and machine specs
-	emFactors, err := factors.GetProviderEmissionFactors(
-		instance.Provider,
-		factors.DataPath,
-	)
+	emFactors, err := emissionFactors.provider(instance.Provider)
 	if err != nil {
-		c.logger.Error("error getting emission factors", "error", err)
-		return
+		return nil, err
+	}
+
+	breakdown, err := l.calculate(ctx, emFactors, emissionFactors.instance, instance, interval)
+	if err != nil {
+		return nil, err
+	}
+	// the sites aren't the grid of the region
+	if _, ok := l.siteIntensity(instance); !ok {
+		setGridIntensity(instance.Provider, instance.Region, breakdown.GridCO2e)
 	}
 
-	gridCO2eTons, ok := emFactors.Coefficient[instance.Region]
+	return breakdown, nil
+}
+
+// CalculateWith is Calculate with the emission factors of the provider of
+// the instance, e.g. read from another directory than the ones used by the
+// exporter. The wattage of the v2 dataset is not used
+func CalculateWith(ctx context.Context, emFactors *factors.EmissionFactors, instance *v1.Instance, interval time.Duration) (*Breakdown, error) {
+	return (*Locations)(nil).CalculateWith(ctx, emFactors, instance, interval)
+}
+
+// CalculateWith is the package CalculateWith with the on-premises sites and
+// the custom regions
+func (l *Locations) CalculateWith(ctx context.Context, emFactors *factors.EmissionFactors, instance *v1.Instance, interval time.Duration) (*Breakdown, error) {
+	return l.calculate(ctx, emFactors, nil, instance, interval)
+}
+
+// calculate calculates the emissions of the instance with the emission
+// factors and the instance types of the v2 dataset, if any
+func (l *Locations) calculate(
+	ctx context.Context,
+	emFactors *factors.EmissionFactors,
+	instances func(kind string) (data.Instance, bool),
+	instance *v1.Instance,
+	interval time.Duration,
+) (*Breakdown, error) {
+	logger := log.FromContext(ctx)
+
+	gridCO2e, ok := l.siteIntensity(instance)
+	var fallback string
 	if !ok {
-		c.logger.Error("region does not exist in factors for provider", "region", instance.Region, "provider", "gcp")
-		return
+		var err error
+		gridCO2e, fallback, err = l.fallbackIntensity(emFactors, instance.Region)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the resources of the services without a kind, e.g. the load
+	// balancers, only have the coefficients of the provider
+	params := serviceParameters(emFactors)
+	if instance.Kind != "" {
+		var err error
+		params, err = kindParameters(emFactors, instances, instance.Kind, instance.CPUPlatform)
+		if err != nil {
+			return nil, err
+		}
 	}
+	params.gridCO2e = gridCO2e
+	params.gridFallback = fallback
+	l.regionParameters(&params, instance.Provider, instance.Region)
+	hostParameters(&params, emFactors, instance)
+	l.acceleratorParameters(&params, emFactors, instance.Provider, instance.Region, instance.Kind, instance.Labels)
 
-	// TODO: hotfix until updated in emissions data
-	// convert gridCO2e from metric tonnes to grams
-	gridCO2e := gridCO2eTons * (1000 * 1000)
+	// the points of a curve are shared by the breakdowns
+	wattage := []WattagePoint{}
+	if len(params.wattage) > 0 {
+		wattage = curveOf(params.wattage).points
+	}
 
+	breakdown := &Breakdown{
+		Provider:         instance.Provider,
+		Account:          instance.Labels[v1.AccountLabel],
+		Name:             instance.Name,
+		Region:           instance.Region,
+		Zone:             instance.Zone,
+		Kind:             instance.Kind,
+		CalculatedAt:     time.Now().UTC(),
+		Interval:         interval,
+		Observed:         instance.Observed(interval),
+		GridCO2e:         params.gridCO2e,
+		GridFallback:     params.gridFallback,
+		PUE:              params.pue,
+		PUESource:        params.pueSource,
+		VCPU:             params.vCPU,
+		Architecture:     params.architecture,
+		Wattage:          wattage,
+		EmbodiedFactor:   params.embodiedFactor,
+		Accelerators:     params.accelerators,
+		EmbodiedOverhead: params.embodiedOverhead,
+		Metrics:          make([]MetricBreakdown, 0, len(instance.Metrics)),
+	}
+
+	// calculate and set the operational emissions for each
+	// metric type (CPU, Memory, Storage, and networking)
+	metrics := instance.Metrics
+	for _, v := range metrics {
+		params.metric = v
+		// the labels are shared with the instance collected
+		if params.gridFallback != "" {
+			params.metric.Labels = params.metric.Labels.With(v1.GridFallbackLabel, params.gridFallback)
+		}
+		// the CPU emissions take 4 steps
+		params.steps = make([]Step, 0, 4)
+		params.anomalies = nil
+
+		mb := MetricBreakdown{
+			Name:       v.Name,
+			Usage:      v.Usage,
+			UnitAmount: v.UnitAmount,
+			Unit:       v.Unit.String(),
+		}
+
+		// the metrics observed for a part of the interval only are
+		// prorated, the ones collected less often than every scrape are
+		// accounted for over their own window
+		opEm, err := operationalEmissions(ctx, v.Observed.Span(interval), &params)
+		mb.Steps = params.steps
+		if err != nil {
+			logger.Error("failed calculating operational emissions", "type", v.Name, "error", err)
+			mb.Error = err.Error()
+			breakdown.Metrics = append(breakdown.Metrics, mb)
+			continue
+		}
+		opEm = params.clamp(opEm, 0, math.Inf(1), AnomalyNegativeEmissions, AnomalyNegativeEmissions)
+		for _, a := range params.anomalies {
+			calculationAnomalies.WithLabelValues(instance.Provider.String(), a.Type).Inc()
+			logger.Warn("clamped an implausible value of the calculation",
+				"provider", instance.Provider, "instance", instance.Name, "metric", v.Name,
+				"type", a.Type, "value", a.Value, "clamped", a.Clamped)
+		}
+		mb.Anomalies = params.anomalies
+
+		mb.Emissions = opEm
+		mb.Quality = v1.Quality{
+			Power: params.power,
+			// the emission factors only have the annual intensity
+			Intensity: v1.IntensityAnnual,
+		}
+		if v.Power > 0 {
+			mb.Quality.Power = v1.PowerMeasured
+		}
+		breakdown.Metrics = append(breakdown.Metrics, mb)
+
+		params.metric.Emissions = v1.NewResourceEmission(opEm, v1.GCO2eqkWh)
+		params.metric.Emissions.Quality = mb.Quality
+		// update the instance metrics
+		metrics.Upsert(&params.metric)
+	}
+
+	slices.SortFunc(breakdown.Metrics, func(a, b MetricBreakdown) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	embodied := embodiedEmissions(breakdown.Observed, params.embodiedFactor)
+	breakdown.Embodied = Step{
+		Description: "embodied emissions in gCO2eq over the interval",
+		Formula:     formula("%g gCO2eq/h / 60 * %g min", params.embodiedFactor, breakdown.Observed.Minutes()),
+		Value:       embodied,
+	}
+
+	breakdown.EmbodiedQuality = v1.Quality{Power: params.power}
+
+	instance.EmbodiedEmissions = v1.NewResourceEmission(
+		embodied,
+		v1.GCO2eqkWh,
+	)
+	instance.EmbodiedEmissions.Quality = breakdown.EmbodiedQuality
+	instance.GridIntensity = breakdown.GridCO2e
+
+	return breakdown, nil
+}
+
+// kindParameters returns the wattage, vCPUs, PUE and embodied factor of an
+// instance type, from the instance types of the v2 dataset if available.
+// Otherwise the wattage is the one of the CPU platform the instance runs on
+// when known, and the one of the architecture of the instance type if not
+func kindParameters(
+	emFactors *factors.EmissionFactors,
+	instances func(kind string) (data.Instance, bool),
+	kind, cpuPlatform string,
+) (parameters, error) {
 	params := parameters{
-		gridCO2e: gridCO2e,
-		pue:      emFactors.AveragePUE,
+		pue: emFactors.AveragePUE,
 	}
+	networkParameters(&params, emFactors)
 
-	specs, ok := emFactors.Embodied[instance.Kind]
+	specs, ok := emFactors.Embodied[kind]
 	if !ok {
-		c.logger.Error("failed finding instance in factor data", "instance", instance.Name, "kind", instance.Kind)
-		return
+		return params, fmt.Errorf("%w: %s %s", ErrUnknownKind, emFactors.Provider, kind)
+	}
+
+	var d data.Instance
+	ok = false
+	if instances != nil {
+		d, ok = instances(kind)
 	}
 
-	if d, ok := awsInstances[instance.Kind]; ok {
+	if ok {
 		params.wattage = d.PkgWatt
 		params.vCPU = float64(d.VCPU)
 		params.embodiedFactor = d.EmbodiedHourlyGCO2e
+		params.architecture = d.Architecture
+		params.power = v1.PowerCurve
+		memoryParameters(&params, emFactors, &specs, &d, kind)
+		storageParameters(&params, emFactors)
 	} else {
-		params.wattage = []data.Wattage{
+		machine := specs.MachineSpecs
+		if platform, ok := platformSpecs(emFactors.Use, cpuPlatform); ok {
+			machine = platform
+		}
+
+		wattage := [...]data.Wattage{
 			{
 				Percentage: 0,
-				Wattage:    specs.MinWatts,
+				Wattage:    machine.MinWatts,
 			},
 			{
 				Percentage: 100,
-				Wattage:    specs.MaxWatts,
+				Wattage:    machine.MaxWatts,
 			},
 		}
+		// shared by the calculations of the architecture
+		params.wattage = curveOf(wattage[:]).wattage
 		params.embodiedFactor = hourlyEmbodiedEmissions(&specs)
+		params.architecture = machine.Architecture
+		params.power = v1.PowerFallback
+		memoryParameters(&params, emFactors, &specs, nil, kind)
+		storageParameters(&params, emFactors)
 	}
 
-	// calculate and set the operational emissions for each
-	// metric type (CPU, Memory, Storage, and networking)
-	metrics := instance.Metrics
-	for _, v := range metrics {
-		params.metric = &v
-		opEm, err := operationalEmissions(log.WithContext(context.Background(), c.logger), interval, &params)
-		if err != nil {
-			c.logger.Error("failed calculating operational emissions", "type", v.Name, "error", err)
-			continue
-		}
-		params.metric.Emissions = v1.NewResourceEmission(opEm, v1.GCO2eqkWh)
-		// update the instance metrics
-		metrics.Upsert(params.metric)
+	return params, nil
+}
+
+// hostParameters scales the vCPUs and the embodied emissions of the kind to
+// the whole host of the instance, when it's a dedicated one
+func hostParameters(p *parameters, emFactors *factors.EmissionFactors, instance *v1.Instance) {
+	if instance.HostVCPU <= 0 {
+		return
 	}
 
-	instance.EmbodiedEmissions = v1.NewResourceEmission(
-		embodiedEmissions(interval, params.embodiedFactor),
-		v1.GCO2eqkWh,
-	)
+	vCPU := p.vCPU
+	if vCPU == 0 {
+		vCPU = emFactors.Embodied[instance.Kind].VCPU
+	}
+	if vCPU > 0 {
+		p.embodiedFactor *= instance.HostVCPU / vCPU
+	}
 
-	// We publish the interface on the bus once its been calculated
-	if err := c.Bus.Publish(&bus.Event{
-		Type: v1.EmissionsCalculatedEvent,
-		Data: instance,
-	}); err != nil {
-		c.logger.Error("failed publishing instance after calculation", "instance", instance.Name, "error", err)
+	// without the vCPUs of the kind the ones of the metric, which are the
+	// ones of the host, are used
+	if p.vCPU > 0 {
+		p.vCPU = instance.HostVCPU
 	}
 }
 
@@ -1,9 +1,14 @@
 package calculator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/re-cinq/aether/pkg/bus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
 	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
 	"github.com/stretchr/testify/require"
 )
@@ -71,3 +76,85 @@ func TestHourlyEmbodiedEmissions(t *testing.T) {
 		})
 	}
 }
+
+func TestHostParameters(t *testing.T) {
+	assert := require.New(t)
+
+	emFactors := &factors.EmissionFactors{
+		Embodied: map[string]factors.Embodied{
+			"n2-standard-16": {VCPU: 16},
+		},
+	}
+
+	// the instances which aren't hosts are left as they are
+	p := parameters{vCPU: 16, embodiedFactor: 2}
+	hostParameters(&p, emFactors, &v1.Instance{Kind: "n2-standard-16"})
+	assert.Equal(parameters{vCPU: 16, embodiedFactor: 2}, p)
+
+	// the kind is scaled to the vCPUs of the host
+	p = parameters{vCPU: 16, embodiedFactor: 2}
+	hostParameters(&p, emFactors, &v1.Instance{Kind: "n2-standard-16", HostVCPU: 80})
+	assert.Equal(parameters{vCPU: 80, embodiedFactor: 10}, p)
+
+	// without the vCPUs of the dataset the ones of the specs are used
+	p = parameters{embodiedFactor: 2}
+	hostParameters(&p, emFactors, &v1.Instance{Kind: "n2-standard-16", HostVCPU: 80})
+	assert.Equal(parameters{embodiedFactor: 10}, p)
+}
+
+func TestNewHandlerDegraded(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	corpusFactors(t)
+
+	defaults := loadFactors
+	t.Cleanup(func() { loadFactors = defaults })
+
+	var pulls int
+	pullErr := errors.New("repository not found")
+	loadFactors = func(context.Context) (Dataset, error) {
+		pulls++
+		if pullErr != nil {
+			return Dataset{}, pullErr
+		}
+		return Dataset{Version: "corpus", RefreshedAt: time.Now()}, nil
+	}
+
+	// the handler is degraded when the factors can't be pulled
+	c := NewHandler(ctx, bus.New())
+	assert.NotNil(c)
+	assert.Equal(1, pulls)
+	assert.Equal("repository not found", c.Dataset().Error)
+	assert.Zero(c.Dataset().RefreshedAt)
+
+	event := func(name string) *bus.Event {
+		i := v1.NewInstance(name, v1.AWS)
+		i.Region = "eu-west-1"
+		i.Kind = "m5.xlarge"
+		i.Metrics.Upsert(&v1.Metric{Name: v1.CPU.String(), Usage: 40, UnitAmount: 4})
+		return &bus.Event{Type: v1.MetricsCollectedEvent, Data: *i}
+	}
+
+	// the pull is retried by the next collected metrics, which are
+	// calculated with the factors on disk meanwhile
+	c.Handle(ctx, event("i-1"))
+	assert.Equal(2, pulls)
+	_, err := c.Breakdown("", "", "i-1")
+	assert.NoError(err)
+
+	pullErr = nil
+	c.Handle(ctx, event("i-2"))
+	assert.Equal(3, pulls)
+	assert.Equal("corpus", c.Dataset().Version)
+	assert.Empty(c.Dataset().Error)
+
+	// and not once pulled
+	c.Handle(ctx, event("i-3"))
+	assert.Equal(3, pulls)
+
+	// the factors pulled before are used when the refresh fails
+	pullErr = errors.New("repository not found")
+	assert.ErrorIs(c.RefreshFactors(ctx), v1.ErrDatasetStale)
+	assert.Equal("corpus", c.Dataset().Version)
+	assert.Equal("repository not found", c.Dataset().Error)
+}
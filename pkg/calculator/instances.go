@@ -0,0 +1,317 @@
+package calculator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+)
+
+var (
+	// instancesURL returns where the instance types of the v2 dataset of a
+	// provider are downloaded from when the local repo doesn't have them
+	instancesURL = rawInstancesURL
+
+	// instancesDir is the directory the instance types are downloaded to,
+	// they're only downloaded again when they changed
+	instancesDir = filepath.Join(os.TempDir(), "emissions-data-v2")
+
+	// instancesClient downloads the instance types, the download is bounded
+	// so that a network without egress doesn't hang the refresh
+	instancesClient = &http.Client{Timeout: time.Minute}
+)
+
+// rawInstancesURL returns the raw file of the instance types of the provider
+// in the repo the emission factors are pulled from. Only the GitHub repos
+// have raw files, the mirrors must have the v2 dataset
+func rawInstancesURL(provider v1.Provider) (string, error) {
+	repo := strings.TrimSuffix(strings.TrimSuffix(factors.RepoURL(), "/"), ".git")
+	name, ok := strings.CutPrefix(repo, "https://github.com/")
+	if !ok {
+		return "", fmt.Errorf("no v2 dataset in %s", factors.RepoURL())
+	}
+
+	return fmt.Sprintf("https://raw.githubusercontent.com/%s/HEAD/data/v2/%s-instances.yaml", name, provider), nil
+}
+
+// span is where an instance type is in the dataset file
+type span struct {
+	offset, length int64
+}
+
+// instanceIndex is the instance types of a v2 dataset file. The file is only
+// scanned for where the instance types are, an instance type is decoded the
+// first time it's observed and kept in memory afterwards, so that only the
+// kinds of the scraped instances are held and not the whole dataset. It's
+// safe for concurrent use
+type instanceIndex struct {
+	// The file stays open while the index is used, so that the index isn't
+	// affected when the file is downloaded again. It's closed once the
+	// index is garbage collected
+	file  *os.File
+	spans map[string]span
+
+	// The instance types decoded so far
+	kinds map[string]data.Instance
+	mu    sync.RWMutex
+}
+
+// instancesOf returns the index of the instance types already decoded
+func instancesOf(kinds map[string]data.Instance) *instanceIndex {
+	return &instanceIndex{
+		kinds: kinds,
+	}
+}
+
+// indexInstances scans the dataset file for the instance types. The file is
+// a mapping of the instance types by kind in block style, like the ones of
+// the emissions-data repo
+func indexInstances(path string) (*instanceIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spans, err := scanInstances(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to index %s: %w", path, err)
+	}
+
+	return &instanceIndex{
+		file:  file,
+		spans: spans,
+		kinds: make(map[string]data.Instance),
+	}, nil
+}
+
+// scanInstances returns where the instance types are, from their key at the
+// start of a line to the next one
+func scanInstances(r io.Reader) (map[string]span, error) {
+	spans := make(map[string]span)
+
+	var (
+		kind   string
+		offset int64
+		start  int64 = -1
+	)
+	end := func() {
+		if start >= 0 {
+			spans[kind] = span{offset: start, length: offset - start}
+		}
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		key := bytes.TrimRight(line, "\r\n")
+		if len(key) > 0 && !isIndented(key[0]) && !bytes.Equal(key, []byte("---")) {
+			if !bytes.HasSuffix(key, []byte(":")) {
+				return nil, fmt.Errorf("unsupported line at offset %d: %q", offset, key)
+			}
+			end()
+			kind = string(unquote(bytes.TrimSuffix(key, []byte(":"))))
+			start = offset
+		}
+		offset += int64(len(line))
+
+		if err != nil {
+			break
+		}
+	}
+	end()
+
+	return spans, nil
+}
+
+// isIndented tells if a line starting with the byte isn't a key of the
+// mapping of the instance types
+func isIndented(b byte) bool {
+	return b == ' ' || b == '\t' || b == '-' || b == '#'
+}
+
+// unquote removes the quotes of a key
+func unquote(key []byte) []byte {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// instance returns the instance type of the kind, decoded on first use
+func (x *instanceIndex) instance(kind string) (data.Instance, bool) {
+	if x == nil {
+		return data.Instance{}, false
+	}
+
+	x.mu.RLock()
+	d, ok := x.kinds[kind]
+	x.mu.RUnlock()
+
+	if ok {
+		return d, true
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	// decoded by another calculation in the meantime
+	if d, ok := x.kinds[kind]; ok {
+		return d, true
+	}
+
+	s, ok := x.spans[kind]
+	if !ok {
+		return data.Instance{}, false
+	}
+
+	d, err := x.decode(kind, s)
+	if err != nil {
+		// it isn't decoded again, the instances of the kind fall back to
+		// the v1 dataset
+		slog.Warn("failed to decode the instance type", "kind", kind, "error", err)
+		delete(x.spans, kind)
+		return data.Instance{}, false
+	}
+	x.kinds[kind] = d
+
+	return d, true
+}
+
+// decode reads the instance type of the kind from the dataset file
+func (x *instanceIndex) decode(kind string, s span) (data.Instance, error) {
+	b := make([]byte, s.length)
+	if _, err := x.file.ReadAt(b, s.offset); err != nil {
+		return data.Instance{}, err
+	}
+
+	var instances map[string]data.Instance
+	if err := yaml.Unmarshal(b, &instances); err != nil {
+		return data.Instance{}, err
+	}
+
+	d, ok := instances[kind]
+	if !ok {
+		return data.Instance{}, fmt.Errorf("instance type not found at offset %d", s.offset)
+	}
+
+	return d, nil
+}
+
+// loadInstances indexes the instance types of the v2 dataset of the provider
+// in the local repo, pulled with the v1 one. They're downloaded from the
+// repo when it doesn't have them. The download is kept on disk and only done
+// again when the dataset changed, the one kept is used when it fails
+func loadInstances(ctx context.Context, provider v1.Provider) (*instanceIndex, error) {
+	logger := log.FromContext(ctx)
+
+	file := fmt.Sprintf("%s-instances.yaml", provider)
+	if local := filepath.Join(factors.V2DataPath, file); fileExists(local) {
+		return indexInstances(local)
+	}
+
+	path := filepath.Join(instancesDir, file)
+	if err := downloadInstances(ctx, provider, path); err != nil {
+		if _, errStat := os.Stat(path); errStat != nil {
+			return nil, err
+		}
+		logger.Warn("failed to download the v2 instance types, using the ones downloaded before", "error", err)
+	}
+
+	return indexInstances(path)
+}
+
+// fileExists tells whether the path is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// downloadInstances downloads the instance types of the provider to the path
+// unless they're the ones already there
+func downloadInstances(ctx context.Context, provider v1.Provider, path string) error {
+	url, err := instancesURL(provider)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	// the ETag of the download is kept next to it
+	etagPath := path + ".etag"
+	if etag, err := os.ReadFile(etagPath); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	resp, err := instancesClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil
+	case http.StatusOK:
+	default:
+		return fmt.Errorf("failed to download the instance types: %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// the file is replaced and not written in place, the indexes of the
+	// previous download keep reading it
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		if err := os.Remove(etagPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(etagPath, []byte(etag), 0o600)
+}
@@ -0,0 +1,165 @@
+package calculator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+const instancesYAML = `---
+# the instance types
+m5.large:
+  kind: m5.large
+  vcpu: 2
+  pkgwatt:
+  - percentage: 0
+    wattage: 1.21
+  - percentage: 100
+    wattage: 12.38
+  embodiedhourlygco2e: 4.5
+  platform:
+    architecture: Skylake
+"m5.xlarge":
+  kind: m5.xlarge
+  vcpu: 4
+  embodiedhourlygco2e: 9
+broken:
+  vcpu: [
+`
+
+func TestScanInstances(t *testing.T) {
+	assert := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "instances.yaml")
+	assert.NoError(os.WriteFile(path, []byte(instancesYAML), 0o600))
+
+	x, err := indexInstances(path)
+	assert.NoError(err)
+	assert.Len(x.spans, 3)
+	assert.Empty(x.kinds)
+
+	// decoded on first use
+	d, ok := x.instance("m5.large")
+	assert.True(ok)
+	assert.Equal(2, d.VCPU)
+	assert.Equal(12.38, d.PkgWatt[1].Wattage)
+	assert.Equal("Skylake", d.Architecture)
+	assert.Len(x.kinds, 1)
+
+	d, ok = x.instance("m5.xlarge")
+	assert.True(ok)
+	assert.Equal(9.0, d.EmbodiedHourlyGCO2e)
+	assert.Len(x.kinds, 2)
+
+	_, ok = x.instance("m5.metal")
+	assert.False(ok)
+
+	// the kinds which can't be decoded are missing
+	_, ok = x.instance("broken")
+	assert.False(ok)
+	assert.NotContains(x.spans, "broken")
+
+	// only the mappings in block style are supported
+	assert.NoError(os.WriteFile(path, []byte("{m5.large: {vcpu: 2}}\n"), 0o600))
+	_, err = indexInstances(path)
+	assert.ErrorContains(err, "unsupported line at offset 0")
+}
+
+func TestLoadInstances(t *testing.T) {
+	assert := require.New(t)
+
+	url, dir, local := instancesURL, instancesDir, factors.V2DataPath
+	t.Cleanup(func() { instancesURL, instancesDir, factors.V2DataPath = url, dir, local })
+
+	var (
+		downloads int
+		fail      bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(instancesYAML))
+	}))
+	t.Cleanup(server.Close)
+
+	instancesURL = func(provider v1.Provider) (string, error) {
+		return server.URL + "/" + provider.String() + "-instances.yaml", nil
+	}
+	instancesDir = t.TempDir()
+	factors.V2DataPath = t.TempDir()
+	ctx := context.Background()
+
+	// nothing was downloaded yet
+	fail = true
+	_, err := loadInstances(ctx, "aws")
+	assert.Error(err)
+
+	fail = false
+	for range 2 {
+		x, err := loadInstances(ctx, "aws")
+		assert.NoError(err)
+		_, ok := x.instance("m5.large")
+		assert.True(ok)
+	}
+	assert.Equal(1, downloads)
+
+	// the previous download is used
+	fail = true
+	x, err := loadInstances(ctx, "aws")
+	assert.NoError(err)
+	_, ok := x.instance("m5.xlarge")
+	assert.True(ok)
+	assert.FileExists(filepath.Join(instancesDir, "aws-instances.yaml"))
+
+	// the ones of the local repo aren't downloaded
+	assert.NoError(os.WriteFile(filepath.Join(factors.V2DataPath, "gcp-instances.yaml"), []byte(instancesYAML), 0o600))
+	x, err = loadInstances(ctx, "gcp")
+	assert.NoError(err)
+	_, ok = x.instance("m5.large")
+	assert.True(ok)
+	assert.NoFileExists(filepath.Join(instancesDir, "gcp-instances.yaml"))
+}
+
+func TestRawInstancesURL(t *testing.T) {
+	t.Cleanup(func() { factors.SetRepoURL("") })
+
+	tests := []struct {
+		repo     string
+		expected string
+	}{
+		{repo: "", expected: "https://raw.githubusercontent.com/re-cinq/emissions-data/HEAD/data/v2/aws-instances.yaml"},
+		{repo: "https://github.com/acme/emissions-data.git", expected: "https://raw.githubusercontent.com/acme/emissions-data/HEAD/data/v2/aws-instances.yaml"},
+		// the mirrors must have the v2 dataset
+		{repo: "https://git.internal.example.com/emissions-data.git"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.repo, func(t *testing.T) {
+			assert := require.New(t)
+
+			factors.SetRepoURL(test.repo)
+			url, err := rawInstancesURL(v1.AWS)
+			if test.expected == "" {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(test.expected, url)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// ErrUnknownRegion is returned when the emission factors have no grid
+// intensity for the region
+var ErrUnknownRegion error = v1.ErrRegionNotFound
+
+// The grid intensity of the regions used by the calculations
+var gridIntensityGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "grid_intensity",
+		Help: "The grid carbon intensity of a region in gCO2eq/kWh, as used by the calculations",
+	},
+	[]string{"provider", "region"},
+)
+
+func init() {
+	prometheus.MustRegister(gridIntensityGauge)
+}
+
+// regionKey identifies the region of a provider
+type regionKey struct {
+	provider v1.Provider
+	region   string
+}
+
+// gridIntensityGauges caches the gauges of the regions, looking them up by
+// their label values allocates at every calculation
+var gridIntensityGauges = struct {
+	byRegion map[regionKey]prometheus.Gauge
+	mu       sync.RWMutex
+}{
+	byRegion: make(map[regionKey]prometheus.Gauge),
+}
+
+// setGridIntensity sets the grid intensity of the region in gCO2eq/kWh
+func setGridIntensity(provider v1.Provider, region string, gridCO2e float64) {
+	key := regionKey{provider: provider, region: region}
+
+	gridIntensityGauges.mu.RLock()
+	g, ok := gridIntensityGauges.byRegion[key]
+	gridIntensityGauges.mu.RUnlock()
+
+	if !ok {
+		g = gridIntensityGauge.WithLabelValues(provider.String(), region)
+
+		gridIntensityGauges.mu.Lock()
+		gridIntensityGauges.byRegion[key] = g
+		gridIntensityGauges.mu.Unlock()
+	}
+
+	g.Set(gridCO2e)
+}
+
+// GridIntensity returns the grid carbon intensity of the region of the
+// provider in gCO2eq/kWh, according to the emission factors in use
+func GridIntensity(provider v1.Provider, region string) (float64, error) {
+	return (*Locations)(nil).GridIntensity(provider, region)
+}
+
+// GridIntensity returns the grid carbon intensity of the region of the
+// provider in gCO2eq/kWh, the one of the custom region or of the emission
+// factors in use
+func (l *Locations) GridIntensity(provider v1.Provider, region string) (float64, error) {
+	emFactors, err := emissionFactors.provider(provider)
+	if err != nil {
+		return 0, err
+	}
+
+	return l.gridIntensity(emFactors, region)
+}
+
+// The parent region of the zones named after it, e.g. us-west-2 for the
+// us-west-2-lax-1a Local Zone or the us-east-1-wl1-bos-wlz-1 Wavelength Zone
+var parentRegion = regexp.MustCompile(`^([a-z]{2}(?:-gov)?-[a-z]+-[0-9]+)-[a-z0-9-]+$`)
+
+// gridIntensity returns the grid intensity of the region in gCO2eq/kWh. The
+// custom regions of the config take precedence over the emission factors,
+// and the zones missing from them, e.g. the AWS Local Zones, use the one of
+// their parent region
+func (l *Locations) gridIntensity(emFactors *factors.EmissionFactors, region string) (float64, error) {
+	if r, ok := l.customRegion(emFactors.Provider, region); ok && r.GridIntensity > 0 {
+		return r.GridIntensity, nil
+	}
+
+	return factorsIntensity(emFactors, region)
+}
+
+// factorsIntensity returns the grid intensity of the region in gCO2eq/kWh
+// according to the emission factors only
+func factorsIntensity(emFactors *factors.EmissionFactors, region string) (float64, error) {
+	gridCO2eTons, ok := emFactors.Coefficient[region]
+	if !ok {
+		if m := parentRegion.FindStringSubmatch(region); m != nil {
+			gridCO2eTons, ok = emFactors.Coefficient[m[1]]
+		}
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: %s %s", ErrUnknownRegion, emFactors.Provider, region)
+	}
+
+	// TODO: hotfix until updated in emissions data
+	// convert gridCO2e from metric tonnes to grams
+	return gridCO2eTons * (1000 * 1000), nil
+}
+
+// The levels of the fallback of the grid intensity of the regions missing
+// from the emission factors, the average of the regions of the same country,
+// of the same continent, or of all the regions of the provider
+const (
+	GridFallbackCountry   = "country"
+	GridFallbackContinent = "continent"
+	GridFallbackGlobal    = "global"
+)
+
+// The continents of the regions named after them, when neither the emission
+// factors nor the config set it
+var continentPrefixes = []struct {
+	prefix    string
+	continent string
+}{
+	{"us-", "north-america"},
+	{"ca-", "north-america"},
+	{"mx-", "north-america"},
+	{"northamerica-", "north-america"},
+	{"sa-", "south-america"},
+	{"southamerica-", "south-america"},
+	{"eu-", "europe"},
+	{"europe-", "europe"},
+	{"ap-", "asia-pacific"},
+	{"asia-", "asia-pacific"},
+	{"australia-", "asia-pacific"},
+	{"me-", "middle-east"},
+	{"il-", "middle-east"},
+	{"af-", "africa"},
+	{"africa-", "africa"},
+}
+
+// regionLocation returns the country and the continent of a region, from the
+// emission factors and the custom regions of the config, the continent
+// of the regions named after it otherwise
+func (l *Locations) regionLocation(emFactors *factors.EmissionFactors, region string) factors.Location {
+	loc := emFactors.Locations[region]
+	if r, ok := l.customRegion(emFactors.Provider, region); ok {
+		if r.Country != "" {
+			loc.Country = r.Country
+		}
+		if r.Continent != "" {
+			loc.Continent = r.Continent
+		}
+	}
+
+	if loc.Continent == "" {
+		for _, p := range continentPrefixes {
+			if strings.HasPrefix(region, p.prefix) {
+				loc.Continent = p.continent
+				break
+			}
+		}
+	}
+
+	return loc
+}
+
+// fallbackIntensity returns the grid intensity of the region in gCO2eq/kWh
+// and, when the region is missing from the emission factors, the level of
+// the configured fallback whose average is used instead. The error of the
+// region is returned when no level has an average
+func (l *Locations) fallbackIntensity(emFactors *factors.EmissionFactors, region string) (float64, string, error) {
+	gridCO2e, err := l.gridIntensity(emFactors, region)
+	if !errors.Is(err, ErrUnknownRegion) || l == nil {
+		return gridCO2e, "", err
+	}
+
+	loc := l.regionLocation(emFactors, region)
+	for _, level := range l.gridFallback {
+		if avg, ok := l.averageIntensity(emFactors, level, loc); ok {
+			return avg, level, nil
+		}
+	}
+
+	return 0, "", err
+}
+
+// averageIntensity returns the average grid intensity in gCO2eq/kWh of the
+// regions of the emission factors in the same country or continent as the
+// location, or of all of them. The unknown levels have none
+func (l *Locations) averageIntensity(emFactors *factors.EmissionFactors, level string, loc factors.Location) (float64, bool) {
+	var match func(factors.Location) bool
+	switch level {
+	case GridFallbackCountry:
+		if loc.Country == "" {
+			return 0, false
+		}
+		match = func(l factors.Location) bool { return l.Country == loc.Country }
+	case GridFallbackContinent:
+		if loc.Continent == "" {
+			return 0, false
+		}
+		match = func(l factors.Location) bool { return l.Continent == loc.Continent }
+	case GridFallbackGlobal:
+		match = func(factors.Location) bool { return true }
+	default:
+		return 0, false
+	}
+
+	var sum float64
+	var n int
+	for region := range emFactors.Coefficient {
+		if !match(l.regionLocation(emFactors, region)) {
+			continue
+		}
+		gridCO2e, err := l.gridIntensity(emFactors, region)
+		if err != nil {
+			continue
+		}
+		sum += gridCO2e
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+
+	return sum / float64(n), true
+}
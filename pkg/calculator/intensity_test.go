@@ -0,0 +1,103 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGridIntensity(t *testing.T) {
+	emFactors := &factors.EmissionFactors{
+		Provider: v1.AWS,
+		Coefficient: factors.CoefficientData{
+			"us-west-2":     0.0003,
+			"us-gov-west-1": 0.0004,
+		},
+	}
+
+	tests := []struct {
+		region   string
+		expected float64
+	}{
+		{region: "us-west-2", expected: 300},
+		// Local Zones and Wavelength Zones use their parent region
+		{region: "us-west-2-lax-1a", expected: 300},
+		{region: "us-west-2-wl1-las-wlz-1", expected: 300},
+		{region: "us-gov-west-1-lax-1", expected: 400},
+		{region: "eu-west-1-ham-1a"},
+		{region: "us-west-2a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.region, func(t *testing.T) {
+			assert := require.New(t)
+
+			gridCO2e, err := factorsIntensity(emFactors, test.region)
+			if test.expected == 0 {
+				assert.ErrorIs(err, ErrUnknownRegion)
+				return
+			}
+			assert.NoError(err)
+			assert.InDelta(test.expected, gridCO2e, 1e-9)
+		})
+	}
+}
+
+func TestFallbackIntensity(t *testing.T) {
+	l := NewLocations(&config.ApplicationConfig{
+		Factors: config.FactorsConfig{GridFallback: []string{"country", "continent", "global"}},
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {Regions: map[string]config.RegionConfig{
+				"eu-central-2": {Country: "CH"},
+				"eu-south-2":   {Country: "ES"},
+			}},
+		},
+	})
+
+	emFactors := &factors.EmissionFactors{
+		Provider: v1.AWS,
+		Coefficient: factors.CoefficientData{
+			"eu-central-1": 0.0004,
+			"eu-west-3":    0.00006,
+			"us-east-1":    0.0005,
+			"zurich-1":     0.00002,
+		},
+		Locations: factors.LocationData{
+			"eu-central-1": {Country: "DE"},
+			"zurich-1":     {Country: "CH", Continent: "europe"},
+		},
+	}
+
+	tests := []struct {
+		region   string
+		expected float64
+		fallback string
+	}{
+		{region: "eu-central-1", expected: 400},
+		// the other regions of the country
+		{region: "eu-central-2", expected: 20, fallback: GridFallbackCountry},
+		// the regions of the continent, named after it or not
+		{region: "eu-south-2", expected: 160, fallback: GridFallbackContinent},
+		// all the regions
+		{region: "custom-1", expected: 245, fallback: GridFallbackGlobal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.region, func(t *testing.T) {
+			assert := require.New(t)
+
+			gridCO2e, fallback, err := l.fallbackIntensity(emFactors, test.region)
+			assert.NoError(err)
+			assert.InDelta(test.expected, gridCO2e, 1e-9)
+			assert.Equal(test.fallback, fallback)
+		})
+	}
+
+	// without fallback the region is unknown
+	l = NewLocations(&config.ApplicationConfig{})
+	_, _, err := l.fallbackIntensity(emFactors, "custom-1")
+	require.ErrorIs(t, err, ErrUnknownRegion)
+}
@@ -0,0 +1,110 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/log"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+)
+
+// family returns the instance family of an instance type, e.g. r5 for
+// r5.large or n2 for n2-highmem-8
+func family(kind string) string {
+	f, _, _ := strings.Cut(kind, ".")
+	f, _, _ = strings.Cut(f, "-")
+	return f
+}
+
+// memoryParameters sets the memory coefficient of the provider and the
+// DIMMs of the hosts of the family of the instance type, if known. The memory
+// of the instance type is the one of the v2 dataset, or its share of the
+// memory of the host when its family has DIMMs
+func memoryParameters(p *parameters, emFactors *factors.EmissionFactors, specs *factors.Embodied, d *data.Instance, kind string) {
+	p.memoryKW = emFactors.MemoryKilloWattHours
+
+	if m, ok := emFactors.Memory[family(kind)]; ok {
+		p.memory = &m
+	}
+
+	switch {
+	case d != nil && d.MemoryGB > 0:
+		p.memoryGB = d.MemoryGB
+	case p.memory != nil && specs.TotalVCPU > 0:
+		p.memoryGB = specs.VCPU / specs.TotalVCPU * p.memory.HostGB()
+	}
+}
+
+// memory calculates the CO2e operational emissions of the memory of a Cloud
+// VM instance over an interval of time.
+//
+// When the DIMMs of the hosts of the instance family are known, the power of
+// the memory is the one of the DIMMs backing the memory of the instance,
+// from idle to fully active with the share of the memory used. Otherwise it's
+// the memory coefficient of the provider, in kWh per GB, whatever the usage
+func memory(ctx context.Context, interval time.Duration, p *parameters) (float64, error) {
+	// the memory of the instance is the one allocated to it, the one of its
+	// type, or the one used when it's all that's known
+	gb := p.metric.UnitAmount
+	if gb == 0 {
+		gb = p.memoryGB
+	}
+	if gb == 0 {
+		gb = p.metric.Usage
+	}
+	if gb == 0 {
+		return 0, errors.New("error memory set to 0")
+	}
+	p.steps = append(p.steps, Step{
+		Description: "memory of the instance in GB",
+		Formula:     "GB",
+		Value:       gb,
+	})
+
+	var usageMemorykW float64
+	if p.memory != nil {
+		active := min(p.metric.Usage/gb, 1)
+		dimm := p.memory.DIMM
+		usageMemorykW = gb / dimm.GB * (dimm.IdleWatts + (dimm.ActiveWatts-dimm.IdleWatts)*active) / 1000
+		p.steps = append(p.steps, Step{
+			Description: "memory power in kW of the DIMMs from idle to active",
+			Formula: formula("%g GB / %g GB * (%g W + (%g W - %g W) * %g) / 1000",
+				gb, dimm.GB, dimm.IdleWatts, dimm.ActiveWatts, dimm.IdleWatts, active),
+			Value: usageMemorykW,
+		})
+	} else {
+		if p.memoryKW == 0 {
+			return 0, errors.New("error: cannot calculate memory energy, no memory coefficient found")
+		}
+		usageMemorykW = p.memoryKW * gb
+		p.steps = append(p.steps, Step{
+			Description: "memory power in kW from the coefficient of the provider",
+			Formula:     formula("%g kWh/GB * %g GB", p.memoryKW, gb),
+			Value:       usageMemorykW,
+		})
+	}
+
+	hours := interval.Minutes() / float64(60)
+	p.steps = append(p.steps, Step{
+		Description: "hours over the interval",
+		Formula:     formula("%g min / 60", interval.Minutes()),
+		Value:       hours,
+	})
+
+	if logger := log.FromContext(ctx); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf("memory calculation: %+v, %+v, %+v, %+v", usageMemorykW, hours, p.pue, p.gridCO2e))
+	}
+	emissions := usageMemorykW * hours * p.pue * p.gridCO2e
+	p.steps = append(p.steps, Step{
+		Description: "operational emissions in gCO2eq",
+		Formula:     formula("%g kW * %g h * %g PUE * %g gCO2eq/kWh", usageMemorykW, hours, p.pue, p.gridCO2e),
+		Value:       emissions,
+	})
+
+	return emissions, nil
+}
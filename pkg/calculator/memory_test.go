@@ -0,0 +1,133 @@
+package calculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFamily(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("r5", family("r5.xlarge"))
+	assert.Equal("n2", family("n2-highmem-8"))
+	assert.Equal("x2iedn", family("x2iedn.metal"))
+	assert.Equal("custom", family("custom"))
+}
+
+func TestMemoryParameters(t *testing.T) {
+	assert := require.New(t)
+
+	r5 := factors.MemorySpecs{
+		Family: "r5",
+		DIMMs:  24,
+		DIMM:   factors.DIMM{GB: 32, IdleWatts: 1.2, ActiveWatts: 4.8},
+	}
+	emFactors := &factors.EmissionFactors{
+		Memory:           factors.MemoryData{"r5": r5},
+		ProviderDefaults: &factors.ProviderDefaults{MemoryKilloWattHours: 0.000392},
+	}
+	specs := &factors.Embodied{VCPU: 4, TotalVCPU: 96}
+
+	// the memory of the v2 dataset comes first
+	p := parameters{}
+	memoryParameters(&p, emFactors, specs, &data.Instance{MemoryGB: 16}, "r5.xlarge")
+	assert.Equal(parameters{memoryKW: 0.000392, memory: &r5, memoryGB: 16}, p)
+
+	// the share of the host otherwise
+	p = parameters{}
+	memoryParameters(&p, emFactors, specs, nil, "r5.xlarge")
+	assert.Equal(32.0, p.memoryGB)
+
+	// the families without DIMMs only have the coefficient
+	p = parameters{}
+	memoryParameters(&p, emFactors, specs, nil, "m5.xlarge")
+	assert.Equal(parameters{memoryKW: 0.000392}, p)
+}
+
+func TestMemory(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	dimms := &factors.MemorySpecs{
+		DIMMs: 24,
+		DIMM:  factors.DIMM{GB: 32, IdleWatts: 1.2, ActiveWatts: 4.8},
+	}
+
+	type testcase struct {
+		description string
+		params      parameters
+		expected    float64
+		err         string
+	}
+	tt := []testcase{
+		{
+			description: "the coefficient of the provider whatever the usage",
+			params: parameters{
+				memoryKW: 0.0004,
+				metric:   v1.Metric{Usage: 2, UnitAmount: 10},
+			},
+			// 0.004 kW over an hour
+			expected: 4,
+		},
+		{
+			description: "the DIMMs from idle to active",
+			params: parameters{
+				memoryKW: 0.0004,
+				memory:   dimms,
+				metric:   v1.Metric{Usage: 32, UnitAmount: 64},
+			},
+			// 2 DIMMs at 3 W
+			expected: 6,
+		},
+		{
+			description: "the memory of the instance type",
+			params: parameters{
+				memory:   dimms,
+				memoryGB: 32,
+				metric:   v1.Metric{Usage: 32},
+			},
+			expected: 4.8,
+		},
+		{
+			description: "the used memory when it's all that's known",
+			params: parameters{
+				memory: dimms,
+				metric: v1.Metric{Usage: 16},
+			},
+			// half a DIMM fully active
+			expected: 2.4,
+		},
+		{
+			description: "no memory",
+			params: parameters{
+				memoryKW: 0.0004,
+			},
+			err: "error memory set to 0",
+		},
+		{
+			description: "no coefficient",
+			params: parameters{
+				metric: v1.Metric{Usage: 2, UnitAmount: 10},
+			},
+			err: "error: cannot calculate memory energy, no memory coefficient found",
+		},
+	}
+
+	for _, test := range tt {
+		test.params.pue = 1
+		test.params.gridCO2e = 1000
+		emissions, err := memory(ctx, time.Hour, &test.params)
+		if test.err != "" {
+			assert.EqualError(err, test.err, test.description)
+			continue
+		}
+		assert.NoError(err, test.description)
+		assert.InDelta(test.expected, emissions, 1e-9, test.description)
+	}
+}
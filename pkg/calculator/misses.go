@@ -0,0 +1,197 @@
+package calculator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The types of the lookups missing from the emission factors
+const (
+	MissKind   = "kind"
+	MissRegion = "region"
+)
+
+var (
+	// The lookups missing from the emission factors, the emissions of the
+	// instances are not calculated
+	missesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dataset_misses_total",
+			Help: "The calculations skipped because the instance kind or the region is missing from the emission factors",
+		},
+		[]string{"provider", "type", "value"},
+	)
+
+	// The failed calculations, by the code of their error
+	calculationErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "calculation_errors_total",
+			Help: "The calculations which failed, by the code of their error",
+		},
+		[]string{"provider", "code"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(missesCounter, calculationErrors)
+}
+
+// Miss is an instance kind or a region missing from the emission factors
+type Miss struct {
+	Provider v1.Provider `json:"provider"`
+
+	// Whether the kind or the region is missing
+	Type  string `json:"type"`
+	Value string `json:"value"`
+
+	// The amount of calculations skipped
+	Count int `json:"count"`
+
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+
+	// The last instance the lookup failed for
+	Instance string `json:"instance"`
+
+	// The specs of the instances of the missing kinds, as collected from
+	// the provider, zero when unknown
+	VCPU         float64 `json:"vCPU,omitempty"`
+	MemoryGB     float64 `json:"memoryGB,omitempty"`
+	Architecture string  `json:"architecture,omitempty"`
+}
+
+// missOf returns the miss the error of the calculation is about
+func missOf(instance *v1.Instance, err error) (Miss, bool) {
+	m := Miss{
+		Provider: instance.Provider,
+		Instance: instance.Name,
+	}
+
+	switch v1.Code(err) {
+	case v1.CodeKindNotFound:
+		m.Type = MissKind
+		m.Value = instance.Kind
+		m.VCPU = instance.Metrics[v1.CPU.String()].UnitAmount
+		if memory, ok := instance.Metrics[v1.Memory.String()]; ok && memory.Unit == v1.GB {
+			m.MemoryGB = memory.UnitAmount
+		}
+		m.Architecture = instance.CPUPlatform
+		if m.Architecture == "" {
+			m.Architecture = instance.Architecture
+		}
+	case v1.CodeRegionNotFound:
+		m.Type = MissRegion
+		m.Value = instance.Region
+	default:
+		return m, false
+	}
+
+	return m, true
+}
+
+// misses counts the lookups missing from the emission factors
+type misses struct {
+	seen map[string]*Miss
+	mu   sync.RWMutex
+}
+
+// add counts the miss and returns whether it is seen for the first time
+func (m *misses) add(miss Miss, at time.Time) bool {
+	missesCounter.WithLabelValues(miss.Provider.String(), miss.Type, miss.Value).Inc()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.seen == nil {
+		m.seen = make(map[string]*Miss)
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", miss.Provider, miss.Type, miss.Value)
+	if seen, ok := m.seen[key]; ok {
+		seen.Count++
+		seen.LastSeen = at
+		seen.Instance = miss.Instance
+		if miss.VCPU != 0 {
+			seen.VCPU = miss.VCPU
+		}
+		if miss.MemoryGB != 0 {
+			seen.MemoryGB = miss.MemoryGB
+		}
+		if miss.Architecture != "" {
+			seen.Architecture = miss.Architecture
+		}
+		return false
+	}
+
+	miss.Count = 1
+	miss.FirstSeen = at
+	miss.LastSeen = at
+	m.seen[key] = &miss
+
+	return true
+}
+
+// list returns the misses, the most frequent first
+func (m *misses) list() []Miss {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]Miss, 0, len(m.seen))
+	for _, miss := range m.seen {
+		list = append(list, *miss)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		if list[i].Provider != list[j].Provider {
+			return list[i].Provider < list[j].Provider
+		}
+		if list[i].Type != list[j].Type {
+			return list[i].Type < list[j].Type
+		}
+		return list[i].Value < list[j].Value
+	})
+
+	return list
+}
+
+// notifyMiss posts the miss as JSON to the webhook, e.g. to open an issue
+// on the emissions data repo
+func notifyMiss(ctx context.Context, webhook string, miss Miss) error {
+	body, err := json.Marshal(miss)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package calculator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMisses(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	corpusFactors(t)
+
+	posted := make(chan Miss, 3)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m Miss
+		assert.NoError(json.NewDecoder(r.Body).Decode(&m))
+		posted <- m
+	}))
+	defer webhook.Close()
+
+	c := NewHandler(ctx, bus.New(),
+		WithInterval(5*time.Minute),
+		WithDataset(Dataset{Version: "corpus"}),
+		WithMissesWebhook(webhook.URL),
+	)
+
+	instance := func(name, region, kind string) *bus.Event {
+		i := v1.NewInstance(name, v1.AWS)
+		i.Region = region
+		i.Kind = kind
+		i.Metrics.Upsert(&v1.Metric{Name: v1.CPU.String(), Usage: 40, UnitAmount: 4})
+		return &bus.Event{Type: v1.MetricsCollectedEvent, Data: *i}
+	}
+
+	c.Handle(ctx, instance("i-1", "eu-west-1", "x9.metal"))
+	c.Handle(ctx, instance("i-2", "eu-west-1", "x9.metal"))
+	c.Handle(ctx, instance("i-3", "moon-base1", "m5.xlarge"))
+	c.Handle(ctx, instance("i-4", "eu-west-1", "m5.xlarge"))
+
+	misses := c.Misses()
+	assert.Len(misses, 2)
+
+	// the most frequent first
+	assert.Equal(v1.AWS, misses[0].Provider)
+	assert.Equal(MissKind, misses[0].Type)
+	assert.Equal("x9.metal", misses[0].Value)
+	assert.Equal(2, misses[0].Count)
+	assert.Equal("i-2", misses[0].Instance)
+	assert.InDelta(4, misses[0].VCPU, 1e-9)
+	assert.False(misses[0].LastSeen.Before(misses[0].FirstSeen))
+
+	assert.Equal(MissRegion, misses[1].Type)
+	assert.Equal("moon-base1", misses[1].Value)
+	assert.Equal(1, misses[1].Count)
+	assert.Zero(misses[1].VCPU)
+
+	// only posted the first time
+	values := map[string]bool{}
+	for range 2 {
+		select {
+		case m := <-posted:
+			values[m.Value] = true
+		case <-time.After(5 * time.Second):
+			assert.Fail("the misses were not posted")
+		}
+	}
+	assert.Equal(map[string]bool{"x9.metal": true, "moon-base1": true}, values)
+	assert.Empty(posted)
+
+	// the regions calculated with a fallback are still missing
+	c.Configure(&config.ApplicationConfig{
+		Factors: config.FactorsConfig{GridFallback: []string{GridFallbackGlobal}},
+	})
+
+	c.Handle(ctx, instance("i-5", "moon-base1", "m5.xlarge"))
+	breakdown, err := c.Breakdown("", "", "i-5")
+	assert.NoError(err)
+	assert.Equal(GridFallbackGlobal, breakdown.GridFallback)
+	for _, m := range c.Misses() {
+		if m.Type == MissRegion {
+			assert.Equal(2, m.Count)
+			assert.Equal("i-5", m.Instance)
+		}
+	}
+
+	// the metrics have the level of the fallback
+	i := instance("i-6", "moon-base1", "m5.xlarge").Data.(v1.Instance)
+	b, err := c.Locations().Calculate(ctx, &i, 5*time.Minute)
+	assert.NoError(err)
+	assert.Equal(GridFallbackGlobal, i.Metrics[v1.CPU.String()].Labels[v1.GridFallbackLabel])
+
+	// the instance has the intensity it was calculated with
+	assert.Positive(i.GridIntensity)
+	assert.Equal(b.GridCO2e, i.GridIntensity)
+}
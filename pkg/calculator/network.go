@@ -0,0 +1,95 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// serviceParameters returns the parameters of the resources of a service
+// which aren't instances, e.g. the CDN distributions and the load balancers.
+// They have no kind, so neither a wattage nor embodied emissions, only the
+// coefficients of the provider
+func serviceParameters(emFactors *factors.EmissionFactors) parameters {
+	params := parameters{
+		pue:   emFactors.AveragePUE,
+		power: v1.PowerFallback,
+	}
+	storageParameters(&params, emFactors)
+	networkParameters(&params, emFactors)
+
+	return params
+}
+
+// networkParameters sets the networking coefficients of the provider, the
+// ones of the destination classes it doesn't have are the flat one
+func networkParameters(p *parameters, emFactors *factors.EmissionFactors) {
+	p.networkKW = emFactors.NetworkingKilloWattHours
+	p.destinations = map[string]float64{
+		v1.DestinationIntraRegion: emFactors.NetworkingKilloWattHours,
+		v1.DestinationInterRegion: emFactors.InterRegionNetworkingKilloWattHours,
+		v1.DestinationInternet:    emFactors.InternetNetworkingKilloWattHours,
+	}
+
+	for destination, kw := range p.destinations {
+		if kw == 0 {
+			p.destinations[destination] = p.networkKW
+		}
+	}
+}
+
+// network calculates the CO2e operational emissions of the data transferred
+// by a resource over an interval of time.
+//
+// The metric is the GB transferred over the interval, so it's not prorated,
+// and the energy of the network is the coefficient of the provider in kWh
+// per GB, the one of the class of the destination of the flows if known
+func network(ctx context.Context, _ time.Duration, p *parameters) (float64, error) {
+	gb := p.metric.UnitAmount
+	if gb == 0 {
+		return 0, nil
+	}
+	p.steps = append(p.steps, Step{
+		Description: "data transferred in GB over the interval",
+		Formula:     "GB",
+		Value:       gb,
+	})
+
+	description := "network energy in kWh from the coefficient of the provider"
+	networkKW := p.networkKW
+	if destination := p.metric.Labels[v1.DestinationLabel]; destination != "" {
+		if kw, ok := p.destinations[destination]; ok {
+			description = fmt.Sprintf("network energy in kWh to the %s destinations", destination)
+			networkKW = kw
+		}
+	}
+
+	if networkKW == 0 {
+		return 0, errors.New("error: cannot calculate network energy, no networking coefficient found")
+	}
+
+	kWh := gb * networkKW
+	p.steps = append(p.steps, Step{
+		Description: description,
+		Formula:     formula("%g GB * %g kWh/GB", gb, networkKW),
+		Value:       kWh,
+	})
+
+	if logger := log.FromContext(ctx); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf("network calculation: %+v, %+v, %+v", kWh, p.pue, p.gridCO2e))
+	}
+	emissions := kWh * p.pue * p.gridCO2e
+	p.steps = append(p.steps, Step{
+		Description: "operational emissions in gCO2eq",
+		Formula:     formula("%g kWh * %g PUE * %g gCO2eq/kWh", kWh, p.pue, p.gridCO2e),
+		Value:       emissions,
+	})
+
+	return emissions, nil
+}
@@ -0,0 +1,135 @@
+package calculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceParameters(t *testing.T) {
+	assert := require.New(t)
+
+	p := serviceParameters(&factors.EmissionFactors{
+		ProviderDefaults: &factors.ProviderDefaults{
+			AveragePUE:               1.2,
+			NetworkingKilloWattHours: 0.001,
+		},
+	})
+
+	// the services have neither a wattage nor embodied emissions
+	assert.Equal(1.2, p.pue)
+	assert.Equal(0.001, p.networkKW)
+	assert.Equal(v1.PowerFallback, p.power)
+	assert.Empty(p.wattage)
+	assert.Zero(p.embodiedFactor)
+}
+
+func TestNetworkParameters(t *testing.T) {
+	assert := require.New(t)
+
+	p := parameters{}
+	networkParameters(&p, &factors.EmissionFactors{
+		ProviderDefaults: &factors.ProviderDefaults{
+			NetworkingKilloWattHours:         0.001,
+			InternetNetworkingKilloWattHours: 0.006,
+		},
+	})
+
+	// the destinations missing from the emission factors have the flat
+	// coefficient
+	assert.Equal(0.001, p.networkKW)
+	assert.Equal(map[string]float64{
+		v1.DestinationIntraRegion: 0.001,
+		v1.DestinationInterRegion: 0.001,
+		v1.DestinationInternet:    0.006,
+	}, p.destinations)
+}
+
+func TestNetwork(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	type testcase struct {
+		description string
+		networkKW   float64
+		metric      v1.Metric
+		expected    float64
+		err         string
+	}
+	tt := []testcase{
+		{
+			description: "the GB transferred over the interval",
+			networkKW:   0.001,
+			metric:      v1.Metric{UnitAmount: 500},
+			// 0.5 kWh
+			expected: 500,
+		},
+		{
+			description: "the coefficient of the destination of the flows",
+			networkKW:   0.001,
+			metric: v1.Metric{
+				UnitAmount: 500,
+				Labels:     v1.Labels{v1.DestinationLabel: v1.DestinationInternet},
+			},
+			expected: 1000,
+		},
+		{
+			description: "an unknown destination has the flat coefficient",
+			networkKW:   0.001,
+			metric: v1.Metric{
+				UnitAmount: 500,
+				Labels:     v1.Labels{v1.DestinationLabel: "moon"},
+			},
+			expected: 500,
+		},
+		{
+			description: "nothing transferred",
+			metric:      v1.Metric{},
+			expected:    0,
+		},
+		{
+			description: "no networking coefficient",
+			metric:      v1.Metric{UnitAmount: 500},
+			err:         "error: cannot calculate network energy, no networking coefficient found",
+		},
+	}
+
+	for _, test := range tt {
+		p := parameters{
+			pue:       1,
+			gridCO2e:  1000,
+			networkKW: test.networkKW,
+			destinations: map[string]float64{
+				v1.DestinationInternet: 0.002,
+			},
+			metric: test.metric,
+		}
+		// the GB are a total, they aren't prorated over the interval
+		emissions, err := network(ctx, time.Minute, &p)
+		if test.err != "" {
+			assert.EqualError(err, test.err, test.description)
+			continue
+		}
+		assert.NoError(err, test.description)
+		assert.InDelta(test.expected, emissions, 1e-9, test.description)
+	}
+
+	// the flows are named after their destination
+	p := parameters{
+		pue:       1,
+		gridCO2e:  1000,
+		networkKW: 0.001,
+		metric: v1.Metric{
+			Name:         "network-egress-intra-region",
+			ResourceType: v1.Network,
+			UnitAmount:   1,
+		},
+	}
+	emissions, err := operationalEmissions(ctx, time.Minute, &p)
+	assert.NoError(err)
+	assert.InDelta(1, emissions, 1e-9)
+}
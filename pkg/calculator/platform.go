@@ -0,0 +1,103 @@
+package calculator
+
+import (
+	"slices"
+	"strings"
+
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// The architectures of the emission factors named after the generations
+// of the CPUs instead of their codenames
+var platformAliases = map[string]string{
+	"naples": "EPYC 1st Gen",
+	"rome":   "EPYC 2nd Gen",
+	"milan":  "EPYC 3rd Gen",
+	"genoa":  "EPYC 4th Gen",
+	"turin":  "EPYC 5th Gen",
+}
+
+// The architectures of every vendor, the oldest first, used to pick the
+// closest one of the emission factors when a CPU platform isn't in them
+var platformGenerations = [][]string{
+	{"Sandy Bridge", "Ivy Bridge", "Haswell", "Broadwell", "Skylake", "Cascade Lake", "Ice Lake", "Sapphire Rapids", "Emerald Rapids", "Granite Rapids"},
+	{"EPYC 1st Gen", "EPYC 2nd Gen", "EPYC 3rd Gen", "EPYC 4th Gen", "EPYC 5th Gen"},
+	{"Graviton", "Graviton2", "Graviton3", "Graviton4"},
+}
+
+// platformSpecs returns the specs of the architecture of the CPU platform
+// reported by the provider, e.g. Cascade Lake for Intel Cascade Lake or
+// EPYC 3rd Gen for AMD Milan. The platform of the instances can change, e.g.
+// the E2 instances of GCP are migrated across platforms, so it's the one
+// reported at the last refresh. When the architecture isn't in the emission
+// factors, the closest generation of the same vendor is used, the older one
+// when two are as close
+func platformSpecs(use factors.MachineSpecsData, cpuPlatform string) (factors.MachineSpecs, bool) {
+	if cpuPlatform == "" {
+		return factors.MachineSpecs{}, false
+	}
+
+	names := []string{cpuPlatform}
+
+	// without the vendor
+	if _, name, ok := strings.Cut(cpuPlatform, " "); ok {
+		names = append(names, name)
+		if alias, ok := platformAliases[strings.ToLower(name)]; ok {
+			names = append(names, alias)
+		}
+	}
+
+	for _, name := range names {
+		if specs, ok := architectureSpecs(use, name); ok {
+			return specs, true
+		}
+	}
+
+	for _, name := range names {
+		if specs, ok := closestSpecs(use, name); ok {
+			return specs, true
+		}
+	}
+
+	return factors.MachineSpecs{}, false
+}
+
+// architectureSpecs returns the specs of an architecture, the names are
+// case insensitive
+func architectureSpecs(use factors.MachineSpecsData, architecture string) (factors.MachineSpecs, bool) {
+	for name, specs := range use {
+		if strings.EqualFold(name, architecture) {
+			return specs, true
+		}
+	}
+
+	return factors.MachineSpecs{}, false
+}
+
+// closestSpecs returns the specs of the closest generation of the
+// architecture in the emission factors
+func closestSpecs(use factors.MachineSpecsData, architecture string) (factors.MachineSpecs, bool) {
+	for _, generations := range platformGenerations {
+		i := slices.IndexFunc(generations, func(g string) bool {
+			return strings.EqualFold(g, architecture)
+		})
+		if i < 0 {
+			continue
+		}
+
+		for d := 1; d < len(generations); d++ {
+			if i-d >= 0 {
+				if specs, ok := architectureSpecs(use, generations[i-d]); ok {
+					return specs, true
+				}
+			}
+			if i+d < len(generations) {
+				if specs, ok := architectureSpecs(use, generations[i+d]); ok {
+					return specs, true
+				}
+			}
+		}
+	}
+
+	return factors.MachineSpecs{}, false
+}
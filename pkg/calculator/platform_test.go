@@ -0,0 +1,46 @@
+package calculator
+
+import (
+	"testing"
+
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlatformSpecs(t *testing.T) {
+	use := factors.MachineSpecsData{
+		"Cascade Lake":    {Architecture: "Cascade Lake", MinWatts: 0.64, MaxWatts: 3.97},
+		"EPYC 3rd Gen":    {Architecture: "EPYC 3rd Gen", MinWatts: 0.45, MaxWatts: 2.02},
+		"Graviton2":       {Architecture: "Graviton2", MinWatts: 0.47, MaxWatts: 1.69},
+		"Sapphire Rapids": {Architecture: "Sapphire Rapids", MinWatts: 0.5, MaxWatts: 3.2},
+	}
+
+	tt := []struct {
+		platform     string
+		architecture string
+	}{
+		{platform: "Intel Cascade Lake", architecture: "Cascade Lake"},
+		{platform: "cascade lake", architecture: "Cascade Lake"},
+		{platform: "AMD Milan", architecture: "EPYC 3rd Gen"},
+		{platform: "AWS Graviton2", architecture: "Graviton2"},
+		// the closest generation, the older one of two as close
+		{platform: "Intel Ice Lake", architecture: "Cascade Lake"},
+		{platform: "Intel Emerald Rapids", architecture: "Sapphire Rapids"},
+		{platform: "AMD Genoa", architecture: "EPYC 3rd Gen"},
+		{platform: "Intel Broadwell", architecture: "Cascade Lake"},
+		{platform: "AWS Graviton3", architecture: "Graviton2"},
+		{platform: "Ampere Altra"},
+		{platform: "Unknown CPU Platform"},
+		{platform: ""},
+	}
+
+	for _, test := range tt {
+		t.Run(test.platform, func(t *testing.T) {
+			assert := require.New(t)
+
+			specs, ok := platformSpecs(use, test.platform)
+			assert.Equal(test.architecture != "", ok)
+			assert.Equal(test.architecture, specs.Architecture)
+		})
+	}
+}
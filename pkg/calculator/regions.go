@@ -0,0 +1,218 @@
+package calculator
+
+import (
+	"sort"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The uplifts of the embodied emissions overridden by the config, besides
+// the PUE
+const (
+	FactorNetworkingEmbodied = "networking-embodied"
+	FactorStorageEmbodied    = "storage-embodied"
+)
+
+// The sources of the PUE: the emission factors, or the config when an
+// override doesn't set one
+const (
+	PUESourceFactors = "emission-factors"
+	PUESourceConfig  = "config"
+)
+
+// Override is a factor of the emission factors overridden by the config
+type Override struct {
+	Provider v1.Provider `json:"provider"`
+
+	// The region, empty for all the regions of the provider
+	Region string `json:"region,omitempty"`
+
+	// The factor overridden, e.g. pue
+	Factor string  `json:"factor"`
+	Value  float64 `json:"value"`
+
+	// Where the value comes from, e.g. the contract it's taken from
+	Source string `json:"source"`
+}
+
+// Locations are the on-premises sites and the custom regions of the
+// providers, and the factors of the emission factors overridden by the
+// config. They're read only, the ones of a reloaded config are new ones. The
+// nil Locations have none of them, the calculations use the emission factors
+// only
+type Locations struct {
+	// The grid intensity of the sites in gCO2eq/kWh, by provider and site
+	sites map[v1.Provider]map[string]float64
+
+	// The custom regions, by provider and region
+	regions map[v1.Provider]map[string]config.RegionConfig
+
+	// The PUE of all the regions of the providers
+	pue map[v1.Provider]Override
+
+	// The uplifts of the embodied emissions of the servers of the
+	// providers, by factor
+	overhead map[v1.Provider][]Override
+
+	// The averages the grid intensity of the regions missing from the
+	// emission factors falls back on, in order
+	gridFallback []string
+}
+
+// NewLocations returns the on-premises sites and the custom regions of
+// every provider of the config
+func NewLocations(cfg *config.ApplicationConfig) *Locations {
+	l := &Locations{
+		sites:        make(map[v1.Provider]map[string]float64, len(cfg.Providers)),
+		regions:      make(map[v1.Provider]map[string]config.RegionConfig, len(cfg.Providers)),
+		pue:          make(map[v1.Provider]Override),
+		overhead:     make(map[v1.Provider][]Override),
+		gridFallback: cfg.Factors.GridFallback,
+	}
+	for provider, p := range cfg.Providers {
+		if len(p.Sites) > 0 {
+			l.sites[provider] = p.Sites
+		}
+		if len(p.Regions) > 0 {
+			l.regions[provider] = p.Regions
+		}
+		if p.PUE > 0 {
+			l.pue[provider] = Override{
+				Provider: provider,
+				Factor:   FactorPUE,
+				Value:    p.PUE,
+				Source:   pueSource(p.PUESource),
+			}
+		}
+		for factor, uplift := range map[string]float64{
+			FactorNetworkingEmbodied: p.EmbodiedOverhead.Networking,
+			FactorStorageEmbodied:    p.EmbodiedOverhead.Storage,
+		} {
+			if uplift > 0 {
+				l.overhead[provider] = append(l.overhead[provider], Override{
+					Provider: provider,
+					Factor:   factor,
+					Value:    uplift,
+					Source:   pueSource(p.EmbodiedOverhead.Source),
+				})
+			}
+		}
+	}
+
+	return l
+}
+
+// pueSource returns the source of a PUE, or of another factor, set by the
+// config
+func pueSource(source string) string {
+	if source == "" {
+		return PUESourceConfig
+	}
+	return source
+}
+
+// Overrides returns the factors of the emission factors overridden by the
+// config, by provider and region
+func (l *Locations) Overrides() []Override {
+	if l == nil {
+		return nil
+	}
+
+	var overrides []Override
+	for _, o := range l.pue {
+		overrides = append(overrides, o)
+	}
+	for _, o := range l.overhead {
+		overrides = append(overrides, o...)
+	}
+	for provider, regions := range l.regions {
+		for region, r := range regions {
+			if r.PUE > 0 {
+				overrides = append(overrides, Override{
+					Provider: provider,
+					Region:   region,
+					Factor:   FactorPUE,
+					Value:    r.PUE,
+					Source:   pueSource(r.PUESource),
+				})
+			}
+		}
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Provider != overrides[j].Provider {
+			return overrides[i].Provider < overrides[j].Provider
+		}
+		if overrides[i].Region != overrides[j].Region {
+			return overrides[i].Region < overrides[j].Region
+		}
+		return overrides[i].Factor < overrides[j].Factor
+	})
+
+	return overrides
+}
+
+// siteIntensity returns the configured grid intensity of the site the
+// instance runs on, false when it doesn't run on a site or the site isn't
+// configured
+func (l *Locations) siteIntensity(instance *v1.Instance) (float64, bool) {
+	site := instance.Labels[v1.SiteLabel]
+	if l == nil || site == "" {
+		return 0, false
+	}
+
+	gridCO2e, ok := l.sites[instance.Provider][site]
+	return gridCO2e, ok
+}
+
+// customRegion returns the custom region of the provider, false when the
+// region isn't configured
+func (l *Locations) customRegion(provider v1.Provider, region string) (config.RegionConfig, bool) {
+	if l == nil {
+		return config.RegionConfig{}, false
+	}
+
+	r, ok := l.regions[provider][region]
+	return r, ok
+}
+
+// regionParameters sets the PUE and the embodied overhead of the provider
+// and the PUE and the lifespan of the servers of the custom region, the ones
+// they don't set are left as they are. The PUE is recorded along with its
+// source
+func (l *Locations) regionParameters(p *parameters, provider v1.Provider, region string) {
+	p.pueSource = PUESourceFactors
+
+	var overhead []Override
+	if l != nil {
+		if o, ok := l.pue[provider]; ok {
+			p.pue = o.Value
+			p.pueSource = o.Source
+		}
+		overhead = l.overhead[provider]
+	}
+
+	// the shared networking and storage infrastructure is an uplift of the
+	// embodied emissions of the servers
+	p.embodiedOverhead = 0
+	for _, o := range overhead {
+		p.embodiedOverhead += o.Value
+	}
+	p.embodiedFactor *= 1 + p.embodiedOverhead/100
+
+	r, ok := l.customRegion(provider, region)
+	if !ok {
+		return
+	}
+
+	if r.PUE > 0 {
+		p.pue = r.PUE
+		p.pueSource = pueSource(r.PUESource)
+	}
+
+	// the hourly embodied emissions are spread over the lifespan
+	if r.Lifespan > 0 {
+		p.embodiedFactor *= serverLifespan / r.Lifespan
+	}
+}
@@ -0,0 +1,163 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteIntensity(t *testing.T) {
+	assert := require.New(t)
+
+	l := NewLocations(&config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {Sites: map[string]float64{"op-0123": 50}},
+		},
+	})
+
+	outpost := v1.Instance{Provider: v1.AWS, Labels: v1.Labels{v1.SiteLabel: "op-0123"}}
+	gridCO2e, ok := l.siteIntensity(&outpost)
+	assert.True(ok)
+	assert.Equal(50.0, gridCO2e)
+
+	// the sites not configured use the grid intensity of their region
+	other := v1.Instance{Provider: v1.AWS, Labels: v1.Labels{v1.SiteLabel: "op-0456"}}
+	_, ok = l.siteIntensity(&other)
+	assert.False(ok)
+
+	_, ok = l.siteIntensity(&v1.Instance{Provider: v1.AWS})
+	assert.False(ok)
+}
+
+func TestCustomRegion(t *testing.T) {
+	assert := require.New(t)
+
+	l := NewLocations(&config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {Regions: map[string]config.RegionConfig{
+				"edge-paris-1": {GridIntensity: 60, PUE: 1.5, Lifespan: 3},
+				"eu-west-3":    {PUE: 1.1},
+			}},
+		},
+	})
+
+	emFactors := &factors.EmissionFactors{
+		Provider:    v1.AWS,
+		Coefficient: factors.CoefficientData{"eu-west-3": 0.00005},
+	}
+
+	// the custom regions are looked up like the ones of the factors
+	gridCO2e, err := l.gridIntensity(emFactors, "edge-paris-1")
+	assert.NoError(err)
+	assert.Equal(60.0, gridCO2e)
+
+	p := parameters{pue: 1.2, embodiedFactor: 10}
+	l.regionParameters(&p, v1.AWS, "edge-paris-1")
+	assert.Equal(1.5, p.pue)
+	assert.Equal(20.0, p.embodiedFactor)
+
+	// the values not set are the ones of the factors
+	gridCO2e, err = l.gridIntensity(emFactors, "eu-west-3")
+	assert.NoError(err)
+	assert.InDelta(50, gridCO2e, 1e-9)
+
+	p = parameters{pue: 1.2, embodiedFactor: 10}
+	l.regionParameters(&p, v1.AWS, "eu-west-3")
+	assert.Equal(1.1, p.pue)
+	assert.Equal(10.0, p.embodiedFactor)
+
+	// the other providers don't have the region
+	_, err = l.gridIntensity(&factors.EmissionFactors{Provider: v1.GCP}, "edge-paris-1")
+	assert.ErrorIs(err, ErrUnknownRegion)
+}
+
+func TestPUEOverrides(t *testing.T) {
+	assert := require.New(t)
+
+	l := NewLocations(&config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {
+				PUE:       1.15,
+				PUESource: "AWS contract 2024",
+				Regions: map[string]config.RegionConfig{
+					"eu-west-3":    {PUE: 1.1, PUESource: "colocation SLA"},
+					"edge-paris-1": {PUE: 1.5},
+					"eu-north-1":   {Lifespan: 5},
+				},
+			},
+		},
+	})
+
+	for _, test := range []struct {
+		provider v1.Provider
+		region   string
+		pue      float64
+		source   string
+	}{
+		// the PUE of the region takes precedence
+		{provider: v1.AWS, region: "eu-west-3", pue: 1.1, source: "colocation SLA"},
+		{provider: v1.AWS, region: "edge-paris-1", pue: 1.5, source: PUESourceConfig},
+		// the PUE of the provider
+		{provider: v1.AWS, region: "eu-north-1", pue: 1.15, source: "AWS contract 2024"},
+		{provider: v1.AWS, region: "us-east-1", pue: 1.15, source: "AWS contract 2024"},
+		// the one of the emission factors
+		{provider: v1.GCP, region: "europe-west1", pue: 1.2, source: PUESourceFactors},
+	} {
+		p := parameters{pue: 1.2}
+		l.regionParameters(&p, test.provider, test.region)
+		assert.Equal(test.pue, p.pue, test.region)
+		assert.Equal(test.source, p.pueSource, test.region)
+	}
+
+	assert.Equal([]Override{
+		{Provider: v1.AWS, Factor: "pue", Value: 1.15, Source: "AWS contract 2024"},
+		{Provider: v1.AWS, Region: "edge-paris-1", Factor: "pue", Value: 1.5, Source: PUESourceConfig},
+		{Provider: v1.AWS, Region: "eu-west-3", Factor: "pue", Value: 1.1, Source: "colocation SLA"},
+	}, l.Overrides())
+}
+
+func TestEmbodiedOverhead(t *testing.T) {
+	assert := require.New(t)
+
+	l := NewLocations(&config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {
+				EmbodiedOverhead: config.EmbodiedOverheadConfig{Networking: 5, Storage: 3, Source: "data center LCA 2024"},
+				Regions: map[string]config.RegionConfig{
+					"edge-paris-1": {Lifespan: 3},
+				},
+			},
+			// only the networking
+			v1.GCP: {EmbodiedOverhead: config.EmbodiedOverheadConfig{Networking: 4}},
+		},
+	})
+
+	p := parameters{embodiedFactor: 10}
+	l.regionParameters(&p, v1.AWS, "us-east-1")
+	assert.Equal(8.0, p.embodiedOverhead)
+	assert.InDelta(10.8, p.embodiedFactor, 1e-9)
+
+	// spread over the lifespan of the custom region
+	p = parameters{embodiedFactor: 10}
+	l.regionParameters(&p, v1.AWS, "edge-paris-1")
+	assert.InDelta(21.6, p.embodiedFactor, 1e-9)
+
+	p = parameters{embodiedFactor: 10}
+	l.regionParameters(&p, v1.GCP, "europe-west1")
+	assert.InDelta(10.4, p.embodiedFactor, 1e-9)
+
+	// none when not set
+	p = parameters{embodiedFactor: 10}
+	l.regionParameters(&p, v1.Azure, "westeurope")
+	assert.Zero(p.embodiedOverhead)
+	assert.Equal(10.0, p.embodiedFactor)
+
+	assert.Equal([]Override{
+		{Provider: v1.AWS, Factor: FactorNetworkingEmbodied, Value: 5, Source: "data center LCA 2024"},
+		{Provider: v1.AWS, Factor: FactorStorageEmbodied, Value: 3, Source: "data center LCA 2024"},
+		{Provider: v1.GCP, Factor: FactorNetworkingEmbodied, Value: 4, Source: PUESourceConfig},
+	}, l.Overrides())
+}
@@ -0,0 +1,94 @@
+package calculator
+
+import (
+	"sync"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+)
+
+// emissionFactors are the emission factors used by the calculations
+var emissionFactors = &repository{}
+
+// repository keeps the emission factors of the providers. The factors of a
+// provider are read from factors.DataPath the first time they're used, and
+// again when the data path changes or the factors are refreshed. It's safe
+// for concurrent use, the factors it returns must not be modified
+type repository struct {
+	// The data path the factors of the providers were read from
+	dataPath  string
+	providers map[v1.Provider]*factors.EmissionFactors
+
+	// The instance types of the v2 AWS dataset
+	instances *instanceIndex
+
+	mu sync.RWMutex
+}
+
+// provider returns the emission factors of the provider, read on first use
+func (r *repository) provider(provider v1.Provider) (*factors.EmissionFactors, error) {
+	dataPath := factors.DataPath
+
+	r.mu.RLock()
+	ef, ok := r.providers[provider]
+	if r.dataPath != dataPath {
+		ok = false
+	}
+	r.mu.RUnlock()
+
+	if ok {
+		return ef, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the factors of another data path are stale
+	if r.dataPath != dataPath || r.providers == nil {
+		r.dataPath = dataPath
+		r.providers = make(map[v1.Provider]*factors.EmissionFactors)
+	}
+
+	// read by another calculation in the meantime
+	if ef, ok := r.providers[provider]; ok {
+		return ef, nil
+	}
+
+	ef, err := factors.GetProviderEmissionFactors(provider, dataPath)
+	if err != nil {
+		return nil, err
+	}
+	r.providers[provider] = ef
+
+	return ef, nil
+}
+
+// instance returns the instance type of the v2 AWS dataset
+func (r *repository) instance(kind string) (data.Instance, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.instances.instance(kind)
+}
+
+// reset forgets the factors read so far, they're read again on their next
+// use, e.g. once the emissions-data repo is pulled
+func (r *repository) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers = nil
+}
+
+// swapInstances replaces the instance types of the v2 AWS dataset and
+// returns the previous ones
+func (r *repository) swapInstances(instances *instanceIndex) *instanceIndex {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.instances
+	r.instances = instances
+
+	return previous
+}
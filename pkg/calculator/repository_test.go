@@ -0,0 +1,113 @@
+package calculator
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	data "github.com/re-cinq/emissions-data/pkg/types/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFactors writes the emission factors of the test provider with the
+// grid intensity of the north region to the directory
+func writeFactors(t *testing.T, dir string, co2e string) {
+	for name, content := range map[string]string{
+		"test-default.yaml":  "name: test\naveragePUE: 1.5\n",
+		"test-grid.yaml":     "- region: north\n  co2e: " + co2e + "\n",
+		"test-use.yaml":      "- architecture: A\n  minwatts: 10\n  maxwatts: 20\n",
+		"test-embodied.yaml": "- type: t-2\n  total: 315360\n  vCPU: 2\n  totalVCPU: 4\n  architecture: A\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+}
+
+func TestRepository(t *testing.T) {
+	assert := require.New(t)
+
+	dataPath := factors.DataPath
+	t.Cleanup(func() { factors.DataPath = dataPath })
+
+	first, second := t.TempDir(), t.TempDir()
+	writeFactors(t, first, "0.0001")
+	writeFactors(t, second, "0.0004")
+	factors.DataPath = first
+
+	r := &repository{}
+
+	ef, err := r.provider("test")
+	assert.NoError(err)
+	assert.Equal(0.0001, ef.Coefficient["north"])
+
+	// read once
+	writeFactors(t, first, "0.0002")
+	ef, err = r.provider("test")
+	assert.NoError(err)
+	assert.Equal(0.0001, ef.Coefficient["north"])
+
+	// and again once reset
+	r.reset()
+	ef, err = r.provider("test")
+	assert.NoError(err)
+	assert.Equal(0.0002, ef.Coefficient["north"])
+
+	// or when the data path changes
+	factors.DataPath = second
+	ef, err = r.provider("test")
+	assert.NoError(err)
+	assert.Equal(0.0004, ef.Coefficient["north"])
+
+	// the failures aren't kept
+	_, err = r.provider("missing")
+	assert.Error(err)
+	for _, f := range []string{"default", "grid", "use", "embodied"} {
+		content, err := os.ReadFile(filepath.Join(second, "test-"+f+".yaml"))
+		assert.NoError(err)
+		assert.NoError(os.WriteFile(filepath.Join(second, "missing-"+f+".yaml"), content, 0o600))
+	}
+	_, err = r.provider("missing")
+	assert.NoError(err)
+
+	// the instance types are swapped
+	_, ok := r.instance("m5.large")
+	assert.False(ok)
+	assert.Nil(r.swapInstances(instancesOf(map[string]data.Instance{"m5.large": {VCPU: 2}})))
+	d, ok := r.instance("m5.large")
+	assert.True(ok)
+	assert.Equal(2, d.VCPU)
+}
+
+// TestRepositoryConcurrency reads the factors while they're refreshed, it
+// fails with -race if they aren't safe for concurrent use
+func TestRepositoryConcurrency(t *testing.T) {
+	dataPath := factors.DataPath
+	t.Cleanup(func() { factors.DataPath = dataPath })
+
+	factors.DataPath = t.TempDir()
+	writeFactors(t, factors.DataPath, "0.0001")
+
+	r := &repository{}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if i%4 == 0 {
+					r.reset()
+					r.swapInstances(instancesOf(map[string]data.Instance{}))
+					continue
+				}
+
+				_, err := r.provider(v1.Provider("test"))
+				require.NoError(t, err)
+				r.instance("t-2")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,120 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// diskFactors is the power of the capacity of a disk media and the energy of
+// its I/O
+type diskFactors struct {
+	// Watts per TB, whatever the activity
+	watts float64
+
+	// Joules per read or write operation, and per GB read or written
+	ioJoules float64
+	gbJoules float64
+}
+
+// The energy of the I/O of the disk media when the emission factors don't
+// have it. A read or write costs the seek of the heads of an HDD, so an HDD
+// draws far more per operation than an SSD, and a bit more per GB transferred
+var defaultDiskIO = map[string]diskFactors{
+	v1.MediaSSD: {ioJoules: 0.00002, gbJoules: 3},
+	v1.MediaHDD: {ioJoules: 0.015, gbJoules: 15},
+}
+
+// storageParameters sets the factors of the disk media from the defaults of
+// the provider, the energy of the I/O they don't have is the default one
+func storageParameters(p *parameters, emFactors *factors.EmissionFactors) {
+	p.disks = map[string]diskFactors{
+		v1.MediaSSD: {
+			watts:    emFactors.SSDStorageWatts,
+			ioJoules: emFactors.SSDIOJoules,
+			gbJoules: emFactors.SSDGBJoules,
+		},
+		v1.MediaHDD: {
+			watts:    emFactors.HDDStorageWatts,
+			ioJoules: emFactors.HDDIOJoules,
+			gbJoules: emFactors.HDDGBJoules,
+		},
+	}
+
+	for media, f := range p.disks {
+		if f.ioJoules == 0 {
+			f.ioJoules = defaultDiskIO[media].ioJoules
+		}
+		if f.gbJoules == 0 {
+			f.gbJoules = defaultDiskIO[media].gbJoules
+		}
+		p.disks[media] = f
+	}
+}
+
+// storage calculates the CO2e operational emissions of a disk of a Cloud VM
+// instance over an interval of time.
+//
+// The capacity of the disk draws power whatever its activity, in watts per TB
+// of its media. The I/O of the disk adds the energy of its operations and of
+// the bytes it read and wrote, so that a busy database isn't accounted for
+// as a cold archive of the same size
+func storage(ctx context.Context, interval time.Duration, p *parameters) (float64, error) {
+	media := p.metric.Labels[v1.MediaLabel]
+	if media != v1.MediaHDD {
+		media = v1.MediaSSD
+	}
+	f := p.disks[media]
+
+	if p.metric.UnitAmount == 0 && p.metric.IOPS == 0 && p.metric.Throughput == 0 {
+		return 0, errors.New("error disk size set to 0")
+	}
+
+	tb := p.metric.UnitAmount / 1000
+	p.steps = append(p.steps, Step{
+		Description: "capacity of the disk in TB",
+		Formula:     formula("%g GB / 1000", p.metric.UnitAmount),
+		Value:       tb,
+	})
+
+	capacitykW := tb * f.watts / 1000
+	p.steps = append(p.steps, Step{
+		Description: fmt.Sprintf("capacity power in kW of the %s", media),
+		Formula:     formula("%g TB * %g W/TB / 1000", tb, f.watts),
+		Value:       capacitykW,
+	})
+
+	hours := interval.Minutes() / float64(60)
+	p.steps = append(p.steps, Step{
+		Description: "hours over the interval",
+		Formula:     formula("%g min / 60", interval.Minutes()),
+		Value:       hours,
+	})
+
+	// a kWh is 3.6 million joules
+	iokWh := (p.metric.IOPS*f.ioJoules + p.metric.Throughput/1e9*f.gbJoules) * interval.Seconds() / 3.6e6
+	p.steps = append(p.steps, Step{
+		Description: "I/O energy in kWh over the interval",
+		Formula: formula("(%g IOPS * %g J + %g GB/s * %g J/GB) * %g s / 3600000",
+			p.metric.IOPS, f.ioJoules, p.metric.Throughput/1e9, f.gbJoules, interval.Seconds()),
+		Value: iokWh,
+	})
+
+	if logger := log.FromContext(ctx); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf("storage calculation: %+v, %+v, %+v, %+v, %+v", capacitykW, hours, iokWh, p.pue, p.gridCO2e))
+	}
+	emissions := (capacitykW*hours + iokWh) * p.pue * p.gridCO2e
+	p.steps = append(p.steps, Step{
+		Description: "operational emissions in gCO2eq",
+		Formula:     formula("(%g kW * %g h + %g kWh) * %g PUE * %g gCO2eq/kWh", capacitykW, hours, iokWh, p.pue, p.gridCO2e),
+		Value:       emissions,
+	})
+
+	return emissions, nil
+}
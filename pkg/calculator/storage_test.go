@@ -0,0 +1,90 @@
+package calculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageParameters(t *testing.T) {
+	assert := require.New(t)
+
+	p := parameters{}
+	storageParameters(&p, &factors.EmissionFactors{
+		ProviderDefaults: &factors.ProviderDefaults{
+			HDDStorageWatts: 0.65,
+			SSDStorageWatts: 1.2,
+			SSDIOJoules:     0.0001,
+		},
+	})
+
+	// the energy of the I/O missing from the emission factors is the default
+	assert.Equal(diskFactors{watts: 1.2, ioJoules: 0.0001, gbJoules: 3}, p.disks[v1.MediaSSD])
+	assert.Equal(diskFactors{watts: 0.65, ioJoules: 0.015, gbJoules: 15}, p.disks[v1.MediaHDD])
+}
+
+func TestStorage(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	disks := map[string]diskFactors{
+		v1.MediaSSD: {watts: 1, ioJoules: 0.0001, gbJoules: 4},
+		v1.MediaHDD: {watts: 0.5, ioJoules: 0.01, gbJoules: 10},
+	}
+
+	type testcase struct {
+		description string
+		metric      v1.Metric
+		expected    float64
+		err         string
+	}
+	tt := []testcase{
+		{
+			description: "an idle SSD only draws the power of its capacity",
+			metric:      v1.Metric{UnitAmount: 1000},
+			// 1 W over an hour
+			expected: 1,
+		},
+		{
+			description: "an idle HDD",
+			metric:      v1.Metric{UnitAmount: 1000, Labels: v1.Labels{v1.MediaLabel: v1.MediaHDD}},
+			expected:    0.5,
+		},
+		{
+			description: "the I/O adds the energy of the operations and of the bytes",
+			metric: v1.Metric{
+				UnitAmount: 1000,
+				IOPS:       100,
+				Throughput: 1e8,
+				Labels:     v1.Labels{v1.MediaLabel: v1.MediaHDD},
+			},
+			// 0.5 W + 100 * 0.01 J/s + 0.1 GB/s * 10 J/GB
+			expected: 2.5,
+		},
+		{
+			description: "no disk",
+			metric:      v1.Metric{},
+			err:         "error disk size set to 0",
+		},
+	}
+
+	for _, test := range tt {
+		p := parameters{
+			pue:      1,
+			gridCO2e: 1000,
+			disks:    disks,
+			metric:   test.metric,
+		}
+		emissions, err := storage(ctx, time.Hour, &p)
+		if test.err != "" {
+			assert.EqualError(err, test.err, test.description)
+			continue
+		}
+		assert.NoError(err, test.description)
+		assert.InDelta(test.expected, emissions, 1e-9, test.description)
+	}
+}
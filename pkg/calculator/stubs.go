@@ -0,0 +1,79 @@
+package calculator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// WriteEmbodiedStubs writes the entries of the missing instance kinds to add
+// to the {provider}-embodied.yaml files of the emissions data repo, with the
+// vCPUs collected from the provider. The embodied emissions are left to the
+// contributor, they are marked TODO
+func WriteEmbodiedStubs(w io.Writer, misses []Miss) error {
+	kinds := make([]Miss, 0, len(misses))
+	for _, m := range misses {
+		if m.Type == MissKind && m.Value != "" {
+			kinds = append(kinds, m)
+		}
+	}
+
+	sort.Slice(kinds, func(i, j int) bool {
+		if kinds[i].Provider != kinds[j].Provider {
+			return kinds[i].Provider < kinds[j].Provider
+		}
+		return kinds[i].Value < kinds[j].Value
+	})
+
+	out := bufio.NewWriter(w)
+
+	var provider v1.Provider
+	for i, k := range kinds {
+		if i == 0 || k.Provider != provider {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			provider = k.Provider
+			fmt.Fprintf(out, "# data/v1/%s-embodied.yaml\n", provider)
+		}
+
+		fmt.Fprintf(out, "# %s\n", describeKind(&k))
+		fmt.Fprintf(out, "- type: %s\n", strconv.Quote(k.Value))
+		if k.VCPU > 0 {
+			fmt.Fprintf(out, "  vCPU: %g\n", k.VCPU)
+			fmt.Fprintf(out, "  totalVCPU: %g # TODO: the vCPUs of the largest kind of the family\n", k.VCPU)
+		} else {
+			fmt.Fprintln(out, "  vCPU: 0 # TODO")
+			fmt.Fprintln(out, "  totalVCPU: 0 # TODO: the vCPUs of the largest kind of the family")
+		}
+		fmt.Fprintln(out, "  additionalmemory: 0 # TODO")
+		fmt.Fprintln(out, "  additionalstorage: 0 # TODO")
+		fmt.Fprintln(out, "  additionalcpus: 0 # TODO")
+		fmt.Fprintln(out, "  additionalgpus: 0 # TODO")
+		fmt.Fprintln(out, "  total: 0 # TODO: 1000 kgCO2eq of the base server and the additional emissions")
+		fmt.Fprintf(out, "  architecture: \"\" # TODO: an architecture of %s-use.yaml, the defaults of the provider when empty\n", k.Provider)
+	}
+
+	return out.Flush()
+}
+
+// describeKind returns what is known of the instances of the kind, e.g.
+// 4 vCPU, 16 GB of memory, Intel Cascade Lake, seen 12 times on i-123
+func describeKind(k *Miss) string {
+	var s string
+	if k.VCPU > 0 {
+		s += fmt.Sprintf("%g vCPU, ", k.VCPU)
+	}
+	if k.MemoryGB > 0 {
+		s += fmt.Sprintf("%g GB of memory, ", k.MemoryGB)
+	}
+	if k.Architecture != "" {
+		s += k.Architecture + ", "
+	}
+
+	return s + fmt.Sprintf("seen %d times on %s", k.Count, k.Instance)
+}
@@ -0,0 +1,48 @@
+package calculator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-yaml/yaml"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEmbodiedStubs(t *testing.T) {
+	assert := require.New(t)
+
+	var out strings.Builder
+	assert.NoError(WriteEmbodiedStubs(&out, []Miss{
+		{Provider: v1.GCP, Type: MissKind, Value: "n4-standard-4", Count: 3, Instance: "web-1", VCPU: 4, MemoryGB: 16, Architecture: "Intel Emerald Rapids"},
+		{Provider: v1.AWS, Type: MissRegion, Value: "moon-base1", Count: 1, Instance: "i-3"},
+		{Provider: v1.AWS, Type: MissKind, Value: "x9.metal", Count: 2, Instance: "i-2"},
+		{Provider: v1.AWS, Type: MissKind, Value: "c8g.large", Count: 1, Instance: "i-1", VCPU: 2},
+	}))
+
+	// a file by provider, the kinds sorted
+	files := strings.Split(out.String(), "\n\n")
+	assert.Len(files, 2)
+	assert.True(strings.HasPrefix(files[0], "# data/v1/aws-embodied.yaml\n# 2 vCPU, seen 1 times on i-1\n- type: \"c8g.large\"\n"))
+	assert.Contains(files[1], "# 4 vCPU, 16 GB of memory, Intel Emerald Rapids, seen 3 times on web-1\n")
+
+	// the entries are read like the ones of the emission factors
+	var aws []factors.Embodied
+	assert.NoError(yaml.Unmarshal([]byte(files[0]), &aws))
+	assert.Len(aws, 2)
+	assert.Equal("c8g.large", aws[0].MachineType)
+	assert.InDelta(2, aws[0].VCPU, 1e-9)
+	assert.InDelta(2, aws[0].TotalVCPU, 1e-9)
+	assert.Equal("x9.metal", aws[1].MachineType)
+	assert.Zero(aws[1].VCPU)
+
+	var gcp []factors.Embodied
+	assert.NoError(yaml.Unmarshal([]byte(files[1]), &gcp))
+	assert.Equal("n4-standard-4", gcp[0].MachineType)
+	assert.Empty(gcp[0].Architecture)
+
+	out.Reset()
+	assert.NoError(WriteEmbodiedStubs(&out, nil))
+	assert.Empty(out.String())
+}
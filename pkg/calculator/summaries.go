@@ -0,0 +1,130 @@
+package calculator
+
+import (
+	"fmt"
+	"sync"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// summaries sums up the emissions of the instances of every account until
+// their collection completes. The instances and the completed collections
+// are handled concurrently by the bus, so the summary of a collection is
+// ready once it completed and as many instances as it published were handled
+type summaries struct {
+	// The summaries being summed up, the key is provider/account
+	accounts map[string]*pendingSummary
+	mu       sync.Mutex
+}
+
+// pendingSummary is the summary of the current collection of an account
+type pendingSummary struct {
+	summary v1.AccountSummary
+
+	// The instances handled since the last summary
+	handled int
+
+	// Whether the collection completed, the amount of its instances is then
+	// known
+	completed bool
+}
+
+// summaryKey identifies the account of a provider
+func summaryKey(provider v1.Provider, account string) string {
+	return fmt.Sprintf("%s/%s", provider, account)
+}
+
+// get returns the pending summary of the account, a new one when there's none
+func (s *summaries) get(provider v1.Provider, account string) *pendingSummary {
+	if s.accounts == nil {
+		s.accounts = make(map[string]*pendingSummary)
+	}
+
+	key := summaryKey(provider, account)
+	p, ok := s.accounts[key]
+	if !ok {
+		p = &pendingSummary{
+			summary: v1.AccountSummary{
+				Provider: provider,
+				Account:  account,
+			},
+		}
+		s.accounts[key] = p
+	}
+
+	return p
+}
+
+// add counts an instance handled by the calculator with the breakdown of its
+// emissions, nil when they weren't calculated, and returns the summary of its
+// account when it was the last instance of the completed collection
+func (s *summaries) add(instance *v1.Instance, breakdown *Breakdown, err error) (v1.AccountSummary, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.get(instance.Provider, instance.Labels[v1.AccountLabel])
+	p.handled++
+
+	switch {
+	case err != nil:
+		p.summary.Failed++
+	case breakdown != nil:
+		p.summary.Calculated++
+		for i := range breakdown.Metrics {
+			emissions := breakdown.Metrics[i].Emissions
+			p.summary.Operational += emissions
+
+			// the emissions are the energy times the grid intensity
+			if breakdown.GridCO2e > 0 {
+				p.summary.Energy += emissions / breakdown.GridCO2e
+			}
+		}
+		p.summary.Embodied += breakdown.Embodied.Value
+	}
+
+	return s.ready(p)
+}
+
+// complete records the completed collection and returns the summaries which
+// are ready: the previous collection of the account when some of its
+// instances were never handled, e.g. they failed to be published, and the
+// completed one when all its instances were handled
+func (s *summaries) complete(c *v1.Collection) []v1.AccountSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ready []v1.AccountSummary
+
+	p := s.get(c.Provider, c.Account)
+	if p.completed {
+		ready = append(ready, p.summary)
+		delete(s.accounts, summaryKey(c.Provider, c.Account))
+		p = s.get(c.Provider, c.Account)
+	}
+
+	p.completed = true
+	p.summary.Window = c.Window
+	p.summary.Instances = c.Instances
+	if c.Err != nil {
+		p.summary.Error = c.Err.Error()
+		p.summary.ErrorCode = v1.Code(c.Err)
+	}
+
+	if summary, ok := s.ready(p); ok {
+		ready = append(ready, summary)
+	}
+
+	return ready
+}
+
+// ready returns the summary when all the instances of the completed
+// collection were handled, the next instances of the account are summed up
+// in a new one
+func (s *summaries) ready(p *pendingSummary) (v1.AccountSummary, bool) {
+	if !p.completed || p.handled < p.summary.Instances {
+		return v1.AccountSummary{}, false
+	}
+
+	delete(s.accounts, summaryKey(p.summary.Provider, p.summary.Account))
+	return p.summary, true
+}
@@ -0,0 +1,75 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaries(t *testing.T) {
+	assert := require.New(t)
+
+	var s summaries
+
+	instance := func(account string) *v1.Instance {
+		i := v1.NewInstance("i-1", v1.AWS)
+		i.Labels = v1.Labels{v1.AccountLabel: account}
+		return i
+	}
+	breakdown := &Breakdown{
+		GridCO2e: 400,
+		Metrics:  []MetricBreakdown{{Emissions: 20}, {Emissions: 4}},
+		Embodied: Step{Value: 2},
+	}
+	window := v1.NewWindow(time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), 5*time.Minute)
+
+	// the summary waits for the instances published by the collection
+	_, ok := s.add(instance("prod"), breakdown, nil)
+	assert.False(ok)
+	assert.Empty(s.complete(&v1.Collection{Provider: v1.AWS, Account: "prod", Window: window, Instances: 3}))
+
+	_, ok = s.add(instance("dev"), breakdown, nil)
+	assert.False(ok)
+	_, ok = s.add(instance("prod"), nil, errors.New("failed"))
+	assert.False(ok)
+
+	// the energy is the emissions over the grid intensity
+	summary, ok := s.add(instance("prod"), nil, nil)
+	assert.True(ok)
+	assert.InDelta(0.06, summary.Energy, 1e-9)
+	summary.Energy = 0
+	assert.Equal(v1.AccountSummary{
+		Provider:    v1.AWS,
+		Account:     "prod",
+		Window:      window,
+		Instances:   3,
+		Calculated:  1,
+		Failed:      1,
+		Operational: 24,
+		Embodied:    2,
+	}, summary)
+
+	// the summary is ready when the collection completes after its instances
+	summaries := s.complete(&v1.Collection{Provider: v1.AWS, Account: "dev", Window: window, Instances: 1})
+	assert.Len(summaries, 1)
+	assert.Equal("dev", summaries[0].Account)
+	assert.Equal(1, summaries[0].Calculated)
+
+	// the failed collections are summed up too
+	failed := errors.Join(v1.ErrProviderThrottled, errors.New("rate exceeded"))
+	summaries = s.complete(&v1.Collection{Provider: v1.AWS, Account: "prod", Window: window, Err: failed})
+	assert.Len(summaries, 1)
+	assert.Zero(summaries[0].Instances)
+	assert.Equal(v1.CodeProviderThrottled, summaries[0].ErrorCode)
+
+	// the collection whose instances were never all handled is sent with
+	// the next one
+	assert.Empty(s.complete(&v1.Collection{Provider: v1.AWS, Account: "prod", Window: window, Instances: 2}))
+	summaries = s.complete(&v1.Collection{Provider: v1.AWS, Account: "prod", Window: window, Instances: 1})
+	assert.Len(summaries, 1)
+	assert.Equal(2, summaries[0].Instances)
+	assert.Len(s.accounts, 1)
+}
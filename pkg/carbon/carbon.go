@@ -0,0 +1,175 @@
+// Package carbon calculates the emissions of instances with the methodology
+// of the exporter, so that it can be embedded by other services. It needs
+// the emission factors only, no bus, scrapers or config file:
+//
+//	c := carbon.New("/path/to/emissions-data/data/v1")
+//	e, err := c.Calculate(ctx, carbon.Instance{
+//		Provider:    "aws",
+//		Kind:        "m5.xlarge",
+//		Region:      "eu-west-1",
+//		Utilization: 40,
+//	}, time.Hour)
+package carbon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// The errors of the instances missing from the emission factors
+var (
+	ErrUnknownKind   = calculator.ErrUnknownKind
+	ErrUnknownRegion = calculator.ErrUnknownRegion
+)
+
+// Instance is the usage of an instance to calculate the emissions of
+type Instance struct {
+	Provider v1.Provider
+	Name     string
+	Kind     string
+	Region   string
+
+	// The CPU platform the instance runs on, e.g. Intel Cascade Lake,
+	// the architecture of the kind is used when empty
+	CPUPlatform string
+
+	// The vCPUs of the instance, the ones of the kind when zero
+	VCPU float64
+
+	// The CPU utilization in percent
+	Utilization float64
+}
+
+// Emissions are the emissions of an instance in gCO2eq
+type Emissions struct {
+	Operational float64
+	Embodied    float64
+	Total       float64
+
+	// How they were calculated
+	Breakdown *calculator.Breakdown
+}
+
+// Calculator calculates the emissions of instances with the emission
+// factors of a directory. It's safe for concurrent use
+type Calculator struct {
+	// The directory of the emission factors, in the format of the data/v1
+	// directory of the emissions-data repo
+	dataPath string
+
+	// The emission factors of the providers, read on first use
+	providers map[v1.Provider]*factors.EmissionFactors
+
+	// The on-premises sites and the custom regions, none by default
+	locations *calculator.Locations
+
+	mu sync.Mutex
+}
+
+// Option configures the Calculator
+type Option func(*Calculator)
+
+// WithLocations calculates the emissions of the instances of the
+// on-premises sites and the custom regions with their own factors, e.g.
+// the ones of calculator.NewLocations
+func WithLocations(l *calculator.Locations) Option {
+	return func(c *Calculator) {
+		c.locations = l
+	}
+}
+
+// New returns a calculator of the emission factors of the directory
+func New(dataPath string, opts ...Option) *Calculator {
+	c := &Calculator{
+		dataPath:  dataPath,
+		providers: make(map[v1.Provider]*factors.EmissionFactors),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Calculate returns the emissions of the instance over the duration
+func (c *Calculator) Calculate(ctx context.Context, i Instance, d time.Duration) (*Emissions, error) {
+	if d <= 0 {
+		return nil, errors.New("the duration must be positive")
+	}
+	if i.Utilization < 0 || i.Utilization > 100 {
+		return nil, fmt.Errorf("invalid utilization %g, must be between 0 and 100", i.Utilization)
+	}
+
+	emFactors, err := c.factors(i.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	vCPU := i.VCPU
+	if vCPU == 0 {
+		vCPU = emFactors.Embodied[i.Kind].VCPU
+	}
+
+	instance := &v1.Instance{
+		Name:        i.Name,
+		Provider:    i.Provider,
+		Region:      i.Region,
+		Kind:        i.Kind,
+		CPUPlatform: i.CPUPlatform,
+		Metrics: v1.Metrics{
+			v1.CPU.String(): v1.Metric{
+				Name:         v1.CPU.String(),
+				ResourceType: v1.CPU,
+				Usage:        i.Utilization,
+				UnitAmount:   vCPU,
+				Unit:         v1.VCPU,
+			},
+		},
+		Labels: v1.Labels{},
+	}
+
+	breakdown, err := c.locations.CalculateWith(ctx, emFactors, instance, d)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Emissions{
+		Embodied:  breakdown.Embodied.Value,
+		Breakdown: breakdown,
+	}
+	for _, m := range breakdown.Metrics {
+		if m.Error != "" {
+			return nil, fmt.Errorf("failed calculating the %s emissions: %s", m.Name, m.Error)
+		}
+		e.Operational += m.Emissions
+	}
+	e.Total = e.Operational + e.Embodied
+
+	return e, nil
+}
+
+// factors returns the emission factors of the provider, read on first use
+func (c *Calculator) factors(provider v1.Provider) (*factors.EmissionFactors, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ef, ok := c.providers[provider]; ok {
+		return ef, nil
+	}
+
+	ef, err := factors.GetProviderEmissionFactors(provider, c.dataPath)
+	if err != nil {
+		return nil, err
+	}
+	c.providers[provider] = ef
+
+	return ef, nil
+}
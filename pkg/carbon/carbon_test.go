@@ -0,0 +1,131 @@
+package carbon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/fakeprovider"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculate(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(fakeprovider.WriteFactors(dir))
+
+	ctx := context.Background()
+	c := New(dir)
+
+	i := Instance{
+		Provider:    fakeprovider.Provider,
+		Name:        "web",
+		Kind:        fakeprovider.Kind2,
+		Region:      fakeprovider.RegionNorth,
+		Utilization: 50,
+	}
+
+	// 0.015 kW * 2 vCPU * 5/60 h * 1.5 PUE * 100 gCO2eq/kWh and
+	// 1.5 gCO2eq/h per vCPU * 2 vCPU * 5/60 h
+	e, err := c.Calculate(ctx, i, 5*time.Minute)
+	assert.NoError(err)
+	assert.InDelta(0.375, e.Operational, 0.0001)
+	assert.InDelta(0.25, e.Embodied, 0.0001)
+	assert.InDelta(0.625, e.Total, 0.0001)
+	assert.Equal("web", e.Breakdown.Name)
+	assert.Equal(2.0, e.Breakdown.Metrics[0].UnitAmount)
+
+	// the vCPUs of the instance over the ones of the kind
+	i.VCPU = 4
+	e, err = c.Calculate(ctx, i, 5*time.Minute)
+	assert.NoError(err)
+	assert.InDelta(0.75, e.Operational, 0.0001)
+
+	tests := []struct {
+		name   string
+		modify func(i *Instance)
+		d      time.Duration
+		err    error
+	}{
+		{
+			name:   "unknown kind",
+			modify: func(i *Instance) { i.Kind = "fake-64" },
+			d:      time.Hour,
+			err:    ErrUnknownKind,
+		},
+		{
+			name:   "unknown region",
+			modify: func(i *Instance) { i.Region = "fake-east" },
+			d:      time.Hour,
+			err:    ErrUnknownRegion,
+		},
+		{
+			name:   "invalid utilization",
+			modify: func(i *Instance) { i.Utilization = 120 },
+			d:      time.Hour,
+		},
+		{
+			name:   "no duration",
+			modify: func(*Instance) {},
+		},
+		{
+			name:   "unknown provider",
+			modify: func(i *Instance) { i.Provider = "missing" },
+			d:      time.Hour,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			i := i
+			test.modify(&i)
+
+			_, err := c.Calculate(ctx, i, test.d)
+			require.Error(t, err)
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestCalculateLocations(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(fakeprovider.WriteFactors(dir))
+
+	ctx := context.Background()
+	c := New(dir, WithLocations(calculator.NewLocations(&config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			fakeprovider.Provider: {Regions: map[string]config.RegionConfig{
+				"fake-east": {GridIntensity: 200},
+			}},
+		},
+	})))
+
+	// the custom region is looked up like the ones of the factors, with
+	// twice the grid intensity of fake-north
+	e, err := c.Calculate(ctx, Instance{
+		Provider:    fakeprovider.Provider,
+		Kind:        fakeprovider.Kind2,
+		Region:      "fake-east",
+		Utilization: 50,
+	}, 5*time.Minute)
+	assert.NoError(err)
+	assert.InDelta(0.75, e.Operational, 0.0001)
+	assert.Equal(200.0, e.Breakdown.GridCO2e)
+
+	// the calculators without locations don't have it
+	_, err = New(dir).Calculate(ctx, Instance{
+		Provider:    fakeprovider.Provider,
+		Kind:        fakeprovider.Kind2,
+		Region:      "fake-east",
+		Utilization: 50,
+	}, 5*time.Minute)
+	assert.ErrorIs(err, ErrUnknownRegion)
+}
@@ -0,0 +1,182 @@
+// Package cardinality limits the labels and the amount of the series of the
+// exported metrics, so that the estates with many instances don't overwhelm
+// Prometheus
+package cardinality
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/re-cinq/aether/pkg/config"
+)
+
+// What happens to the series past the limit
+const (
+	OverflowDrop = "drop"
+	OverflowHash = "hash"
+)
+
+// The default amount of values of the hashed labels
+const defaultBuckets = 100
+
+// The series dropped or hashed past the limit, by exporter
+var limitedSeries = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "exporter_series_limited_total",
+		Help: "The series of the exported metrics past the limit of their exporter, by action: drop or hash",
+	},
+	[]string{"exporter", "action"},
+)
+
+func init() {
+	prometheus.MustRegister(limitedSeries)
+}
+
+// Limiter filters the labels of the series of an exporter and limits their
+// amount. The series keep their place once exported
+type Limiter struct {
+	name string
+
+	allow map[string]bool
+	deny  map[string]bool
+
+	// The labels always exported, the metric is identified by them
+	fixed map[string]bool
+
+	maxSeries  int
+	overflow   string
+	hashLabels []string
+	buckets    uint32
+
+	// The series exported, by their labels
+	series map[string]bool
+	mu     sync.Mutex
+}
+
+// New returns the limiter of the exporter, the fixed labels are exported
+// whatever the allowed and the denied ones
+func New(name string, cfg *config.CardinalityConfig, fixed ...string) (*Limiter, error) {
+	l := &Limiter{
+		name:       name,
+		allow:      set(cfg.Allow),
+		deny:       set(cfg.Deny),
+		fixed:      set(fixed),
+		maxSeries:  cfg.MaxSeries,
+		overflow:   strings.ToLower(cfg.Overflow),
+		hashLabels: cfg.HashLabels,
+		buckets:    defaultBuckets,
+		series:     make(map[string]bool),
+	}
+
+	if cfg.MaxSeries < 0 {
+		return nil, fmt.Errorf("exporter %s: invalid maxSeries %d", name, cfg.MaxSeries)
+	}
+	if cfg.HashBuckets < 0 {
+		return nil, fmt.Errorf("exporter %s: invalid hashBuckets %d", name, cfg.HashBuckets)
+	}
+	if cfg.HashBuckets > 0 {
+		l.buckets = uint32(cfg.HashBuckets)
+	}
+
+	switch l.overflow {
+	case "":
+		l.overflow = OverflowDrop
+	case OverflowDrop:
+	case OverflowHash:
+		if len(l.hashLabels) == 0 {
+			return nil, fmt.Errorf("exporter %s: the overflow is hash but no labels are hashed", name)
+		}
+	default:
+		return nil, fmt.Errorf("exporter %s: invalid overflow %q, expected drop or hash", name, cfg.Overflow)
+	}
+
+	return l, nil
+}
+
+// Labels returns the labels exported of a series, false when the series is
+// dropped. The labels aren't modified
+func (l *Limiter) Labels(labels map[string]string) (map[string]string, bool) {
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if l.fixed[k] || (len(l.allow) == 0 || l.allow[k]) && !l.deny[k] {
+			out[k] = v
+		}
+	}
+
+	if l.maxSeries == 0 {
+		return out, true
+	}
+
+	key := Key(out)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.series[key] || len(l.series) < l.maxSeries {
+		l.series[key] = true
+		return out, true
+	}
+
+	limitedSeries.WithLabelValues(l.name, l.overflow).Inc()
+
+	if l.overflow == OverflowDrop {
+		return nil, false
+	}
+
+	// the hashed series are bounded by the buckets, they don't take a place
+	for _, name := range l.hashLabels {
+		if v, ok := out[name]; ok {
+			out[name] = l.bucket(v)
+		}
+	}
+
+	return out, true
+}
+
+// Release frees the place of the series of the labels, e.g. once it's no
+// longer exported
+func (l *Limiter) Release(labels map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.series, Key(labels))
+}
+
+// bucket returns the hashed value of a label
+func (l *Limiter) bucket(value string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(value))
+	return "hash_" + strconv.FormatUint(uint64(h.Sum32()%l.buckets), 10)
+}
+
+// Key identifies the series of the labels
+func Key(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, k := range names {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// set returns the set of the values
+func set(values []string) map[string]bool {
+	s := make(map[string]bool, len(values))
+	for _, v := range values {
+		s[v] = true
+	}
+	return s
+}
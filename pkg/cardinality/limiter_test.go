@@ -0,0 +1,118 @@
+package cardinality
+
+import (
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	assert := require.New(t)
+
+	labels := map[string]string{"provider": "aws", "name": "i-1", "region": "eu-west-1", "team": "web"}
+
+	tests := []struct {
+		name     string
+		cfg      config.CardinalityConfig
+		expected map[string]string
+	}{
+		{
+			name:     "all the labels",
+			expected: labels,
+		},
+		{
+			name:     "the allowed labels and the fixed ones",
+			cfg:      config.CardinalityConfig{Allow: []string{"team", "region"}},
+			expected: map[string]string{"provider": "aws", "region": "eu-west-1", "team": "web"},
+		},
+		{
+			name:     "the denied labels",
+			cfg:      config.CardinalityConfig{Deny: []string{"name", "provider"}},
+			expected: map[string]string{"provider": "aws", "region": "eu-west-1", "team": "web"},
+		},
+		{
+			name:     "the allowed labels not denied",
+			cfg:      config.CardinalityConfig{Allow: []string{"team", "region"}, Deny: []string{"region"}},
+			expected: map[string]string{"provider": "aws", "team": "web"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, err := New("instances", &test.cfg, "provider")
+			assert.NoError(err)
+
+			out, ok := l.Labels(labels)
+			assert.True(ok)
+			assert.Equal(test.expected, out)
+		})
+	}
+
+	// the labels aren't modified
+	assert.Len(labels, 4)
+}
+
+func TestLimit(t *testing.T) {
+	assert := require.New(t)
+
+	series := func(name string) map[string]string {
+		return map[string]string{"provider": "aws", "name": name}
+	}
+
+	l, err := New("instances", &config.CardinalityConfig{MaxSeries: 2})
+	assert.NoError(err)
+
+	_, ok := l.Labels(series("i-1"))
+	assert.True(ok)
+	_, ok = l.Labels(series("i-2"))
+	assert.True(ok)
+	_, ok = l.Labels(series("i-3"))
+	assert.False(ok)
+
+	// the series exported keep their place
+	_, ok = l.Labels(series("i-1"))
+	assert.True(ok)
+
+	// the series past the limit are hashed into the buckets
+	l, err = New("instances", &config.CardinalityConfig{MaxSeries: 1, Overflow: "hash", HashLabels: []string{"name"}, HashBuckets: 2})
+	assert.NoError(err)
+
+	out, ok := l.Labels(series("i-1"))
+	assert.True(ok)
+	assert.Equal("i-1", out["name"])
+
+	buckets := map[string]bool{}
+	for _, name := range []string{"i-2", "i-3", "i-4", "i-5", "i-6"} {
+		out, ok := l.Labels(series(name))
+		assert.True(ok)
+		assert.Equal("aws", out["provider"])
+		assert.Regexp(`^hash_[01]$`, out["name"])
+		buckets[out["name"]] = true
+	}
+	assert.Len(buckets, 2)
+
+	// the same value is always in the same bucket
+	a, _ := l.Labels(series("i-2"))
+	b, _ := l.Labels(series("i-2"))
+	assert.Equal(a, b)
+}
+
+func TestNew(t *testing.T) {
+	assert := require.New(t)
+
+	for _, cfg := range []config.CardinalityConfig{
+		{MaxSeries: -1},
+		{HashBuckets: -1},
+		{Overflow: "sample"},
+		{Overflow: "hash"},
+	} {
+		_, err := New("instances", &cfg)
+		assert.Error(err, "%+v", cfg)
+	}
+
+	l, err := New("instances", &config.CardinalityConfig{Overflow: "Hash", HashLabels: []string{"name"}})
+	assert.NoError(err)
+	assert.Equal(OverflowHash, l.overflow)
+	assert.Equal(uint32(defaultBuckets), l.buckets)
+}
@@ -0,0 +1,225 @@
+// Package ccf exports the stored emissions in the estimation format of Cloud
+// Carbon Footprint (https://www.cloudcarbonfootprint.org), the one returned
+// by its /api/footprint endpoint, so that its dashboards and the tools built
+// on its output can read them
+package ccf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/cost"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The periods the estimates are grouped by, the ones of CCF
+const (
+	GroupByDay     = "day"
+	GroupByWeek    = "week"
+	GroupByMonth   = "month"
+	GroupByQuarter = "quarter"
+	GroupByYear    = "year"
+)
+
+// EstimationResult is the estimates of a period
+type EstimationResult struct {
+	Timestamp        time.Time         `json:"timestamp"`
+	ServiceEstimates []ServiceEstimate `json:"serviceEstimates"`
+	PeriodStartDate  time.Time         `json:"periodStartDate"`
+	PeriodEndDate    time.Time         `json:"periodEndDate"`
+	GroupBy          string            `json:"groupBy"`
+}
+
+// ServiceEstimate is the energy, the emissions and the cost of a service of
+// an account in a region
+type ServiceEstimate struct {
+	CloudProvider string `json:"cloudProvider"`
+	AccountID     string `json:"accountId"`
+	AccountName   string `json:"accountName"`
+	ServiceName   string `json:"serviceName"`
+	Region        string `json:"region"`
+
+	// The energy drawn, derived from the operational emissions and the
+	// annual grid intensity of the region
+	KilowattHours float64 `json:"kilowattHours"`
+
+	// The operational and the embodied emissions in metric tons CO2eq
+	CO2e float64 `json:"co2e"`
+
+	// The spend read from the billing exports, in their currency
+	Cost float64 `json:"cost"`
+
+	// Whether some emissions are of the low quality tier, the closest to
+	// the average CPU utilization CCF estimates with without metrics
+	UsesAverageCPUConstant bool `json:"usesAverageCPUConstant"`
+}
+
+// Request describes the estimates to export
+type Request struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+
+	// The location the periods start in
+	Location *time.Location
+
+	// Returns the grid intensity of a region in gCO2eq/kWh
+	Intensity func(provider v1.Provider, region string) (float64, error)
+}
+
+// ValidGroupBy returns an error when the period isn't one of CCF
+func ValidGroupBy(groupBy string) error {
+	switch groupBy {
+	case GroupByDay, GroupByWeek, GroupByMonth, GroupByQuarter, GroupByYear:
+		return nil
+	}
+	return fmt.Errorf("invalid groupBy %q, expected day, week, month, quarter or year", groupBy)
+}
+
+// Export returns the estimates of the samples and the costs in [from, to) by
+// period, the earliest first. The costs are added to the estimates of the
+// instances billed, the ones of the other resources are left out
+func Export(samples []store.Sample, costs []cost.Cost, r *Request) []EstimationResult {
+	loc := r.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	type period struct {
+		start     time.Time
+		estimates map[string]*ServiceEstimate
+	}
+
+	periods := make(map[time.Time]*period)
+
+	// the estimates the instances are added to, by period, for their costs
+	instances := make(map[time.Time]map[string]*ServiceEstimate)
+
+	for i := range samples {
+		s := &samples[i]
+		if s.Time.Before(r.From) || !s.Time.Before(r.To) {
+			continue
+		}
+
+		start := truncate(s.Time, r.GroupBy, loc)
+		p, ok := periods[start]
+		if !ok {
+			p = &period{start: start, estimates: make(map[string]*ServiceEstimate)}
+			periods[start] = p
+			instances[start] = make(map[string]*ServiceEstimate)
+		}
+
+		account := s.Label(v1.AccountLabel)
+		key := strings.Join([]string{s.Provider.String(), account, s.Service, s.Region}, "\x00")
+		e, ok := p.estimates[key]
+		if !ok {
+			e = &ServiceEstimate{
+				CloudProvider: strings.ToUpper(s.Provider.String()),
+				AccountID:     account,
+				AccountName:   account,
+				ServiceName:   s.Service,
+				Region:        s.Region,
+			}
+			p.estimates[key] = e
+		}
+
+		e.CO2e += (s.Operational + s.Embodied) / 1e6
+		if s.Quality == v1.TierLow {
+			e.UsesAverageCPUConstant = true
+		}
+		if r.Intensity != nil && s.Operational > 0 {
+			if intensity, err := r.Intensity(s.Provider, s.Region); err == nil && intensity > 0 {
+				e.KilowattHours += s.Operational / intensity
+			}
+		}
+
+		instances[start][s.Provider.String()+"/"+s.Name] = e
+	}
+
+	for i := range costs {
+		c := &costs[i]
+		if c.Day.Before(r.From) || !c.Day.Before(r.To) {
+			continue
+		}
+
+		if e, ok := instances[truncate(c.Day, r.GroupBy, loc)][c.Provider.String()+"/"+c.Resource]; ok {
+			e.Cost += c.Amount
+		}
+	}
+
+	results := make([]EstimationResult, 0, len(periods))
+	for _, p := range periods {
+		estimates := make([]ServiceEstimate, 0, len(p.estimates))
+		for _, e := range p.estimates {
+			estimates = append(estimates, *e)
+		}
+
+		sort.Slice(estimates, func(i, j int) bool {
+			a, b := &estimates[i], &estimates[j]
+			if a.CloudProvider != b.CloudProvider {
+				return a.CloudProvider < b.CloudProvider
+			}
+			if a.AccountID != b.AccountID {
+				return a.AccountID < b.AccountID
+			}
+			if a.ServiceName != b.ServiceName {
+				return a.ServiceName < b.ServiceName
+			}
+			return a.Region < b.Region
+		})
+
+		// the period ends on its last millisecond, like in CCF
+		results = append(results, EstimationResult{
+			Timestamp:        p.start.UTC(),
+			ServiceEstimates: estimates,
+			PeriodStartDate:  p.start.UTC(),
+			PeriodEndDate:    next(p.start, r.GroupBy).Add(-time.Millisecond).UTC(),
+			GroupBy:          r.GroupBy,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Timestamp.Before(results[j].Timestamp)
+	})
+
+	return results
+}
+
+// truncate returns the start of the period of the time in the location, the
+// weeks start on Monday
+func truncate(t time.Time, groupBy string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+	switch groupBy {
+	case GroupByWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case GroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	case GroupByQuarter:
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, loc)
+	case GroupByYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return day
+	}
+}
+
+// next returns the start of the period following the one starting at start
+func next(start time.Time, groupBy string) time.Time {
+	switch groupBy {
+	case GroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case GroupByMonth:
+		return start.AddDate(0, 1, 0)
+	case GroupByQuarter:
+		return start.AddDate(0, 3, 0)
+	case GroupByYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
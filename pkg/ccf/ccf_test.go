@@ -0,0 +1,120 @@
+package ccf
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/cost"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	assert := require.New(t)
+
+	day := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	prod := v1.Labels{v1.AccountLabel: "prod"}
+
+	samples := []store.Sample{
+		{Time: day(1, 10), Provider: v1.AWS, Service: "ec2", Name: "a", Region: "eu-west-1", Labels: prod, Operational: 200_000, Embodied: 100_000, Quality: v1.TierHigh},
+		{Time: day(1, 11), Provider: v1.AWS, Service: "ec2", Name: "b", Region: "eu-west-1", Labels: prod, Operational: 100_000, Quality: v1.TierLow},
+		// the region has no grid intensity
+		{Time: day(1, 12), Provider: v1.GCP, Service: "compute", Name: "c", Region: "moon-1", Operational: 1_000_000},
+		{Time: day(2, 10), Provider: v1.AWS, Service: "ec2", Name: "a", Region: "eu-west-1", Labels: prod, Operational: 400_000},
+		// after the range
+		{Time: day(3, 0), Provider: v1.AWS, Service: "ec2", Name: "a", Region: "eu-west-1", Labels: prod, Operational: 400_000},
+	}
+
+	costs := []cost.Cost{
+		{Provider: v1.AWS, Resource: "a", Day: day(1, 0), Amount: 2},
+		{Provider: v1.AWS, Resource: "b", Day: day(1, 0), Amount: 1},
+		{Provider: v1.AWS, Resource: "a", Day: day(2, 0), Amount: 3},
+		// not an instance
+		{Provider: v1.AWS, Resource: "bucket", Day: day(1, 0), Amount: 5},
+	}
+
+	r := &Request{
+		From:    day(1, 0),
+		To:      day(3, 0),
+		GroupBy: GroupByDay,
+		Intensity: func(provider v1.Provider, region string) (float64, error) {
+			if region != "eu-west-1" {
+				return 0, errors.New("unknown region")
+			}
+			return 100, nil
+		},
+	}
+
+	results := Export(samples, costs, r)
+	assert.Len(results, 2)
+
+	assert.Equal(day(1, 0), results[0].Timestamp)
+	assert.Equal(day(1, 0), results[0].PeriodStartDate)
+	assert.Equal(day(2, 0).Add(-time.Millisecond), results[0].PeriodEndDate)
+	assert.Equal(GroupByDay, results[0].GroupBy)
+
+	assert.Len(results[0].ServiceEstimates, 2)
+	aws := results[0].ServiceEstimates[0]
+	assert.Equal("AWS", aws.CloudProvider)
+	assert.Equal("prod", aws.AccountID)
+	assert.Equal("ec2", aws.ServiceName)
+	assert.Equal("eu-west-1", aws.Region)
+	assert.InDelta(3000, aws.KilowattHours, 1e-9)
+	assert.InDelta(0.4, aws.CO2e, 1e-9)
+	assert.InDelta(3, aws.Cost, 1e-9)
+	assert.True(aws.UsesAverageCPUConstant)
+
+	gcp := results[0].ServiceEstimates[1]
+	assert.Equal("GCP", gcp.CloudProvider)
+	assert.Empty(gcp.AccountID)
+	assert.Zero(gcp.KilowattHours)
+	assert.InDelta(1, gcp.CO2e, 1e-9)
+
+	assert.Len(results[1].ServiceEstimates, 1)
+	assert.InDelta(0.4, results[1].ServiceEstimates[0].CO2e, 1e-9)
+	assert.InDelta(3, results[1].ServiceEstimates[0].Cost, 1e-9)
+	assert.False(results[1].ServiceEstimates[0].UsesAverageCPUConstant)
+
+	// a month sums the days
+	r.GroupBy = GroupByMonth
+	results = Export(samples, costs, r)
+	assert.Len(results, 1)
+	assert.Equal(time.Date(2024, 1, 31, 23, 59, 59, 999_000_000, time.UTC), results[0].PeriodEndDate)
+	assert.InDelta(0.8, results[0].ServiceEstimates[0].CO2e, 1e-9)
+	assert.InDelta(6, results[0].ServiceEstimates[0].Cost, 1e-9)
+}
+
+func TestTruncate(t *testing.T) {
+	assert := require.New(t)
+
+	// a Wednesday
+	at := time.Date(2024, 5, 15, 13, 30, 0, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(err)
+
+	tests := []struct {
+		groupBy string
+		loc     *time.Location
+		want    time.Time
+	}{
+		{groupBy: GroupByDay, loc: time.UTC, want: time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{groupBy: GroupByWeek, loc: time.UTC, want: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{groupBy: GroupByMonth, loc: time.UTC, want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{groupBy: GroupByQuarter, loc: time.UTC, want: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{groupBy: GroupByYear, loc: time.UTC, want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{groupBy: GroupByDay, loc: berlin, want: time.Date(2024, 5, 15, 0, 0, 0, 0, berlin)},
+	}
+
+	for _, test := range tests {
+		got := truncate(at, test.groupBy, test.loc)
+		assert.True(test.want.Equal(got), "%s %s: %s", test.groupBy, test.loc, got)
+	}
+
+	// a Sunday starts the week of the Monday before
+	assert.Equal(time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), truncate(time.Date(2024, 5, 19, 8, 0, 0, 0, time.UTC), GroupByWeek, time.UTC))
+
+	assert.NoError(ValidGroupBy(GroupByQuarter))
+	assert.Error(ValidGroupBy("hour"))
+}
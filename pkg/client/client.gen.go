@@ -0,0 +1,5739 @@
+// Package client provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen/v2 version v2.1.0 DO NOT EDIT.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	AdminTokenScopes = "adminToken.Scopes"
+	BasicAuthScopes  = "basicAuth.Scopes"
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for AnomalyType.
+const (
+	NegativeEmissions     AnomalyType = "negative_emissions"
+	NegativePower         AnomalyType = "negative_power"
+	PowerAboveMax         AnomalyType = "power_above_max"
+	UtilizationOutOfRange AnomalyType = "utilization_out_of_range"
+)
+
+// Defines values for BreakdownGridFallback.
+const (
+	BreakdownGridFallbackContinent BreakdownGridFallback = "continent"
+	BreakdownGridFallbackCountry   BreakdownGridFallback = "country"
+	BreakdownGridFallbackGlobal    BreakdownGridFallback = "global"
+)
+
+// Defines values for ErrorCode.
+const (
+	DatasetStale      ErrorCode = "dataset_stale"
+	Internal          ErrorCode = "internal"
+	KindNotFound      ErrorCode = "kind_not_found"
+	ProviderThrottled ErrorCode = "provider_throttled"
+	RegionNotFound    ErrorCode = "region_not_found"
+)
+
+// Defines values for EstimateGridFallback.
+const (
+	EstimateGridFallbackContinent EstimateGridFallback = "continent"
+	EstimateGridFallbackCountry   EstimateGridFallback = "country"
+	EstimateGridFallbackGlobal    EstimateGridFallback = "global"
+)
+
+// Defines values for EstimationResultGroupBy.
+const (
+	EstimationResultGroupByDay     EstimationResultGroupBy = "day"
+	EstimationResultGroupByMonth   EstimationResultGroupBy = "month"
+	EstimationResultGroupByQuarter EstimationResultGroupBy = "quarter"
+	EstimationResultGroupByWeek    EstimationResultGroupBy = "week"
+	EstimationResultGroupByYear    EstimationResultGroupBy = "year"
+)
+
+// Defines values for JobStatus.
+const (
+	Complete JobStatus = "Complete"
+	Failed   JobStatus = "Failed"
+	Unknown  JobStatus = "Unknown"
+)
+
+// Defines values for MissType.
+const (
+	Kind   MissType = "kind"
+	Region MissType = "region"
+)
+
+// Defines values for OverrideFactor.
+const (
+	NetworkingEmbodied OverrideFactor = "networking-embodied"
+	Pue                OverrideFactor = "pue"
+	StorageEmbodied    OverrideFactor = "storage-embodied"
+)
+
+// Defines values for Provider.
+const (
+	Aws          Provider = "aws"
+	Azure        Provider = "azure"
+	Digitalocean Provider = "digitalocean"
+	Gcp          Provider = "gcp"
+	Oci          Provider = "oci"
+	Prometheus   Provider = "prometheus"
+	Scaleway     Provider = "scaleway"
+)
+
+// Defines values for QualityIntensity.
+const (
+	Annual   QualityIntensity = "annual"
+	Realtime QualityIntensity = "realtime"
+)
+
+// Defines values for QualityPower.
+const (
+	Curve    QualityPower = "curve"
+	Fallback QualityPower = "fallback"
+	Measured QualityPower = "measured"
+)
+
+// Defines values for QueryResponseUnit1.
+const (
+	QueryResponseUnit1GCO2ekWh QueryResponseUnit1 = "gCO2e/kWh"
+)
+
+// Defines values for RangeResponseDataResolution.
+const (
+	Daily  RangeResponseDataResolution = "daily"
+	Hourly RangeResponseDataResolution = "hourly"
+	Raw    RangeResponseDataResolution = "raw"
+)
+
+// Defines values for RangeResponseDataResultType.
+const (
+	Matrix RangeResponseDataResultType = "matrix"
+)
+
+// Defines values for RangeResponseDataUnit1.
+const (
+	RangeResponseDataUnit1GCO2ekWh RangeResponseDataUnit1 = "gCO2e/kWh"
+)
+
+// Defines values for RangeResponseStatus.
+const (
+	Success RangeResponseStatus = "success"
+)
+
+// Defines values for RecommendationPattern.
+const (
+	Batch   RecommendationPattern = "batch"
+	Diurnal RecommendationPattern = "diurnal"
+)
+
+// Defines values for RightsizingFinding.
+const (
+	Overprovisioned  RightsizingFinding = "overprovisioned"
+	Underprovisioned RightsizingFinding = "underprovisioned"
+)
+
+// Defines values for Tier.
+const (
+	High   Tier = "high"
+	Low    Tier = "low"
+	Medium Tier = "medium"
+)
+
+// Defines values for Unit.
+const (
+	GCO2e  Unit = "gCO2e"
+	KgCO2e Unit = "kgCO2e"
+	TCO2e  Unit = "tCO2e"
+)
+
+// Defines values for CcfFootprintParamsGroupBy.
+const (
+	CcfFootprintParamsGroupByDay     CcfFootprintParamsGroupBy = "day"
+	CcfFootprintParamsGroupByMonth   CcfFootprintParamsGroupBy = "month"
+	CcfFootprintParamsGroupByQuarter CcfFootprintParamsGroupBy = "quarter"
+	CcfFootprintParamsGroupByWeek    CcfFootprintParamsGroupBy = "week"
+	CcfFootprintParamsGroupByYear    CcfFootprintParamsGroupBy = "year"
+)
+
+// Defines values for EstimateParamsFormat.
+const (
+	Github   EstimateParamsFormat = "github"
+	Gitlab   EstimateParamsFormat = "gitlab"
+	Json     EstimateParamsFormat = "json"
+	Markdown EstimateParamsFormat = "markdown"
+)
+
+// Defines values for ListInstancesParamsSort.
+const (
+	ListInstancesParamsSortEmissions      ListInstancesParamsSort = "emissions"
+	ListInstancesParamsSortMinusEmissions ListInstancesParamsSort = "-emissions"
+	ListInstancesParamsSortMinusName      ListInstancesParamsSort = "-name"
+	ListInstancesParamsSortName           ListInstancesParamsSort = "name"
+)
+
+// Defines values for GetExternalMetricParamsMetric.
+const (
+	GetExternalMetricParamsMetricEmissions          GetExternalMetricParamsMetric = "emissions"
+	GetExternalMetricParamsMetricGridIntensity      GetExternalMetricParamsMetric = "grid_intensity"
+	GetExternalMetricParamsMetricNamespaceEmissions GetExternalMetricParamsMetric = "namespace_emissions"
+)
+
+// APIResourceList defines model for APIResourceList.
+type APIResourceList struct {
+	ApiVersion   *string `json:"apiVersion,omitempty"`
+	GroupVersion *string `json:"groupVersion,omitempty"`
+	Kind         *string `json:"kind,omitempty"`
+	Resources    *[]struct {
+		Kind       *string   `json:"kind,omitempty"`
+		Name       *string   `json:"name,omitempty"`
+		Namespaced *bool     `json:"namespaced,omitempty"`
+		Verbs      *[]string `json:"verbs,omitempty"`
+	} `json:"resources,omitempty"`
+}
+
+// Accelerators The accelerators attached to the instance, whose embodied emissions are included in its embodied factor
+type Accelerators struct {
+	Count float64 `json:"count"`
+
+	// EmbodiedHourlyFactor In gCO2eq/h
+	EmbodiedHourlyFactor float64 `json:"embodiedHourlyFactor"`
+	Type                 string  `json:"type"`
+}
+
+// Anomaly defines model for Anomaly.
+type Anomaly struct {
+	Clamped float64     `json:"clamped"`
+	Type    AnomalyType `json:"type"`
+	Value   float64     `json:"value"`
+}
+
+// AnomalyType defines model for Anomaly.Type.
+type AnomalyType string
+
+// Breakdown defines model for Breakdown.
+type Breakdown struct {
+	// Accelerators The accelerators attached to the instance, whose embodied emissions are included in its embodied factor
+	Accelerators *Accelerators `json:"accelerators,omitempty"`
+
+	// Account The account the instance is scraped from
+	Account      *string   `json:"account,omitempty"`
+	CalculatedAt time.Time `json:"calculatedAt"`
+	Embodied     Step      `json:"embodied"`
+
+	// EmbodiedHourlyFactor In gCO2eq/h
+	EmbodiedHourlyFactor float64 `json:"embodiedHourlyFactor"`
+
+	// EmbodiedOverhead The uplift in % of the embodied factor for the shared networking and storage infrastructure of the data centers, included in it
+	EmbodiedOverhead *float64 `json:"embodiedOverhead,omitempty"`
+	EmbodiedQuality  Quality  `json:"embodiedQuality"`
+
+	// GridCO2e In gCO2eq/kWh
+	GridCO2e float64 `json:"gridCO2e"`
+
+	// GridFallback The level of the average grid intensity used when the region is missing from the emission factors
+	GridFallback *BreakdownGridFallback `json:"gridFallback,omitempty"`
+
+	// Interval In nanoseconds
+	Interval int64             `json:"interval"`
+	Kind     string            `json:"kind"`
+	Metrics  []MetricBreakdown `json:"metrics"`
+	Name     string            `json:"name"`
+
+	// Observed How long the instance was observed over the interval, shorter than it when the instance was launched or terminated during the interval. In nanoseconds
+	Observed int64    `json:"observed"`
+	Provider Provider `json:"provider"`
+	Pue      float64  `json:"pue"`
+
+	// PueSource Where the PUE comes from, emission-factors or the source set with the override of the config
+	PueSource *string        `json:"pueSource,omitempty"`
+	Region    string         `json:"region"`
+	VCPU      float64        `json:"vCPU"`
+	Wattage   []WattagePoint `json:"wattage"`
+	Zone      string         `json:"zone"`
+}
+
+// BreakdownGridFallback The level of the average grid intensity used when the region is missing from the emission factors
+type BreakdownGridFallback string
+
+// CostRow defines model for CostRow.
+type CostRow struct {
+	// Cost The spend in the currency, zero when the instances are billed in several currencies
+	Cost *float32 `json:"cost,omitempty"`
+
+	// Currency The currency of the billing export, empty when the instances are billed in several currencies
+	Currency *string `json:"currency,omitempty"`
+
+	// Emissions gCO2eq
+	Emissions *float32 `json:"emissions,omitempty"`
+
+	// Gco2ePerDollar The emissions per unit of the currency
+	Gco2ePerDollar *float32 `json:"gco2ePerDollar,omitempty"`
+	Group          *string  `json:"group,omitempty"`
+	Instances      *int     `json:"instances,omitempty"`
+}
+
+// CostsResponse defines model for CostsResponse.
+type CostsResponse struct {
+	From    *time.Time `json:"from,omitempty"`
+	GroupBy *string    `json:"groupBy,omitempty"`
+	Rows    *[]CostRow `json:"rows,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+	Total   *CostRow   `json:"total,omitempty"`
+}
+
+// Dataset defines model for Dataset.
+type Dataset struct {
+	// Error Why the last pull of the dataset failed, the one loaded before, if any, is still used
+	Error *string `json:"error,omitempty"`
+
+	// Overrides The factors overridden by the config
+	Overrides *[]Override `json:"overrides,omitempty"`
+
+	// RefreshedAt When the dataset was last loaded
+	RefreshedAt time.Time `json:"refreshedAt"`
+
+	// Source Where the dataset is loaded from
+	Source string `json:"source"`
+
+	// Version The version of the dataset, e.g. a commit hash
+	Version string `json:"version"`
+}
+
+// DatasetsResponse defines model for DatasetsResponse.
+type DatasetsResponse struct {
+	EmissionFactors Dataset `json:"emissionFactors"`
+}
+
+// EfficiencyResponse defines model for EfficiencyResponse.
+type EfficiencyResponse struct {
+	From     *time.Time       `json:"from,omitempty"`
+	GroupBy  *string          `json:"groupBy,omitempty"`
+	Previous *EfficiencyRow   `json:"previous,omitempty"`
+	Rows     *[]EfficiencyRow `json:"rows,omitempty"`
+	To       *time.Time       `json:"to,omitempty"`
+	Total    *EfficiencyRow   `json:"total,omitempty"`
+}
+
+// EfficiencyRow defines model for EfficiencyRow.
+type EfficiencyRow struct {
+	// Emissions The operational and embodied emissions in gCO2eq
+	Emissions *float32 `json:"emissions,omitempty"`
+
+	// Gco2ePerVCPUHour The emissions per vCPU hour
+	Gco2ePerVCPUHour *float32 `json:"gco2ePerVCPUHour,omitempty"`
+	Group            *string  `json:"group,omitempty"`
+
+	// IdleEmissions The emissions of the vCPUs left unused in gCO2eq
+	IdleEmissions *float32 `json:"idleEmissions,omitempty"`
+
+	// IdleShare The share of the emissions of the vCPUs left unused in %
+	IdleShare *float32 `json:"idleShare,omitempty"`
+	Instances *int     `json:"instances,omitempty"`
+
+	// Utilization The utilization of the vCPUs in %, averaged over the vCPU hours
+	Utilization *float32 `json:"utilization,omitempty"`
+
+	// VcpuHours The vCPU hours of the instances
+	VcpuHours *float32 `json:"vcpuHours,omitempty"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	// Code Identifies the cause of the error
+	Code  *ErrorCode `json:"code,omitempty"`
+	Error string     `json:"error"`
+}
+
+// ErrorCode Identifies the cause of the error
+type ErrorCode string
+
+// Estimate defines model for Estimate.
+type Estimate struct {
+	// Duration In nanoseconds
+	Duration *int64 `json:"duration,omitempty"`
+
+	// Embodied The embodied emissions
+	Embodied *float32 `json:"embodied,omitempty"`
+
+	// GridCO2e The grid intensity in gCO2eq/kWh
+	GridCO2e *float32 `json:"gridCO2e,omitempty"`
+
+	// GridFallback The level of the average grid intensity used when the region is missing from the emission factors
+	GridFallback *EstimateGridFallback `json:"gridFallback,omitempty"`
+	Kind         *string               `json:"kind,omitempty"`
+
+	// Operational The operational emissions
+	Operational *float32  `json:"operational,omitempty"`
+	Provider    *Provider `json:"provider,omitempty"`
+	Pue         *float32  `json:"pue,omitempty"`
+	PueSource   *string   `json:"pueSource,omitempty"`
+	Region      *string   `json:"region,omitempty"`
+
+	// Total The emissions
+	Total *float32 `json:"total,omitempty"`
+
+	// Unit The unit of the emissions, set by the units config. gCO2e by default
+	Unit        *Unit    `json:"unit,omitempty"`
+	Utilization *float32 `json:"utilization,omitempty"`
+	VCPU        *float32 `json:"vCPU,omitempty"`
+}
+
+// EstimateGridFallback The level of the average grid intensity used when the region is missing from the emission factors
+type EstimateGridFallback string
+
+// EstimationResult defines model for EstimationResult.
+type EstimationResult struct {
+	GroupBy EstimationResultGroupBy `json:"groupBy"`
+
+	// PeriodEndDate The last millisecond of the period
+	PeriodEndDate    time.Time         `json:"periodEndDate"`
+	PeriodStartDate  time.Time         `json:"periodStartDate"`
+	ServiceEstimates []ServiceEstimate `json:"serviceEstimates"`
+
+	// Timestamp The start of the period
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EstimationResultGroupBy defines model for EstimationResult.GroupBy.
+type EstimationResultGroupBy string
+
+// ExternalMetricValueList defines model for ExternalMetricValueList.
+type ExternalMetricValueList struct {
+	ApiVersion *string `json:"apiVersion,omitempty"`
+	Items      *[]struct {
+		MetricLabels *map[string]string `json:"metricLabels,omitempty"`
+		MetricName   *string            `json:"metricName,omitempty"`
+		Timestamp    *time.Time         `json:"timestamp,omitempty"`
+
+		// Value A Kubernetes quantity, e.g. 215500m
+		Value *string `json:"value,omitempty"`
+	} `json:"items,omitempty"`
+	Kind     *string                 `json:"kind,omitempty"`
+	Metadata *map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// GraphQLRequest defines model for GraphQLRequest.
+type GraphQLRequest struct {
+	OperationName *string                 `json:"operationName,omitempty"`
+	Query         string                  `json:"query"`
+	Variables     *map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Health defines model for Health.
+type Health struct {
+	Status string `json:"status"`
+}
+
+// ImportRecord The emissions or the energy of a resource over a period ending at its time, one of operational and energy is required
+type ImportRecord struct {
+	// Embodied The embodied emissions in gCO2eq
+	Embodied *float64 `json:"embodied,omitempty"`
+
+	// Energy The energy in kWh
+	Energy *float64 `json:"energy,omitempty"`
+
+	// GridIntensity The grid intensity of the energy in gCO2eq/kWh. Default: the one of the region of the provider
+	GridIntensity *float64           `json:"gridIntensity,omitempty"`
+	Kind          *string            `json:"kind,omitempty"`
+	Labels        *map[string]string `json:"labels,omitempty"`
+	Name          string             `json:"name"`
+
+	// Operational The operational emissions in gCO2eq, calculated from the energy when missing
+	Operational *float64 `json:"operational,omitempty"`
+	Provider    Provider `json:"provider"`
+	Region      *string  `json:"region,omitempty"`
+	Service     *string  `json:"service,omitempty"`
+
+	// Source The source label of the sample, e.g. the vendor. Default: import
+	Source *string   `json:"source,omitempty"`
+	Time   time.Time `json:"time"`
+	Zone   *string   `json:"zone,omitempty"`
+}
+
+// ImportRequest defines model for ImportRequest.
+type ImportRequest struct {
+	Records []ImportRecord `json:"records"`
+}
+
+// ImportResponse defines model for ImportResponse.
+type ImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+// InstancesResponse defines model for InstancesResponse.
+type InstancesResponse struct {
+	Instances []Sample `json:"instances"`
+
+	// Next The cursor of the next page, missing on the last one
+	Next *string `json:"next,omitempty"`
+
+	// Unit The unit of the emissions, set by the units config. gCO2e by default
+	Unit Unit `json:"unit"`
+}
+
+// Job defines model for Job.
+type Job struct {
+	// CronJob The CronJob that created the job, if any
+	CronJob *string `json:"cronJob,omitempty"`
+
+	// Emissions The emissions over the lifetime of the job
+	Emissions float32 `json:"emissions"`
+
+	// End When the pods of the job were last seen running
+	End       time.Time `json:"end"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+
+	// Start When the pods of the job were first seen running
+	Start time.Time `json:"start"`
+
+	// Status Unknown when the job was deleted before its completion was seen
+	Status *JobStatus `json:"status,omitempty"`
+}
+
+// JobStatus Unknown when the job was deleted before its completion was seen
+type JobStatus string
+
+// JobsResponse defines model for JobsResponse.
+type JobsResponse struct {
+	Jobs []Job `json:"jobs"`
+
+	// Next The cursor of the next page, empty on the last one
+	Next *string `json:"next,omitempty"`
+
+	// Unit The unit of the emissions, set by the units config. gCO2e by default
+	Unit Unit `json:"unit"`
+}
+
+// MetricBreakdown defines model for MetricBreakdown.
+type MetricBreakdown struct {
+	// Anomalies The physically implausible values which were clamped
+	Anomalies *[]Anomaly `json:"anomalies,omitempty"`
+
+	// Emissions In gCO2eq
+	Emissions  float64  `json:"emissions"`
+	Error      *string  `json:"error,omitempty"`
+	Name       string   `json:"name"`
+	Quality    *Quality `json:"quality,omitempty"`
+	Steps      []Step   `json:"steps"`
+	Unit       string   `json:"unit"`
+	UnitAmount float64  `json:"unitAmount"`
+	Usage      float64  `json:"usage"`
+}
+
+// Miss defines model for Miss.
+type Miss struct {
+	// Architecture The CPU platform or architecture of the instances of the missing kind
+	Architecture *string `json:"architecture,omitempty"`
+
+	// Count The amount of calculations skipped
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+
+	// Instance The last instance the lookup failed for
+	Instance string    `json:"instance"`
+	LastSeen time.Time `json:"lastSeen"`
+
+	// MemoryGB The memory of the instances of the missing kind in GB, as collected from the provider
+	MemoryGB *float32 `json:"memoryGB,omitempty"`
+	Provider Provider `json:"provider"`
+
+	// Type Whether the instance kind or the region is missing
+	Type MissType `json:"type"`
+
+	// VCPU The vCPUs of the instances of the missing kind, as collected from the provider
+	VCPU *float32 `json:"vCPU,omitempty"`
+
+	// Value The missing instance kind or region
+	Value string `json:"value"`
+}
+
+// MissType Whether the instance kind or the region is missing
+type MissType string
+
+// MissesResponse defines model for MissesResponse.
+type MissesResponse struct {
+	Misses []Miss `json:"misses"`
+}
+
+// OperationStatus defines model for OperationStatus.
+type OperationStatus struct {
+	Status string `json:"status"`
+}
+
+// Overhead Emissions over the scraping interval
+type Overhead struct {
+	Cluster *string `json:"cluster,omitempty"`
+
+	// ControlPlane The configured emissions of the managed control plane
+	ControlPlane *float32 `json:"controlPlane,omitempty"`
+
+	// Pods The emissions attributed to the pods
+	Pods *float32 `json:"pods,omitempty"`
+
+	// System The emissions of the pods of the system namespaces
+	System *float32 `json:"system,omitempty"`
+
+	// Total The emissions of the cluster
+	Total *float32 `json:"total,omitempty"`
+
+	// Unallocated The emissions of the nodes running no pods and of the pods dropped by the relabeling
+	Unallocated *float32 `json:"unallocated,omitempty"`
+
+	// Unit The unit of the emissions, set by the units config. gCO2e by default
+	Unit *Unit `json:"unit,omitempty"`
+}
+
+// Override defines model for Override.
+type Override struct {
+	Factor   OverrideFactor `json:"factor"`
+	Provider string         `json:"provider"`
+
+	// Region Empty for all the regions of the provider
+	Region *string `json:"region,omitempty"`
+	Source string  `json:"source"`
+	Value  float64 `json:"value"`
+}
+
+// OverrideFactor defines model for Override.Factor.
+type OverrideFactor string
+
+// Permission defines model for Permission.
+type Permission struct {
+	// Error The error of the check of a missing permission
+	Error   *string `json:"error,omitempty"`
+	Granted bool    `json:"granted"`
+
+	// Name The permission, in the terms of the provider
+	Name string `json:"name"`
+}
+
+// Pod defines model for Pod.
+type Pod struct {
+	// Emissions The emissions over the scraping interval
+	Emissions float32 `json:"emissions"`
+
+	// Job The Job the pod belongs to, if any
+	Job *string `json:"job,omitempty"`
+
+	// Labels The copied pod labels and annotations, after the relabeling
+	Labels    *map[string]string `json:"labels,omitempty"`
+	Name      string             `json:"name"`
+	Namespace string             `json:"namespace"`
+	Node      string             `json:"node"`
+}
+
+// PodsResponse defines model for PodsResponse.
+type PodsResponse struct {
+	// Next The cursor of the next page, empty on the last one
+	Next *string `json:"next,omitempty"`
+	Pods []Pod   `json:"pods"`
+
+	// Unit The unit of the emissions, set by the units config. gCO2e by default
+	Unit Unit `json:"unit"`
+}
+
+// Provider defines model for Provider.
+type Provider string
+
+// Quality defines model for Quality.
+type Quality struct {
+	// Intensity Where the grid intensity comes from, not set for the embodied emissions
+	Intensity *QualityIntensity `json:"intensity,omitempty"`
+
+	// Power Where the power or the embodied factor comes from: measured on the instance, the curve or factor of the instance type, or the generic values of the CPU platform or architecture
+	Power QualityPower `json:"power"`
+
+	// Tier The confidence in the emissions
+	Tier Tier `json:"tier"`
+}
+
+// QualityIntensity Where the grid intensity comes from, not set for the embodied emissions
+type QualityIntensity string
+
+// QualityPower Where the power or the embodied factor comes from: measured on the instance, the curve or factor of the instance type, or the generic values of the CPU platform or architecture
+type QualityPower string
+
+// QueryResponse defines model for QueryResponse.
+type QueryResponse struct {
+	// Next The cursor of the next page, missing on the last one
+	Next    *string       `json:"next,omitempty"`
+	Query   string        `json:"query"`
+	Results []QueryResult `json:"results"`
+
+	// Unit The unit of the values, missing for the count function. gCO2e/kWh for the grid intensity, which isn't converted
+	Unit *QueryResponse_Unit `json:"unit,omitempty"`
+}
+
+// QueryResponseUnit1 defines model for QueryResponse.Unit.1.
+type QueryResponseUnit1 string
+
+// QueryResponse_Unit The unit of the values, missing for the count function. gCO2e/kWh for the grid intensity, which isn't converted
+type QueryResponse_Unit struct {
+	union json.RawMessage
+}
+
+// QueryResult defines model for QueryResult.
+type QueryResult struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// RangeResponse defines model for RangeResponse.
+type RangeResponse struct {
+	Data struct {
+		// Resolution The samples the query read
+		Resolution RangeResponseDataResolution `json:"resolution"`
+		Result     []RangeSeries               `json:"result"`
+		ResultType RangeResponseDataResultType `json:"resultType"`
+
+		// Unit The unit of the values, missing for the count function. gCO2e/kWh for the grid intensity, which isn't converted
+		Unit *RangeResponse_Data_Unit `json:"unit,omitempty"`
+	} `json:"data"`
+	Status RangeResponseStatus `json:"status"`
+}
+
+// RangeResponseDataResolution The samples the query read
+type RangeResponseDataResolution string
+
+// RangeResponseDataResultType defines model for RangeResponse.Data.ResultType.
+type RangeResponseDataResultType string
+
+// RangeResponseDataUnit1 defines model for RangeResponse.Data.Unit.1.
+type RangeResponseDataUnit1 string
+
+// RangeResponse_Data_Unit The unit of the values, missing for the count function. gCO2e/kWh for the grid intensity, which isn't converted
+type RangeResponse_Data_Unit struct {
+	union json.RawMessage
+}
+
+// RangeResponseStatus defines model for RangeResponse.Status.
+type RangeResponseStatus string
+
+// RangeSeries defines model for RangeSeries.
+type RangeSeries struct {
+	// Metric The labels of the group
+	Metric map[string]string `json:"metric"`
+
+	// Values The steps with samples, as pairs of the unix timestamp of their start in seconds and of the value as a string
+	Values [][]interface{} `json:"values"`
+}
+
+// Recommendation defines model for Recommendation.
+type Recommendation struct {
+	// GreenHours The greenest UTC hours the load could run in, without a profile for the region when missing
+	GreenHours *[]int  `json:"greenHours,omitempty"`
+	Name       *string `json:"name,omitempty"`
+
+	// Operational gCO2eq
+	Operational *float32               `json:"operational,omitempty"`
+	Pattern     *RecommendationPattern `json:"pattern,omitempty"`
+
+	// PeakHours The UTC hours holding half of the shiftable emissions
+	PeakHours *[]int    `json:"peakHours,omitempty"`
+	Provider  *Provider `json:"provider,omitempty"`
+	Region    *string   `json:"region,omitempty"`
+
+	// Savings gCO2eq
+	Savings *float32 `json:"savings,omitempty"`
+
+	// Shiftable The operational emissions above the idle floor of the instance, in gCO2eq
+	Shiftable *float32 `json:"shiftable,omitempty"`
+}
+
+// RecommendationPattern defines model for Recommendation.Pattern.
+type RecommendationPattern string
+
+// Rightsizing defines model for Rightsizing.
+type Rightsizing struct {
+	Account string `json:"account"`
+
+	// CarbonSavings The emissions saved a month in gCO2eq, negative when the recommended type emits more
+	CarbonSavings float32 `json:"carbonSavings"`
+
+	// CostSavings The cost saved a month, negative when the recommended type costs more
+	CostSavings float32 `json:"costSavings"`
+	Currency    *string `json:"currency,omitempty"`
+
+	// Emissions The emissions of the current type over a month, in gCO2eq
+	Emissions float32 `json:"emissions"`
+
+	// Error Why the emissions couldn't be estimated, e.g. a type missing from the emission factors
+	Error   *string            `json:"error,omitempty"`
+	Finding RightsizingFinding `json:"finding"`
+
+	// Kind The current instance type
+	Kind string `json:"kind"`
+
+	// Name The ID of the AWS instances, the name of the GCP ones
+	Name     string   `json:"name"`
+	Provider Provider `json:"provider"`
+
+	// Recommended The recommended instance type
+	Recommended string `json:"recommended"`
+
+	// RecommendedEmissions The emissions of the recommended type over a month, in gCO2eq
+	RecommendedEmissions float32 `json:"recommendedEmissions"`
+	Region               string  `json:"region"`
+
+	// Utilization The CPU utilization in % the emissions are estimated with, the recommended type runs the same load on its vCPUs
+	Utilization float32 `json:"utilization"`
+	Zone        *string `json:"zone,omitempty"`
+}
+
+// RightsizingFinding defines model for Rightsizing.Finding.
+type RightsizingFinding string
+
+// RightsizingResponse defines model for RightsizingResponse.
+type RightsizingResponse struct {
+	Recommendations []Rightsizing `json:"recommendations"`
+}
+
+// Sample defines model for Sample.
+type Sample struct {
+	Embodied float64 `json:"embodied"`
+
+	// GridIntensity The grid carbon intensity of the region in gCO2eq/kWh the emissions were calculated with, missing for the samples recorded without it
+	GridIntensity *float32 `json:"gridIntensity,omitempty"`
+
+	// Hours The hours the instance was observed over the scraping interval
+	Hours       *float32           `json:"hours,omitempty"`
+	Kind        string             `json:"kind"`
+	Labels      *map[string]string `json:"labels,omitempty"`
+	Name        string             `json:"name"`
+	Operational float64            `json:"operational"`
+	Provider    Provider           `json:"provider"`
+
+	// Quality The confidence in the emissions
+	Quality *Tier     `json:"quality,omitempty"`
+	Region  string    `json:"region"`
+	Service string    `json:"service"`
+	Time    time.Time `json:"time"`
+
+	// Utilization The utilization of the vCPUs in %
+	Utilization *float32 `json:"utilization,omitempty"`
+
+	// Vcpu The vCPUs of the instance, zero without a CPU metric
+	Vcpu *float32 `json:"vcpu,omitempty"`
+	Zone string   `json:"zone"`
+}
+
+// ScrapeStatus defines model for ScrapeStatus.
+type ScrapeStatus struct {
+	Account             string `json:"account"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	Degraded            bool   `json:"degraded"`
+
+	// Initializing The scraper of the account failed to be created and its creation is being retried
+	Initializing *bool     `json:"initializing,omitempty"`
+	Instances    int       `json:"instances"`
+	LastAttempt  time.Time `json:"lastAttempt"`
+
+	// LastCheck When the permissions of the account were last checked
+	LastCheck *time.Time `json:"lastCheck,omitempty"`
+
+	// LastDuration In nanoseconds
+	LastDuration int64   `json:"lastDuration"`
+	LastError    *string `json:"lastError,omitempty"`
+
+	// LastErrorCode Identifies the cause of the error
+	LastErrorCode *ErrorCode    `json:"lastErrorCode,omitempty"`
+	LastSuccess   time.Time     `json:"lastSuccess"`
+	Permissions   *[]Permission `json:"permissions,omitempty"`
+	Provider      Provider      `json:"provider"`
+}
+
+// ServiceEstimate defines model for ServiceEstimate.
+type ServiceEstimate struct {
+	// AccountId The account label of the instances
+	AccountId   string `json:"accountId"`
+	AccountName string `json:"accountName"`
+
+	// CloudProvider The provider in upper case, e.g. AWS
+	CloudProvider string `json:"cloudProvider"`
+
+	// Co2e The operational and the embodied emissions in metric tons CO2eq
+	Co2e float32 `json:"co2e"`
+
+	// Cost The spend of the instances read from the billing exports, zero without them
+	Cost float32 `json:"cost"`
+
+	// KilowattHours The energy drawn, derived from the operational emissions and the annual grid intensity of the region
+	KilowattHours float32 `json:"kilowattHours"`
+	Region        string  `json:"region"`
+	ServiceName   string  `json:"serviceName"`
+
+	// UsesAverageCPUConstant Whether some emissions are of the low quality tier
+	UsesAverageCPUConstant bool `json:"usesAverageCPUConstant"`
+}
+
+// ShiftingResponse defines model for ShiftingResponse.
+type ShiftingResponse struct {
+	From *time.Time `json:"from,omitempty"`
+
+	// Operational The operational emissions of all the instances, in gCO2eq
+	Operational     *float32          `json:"operational,omitempty"`
+	Recommendations *[]Recommendation `json:"recommendations,omitempty"`
+
+	// Savings gCO2eq
+	Savings *float32   `json:"savings,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+}
+
+// StatementEnvelope A DSSE envelope
+type StatementEnvelope struct {
+	// Payload The base64 encoded statement
+	Payload     *[]byte `json:"payload,omitempty"`
+	PayloadType *string `json:"payloadType,omitempty"`
+	Signatures  *[]struct {
+		// Keyid The hex SHA-256 of the Ed25519 public key
+		Keyid *string `json:"keyid,omitempty"`
+		Sig   *[]byte `json:"sig,omitempty"`
+	} `json:"signatures,omitempty"`
+}
+
+// StatusResponse defines model for StatusResponse.
+type StatusResponse struct {
+	Accounts []ScrapeStatus `json:"accounts"`
+
+	// Next The cursor of the next page, missing on the last one
+	Next *string `json:"next,omitempty"`
+}
+
+// Step defines model for Step.
+type Step struct {
+	Description string  `json:"description"`
+	Formula     string  `json:"formula"`
+	Value       float64 `json:"value"`
+}
+
+// Tier The confidence in the emissions
+type Tier string
+
+// Unit The unit of the emissions, set by the units config. gCO2e by default
+type Unit string
+
+// WattagePoint defines model for WattagePoint.
+type WattagePoint struct {
+	Percentage int     `json:"percentage"`
+	Watts      float64 `json:"watts"`
+}
+
+// Cursor defines model for cursor.
+type Cursor = string
+
+// Limit defines model for limit.
+type Limit = int
+
+// Accepted defines model for Accepted.
+type Accepted = OperationStatus
+
+// BadRequest defines model for BadRequest.
+type BadRequest = Error
+
+// InternalError defines model for InternalError.
+type InternalError = Error
+
+// NotFound defines model for NotFound.
+type NotFound = Error
+
+// Unauthorized defines model for Unauthorized.
+type Unauthorized = Error
+
+// CcfFootprintParams defines parameters for CcfFootprint.
+type CcfFootprintParams struct {
+	// Start The start of the period, as 2006-01-02 or RFC3339. A date starts at midnight in the time zone of the store
+	Start string `form:"start" json:"start"`
+
+	// End The end of the period, as 2006-01-02 or RFC3339. A date is included
+	End string `form:"end" json:"end"`
+
+	// GroupBy The period the estimates are grouped by, the weeks start on Monday
+	GroupBy *CcfFootprintParamsGroupBy `form:"groupBy,omitempty" json:"groupBy,omitempty"`
+}
+
+// CcfFootprintParamsGroupBy defines parameters for CcfFootprint.
+type CcfFootprintParamsGroupBy string
+
+// CostsParams defines parameters for Costs.
+type CostsParams struct {
+	// From The start of the period, as 2006-01-02 or RFC3339. A date starts at midnight in the time zone of the store
+	From string `form:"from" json:"from"`
+
+	// To The end of the period, as 2006-01-02 or RFC3339. A date is included
+	To string `form:"to" json:"to"`
+
+	// GroupBy The field or label the instances are grouped by, provider by default
+	GroupBy *string `form:"groupBy,omitempty" json:"groupBy,omitempty"`
+}
+
+// EfficiencyParams defines parameters for Efficiency.
+type EfficiencyParams struct {
+	// From The start of the period, as 2006-01-02 or RFC3339. A date starts at midnight in the time zone of the store
+	From string `form:"from" json:"from"`
+
+	// To The end of the period, as 2006-01-02 or RFC3339. A date is included
+	To string `form:"to" json:"to"`
+
+	// GroupBy The field or label the instances are grouped by, provider by default, e.g. account or team
+	GroupBy *string `form:"groupBy,omitempty" json:"groupBy,omitempty"`
+}
+
+// QueryRangeParams defines parameters for QueryRange.
+type QueryRangeParams struct {
+	// Q The query, e.g. sum(emissions) by (team) where provider=aws, or avg(intensity) by (region) to chart the grid intensity of the regions. Its range is the default range of the query. Default: sum(emissions)
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// Step The step, e.g. 5m, 1h or 1d
+	Step string `form:"step" json:"step"`
+
+	// Start The start of the range, included, as a unix timestamp, a date or RFC3339. Default: the end minus the range of the query
+	Start *string `form:"start,omitempty" json:"start,omitempty"`
+
+	// End The end of the range, excluded, as a unix timestamp, a date or RFC3339. Default: now
+	End *string `form:"end,omitempty" json:"end,omitempty"`
+}
+
+// EstimateParams defines parameters for Estimate.
+type EstimateParams struct {
+	Provider Provider `form:"provider" json:"provider"`
+
+	// Type The instance type, e.g. m5.xlarge
+	Type   string `form:"type" json:"type"`
+	Region string `form:"region" json:"region"`
+
+	// Duration How long the instance is used, e.g. 15m or 2h
+	Duration string `form:"duration" json:"duration"`
+
+	// Utilization The CPU utilization in percent, 50 by default
+	Utilization *float32 `form:"utilization,omitempty" json:"utilization,omitempty"`
+
+	// Format json, github (name=value lines for $GITHUB_OUTPUT), gitlab (a metrics report) or markdown
+	Format *EstimateParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// EstimateParamsFormat defines parameters for Estimate.
+type EstimateParamsFormat string
+
+// ListInstancesParams defines parameters for ListInstances.
+type ListInstancesParams struct {
+	// Provider Only return the instances with this provider
+	Provider *Provider `form:"provider,omitempty" json:"provider,omitempty"`
+
+	// Service Only return the instances with this service
+	Service *string `form:"service,omitempty" json:"service,omitempty"`
+
+	// Region Only return the instances with this region
+	Region *string `form:"region,omitempty" json:"region,omitempty"`
+
+	// Zone Only return the instances with this zone
+	Zone *string `form:"zone,omitempty" json:"zone,omitempty"`
+
+	// Kind Only return the instances with this kind
+	Kind *string `form:"kind,omitempty" json:"kind,omitempty"`
+
+	// Quality Only return the instances with this lowest quality tier
+	Quality *Tier `form:"quality,omitempty" json:"quality,omitempty"`
+
+	// Label Only return the instances with this label, formatted as key=value. Can be repeated
+	Label *[]string `form:"label,omitempty" json:"label,omitempty"`
+
+	// Sort The order of the instances
+	Sort *ListInstancesParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit The maximum amount of items returned, at most 1000
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The cursor of the page, as returned by the previous one
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListInstancesParamsSort defines parameters for ListInstances.
+type ListInstancesParamsSort string
+
+// GetInstanceParams defines parameters for GetInstance.
+type GetInstanceParams struct {
+	// Provider The provider of the instance, needed when instances of several providers have the name
+	Provider *Provider `form:"provider,omitempty" json:"provider,omitempty"`
+
+	// Account The account of the instance, needed when instances of several accounts have the name
+	Account *string `form:"account,omitempty" json:"account,omitempty"`
+}
+
+// ListJobsParams defines parameters for ListJobs.
+type ListJobsParams struct {
+	// Namespace Only return the jobs of this namespace
+	Namespace *string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// CronJob Only return the jobs created by this CronJob
+	CronJob *string `form:"cronJob,omitempty" json:"cronJob,omitempty"`
+
+	// Limit The maximum amount of items returned, at most 1000
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The cursor of the page, as returned by the previous one
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListPodsParams defines parameters for ListPods.
+type ListPodsParams struct {
+	// Namespace Only return the pods of this namespace
+	Namespace *string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Node Only return the pods running on this node
+	Node *string `form:"node,omitempty" json:"node,omitempty"`
+
+	// Label Only return the pods with this label, formatted as key=value. Can be repeated
+	Label *[]string `form:"label,omitempty" json:"label,omitempty"`
+
+	// Limit The maximum amount of items returned, at most 1000
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The cursor of the page, as returned by the previous one
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// QueryParams defines parameters for Query.
+type QueryParams struct {
+	// Q The query, e.g. sum(emissions) by (team) where provider=aws and range=30d, or avg(intensity) by (region) for the grid carbon intensity the emissions were calculated with. The range is rolling, e.g. 30d or 3mo, or the calendar day, week, month or year to date in the time zone of the store
+	Q string `form:"q" json:"q"`
+
+	// Limit The maximum amount of items returned, at most 1000
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The cursor of the page, as returned by the previous one
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// RightsizingParams defines parameters for Rightsizing.
+type RightsizingParams struct {
+	// Provider The provider of the instances, all of them when missing
+	Provider *Provider `form:"provider,omitempty" json:"provider,omitempty"`
+}
+
+// ShiftingParams defines parameters for Shifting.
+type ShiftingParams struct {
+	// From The start of the period, as 2006-01-02 or RFC3339. A date starts at midnight in the time zone of the store
+	From string `form:"from" json:"from"`
+
+	// To The end of the period, as 2006-01-02 or RFC3339. A date is included
+	To string `form:"to" json:"to"`
+}
+
+// StatementParams defines parameters for Statement.
+type StatementParams struct {
+	// From The start of the period, as 2006-01-02 or RFC3339. A date starts at midnight in the time zone of the store
+	From string `form:"from" json:"from"`
+
+	// To The end of the period, as 2006-01-02 or RFC3339. A date is included
+	To string `form:"to" json:"to"`
+
+	// Account The account the instances are scraped from, all the accounts by default
+	Account *string `form:"account,omitempty" json:"account,omitempty"`
+}
+
+// GetStatusParams defines parameters for GetStatus.
+type GetStatusParams struct {
+	// Provider Only return the accounts of the provider
+	Provider *Provider `form:"provider,omitempty" json:"provider,omitempty"`
+
+	// Limit The maximum amount of items returned, at most 1000
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The cursor of the page, as returned by the previous one
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetExternalMetricParams defines parameters for GetExternalMetric.
+type GetExternalMetricParams struct {
+	// LabelSelector A Kubernetes label selector. grid_intensity requires provider and region, namespace_emissions matches the pod labels and emissions the instance labels
+	LabelSelector *string `form:"labelSelector,omitempty" json:"labelSelector,omitempty"`
+}
+
+// GetExternalMetricParamsMetric defines parameters for GetExternalMetric.
+type GetExternalMetricParamsMetric string
+
+// GraphqlJSONRequestBody defines body for Graphql for application/json ContentType.
+type GraphqlJSONRequestBody = GraphQLRequest
+
+// ImportEmissionsJSONRequestBody defines body for ImportEmissions for application/json ContentType.
+type ImportEmissionsJSONRequestBody = ImportRequest
+
+// AsUnit returns the union data inside the QueryResponse_Unit as a Unit
+func (t QueryResponse_Unit) AsUnit() (Unit, error) {
+	var body Unit
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromUnit overwrites any union data inside the QueryResponse_Unit as the provided Unit
+func (t *QueryResponse_Unit) FromUnit(v Unit) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeUnit performs a merge with any union data inside the QueryResponse_Unit, using the provided Unit
+func (t *QueryResponse_Unit) MergeUnit(v Unit) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsQueryResponseUnit1 returns the union data inside the QueryResponse_Unit as a QueryResponseUnit1
+func (t QueryResponse_Unit) AsQueryResponseUnit1() (QueryResponseUnit1, error) {
+	var body QueryResponseUnit1
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromQueryResponseUnit1 overwrites any union data inside the QueryResponse_Unit as the provided QueryResponseUnit1
+func (t *QueryResponse_Unit) FromQueryResponseUnit1(v QueryResponseUnit1) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeQueryResponseUnit1 performs a merge with any union data inside the QueryResponse_Unit, using the provided QueryResponseUnit1
+func (t *QueryResponse_Unit) MergeQueryResponseUnit1(v QueryResponseUnit1) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t QueryResponse_Unit) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *QueryResponse_Unit) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsUnit returns the union data inside the RangeResponse_Data_Unit as a Unit
+func (t RangeResponse_Data_Unit) AsUnit() (Unit, error) {
+	var body Unit
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromUnit overwrites any union data inside the RangeResponse_Data_Unit as the provided Unit
+func (t *RangeResponse_Data_Unit) FromUnit(v Unit) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeUnit performs a merge with any union data inside the RangeResponse_Data_Unit, using the provided Unit
+func (t *RangeResponse_Data_Unit) MergeUnit(v Unit) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsRangeResponseDataUnit1 returns the union data inside the RangeResponse_Data_Unit as a RangeResponseDataUnit1
+func (t RangeResponse_Data_Unit) AsRangeResponseDataUnit1() (RangeResponseDataUnit1, error) {
+	var body RangeResponseDataUnit1
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromRangeResponseDataUnit1 overwrites any union data inside the RangeResponse_Data_Unit as the provided RangeResponseDataUnit1
+func (t *RangeResponse_Data_Unit) FromRangeResponseDataUnit1(v RangeResponseDataUnit1) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeRangeResponseDataUnit1 performs a merge with any union data inside the RangeResponse_Data_Unit, using the provided RangeResponseDataUnit1
+func (t *RangeResponse_Data_Unit) MergeRangeResponseDataUnit1(v RangeResponseDataUnit1) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t RangeResponse_Data_Unit) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *RangeResponse_Data_Unit) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example. This can contain a path relative
+	// to the server, such as https://api.deepmap.com/dev-test, and all the
+	// paths in the swagger spec will be appended to the server.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A list of callbacks for modifying requests which are generated before sending over
+	// the network.
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = &http.Client{}
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// GetRoot request
+	GetRoot(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CcfFootprint request
+	CcfFootprint(ctx context.Context, params *CcfFootprintParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GraphqlWithBody request with any body
+	GraphqlWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	Graphql(ctx context.Context, body GraphqlJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetOpenAPI request
+	GetOpenAPI(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// FlushCaches request
+	FlushCaches(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// RefreshFactors request
+	RefreshFactors(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ImportEmissionsWithBody request with any body
+	ImportEmissionsWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	ImportEmissions(ctx context.Context, body ImportEmissionsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// TriggerScrape request
+	TriggerScrape(ctx context.Context, provider Provider, account string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Costs request
+	Costs(ctx context.Context, params *CostsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetDatasets request
+	GetDatasets(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetDatasetMisses request
+	GetDatasetMisses(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Efficiency request
+	Efficiency(ctx context.Context, params *EfficiencyParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// QueryRange request
+	QueryRange(ctx context.Context, params *QueryRangeParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Estimate request
+	Estimate(ctx context.Context, params *EstimateParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListInstances request
+	ListInstances(ctx context.Context, params *ListInstancesParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetInstance request
+	GetInstance(ctx context.Context, id string, params *GetInstanceParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListJobs request
+	ListJobs(ctx context.Context, params *ListJobsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetOverhead request
+	GetOverhead(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListPods request
+	ListPods(ctx context.Context, params *ListPodsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Query request
+	Query(ctx context.Context, params *QueryParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Rightsizing request
+	Rightsizing(ctx context.Context, params *RightsizingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Shifting request
+	Shifting(ctx context.Context, params *ShiftingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Statement request
+	Statement(ctx context.Context, params *StatementParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// StatementSchema request
+	StatementSchema(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetStatus request
+	GetStatus(ctx context.Context, params *GetStatusParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListExternalMetrics request
+	ListExternalMetrics(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetExternalMetric request
+	GetExternalMetric(ctx context.Context, namespace string, metric GetExternalMetricParamsMetric, params *GetExternalMetricParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetHealth request
+	GetHealth(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetDashboard request
+	GetDashboard(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) GetRoot(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetRootRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CcfFootprint(ctx context.Context, params *CcfFootprintParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCcfFootprintRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GraphqlWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGraphqlRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Graphql(ctx context.Context, body GraphqlJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGraphqlRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetOpenAPI(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetOpenAPIRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) FlushCaches(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewFlushCachesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) RefreshFactors(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRefreshFactorsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ImportEmissionsWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewImportEmissionsRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ImportEmissions(ctx context.Context, body ImportEmissionsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewImportEmissionsRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TriggerScrape(ctx context.Context, provider Provider, account string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTriggerScrapeRequest(c.Server, provider, account)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Costs(ctx context.Context, params *CostsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCostsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetDatasets(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetDatasetsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetDatasetMisses(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetDatasetMissesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Efficiency(ctx context.Context, params *EfficiencyParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewEfficiencyRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) QueryRange(ctx context.Context, params *QueryRangeParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewQueryRangeRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Estimate(ctx context.Context, params *EstimateParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewEstimateRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListInstances(ctx context.Context, params *ListInstancesParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListInstancesRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetInstance(ctx context.Context, id string, params *GetInstanceParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetInstanceRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListJobs(ctx context.Context, params *ListJobsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListJobsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetOverhead(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetOverheadRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListPods(ctx context.Context, params *ListPodsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListPodsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Query(ctx context.Context, params *QueryParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewQueryRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Rightsizing(ctx context.Context, params *RightsizingParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRightsizingRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Shifting(ctx context.Context, params *ShiftingParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewShiftingRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Statement(ctx context.Context, params *StatementParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStatementRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StatementSchema(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStatementSchemaRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetStatus(ctx context.Context, params *GetStatusParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetStatusRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListExternalMetrics(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListExternalMetricsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetExternalMetric(ctx context.Context, namespace string, metric GetExternalMetricParamsMetric, params *GetExternalMetricParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetExternalMetricRequest(c.Server, namespace, metric, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetHealth(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetHealthRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetDashboard(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetDashboardRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewGetRootRequest generates requests for GetRoot
+func NewGetRootRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewCcfFootprintRequest generates requests for CcfFootprint
+func NewCcfFootprintRequest(server string, params *CcfFootprintParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/ccf/footprint")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "start", runtime.ParamLocationQuery, params.Start); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "end", runtime.ParamLocationQuery, params.End); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.GroupBy != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "groupBy", runtime.ParamLocationQuery, *params.GroupBy); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGraphqlRequest calls the generic Graphql builder with application/json body
+func NewGraphqlRequest(server string, body GraphqlJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewGraphqlRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewGraphqlRequestWithBody generates requests for Graphql with any type of body
+func NewGraphqlRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/graphql")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetOpenAPIRequest generates requests for GetOpenAPI
+func NewGetOpenAPIRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/openapi.json")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewFlushCachesRequest generates requests for FlushCaches
+func NewFlushCachesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/admin/caches/flush")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewRefreshFactorsRequest generates requests for RefreshFactors
+func NewRefreshFactorsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/admin/factors/refresh")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewImportEmissionsRequest calls the generic ImportEmissions builder with application/json body
+func NewImportEmissionsRequest(server string, body ImportEmissionsJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewImportEmissionsRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewImportEmissionsRequestWithBody generates requests for ImportEmissions with any type of body
+func NewImportEmissionsRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/admin/import")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewTriggerScrapeRequest generates requests for TriggerScrape
+func NewTriggerScrapeRequest(server string, provider Provider, account string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "provider", runtime.ParamLocationPath, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "account", runtime.ParamLocationPath, account)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/admin/scrape/%s/%s", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewCostsRequest generates requests for Costs
+func NewCostsRequest(server string, params *CostsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/costs")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "from", runtime.ParamLocationQuery, params.From); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "to", runtime.ParamLocationQuery, params.To); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.GroupBy != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "groupBy", runtime.ParamLocationQuery, *params.GroupBy); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetDatasetsRequest generates requests for GetDatasets
+func NewGetDatasetsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/datasets")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetDatasetMissesRequest generates requests for GetDatasetMisses
+func NewGetDatasetMissesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/datasets/misses")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewEfficiencyRequest generates requests for Efficiency
+func NewEfficiencyRequest(server string, params *EfficiencyParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/efficiency")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "from", runtime.ParamLocationQuery, params.From); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "to", runtime.ParamLocationQuery, params.To); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.GroupBy != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "groupBy", runtime.ParamLocationQuery, *params.GroupBy); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewQueryRangeRequest generates requests for QueryRange
+func NewQueryRangeRequest(server string, params *QueryRangeParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/emissions/range")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Q != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "q", runtime.ParamLocationQuery, *params.Q); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "step", runtime.ParamLocationQuery, params.Step); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.Start != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "start", runtime.ParamLocationQuery, *params.Start); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.End != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "end", runtime.ParamLocationQuery, *params.End); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewEstimateRequest generates requests for Estimate
+func NewEstimateRequest(server string, params *EstimateParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/estimate")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "provider", runtime.ParamLocationQuery, params.Provider); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "type", runtime.ParamLocationQuery, params.Type); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "region", runtime.ParamLocationQuery, params.Region); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "duration", runtime.ParamLocationQuery, params.Duration); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.Utilization != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "utilization", runtime.ParamLocationQuery, *params.Utilization); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Format != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "format", runtime.ParamLocationQuery, *params.Format); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListInstancesRequest generates requests for ListInstances
+func NewListInstancesRequest(server string, params *ListInstancesParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/instances")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Provider != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "provider", runtime.ParamLocationQuery, *params.Provider); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Service != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "service", runtime.ParamLocationQuery, *params.Service); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Region != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "region", runtime.ParamLocationQuery, *params.Region); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Zone != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "zone", runtime.ParamLocationQuery, *params.Zone); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Kind != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "kind", runtime.ParamLocationQuery, *params.Kind); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Quality != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "quality", runtime.ParamLocationQuery, *params.Quality); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Label != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "label", runtime.ParamLocationQuery, *params.Label); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Sort != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "sort", runtime.ParamLocationQuery, *params.Sort); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetInstanceRequest generates requests for GetInstance
+func NewGetInstanceRequest(server string, id string, params *GetInstanceParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/instances/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Provider != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "provider", runtime.ParamLocationQuery, *params.Provider); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Account != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "account", runtime.ParamLocationQuery, *params.Account); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListJobsRequest generates requests for ListJobs
+func NewListJobsRequest(server string, params *ListJobsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/jobs")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Namespace != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "namespace", runtime.ParamLocationQuery, *params.Namespace); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.CronJob != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cronJob", runtime.ParamLocationQuery, *params.CronJob); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetOverheadRequest generates requests for GetOverhead
+func NewGetOverheadRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/overhead")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListPodsRequest generates requests for ListPods
+func NewListPodsRequest(server string, params *ListPodsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/pods")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Namespace != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "namespace", runtime.ParamLocationQuery, *params.Namespace); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Node != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "node", runtime.ParamLocationQuery, *params.Node); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Label != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "label", runtime.ParamLocationQuery, *params.Label); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewQueryRequest generates requests for Query
+func NewQueryRequest(server string, params *QueryParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/query")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "q", runtime.ParamLocationQuery, params.Q); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewRightsizingRequest generates requests for Rightsizing
+func NewRightsizingRequest(server string, params *RightsizingParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/recommendations/rightsizing")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Provider != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "provider", runtime.ParamLocationQuery, *params.Provider); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewShiftingRequest generates requests for Shifting
+func NewShiftingRequest(server string, params *ShiftingParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/shifting")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "from", runtime.ParamLocationQuery, params.From); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "to", runtime.ParamLocationQuery, params.To); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewStatementRequest generates requests for Statement
+func NewStatementRequest(server string, params *StatementParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/statement")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "from", runtime.ParamLocationQuery, params.From); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "to", runtime.ParamLocationQuery, params.To); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.Account != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "account", runtime.ParamLocationQuery, *params.Account); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewStatementSchemaRequest generates requests for StatementSchema
+func NewStatementSchemaRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/statement/schema")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetStatusRequest generates requests for GetStatus
+func NewGetStatusRequest(server string, params *GetStatusParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/status")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Provider != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "provider", runtime.ParamLocationQuery, *params.Provider); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListExternalMetricsRequest generates requests for ListExternalMetrics
+func NewListExternalMetricsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/apis/external.metrics.k8s.io/v1beta1")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetExternalMetricRequest generates requests for GetExternalMetric
+func NewGetExternalMetricRequest(server string, namespace string, metric GetExternalMetricParamsMetric, params *GetExternalMetricParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "metric", runtime.ParamLocationPath, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/apis/external.metrics.k8s.io/v1beta1/namespaces/%s/%s", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.LabelSelector != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "labelSelector", runtime.ParamLocationQuery, *params.LabelSelector); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetHealthRequest generates requests for GetHealth
+func NewGetHealthRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/healthz")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetDashboardRequest generates requests for GetDashboard
+func NewGetDashboardRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/ui/")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// GetRootWithResponse request
+	GetRootWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetRootHTTPResponse, error)
+
+	// CcfFootprintWithResponse request
+	CcfFootprintWithResponse(ctx context.Context, params *CcfFootprintParams, reqEditors ...RequestEditorFn) (*CcfFootprintHTTPResponse, error)
+
+	// GraphqlWithBodyWithResponse request with any body
+	GraphqlWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*GraphqlHTTPResponse, error)
+
+	GraphqlWithResponse(ctx context.Context, body GraphqlJSONRequestBody, reqEditors ...RequestEditorFn) (*GraphqlHTTPResponse, error)
+
+	// GetOpenAPIWithResponse request
+	GetOpenAPIWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetOpenAPIHTTPResponse, error)
+
+	// FlushCachesWithResponse request
+	FlushCachesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*FlushCachesHTTPResponse, error)
+
+	// RefreshFactorsWithResponse request
+	RefreshFactorsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*RefreshFactorsHTTPResponse, error)
+
+	// ImportEmissionsWithBodyWithResponse request with any body
+	ImportEmissionsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ImportEmissionsHTTPResponse, error)
+
+	ImportEmissionsWithResponse(ctx context.Context, body ImportEmissionsJSONRequestBody, reqEditors ...RequestEditorFn) (*ImportEmissionsHTTPResponse, error)
+
+	// TriggerScrapeWithResponse request
+	TriggerScrapeWithResponse(ctx context.Context, provider Provider, account string, reqEditors ...RequestEditorFn) (*TriggerScrapeHTTPResponse, error)
+
+	// CostsWithResponse request
+	CostsWithResponse(ctx context.Context, params *CostsParams, reqEditors ...RequestEditorFn) (*CostsHTTPResponse, error)
+
+	// GetDatasetsWithResponse request
+	GetDatasetsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetDatasetsHTTPResponse, error)
+
+	// GetDatasetMissesWithResponse request
+	GetDatasetMissesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetDatasetMissesHTTPResponse, error)
+
+	// EfficiencyWithResponse request
+	EfficiencyWithResponse(ctx context.Context, params *EfficiencyParams, reqEditors ...RequestEditorFn) (*EfficiencyHTTPResponse, error)
+
+	// QueryRangeWithResponse request
+	QueryRangeWithResponse(ctx context.Context, params *QueryRangeParams, reqEditors ...RequestEditorFn) (*QueryRangeHTTPResponse, error)
+
+	// EstimateWithResponse request
+	EstimateWithResponse(ctx context.Context, params *EstimateParams, reqEditors ...RequestEditorFn) (*EstimateHTTPResponse, error)
+
+	// ListInstancesWithResponse request
+	ListInstancesWithResponse(ctx context.Context, params *ListInstancesParams, reqEditors ...RequestEditorFn) (*ListInstancesHTTPResponse, error)
+
+	// GetInstanceWithResponse request
+	GetInstanceWithResponse(ctx context.Context, id string, params *GetInstanceParams, reqEditors ...RequestEditorFn) (*GetInstanceHTTPResponse, error)
+
+	// ListJobsWithResponse request
+	ListJobsWithResponse(ctx context.Context, params *ListJobsParams, reqEditors ...RequestEditorFn) (*ListJobsHTTPResponse, error)
+
+	// GetOverheadWithResponse request
+	GetOverheadWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetOverheadHTTPResponse, error)
+
+	// ListPodsWithResponse request
+	ListPodsWithResponse(ctx context.Context, params *ListPodsParams, reqEditors ...RequestEditorFn) (*ListPodsHTTPResponse, error)
+
+	// QueryWithResponse request
+	QueryWithResponse(ctx context.Context, params *QueryParams, reqEditors ...RequestEditorFn) (*QueryHTTPResponse, error)
+
+	// RightsizingWithResponse request
+	RightsizingWithResponse(ctx context.Context, params *RightsizingParams, reqEditors ...RequestEditorFn) (*RightsizingHTTPResponse, error)
+
+	// ShiftingWithResponse request
+	ShiftingWithResponse(ctx context.Context, params *ShiftingParams, reqEditors ...RequestEditorFn) (*ShiftingHTTPResponse, error)
+
+	// StatementWithResponse request
+	StatementWithResponse(ctx context.Context, params *StatementParams, reqEditors ...RequestEditorFn) (*StatementHTTPResponse, error)
+
+	// StatementSchemaWithResponse request
+	StatementSchemaWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*StatementSchemaHTTPResponse, error)
+
+	// GetStatusWithResponse request
+	GetStatusWithResponse(ctx context.Context, params *GetStatusParams, reqEditors ...RequestEditorFn) (*GetStatusHTTPResponse, error)
+
+	// ListExternalMetricsWithResponse request
+	ListExternalMetricsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListExternalMetricsHTTPResponse, error)
+
+	// GetExternalMetricWithResponse request
+	GetExternalMetricWithResponse(ctx context.Context, namespace string, metric GetExternalMetricParamsMetric, params *GetExternalMetricParams, reqEditors ...RequestEditorFn) (*GetExternalMetricHTTPResponse, error)
+
+	// GetHealthWithResponse request
+	GetHealthWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetHealthHTTPResponse, error)
+
+	// GetDashboardWithResponse request
+	GetDashboardWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetDashboardHTTPResponse, error)
+}
+
+type GetRootHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r GetRootHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetRootHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CcfFootprintHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]EstimationResult
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r CcfFootprintHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CcfFootprintHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GraphqlHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *struct {
+		Data   *map[string]interface{}   `json:"data,omitempty"`
+		Errors *[]map[string]interface{} `json:"errors,omitempty"`
+	}
+	JSON401 *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r GraphqlHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GraphqlHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetOpenAPIHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *map[string]interface{}
+}
+
+// Status returns HTTPResponse.Status
+func (r GetOpenAPIHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetOpenAPIHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type FlushCachesHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Accepted
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r FlushCachesHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r FlushCachesHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type RefreshFactorsHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Accepted
+	JSON401      *Unauthorized
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r RefreshFactorsHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RefreshFactorsHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ImportEmissionsHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ImportResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r ImportEmissionsHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ImportEmissionsHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type TriggerScrapeHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *Accepted
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+}
+
+// Status returns HTTPResponse.Status
+func (r TriggerScrapeHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r TriggerScrapeHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CostsHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *CostsResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r CostsHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CostsHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetDatasetsHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DatasetsResponse
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r GetDatasetsHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetDatasetsHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetDatasetMissesHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *MissesResponse
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r GetDatasetMissesHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetDatasetMissesHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type EfficiencyHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *EfficiencyResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r EfficiencyHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r EfficiencyHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type QueryRangeHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *RangeResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r QueryRangeHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r QueryRangeHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type EstimateHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Estimate
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r EstimateHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r EstimateHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListInstancesHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *InstancesResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r ListInstancesHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListInstancesHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetInstanceHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Breakdown
+	JSON401      *Unauthorized
+	JSON404      *Error
+	JSON409      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetInstanceHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetInstanceHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListJobsHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *JobsResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r ListJobsHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListJobsHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetOverheadHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Overhead
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r GetOverheadHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetOverheadHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListPodsHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *PodsResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r ListPodsHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListPodsHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type QueryHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *QueryResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r QueryHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r QueryHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type RightsizingHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *RightsizingResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r RightsizingHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RightsizingHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ShiftingHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ShiftingResponse
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r ShiftingHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ShiftingHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type StatementHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *StatementEnvelope
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r StatementHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r StatementHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type StatementSchemaHTTPResponse struct {
+	Body                     []byte
+	HTTPResponse             *http.Response
+	ApplicationschemaJSON200 *map[string]interface{}
+	JSON401                  *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r StatementSchemaHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r StatementSchemaHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetStatusHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *StatusResponse
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r GetStatusHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetStatusHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListExternalMetricsHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *APIResourceList
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r ListExternalMetricsHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListExternalMetricsHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetExternalMetricHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ExternalMetricValueList
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+}
+
+// Status returns HTTPResponse.Status
+func (r GetExternalMetricHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetExternalMetricHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetHealthHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Health
+}
+
+// Status returns HTTPResponse.Status
+func (r GetHealthHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetHealthHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetDashboardHTTPResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON401      *Unauthorized
+}
+
+// Status returns HTTPResponse.Status
+func (r GetDashboardHTTPResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetDashboardHTTPResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// GetRootWithResponse request returning *GetRootHTTPResponse
+func (c *ClientWithResponses) GetRootWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetRootHTTPResponse, error) {
+	rsp, err := c.GetRoot(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetRootHTTPResponse(rsp)
+}
+
+// CcfFootprintWithResponse request returning *CcfFootprintHTTPResponse
+func (c *ClientWithResponses) CcfFootprintWithResponse(ctx context.Context, params *CcfFootprintParams, reqEditors ...RequestEditorFn) (*CcfFootprintHTTPResponse, error) {
+	rsp, err := c.CcfFootprint(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCcfFootprintHTTPResponse(rsp)
+}
+
+// GraphqlWithBodyWithResponse request with arbitrary body returning *GraphqlHTTPResponse
+func (c *ClientWithResponses) GraphqlWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*GraphqlHTTPResponse, error) {
+	rsp, err := c.GraphqlWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGraphqlHTTPResponse(rsp)
+}
+
+func (c *ClientWithResponses) GraphqlWithResponse(ctx context.Context, body GraphqlJSONRequestBody, reqEditors ...RequestEditorFn) (*GraphqlHTTPResponse, error) {
+	rsp, err := c.Graphql(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGraphqlHTTPResponse(rsp)
+}
+
+// GetOpenAPIWithResponse request returning *GetOpenAPIHTTPResponse
+func (c *ClientWithResponses) GetOpenAPIWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetOpenAPIHTTPResponse, error) {
+	rsp, err := c.GetOpenAPI(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetOpenAPIHTTPResponse(rsp)
+}
+
+// FlushCachesWithResponse request returning *FlushCachesHTTPResponse
+func (c *ClientWithResponses) FlushCachesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*FlushCachesHTTPResponse, error) {
+	rsp, err := c.FlushCaches(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFlushCachesHTTPResponse(rsp)
+}
+
+// RefreshFactorsWithResponse request returning *RefreshFactorsHTTPResponse
+func (c *ClientWithResponses) RefreshFactorsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*RefreshFactorsHTTPResponse, error) {
+	rsp, err := c.RefreshFactors(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRefreshFactorsHTTPResponse(rsp)
+}
+
+// ImportEmissionsWithBodyWithResponse request with arbitrary body returning *ImportEmissionsHTTPResponse
+func (c *ClientWithResponses) ImportEmissionsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ImportEmissionsHTTPResponse, error) {
+	rsp, err := c.ImportEmissionsWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseImportEmissionsHTTPResponse(rsp)
+}
+
+func (c *ClientWithResponses) ImportEmissionsWithResponse(ctx context.Context, body ImportEmissionsJSONRequestBody, reqEditors ...RequestEditorFn) (*ImportEmissionsHTTPResponse, error) {
+	rsp, err := c.ImportEmissions(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseImportEmissionsHTTPResponse(rsp)
+}
+
+// TriggerScrapeWithResponse request returning *TriggerScrapeHTTPResponse
+func (c *ClientWithResponses) TriggerScrapeWithResponse(ctx context.Context, provider Provider, account string, reqEditors ...RequestEditorFn) (*TriggerScrapeHTTPResponse, error) {
+	rsp, err := c.TriggerScrape(ctx, provider, account, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTriggerScrapeHTTPResponse(rsp)
+}
+
+// CostsWithResponse request returning *CostsHTTPResponse
+func (c *ClientWithResponses) CostsWithResponse(ctx context.Context, params *CostsParams, reqEditors ...RequestEditorFn) (*CostsHTTPResponse, error) {
+	rsp, err := c.Costs(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCostsHTTPResponse(rsp)
+}
+
+// GetDatasetsWithResponse request returning *GetDatasetsHTTPResponse
+func (c *ClientWithResponses) GetDatasetsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetDatasetsHTTPResponse, error) {
+	rsp, err := c.GetDatasets(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetDatasetsHTTPResponse(rsp)
+}
+
+// GetDatasetMissesWithResponse request returning *GetDatasetMissesHTTPResponse
+func (c *ClientWithResponses) GetDatasetMissesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetDatasetMissesHTTPResponse, error) {
+	rsp, err := c.GetDatasetMisses(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetDatasetMissesHTTPResponse(rsp)
+}
+
+// EfficiencyWithResponse request returning *EfficiencyHTTPResponse
+func (c *ClientWithResponses) EfficiencyWithResponse(ctx context.Context, params *EfficiencyParams, reqEditors ...RequestEditorFn) (*EfficiencyHTTPResponse, error) {
+	rsp, err := c.Efficiency(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseEfficiencyHTTPResponse(rsp)
+}
+
+// QueryRangeWithResponse request returning *QueryRangeHTTPResponse
+func (c *ClientWithResponses) QueryRangeWithResponse(ctx context.Context, params *QueryRangeParams, reqEditors ...RequestEditorFn) (*QueryRangeHTTPResponse, error) {
+	rsp, err := c.QueryRange(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseQueryRangeHTTPResponse(rsp)
+}
+
+// EstimateWithResponse request returning *EstimateHTTPResponse
+func (c *ClientWithResponses) EstimateWithResponse(ctx context.Context, params *EstimateParams, reqEditors ...RequestEditorFn) (*EstimateHTTPResponse, error) {
+	rsp, err := c.Estimate(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseEstimateHTTPResponse(rsp)
+}
+
+// ListInstancesWithResponse request returning *ListInstancesHTTPResponse
+func (c *ClientWithResponses) ListInstancesWithResponse(ctx context.Context, params *ListInstancesParams, reqEditors ...RequestEditorFn) (*ListInstancesHTTPResponse, error) {
+	rsp, err := c.ListInstances(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListInstancesHTTPResponse(rsp)
+}
+
+// GetInstanceWithResponse request returning *GetInstanceHTTPResponse
+func (c *ClientWithResponses) GetInstanceWithResponse(ctx context.Context, id string, params *GetInstanceParams, reqEditors ...RequestEditorFn) (*GetInstanceHTTPResponse, error) {
+	rsp, err := c.GetInstance(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetInstanceHTTPResponse(rsp)
+}
+
+// ListJobsWithResponse request returning *ListJobsHTTPResponse
+func (c *ClientWithResponses) ListJobsWithResponse(ctx context.Context, params *ListJobsParams, reqEditors ...RequestEditorFn) (*ListJobsHTTPResponse, error) {
+	rsp, err := c.ListJobs(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListJobsHTTPResponse(rsp)
+}
+
+// GetOverheadWithResponse request returning *GetOverheadHTTPResponse
+func (c *ClientWithResponses) GetOverheadWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetOverheadHTTPResponse, error) {
+	rsp, err := c.GetOverhead(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetOverheadHTTPResponse(rsp)
+}
+
+// ListPodsWithResponse request returning *ListPodsHTTPResponse
+func (c *ClientWithResponses) ListPodsWithResponse(ctx context.Context, params *ListPodsParams, reqEditors ...RequestEditorFn) (*ListPodsHTTPResponse, error) {
+	rsp, err := c.ListPods(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListPodsHTTPResponse(rsp)
+}
+
+// QueryWithResponse request returning *QueryHTTPResponse
+func (c *ClientWithResponses) QueryWithResponse(ctx context.Context, params *QueryParams, reqEditors ...RequestEditorFn) (*QueryHTTPResponse, error) {
+	rsp, err := c.Query(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseQueryHTTPResponse(rsp)
+}
+
+// RightsizingWithResponse request returning *RightsizingHTTPResponse
+func (c *ClientWithResponses) RightsizingWithResponse(ctx context.Context, params *RightsizingParams, reqEditors ...RequestEditorFn) (*RightsizingHTTPResponse, error) {
+	rsp, err := c.Rightsizing(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRightsizingHTTPResponse(rsp)
+}
+
+// ShiftingWithResponse request returning *ShiftingHTTPResponse
+func (c *ClientWithResponses) ShiftingWithResponse(ctx context.Context, params *ShiftingParams, reqEditors ...RequestEditorFn) (*ShiftingHTTPResponse, error) {
+	rsp, err := c.Shifting(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseShiftingHTTPResponse(rsp)
+}
+
+// StatementWithResponse request returning *StatementHTTPResponse
+func (c *ClientWithResponses) StatementWithResponse(ctx context.Context, params *StatementParams, reqEditors ...RequestEditorFn) (*StatementHTTPResponse, error) {
+	rsp, err := c.Statement(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatementHTTPResponse(rsp)
+}
+
+// StatementSchemaWithResponse request returning *StatementSchemaHTTPResponse
+func (c *ClientWithResponses) StatementSchemaWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*StatementSchemaHTTPResponse, error) {
+	rsp, err := c.StatementSchema(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatementSchemaHTTPResponse(rsp)
+}
+
+// GetStatusWithResponse request returning *GetStatusHTTPResponse
+func (c *ClientWithResponses) GetStatusWithResponse(ctx context.Context, params *GetStatusParams, reqEditors ...RequestEditorFn) (*GetStatusHTTPResponse, error) {
+	rsp, err := c.GetStatus(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetStatusHTTPResponse(rsp)
+}
+
+// ListExternalMetricsWithResponse request returning *ListExternalMetricsHTTPResponse
+func (c *ClientWithResponses) ListExternalMetricsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListExternalMetricsHTTPResponse, error) {
+	rsp, err := c.ListExternalMetrics(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListExternalMetricsHTTPResponse(rsp)
+}
+
+// GetExternalMetricWithResponse request returning *GetExternalMetricHTTPResponse
+func (c *ClientWithResponses) GetExternalMetricWithResponse(ctx context.Context, namespace string, metric GetExternalMetricParamsMetric, params *GetExternalMetricParams, reqEditors ...RequestEditorFn) (*GetExternalMetricHTTPResponse, error) {
+	rsp, err := c.GetExternalMetric(ctx, namespace, metric, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetExternalMetricHTTPResponse(rsp)
+}
+
+// GetHealthWithResponse request returning *GetHealthHTTPResponse
+func (c *ClientWithResponses) GetHealthWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetHealthHTTPResponse, error) {
+	rsp, err := c.GetHealth(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetHealthHTTPResponse(rsp)
+}
+
+// GetDashboardWithResponse request returning *GetDashboardHTTPResponse
+func (c *ClientWithResponses) GetDashboardWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetDashboardHTTPResponse, error) {
+	rsp, err := c.GetDashboard(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetDashboardHTTPResponse(rsp)
+}
+
+// ParseGetRootHTTPResponse parses an HTTP response from a GetRootWithResponse call
+func ParseGetRootHTTPResponse(rsp *http.Response) (*GetRootHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetRootHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
+// ParseCcfFootprintHTTPResponse parses an HTTP response from a CcfFootprintWithResponse call
+func ParseCcfFootprintHTTPResponse(rsp *http.Response) (*CcfFootprintHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CcfFootprintHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []EstimationResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGraphqlHTTPResponse parses an HTTP response from a GraphqlWithResponse call
+func ParseGraphqlHTTPResponse(rsp *http.Response) (*GraphqlHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GraphqlHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest struct {
+			Data   *map[string]interface{}   `json:"data,omitempty"`
+			Errors *[]map[string]interface{} `json:"errors,omitempty"`
+		}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetOpenAPIHTTPResponse parses an HTTP response from a GetOpenAPIWithResponse call
+func ParseGetOpenAPIHTTPResponse(rsp *http.Response) (*GetOpenAPIHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetOpenAPIHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest map[string]interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseFlushCachesHTTPResponse parses an HTTP response from a FlushCachesWithResponse call
+func ParseFlushCachesHTTPResponse(rsp *http.Response) (*FlushCachesHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &FlushCachesHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Accepted
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseRefreshFactorsHTTPResponse parses an HTTP response from a RefreshFactorsWithResponse call
+func ParseRefreshFactorsHTTPResponse(rsp *http.Response) (*RefreshFactorsHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RefreshFactorsHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Accepted
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseImportEmissionsHTTPResponse parses an HTTP response from a ImportEmissionsWithResponse call
+func ParseImportEmissionsHTTPResponse(rsp *http.Response) (*ImportEmissionsHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ImportEmissionsHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ImportResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseTriggerScrapeHTTPResponse parses an HTTP response from a TriggerScrapeWithResponse call
+func ParseTriggerScrapeHTTPResponse(rsp *http.Response) (*TriggerScrapeHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TriggerScrapeHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest Accepted
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCostsHTTPResponse parses an HTTP response from a CostsWithResponse call
+func ParseCostsHTTPResponse(rsp *http.Response) (*CostsHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CostsHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest CostsResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetDatasetsHTTPResponse parses an HTTP response from a GetDatasetsWithResponse call
+func ParseGetDatasetsHTTPResponse(rsp *http.Response) (*GetDatasetsHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetDatasetsHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest DatasetsResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetDatasetMissesHTTPResponse parses an HTTP response from a GetDatasetMissesWithResponse call
+func ParseGetDatasetMissesHTTPResponse(rsp *http.Response) (*GetDatasetMissesHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetDatasetMissesHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest MissesResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseEfficiencyHTTPResponse parses an HTTP response from a EfficiencyWithResponse call
+func ParseEfficiencyHTTPResponse(rsp *http.Response) (*EfficiencyHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &EfficiencyHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest EfficiencyResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseQueryRangeHTTPResponse parses an HTTP response from a QueryRangeWithResponse call
+func ParseQueryRangeHTTPResponse(rsp *http.Response) (*QueryRangeHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &QueryRangeHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest RangeResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseEstimateHTTPResponse parses an HTTP response from a EstimateWithResponse call
+func ParseEstimateHTTPResponse(rsp *http.Response) (*EstimateHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &EstimateHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Estimate
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	case rsp.StatusCode == 200:
+		// Content-type (text/plain) unsupported
+
+	}
+
+	return response, nil
+}
+
+// ParseListInstancesHTTPResponse parses an HTTP response from a ListInstancesWithResponse call
+func ParseListInstancesHTTPResponse(rsp *http.Response) (*ListInstancesHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListInstancesHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest InstancesResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetInstanceHTTPResponse parses an HTTP response from a GetInstanceWithResponse call
+func ParseGetInstanceHTTPResponse(rsp *http.Response) (*GetInstanceHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetInstanceHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Breakdown
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListJobsHTTPResponse parses an HTTP response from a ListJobsWithResponse call
+func ParseListJobsHTTPResponse(rsp *http.Response) (*ListJobsHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListJobsHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest JobsResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetOverheadHTTPResponse parses an HTTP response from a GetOverheadWithResponse call
+func ParseGetOverheadHTTPResponse(rsp *http.Response) (*GetOverheadHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetOverheadHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Overhead
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListPodsHTTPResponse parses an HTTP response from a ListPodsWithResponse call
+func ParseListPodsHTTPResponse(rsp *http.Response) (*ListPodsHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListPodsHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest PodsResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseQueryHTTPResponse parses an HTTP response from a QueryWithResponse call
+func ParseQueryHTTPResponse(rsp *http.Response) (*QueryHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &QueryHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest QueryResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseRightsizingHTTPResponse parses an HTTP response from a RightsizingWithResponse call
+func ParseRightsizingHTTPResponse(rsp *http.Response) (*RightsizingHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RightsizingHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest RightsizingResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseShiftingHTTPResponse parses an HTTP response from a ShiftingWithResponse call
+func ParseShiftingHTTPResponse(rsp *http.Response) (*ShiftingHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ShiftingHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ShiftingResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseStatementHTTPResponse parses an HTTP response from a StatementWithResponse call
+func ParseStatementHTTPResponse(rsp *http.Response) (*StatementHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StatementHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest StatementEnvelope
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseStatementSchemaHTTPResponse parses an HTTP response from a StatementSchemaWithResponse call
+func ParseStatementSchemaHTTPResponse(rsp *http.Response) (*StatementSchemaHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StatementSchemaHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest map[string]interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.ApplicationschemaJSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetStatusHTTPResponse parses an HTTP response from a GetStatusWithResponse call
+func ParseGetStatusHTTPResponse(rsp *http.Response) (*GetStatusHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetStatusHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest StatusResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListExternalMetricsHTTPResponse parses an HTTP response from a ListExternalMetricsWithResponse call
+func ParseListExternalMetricsHTTPResponse(rsp *http.Response) (*ListExternalMetricsHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListExternalMetricsHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest APIResourceList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetExternalMetricHTTPResponse parses an HTTP response from a GetExternalMetricWithResponse call
+func ParseGetExternalMetricHTTPResponse(rsp *http.Response) (*GetExternalMetricHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetExternalMetricHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ExternalMetricValueList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetHealthHTTPResponse parses an HTTP response from a GetHealthWithResponse call
+func ParseGetHealthHTTPResponse(rsp *http.Response) (*GetHealthHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetHealthHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Health
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetDashboardHTTPResponse parses an HTTP response from a GetDashboardWithResponse call
+func ParseGetDashboardHTTPResponse(rsp *http.Response) (*GetDashboardHTTPResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetDashboardHTTPResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
@@ -22,6 +22,9 @@ var (
 
 	// The last time the config was updated/reloaded
 	UpdatedAt time.Time
+
+	// Functions called after the config was reloaded
+	subscribers []func(*ApplicationConfig)
 )
 
 func InitConfig(ctx context.Context) {
@@ -35,7 +38,56 @@ func InitConfig(ctx context.Context) {
 
 	// Set defaults
 	viper.SetDefault("api.metricsPath", "/metrics")
+	viper.SetDefault("api.ui", true)
+	viper.SetDefault("api.debug.address", "127.0.0.1")
+	viper.SetDefault("api.debug.port", "6060")
 	viper.SetDefault("logLevel", "info")
+	viper.SetDefault("providersConfig.retry.maxAttempts", 3)
+	viper.SetDefault("providersConfig.retry.initialBackoff", "1s")
+	viper.SetDefault("providersConfig.retry.maxBackoff", "30s")
+	viper.SetDefault("providersConfig.circuitBreaker.failureThreshold", 5)
+	viper.SetDefault("providersConfig.circuitBreaker.cooldown", "5m")
+	viper.SetDefault("providersConfig.workers", 10)
+	viper.SetDefault("providersConfig.mode", "schedule")
+	viper.SetDefault("providersConfig.checkInterval", "1h")
+	viper.SetDefault("sharding.index", -1)
+	viper.SetDefault("store.retention", "720h")
+	viper.SetDefault("store.redis.stream", "aether:emissions")
+	viper.SetDefault("operator.resyncInterval", "1m")
+	viper.SetDefault("attribution.interval", "1m")
+	viper.SetDefault("attribution.cluster", "default")
+	viper.SetDefault("attribution.overhead.namespaces", []string{"kube-system"})
+	viper.SetDefault("attribution.inventory.lifespan", 6)
+	viper.SetDefault("nodeLabels.interval", "10m")
+	viper.SetDefault("costs.interval", "1h")
+	viper.SetDefault("costs.window", "168h")
+	viper.SetDefault("functionalUnits.interval", "5m")
+	viper.SetDefault("functionalUnits.window", "1h")
+	viper.SetDefault("saas.interval", "1h")
+	viper.SetDefault("saas.lookback", "168h")
+	viper.SetDefault("saas.delay", "6h")
+	viper.SetDefault("rightsizing.interval", "24h")
+	viper.SetDefault("reports.interval", "1h")
+	viper.SetDefault("notifications.interval", "5m")
+	viper.SetDefault("notifications.anomalies.groupBy", "provider")
+	viper.SetDefault("notifications.anomalies.window", "168h")
+	viper.SetDefault("notifications.anomalies.threshold", 3)
+	viper.SetDefault("notifications.summary.weekday", "monday")
+	viper.SetDefault("notifications.summary.hour", 9)
+	viper.SetDefault("notifications.summary.groupBy", "provider")
+	viper.SetDefault("notifications.alertmanager.staleness", "1h")
+	viper.SetDefault("exporters.mode", "both")
+	viper.SetDefault("exporters.instances.overflow", "drop")
+	viper.SetDefault("exporters.instances.hashLabels", []string{"name"})
+	viper.SetDefault("exporters.instances.hashBuckets", 100)
+	viper.SetDefault("exporters.pods.overflow", "drop")
+	viper.SetDefault("exporters.pods.hashLabels", []string{"pod"})
+	viper.SetDefault("exporters.pods.hashBuckets", 100)
+	viper.SetDefault("factors.gridFallback", []string{"country", "continent", "global"})
+	viper.SetDefault("providersConfig.catchUp.maxLookback", "6h")
+	viper.SetDefault("providersConfig.catchUp.stateFile", "/tmp/aether/checkpoints.json")
+	viper.SetDefault("providersConfig.specs.path", "/tmp/aether/specs.json")
+	viper.SetDefault("providersConfig.specs.expiration", "168h")
 
 	// Find and read the config file
 	err := viper.ReadInConfig()
@@ -60,18 +112,30 @@ func InitConfig(ctx context.Context) {
 		// Parse the config file
 		config = parseApplicationConfig(ctx)
 
+		// Copy the subscribers while holding the lock
+		subs := append([]func(*ApplicationConfig){}, subscribers...)
+		current := config
+
 		// Unlock so that the file can be accessed again
 		lock.Unlock()
 
 		// Log the fact that the new config file was reloaded
 		logger.Info("config file reloaded", "file", fmt.Sprintf("%s.yaml", getEnvConfig()))
+
+		// Notify the subscribers about the new config
+		for _, sub := range subs {
+			sub(current)
+		}
 	})
 
 	// Setup a watch in case the config file is changed
 	viper.WatchConfig()
 }
 
-// AppConfig returns the app config
+// AppConfig returns the app config loaded by InitConfig, nil before. It's
+// only read by the commands, the packages are given the config they need
+// by their constructors so that they can be tested and embedded without a
+// config file
 func AppConfig() *ApplicationConfig {
 	// Make sure we lock, because there could be a write happening
 	lock.Lock()
@@ -83,6 +147,15 @@ func AppConfig() *ApplicationConfig {
 	return config
 }
 
+// OnChange registers a function which is called with the new config
+// every time the config file is reloaded
+func OnChange(fn func(*ApplicationConfig)) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	subscribers = append(subscribers, fn)
+}
+
 // ParseApplicationConfig reads the config file into a struct
 func parseApplicationConfig(ctx context.Context) *ApplicationConfig {
 	logger := log.FromContext(ctx)
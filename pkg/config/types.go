@@ -12,7 +12,676 @@ type ApplicationConfig struct {
 	Proxy           ProxyConfig `mapstructure:"proxy"`
 	ProvidersConfig `mapstructure:"providersConfig"`
 	Providers       map[v1.Provider]Provider `mapstructure:"providers"`
+	Plugins         []PluginConfig           `mapstructure:"plugins"`
 	LogLevel        string                   `mapstructure:"logLevel"`
+	Sharding        ShardingConfig           `mapstructure:"sharding"`
+	Store           StoreConfig              `mapstructure:"store"`
+	Operator        OperatorConfig           `mapstructure:"operator"`
+	Attribution     AttributionConfig        `mapstructure:"attribution"`
+	NodeLabels      NodeLabelsConfig         `mapstructure:"nodeLabels"`
+	Costs           CostsConfig              `mapstructure:"costs"`
+	Shifting        ShiftingConfig           `mapstructure:"shifting"`
+	Recording       RecordingConfig          `mapstructure:"recording"`
+	Misses          MissesConfig             `mapstructure:"misses"`
+	Units           UnitsConfig              `mapstructure:"units"`
+	Grouping        GroupingConfig           `mapstructure:"grouping"`
+	Deduplication   DeduplicationConfig      `mapstructure:"deduplication"`
+	Factors         FactorsConfig            `mapstructure:"factors"`
+	FunctionalUnits FunctionalUnitsConfig    `mapstructure:"functionalUnits"`
+	SaaS            SaaSConfig               `mapstructure:"saas"`
+	Rightsizing     RightsizingConfig        `mapstructure:"rightsizing"`
+	Reports         ReportsConfig            `mapstructure:"reports"`
+	Notifications   NotificationsConfig      `mapstructure:"notifications"`
+	Exporters       ExportersConfig          `mapstructure:"exporters"`
+}
+
+// Defines the labels and the amount of series of the Prometheus metrics, so
+// that the estates with many instances don't overwhelm Prometheus
+type ExportersConfig struct {
+	// How the emissions of the instances are exported: gauge, the emissions
+	// of the last scraping interval, counter, the emissions since the start
+	// as the emissions_total and embodied_total counters, or both
+	// Default: both
+	Mode string `mapstructure:"mode"`
+
+	// How long the series of the instances that are no longer scraped, e.g.
+	// deleted ones, are exported for. Not positive keeps them forever
+	// Default: 3 times the scraping interval
+	Staleness time.Duration `mapstructure:"staleness"`
+
+	// The emissions and embodied metrics of the instances
+	Instances CardinalityConfig `mapstructure:"instances"`
+
+	// The pod_emissions metric of the attribution
+	Pods CardinalityConfig `mapstructure:"pods"`
+}
+
+// Defines the labels of the series of an exporter and how many are exported
+type CardinalityConfig struct {
+	// The labels exported, all of them when empty. The labels the metric is
+	// identified by, e.g. the provider and the unit, are always exported
+	Allow []string `mapstructure:"allow"`
+
+	// The labels not exported
+	Deny []string `mapstructure:"deny"`
+
+	// The most series exported, the new ones past it overflow
+	// Default: 0, no limit
+	MaxSeries int `mapstructure:"maxSeries"`
+
+	// What happens to the series past the limit: drop, or hash to replace
+	// the values of the hashed labels with one of the buckets, the series of
+	// a bucket are summed
+	// Default: drop
+	Overflow string `mapstructure:"overflow"`
+
+	// The labels hashed past the limit, the ones with the most values
+	// Default: name for the instances, pod for the pods
+	HashLabels []string `mapstructure:"hashLabels"`
+
+	// How many values the hashed labels are reduced to
+	// Default: 100
+	HashBuckets int `mapstructure:"hashBuckets"`
+}
+
+// Defines the functional units the emissions of the applications are divided
+// by, e.g. their requests, read from the metrics of the applications
+type FunctionalUnitsConfig struct {
+	// How often the throughput of the applications is queried
+	// Default: 5m
+	Interval time.Duration `mapstructure:"interval"`
+
+	// How far back the emissions and the throughput are summed
+	// Default: 1h
+	Window time.Duration `mapstructure:"window"`
+
+	// The functional units of the applications
+	Units []FunctionalUnit `mapstructure:"units"`
+}
+
+// Defines the throughput of an application, from either Prometheus or
+// CloudWatch
+type FunctionalUnit struct {
+	// The application label of the instances, set by the grouping rules
+	Application string `mapstructure:"application"`
+
+	// The name of the unit, e.g. request or transaction
+	// Default: request
+	Unit string `mapstructure:"unit"`
+
+	Prometheus PrometheusThroughput `mapstructure:"prometheus"`
+	CloudWatch CloudWatchThroughput `mapstructure:"cloudWatch"`
+}
+
+// Defines a Prometheus query returning the throughput of an application
+type PrometheusThroughput struct {
+	// The URL of the Prometheus server, e.g. http://prometheus:9090
+	URL string `mapstructure:"url"`
+
+	// The instant query, $window is replaced by the window, e.g.
+	// sum(increase(http_requests_total{app="shop"}[$window]))
+	Query string `mapstructure:"query"`
+}
+
+// Defines a CloudWatch metric whose sum is the throughput of an application
+type CloudWatchThroughput struct {
+	Region     string                `mapstructure:"region"`
+	Namespace  string                `mapstructure:"namespace"`
+	MetricName string                `mapstructure:"metricName"`
+	Dimensions []CloudWatchDimension `mapstructure:"dimensions"`
+}
+
+// Defines a dimension of a CloudWatch metric
+type CloudWatchDimension struct {
+	Name  string `mapstructure:"name"`
+	Value string `mapstructure:"value"`
+}
+
+// Defines where the emission factors are pulled from
+type FactorsConfig struct {
+	// The git repo of the emission factors, e.g. a mirror reachable without
+	// internet egress
+	// Default: https://github.com/re-cinq/emissions-data/
+	Repository string `mapstructure:"repository"`
+
+	// The averages the grid intensity of the regions missing from the
+	// emission factors falls back on, in order: country, continent and
+	// global. The instances of the regions without grid intensity are
+	// dropped when empty
+	// Default: [country, continent, global]
+	GridFallback []string `mapstructure:"gridFallback"`
+}
+
+// Defines how the instances reported by several collectors, e.g. a node
+// reported by the cloud provider and by an in-cluster agent, are counted once
+type DeduplicationConfig struct {
+	// Whether the instances are deduplicated
+	// Default: false
+	Enabled bool `mapstructure:"enabled"`
+
+	// The providers of the collectors, the first one reporting an instance
+	// takes precedence over the next ones. The providers not listed come
+	// last, in the order they're seen
+	Precedence []string `mapstructure:"precedence"`
+}
+
+// Defines how the application and team labels of the instances are
+// derived, so that the emissions of every provider can be grouped by them
+type GroupingConfig struct {
+	// The rules are matched in order, the first matching rule sets the
+	// application and the first one sets the team
+	Rules []GroupingRule `mapstructure:"rules"`
+}
+
+// Defines a rule deriving the application or the team of the instances
+type GroupingRule struct {
+	// The field matched: name, provider, service, region, zone or kind, or
+	// a label, e.g. tag_team for the team tag of the instance. The name is
+	// the Name tag of the EC2 instances and the name of the GCE instances
+	Source string `mapstructure:"source"`
+
+	// The anchored regex the value is matched with, "(.+)" by default
+	Regex string `mapstructure:"regex"`
+
+	// The application and team set when the value matches, they can refer
+	// to the groups of the regex, e.g. $1
+	Application string `mapstructure:"application"`
+	Team        string `mapstructure:"team"`
+}
+
+// Defines the unit and the precision of the emissions in the metrics and
+// the API responses, the emissions are calculated in gCO2e
+type UnitsConfig struct {
+	OutputConfig `mapstructure:",squash"`
+
+	// Overrides the unit or the precision of the metrics
+	Metrics OutputConfig `mapstructure:"metrics"`
+
+	// Overrides the unit or the precision of the API responses
+	API OutputConfig `mapstructure:"api"`
+}
+
+// Defines how the emissions are output
+type OutputConfig struct {
+	// The unit: gCO2e, kgCO2e or tCO2e
+	// Default: gCO2e
+	Unit string `mapstructure:"unit"`
+
+	// The decimal places the emissions are rounded to
+	// Default: not rounded
+	Precision *int `mapstructure:"precision"`
+}
+
+// MetricsOutput returns how the emissions are output in the metrics
+func (c *UnitsConfig) MetricsOutput() OutputConfig {
+	return c.OutputConfig.override(&c.Metrics)
+}
+
+// APIOutput returns how the emissions are output in the API responses
+func (c *UnitsConfig) APIOutput() OutputConfig {
+	return c.OutputConfig.override(&c.API)
+}
+
+// override returns the output with the fields set by the other one
+func (c OutputConfig) override(other *OutputConfig) OutputConfig {
+	if other.Unit != "" {
+		c.Unit = other.Unit
+	}
+	if other.Precision != nil {
+		c.Precision = other.Precision
+	}
+	return c
+}
+
+// Defines how the instance kinds and regions missing from the emission
+// factors are reported
+type MissesConfig struct {
+	// The URL the misses are posted to as JSON when first seen, e.g. to open
+	// an issue on the emissions data repo
+	// Nothing is posted when empty
+	Webhook string `mapstructure:"webhook"`
+}
+
+// Defines how the instances collected by the scrapers are recorded, and
+// replayed instead of scraping the providers
+type RecordingConfig struct {
+	// The file the collected instances are appended to, or the URL of the
+	// S3 or Cloud Storage prefix they're uploaded to, e.g. s3://bucket/prefix
+	// Nothing is recorded when empty
+	Record string `mapstructure:"record"`
+
+	// The recording published instead of scraping the providers, over and
+	// over, one scrape per interval. A file or an object storage prefix
+	// The providers are scraped when empty
+	Replay string `mapstructure:"replay"`
+}
+
+// Defines how the savings of shifting the workloads to the greener hours of
+// the day are estimated
+type ShiftingConfig struct {
+	// The CSV file of the grid intensity of the regions by UTC hour of the
+	// day, with a region, hour and intensity header
+	// The savings aren't estimated when empty
+	Profiles string `mapstructure:"profiles"`
+}
+
+// Defines how the spend of the instances is read from the billing exports
+// and joined with their emissions
+type CostsConfig struct {
+	// Whether the spend is joined with the emissions
+	Enabled bool `mapstructure:"enabled"`
+
+	// How often the billing exports are read again
+	Interval time.Duration `mapstructure:"interval"`
+
+	// How far back the exported gCO2eq per dollar looks
+	Window time.Duration `mapstructure:"window"`
+
+	// The billing exports
+	Sources []CostSource `mapstructure:"sources"`
+}
+
+// CostSource is a set of billing export files
+type CostSource struct {
+	// The format of the files: cur for the AWS Cost and Usage Reports or
+	// gcp-billing for the GCP billing exports
+	Format string `mapstructure:"format"`
+
+	// The files, a glob pattern, gzipped files end with .gz
+	Path string `mapstructure:"path"`
+}
+
+// Defines how the rightsizing recommendations of the instances are pulled
+// from AWS Compute Optimizer and the GCP Recommender for the accounts of the
+// providers
+type RightsizingConfig struct {
+	// Whether the recommendations are pulled
+	Enabled bool `mapstructure:"enabled"`
+
+	// How often the recommendations are pulled
+	// Default: 24h
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Defines the executive summaries rendered at the end of every period from
+// the stored emissions
+type ReportsConfig struct {
+	// How often the schedules are checked for a period ended
+	// Default: 1h
+	Interval time.Duration `mapstructure:"interval"`
+
+	// The branding of the summaries
+	Brand ReportBrand `mapstructure:"brand"`
+
+	// The summaries rendered, none when empty
+	Schedules []ReportSchedule `mapstructure:"schedules"`
+}
+
+// Defines the branding of the executive summaries
+type ReportBrand struct {
+	// The organization named in the header
+	Organization string `mapstructure:"organization"`
+
+	// The PNG or JPEG logo of the header
+	Logo string `mapstructure:"logo"`
+
+	// The color of the header and the charts, as #rrggbb
+	// Default: #2e7d32
+	Color string `mapstructure:"color"`
+}
+
+// Defines an executive summary rendered at the end of every period
+type ReportSchedule struct {
+	// The name of the files, followed by the first day of the period
+	Name string `mapstructure:"name"`
+
+	// The period covered: day, week or month. The weeks start on Monday in
+	// the time zone of the store
+	Period string `mapstructure:"period"`
+
+	// The field or label the emissions are grouped by
+	// Default: provider
+	GroupBy string `mapstructure:"groupBy"`
+
+	// How many groups are listed, the others are summed
+	// Default: 5
+	Top int `mapstructure:"top"`
+
+	// The format of the files: html or pdf
+	// Default: pdf
+	Format string `mapstructure:"format"`
+
+	// The directory the files are written to
+	Directory string `mapstructure:"directory"`
+}
+
+// Defines the notifications of the budget breaches, the anomalies and the
+// weekly summaries posted to the Slack and Microsoft Teams webhooks
+type NotificationsConfig struct {
+	// How often the budgets and the anomalies are checked
+	// Default: 5m
+	Interval time.Duration `mapstructure:"interval"`
+
+	// The webhooks the notifications are posted to, none when empty
+	Channels []NotificationChannel `mapstructure:"channels"`
+
+	// The limits of the emissions notified when exceeded
+	Budgets []NotificationBudget `mapstructure:"budgets"`
+
+	Anomalies AnomaliesConfig `mapstructure:"anomalies"`
+	Summary   SummaryConfig   `mapstructure:"summary"`
+
+	// The Alertmanagers the budgets exceeded, the anomalies and the stale
+	// accounts are posted to as alerts
+	Alertmanager AlertmanagerConfig `mapstructure:"alertmanager"`
+}
+
+// Defines the Alertmanagers the alerts are posted to
+type AlertmanagerConfig struct {
+	// The URLs of the Alertmanagers, e.g. http://alertmanager:9093, the
+	// alerts are posted to all of them. None when empty
+	URLs []string `mapstructure:"urls"`
+
+	// The labels added to all the alerts, e.g. the severity or the cluster
+	Labels map[string]string `mapstructure:"labels"`
+
+	// How long an account can go without a successful scrape before it is
+	// stale
+	// Default: 1h
+	Staleness time.Duration `mapstructure:"staleness"`
+
+	// The URL of the exporter the alerts link to
+	ExternalURL string `mapstructure:"externalURL"`
+}
+
+// Defines a webhook the notifications are posted to
+type NotificationChannel struct {
+	// The name of the channel in the logs
+	Name string `mapstructure:"name"`
+
+	// The kind of webhook: slack or teams
+	Type string `mapstructure:"type"`
+
+	// The URL of the incoming webhook
+	Webhook string `mapstructure:"webhook"`
+
+	// The notifications posted: budget, anomaly or summary
+	// Default: all of them
+	Events []string `mapstructure:"events"`
+
+	// The Go templates of the messages by notification, replacing the
+	// default ones
+	Templates map[string]string `mapstructure:"templates"`
+}
+
+// Defines a limit of the emissions matched by a query
+type NotificationBudget struct {
+	// The name of the budget
+	Name string `mapstructure:"name"`
+
+	// The query the emissions are aggregated with, the highest group is
+	// compared with the limit, e.g.
+	//	sum(emissions) by (team) where range=month
+	Query string `mapstructure:"query"`
+
+	// The maximum value, in gCO2eq
+	Limit float64 `mapstructure:"limit"`
+}
+
+// Defines how the spikes of the hourly emissions are detected
+type AnomaliesConfig struct {
+	// Whether the anomalies are notified
+	Enabled bool `mapstructure:"enabled"`
+
+	// The field or label the emissions are grouped by
+	// Default: provider
+	GroupBy string `mapstructure:"groupBy"`
+
+	// How far back the hours the last one is compared with go
+	// Default: 168h
+	Window time.Duration `mapstructure:"window"`
+
+	// How many standard deviations above the mean an hour is an anomaly
+	// Default: 3
+	Threshold float64 `mapstructure:"threshold"`
+}
+
+// Defines the summary of the previous week
+type SummaryConfig struct {
+	// Whether the summary is posted
+	Enabled bool `mapstructure:"enabled"`
+
+	// The day and the hour it is posted, in the time zone of the store
+	// Default: monday at 9
+	Weekday string `mapstructure:"weekday"`
+	Hour    int    `mapstructure:"hour"`
+
+	// The field or label the emissions are grouped by
+	// Default: provider
+	GroupBy string `mapstructure:"groupBy"`
+
+	// How many groups are listed
+	// Default: 5
+	Top int `mapstructure:"top"`
+}
+
+// Defines how the usage of the SaaS and DBaaS vendors is pulled from their
+// APIs and stored as emissions alongside the ones of the instances
+type SaaSConfig struct {
+	// How often the usage is pulled
+	// Default: 1h
+	Interval time.Duration `mapstructure:"interval"`
+
+	// How far back the usage is pulled the first time
+	// Default: 168h
+	Lookback time.Duration `mapstructure:"lookback"`
+
+	// How long the vendors take to report the usage, the usage more recent
+	// than the delay is pulled by the next runs
+	// Default: 6h
+	Delay time.Duration `mapstructure:"delay"`
+
+	// The accounts of the vendors
+	Connectors []SaaSConnector `mapstructure:"connectors"`
+}
+
+// Defines an account of a SaaS or DBaaS vendor
+type SaaSConnector struct {
+	// The vendor: snowflake, databricks or atlas
+	Vendor string `mapstructure:"vendor"`
+
+	// The name of the account, the name of the samples without one, e.g.
+	// the Atlas projects
+	Account string `mapstructure:"account"`
+
+	// The URL of the account, e.g. https://myorg-myaccount.snowflakecomputing.com
+	// or https://dbc-1234.cloud.databricks.com
+	// Default: https://cloud.mongodb.com for atlas
+	URL string `mapstructure:"url"`
+
+	// The bearer token: a programmatic access token for snowflake or a
+	// personal access token for databricks
+	Token string `mapstructure:"token"`
+
+	// The service account the access tokens of atlas are requested for
+	ClientID     string `mapstructure:"clientID"`
+	ClientSecret string `mapstructure:"clientSecret"`
+
+	// The SQL warehouse running the queries of databricks
+	Warehouse string `mapstructure:"warehouse"`
+
+	// The organization billed by atlas
+	Organization string `mapstructure:"organization"`
+
+	// The cloud provider and the region the account runs in, which set the
+	// grid intensity of its energy. The provider of atlas is read from the
+	// line items
+	Provider v1.Provider `mapstructure:"provider"`
+	Region   string      `mapstructure:"region"`
+
+	// The energy in kWh, including the overhead of the data centre, and
+	// the embodied emissions in gCO2eq of a unit: a credit for snowflake,
+	// a DBU for databricks and a vCPU hour for atlas
+	// Default: the estimates of the vendor
+	EnergyPerUnit   float64 `mapstructure:"energyPerUnit"`
+	EmbodiedPerUnit float64 `mapstructure:"embodiedPerUnit"`
+}
+
+// Defines how the Kubernetes nodes are labeled with the grid intensity of
+// their region, so that the workloads can prefer the greener nodes
+type NodeLabelsConfig struct {
+	// Whether the nodes are labeled
+	Enabled bool `mapstructure:"enabled"`
+
+	// The kubeconfig used to connect to the cluster
+	// The in-cluster config is used when empty
+	Kubeconfig string `mapstructure:"kubeconfig"`
+
+	// How often the labels are updated
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Defines how the emissions of the Kubernetes nodes are attributed to
+// their pods
+type AttributionConfig struct {
+	// Whether the emissions of the pods are exported
+	Enabled bool `mapstructure:"enabled"`
+
+	// The kubeconfig used to connect to the cluster
+	// The in-cluster config is used when empty
+	Kubeconfig string `mapstructure:"kubeconfig"`
+
+	// How often the usage of the pods is collected
+	Interval time.Duration `mapstructure:"interval"`
+
+	// The pod labels copied onto the emissions of the pods, as label_<name>
+	Labels []string `mapstructure:"labels"`
+
+	// The pod annotations copied onto the emissions of the pods,
+	// as annotation_<name>
+	Annotations []string `mapstructure:"annotations"`
+
+	// The rules applied to the labels of the pods, in order
+	RelabelConfigs []RelabelConfig `mapstructure:"relabelConfigs"`
+
+	// The name of the cluster, the emissions that aren't attributed to the
+	// pods are reported as its overhead
+	Cluster string `mapstructure:"cluster"`
+
+	// The emissions of the cluster that aren't attributed to the pods
+	Overhead OverheadConfig `mapstructure:"overhead"`
+
+	// The embodied emissions of the nodes without a cloud instance
+	Inventory InventoryConfig `mapstructure:"inventory"`
+}
+
+// Defines the estimation of the embodied emissions of the nodes without a
+// cloud instance, e.g. the bare-metal ones, from the hardware inventory read
+// by node-feature-discovery from SMBIOS
+type InventoryConfig struct {
+	// Whether the nodes are estimated and emitted as instances
+	Enabled bool `mapstructure:"enabled"`
+
+	// The lifespan of the servers in years, the embodied emissions are
+	// spread over it
+	Lifespan float64 `mapstructure:"lifespan"`
+}
+
+// Defines the overhead of a cluster, so that the emissions of the pods and
+// the overhead add up to the emissions of the cluster
+type OverheadConfig struct {
+	// The namespaces whose pods are reported as overhead instead of being
+	// attributed emissions, e.g. kube-system
+	Namespaces []string `mapstructure:"namespaces"`
+
+	// The emissions of the managed control plane (EKS, GKE, AKS) in gCO2eq
+	// per hour, the providers don't report its usage
+	ControlPlane float64 `mapstructure:"controlPlane"`
+}
+
+// Defines a relabeling rule, like the Prometheus relabel_config
+type RelabelConfig struct {
+	// The labels whose values are joined and matched against the regex
+	SourceLabels []string `mapstructure:"sourceLabels"`
+
+	// The separator of the joined values, ";" by default
+	Separator string `mapstructure:"separator"`
+
+	// The anchored regex the values or the label names are matched with,
+	// "(.*)" by default
+	Regex string `mapstructure:"regex"`
+
+	// The label the replacement is written to
+	TargetLabel string `mapstructure:"targetLabel"`
+
+	// The value written to the target label, it can refer to the regex
+	// capture groups, "$1" by default
+	Replacement string `mapstructure:"replacement"`
+
+	// One of replace (default), keep, drop, labelmap, labeldrop, labelkeep
+	Action string `mapstructure:"action"`
+}
+
+// Defines how the CarbonPolicy resources are reconciled when running
+// as a Kubernetes operator
+type OperatorConfig struct {
+	// Whether the CarbonPolicy resources are watched
+	Enabled bool `mapstructure:"enabled"`
+
+	// The namespace the policies are read from, all of them when empty
+	Namespace string `mapstructure:"namespace"`
+
+	// The kubeconfig used to connect to the cluster
+	// The in-cluster config is used when empty
+	Kubeconfig string `mapstructure:"kubeconfig"`
+
+	// How often the policies are reconciled
+	ResyncInterval time.Duration `mapstructure:"resyncInterval"`
+}
+
+// Defines where the calculated emissions are kept for querying
+type StoreConfig struct {
+	// How long the emissions are kept
+	Retention time.Duration `mapstructure:"retention"`
+
+	// The file the emissions are persisted to, they are only kept in memory
+	// when empty
+	Path string `mapstructure:"path"`
+
+	// Shares the emissions of all the replicas through Redis, so that every
+	// replica answers the same. Exclusive with the path
+	Redis RedisConfig `mapstructure:"redis"`
+
+	// The IANA time zone the days and the months of the queries and the
+	// reports start in, e.g. Europe/Berlin. UTC when empty
+	Timezone string `mapstructure:"timezone"`
+}
+
+// Defines the connection to Redis
+type RedisConfig struct {
+	// The address of the Redis server, Redis is not used when empty
+	Address string `mapstructure:"address"`
+
+	// The credentials, if required by the server
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// The database number
+	DB int `mapstructure:"db"`
+
+	// The stream the emissions are added to
+	Stream string `mapstructure:"stream"`
+}
+
+// Defines how the accounts are split across multiple replicas
+type ShardingConfig struct {
+	// The total amount of replicas, 0 or 1 disables sharding
+	Replicas int `mapstructure:"replicas"`
+
+	// The index of this replica, starting from 0
+	// When negative it's derived from the ordinal suffix of the hostname
+	// as set by a Kubernetes StatefulSet (e.g. aether-2)
+	Index int `mapstructure:"index"`
 }
 
 // Defines the configuration for the API
@@ -25,11 +694,189 @@ type APIConfig struct {
 
 	// The prometheus metrics path
 	MetricsPath string `mapstructure:"metricsPath"`
+
+	// Whether the web dashboard is served on /ui
+	UI bool `mapstructure:"ui"`
+
+	// Whether the GraphQL API is served on /api/graphql
+	GraphQL bool `mapstructure:"graphql"`
+
+	// Whether the Kubernetes external metrics API is served under
+	// /apis/external.metrics.k8s.io/v1beta1
+	ExternalMetrics bool `mapstructure:"externalMetrics"`
+
+	// The bearer token required by the admin endpoints
+	// The admin endpoints are disabled when empty
+	AdminToken string `mapstructure:"adminToken"`
+
+	// The signed emissions statements served on /api/v1/statement
+	Statements StatementsConfig `mapstructure:"statements"`
+
+	// Serve the API over TLS
+	TLS TLSConfig `mapstructure:"tls"`
+
+	// The credentials required by the API and the metrics endpoints
+	Auth AuthConfig `mapstructure:"auth"`
+
+	// The profiling and runtime metrics server
+	Debug DebugConfig `mapstructure:"debug"`
+}
+
+// Defines how the emissions statements are signed
+type StatementsConfig struct {
+	// The Ed25519 private key, as a PKCS #8 PEM file, the statements are
+	// signed with. The statements are disabled when empty
+	SigningKey string `mapstructure:"signingKey"`
+
+	// The organization issuing the statements
+	Issuer string `mapstructure:"issuer"`
+}
+
+// Defines the certificates of a listener
+type TLSConfig struct {
+	// The server certificate and its key, TLS is disabled when empty
+	CertFile string `mapstructure:"certFile"`
+	KeyFile  string `mapstructure:"keyFile"`
+
+	// The CA used to verify the client certificates
+	// When set, the clients must present a valid certificate (mTLS)
+	ClientCAFile string `mapstructure:"clientCAFile"`
+}
+
+// Enabled returns whether the listener is served over TLS
+func (t *TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// Defines the credentials accepted by a listener, either a bearer token
+// or the basic auth user. No authentication is required when empty
+type AuthConfig struct {
+	// The accepted bearer tokens
+	Tokens []string `mapstructure:"tokens"`
+
+	// The basic auth credentials
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// Enabled returns whether the listener requires authentication
+func (a *AuthConfig) Enabled() bool {
+	return len(a.Tokens) > 0 || a.Username != ""
+}
+
+// Defines the server exposing pprof and the Go runtime metrics
+type DebugConfig struct {
+	// Whether the debug server is started
+	Enabled bool `mapstructure:"enabled"`
+
+	// The address to listen to
+	Address string `mapstructure:"address"`
+
+	// The port to listen to, it must differ from the API one
+	Port string `mapstructure:"port"`
+
+	// Serve the debug endpoints over TLS
+	TLS TLSConfig `mapstructure:"tls"`
+
+	// The credentials required by the debug endpoints
+	Auth AuthConfig `mapstructure:"auth"`
 }
 
 type ProvidersConfig struct {
 	// How often we should scrape the data
 	Interval time.Duration `mapstructure:"scrapingInterval"`
+
+	// When the accounts are scraped: schedule, every scraping interval, or
+	// pull, when the metrics are scraped by Prometheus
+	// Default: schedule
+	Mode string `mapstructure:"mode"`
+
+	// In the pull mode, how long the emissions of a scrape are served before
+	// the accounts are scraped again
+	// Default: the scraping interval
+	Freshness time.Duration `mapstructure:"freshness"`
+
+	// How often the credentials and the permissions of the accounts are
+	// checked, once their scraper is started and then at every interval.
+	// The checks are disabled with 0
+	// Default: 1h
+	CheckInterval time.Duration `mapstructure:"checkInterval"`
+
+	// How failed scrapes are retried within a scraping interval
+	Retry RetryConfig `mapstructure:"retry"`
+
+	// When to stop scraping an account that keeps failing
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuitBreaker"`
+
+	// Backfilling of the intervals that were missed
+	CatchUp CatchUpConfig `mapstructure:"catchUp"`
+
+	// Limit of the requests per second made to all the provider APIs combined
+	RateLimit RateLimitConfig `mapstructure:"rateLimit"`
+
+	// Maximum amount of regions refreshed concurrently across all the accounts
+	Workers int `mapstructure:"workers"`
+
+	// The cache of the specifications of the instance types shared by all
+	// the accounts
+	Specs SpecsConfig `mapstructure:"specs"`
+}
+
+// Defines how the specifications of the instance types, their vCPUs, memory
+// and architecture, are cached. A type is fetched once for all the accounts
+// and the regions, and again once expired
+type SpecsConfig struct {
+	// The file the specs are persisted to, so that they aren't fetched
+	// again on restart. They are only kept in memory when empty
+	// Default: /tmp/aether/specs.json
+	Path string `mapstructure:"path"`
+
+	// How long the specs are used before being fetched again
+	// Default: 168h
+	Expiration time.Duration `mapstructure:"expiration"`
+}
+
+// Defines a token bucket rate limit
+type RateLimitConfig struct {
+	// The amount of requests allowed per second, 0 means no limit
+	RequestsPerSecond float64 `mapstructure:"requestsPerSecond"`
+
+	// The amount of requests that can be made at once
+	Burst int `mapstructure:"burst"`
+}
+
+// Defines how a failed scrape is retried with an exponential backoff
+type RetryConfig struct {
+	// Maximum amount of attempts per scraping interval, including the first one
+	MaxAttempts int `mapstructure:"maxAttempts"`
+
+	// The wait time before the first retry, doubled on every attempt
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+
+	// The upper limit of the wait time between two attempts
+	MaxBackoff time.Duration `mapstructure:"maxBackoff"`
+}
+
+// Defines how the intervals missed while the process was down, or while
+// the scrapes were failing, are collected again
+type CatchUpConfig struct {
+	// Whether the missed intervals should be collected
+	Enabled bool `mapstructure:"enabled"`
+
+	// How far back in time the missed intervals are collected
+	MaxLookback time.Duration `mapstructure:"maxLookback"`
+
+	// Where the time of the last successful scrape of every account is stored
+	StateFile string `mapstructure:"stateFile"`
+}
+
+// Defines when the circuit breaker of an account opens and for how long
+type CircuitBreakerConfig struct {
+	// Amount of consecutive failed scrapes before the circuit opens
+	FailureThreshold int `mapstructure:"failureThreshold"`
+
+	// How long the circuit stays open before a new scrape is attempted
+	Cooldown time.Duration `mapstructure:"cooldown"`
 }
 
 // Defines the general configuration for a provider
@@ -40,11 +887,116 @@ type Provider struct {
 
 	// The SDK Http Client transport configuration for the whole provider
 	Transport TransportConfig `mapstructure:"transport"`
+
+	// The rate limits of the provider APIs shared by all the accounts
+	// the key is the API family:
+	// - AWS: ec2, cloudwatch
+	// - GCP: compute, monitoring
+	// - Azure: compute, monitor
+	// - OCI: compute, monitoring
+	// - DigitalOcean: droplets, monitoring
+	// - Scaleway: instance, baremetal, cockpit
+	RateLimits map[string]RateLimitConfig `mapstructure:"rateLimits"`
+
+	// The quotas of the requests per minute of the provider APIs, the
+	// usage of the quotas is exported. The key is the API family, the ones
+	// not set use the default quota of the provider
+	Quotas map[string]float64 `mapstructure:"quotas"`
+
+	// How often the metrics of the resource types are collected, e.g.
+	// network: 1h for the flow summaries which are expensive to query. The
+	// key is the resource type: network or storage for its I/O. The ones not
+	// set, or not longer than the scraping interval, are collected at every
+	// scrape
+	Granularity map[string]time.Duration `mapstructure:"granularity"`
+
+	// The grid intensity in gCO2eq/kWh of the on-premises sites running
+	// instances of the provider, e.g. the AWS Outposts by ID. The
+	// instances of the sites not set use the one of their region
+	Sites map[string]float64 `mapstructure:"sites"`
+
+	// The custom regions of the provider, e.g. edge locations or sovereign
+	// clouds missing from the emission factors, by region. They take
+	// precedence over the regions of the emission factors
+	Regions map[string]RegionConfig `mapstructure:"regions"`
+
+	// The power usage effectiveness of the data centers of all the regions
+	// of the provider, e.g. a contractual figure, and where it comes from.
+	// The PUE of the custom regions takes precedence
+	PUE       float64 `mapstructure:"pue"`
+	PUESource string  `mapstructure:"pueSource"`
+
+	// The uplift of the embodied emissions of the servers for their share
+	// of the shared networking and storage infrastructure of the data
+	// centers, e.g. the switches and the storage arrays. None when not set
+	EmbodiedOverhead EmbodiedOverheadConfig `mapstructure:"embodiedOverhead"`
+
+	// The directory of the emission factors of the provider, in the format
+	// of the emissions-data repo. OCI is missing from the repo and needs
+	// them, DigitalOcean and Scaleway embed their own and the other
+	// providers read theirs from the repo when empty
+	Factors string `mapstructure:"factors"`
+}
+
+// EmbodiedOverheadConfig is the share of the embodied emissions of the
+// infrastructure of the data centers shared by the servers, in % of the
+// embodied emissions of the servers
+type EmbodiedOverheadConfig struct {
+	// The uplifts in %, e.g. 5 adds 5% to the embodied emissions
+	Networking float64 `mapstructure:"networking"`
+	Storage    float64 `mapstructure:"storage"`
+
+	// Where the uplifts come from, e.g. the study they're taken from,
+	// recorded with the overrides
+	Source string `mapstructure:"source"`
+}
+
+// RegionConfig is a custom region, the values not set are the ones of the
+// emission factors
+type RegionConfig struct {
+	// The grid intensity in gCO2eq/kWh
+	GridIntensity float64 `mapstructure:"gridIntensity"`
+
+	// The power usage effectiveness of the data centers, and where it
+	// comes from, e.g. the contract it's taken from
+	PUE       float64 `mapstructure:"pue"`
+	PUESource string  `mapstructure:"pueSource"`
+
+	// The lifespan of the servers in years, the embodied emissions are
+	// spread over it
+	Lifespan float64 `mapstructure:"lifespan"`
+
+	// Where the region is, e.g. FR and europe, for the fallback of the
+	// grid intensity of the regions missing from the emission factors
+	Country   string `mapstructure:"country"`
+	Continent string `mapstructure:"continent"`
+}
+
+// PluginConfig is a provider implemented by a plugin, its accounts are
+// configured in providers like the built-in ones
+type PluginConfig struct {
+	// The name of the provider
+	Provider string `mapstructure:"provider"`
+
+	// The plugin executable
+	Path string `mapstructure:"path"`
+
+	// The directory of the emission factors of the provider, in the format
+	// of the emissions-data repo: <provider>-default.yaml, -grid.yaml,
+	// -embodied.yaml and -use.yaml
+	Factors string `mapstructure:"factors"`
 }
 
 type Account struct {
 
-	// AWS: The regions we should scrape the data for
+	// Optional unique name of the account, used to identify it
+	// in logs, metrics and the API
+	Name string `mapstructure:"name"`
+
+	// AWS, Azure, OCI, DigitalOcean: The regions we should scrape the data
+	// for, all the regions of the Azure VMs and the droplets and the region
+	// of the OCI configuration when empty. Scaleway: the zones or the
+	// regions of all their zones, all the zones when empty
 	Regions []string `mapstructure:"regions"`
 
 	// AWS Specific:
@@ -57,14 +1009,88 @@ type Account struct {
 	// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/aws-services-cloudwatch-metrics.html
 	Namespaces []string `mapstructure:"namespaces"`
 
-	// GCP: The project
+	// GCP: The project. Scaleway: The project ID, the default one of the
+	// profile when empty
 	Project string `mapstructure:"project"`
 
-	// The location from where to load the credentials
+	// Azure: The subscription ID, the credentials are the default ones of
+	// the environment, e.g. a service principal set in AZURE_CLIENT_ID,
+	// AZURE_TENANT_ID and AZURE_CLIENT_SECRET or a managed identity
+	Subscription string `mapstructure:"subscription"`
+
+	// OCI: The OCIDs of the compartments of the instances, the root
+	// compartment of the tenancy when empty. Their sub-compartments aren't
+	// scraped. The credentials are the profile of the OCI configuration
+	// file, ~/.oci/config unless set in credentials
+	Compartments []string `mapstructure:"compartments"`
+
+	// The location from where to load the credentials. DigitalOcean: the
+	// first file holds the API token, DIGITALOCEAN_TOKEN or
+	// DIGITALOCEAN_ACCESS_TOKEN otherwise. Scaleway: the first file is the
+	// configuration of the Scaleway CLI and the second one holds the
+	// Cockpit token, SCW_COCKPIT_TOKEN otherwise
 	Credentials ProviderConfig `mapstructure:"credentials"`
 
 	// The location from where to load the additional configuration
 	Config ProviderConfig `mapstructure:"config"`
+
+	// The private endpoints of the provider APIs, e.g. the VPC endpoints of
+	// AWS or the Private Service Connect endpoints of GCP, so that they're
+	// reached without internet egress. The key is the API family:
+	// - AWS: ec2, cloudwatch, computeoptimizer. {region} is replaced by
+	//   the scraped region
+	// - GCP: compute, monitoring, recommender
+	// - DigitalOcean: api
+	// - Scaleway: api, metrics for the metrics URL of Cockpit
+	Endpoints map[string]string `mapstructure:"endpoints"`
+
+	// AWS: Use the dual-stack endpoints, reachable from IPv6-only networks
+	DualStack bool `mapstructure:"dualStack"`
+
+	// GCP: Account for the whole hosts of the sole-tenant nodes of the
+	// project instead of the vCPUs of their instances
+	SoleTenantNodes bool `mapstructure:"soleTenantNodes"`
+
+	// AWS, GCP: Collect the disks of the instances and their I/O for the
+	// storage emissions
+	Storage bool `mapstructure:"storage"`
+
+	// AWS, GCP: Collect the snapshots and the images of the account for
+	// their storage emissions
+	Snapshots bool `mapstructure:"snapshots"`
+
+	// AWS, GCP: Collect the data transferred by the CDNs and the load
+	// balancers for the network emissions
+	Network bool `mapstructure:"network"`
+
+	// AWS, GCP: The CSV summary of the VPC Flow Logs of the account, the
+	// bytes transferred by the instances by direction and destination,
+	// read again when it changes
+	FlowLogs string `mapstructure:"flowLogs"`
+
+	// AWS, GCP: The managed Kubernetes clusters of the account, EKS or GKE,
+	// whose control plane is accounted for as instances of its equivalent
+	// kind, the providers don't report the machines it runs on
+	ControlPlanes []ControlPlaneConfig `mapstructure:"controlPlanes"`
+}
+
+// Defines the managed control plane of a cluster, the defaults of the
+// provider are used for what isn't set
+type ControlPlaneConfig struct {
+	// The name of the cluster and its region
+	Cluster string `mapstructure:"cluster"`
+	Region  string `mapstructure:"region"`
+
+	// The instance kind equivalent to a replica of the control plane and
+	// its vCPUs, which must be set with the kind
+	Kind string  `mapstructure:"kind"`
+	VCPU float64 `mapstructure:"vCPU"`
+
+	// The replicas of the control plane, e.g. 1 for a zonal GKE cluster
+	Replicas int `mapstructure:"replicas"`
+
+	// The CPU utilization of the replicas in %
+	Usage float64 `mapstructure:"usage"`
 }
 
 type ProviderConfig struct {
@@ -75,6 +1101,24 @@ type ProviderConfig struct {
 	FilePaths []string `mapstructure:"filePaths"`
 }
 
+// ID returns the identifier of the account.
+// If no name is configured it falls back to the GCP project
+// or the credentials profile
+func (a *Account) ID() string {
+	switch {
+	case a.Name != "":
+		return a.Name
+	case a.Project != "":
+		return a.Project
+	case a.Subscription != "":
+		return a.Subscription
+	case a.Credentials.Profile != "":
+		return a.Credentials.Profile
+	default:
+		return "default"
+	}
+}
+
 // Whether the provider config has some values set
 func (pc ProviderConfig) IsPresent() bool {
 	return pc.Profile != "" || len(pc.FilePaths) > 0
@@ -0,0 +1,186 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// sampleSelector returns the stored emissions
+type sampleSelector interface {
+	Select(from, to time.Time, filter func(*store.Sample) bool) []store.Sample
+}
+
+// Collector reads the billing exports periodically and exports the emissions
+// of the instances per unit spent
+type Collector struct {
+	sources []config.CostSource
+
+	// How often the exports are read and how far back the metrics look
+	interval time.Duration
+	window   time.Duration
+
+	// The emissions the spend is joined with
+	store sampleSelector
+
+	// The spend read by the last run
+	costs []Cost
+	mu    sync.RWMutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	logger *slog.Logger
+}
+
+// New returns a collector joining the billing exports of the config with the
+// stored emissions
+func New(ctx context.Context, cfg *config.CostsConfig, s sampleSelector) (*Collector, error) {
+	for i := range cfg.Sources {
+		if _, ok := formats[cfg.Sources[i].Format]; !ok {
+			return nil, fmt.Errorf("unknown billing export format %q", cfg.Sources[i].Format)
+		}
+	}
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	window := cfg.Window
+	if window <= 0 {
+		window = 7 * 24 * time.Hour
+	}
+
+	return &Collector{
+		sources:  cfg.Sources,
+		interval: interval,
+		window:   window,
+		store:    s,
+		logger:   log.FromContext(ctx),
+	}, nil
+}
+
+// Costs returns the spend of the days in [from, to)
+func (c *Collector) Costs(from, to time.Time) []Cost {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var out []Cost
+	for i := range c.costs {
+		if !c.costs[i].Day.Before(from) && c.costs[i].Day.Before(to) {
+			out = append(out, c.costs[i])
+		}
+	}
+
+	return out
+}
+
+// Start reads the billing exports now and then every interval, until the
+// context is done or the collector is stopped
+func (c *Collector) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.collect()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops reading the billing exports, it can be called more than once
+func (c *Collector) Stop(ctx context.Context) {
+	if c.cancel == nil {
+		return
+	}
+
+	c.cancel()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+}
+
+// collect reads the billing exports and updates the metrics, the previous
+// spend is kept when an export can't be read
+func (c *Collector) collect() {
+	costs, err := c.load()
+	if err != nil {
+		c.logger.Error("failed reading the billing exports", "error", err)
+	} else {
+		c.mu.Lock()
+		c.costs = costs
+		c.mu.Unlock()
+	}
+
+	c.export()
+}
+
+// load reads all the billing exports
+func (c *Collector) load() ([]Cost, error) {
+	var costs []Cost
+	for i := range c.sources {
+		loaded, err := Load(&c.sources[i])
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, loaded...)
+	}
+	return costs, nil
+}
+
+// export updates the metrics with the window ending with the latest day of
+// spend. That day is left out, the exports are still filling it
+func (c *Collector) export() {
+	c.mu.RLock()
+	costs := c.costs
+	c.mu.RUnlock()
+
+	var to time.Time
+	for i := range costs {
+		if costs[i].Day.After(to) {
+			to = costs[i].Day
+		}
+	}
+	from := to.Add(-c.window)
+
+	samples := c.store.Select(from, to, nil)
+
+	instances := make(map[v1.Provider]*View)
+	for i := range c.sources {
+		p := formats[c.sources[i].Format].provider
+		if _, ok := instances[p]; ok {
+			continue
+		}
+
+		var selected []store.Sample
+		for j := range samples {
+			if samples[j].Provider == p {
+				selected = append(selected, samples[j])
+			}
+		}
+		instances[p] = Join(selected, costs, from, to, "instance")
+	}
+
+	export(instances, Join(samples, costs, from, to, "provider"))
+}
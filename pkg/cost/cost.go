@@ -0,0 +1,233 @@
+// Package cost joins the stored emissions of the instances with their spend,
+// read from the AWS Cost and Usage Reports and the GCP billing exports
+package cost
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The formats of the billing exports
+const (
+	// The AWS Cost and Usage Reports, legacy or 2.0, in CSV
+	FormatCUR = "cur"
+
+	// The GCP detailed billing export, queried from BigQuery to CSV
+	FormatGCPBilling = "gcp-billing"
+)
+
+// Cost is the spend of a resource over a day
+type Cost struct {
+	Provider v1.Provider `json:"provider"`
+	Resource string      `json:"resource"`
+	Currency string      `json:"currency"`
+
+	// The UTC day the resource was used
+	Day time.Time `json:"day"`
+
+	Amount float64 `json:"amount"`
+}
+
+// columns are the names of the columns read from an export, the first one
+// found in the header is used
+type columns struct {
+	resource []string
+	amount   []string
+	start    []string
+	currency []string
+}
+
+var formats = map[string]struct {
+	provider v1.Provider
+	columns  columns
+}{
+	FormatCUR: {
+		provider: v1.AWS,
+		columns: columns{
+			resource: []string{"lineItem/ResourceId", "line_item_resource_id"},
+			amount:   []string{"lineItem/UnblendedCost", "line_item_unblended_cost"},
+			start:    []string{"lineItem/UsageStartDate", "line_item_usage_start_date"},
+			currency: []string{"lineItem/CurrencyCode", "line_item_currency_code"},
+		},
+	},
+	FormatGCPBilling: {
+		provider: v1.GCP,
+		columns: columns{
+			resource: []string{"resource_id", "resource.global_name", "resource_global_name"},
+			amount:   []string{"cost"},
+			start:    []string{"usage_start_time"},
+			currency: []string{"currency"},
+		},
+	},
+}
+
+// The layouts of the usage times in the exports
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+}
+
+// Parse reads the spend of the resources from a CSV billing export, summed
+// by day. The line items without a resource, like the taxes, are skipped
+func Parse(r io.Reader, format string) ([]Cost, error) {
+	f, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown billing export format %q", format)
+	}
+
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading the header: %w", err)
+	}
+
+	resource, err := column(header, f.columns.resource)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := column(header, f.columns.amount)
+	if err != nil {
+		return nil, err
+	}
+	start, err := column(header, f.columns.start)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := column(header, f.columns.currency)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		resource, currency string
+		day                time.Time
+	}
+	sums := make(map[key]int)
+
+	var costs []Cost
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id := record[resource]
+		if id == "" {
+			continue
+		}
+		// the GCP resources are identified by their full name, which ends
+		// with the id of the instance
+		if f.provider == v1.GCP {
+			id = path.Base(id)
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		value, err := strconv.ParseFloat(record[amount], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid cost %q", line, record[amount])
+		}
+
+		t, err := parseTime(record[start])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		k := key{resource: id, currency: record[currency], day: t.UTC().Truncate(24 * time.Hour)}
+		if i, ok := sums[k]; ok {
+			costs[i].Amount += value
+			continue
+		}
+
+		sums[k] = len(costs)
+		costs = append(costs, Cost{
+			Provider: f.provider,
+			Resource: k.resource,
+			Currency: k.currency,
+			Day:      k.day,
+			Amount:   value,
+		})
+	}
+
+	return costs, nil
+}
+
+// Load reads the files of a billing export source
+func Load(src *config.CostSource) ([]Cost, error) {
+	paths, err := filepath.Glob(src.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var costs []Cost
+	for _, p := range paths {
+		c, err := loadFile(p, src.Format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		costs = append(costs, c...)
+	}
+
+	return costs, nil
+}
+
+// loadFile reads a billing export file, gunzipping it if needed
+func loadFile(p, format string) ([]Cost, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(p, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return Parse(r, format)
+}
+
+// column returns the index of the first of the names found in the header
+func column(header, names []string) (int, error) {
+	for _, name := range names {
+		for i, h := range header {
+			if h == name {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("missing column %s", names[0])
+}
+
+// parseTime parses a usage time of an export
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid usage time %q", s)
+}
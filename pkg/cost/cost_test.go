@@ -0,0 +1,125 @@
+package cost
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+const cur = `identity/LineItemId,lineItem/UsageAccountId,lineItem/LineItemType,lineItem/UsageStartDate,lineItem/ProductCode,lineItem/ResourceId,lineItem/UnblendedCost,lineItem/CurrencyCode
+1,123456789012,Usage,2024-01-01T00:00:00Z,AmazonEC2,i-0a,0.25,USD
+2,123456789012,Usage,2024-01-01T01:00:00Z,AmazonEC2,i-0a,0.25,USD
+3,123456789012,Usage,2024-01-02T00:00:00Z,AmazonEC2,i-0a,0.5,USD
+4,123456789012,Usage,2024-01-01T00:00:00Z,AmazonEC2,vol-0b,0.1,USD
+5,123456789012,Tax,2024-01-01T00:00:00Z,AmazonEC2,,1.2,USD
+`
+
+const gcpBilling = `usage_start_time,usage_end_time,resource_id,cost,currency,project_id
+2024-01-01 00:00:00 UTC,2024-01-01 01:00:00 UTC,//compute.googleapis.com/projects/p/zones/europe-west1-b/instances/4242,1.5,EUR,p
+2024-01-01 01:00:00 UTC,2024-01-01 02:00:00 UTC,//compute.googleapis.com/projects/p/zones/europe-west1-b/instances/4242,-0.5,EUR,p
+`
+
+func TestParse(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		costs  []Cost
+		err    string
+	}{
+		{
+			name:   "cur",
+			format: FormatCUR,
+			data:   cur,
+			costs: []Cost{
+				{Provider: v1.AWS, Resource: "i-0a", Currency: "USD", Day: day, Amount: 0.5},
+				{Provider: v1.AWS, Resource: "i-0a", Currency: "USD", Day: day.AddDate(0, 0, 1), Amount: 0.5},
+				{Provider: v1.AWS, Resource: "vol-0b", Currency: "USD", Day: day, Amount: 0.1},
+			},
+		},
+		{
+			name:   "cur 2.0",
+			format: FormatCUR,
+			data: "line_item_usage_start_date,line_item_resource_id,line_item_unblended_cost,line_item_currency_code\n" +
+				"2024-01-01 00:00:00,i-0a,0.25,USD\n",
+			costs: []Cost{
+				{Provider: v1.AWS, Resource: "i-0a", Currency: "USD", Day: day, Amount: 0.25},
+			},
+		},
+		{
+			name:   "gcp billing",
+			format: FormatGCPBilling,
+			data:   gcpBilling,
+			costs: []Cost{
+				{Provider: v1.GCP, Resource: "4242", Currency: "EUR", Day: day, Amount: 1},
+			},
+		},
+		{
+			name:   "missing column",
+			format: FormatGCPBilling,
+			data:   cur,
+			err:    "missing column resource_id",
+		},
+		{
+			name:   "invalid cost",
+			format: FormatCUR,
+			data:   "lineItem/UsageStartDate,lineItem/ResourceId,lineItem/UnblendedCost,lineItem/CurrencyCode\n2024-01-01T00:00:00Z,i-0a,free,USD\n",
+			err:    `line 2: invalid cost "free"`,
+		},
+		{
+			name:   "unknown format",
+			format: "azure",
+			data:   cur,
+			err:    `unknown billing export format "azure"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			costs, err := Parse(strings.NewReader(test.data), test.format)
+			if test.err != "" {
+				assert.ErrorContains(err, test.err)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Equal(test.costs, costs)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "january.csv"), []byte(cur), 0o600))
+
+	f, err := os.Create(filepath.Join(dir, "february.csv.gz"))
+	assert.NoError(err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte(strings.ReplaceAll(cur, "2024-01-0", "2024-02-0")))
+	assert.NoError(err)
+	assert.NoError(gz.Close())
+	assert.NoError(f.Close())
+
+	costs, err := Load(&config.CostSource{Format: FormatCUR, Path: filepath.Join(dir, "*.csv*")})
+	assert.NoError(err)
+	assert.Len(costs, 6)
+
+	var total float64
+	for _, c := range costs {
+		total += c.Amount
+	}
+	assert.InDelta(2.2, total, 1e-9)
+}
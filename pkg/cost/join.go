@@ -0,0 +1,161 @@
+package cost
+
+import (
+	"sort"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+)
+
+// The value of the instances without the label the view is grouped by
+const unset = "(none)"
+
+// View is the emissions and the spend of the instances over a period,
+// grouped by a label
+type View struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	GroupBy string    `json:"groupBy"`
+
+	// The groups sorted by emissions, the highest first
+	Rows []Row `json:"rows"`
+
+	// The sum of all the groups
+	Total Row `json:"total"`
+}
+
+// Row is the emissions and the spend of a group
+type Row struct {
+	Group     string `json:"group"`
+	Instances int    `json:"instances"`
+
+	// The emissions in gCO2eq
+	Emissions float64 `json:"emissions"`
+
+	// The spend, in the currency of the billing export. Both are empty when
+	// the instances are billed in several currencies
+	Cost     float64 `json:"cost"`
+	Currency string  `json:"currency"`
+
+	// The emissions per unit of the currency, zero without a spend
+	GCO2ePerDollar float64 `json:"gco2ePerDollar"`
+}
+
+// Join returns the view of the instances with both samples and costs in
+// [from, to), grouped by a field or a label of their latest sample. The
+// samples are sorted by time
+func Join(samples []store.Sample, costs []Cost, from, to time.Time, groupBy string) *View {
+	type instance struct {
+		sample    *store.Sample
+		emissions float64
+		cost      float64
+		currency  string
+		billed    bool
+	}
+
+	instances := make(map[string]*instance)
+	for i := range samples {
+		s := &samples[i]
+		if s.Time.Before(from) || !s.Time.Before(to) {
+			continue
+		}
+
+		key := s.Provider.String() + "/" + s.Name
+		in, ok := instances[key]
+		if !ok {
+			in = &instance{}
+			instances[key] = in
+		}
+		in.sample = s
+		in.emissions += s.Operational + s.Embodied
+	}
+
+	for i := range costs {
+		c := &costs[i]
+		if c.Day.Before(from) || !c.Day.Before(to) {
+			continue
+		}
+
+		in, ok := instances[c.Provider.String()+"/"+c.Resource]
+		if !ok {
+			continue
+		}
+		in.cost += c.Amount
+		in.currency = mergeCurrency(in.currency, c.Currency, in.billed)
+		in.billed = true
+	}
+
+	v := &View{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Total:   Row{Group: "total"},
+	}
+
+	rows := make(map[string]*Row)
+	for _, in := range instances {
+		if !in.billed {
+			continue
+		}
+
+		group := in.sample.Label(groupBy)
+		if group == "" {
+			group = unset
+		}
+
+		row, ok := rows[group]
+		if !ok {
+			row = &Row{Group: group}
+			rows[group] = row
+		}
+
+		row.add(in.emissions, in.cost, in.currency)
+		v.Total.add(in.emissions, in.cost, in.currency)
+	}
+
+	v.Rows = make([]Row, 0, len(rows))
+	for _, row := range rows {
+		row.ratio()
+		v.Rows = append(v.Rows, *row)
+	}
+	v.Total.ratio()
+
+	sort.Slice(v.Rows, func(i, j int) bool {
+		if v.Rows[i].Emissions != v.Rows[j].Emissions {
+			return v.Rows[i].Emissions > v.Rows[j].Emissions
+		}
+		return v.Rows[i].Group < v.Rows[j].Group
+	})
+
+	return v
+}
+
+// add adds an instance to the row
+func (r *Row) add(emissions, cost float64, currency string) {
+	r.Currency = mergeCurrency(r.Currency, currency, r.Instances > 0)
+	r.Instances++
+	r.Emissions += emissions
+	r.Cost += cost
+}
+
+// ratio sets the emissions per unit of the currency, the amounts of several
+// currencies aren't summed
+func (r *Row) ratio() {
+	if r.Currency == "" {
+		r.Cost = 0
+		return
+	}
+
+	if r.Cost > 0 {
+		r.GCO2ePerDollar = r.Emissions / r.Cost
+	}
+}
+
+// mergeCurrency returns the currency of a sum, empty when the currencies
+// differ. The first value is taken as is
+func mergeCurrency(current, next string, merged bool) string {
+	if !merged || current == next {
+		return next
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package cost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin(t *testing.T) {
+	assert := require.New(t)
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []store.Sample{
+		{Time: day.Add(time.Hour), Provider: v1.AWS, Name: "i-a", Labels: v1.Labels{"team": "data"}, Operational: 80, Embodied: 20},
+		{Time: day.Add(2 * time.Hour), Provider: v1.AWS, Name: "i-a", Labels: v1.Labels{"team": "data"}, Operational: 80, Embodied: 20},
+		{Time: day.Add(time.Hour), Provider: v1.AWS, Name: "i-b", Operational: 50},
+		{Time: day.Add(time.Hour), Provider: v1.GCP, Name: "42", Labels: v1.Labels{"team": "data"}, Operational: 300},
+		// not billed
+		{Time: day.Add(time.Hour), Provider: v1.AWS, Name: "i-c", Operational: 1000},
+		// after the period
+		{Time: day.AddDate(0, 0, 1), Provider: v1.AWS, Name: "i-b", Operational: 50},
+	}
+	costs := []Cost{
+		{Provider: v1.AWS, Resource: "i-a", Currency: "USD", Day: day, Amount: 2},
+		{Provider: v1.AWS, Resource: "i-b", Currency: "USD", Day: day, Amount: 0.5},
+		{Provider: v1.GCP, Resource: "42", Currency: "EUR", Day: day, Amount: 3},
+		// not scraped
+		{Provider: v1.AWS, Resource: "vol-a", Currency: "USD", Day: day, Amount: 7},
+		// the same name on another provider
+		{Provider: v1.GCP, Resource: "i-c", Currency: "EUR", Day: day, Amount: 7},
+		// after the period
+		{Provider: v1.AWS, Resource: "i-a", Currency: "USD", Day: day.AddDate(0, 0, 1), Amount: 2},
+	}
+
+	v := Join(samples, costs, day, day.AddDate(0, 0, 1), "provider")
+	assert.Equal([]Row{
+		{Group: "gcp", Instances: 1, Emissions: 300, Cost: 3, Currency: "EUR", GCO2ePerDollar: 100},
+		{Group: "aws", Instances: 2, Emissions: 250, Cost: 2.5, Currency: "USD", GCO2ePerDollar: 100},
+	}, v.Rows)
+
+	// the currencies can't be summed
+	assert.Equal(Row{Group: "total", Instances: 3, Emissions: 550}, v.Total)
+
+	v = Join(samples, costs, day, day.AddDate(0, 0, 1), "team")
+	assert.Equal([]Row{
+		{Group: "data", Instances: 2, Emissions: 500},
+		{Group: "(none)", Instances: 1, Emissions: 50, Cost: 0.5, Currency: "USD", GCO2ePerDollar: 100},
+	}, v.Rows)
+}
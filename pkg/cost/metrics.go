@@ -0,0 +1,66 @@
+package cost
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+var (
+	// The spend of the instances over the window
+	instanceCost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "instance_cost",
+			Help: "spend of an instance over the costs window, in the currency of the billing export",
+		},
+		[]string{"provider", "instance", "currency"},
+	)
+
+	// The emissions of the instances per unit spent over the window
+	instanceIntensity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "instance_gco2e_per_dollar",
+			Help: "co2eq of an instance per unit of the currency spent over the costs window",
+		},
+		[]string{"provider", "instance", "currency"},
+	)
+
+	// The emissions of the providers per unit spent over the window
+	providerIntensity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gco2e_per_dollar",
+			Help: "co2eq of the instances of a provider per unit of the currency spent over the costs window",
+		},
+		[]string{"provider", "currency"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		instanceCost,
+		instanceIntensity,
+		providerIntensity,
+	)
+}
+
+// export replaces the exported metrics with the views of the instances of
+// every provider and the view by provider
+func export(instances map[v1.Provider]*View, providers *View) {
+	instanceCost.Reset()
+	instanceIntensity.Reset()
+	providerIntensity.Reset()
+
+	for provider, v := range instances {
+		for _, row := range v.Rows {
+			instanceCost.WithLabelValues(provider.String(), row.Group, row.Currency).Set(row.Cost)
+			if row.GCO2ePerDollar > 0 {
+				instanceIntensity.WithLabelValues(provider.String(), row.Group, row.Currency).Set(row.GCO2ePerDollar)
+			}
+		}
+	}
+
+	for _, row := range providers.Rows {
+		if row.GCO2ePerDollar > 0 {
+			providerIntensity.WithLabelValues(row.Group, row.Currency).Set(row.GCO2ePerDollar)
+		}
+	}
+}
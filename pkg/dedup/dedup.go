@@ -0,0 +1,233 @@
+// Package dedup resolves the identity of the instances reported by several
+// collectors, e.g. a node reported by the cloud provider collector and by an
+// in-cluster agent, so that the emissions of each of them are counted once
+package dedup
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+var deduplicated = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "deduplicated_instances_total",
+		Help: "The instances not counted because a collector of higher precedence reports them, by their provider and the one of the collector counting them",
+	},
+	[]string{"provider", "owner"},
+)
+
+func init() {
+	prometheus.MustRegister(deduplicated)
+}
+
+// providerIDs maps the scheme of the node provider IDs to the providers
+var providerIDs = map[string]v1.Provider{
+	"aws":   v1.AWS,
+	"gce":   v1.GCP,
+	"azure": v1.Azure,
+}
+
+// azureIDLabel is the label of the resource ID of the Azure VMs
+const azureIDLabel = "ID"
+
+// owner is the collector counting a resource
+type owner struct {
+	// The collector counting the resource and when it last reported it,
+	// none while the collector of the provider of the resource is waited
+	// for
+	provider v1.Provider
+	seen     time.Time
+
+	// When the resource was first reported, and last reported by any
+	// collector
+	first, last time.Time
+}
+
+// Resolver tells which collector counts a resource. A resource is counted
+// by the collector of the highest precedence reporting it, the other ones
+// take over when it isn't reported anymore. The collector of the provider
+// of the resource, e.g. aws for an EKS node, is waited for when it takes
+// precedence, whatever the order the collectors report the resource in.
+// It's safe for concurrent use, a nil Resolver counts every instance
+type Resolver struct {
+	// The rank of the providers, the lowest first
+	precedence map[v1.Provider]int
+
+	// How long a collector owns a resource after reporting it
+	ttl time.Duration
+
+	owners map[string]owner
+	pruned time.Time
+	mu     sync.Mutex
+
+	now func() time.Time
+}
+
+// New returns a resolver with the precedence of the config, the instances
+// are reported once per scraping interval
+func New(cfg *config.DeduplicationConfig, interval time.Duration) *Resolver {
+	precedence := make(map[v1.Provider]int, len(cfg.Precedence))
+	for i, p := range cfg.Precedence {
+		if _, ok := precedence[v1.Provider(p)]; !ok {
+			precedence[v1.Provider(p)] = i
+		}
+	}
+
+	// a scrape can be late, the owner is kept for a missed one
+	return &Resolver{
+		precedence: precedence,
+		ttl:        2 * interval,
+		owners:     make(map[string]owner),
+		now:        time.Now,
+	}
+}
+
+// Counted tells whether the emissions of the instance are counted, they
+// aren't when a collector of higher precedence reports the same resource
+func (r *Resolver) Counted(instance *v1.Instance) bool {
+	if r == nil {
+		return true
+	}
+
+	key, resource, ok := identity(instance)
+	if !ok {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.prune(now)
+
+	o, ok := r.owners[key]
+	if !ok {
+		o.first = now
+	}
+	o.last = now
+	defer func() { r.owners[key] = o }()
+
+	owned := o.provider != "" && now.Sub(o.seen) <= r.ttl
+	switch {
+	case owned && o.provider != instance.Provider && !r.precedes(instance.Provider, o.provider):
+		deduplicated.WithLabelValues(instance.Provider.String(), o.provider.String()).Inc()
+		return false
+	case !owned && resource != instance.Provider && r.precedes(resource, instance.Provider) && now.Sub(o.first) <= r.ttl:
+		// the resource isn't counted until its provider reports it, or
+		// doesn't for a missed scrape
+		deduplicated.WithLabelValues(instance.Provider.String(), resource.String()).Inc()
+		return false
+	}
+
+	o.provider = instance.Provider
+	o.seen = now
+	return true
+}
+
+// precedes tells whether the provider takes precedence over the other one,
+// the providers not listed come last
+func (r *Resolver) precedes(provider, other v1.Provider) bool {
+	rank, ok := r.precedence[provider]
+	if !ok {
+		return false
+	}
+
+	otherRank, ok := r.precedence[other]
+	return !ok || rank < otherRank
+}
+
+// prune forgets the resources not reported anymore, at most once per ttl
+func (r *Resolver) prune(now time.Time) {
+	if now.Sub(r.pruned) < r.ttl {
+		return
+	}
+	r.pruned = now
+
+	for key, o := range r.owners {
+		if now.Sub(o.last) > r.ttl {
+			delete(r.owners, key)
+		}
+	}
+}
+
+// Identity returns the resource backing the instance, the same for the
+// instance of a cloud provider and the one of its Kubernetes node:
+//   - the ID of the EC2 instances, the last part of aws:///eu-west-1a/i-0123
+//   - the project, zone and name of the GCE instances, gce://project/zone/name
+//   - the resource ID of the Azure VMs, with the subscription and the
+//     resource group, in lower case
+//
+// The instances of the nodes have their provider ID in the v1.ProviderIDLabel
+func Identity(instance *v1.Instance) (string, bool) {
+	key, _, ok := identity(instance)
+	return key, ok
+}
+
+// identity returns the resource backing the instance and its provider
+func identity(instance *v1.Instance) (string, v1.Provider, bool) {
+	if providerID := instance.Labels[v1.ProviderIDLabel]; providerID != "" {
+		scheme, path, ok := strings.Cut(providerID, "://")
+		if !ok {
+			return "", "", false
+		}
+
+		provider, ok := providerIDs[scheme]
+		if !ok {
+			return "", "", false
+		}
+
+		var id string
+		switch provider {
+		case v1.GCP:
+			// the project, the zone and the name
+			if parts := strings.Split(path, "/"); len(parts) == 3 {
+				id = resourceID(parts...)
+			}
+		case v1.Azure:
+			id = resourceID(strings.ToLower(strings.Trim(path, "/")))
+		default:
+			id = resourceID(path[strings.LastIndex(path, "/")+1:])
+		}
+		if id == "" {
+			return "", "", false
+		}
+
+		return provider.String() + "/" + id, provider, true
+	}
+
+	var id string
+	switch instance.Provider {
+	case v1.GCP:
+		// the GCE instances are named after their numeric ID
+		name := instance.Name
+		if n := instance.Labels[v1.NameLabel]; n != "" {
+			name = n
+		}
+		id = resourceID(instance.Labels[v1.ProjectLabel], instance.Zone, name)
+	case v1.Azure:
+		id = resourceID(strings.ToLower(strings.Trim(instance.Labels[azureIDLabel], "/")))
+	default:
+		id = resourceID(instance.Name)
+	}
+	if id == "" {
+		return "", "", false
+	}
+
+	return instance.Provider.String() + "/" + id, instance.Provider, true
+}
+
+// resourceID joins the parts of the ID of a resource, none when a part is
+// missing
+func resourceID(parts ...string) string {
+	for _, p := range parts {
+		if p == "" {
+			return ""
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,128 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance v1.Instance
+		expected string
+	}{
+		{
+			name:     "ec2",
+			instance: v1.Instance{Name: "i-0123", Provider: v1.AWS, Labels: v1.Labels{v1.NameLabel: "web"}},
+			expected: "aws/i-0123",
+		},
+		{
+			name:     "gce",
+			instance: v1.Instance{Name: "4718", Provider: v1.GCP, Zone: "europe-west1-b", Labels: v1.Labels{v1.NameLabel: "gke-pool-1-abcd", v1.ProjectLabel: "demo"}},
+			expected: "gcp/demo/europe-west1-b/gke-pool-1-abcd",
+		},
+		{
+			name:     "gce without project",
+			instance: v1.Instance{Name: "4718", Provider: v1.GCP, Zone: "europe-west1-b", Labels: v1.Labels{v1.NameLabel: "gke-pool-1-abcd"}},
+		},
+		{
+			name:     "azure vm",
+			instance: v1.Instance{Name: "aks-pool-1", Provider: v1.Azure, Labels: v1.Labels{"ID": "/subscriptions/s/resourceGroups/G/providers/Microsoft.Compute/virtualMachines/aks-pool-1"}},
+			expected: "azure/subscriptions/s/resourcegroups/g/providers/microsoft.compute/virtualmachines/aks-pool-1",
+		},
+		{
+			name:     "eks node",
+			instance: v1.Instance{Name: "ip-10-0-0-1", Provider: "kubernetes", Labels: v1.Labels{v1.ProviderIDLabel: "aws:///eu-west-1a/i-0123"}},
+			expected: "aws/i-0123",
+		},
+		{
+			name:     "gke node",
+			instance: v1.Instance{Name: "gke-pool-1-abcd", Provider: "kubernetes", Labels: v1.Labels{v1.ProviderIDLabel: "gce://demo/europe-west1-b/gke-pool-1-abcd"}},
+			expected: "gcp/demo/europe-west1-b/gke-pool-1-abcd",
+		},
+		{
+			name:     "aks node",
+			instance: v1.Instance{Name: "aks-pool-1", Provider: "kubernetes", Labels: v1.Labels{v1.ProviderIDLabel: "azure:///subscriptions/s/resourceGroups/g/providers/Microsoft.Compute/virtualMachines/aks-pool-1"}},
+			expected: "azure/subscriptions/s/resourcegroups/g/providers/microsoft.compute/virtualmachines/aks-pool-1",
+		},
+		{
+			name:     "unknown provider ID",
+			instance: v1.Instance{Name: "kind-1", Provider: "kubernetes", Labels: v1.Labels{v1.ProviderIDLabel: "kind://docker/kind/kind-1"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			key, ok := Identity(&test.instance)
+			assert.Equal(test.expected != "", ok)
+			assert.Equal(test.expected, key)
+		})
+	}
+}
+
+func TestCounted(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := New(&config.DeduplicationConfig{Precedence: []string{"aws", "kubernetes"}}, 5*time.Minute)
+	r.now = func() time.Time { return now }
+
+	cloud := v1.Instance{Name: "i-0123", Provider: v1.AWS}
+	node := v1.Instance{Name: "ip-10-0-0-1", Provider: "kubernetes", Labels: v1.Labels{v1.ProviderIDLabel: "aws:///eu-west-1a/i-0123"}}
+	other := v1.Instance{Name: "ip-10-0-0-2", Provider: "kubernetes", Labels: v1.Labels{v1.ProviderIDLabel: "aws:///eu-west-1a/i-0456"}}
+
+	// the cloud provider takes precedence whatever the order they report
+	// the resource in
+	assert.False(r.Counted(&node))
+	assert.True(r.Counted(&cloud))
+	assert.False(r.Counted(&node))
+	assert.False(r.Counted(&other))
+
+	// the cloud provider keeps it over the next scrapes
+	now = now.Add(5 * time.Minute)
+	assert.False(r.Counted(&node))
+	assert.True(r.Counted(&cloud))
+	assert.False(r.Counted(&other))
+
+	// the node not reported by the cloud provider is counted after a
+	// missed scrape
+	now = now.Add(6 * time.Minute)
+	assert.True(r.Counted(&other))
+	assert.False(r.Counted(&node))
+
+	// the node takes over once the cloud provider stops reporting it
+	now = now.Add(5 * time.Minute)
+	assert.True(r.Counted(&node))
+	assert.True(r.Counted(&other))
+	assert.True(r.Counted(&cloud))
+	assert.False(r.Counted(&node))
+
+	// the instances are forgotten once not reported anymore, the cloud
+	// provider is waited for again
+	now = now.Add(time.Hour)
+	assert.False(r.Counted(&other))
+	assert.Len(r.owners, 1)
+
+	// every instance is counted without a resolver
+	var none *Resolver
+	assert.True(none.Counted(&node))
+}
+
+func TestCountedUnlisted(t *testing.T) {
+	assert := require.New(t)
+
+	r := New(&config.DeduplicationConfig{}, 5*time.Minute)
+
+	// the first collector keeps the resources of the unlisted providers
+	cloud := v1.Instance{Name: "i-0123", Provider: v1.AWS}
+	node := v1.Instance{Name: "ip-10-0-0-1", Provider: "kubernetes", Labels: v1.Labels{v1.ProviderIDLabel: "aws:///eu-west-1a/i-0123"}}
+	assert.True(r.Counted(&node))
+	assert.False(r.Counted(&cloud))
+	assert.True(r.Counted(&node))
+}
@@ -0,0 +1,146 @@
+package efficiency
+
+import (
+	"sort"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+)
+
+// The value of the instances without the label the view is grouped by
+const unset = "(none)"
+
+// View is the efficiency of the compute instances over a period, grouped by
+// a label, and the one of the period of the same length before, so that the
+// indicators can be tracked period over period
+type View struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	GroupBy string    `json:"groupBy"`
+
+	// The groups sorted by emissions, the highest first
+	Rows []Row `json:"rows"`
+
+	// The sum of all the groups
+	Total Row `json:"total"`
+
+	// The sum of all the groups over the period before, empty without
+	// samples in it
+	Previous Row `json:"previous"`
+}
+
+// Row is the efficiency of the instances of a group
+type Row struct {
+	Group     string `json:"group"`
+	Instances int    `json:"instances"`
+
+	// The operational and embodied emissions in gCO2eq
+	Emissions float64 `json:"emissions"`
+
+	// The vCPU hours of the instances and their utilization in %, averaged
+	// over the vCPU hours
+	VCPUHours   float64 `json:"vcpuHours"`
+	Utilization float64 `json:"utilization"`
+
+	// The emissions per vCPU hour
+	GCO2ePerVCPUHour float64 `json:"gco2ePerVCPUHour"`
+
+	// The emissions of the idle capacity, the share of the emissions of the
+	// vCPUs left unused, and their share of the emissions in %
+	IdleEmissions float64 `json:"idleEmissions"`
+	IdleShare     float64 `json:"idleShare"`
+
+	// The vCPU hours used and the instances counted
+	used      float64
+	instances map[string]bool
+}
+
+// Compute returns the view of the compute instances sampled in [from, to),
+// grouped by a field or a label of their samples, and the total of the
+// samples in the period of the same length before. The samples without
+// vCPUs, e.g. the network and the storage resources, are left out
+func Compute(samples []store.Sample, from, to time.Time, groupBy string) *View {
+	previous := from.Add(-to.Sub(from))
+
+	v := &View{
+		From:     from,
+		To:       to,
+		GroupBy:  groupBy,
+		Total:    Row{Group: "total"},
+		Previous: Row{Group: "previous"},
+	}
+
+	rows := make(map[string]*Row)
+	for i := range samples {
+		s := &samples[i]
+		if s.VCPUHours() <= 0 || s.Time.Before(previous) || !s.Time.Before(to) {
+			continue
+		}
+
+		if s.Time.Before(from) {
+			v.Previous.add(s)
+			continue
+		}
+
+		group := s.Label(groupBy)
+		if group == "" {
+			group = unset
+		}
+
+		row, ok := rows[group]
+		if !ok {
+			row = &Row{Group: group}
+			rows[group] = row
+		}
+
+		row.add(s)
+		v.Total.add(s)
+	}
+
+	v.Rows = make([]Row, 0, len(rows))
+	for _, row := range rows {
+		row.ratio()
+		v.Rows = append(v.Rows, *row)
+	}
+	v.Total.ratio()
+	v.Previous.ratio()
+
+	sort.Slice(v.Rows, func(i, j int) bool {
+		if v.Rows[i].Emissions != v.Rows[j].Emissions {
+			return v.Rows[i].Emissions > v.Rows[j].Emissions
+		}
+		return v.Rows[i].Group < v.Rows[j].Group
+	})
+
+	return v
+}
+
+// add adds a sample to the row, the utilization is clamped to 0-100% so that
+// an implausible sample doesn't make the idle emissions negative
+func (r *Row) add(s *store.Sample) {
+	if r.instances == nil {
+		r.instances = make(map[string]bool)
+	}
+	r.instances[s.Provider.String()+"/"+s.Name] = true
+
+	utilization := min(max(s.Utilization, 0), 100) / 100
+	emissions := s.Operational + s.Embodied
+
+	r.Emissions += emissions
+	r.VCPUHours += s.VCPUHours()
+	r.used += s.VCPUHours() * utilization
+	r.IdleEmissions += emissions * (1 - utilization)
+}
+
+// ratio sets the indicators of the row once all its samples were added
+func (r *Row) ratio() {
+	r.Instances = len(r.instances)
+
+	if r.VCPUHours > 0 {
+		r.Utilization = r.used / r.VCPUHours * 100
+		r.GCO2ePerVCPUHour = r.Emissions / r.VCPUHours
+	}
+	if r.Emissions > 0 {
+		r.IdleShare = r.IdleEmissions / r.Emissions * 100
+	}
+}
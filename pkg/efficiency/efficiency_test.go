@@ -0,0 +1,80 @@
+package efficiency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	assert := require.New(t)
+
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	sample := func(at time.Time, name, team string, vCPU, utilization, emissions float64) store.Sample {
+		return store.Sample{
+			Time:        at,
+			Provider:    v1.AWS,
+			Name:        name,
+			Labels:      v1.Labels{"team": team},
+			Operational: emissions * 0.75,
+			Embodied:    emissions * 0.25,
+			VCPU:        vCPU,
+			Utilization: utilization,
+			Hours:       1,
+		}
+	}
+
+	samples := []store.Sample{
+		// the quarter before
+		sample(from.AddDate(0, -1, 0), "i-a", "data", 4, 10, 80),
+		sample(from.Add(time.Hour), "i-a", "data", 4, 50, 100),
+		sample(from.Add(2*time.Hour), "i-a", "data", 4, 30, 100),
+		sample(from.Add(time.Hour), "i-b", "web", 2, 20, 40),
+		// implausible
+		sample(from.Add(time.Hour), "i-c", "web", 2, 150, 10),
+		// without vCPUs, e.g. a load balancer
+		sample(from.Add(time.Hour), "elb", "web", 0, 0, 500),
+		// after the period
+		sample(to, "i-a", "data", 4, 10, 100),
+	}
+
+	v := Compute(samples, from, to, "team")
+	assert.Equal("team", v.GroupBy)
+	assert.Len(v.Rows, 2)
+
+	data := v.Rows[0]
+	assert.Equal("data", data.Group)
+	assert.Equal(1, data.Instances)
+	assert.Equal(200.0, data.Emissions)
+	assert.Equal(8.0, data.VCPUHours)
+	assert.InDelta(40.0, data.Utilization, 1e-9)
+	assert.Equal(25.0, data.GCO2ePerVCPUHour)
+	assert.InDelta(120.0, data.IdleEmissions, 1e-9)
+	assert.InDelta(60.0, data.IdleShare, 1e-9)
+
+	web := v.Rows[1]
+	assert.Equal(2, web.Instances)
+	assert.Equal(50.0, web.Emissions)
+	assert.InDelta(60.0, web.Utilization, 1e-9)
+	assert.InDelta(32.0, web.IdleEmissions, 1e-9)
+
+	assert.Equal(3, v.Total.Instances)
+	assert.Equal(250.0, v.Total.Emissions)
+	assert.Equal(12.0, v.Total.VCPUHours)
+	assert.InDelta(152.0/250*100, v.Total.IdleShare, 1e-9)
+
+	// quarter over quarter
+	assert.Equal(80.0, v.Previous.Emissions)
+	assert.Equal(10.0, v.Previous.Utilization)
+	assert.Equal(20.0, v.Previous.GCO2ePerVCPUHour)
+
+	// the instances without the label
+	v = Compute(samples, from, to, "application")
+	assert.Len(v.Rows, 1)
+	assert.Equal("(none)", v.Rows[0].Group)
+}
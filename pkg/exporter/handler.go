@@ -2,27 +2,111 @@ package exporter
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/cardinality"
 	"github.com/re-cinq/aether/pkg/log"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/re-cinq/aether/pkg/units"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	api "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/metric"
 )
 
+// The labels the metrics are identified by, they are always exported
+var fixedLabels = []string{"provider", "type", "unit", "quality"}
+
+// The labels of the normalized taxonomy of the instances
+var taxonomyLabels = []string{v1.FamilyLabel, v1.VCPULabel, v1.MemoryGBLabel}
+
+// Mode is how the emissions are exported
+type Mode string
+
+// The modes of the emissions
+const (
+	// The emissions of the last scraping interval, as gauges
+	ModeGauge Mode = "gauge"
+
+	// The emissions since the exporter started, as counters
+	ModeCounter Mode = "counter"
+
+	// Both the gauges and the counters
+	ModeBoth Mode = "both"
+)
+
+// ParseMode returns the mode, both when empty
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case "":
+		return ModeBoth, nil
+	case ModeGauge, ModeCounter, ModeBoth:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q, expected gauge, counter or both", s)
+	}
+}
+
+// series is the value of a series of an instrument
+type series struct {
+	// The metric of the instrument, emissions or embodied
+	metric string
+
+	// The instrument, nil when it isn't exported
+	instrument api.Float64Observable
+	labels     map[string]string
+	value      float64
+
+	// When the emissions of the series were last calculated
+	seen time.Time
+}
+
 // PromHandler is the Event handnelr used to configure prometheus
 // metrics
 type PromHandler struct {
 	Bus    *bus.Bus
 	meter  api.Meter
 	logger *slog.Logger
+
+	// The unit and precision of the emissions
+	output units.Output
+
+	// The labels exported and the limit of the series, none when nil
+	limiter *cardinality.Limiter
+
+	// The gauges of the last emissions and the counters of their sum, nil
+	// when not exported
+	emissions      api.Float64ObservableGauge
+	embodied       api.Float64ObservableGauge
+	emissionsTotal api.Float64ObservableCounter
+	embodiedTotal  api.Float64ObservableCounter
+
+	// The latest series of the instances, by instance and metric. The ones
+	// whose labels are the same after the limiter are summed
+	series map[string]series
+
+	// The sums of the emissions since the start, by counter and labels
+	totals map[string]*series
+
+	// How long the series of the instances no longer calculated are
+	// exported for, forever when not positive
+	staleness time.Duration
+	now       func() time.Time
+
+	mu sync.Mutex
 }
 
-// NewHandler returns a configured instance of PromHandler
-func NewHandler(ctx context.Context, b *bus.Bus) *PromHandler {
+// NewHandler returns a configured instance of PromHandler, the emissions
+// are exported in the unit of the output and labeled with it, as gauges,
+// counters or both depending on the mode. The series are limited by the
+// limiter unless it's nil, and evicted once they weren't calculated for the
+// staleness unless it's not positive
+func NewHandler(ctx context.Context, b *bus.Bus, output units.Output, limiter *cardinality.Limiter, mode Mode, staleness time.Duration) *PromHandler {
 	logger := log.FromContext(ctx)
 
 	exporter, err := prometheus.New()
@@ -35,11 +119,73 @@ func NewHandler(ctx context.Context, b *bus.Bus) *PromHandler {
 		metric.WithReader(exporter),
 	).Meter("cloud-carbon")
 
-	return &PromHandler{
-		Bus:    b,
-		meter:  meter,
-		logger: logger,
+	p := &PromHandler{
+		Bus:       b,
+		meter:     meter,
+		logger:    logger,
+		output:    output,
+		limiter:   limiter,
+		series:    make(map[string]series),
+		totals:    make(map[string]*series),
+		staleness: staleness,
+		now:       time.Now,
+	}
+
+	var instruments []api.Observable
+
+	if mode != ModeCounter {
+		// setup emissions gauge
+		p.emissions, err = meter.Float64ObservableGauge(
+			"emissions",
+			api.WithDescription("co2eq of various services"),
+		)
+		if err != nil {
+			logger.Error("[otel] failed setting up emissions metric", "error", err)
+			return nil
+		}
+
+		// setup embodied emissions gauge
+		p.embodied, err = meter.Float64ObservableGauge(
+			"embodied",
+			api.WithDescription("co2eq of various services"),
+		)
+		if err != nil {
+			logger.Error("[otel] failed setting up embodied emissions metric", "error", err)
+			return nil
+		}
+
+		instruments = append(instruments, p.emissions, p.embodied)
+	}
+
+	if mode != ModeGauge {
+		// setup the counters, exported with the _total suffix
+		p.emissionsTotal, err = meter.Float64ObservableCounter(
+			"emissions_total",
+			api.WithDescription("co2eq of various services since the exporter started"),
+		)
+		if err != nil {
+			logger.Error("[otel] failed setting up emissions counter", "error", err)
+			return nil
+		}
+
+		p.embodiedTotal, err = meter.Float64ObservableCounter(
+			"embodied_total",
+			api.WithDescription("embodied co2eq of various services since the exporter started"),
+		)
+		if err != nil {
+			logger.Error("[otel] failed setting up embodied emissions counter", "error", err)
+			return nil
+		}
+
+		instruments = append(instruments, p.emissionsTotal, p.embodiedTotal)
+	}
+
+	if _, err := meter.RegisterCallback(p.observe, instruments...); err != nil {
+		logger.Error("[otel] failed registering the emissions metrics", "error", err)
+		return nil
 	}
+
+	return p
 }
 
 func (p *PromHandler) Stop(ctx context.Context) {}
@@ -63,86 +209,172 @@ func (p *PromHandler) handleEvent(e *bus.Event) {
 		return
 	}
 
-	// setup emissions gauge
-	emissions, err := p.meter.Float64ObservableGauge(
-		"emissions",
-		api.WithDescription("co2eq of various services"),
-	)
-	if err != nil {
-		p.logger.Error("[otel] failed setting up emissions metric")
+	key := i.Provider.String() + "/" + i.Region + "/" + i.Name
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// NOTE: this will not change based on different types of metrics
+	labels := getLabelsFromInstance(&i)
+	labels["unit"] = string(p.output.Unit())
+	labels["quality"] = string(i.EmbodiedEmissions.Quality.Tier())
+	p.set("embodied/"+key, p.embodied, p.embodiedTotal, labels, i.EmbodiedEmissions.Value)
+
+	for _, m := range i.Metrics {
+		// setup metric labels
+		labels := make(map[string]string, len(m.Labels)+7)
+		for k, l := range m.Labels {
+			labels[k] = l
+		}
+		// the taxonomy of the instance, to compare the emissions of the
+		// providers by family and size
+		for _, k := range taxonomyLabels {
+			if l, ok := i.Labels[k]; ok {
+				labels[k] = l
+			}
+		}
+		labels["type"] = m.ResourceType.String()
+		labels["provider"] = i.Provider.String()
+		labels["unit"] = string(p.output.Unit())
+		labels["quality"] = string(m.Emissions.Quality.Tier())
+
+		p.set("emissions/"+key+"/"+m.Name, p.emissions, p.emissionsTotal, labels, m.Emissions.Value)
+	}
+}
+
+// set replaces the series of the gauge and adds the value to the one of the
+// counter, unless the limiter drops it
+func (p *PromHandler) set(key string, gauge api.Float64ObservableGauge, counter api.Float64ObservableCounter, labels map[string]string, value float64) {
+	if p.limiter != nil {
+		var ok bool
+		if labels, ok = p.limiter.Labels(labels); !ok {
+			delete(p.series, key)
+			return
+		}
+	}
+
+	metric, _, _ := strings.Cut(key, "/")
+	now := p.now()
+
+	// the series are kept without the gauge too, so that they're evicted
+	s := series{metric: metric, labels: labels, value: value, seen: now}
+	if gauge != nil {
+		s.instrument = gauge
+	}
+	p.series[key] = s
+
+	// the counters are kept by labels, they keep increasing when the labels
+	// of the instances change
+	if counter != nil {
+		total := metric + "/" + cardinality.Key(labels)
+		if t, ok := p.totals[total]; ok {
+			t.value += value
+			t.seen = now
+		} else {
+			p.totals[total] = &series{metric: metric, instrument: counter, labels: labels, value: value, seen: now}
+		}
+	}
+}
+
+// evict deletes the series that weren't calculated since the cutoff, e.g.
+// the ones of the deleted instances, and frees their place in the limiter
+func (p *PromHandler) evict(cutoff time.Time) {
+	evicted := make(map[string]map[string]string)
+	for key, s := range p.series {
+		if s.seen.Before(cutoff) {
+			evicted[cardinality.Key(s.labels)] = s.labels
+			delete(p.series, key)
+		}
+	}
+	for key, s := range p.totals {
+		if s.seen.Before(cutoff) {
+			evicted[cardinality.Key(s.labels)] = s.labels
+			delete(p.totals, key)
+		}
+	}
+
+	if len(evicted) == 0 {
 		return
 	}
+	p.logger.Debug("evicted stale series", "count", len(evicted))
 
-	// setup embodied emissions gauge
-	embodied, err := p.meter.Float64ObservableGauge(
-		"embodied",
-		api.WithDescription("co2eq of various services"),
-	)
-	if err != nil {
-		p.logger.Error("[otel] failed setting up embodied emissions metric")
+	if p.limiter == nil {
 		return
 	}
 
-	// register embodied emissions metrics for instance
-	// NOTE: this will not change based on different types of metrics
-	_, err = p.meter.RegisterCallback(
-		func(ctx context.Context, o api.Observer) error {
-			o.ObserveFloat64(
-				embodied,
-				i.EmbodiedEmissions.Value,
-				api.WithAttributes(
-					getAttributesFromInstance(&i)...,
-				))
+	// the labels still exported by another series keep their place
+	for _, s := range p.series {
+		delete(evicted, cardinality.Key(s.labels))
+	}
+	for _, s := range p.totals {
+		delete(evicted, cardinality.Key(s.labels))
+	}
+	for _, labels := range evicted {
+		p.limiter.Release(labels)
+	}
+}
 
-			return nil
-		}, embodied)
-	if err != nil {
-		p.logger.Error("failed setting embodied metric", "instance", i.Name)
-		return
+// observe observes the series of the gauges, the ones with the same labels
+// are summed, and the ones of the counters
+func (p *PromHandler) observe(ctx context.Context, o api.Observer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.staleness > 0 {
+		p.evict(p.now().Add(-p.staleness))
 	}
 
-	for _, m := range i.Metrics {
-		m := m
-		// setup metric labels
-		attrs := getAtrributesFromLabels(&m)
-		attrs = append(
-			attrs,
-			attribute.Key("type").String(m.ResourceType.String()),
-			attribute.Key("provider").String(i.Provider.String()),
-			attribute.Key("type").String(m.ResourceType.String()),
-		)
+	sums := make(map[string]*series, len(p.series))
+	for _, s := range p.series {
+		if s.instrument == nil {
+			continue
+		}
+		key := s.metric + "/" + cardinality.Key(s.labels)
 
-		// register emission metrics for instance
-		_, err := p.meter.RegisterCallback(
-			func(ctx context.Context, o api.Observer) error {
-				o.ObserveFloat64(
-					emissions,
-					m.Emissions.Value,
-					api.WithAttributes(attrs...),
-				)
-				return nil
-			}, emissions)
-		if err != nil {
-			p.logger.Error("failed setting metric", "instance", i.Name)
+		if sum, ok := sums[key]; ok {
+			sum.value += s.value
+			continue
 		}
+		sums[key] = &s
 	}
+
+	for _, s := range sums {
+		o.ObserveFloat64(s.instrument, p.output.Convert(s.value), api.WithAttributes(attributes(s.labels)...))
+	}
+	for _, s := range p.totals {
+		o.ObserveFloat64(s.instrument, p.output.Convert(s.value), api.WithAttributes(attributes(s.labels)...))
+	}
+
+	return nil
 }
 
-func getAtrributesFromLabels(m *v1.Metric) []attribute.KeyValue {
-	attrs := []attribute.KeyValue{}
-	for k, l := range m.Labels {
-		attrs = append(attrs, attribute.Key(k).String(l))
+// attributes returns the labels as attributes
+func attributes(labels map[string]string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for k, v := range labels {
+		attrs = append(attrs, attribute.Key(k).String(v))
 	}
 	return attrs
 }
 
-func getAttributesFromInstance(i *v1.Instance) []attribute.KeyValue {
-	return []attribute.KeyValue{
-		attribute.Key("kind").String(i.Kind),
-		attribute.Key("name").String(i.Name),
-		attribute.Key("zone").String(i.Zone),
-		attribute.Key("region").String(i.Region),
-		attribute.Key("service").String(i.Service),
-		attribute.Key("provider").String(i.Provider.String()),
+func getLabelsFromInstance(i *v1.Instance) map[string]string {
+	return map[string]string{
+		"kind":         i.Kind,
+		"name":         i.Name,
+		"zone":         i.Zone,
+		"region":       i.Region,
+		"service":      i.Service,
+		"provider":     i.Provider.String(),
+		"architecture": i.Architecture,
+		"cpu_platform": i.CPUPlatform,
+		"family":       i.Labels[v1.FamilyLabel],
+		"vcpu":         i.Labels[v1.VCPULabel],
+		"memory_gb":    i.Labels[v1.MemoryGBLabel],
 	}
 }
+
+// FixedLabels returns the labels the metrics of the instances are
+// identified by, they can't be dropped
+func FixedLabels() []string {
+	return fixedLabels
+}
@@ -0,0 +1,126 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/cardinality"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/re-cinq/aether/pkg/units"
+	"github.com/stretchr/testify/require"
+)
+
+// gather returns the values of the series of the metric by their name label
+func gather(t *testing.T, metric string) map[string]float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != metric {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			var name string
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "name" {
+					name = l.GetValue()
+				}
+			}
+			if m.GetCounter() != nil {
+				values[name] += m.GetCounter().GetValue()
+			} else {
+				values[name] += m.GetGauge().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestHandler(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	limiter, err := cardinality.New("instances", &config.CardinalityConfig{
+		MaxSeries:   1,
+		Overflow:    cardinality.OverflowHash,
+		HashLabels:  []string{"name"},
+		HashBuckets: 1,
+	}, FixedLabels()...)
+	assert.NoError(err)
+
+	p := NewHandler(ctx, bus.New(), units.Output{}, limiter, ModeBoth, 0)
+	assert.NotNil(p)
+
+	handle := func(name string, embodied float64) {
+		i := v1.NewInstance(name, v1.AWS)
+		i.EmbodiedEmissions = v1.NewResourceEmission(embodied, v1.GCO2eqkWh)
+		p.Handle(ctx, &bus.Event{Type: v1.EmissionsCalculatedEvent, Data: *i})
+	}
+
+	// the gauges are the last emissions, the counters their sum
+	handle("i-1", 2)
+	handle("i-1", 3)
+	assert.Equal(map[string]float64{"i-1": 3}, gather(t, "embodied"))
+	assert.Equal(map[string]float64{"i-1": 5}, gather(t, "embodied_total"))
+
+	// the series past the limit are summed into the bucket
+	handle("i-2", 4)
+	handle("i-3", 1)
+	handle("i-3", 1)
+	assert.Equal(map[string]float64{"i-1": 3, "hash_0": 5}, gather(t, "embodied"))
+	assert.Equal(map[string]float64{"i-1": 5, "hash_0": 6}, gather(t, "embodied_total"))
+}
+
+func TestHandlerEvict(t *testing.T) {
+	assert := require.New(t)
+
+	limiter, err := cardinality.New("instances", &config.CardinalityConfig{MaxSeries: 1}, FixedLabels()...)
+	assert.NoError(err)
+
+	now := time.Now()
+	p := &PromHandler{
+		logger:  slog.Default(),
+		limiter: limiter,
+		series:  make(map[string]series),
+		totals:  make(map[string]*series),
+		now:     func() time.Time { return now },
+	}
+
+	p.set("embodied/i-1", nil, nil, map[string]string{"name": "i-1"}, 1)
+	now = now.Add(time.Hour)
+
+	// the limit is reached until the stale series is evicted
+	p.set("embodied/i-2", nil, nil, map[string]string{"name": "i-2"}, 1)
+	assert.NotContains(p.series, "embodied/i-2")
+
+	p.evict(now.Add(-time.Minute))
+	assert.Empty(p.series)
+
+	p.set("embodied/i-2", nil, nil, map[string]string{"name": "i-2"}, 1)
+	assert.Contains(p.series, "embodied/i-2")
+
+	// the fresh series are kept
+	p.evict(now.Add(-time.Minute))
+	assert.Len(p.series, 1)
+}
+
+func TestParseMode(t *testing.T) {
+	assert := require.New(t)
+
+	for s, expected := range map[string]Mode{"": ModeBoth, "gauge": ModeGauge, "Counter": ModeCounter, "both": ModeBoth} {
+		m, err := ParseMode(s)
+		assert.NoError(err)
+		assert.Equal(expected, m)
+	}
+
+	_, err := ParseMode("delta")
+	assert.Error(err)
+}
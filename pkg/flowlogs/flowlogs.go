@@ -0,0 +1,219 @@
+// Package flowlogs reads the summaries of the VPC Flow Logs of AWS and GCP,
+// the bytes the instances transferred by direction and destination, e.g.
+// queried with Athena or BigQuery, and adds them to the instances as their
+// network metrics
+package flowlogs
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The directions of the flows
+const (
+	Egress  = "egress"
+	Ingress = "ingress"
+)
+
+// The columns of a summary, in any order
+var columns = []string{"start", "end", "instance", "direction", "destination", "bytes"}
+
+// Flow is the bytes an instance transferred in a direction with the
+// destinations of a class over a period
+type Flow struct {
+	Start, End time.Time
+
+	// The ID or the name of the instance
+	Instance string
+
+	Direction   string
+	Destination string
+
+	Bytes float64
+}
+
+// Parse reads the flows of a CSV summary with a start, end, instance,
+// direction, destination and bytes header. The times are RFC 3339
+func Parse(r io.Reader) ([]Flow, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading the header: %w", err)
+	}
+
+	index := make(map[string]int, len(columns))
+	for _, name := range columns {
+		index[name] = -1
+		for i, h := range header {
+			if h == name {
+				index[name] = i
+			}
+		}
+		if index[name] < 0 {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	var flows []Flow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return flows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		f := Flow{
+			Instance:    record[index["instance"]],
+			Direction:   record[index["direction"]],
+			Destination: record[index["destination"]],
+		}
+
+		switch f.Direction {
+		case Egress, Ingress:
+		default:
+			return nil, fmt.Errorf("line %d: invalid direction %q", line, f.Direction)
+		}
+		switch f.Destination {
+		case v1.DestinationIntraRegion, v1.DestinationInterRegion, v1.DestinationInternet:
+		default:
+			return nil, fmt.Errorf("line %d: invalid destination %q", line, f.Destination)
+		}
+
+		if f.Start, err = time.Parse(time.RFC3339, record[index["start"]]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid start %q", line, record[index["start"]])
+		}
+		if f.End, err = time.Parse(time.RFC3339, record[index["end"]]); err != nil || !f.End.After(f.Start) {
+			return nil, fmt.Errorf("line %d: invalid end %q", line, record[index["end"]])
+		}
+		if f.Bytes, err = strconv.ParseFloat(record[index["bytes"]], 64); err != nil || f.Bytes < 0 {
+			return nil, fmt.Errorf("line %d: invalid bytes %q", line, record[index["bytes"]])
+		}
+
+		flows = append(flows, f)
+	}
+}
+
+// Source is a summary file of the flow logs, read again when it changes,
+// e.g. when it's synced from the bucket the queries export to
+type Source struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	flows   map[string][]Flow
+}
+
+// New returns the source of the summary file, nil when the path is empty
+func New(path string) *Source {
+	if path == "" {
+		return nil
+	}
+	return &Source{path: path}
+}
+
+// Refresh reads the summary when it changed since it was last read. The
+// flows read before are kept when it can't be read
+func (s *Source) Refresh() error {
+	if s == nil {
+		return nil
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	flows, err := Parse(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	byInstance := make(map[string][]Flow)
+	for _, flow := range flows {
+		byInstance[flow.Instance] = append(byInstance[flow.Instance], flow)
+	}
+
+	s.mu.Lock()
+	s.modTime = info.ModTime()
+	s.flows = byInstance
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Apply adds the network metrics of the instance over the window, one per
+// destination, with the GB it transferred. The flows are matched by the ID
+// of the instance or its name, and prorated to their part in the window.
+// The egress is accounted for, and the ingress from the internet only: the
+// other ingress is the egress of another instance
+func (s *Source) Apply(instance *v1.Instance, window v1.Window) {
+	if s == nil {
+		return
+	}
+
+	s.mu.RLock()
+	flows, ok := s.flows[instance.Name]
+	if !ok {
+		flows = s.flows[instance.Labels[v1.NameLabel]]
+	}
+	s.mu.RUnlock()
+
+	type class struct {
+		direction, destination string
+	}
+	gb := make(map[class]float64)
+	for _, f := range flows {
+		if f.Direction == Ingress && f.Destination != v1.DestinationInternet {
+			continue
+		}
+
+		overlap := window.Intersect(v1.Window{Start: f.Start, End: f.End})
+		if overlap.Duration() <= 0 {
+			continue
+		}
+
+		c := class{direction: f.Direction, destination: f.Destination}
+		gb[c] += f.Bytes / 1e9 * overlap.Duration().Seconds() / f.End.Sub(f.Start).Seconds()
+	}
+
+	for c, value := range gb {
+		instance.Metrics.Upsert(&v1.Metric{
+			Name:         fmt.Sprintf("%s-%s-%s", v1.Network, c.direction, c.destination),
+			ResourceType: v1.Network,
+			UnitAmount:   value,
+			Unit:         v1.GB,
+			UpdatedAt:    window.End.UTC(),
+			Labels: v1.Labels{
+				v1.DirectionLabel:   c.direction,
+				v1.DestinationLabel: c.destination,
+			},
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package flowlogs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+const summary = `instance,direction,destination,bytes,start,end
+i-1,egress,internet,3600000000000,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z
+i-1,egress,inter-region,1200000000,2024-01-01T00:00:00Z,2024-01-01T00:10:00Z
+i-1,ingress,internet,360000000000,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z
+i-1,ingress,intra-region,999000000000,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z
+i-1,egress,internet,1000000000,2024-01-01T02:00:00Z,2024-01-01T03:00:00Z
+web,egress,intra-region,3000000000,2024-01-01T00:00:00Z,2024-01-01T00:05:00Z
+`
+
+func TestParse(t *testing.T) {
+	assert := require.New(t)
+
+	flows, err := Parse(strings.NewReader(summary))
+	assert.NoError(err)
+	assert.Len(flows, 6)
+	assert.Equal(Flow{
+		Start:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+		Instance:    "i-1",
+		Direction:   Egress,
+		Destination: v1.DestinationInternet,
+		Bytes:       3.6e12,
+	}, flows[0])
+
+	type testcase struct {
+		description string
+		content     string
+		err         string
+	}
+	tt := []testcase{
+		{
+			description: "missing column",
+			content:     "instance,direction,destination,bytes,start\n",
+			err:         "missing column end",
+		},
+		{
+			description: "invalid direction",
+			content:     "start,end,instance,direction,destination,bytes\n2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,i-1,out,internet,1\n",
+			err:         `line 2: invalid direction "out"`,
+		},
+		{
+			description: "invalid destination",
+			content:     "start,end,instance,direction,destination,bytes\n2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,i-1,egress,moon,1\n",
+			err:         `line 2: invalid destination "moon"`,
+		},
+		{
+			description: "end before the start",
+			content:     "start,end,instance,direction,destination,bytes\n2024-01-01T01:00:00Z,2024-01-01T00:00:00Z,i-1,egress,internet,1\n",
+			err:         `line 2: invalid end "2024-01-01T00:00:00Z"`,
+		},
+		{
+			description: "negative bytes",
+			content:     "start,end,instance,direction,destination,bytes\n2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,i-1,egress,internet,-1\n",
+			err:         `line 2: invalid bytes "-1"`,
+		},
+	}
+
+	for _, test := range tt {
+		_, err := Parse(strings.NewReader(test.content))
+		assert.EqualError(err, test.err, test.description)
+	}
+}
+
+func TestSource(t *testing.T) {
+	assert := require.New(t)
+
+	// without a summary nothing is applied
+	var none *Source
+	assert.Nil(New(""))
+	assert.NoError(none.Refresh())
+	none.Apply(v1.NewInstance("i-1", v1.AWS), v1.Window{})
+
+	path := filepath.Join(t.TempDir(), "flows.csv")
+	assert.NoError(os.WriteFile(path, []byte(summary), 0o600))
+
+	s := New(path)
+	assert.NoError(s.Refresh())
+
+	window := v1.NewWindow(time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), 5*time.Minute)
+
+	i := v1.NewInstance("i-1", v1.AWS)
+	s.Apply(i, window)
+
+	// the flows are prorated to their part in the window, the ingress is
+	// only accounted for from the internet
+	assert.Len(i.Metrics, 3)
+	internet := i.Metrics["network-egress-internet"]
+	assert.Equal(v1.Network, internet.ResourceType)
+	assert.Equal(v1.GB, internet.Unit)
+	assert.InDelta(300, internet.UnitAmount, 1e-9)
+	assert.Equal(v1.Labels{v1.DirectionLabel: Egress, v1.DestinationLabel: v1.DestinationInternet}, internet.Labels)
+	assert.True(window.End.Equal(internet.UpdatedAt))
+	assert.InDelta(0.6, i.Metrics["network-egress-inter-region"].UnitAmount, 1e-9)
+	assert.InDelta(30, i.Metrics["network-ingress-internet"].UnitAmount, 1e-9)
+
+	// the instances are matched by their name too
+	web := v1.NewInstance("1234", v1.GCP)
+	web.Labels.Add(v1.NameLabel, "web")
+	s.Apply(web, v1.NewWindow(time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), 5*time.Minute))
+	assert.InDelta(3, web.Metrics["network-egress-intra-region"].UnitAmount, 1e-9)
+
+	// the flows read before are kept when the summary is broken
+	assert.NoError(os.WriteFile(path, []byte("broken\n"), 0o600))
+	assert.NoError(os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	assert.Error(s.Refresh())
+
+	i = v1.NewInstance("i-1", v1.AWS)
+	s.Apply(i, window)
+	assert.Len(i.Metrics, 3)
+}
@@ -0,0 +1,122 @@
+// Package grouping derives the application and team labels of the instances
+// from rules matching their fields and tags, so that the emissions of every
+// provider can be aggregated by the same dimensions
+package grouping
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The regex of the rules which don't set one, any value that isn't empty
+const defaultRegex = "(.+)"
+
+// rule is a validated grouping rule
+type rule struct {
+	source      string
+	regex       *regexp.Regexp
+	application string
+	team        string
+}
+
+// Rules set the application and team labels of the instances, a nil Rules
+// leaves them unchanged
+type Rules []rule
+
+// New validates the grouping rules
+func New(cfg *config.GroupingConfig) (Rules, error) {
+	rules := make(Rules, 0, len(cfg.Rules))
+
+	for i := range cfg.Rules {
+		c := &cfg.Rules[i]
+		if c.Source == "" {
+			return nil, fmt.Errorf("grouping rule %d: missing source", i)
+		}
+		if c.Application == "" && c.Team == "" {
+			return nil, fmt.Errorf("grouping rule %d: sets neither the application nor the team", i)
+		}
+
+		expr := c.Regex
+		if expr == "" {
+			expr = defaultRegex
+		}
+
+		// the regex is anchored on both ends
+		regex, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("grouping rule %d: invalid regex: %w", i, err)
+		}
+
+		rules = append(rules, rule{
+			source:      c.Source,
+			regex:       regex,
+			application: c.Application,
+			team:        c.Team,
+		})
+	}
+
+	return rules, nil
+}
+
+// Apply sets the application and team labels of the instance from the first
+// rules matching it. The labels the rules don't set are left as they are,
+// e.g. the ones set by a plugin
+func (rules Rules) Apply(instance *v1.Instance) {
+	var application, team string
+
+	for i := range rules {
+		r := &rules[i]
+		if application != "" && team != "" {
+			break
+		}
+
+		value := source(instance, r.source)
+		match := r.regex.FindStringSubmatchIndex(value)
+		if match == nil {
+			continue
+		}
+
+		if application == "" && r.application != "" {
+			application = string(r.regex.ExpandString(nil, r.application, value, match))
+		}
+		if team == "" && r.team != "" {
+			team = string(r.regex.ExpandString(nil, r.team, value, match))
+		}
+	}
+
+	// the labels are copied, they can be shared with the caches of the
+	// scrapers
+	if application != "" {
+		instance.Labels = instance.Labels.With(v1.ApplicationLabel, application)
+	}
+	if team != "" {
+		instance.Labels = instance.Labels.With(v1.TeamLabel, team)
+	}
+}
+
+// source returns the value of a field or a label of the instance, the name
+// is the one of v1.NameLabel when the providers set it
+func source(instance *v1.Instance, name string) string {
+	switch name {
+	case "name":
+		if name := instance.Labels[v1.NameLabel]; name != "" {
+			return name
+		}
+		return instance.Name
+	case "provider":
+		return instance.Provider.String()
+	case "service":
+		return instance.Service
+	case "region":
+		return instance.Region
+	case "zone":
+		return instance.Zone
+	case "kind":
+		return instance.Kind
+	default:
+		return instance.Labels[name]
+	}
+}
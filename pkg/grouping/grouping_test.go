@@ -0,0 +1,113 @@
+package grouping
+
+import (
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	cfg := config.GroupingConfig{
+		Rules: []config.GroupingRule{
+			{Source: "tag_app", Application: "$1"},
+			{Source: "tag_Name", Regex: "(web|api)-.*", Application: "$1", Team: "storefront"},
+			{Source: "name", Regex: "(web|api)-.*", Application: "$1", Team: "storefront"},
+			{Source: "tag_team", Team: "$1"},
+			{Source: "service", Regex: "cloudsql", Application: "database", Team: "platform"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		instance v1.Instance
+		expected v1.Labels
+	}{
+		{
+			name:     "aws name tag",
+			instance: v1.Instance{Name: "i-0123", Provider: v1.AWS, Labels: v1.Labels{"tag_Name": "web-1"}},
+			expected: v1.Labels{"tag_Name": "web-1", "application": "web", "team": "storefront"},
+		},
+		{
+			name:     "name label",
+			instance: v1.Instance{Name: "4718", Provider: v1.GCP, Labels: v1.Labels{"Name": "api-7f9c"}},
+			expected: v1.Labels{"Name": "api-7f9c", "application": "api", "team": "storefront"},
+		},
+		{
+			name:     "instance name",
+			instance: v1.Instance{Name: "api-7f9c", Provider: v1.GCP},
+			expected: v1.Labels{"application": "api", "team": "storefront"},
+		},
+		{
+			name:     "first match wins",
+			instance: v1.Instance{Name: "web-1", Provider: v1.GCP, Labels: v1.Labels{"tag_app": "shop", "tag_team": "checkout"}},
+			expected: v1.Labels{"tag_app": "shop", "tag_team": "checkout", "application": "shop", "team": "storefront"},
+		},
+		{
+			name:     "team tag",
+			instance: v1.Instance{Name: "batch-1", Provider: v1.AWS, Labels: v1.Labels{"tag_team": "data"}},
+			expected: v1.Labels{"tag_team": "data", "team": "data"},
+		},
+		{
+			name:     "no match keeps the labels",
+			instance: v1.Instance{Name: "batch-1", Provider: v1.AWS, Labels: v1.Labels{"team": "data"}},
+			expected: v1.Labels{"team": "data"},
+		},
+		{
+			name:     "service",
+			instance: v1.Instance{Name: "orders", Provider: v1.GCP, Service: "cloudsql"},
+			expected: v1.Labels{"application": "database", "team": "platform"},
+		},
+	}
+
+	rules, err := New(&cfg)
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			labels := test.instance.Labels
+			rules.Apply(&test.instance)
+			assert.Equal(test.expected, test.instance.Labels)
+
+			// the labels of the instance are copied
+			if len(labels) > 0 {
+				assert.NotContains(labels, v1.ApplicationLabel)
+			}
+		})
+	}
+
+	// no rules
+	assert := require.New(t)
+	instance := v1.Instance{Name: "web-1"}
+	Rules(nil).Apply(&instance)
+	assert.Nil(instance.Labels)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.GroupingRule
+		err  string
+	}{
+		{name: "valid", rule: config.GroupingRule{Source: "name", Regex: "web-.*", Application: "web"}},
+		{name: "missing source", rule: config.GroupingRule{Team: "data"}, err: "missing source"},
+		{name: "no labels", rule: config.GroupingRule{Source: "name"}, err: "sets neither the application nor the team"},
+		{name: "invalid regex", rule: config.GroupingRule{Source: "name", Regex: "web-(", Team: "data"}, err: "invalid regex"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			_, err := New(&config.GroupingConfig{Rules: []config.GroupingRule{test.rule}})
+			if test.err == "" {
+				assert.NoError(err)
+				return
+			}
+			assert.ErrorContains(err, test.err)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package hardware estimates the embodied emissions of a server from its
+// hardware inventory, with the component factors of the Boavizta methodology
+// (https://doc.api.boavizta.org/Explanations/components/)
+package hardware
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Inventory is the hardware of a server, e.g. read from SMBIOS. The zero
+// counts are unknown and fall back on the defaults of the methodology
+type Inventory struct {
+	// The CPU model, e.g. Intel(R) Xeon(R) Gold 6230 CPU @ 2.10GHz
+	CPUModel string
+
+	// The CPU sockets and the physical cores of every CPU
+	Sockets int
+	Cores   int
+
+	// The memory modules and the size of every one of them in GB
+	DIMMs    int
+	DIMMSize float64
+
+	// The memory in GB, counted in modules when their count is unknown
+	Memory float64
+
+	// The SSDs, the size of every one of them in GB, and the HDDs
+	SSDs    int
+	SSDSize float64
+	HDDs    int
+}
+
+// cpu is the die of a CPU model
+type cpu struct {
+	// The physical cores
+	cores int
+
+	// The area of all the dies of the package in cm², e.g. the CCDs and the
+	// IO die of the AMD EPYC
+	dieSize float64
+}
+
+// cpus are the dies of the common server CPUs, by model without the vendor
+var cpus = map[string]cpu{
+	// Cascade Lake, the HCC die is 485 mm² and the XCC one 694 mm²
+	"xeon silver 4214":   {cores: 12, dieSize: 4.85},
+	"xeon gold 5218":     {cores: 16, dieSize: 4.85},
+	"xeon gold 6230":     {cores: 20, dieSize: 6.94},
+	"xeon gold 6248":     {cores: 20, dieSize: 6.94},
+	"xeon platinum 8280": {cores: 28, dieSize: 6.94},
+	// Ice Lake XCC
+	"xeon gold 6330":     {cores: 28, dieSize: 6.60},
+	"xeon platinum 8380": {cores: 40, dieSize: 6.60},
+	// Coffee Lake
+	"xeon e 2288g": {cores: 8, dieSize: 1.74},
+	// Rome, 74 mm² CCDs and a 416 mm² IO die
+	"epyc 7302": {cores: 16, dieSize: 7.12},
+	"epyc 7502": {cores: 32, dieSize: 7.12},
+	"epyc 7742": {cores: 64, dieSize: 10.08},
+	// Milan, 81 mm² CCDs
+	"epyc 7543": {cores: 32, dieSize: 7.40},
+	"epyc 7763": {cores: 64, dieSize: 10.64},
+	// Genoa, 72 mm² CCDs and a 397 mm² IO die
+	"epyc 9654": {cores: 96, dieSize: 12.61},
+}
+
+// The factors of the components in kgCO2eq, the die ones per cm²
+const (
+	cpuDieImpact  = 1.97
+	cpuBaseImpact = 9.14
+
+	ramDieImpact  = 2.20
+	ramBaseImpact = 5.22
+	// GB per cm² of die
+	ramDensity = 1.79
+
+	ssdDieImpact  = 2.20
+	ssdBaseImpact = 6.34
+	// GB per cm² of die
+	ssdDensity = 48.5
+
+	hddImpact = 31.1
+
+	motherboardImpact = 66.1
+	// 2 power supplies of 2.99 kg at 24.3 kgCO2eq/kg
+	powerSupplyImpact = 2 * 2.99 * 24.3
+	rackCaseImpact    = 150
+	assemblyImpact    = 6.68
+)
+
+// The defaults of the unknown components, the average die of the CPUs of
+// the table by core and the memory modules of the servers
+const (
+	defaultDieSizePerCore = 0.245
+	defaultCores          = 16
+	defaultDIMMSize       = 32
+	defaultSSDSize        = 1000
+)
+
+// Embodied returns the embodied emissions of the server in kgCO2eq, and
+// whether its CPU model is known
+func (i *Inventory) Embodied() (float64, bool) {
+	c, known := lookup(i.CPUModel)
+	if !known {
+		c = cpu{cores: defaultCores}
+		if i.Cores > 0 {
+			c.cores = i.Cores
+		}
+		c.dieSize = float64(c.cores) * defaultDieSizePerCore
+	}
+
+	sockets := max(i.Sockets, 1)
+	total := float64(sockets) * (c.dieSize*cpuDieImpact + cpuBaseImpact)
+
+	dimms, dimmSize := i.DIMMs, i.DIMMSize
+	if dimmSize <= 0 {
+		dimmSize = defaultDIMMSize
+	}
+	if dimms <= 0 {
+		dimms = int(math.Ceil(i.Memory / dimmSize))
+	}
+	total += float64(dimms) * (dimmSize/ramDensity*ramDieImpact + ramBaseImpact)
+
+	ssdSize := i.SSDSize
+	if ssdSize <= 0 {
+		ssdSize = defaultSSDSize
+	}
+	total += float64(i.SSDs) * (ssdSize/ssdDensity*ssdDieImpact + ssdBaseImpact)
+	total += float64(i.HDDs) * hddImpact
+
+	total += motherboardImpact + powerSupplyImpact + rackCaseImpact + assemblyImpact
+
+	return total, known
+}
+
+// CoresPerSocket returns the physical cores of every CPU of the server, the
+// ones of its model when they aren't known and zero when neither is
+func (i *Inventory) CoresPerSocket() int {
+	if i.Cores > 0 {
+		return i.Cores
+	}
+	if c, ok := lookup(i.CPUModel); ok {
+		return c.cores
+	}
+	return 0
+}
+
+// The separators of the words of a CPU model, the label values can't
+// contain spaces
+var separators = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// The core counts of the models, e.g. AMD EPYC 7763 64-Core Processor
+var coreCount = regexp.MustCompile(`\d+-core`)
+
+// The words of the CPU models which aren't part of the model
+var noise = map[string]bool{
+	"intel": true, "amd": true, "r": true, "tm": true, "cpu": true, "processor": true,
+}
+
+// lookup returns the die of a CPU model, e.g. "Intel(R) Xeon(R) Gold 6230
+// CPU @ 2.10GHz" or the label value "Intel-Xeon-Gold-6230". The words of
+// the models are separated by spaces in the table, e.g. "xeon e 2288g"
+func lookup(model string) (cpu, bool) {
+	model, _, _ = strings.Cut(strings.ToLower(model), "@")
+	model = coreCount.ReplaceAllString(model, "")
+
+	var words []string
+	for _, w := range separators.Split(model, -1) {
+		if w == "" || noise[w] || strings.HasSuffix(w, "ghz") {
+			continue
+		}
+		words = append(words, w)
+	}
+
+	c, ok := cpus[strings.Join(words, " ")]
+	return c, ok
+}
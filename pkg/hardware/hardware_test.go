@@ -0,0 +1,63 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		model string
+		cores int
+		known bool
+	}{
+		{model: "Intel(R) Xeon(R) Gold 6230 CPU @ 2.10GHz", cores: 20, known: true},
+		{model: "Intel-R-Xeon-R-Gold-6230-CPU-2.10GHz", cores: 20, known: true},
+		{model: "AMD EPYC 7763 64-Core Processor", cores: 64, known: true},
+		{model: "AMD-EPYC-7763", cores: 64, known: true},
+		{model: "Intel-Xeon-E-2288G", cores: 8, known: true},
+		{model: "Intel Xeon Gold 9999", known: false},
+		{model: "", known: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.model, func(t *testing.T) {
+			assert := require.New(t)
+
+			c, ok := lookup(test.model)
+			assert.Equal(test.known, ok)
+			assert.Equal(test.cores, c.cores)
+		})
+	}
+}
+
+func TestEmbodied(t *testing.T) {
+	assert := require.New(t)
+
+	// 2 sockets, 12 DIMMs of 32 GB and 2 SSDs of 960 GB
+	i := Inventory{
+		CPUModel: "Intel(R) Xeon(R) Gold 6230 CPU @ 2.10GHz",
+		Sockets:  2,
+		DIMMs:    12,
+		DIMMSize: 32,
+		SSDs:     2,
+		SSDSize:  960,
+	}
+	embodied, known := i.Embodied()
+	assert.True(known)
+	cpus := 2 * (6.94*1.97 + 9.14)
+	dimms := 12 * (32/1.79*2.20 + 5.22)
+	ssds := 2 * (960/48.5*2.20 + 6.34)
+	assert.InDelta(cpus+dimms+ssds+66.1+2*2.99*24.3+150+6.68, embodied, 1e-9)
+	assert.Equal(20, i.CoresPerSocket())
+
+	// the memory is counted in modules and the CPU die estimated from its
+	// cores when they're unknown
+	unknown := Inventory{CPUModel: "Ampere Altra", Cores: 80, Memory: 100, HDDs: 1}
+	embodied, known = unknown.Embodied()
+	assert.False(known)
+	assert.InDelta((80*0.245*1.97+9.14)+4*(32/1.79*2.20+5.22)+31.1+66.1+2*2.99*24.3+150+6.68, embodied, 1e-9)
+	assert.Equal(80, unknown.CoresPerSocket())
+	assert.Zero((&Inventory{}).CoresPerSocket())
+}
@@ -0,0 +1,25 @@
+// Package kube connects to the Kubernetes API
+package kube
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// NewClient returns a client of the cluster set in the kubeconfig
+// The in-cluster config is used when the kubeconfig is empty
+func NewClient(kubeconfig string) (dynamic.Interface, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading the kubernetes config: %w", err)
+	}
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating the kubernetes client: %w", err)
+	}
+
+	return client, nil
+}
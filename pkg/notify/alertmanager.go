@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The names of the alerts
+const (
+	AlertBudgetExceeded = "CarbonBudgetExceeded"
+	AlertAnomaly        = "CarbonEmissionsAnomaly"
+	AlertScrapeStale    = "CarbonScrapeStale"
+)
+
+// How many intervals the alerts firing last unless posted again, like the
+// rules of Prometheus
+const resendFactor = 4
+
+// statusReader returns the status of the scraping of the accounts
+type statusReader interface {
+	Status() []v1.ScrapeStatus
+}
+
+// alert is an alert of the API v2 of Alertmanager
+type alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// alertmanager posts the alerts firing to the Alertmanagers every check,
+// and the ones which stopped firing once as resolved
+type alertmanager struct {
+	urls         []string
+	labels       map[string]string
+	generatorURL string
+
+	// The summaries of the alerts, the default messages
+	summaries map[string]*template.Template
+
+	// The alerts firing by their labels
+	firing map[string]*alert
+
+	client *http.Client
+}
+
+// newAlertmanager returns the Alertmanagers of the config
+func newAlertmanager(cfg *config.AlertmanagerConfig) (*alertmanager, error) {
+	a := &alertmanager{
+		labels:    cfg.Labels,
+		summaries: make(map[string]*template.Template, len(defaultTemplates)),
+		firing:    make(map[string]*alert),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, u := range cfg.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid alertmanager URL %q", u)
+		}
+		a.urls = append(a.urls, strings.TrimSuffix(u, "/")+"/api/v2/alerts")
+	}
+
+	if cfg.ExternalURL != "" {
+		a.generatorURL = strings.TrimSuffix(cfg.ExternalURL, "/") + "/ui/"
+	}
+
+	for event, text := range defaultTemplates {
+		a.summaries[event] = template.Must(template.New(event).Funcs(funcs).Parse(text))
+	}
+
+	return a, nil
+}
+
+// newAlert returns the alert of the labels, on top of the ones of the config
+func (a *alertmanager) newAlert(name string, labels map[string]string, summary string) *alert {
+	l := make(map[string]string, len(a.labels)+len(labels)+1)
+	for k, v := range a.labels {
+		l[k] = v
+	}
+	for k, v := range labels {
+		l[k] = v
+	}
+	l["alertname"] = name
+
+	return &alert{
+		Labels:       l,
+		Annotations:  map[string]string{"summary": summary},
+		GeneratorURL: a.generatorURL,
+	}
+}
+
+// summarize returns the default message of the notification
+func (a *alertmanager) summarize(event string, data any) string {
+	var summary strings.Builder
+	if err := a.summaries[event].Execute(&summary, data); err != nil {
+		return ""
+	}
+	return summary.String()
+}
+
+// sync posts the alerts firing, they end unless posted again within a few
+// intervals, and the ones no longer firing as resolved
+func (a *alertmanager) sync(ctx context.Context, active []*alert, now time.Time, interval time.Duration) error {
+	posted := make([]*alert, 0, len(active)+len(a.firing))
+
+	keys := make(map[string]bool, len(active))
+	for _, al := range active {
+		key := labelsKey(al.Labels)
+		keys[key] = true
+
+		// the alerts keep their start, the ones with an end stop firing by
+		// themselves
+		if prev, ok := a.firing[key]; ok {
+			al.StartsAt = prev.StartsAt
+		} else if al.StartsAt.IsZero() {
+			al.StartsAt = now
+		}
+		if al.EndsAt.IsZero() {
+			al.EndsAt = now.Add(resendFactor * interval)
+		}
+
+		a.firing[key] = al
+		posted = append(posted, al)
+	}
+
+	for key, al := range a.firing {
+		if keys[key] {
+			continue
+		}
+		delete(a.firing, key)
+
+		if al.EndsAt.After(now) {
+			al.EndsAt = now
+			posted = append(posted, al)
+		}
+	}
+
+	if len(posted) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(posted)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, u := range a.urls {
+		if err := a.post(ctx, u, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// post posts the alerts to an Alertmanager
+func (a *alertmanager) post(ctx context.Context, u string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL.Redacted(), resp.Status)
+	}
+
+	return nil
+}
+
+// labelsKey identifies the alerts of the labels
+func labelsKey(labels map[string]string) string {
+	return formatLabels(labels)
+}
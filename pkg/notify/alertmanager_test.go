@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the alerts posted to it, like an Alertmanager
+type receiver struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	posts [][]alert
+}
+
+func newReceiver(t *testing.T) *receiver {
+	t.Helper()
+
+	r := &receiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.URL.Path != "/api/v2/alerts" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var alerts []alert
+		if err := json.NewDecoder(req.Body).Decode(&alerts); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		r.mu.Lock()
+		r.posts = append(r.posts, alerts)
+		r.mu.Unlock()
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+// last returns the alerts of the last post by their name
+func (r *receiver) last() map[string]alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string]alert)
+	if len(r.posts) == 0 {
+		return out
+	}
+	for _, a := range r.posts[len(r.posts)-1] {
+		out[a.Labels["alertname"]] = a
+	}
+	return out
+}
+
+// statuses is a static status of the scraping
+type statuses []v1.ScrapeStatus
+
+func (s statuses) Status() []v1.ScrapeStatus {
+	return s
+}
+
+func TestAlertmanager(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	now := time.Now().Truncate(time.Second)
+	assert.NoError(s.Add(ctx, store.Sample{Time: now, Provider: v1.AWS, Name: "web", Operational: 1500}))
+
+	am := newReceiver(t)
+	n, err := New(ctx, &config.NotificationsConfig{
+		Interval: time.Minute,
+		Budgets:  []config.NotificationBudget{{Name: "all", Query: "sum(emissions) where range=1h", Limit: 1000}},
+		Alertmanager: config.AlertmanagerConfig{
+			URLs:        []string{am.URL + "/"},
+			Labels:      map[string]string{"severity": "warning"},
+			Staleness:   time.Hour,
+			ExternalURL: "http://aether:8080",
+		},
+	}, s, statuses{
+		{Provider: v1.AWS, Account: "prod", LastSuccess: now.Add(-2 * time.Hour), LastError: "access denied"},
+		{Provider: v1.GCP, Account: "dev", LastSuccess: now.Add(-time.Minute)},
+	})
+	assert.NoError(err)
+
+	n.check(ctx, now)
+	alerts := am.last()
+	assert.Len(alerts, 2)
+
+	budget := alerts[AlertBudgetExceeded]
+	assert.Equal(map[string]string{"alertname": AlertBudgetExceeded, "budget": "all", "severity": "warning"}, budget.Labels)
+	assert.Equal("Budget all exceeded: 1.50 kgCO2eq over a limit of 1.00 kgCO2eq\nsum(emissions) where range=1h", budget.Annotations["summary"])
+	assert.Equal("http://aether:8080/ui/", budget.GeneratorURL)
+	assert.True(budget.StartsAt.Equal(now))
+	assert.True(budget.EndsAt.Equal(now.Add(4 * time.Minute)))
+
+	stale := alerts[AlertScrapeStale]
+	assert.Equal(map[string]string{"alertname": AlertScrapeStale, "provider": "aws", "account": "prod", "severity": "warning"}, stale.Labels)
+	assert.Contains(stale.Annotations["summary"], "access denied")
+
+	// the alerts firing keep their start
+	later := now.Add(time.Minute)
+	n.check(ctx, later)
+	alerts = am.last()
+	assert.True(alerts[AlertBudgetExceeded].StartsAt.Equal(now))
+	assert.True(alerts[AlertBudgetExceeded].EndsAt.Equal(later.Add(4 * time.Minute)))
+
+	// the budget met again is resolved once
+	n.budgets[0].Limit = 2000
+	later = later.Add(time.Minute)
+	n.check(ctx, later)
+	alerts = am.last()
+	assert.True(alerts[AlertBudgetExceeded].EndsAt.Equal(later))
+
+	n.check(ctx, later.Add(time.Minute))
+	assert.NotContains(am.last(), AlertBudgetExceeded)
+
+	_, err = New(ctx, &config.NotificationsConfig{Alertmanager: config.AlertmanagerConfig{URLs: []string{"alertmanager:9093"}}}, s, nil)
+	assert.Error(err)
+}
+
+func TestAlertmanagerSync(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	am := newReceiver(t)
+	a, err := newAlertmanager(&config.AlertmanagerConfig{URLs: []string{am.URL}})
+	assert.NoError(err)
+
+	now := time.Date(2024, 1, 2, 0, 10, 0, 0, time.UTC)
+	hour := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+
+	// the alerts with an end stop firing by themselves
+	spike := a.newAlert(AlertAnomaly, map[string]string{"group": "team=web"}, "spike")
+	spike.StartsAt = hour.Add(time.Hour)
+	spike.EndsAt = hour.Add(2 * time.Hour)
+	assert.NoError(a.sync(ctx, []*alert{spike}, now, time.Minute))
+	assert.True(am.last()[AlertAnomaly].EndsAt.Equal(hour.Add(2 * time.Hour)))
+
+	am.posts = nil
+	assert.NoError(a.sync(ctx, nil, hour.Add(2*time.Hour), time.Minute))
+	assert.Empty(am.posts)
+	assert.Empty(a.firing)
+
+	// the failures of the Alertmanagers are returned
+	a.urls = append(a.urls, am.URL+"/missing")
+	assert.Error(a.sync(ctx, []*alert{a.newAlert(AlertScrapeStale, nil, "stale")}, now, time.Minute))
+}
@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+)
+
+// The kinds of webhooks
+const (
+	TypeSlack = "slack"
+	TypeTeams = "teams"
+)
+
+// The notifications
+const (
+	EventBudget  = "budget"
+	EventAnomaly = "anomaly"
+	EventSummary = "summary"
+)
+
+// The default templates of the messages, plain text which both Slack and
+// Teams render
+var defaultTemplates = map[string]string{
+	EventBudget: `Budget {{.Name}} exceeded: {{mass .Value}}{{with .Group}} for {{.}}{{end}} over a limit of {{mass .Limit}}
+{{.Query}}`,
+	EventAnomaly: `Emissions anomaly: {{.Group}} emitted {{mass .Value}} in the hour of {{time .Hour}}, {{printf "%.1f" .Ratio}}x the hourly mean of {{mass .Mean}}`,
+	EventSummary: `Emissions of the week of {{date .From}}: {{mass .Total.Emissions}}{{with .Change}} ({{.}} since the week before){{end}}
+{{- range .Top}}
+- {{.Group}}: {{mass .Emissions}}
+{{- end}}`,
+}
+
+// The functions of the templates
+var funcs = template.FuncMap{
+	"mass": mass,
+	"date": func(t time.Time) string { return t.Format(time.DateOnly) },
+	"time": func(t time.Time) string { return t.Format("2006-01-02 15:04 MST") },
+}
+
+// channel posts the notifications to a webhook
+type channel struct {
+	name    string
+	kind    string
+	webhook string
+
+	// The templates of the notifications posted
+	templates map[string]*template.Template
+
+	client *http.Client
+}
+
+// newChannel returns the channel of the config, the templates are parsed
+func newChannel(cfg *config.NotificationChannel) (*channel, error) {
+	switch cfg.Type {
+	case TypeSlack, TypeTeams:
+	default:
+		return nil, fmt.Errorf("channel %s: invalid type %q, expected slack or teams", cfg.Name, cfg.Type)
+	}
+	if cfg.Webhook == "" {
+		return nil, fmt.Errorf("channel %s: missing webhook", cfg.Name)
+	}
+
+	events := cfg.Events
+	if len(events) == 0 {
+		events = []string{EventBudget, EventAnomaly, EventSummary}
+	}
+
+	for event := range cfg.Templates {
+		if _, ok := defaultTemplates[event]; !ok {
+			return nil, fmt.Errorf("channel %s: template of the unknown notification %q", cfg.Name, event)
+		}
+	}
+
+	c := &channel{
+		name:      cfg.Name,
+		kind:      cfg.Type,
+		webhook:   cfg.Webhook,
+		templates: make(map[string]*template.Template, len(events)),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, event := range events {
+		text, ok := defaultTemplates[event]
+		if !ok {
+			return nil, fmt.Errorf("channel %s: unknown notification %q, expected budget, anomaly or summary", cfg.Name, event)
+		}
+		if t, ok := cfg.Templates[event]; ok {
+			text = t
+		}
+
+		t, err := template.New(event).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", cfg.Name, err)
+		}
+		c.templates[event] = t
+	}
+
+	return c, nil
+}
+
+// send posts the message of the notification, unless the channel doesn't
+// post it
+func (c *channel) send(ctx context.Context, event string, data any) error {
+	t, ok := c.templates[event]
+	if !ok {
+		return nil
+	}
+
+	var text strings.Builder
+	if err := t.Execute(&text, data); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(c.payload(text.String()))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// payload returns the body of the message: the text of Slack, or an
+// Adaptive Card accepted by both the Teams workflows and connectors
+func (c *channel) payload(text string) any {
+	if c.kind == TypeSlack {
+		return map[string]string{"text": text}
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body": []map[string]any{{
+					"type": "TextBlock",
+					"text": text,
+					"wrap": true,
+				}},
+			},
+		}},
+	}
+}
+
+// mass formats emissions in gCO2eq in g, kg or t
+func mass(g float64) string {
+	switch {
+	case math.Abs(g) >= 1e6:
+		return strconv.FormatFloat(g/1e6, 'f', 2, 64) + " tCO2eq"
+	case math.Abs(g) >= 1e3:
+		return strconv.FormatFloat(g/1e3, 'f', 2, 64) + " kgCO2eq"
+	default:
+		return strconv.FormatFloat(g, 'f', 2, 64) + " gCO2eq"
+	}
+}
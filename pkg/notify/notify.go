@@ -0,0 +1,473 @@
+// Package notify posts the budget breaches, the spikes of the emissions and
+// the weekly summaries to the Slack and Microsoft Teams incoming webhooks,
+// with messages templated in the config, and the breaches, the spikes and
+// the stale accounts as alerts to Prometheus Alertmanager
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/report"
+	"github.com/re-cinq/aether/pkg/store"
+)
+
+// The hours an anomaly is detected with at least, besides the last one
+const minBaseline = 24
+
+// How much above the mean an hour must be at least to be an anomaly, the
+// flat emissions have no deviation
+const minIncrease = 0.2
+
+// The weekdays of the summary
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Budget is the data of the template of a budget breach
+type Budget struct {
+	Name  string
+	Query string
+
+	// The group of the highest value, e.g. team=platform, empty when the
+	// query has no groups
+	Group string
+
+	// The values in gCO2eq
+	Value float64
+	Limit float64
+}
+
+// Anomaly is the data of the template of a spike of the emissions
+type Anomaly struct {
+	// The group of the emissions, e.g. team=platform
+	Group string
+
+	// The start of the hour
+	Hour time.Time
+
+	// The emissions of the hour and the hourly mean before, in gCO2eq
+	Value float64
+	Mean  float64
+
+	// The emissions of the hour over the mean
+	Ratio float64
+}
+
+// Summary is the data of the template of the weekly summary
+type Summary struct {
+	*report.Executive
+
+	// The change of the emissions since the week before, e.g. +12.5%,
+	// empty without emissions before
+	Change string
+}
+
+// budget is a limit of the emissions matched by a query
+type budget struct {
+	config.NotificationBudget
+	query *store.Query
+}
+
+// Notifier checks the budgets and the anomalies and posts the summaries
+// periodically
+type Notifier struct {
+	store    *store.Store
+	status   statusReader
+	channels []*channel
+	budgets  []budget
+
+	// The Alertmanagers the alerts are posted to, nil without any
+	alertmanager *alertmanager
+	staleness    time.Duration
+
+	anomalies config.AnomaliesConfig
+	summary   config.SummaryConfig
+	weekday   time.Weekday
+
+	// How often the budgets and the anomalies are checked
+	interval time.Duration
+
+	// The budgets exceeded, notified until they're met again
+	exceeded map[string]*Budget
+
+	// The last anomaly notified of the groups
+	spikes map[string]*Anomaly
+
+	// When the notifier started, the accounts never scraped are stale past
+	// the staleness since
+	started time.Time
+
+	// When the notifications were last checked, the summaries scheduled
+	// since are posted
+	last time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	logger *slog.Logger
+}
+
+// New returns the notifier of the config, the status of the scraping is
+// used to alert on the stale accounts and can be nil
+func New(ctx context.Context, cfg *config.NotificationsConfig, s *store.Store, status statusReader) (*Notifier, error) {
+	n := &Notifier{
+		store:     s,
+		status:    status,
+		anomalies: cfg.Anomalies,
+		summary:   cfg.Summary,
+		interval:  cfg.Interval,
+		staleness: cfg.Alertmanager.Staleness,
+		exceeded:  make(map[string]*Budget),
+		spikes:    make(map[string]*Anomaly),
+		logger:    log.FromContext(ctx),
+	}
+
+	if n.interval <= 0 {
+		n.interval = 5 * time.Minute
+	}
+	if n.staleness <= 0 {
+		n.staleness = time.Hour
+	}
+
+	if len(cfg.Alertmanager.URLs) > 0 {
+		a, err := newAlertmanager(&cfg.Alertmanager)
+		if err != nil {
+			return nil, err
+		}
+		n.alertmanager = a
+	}
+
+	for i := range cfg.Channels {
+		c, err := newChannel(&cfg.Channels[i])
+		if err != nil {
+			return nil, err
+		}
+		n.channels = append(n.channels, c)
+	}
+
+	for i, b := range cfg.Budgets {
+		if b.Name == "" {
+			return nil, fmt.Errorf("budget %d has no name", i)
+		}
+
+		q, err := store.ParseQuery(b.Query)
+		if err != nil {
+			return nil, fmt.Errorf("budget %s: %w", b.Name, err)
+		}
+		n.budgets = append(n.budgets, budget{NotificationBudget: b, query: q})
+	}
+
+	if n.anomalies.Enabled {
+		if n.anomalies.GroupBy == "" {
+			n.anomalies.GroupBy = "provider"
+		}
+		if n.anomalies.Window <= 0 {
+			n.anomalies.Window = 7 * 24 * time.Hour
+		}
+		if n.anomalies.Threshold <= 0 {
+			n.anomalies.Threshold = 3
+		}
+	}
+
+	if n.summary.Enabled {
+		day, ok := weekdays[strings.ToLower(n.summary.Weekday)]
+		if !ok && n.summary.Weekday != "" {
+			return nil, fmt.Errorf("invalid summary weekday %q", n.summary.Weekday)
+		}
+		if !ok {
+			day = time.Monday
+		}
+		n.weekday = day
+
+		if n.summary.Hour < 0 || n.summary.Hour > 23 {
+			return nil, fmt.Errorf("invalid summary hour %d, expected 0 to 23", n.summary.Hour)
+		}
+		if n.summary.GroupBy == "" {
+			n.summary.GroupBy = "provider"
+		}
+	}
+
+	return n, nil
+}
+
+// Start checks the notifications now and then every interval, until the
+// context is done or the notifier is stopped
+func (n *Notifier) Start(ctx context.Context) {
+	ctx, n.cancel = context.WithCancel(ctx)
+	n.done = make(chan struct{})
+
+	go func() {
+		defer close(n.done)
+
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+
+		for {
+			n.check(ctx, time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops checking the notifications, it can be called more than once
+func (n *Notifier) Stop(ctx context.Context) {
+	if n.cancel == nil {
+		return
+	}
+
+	n.cancel()
+
+	select {
+	case <-n.done:
+	case <-ctx.Done():
+	}
+}
+
+// check posts the budgets newly exceeded, the anomalies of the last hour
+// ended and the summary when it is due, and the alerts firing
+func (n *Notifier) check(ctx context.Context, now time.Time) {
+	if n.started.IsZero() {
+		n.started = now
+	}
+
+	n.checkBudgets(ctx)
+
+	if n.anomalies.Enabled {
+		n.checkAnomalies(ctx, now)
+	}
+
+	// the summaries scheduled while the exporter was down aren't posted
+	last := n.last
+	if last.IsZero() {
+		last = now.Add(-n.interval)
+	}
+	n.last = now
+
+	if n.summary.Enabled {
+		if at := n.summaryTime(now); at.After(last) && !at.After(now) {
+			n.postSummary(ctx, at)
+		}
+	}
+
+	if n.alertmanager != nil {
+		if err := n.alertmanager.sync(ctx, n.alerts(now), now, n.interval); err != nil {
+			n.logger.Error("failed posting the alerts", "error", err)
+		}
+	}
+}
+
+// checkBudgets posts the budgets exceeded since the last check
+func (n *Notifier) checkBudgets(ctx context.Context) {
+	for i := range n.budgets {
+		b := &n.budgets[i]
+
+		// the highest group is compared with the limit
+		var top store.Result
+		for j, r := range n.store.Query(b.query) {
+			if j == 0 || r.Value > top.Value {
+				top = r
+			}
+		}
+
+		if top.Value <= b.Limit {
+			delete(n.exceeded, b.Name)
+			continue
+		}
+
+		breach := &Budget{
+			Name:  b.Name,
+			Query: b.Query,
+			Group: formatLabels(top.Labels),
+			Value: top.Value,
+			Limit: b.Limit,
+		}
+
+		// the alerts follow the value, the channels are notified once
+		_, notified := n.exceeded[b.Name]
+		n.exceeded[b.Name] = breach
+		if notified {
+			continue
+		}
+
+		n.post(ctx, EventBudget, breach)
+	}
+}
+
+// checkAnomalies posts the groups whose emissions of the last hour ended
+// are over the mean of the window by the threshold of standard deviations
+func (n *Notifier) checkAnomalies(ctx context.Context, now time.Time) {
+	to := now.Truncate(time.Hour)
+	from := to.Add(-n.anomalies.Window)
+	hour := to.Add(-time.Hour)
+
+	q := &store.Query{Function: "sum", Field: "emissions", By: []string{n.anomalies.GroupBy}}
+	m, err := n.store.QueryRange(q, from, to, time.Hour)
+	if err != nil {
+		n.logger.Error("failed querying the hourly emissions", "error", err)
+		return
+	}
+
+	for _, series := range m.Series {
+		last := len(series.Points) - 1
+		if last < minBaseline || !series.Points[last].Time.Equal(hour) {
+			continue
+		}
+
+		mean, stddev := meanStddev(series.Points[:last])
+		value := series.Points[last].Value
+		if value <= mean+n.anomalies.Threshold*stddev || value <= mean*(1+minIncrease) {
+			continue
+		}
+
+		group := formatLabels(series.Labels)
+		if a, ok := n.spikes[group]; ok && a.Hour.Equal(hour) {
+			continue
+		}
+
+		a := &Anomaly{
+			Group: group,
+			Hour:  hour.In(n.store.Location()),
+			Value: value,
+			Mean:  mean,
+			Ratio: value / mean,
+		}
+		n.spikes[group] = a
+
+		n.post(ctx, EventAnomaly, a)
+	}
+}
+
+// alerts returns the alerts firing: the budgets exceeded, the anomalies
+// until the end of the hour after theirs and the stale accounts
+func (n *Notifier) alerts(now time.Time) []*alert {
+	var alerts []*alert
+
+	for _, b := range n.exceeded {
+		alerts = append(alerts, n.alertmanager.newAlert(AlertBudgetExceeded,
+			map[string]string{"budget": b.Name},
+			n.alertmanager.summarize(EventBudget, b)))
+	}
+
+	for group, a := range n.spikes {
+		end := a.Hour.Add(2 * time.Hour)
+		if !end.After(now) {
+			continue
+		}
+
+		al := n.alertmanager.newAlert(AlertAnomaly,
+			map[string]string{"group": group},
+			n.alertmanager.summarize(EventAnomaly, a))
+		al.StartsAt = a.Hour.Add(time.Hour)
+		al.EndsAt = end
+		alerts = append(alerts, al)
+	}
+
+	if n.status == nil {
+		return alerts
+	}
+
+	for _, s := range n.status.Status() {
+		last := s.LastSuccess
+		if last.IsZero() {
+			last = n.started
+		}
+		if now.Sub(last) <= n.staleness {
+			continue
+		}
+
+		summary := fmt.Sprintf("No successful scrape of the %s account %s since %s", s.Provider, s.Account, last.Format(time.RFC3339))
+		if s.LastError != "" {
+			summary += ": " + s.LastError
+		}
+
+		alerts = append(alerts, n.alertmanager.newAlert(AlertScrapeStale,
+			map[string]string{"provider": string(s.Provider), "account": s.Account},
+			summary))
+	}
+
+	return alerts
+}
+
+// summaryTime returns when the summary of the week of the time is posted
+func (n *Notifier) summaryTime(now time.Time) time.Time {
+	week := store.Week.Start(now, n.store.Location())
+	days := (int(n.weekday) + 6) % 7
+	return week.AddDate(0, 0, days).Add(time.Duration(n.summary.Hour) * time.Hour)
+}
+
+// postSummary posts the summary of the week before the one of the time
+func (n *Notifier) postSummary(ctx context.Context, at time.Time) {
+	to := store.Week.Start(at, n.store.Location())
+	from := to.AddDate(0, 0, -7)
+
+	e, err := report.NewExecutive(n.store, &report.ExecutiveRequest{
+		From:    from,
+		To:      to,
+		GroupBy: n.summary.GroupBy,
+		Top:     n.summary.Top,
+	}, at)
+	if err != nil {
+		n.logger.Error("failed summarizing the week", "error", err)
+		return
+	}
+
+	summary := &Summary{Executive: e}
+	if e.Change != nil {
+		summary.Change = fmt.Sprintf("%+.1f%%", *e.Change)
+	}
+
+	n.post(ctx, EventSummary, summary)
+}
+
+// post posts the notification to every channel
+func (n *Notifier) post(ctx context.Context, event string, data any) {
+	for _, c := range n.channels {
+		if err := c.send(ctx, event, data); err != nil {
+			n.logger.Error("failed posting the notification", "channel", c.name, "notification", event, "error", err)
+		}
+	}
+}
+
+// meanStddev returns the mean and the population standard deviation of the
+// values of the points
+func meanStddev(points []store.Point) (mean, stddev float64) {
+	for _, p := range points {
+		mean += p.Value
+	}
+	mean /= float64(len(points))
+
+	for _, p := range points {
+		stddev += (p.Value - mean) * (p.Value - mean)
+	}
+
+	return mean, math.Sqrt(stddev / float64(len(points)))
+}
+
+// formatLabels returns the labels as key=value, sorted by key
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,251 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// webhook records the messages posted to it
+type webhook struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []map[string]any
+}
+
+func newWebhook(t *testing.T) *webhook {
+	t.Helper()
+
+	w := &webhook{}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		var msg map[string]any
+		if err := json.Unmarshal(body, &msg); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.mu.Lock()
+		w.messages = append(w.messages, msg)
+		w.mu.Unlock()
+	}))
+	t.Cleanup(w.Close)
+
+	return w
+}
+
+// texts returns the texts of the Slack messages posted
+func (w *webhook) texts() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var out []string
+	for _, msg := range w.messages {
+		out = append(out, msg["text"].(string))
+	}
+	return out
+}
+
+func TestNewChannel(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := newChannel(&config.NotificationChannel{Name: "ops", Type: TypeSlack, Webhook: "http://localhost"})
+	assert.NoError(err)
+	assert.Len(c.templates, 3)
+
+	c, err = newChannel(&config.NotificationChannel{
+		Name:      "ops",
+		Type:      TypeTeams,
+		Webhook:   "http://localhost",
+		Events:    []string{EventBudget},
+		Templates: map[string]string{EventBudget: "{{.Name}} is over"},
+	})
+	assert.NoError(err)
+	assert.Len(c.templates, 1)
+
+	for _, cfg := range []config.NotificationChannel{
+		{Name: "ops", Type: "discord", Webhook: "http://localhost"},
+		{Name: "ops", Type: TypeSlack},
+		{Name: "ops", Type: TypeSlack, Webhook: "http://localhost", Events: []string{"outage"}},
+		{Name: "ops", Type: TypeSlack, Webhook: "http://localhost", Templates: map[string]string{"outage": "down"}},
+		{Name: "ops", Type: TypeSlack, Webhook: "http://localhost", Templates: map[string]string{EventBudget: "{{.Name"}},
+	} {
+		_, err := newChannel(&cfg)
+		assert.Error(err, cfg)
+	}
+}
+
+func TestChannelSend(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	slack := newWebhook(t)
+	c, err := newChannel(&config.NotificationChannel{Name: "slack", Type: TypeSlack, Webhook: slack.URL, Events: []string{EventBudget}})
+	assert.NoError(err)
+
+	budget := &Budget{Name: "platform", Query: "sum(emissions) by (team)", Group: "team=platform", Value: 1_500_000, Limit: 1_000_000}
+	assert.NoError(c.send(ctx, EventBudget, budget))
+	// not posted to the channel
+	assert.NoError(c.send(ctx, EventAnomaly, &Anomaly{}))
+	assert.Equal([]string{"Budget platform exceeded: 1.50 tCO2eq for team=platform over a limit of 1.00 tCO2eq\nsum(emissions) by (team)"}, slack.texts())
+
+	teams := newWebhook(t)
+	c, err = newChannel(&config.NotificationChannel{
+		Name:      "teams",
+		Type:      TypeTeams,
+		Webhook:   teams.URL,
+		Templates: map[string]string{EventBudget: "**{{.Name}}** is at {{mass .Value}}"},
+	})
+	assert.NoError(err)
+	assert.NoError(c.send(ctx, EventBudget, budget))
+
+	assert.Len(teams.messages, 1)
+	card := teams.messages[0]["attachments"].([]any)[0].(map[string]any)
+	assert.Equal("application/vnd.microsoft.card.adaptive", card["contentType"])
+	block := card["content"].(map[string]any)["body"].([]any)[0].(map[string]any)
+	assert.Equal("**platform** is at 1.50 tCO2eq", block["text"])
+
+	// a missing field fails the template
+	c, err = newChannel(&config.NotificationChannel{Name: "teams", Type: TypeTeams, Webhook: teams.URL, Templates: map[string]string{EventBudget: "{{.Owner}}"}})
+	assert.NoError(err)
+	assert.Error(c.send(ctx, EventBudget, budget))
+
+	// the webhook fails
+	c, err = newChannel(&config.NotificationChannel{Name: "teams", Type: TypeTeams, Webhook: teams.URL + "/missing"})
+	assert.NoError(err)
+	teams.Config.Handler = http.NotFoundHandler()
+	assert.Error(c.send(ctx, EventBudget, budget))
+}
+
+func TestBudgets(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	now := time.Now()
+	add := func(team string, g float64) {
+		assert.NoError(s.Add(ctx, store.Sample{Time: now, Provider: v1.AWS, Name: team, Labels: v1.Labels{"team": team}, Operational: g}))
+	}
+	add("web", 400)
+	add("data", 900)
+
+	hook := newWebhook(t)
+	n, err := New(ctx, &config.NotificationsConfig{
+		Channels: []config.NotificationChannel{{Name: "ops", Type: TypeSlack, Webhook: hook.URL}},
+		Budgets:  []config.NotificationBudget{{Name: "teams", Query: "sum(emissions) by (team) where range=1h", Limit: 1000}},
+	}, s, nil)
+	assert.NoError(err)
+
+	n.check(ctx, now)
+	assert.Empty(hook.texts())
+
+	// the breach is posted once
+	add("data", 200)
+	n.check(ctx, now)
+	n.check(ctx, now)
+	assert.Equal([]string{"Budget teams exceeded: 1.10 kgCO2eq for team=data over a limit of 1.00 kgCO2eq\nsum(emissions) by (team) where range=1h"}, hook.texts())
+
+	_, err = New(ctx, &config.NotificationsConfig{Budgets: []config.NotificationBudget{{Query: "sum(emissions)"}}}, s, nil)
+	assert.Error(err)
+	_, err = New(ctx, &config.NotificationsConfig{Budgets: []config.NotificationBudget{{Name: "all", Query: "sum(co2)"}}}, s, nil)
+	assert.Error(err)
+}
+
+func TestAnomalies(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for h := 0; h < 48; h++ {
+		web := 100.0 + float64(h%2)*10
+		if h == 47 {
+			web = 400
+		}
+		for _, sample := range []store.Sample{
+			{Time: start.Add(time.Duration(h)*time.Hour + time.Minute), Provider: v1.AWS, Name: "web", Labels: v1.Labels{"team": "web"}, Operational: web},
+			// flat emissions increasing by less than the minimum
+			{Time: start.Add(time.Duration(h)*time.Hour + time.Minute), Provider: v1.AWS, Name: "data", Labels: v1.Labels{"team": "data"}, Operational: 100 + float64(h/47)*15},
+		} {
+			assert.NoError(s.Add(ctx, sample))
+		}
+	}
+	// a group without enough hours
+	assert.NoError(s.Add(ctx, store.Sample{Time: start.Add(47 * time.Hour), Provider: v1.AWS, Name: "ml", Labels: v1.Labels{"team": "ml"}, Operational: 1000}))
+
+	hook := newWebhook(t)
+	n, err := New(ctx, &config.NotificationsConfig{
+		Channels:  []config.NotificationChannel{{Name: "ops", Type: TypeSlack, Webhook: hook.URL}},
+		Anomalies: config.AnomaliesConfig{Enabled: true, GroupBy: "team", Window: 48 * time.Hour},
+	}, s, nil)
+	assert.NoError(err)
+	assert.InDelta(3, n.anomalies.Threshold, 1e-9)
+
+	now := start.Add(48*time.Hour + 10*time.Minute)
+	n.check(ctx, now)
+	n.check(ctx, now.Add(5*time.Minute))
+	assert.Equal([]string{"Emissions anomaly: team=web emitted 400.00 gCO2eq in the hour of 2024-01-02 23:00 UTC, 3.8x the hourly mean of 104.89 gCO2eq"}, hook.texts())
+}
+
+func TestSummary(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	// Monday January 1st and 8th
+	week := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, sample := range []store.Sample{
+		{Time: week.Add(-24 * time.Hour), Provider: v1.AWS, Name: "a", Operational: 1000},
+		{Time: week.Add(time.Hour), Provider: v1.AWS, Name: "a", Operational: 1500},
+		{Time: week.Add(49 * time.Hour), Provider: v1.GCP, Name: "b", Operational: 500},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	hook := newWebhook(t)
+	n, err := New(ctx, &config.NotificationsConfig{
+		Interval: time.Hour,
+		Channels: []config.NotificationChannel{{Name: "ops", Type: TypeSlack, Webhook: hook.URL}},
+		Summary:  config.SummaryConfig{Enabled: true, Weekday: "Monday", Hour: 9},
+	}, s, nil)
+	assert.NoError(err)
+
+	next := week.AddDate(0, 0, 7)
+
+	// the first check doesn't post the summaries due before the interval
+	n.check(ctx, next.Add(11*time.Hour))
+	assert.Empty(hook.texts())
+
+	n.last = time.Time{}
+	n.check(ctx, next.Add(8*time.Hour+30*time.Minute))
+	assert.Empty(hook.texts())
+	n.check(ctx, next.Add(9*time.Hour+30*time.Minute))
+	n.check(ctx, next.Add(10*time.Hour+30*time.Minute))
+	assert.Equal([]string{"Emissions of the week of 2024-01-01: 2.00 kgCO2eq (+100.0% since the week before)\n- aws: 1.50 kgCO2eq\n- gcp: 500.00 gCO2eq"}, hook.texts())
+
+	for _, cfg := range []config.SummaryConfig{
+		{Enabled: true, Weekday: "someday"},
+		{Enabled: true, Hour: 24},
+	} {
+		_, err := New(ctx, &config.NotificationsConfig{Summary: cfg}, s, nil)
+		assert.Error(err, cfg)
+	}
+}
@@ -0,0 +1,335 @@
+// Package operator reconciles the CarbonPolicy custom resources, so that
+// the scraped accounts and the emission budgets can be managed with GitOps
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/kube"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// accountManager schedules the accounts of the policies
+type accountManager interface {
+	SetExternalAccounts(map[v1.Provider][]config.Account)
+	Status() []v1.ScrapeStatus
+}
+
+// emissionsQuerier evaluates the budgets of the policies
+type emissionsQuerier interface {
+	Query(*store.Query) []store.Result
+}
+
+// Operator keeps the scraped accounts in sync with the CarbonPolicy
+// resources and reports their status
+type Operator struct {
+	client dynamic.Interface
+
+	// The namespace the policies are read from, all of them when empty
+	namespace string
+
+	// How often the policies are reconciled
+	interval time.Duration
+
+	accounts  accountManager
+	emissions emissionsQuerier
+
+	now    func() time.Time
+	logger *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns an operator connected to the cluster set in the config
+func New(ctx context.Context, cfg *config.OperatorConfig, accounts accountManager, emissions emissionsQuerier) (*Operator, error) {
+	client, err := kube.NewClient(cfg.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOperator(ctx, client, cfg, accounts, emissions), nil
+}
+
+// newOperator returns an operator using the client
+func newOperator(ctx context.Context, client dynamic.Interface, cfg *config.OperatorConfig, accounts accountManager, emissions emissionsQuerier) *Operator {
+	interval := cfg.ResyncInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &Operator{
+		client:    client,
+		namespace: cfg.Namespace,
+		interval:  interval,
+		accounts:  accounts,
+		emissions: emissions,
+		now:       time.Now,
+		logger:    log.FromContext(ctx),
+	}
+}
+
+// Start reconciles the policies every resync interval
+// NOTE: this is not a blocking call
+func (o *Operator) Start(ctx context.Context) {
+	ctx, o.cancel = context.WithCancel(ctx)
+	o.done = make(chan struct{})
+
+	go func() {
+		defer close(o.done)
+
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+
+		for {
+			// a slow reconciliation is cancelled instead of delaying the
+			// next ones
+			tick, cancel := context.WithTimeout(ctx, o.interval)
+			o.reconcile(tick)
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the running reconciliation to complete
+func (o *Operator) Stop(ctx context.Context) {
+	if o.cancel == nil {
+		return
+	}
+
+	o.cancel()
+
+	select {
+	case <-o.done:
+	case <-ctx.Done():
+	}
+}
+
+// reconcile schedules the accounts of all the valid policies and updates
+// the status of every policy
+func (o *Operator) reconcile(ctx context.Context) {
+	list, err := o.client.Resource(policyResource).Namespace(o.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// keep the current accounts until the policies can be read again
+		o.logger.Error("failed listing the carbon policies", "error", err)
+		return
+	}
+
+	policies := make([]*policy, len(list.Items))
+	errs := make([]error, len(list.Items))
+	accounts := make(map[v1.Provider][]config.Account)
+	for i := range list.Items {
+		policies[i], errs[i] = decodePolicy(&list.Items[i])
+		if errs[i] != nil {
+			continue
+		}
+
+		for provider, p := range policies[i].spec.Providers {
+			accounts[provider] = append(accounts[provider], p.Accounts...)
+		}
+	}
+
+	o.accounts.SetExternalAccounts(accounts)
+
+	scrapes := make(map[string]v1.ScrapeStatus)
+	for _, s := range o.accounts.Status() {
+		scrapes[s.Provider.String()+"/"+s.Account] = s
+	}
+
+	for i := range list.Items {
+		obj := &list.Items[i]
+
+		var status policyStatus
+		if errs[i] != nil {
+			status = o.invalidStatus(obj, errs[i])
+		} else {
+			status = o.status(policies[i], scrapes)
+		}
+
+		if err := o.updateStatus(ctx, obj, &status); err != nil {
+			// retried on the next resync
+			o.logger.Error("failed updating the carbon policy status",
+				"namespace", obj.GetNamespace(), "name", obj.GetName(), "error", err)
+		}
+	}
+}
+
+// status returns the status of a valid policy
+func (o *Operator) status(p *policy, scrapes map[string]v1.ScrapeStatus) policyStatus {
+	status := policyStatus{
+		ObservedGeneration: p.object.GetGeneration(),
+		Conditions:         currentConditions(p.object),
+	}
+
+	var degraded []string
+	for provider, pp := range p.spec.Providers {
+		for i := range pp.Accounts {
+			id := pp.Accounts[i].ID()
+			account := accountStatus{Provider: provider, Account: id}
+
+			// the account is not scraped by this replica or not started yet
+			if s, ok := scrapes[provider.String()+"/"+id]; ok {
+				account.Degraded = s.Degraded
+				account.Instances = s.Instances
+				account.LastError = s.LastError
+				if !s.LastSuccess.IsZero() {
+					t := metav1.NewTime(s.LastSuccess)
+					account.LastSuccess = &t
+				}
+			}
+
+			if account.Degraded {
+				degraded = append(degraded, provider.String()+"/"+id)
+			}
+			status.Accounts = append(status.Accounts, account)
+		}
+	}
+
+	sort.Slice(status.Accounts, func(i, j int) bool {
+		if status.Accounts[i].Provider != status.Accounts[j].Provider {
+			return status.Accounts[i].Provider < status.Accounts[j].Provider
+		}
+		return status.Accounts[i].Account < status.Accounts[j].Account
+	})
+	sort.Strings(degraded)
+
+	var exceeded []string
+	for i := range p.spec.Budgets {
+		b := &p.spec.Budgets[i]
+
+		// the highest group is compared with the limit
+		var value float64
+		for j, r := range o.emissions.Query(b.query) {
+			if j == 0 || r.Value > value {
+				value = r.Value
+			}
+		}
+
+		bs := budgetStatus{
+			Name:     b.Name,
+			Value:    value,
+			Limit:    b.Limit,
+			Exceeded: value > b.Limit,
+		}
+		if bs.Exceeded {
+			exceeded = append(exceeded, b.Name)
+		}
+		status.Budgets = append(status.Budgets, bs)
+	}
+
+	o.setCondition(&status, conditionReady, true, "Reconciled",
+		fmt.Sprintf("%d accounts scheduled", len(status.Accounts)))
+
+	if len(degraded) > 0 {
+		o.setCondition(&status, conditionDegraded, true, "ScrapesFailing",
+			"accounts not scraped after too many failures: "+strings.Join(degraded, ", "))
+	} else {
+		o.setCondition(&status, conditionDegraded, false, "ScrapesSucceeding", "all the accounts are scraped")
+	}
+
+	if len(exceeded) > 0 {
+		o.setCondition(&status, conditionBudgetExceeded, true, "OverBudget",
+			"budgets exceeded: "+strings.Join(exceeded, ", "))
+	} else {
+		o.setCondition(&status, conditionBudgetExceeded, false, "WithinBudget", "all the budgets are met")
+	}
+
+	return status
+}
+
+// invalidStatus returns the status of a policy whose spec is invalid
+func (o *Operator) invalidStatus(obj *unstructured.Unstructured, err error) policyStatus {
+	status := policyStatus{
+		ObservedGeneration: obj.GetGeneration(),
+		Conditions:         currentConditions(obj),
+	}
+
+	o.setCondition(&status, conditionReady, false, "InvalidSpec", err.Error())
+
+	return status
+}
+
+// setCondition updates the condition, the transition time only changes
+// along with its status
+func (o *Operator) setCondition(status *policyStatus, kind string, value bool, reason, message string) {
+	s := metav1.ConditionFalse
+	if value {
+		s = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               kind,
+		Status:             s,
+		ObservedGeneration: status.ObservedGeneration,
+		LastTransitionTime: metav1.NewTime(o.now()),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateStatus writes the status of the policy if it changed
+func (o *Operator) updateStatus(ctx context.Context, obj *unstructured.Unstructured, status *policyStatus) error {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	var updated map[string]interface{}
+	if err := json.Unmarshal(b, &updated); err != nil {
+		return err
+	}
+
+	// compare the decoded json, the numbers of the object are not floats
+	if current, ok := obj.Object["status"]; ok {
+		var decoded map[string]interface{}
+		if b, err := json.Marshal(current); err == nil && json.Unmarshal(b, &decoded) == nil && reflect.DeepEqual(decoded, updated) {
+			return nil
+		}
+	}
+
+	obj = obj.DeepCopy()
+	obj.Object["status"] = updated
+
+	_, err = o.client.Resource(policyResource).Namespace(obj.GetNamespace()).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// currentConditions returns the conditions in the status of the policy
+func currentConditions(obj *unstructured.Unstructured) []metav1.Condition {
+	raw, ok, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if !ok {
+		return nil
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+
+	var conditions []metav1.Condition
+	if err := json.Unmarshal(b, &conditions); err != nil {
+		return nil
+	}
+
+	return conditions
+}
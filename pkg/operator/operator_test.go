@@ -0,0 +1,158 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// fakeManager records the accounts it's given
+type fakeManager struct {
+	accounts map[v1.Provider][]config.Account
+	status   []v1.ScrapeStatus
+}
+
+func (f *fakeManager) SetExternalAccounts(a map[v1.Provider][]config.Account) { f.accounts = a }
+func (f *fakeManager) Status() []v1.ScrapeStatus                              { return f.status }
+
+// fakeEmissions returns the same results for every query
+type fakeEmissions []store.Result
+
+func (f fakeEmissions) Query(*store.Query) []store.Result { return f }
+
+func newPolicy(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion("aether.re-cinq.com/v1alpha1")
+	obj.SetKind("CarbonPolicy")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetGeneration(1)
+	return obj
+}
+
+func TestReconcile(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	valid := newPolicy("platform", map[string]interface{}{
+		"providers": map[string]interface{}{
+			"aws": map[string]interface{}{
+				"accounts": []interface{}{
+					map[string]interface{}{"name": "prod", "regions": []interface{}{"eu-north-1"}},
+				},
+			},
+		},
+		"budgets": []interface{}{
+			map[string]interface{}{"name": "monthly", "query": "sum(emissions) where range=30d", "limit": int64(100)},
+		},
+	})
+	invalid := newPolicy("broken", map[string]interface{}{
+		"providers": map[string]interface{}{"unknown": map[string]interface{}{}},
+	})
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{policyResource: "CarbonPolicyList"}, valid, invalid)
+
+	manager := &fakeManager{status: []v1.ScrapeStatus{
+		{Provider: v1.AWS, Account: "prod", Degraded: true, Instances: 3, LastError: "denied"},
+	}}
+	o := newOperator(ctx, client, &config.OperatorConfig{}, manager, fakeEmissions{{Value: 150}})
+
+	o.reconcile(ctx)
+
+	// only the accounts of the valid policies are scheduled
+	assert.Equal(map[v1.Provider][]config.Account{
+		v1.AWS: {{Name: "prod", Regions: []string{"eu-north-1"}}},
+	}, manager.accounts)
+
+	status := func(name string) policyStatus {
+		obj, err := client.Resource(policyResource).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+		assert.NoError(err)
+		raw, ok := obj.Object["status"].(map[string]interface{})
+		assert.True(ok)
+
+		var s policyStatus
+		assert.NoError(runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &s))
+		return s
+	}
+
+	s := status("platform")
+	assert.Equal(int64(1), s.ObservedGeneration)
+	assert.True(meta.IsStatusConditionTrue(s.Conditions, conditionReady))
+	assert.True(meta.IsStatusConditionTrue(s.Conditions, conditionDegraded))
+	assert.True(meta.IsStatusConditionTrue(s.Conditions, conditionBudgetExceeded))
+	assert.Equal([]accountStatus{
+		{Provider: v1.AWS, Account: "prod", Degraded: true, Instances: 3, LastError: "denied"},
+	}, s.Accounts)
+	assert.Equal([]budgetStatus{
+		{Name: "monthly", Value: 150, Limit: 100, Exceeded: true},
+	}, s.Budgets)
+
+	s = status("broken")
+	ready := meta.FindStatusCondition(s.Conditions, conditionReady)
+	assert.NotNil(ready)
+	assert.Equal(metav1.ConditionFalse, ready.Status)
+	assert.Equal("InvalidSpec", ready.Reason)
+
+	// the transition time is kept while the condition doesn't change
+	transition := meta.FindStatusCondition(status("platform").Conditions, conditionReady).LastTransitionTime
+	o.now = func() time.Time { return time.Now().Add(time.Hour) }
+	o.reconcile(ctx)
+	assert.Equal(transition, meta.FindStatusCondition(status("platform").Conditions, conditionReady).LastTransitionTime)
+}
+
+func TestDecodePolicy(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  map[string]interface{}
+		valid bool
+	}{
+		{
+			name:  "empty",
+			spec:  map[string]interface{}{},
+			valid: true,
+		},
+		{
+			name:  "unknown field",
+			spec:  map[string]interface{}{"provider": map[string]interface{}{}},
+			valid: false,
+		},
+		{
+			name: "invalid query",
+			spec: map[string]interface{}{
+				"budgets": []interface{}{map[string]interface{}{"name": "b", "query": "median(emissions)"}},
+			},
+			valid: false,
+		},
+		{
+			name: "unnamed budget",
+			spec: map[string]interface{}{
+				"budgets": []interface{}{map[string]interface{}{"query": "sum(emissions)"}},
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+			_, err := decodePolicy(newPolicy("test", test.spec))
+			if test.valid {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// policyResource is the CarbonPolicy custom resource
+var policyResource = schema.GroupVersionResource{
+	Group:    "aether.re-cinq.com",
+	Version:  "v1alpha1",
+	Resource: "carbonpolicies",
+}
+
+// The condition types reported in the status of a policy
+const (
+	// The spec is valid and its accounts are scraped
+	conditionReady = "Ready"
+
+	// Some of the accounts are not scraped after too many failures
+	conditionDegraded = "Degraded"
+
+	// Some of the budgets are exceeded
+	conditionBudgetExceeded = "BudgetExceeded"
+)
+
+// policySpec is the spec of a CarbonPolicy
+// The accounts use the same fields as the config file
+type policySpec struct {
+	// The accounts to scrape, the key is the provider
+	Providers map[v1.Provider]struct {
+		Accounts []config.Account `mapstructure:"accounts"`
+	} `mapstructure:"providers"`
+
+	// The limits of the emissions
+	Budgets []budget `mapstructure:"budgets"`
+}
+
+// budget is a limit of the emissions matched by a query
+type budget struct {
+	// The name of the budget
+	Name string `mapstructure:"name"`
+
+	// The query the emissions are aggregated with, e.g.
+	//	sum(emissions) where team=platform and range=30d
+	Query string `mapstructure:"query"`
+
+	// The maximum value, in gCO2eq
+	Limit float64 `mapstructure:"limit"`
+
+	// The parsed query
+	query *store.Query
+}
+
+// policy is a decoded CarbonPolicy
+type policy struct {
+	object *unstructured.Unstructured
+	spec   policySpec
+}
+
+// policyStatus is the status of a CarbonPolicy
+type policyStatus struct {
+	// The generation of the spec the status refers to
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The Ready, Degraded and BudgetExceeded conditions
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The scraping status of the accounts of the policy
+	Accounts []accountStatus `json:"accounts,omitempty"`
+
+	// The current value of the budgets
+	Budgets []budgetStatus `json:"budgets,omitempty"`
+}
+
+// accountStatus is the scraping status of an account
+type accountStatus struct {
+	Provider    v1.Provider  `json:"provider"`
+	Account     string       `json:"account"`
+	Degraded    bool         `json:"degraded"`
+	LastSuccess *metav1.Time `json:"lastSuccess,omitempty"`
+	Instances   int          `json:"instances"`
+	LastError   string       `json:"lastError,omitempty"`
+}
+
+// budgetStatus is the current value of a budget
+type budgetStatus struct {
+	Name     string  `json:"name"`
+	Value    float64 `json:"value"`
+	Limit    float64 `json:"limit"`
+	Exceeded bool    `json:"exceeded"`
+}
+
+// decodePolicy decodes the spec of a CarbonPolicy
+// Unknown fields and invalid queries are reported as errors
+func decodePolicy(obj *unstructured.Unstructured) (*policy, error) {
+	p := &policy{object: obj}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           &p.spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(obj.Object["spec"]); err != nil {
+		return nil, err
+	}
+
+	for provider := range p.spec.Providers {
+		if _, ok := v1.Providers[provider.String()]; !ok {
+			return nil, fmt.Errorf("%w: %s", v1.ErrParsingProvider, provider)
+		}
+	}
+
+	for i := range p.spec.Budgets {
+		b := &p.spec.Budgets[i]
+		if b.Name == "" {
+			return nil, fmt.Errorf("budget %d has no name", i)
+		}
+
+		b.query, err = store.ParseQuery(b.Query)
+		if err != nil {
+			return nil, fmt.Errorf("budget %s: %w", b.Name, err)
+		}
+	}
+
+	return p, nil
+}
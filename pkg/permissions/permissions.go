@@ -0,0 +1,336 @@
+// Package permissions generates the least-privilege policies the exporter
+// needs for the features enabled in its config: the IAM policy of AWS, the
+// custom role of GCP, the role definition of Azure, the policy statements
+// of OCI, the token scopes of DigitalOcean and the IAM policy of Scaleway,
+// so that security teams grant exactly what is used
+package permissions
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The name and the description of the generated roles and policies
+const (
+	name        = "CloudCarbonExporter"
+	title       = "Cloud Carbon Exporter"
+	description = "Read-only access to the instances and the metrics the Cloud Carbon Exporter calculates the emissions of"
+)
+
+// DefaultGroup is the OCI group granted the policy statements when none is
+// set
+const DefaultGroup = "CloudCarbonExporter"
+
+// Options are the settings of the generated documents
+type Options struct {
+	// OCI: The group granted the policy statements, DefaultGroup when empty
+	Group string
+}
+
+// features are the features of the accounts of a provider which call APIs
+type features struct {
+	storage         bool
+	snapshots       bool
+	network         bool
+	soleTenantNodes bool
+	rightsizing     bool
+}
+
+// featuresOf returns the features enabled by any account of the provider
+func featuresOf(cfg *config.ApplicationConfig, provider v1.Provider) features {
+	f := features{rightsizing: cfg.Rightsizing.Enabled}
+	for _, a := range cfg.Providers[provider].Accounts {
+		f.storage = f.storage || a.Storage
+		f.snapshots = f.snapshots || a.Snapshots
+		f.network = f.network || a.Network
+		f.soleTenantNodes = f.soleTenantNodes || a.SoleTenantNodes
+	}
+	return f
+}
+
+// Providers returns the providers of the config which are granted
+// permissions, sorted
+func Providers(cfg *config.ApplicationConfig) []v1.Provider {
+	var providers []v1.Provider
+	for p, c := range cfg.Providers {
+		if len(c.Accounts) > 0 && supported(p) {
+			providers = append(providers, p)
+		}
+	}
+
+	// the throughput of the functional units is read from CloudWatch with
+	// the default credentials
+	if !slices.Contains(providers, v1.AWS) && cloudWatchThroughput(cfg) {
+		providers = append(providers, v1.AWS)
+	}
+
+	slices.Sort(providers)
+	return providers
+}
+
+// supported returns whether the permissions of the provider are known
+func supported(provider v1.Provider) bool {
+	switch provider {
+	case v1.AWS, v1.GCP, v1.Azure, v1.OCI, v1.DigitalOcean, v1.Scaleway:
+		return true
+	default:
+		return false
+	}
+}
+
+// cloudWatchThroughput returns whether a functional unit is read from
+// CloudWatch
+func cloudWatchThroughput(cfg *config.ApplicationConfig) bool {
+	for _, u := range cfg.FunctionalUnits.Units {
+		if u.CloudWatch.MetricName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Required returns the permissions the features of the provider enabled in
+// the config need, sorted, in the terms of the provider
+func Required(cfg *config.ApplicationConfig, provider v1.Provider) ([]string, error) {
+	f := featuresOf(cfg, provider)
+
+	var permissions []string
+	switch provider {
+	case v1.AWS:
+		// the disks, the load balancers and the CDNs are read from
+		// CloudWatch too
+		permissions = []string{"ec2:DescribeInstances", "ec2:DescribeInstanceTypes", "cloudwatch:GetMetricData"}
+		if f.storage {
+			permissions = append(permissions, "ec2:DescribeVolumes")
+		}
+		if f.snapshots {
+			permissions = append(permissions, "ec2:DescribeSnapshots", "ec2:DescribeImages")
+		}
+		if f.rightsizing {
+			permissions = append(permissions, "compute-optimizer:GetEC2InstanceRecommendations")
+		}
+	case v1.GCP:
+		// the disks are the ones of the instances and their I/O is read
+		// from Cloud Monitoring like the network
+		permissions = []string{"compute.instances.list", "compute.machineTypes.get", "monitoring.timeSeries.list"}
+		if f.soleTenantNodes {
+			permissions = append(permissions, "compute.nodeGroups.list", "compute.nodeGroups.get")
+		}
+		if f.snapshots {
+			permissions = append(permissions, "compute.snapshots.list", "compute.images.list")
+		}
+		if f.rightsizing {
+			permissions = append(permissions, "recommender.computeInstanceMachineTypeRecommendations.list")
+		}
+	case v1.Azure:
+		permissions = []string{
+			"Microsoft.Compute/virtualMachines/read",
+			"Microsoft.Compute/locations/vmSizes/read",
+			"Microsoft.Insights/metrics/read",
+		}
+	case v1.OCI:
+		permissions = []string{"inspect instances", "read metrics"}
+	case v1.DigitalOcean:
+		permissions = []string{"droplet:read", "monitoring:read"}
+	case v1.Scaleway:
+		// the permission sets of the IAM policy, the metrics are queried
+		// with a Cockpit token
+		permissions = []string{"InstancesReadOnly", "ElasticMetalReadOnly", "ObservabilityReadOnly"}
+	default:
+		return nil, fmt.Errorf("no known permissions for provider %s", provider)
+	}
+
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
+// Document returns the policy granting the permissions the provider needs,
+// ready to be marshalled to JSON:
+//   - AWS: an IAM policy document
+//   - GCP: a custom role, e.g. for gcloud iam roles create --file
+//   - Azure: a custom role definition assignable to the subscriptions of
+//     the accounts
+//   - OCI: the statements of a policy, in the compartments of the accounts
+//   - DigitalOcean: the scopes of a custom API token
+//   - Scaleway: an IAM policy on the projects of the accounts and the
+//     scopes of the Cockpit token
+func Document(cfg *config.ApplicationConfig, provider v1.Provider, opts Options) (any, error) {
+	permissions, err := Required(cfg, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	switch provider {
+	case v1.AWS:
+		return awsPolicy{
+			Version: "2012-10-17",
+			Statement: []awsStatement{
+				{
+					Sid:      name,
+					Effect:   "Allow",
+					Action:   permissions,
+					Resource: "*",
+				},
+			},
+		}, nil
+	case v1.GCP:
+		return gcpRole{
+			Title:               title,
+			Description:         description,
+			Stage:               "GA",
+			IncludedPermissions: permissions,
+		}, nil
+	case v1.Azure:
+		return azureRole{
+			Name:             title,
+			IsCustom:         true,
+			Description:      description,
+			Actions:          permissions,
+			NotActions:       []string{},
+			AssignableScopes: azureScopes(cfg),
+		}, nil
+	case v1.OCI:
+		return ociPolicy{
+			Name:        name,
+			Description: description,
+			Statements:  ociStatements(cfg, permissions, opts.Group),
+		}, nil
+	case v1.Scaleway:
+		return scalewayPolicy{
+			Name:        name,
+			Description: description,
+			Rules: []scalewayRule{
+				{
+					PermissionSetNames: permissions,
+					ProjectIDs:         scalewayProjects(cfg),
+				},
+			},
+			CockpitTokenScopes: []string{"query_metrics"},
+		}, nil
+	default:
+		return digitalOceanToken{
+			Name:   name,
+			Scopes: permissions,
+		}, nil
+	}
+}
+
+// awsPolicy is an IAM policy document
+type awsPolicy struct {
+	Version   string         `json:"Version"`
+	Statement []awsStatement `json:"Statement"`
+}
+
+type awsStatement struct {
+	Sid      string   `json:"Sid"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+// gcpRole is a custom role of GCP IAM
+type gcpRole struct {
+	Title               string   `json:"title"`
+	Description         string   `json:"description"`
+	Stage               string   `json:"stage"`
+	IncludedPermissions []string `json:"includedPermissions"`
+}
+
+// azureRole is a custom role definition of Azure RBAC, as created by az role
+// definition create
+type azureRole struct {
+	Name             string   `json:"Name"`
+	IsCustom         bool     `json:"IsCustom"`
+	Description      string   `json:"Description"`
+	Actions          []string `json:"Actions"`
+	NotActions       []string `json:"NotActions"`
+	AssignableScopes []string `json:"AssignableScopes"`
+}
+
+// ociPolicy is an OCI IAM policy, created in the root compartment
+type ociPolicy struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Statements  []string `json:"statements"`
+}
+
+// digitalOceanToken is a custom scoped API token of DigitalOcean
+type digitalOceanToken struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// scalewayPolicy is an IAM policy of Scaleway, as created by scw iam policy
+// create, and the scopes of the Cockpit token the metrics are queried with
+type scalewayPolicy struct {
+	Name               string         `json:"name"`
+	Description        string         `json:"description"`
+	Rules              []scalewayRule `json:"rules"`
+	CockpitTokenScopes []string       `json:"cockpit_token_scopes"`
+}
+
+// scalewayRule grants permission sets on projects, on the organization
+// without projects
+type scalewayRule struct {
+	PermissionSetNames []string `json:"permission_set_names"`
+	ProjectIDs         []string `json:"project_ids,omitempty"`
+}
+
+// scalewayProjects returns the projects of the Scaleway accounts, sorted
+func scalewayProjects(cfg *config.ApplicationConfig) []string {
+	var projects []string
+	for _, a := range cfg.Providers[v1.Scaleway].Accounts {
+		if a.Project != "" && !slices.Contains(projects, a.Project) {
+			projects = append(projects, a.Project)
+		}
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// azureScopes returns the subscriptions of the Azure accounts, sorted
+func azureScopes(cfg *config.ApplicationConfig) []string {
+	scopes := []string{}
+	for _, a := range cfg.Providers[v1.Azure].Accounts {
+		scope := "/subscriptions/" + a.Subscription
+		if a.Subscription != "" && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// ociStatements returns the statements granting the permissions to the group
+// in the compartments of the OCI accounts, the whole tenancy for the accounts
+// without compartments
+func ociStatements(cfg *config.ApplicationConfig, permissions []string, group string) []string {
+	if group == "" {
+		group = DefaultGroup
+	}
+
+	var locations []string
+	for _, a := range cfg.Providers[v1.OCI].Accounts {
+		if len(a.Compartments) == 0 {
+			locations = append(locations, "tenancy")
+			continue
+		}
+		for _, c := range a.Compartments {
+			locations = append(locations, "compartment id "+c)
+		}
+	}
+	slices.Sort(locations)
+	locations = slices.Compact(locations)
+
+	statements := make([]string, 0, len(permissions)*len(locations))
+	for _, location := range locations {
+		for _, p := range permissions {
+			statements = append(statements, fmt.Sprintf("Allow group %s to %s in %s", group, p, location))
+		}
+	}
+	return statements
+}
@@ -0,0 +1,253 @@
+package permissions
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequired(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    v1.Provider
+		accounts    []config.Account
+		rightsizing bool
+		permissions []string
+	}{
+		{
+			name:        "aws instances",
+			provider:    v1.AWS,
+			accounts:    []config.Account{{}},
+			permissions: []string{"cloudwatch:GetMetricData", "ec2:DescribeInstanceTypes", "ec2:DescribeInstances"},
+		},
+		{
+			name:     "aws features of any account",
+			provider: v1.AWS,
+			accounts: []config.Account{
+				{Storage: true},
+				{Snapshots: true, Network: true},
+			},
+			rightsizing: true,
+			permissions: []string{
+				"cloudwatch:GetMetricData",
+				"compute-optimizer:GetEC2InstanceRecommendations",
+				"ec2:DescribeImages",
+				"ec2:DescribeInstanceTypes",
+				"ec2:DescribeInstances",
+				"ec2:DescribeSnapshots",
+				"ec2:DescribeVolumes",
+			},
+		},
+		{
+			name:        "gcp instances with their disks",
+			provider:    v1.GCP,
+			accounts:    []config.Account{{Storage: true, Network: true}},
+			permissions: []string{"compute.instances.list", "compute.machineTypes.get", "monitoring.timeSeries.list"},
+		},
+		{
+			name:     "gcp features",
+			provider: v1.GCP,
+			accounts: []config.Account{
+				{SoleTenantNodes: true, Snapshots: true},
+			},
+			rightsizing: true,
+			permissions: []string{
+				"compute.images.list",
+				"compute.instances.list",
+				"compute.machineTypes.get",
+				"compute.nodeGroups.get",
+				"compute.nodeGroups.list",
+				"compute.snapshots.list",
+				"monitoring.timeSeries.list",
+				"recommender.computeInstanceMachineTypeRecommendations.list",
+			},
+		},
+		{
+			name:     "azure",
+			provider: v1.Azure,
+			accounts: []config.Account{{Subscription: "demo"}},
+			permissions: []string{
+				"Microsoft.Compute/locations/vmSizes/read",
+				"Microsoft.Compute/virtualMachines/read",
+				"Microsoft.Insights/metrics/read",
+			},
+		},
+		{
+			name:        "oci",
+			provider:    v1.OCI,
+			accounts:    []config.Account{{}},
+			permissions: []string{"inspect instances", "read metrics"},
+		},
+		{
+			name:        "digitalocean",
+			provider:    v1.DigitalOcean,
+			accounts:    []config.Account{{}},
+			permissions: []string{"droplet:read", "monitoring:read"},
+		},
+		{
+			name:        "scaleway",
+			provider:    v1.Scaleway,
+			accounts:    []config.Account{{}},
+			permissions: []string{"ElasticMetalReadOnly", "InstancesReadOnly", "ObservabilityReadOnly"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config.ApplicationConfig{
+				Providers: map[v1.Provider]config.Provider{
+					test.provider: {Accounts: test.accounts},
+				},
+				Rightsizing: config.RightsizingConfig{Enabled: test.rightsizing},
+			}
+
+			permissions, err := Required(cfg, test.provider)
+			require.NoError(t, err)
+			require.Equal(t, test.permissions, permissions)
+		})
+	}
+
+	_, err := Required(&config.ApplicationConfig{}, v1.Prometheus)
+	require.ErrorContains(t, err, "no known permissions")
+}
+
+func TestProviders(t *testing.T) {
+	assert := require.New(t)
+
+	cfg := &config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			v1.GCP:        {Accounts: []config.Account{{Project: "demo"}}},
+			v1.Azure:      {Accounts: []config.Account{{Subscription: "demo"}}},
+			v1.Prometheus: {Accounts: []config.Account{{}}},
+			v1.OCI:        {},
+		},
+	}
+	assert.Equal([]v1.Provider{v1.Azure, v1.GCP}, Providers(cfg))
+
+	// the functional units read from CloudWatch need AWS credentials
+	cfg.FunctionalUnits.Units = []config.FunctionalUnit{
+		{CloudWatch: config.CloudWatchThroughput{MetricName: "RequestCount"}},
+	}
+	assert.Equal([]v1.Provider{v1.AWS, v1.Azure, v1.GCP}, Providers(cfg))
+}
+
+func TestDocument(t *testing.T) {
+	cfg := &config.ApplicationConfig{
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {Accounts: []config.Account{{}}},
+			v1.GCP: {Accounts: []config.Account{{Project: "demo"}}},
+			v1.Azure: {Accounts: []config.Account{
+				{Subscription: "b"},
+				{Subscription: "a"},
+				{Subscription: "a"},
+			}},
+			v1.OCI: {Accounts: []config.Account{
+				{Compartments: []string{"ocid1.compartment.a"}},
+				{},
+			}},
+			v1.DigitalOcean: {Accounts: []config.Account{{}}},
+			v1.Scaleway: {Accounts: []config.Account{
+				{Project: "b"},
+				{Project: "a"},
+				{},
+			}},
+		},
+	}
+
+	tests := []struct {
+		provider v1.Provider
+		opts     Options
+		document string
+	}{
+		{
+			provider: v1.AWS,
+			document: `{
+				"Version": "2012-10-17",
+				"Statement": [{
+					"Sid": "CloudCarbonExporter",
+					"Effect": "Allow",
+					"Action": ["cloudwatch:GetMetricData", "ec2:DescribeInstanceTypes", "ec2:DescribeInstances"],
+					"Resource": "*"
+				}]
+			}`,
+		},
+		{
+			provider: v1.GCP,
+			document: `{
+				"title": "Cloud Carbon Exporter",
+				"description": "` + description + `",
+				"stage": "GA",
+				"includedPermissions": ["compute.instances.list", "compute.machineTypes.get", "monitoring.timeSeries.list"]
+			}`,
+		},
+		{
+			provider: v1.Azure,
+			document: `{
+				"Name": "Cloud Carbon Exporter",
+				"IsCustom": true,
+				"Description": "` + description + `",
+				"Actions": [
+					"Microsoft.Compute/locations/vmSizes/read",
+					"Microsoft.Compute/virtualMachines/read",
+					"Microsoft.Insights/metrics/read"
+				],
+				"NotActions": [],
+				"AssignableScopes": ["/subscriptions/a", "/subscriptions/b"]
+			}`,
+		},
+		{
+			provider: v1.OCI,
+			opts:     Options{Group: "carbon"},
+			document: `{
+				"name": "CloudCarbonExporter",
+				"description": "` + description + `",
+				"statements": [
+					"Allow group carbon to inspect instances in compartment id ocid1.compartment.a",
+					"Allow group carbon to read metrics in compartment id ocid1.compartment.a",
+					"Allow group carbon to inspect instances in tenancy",
+					"Allow group carbon to read metrics in tenancy"
+				]
+			}`,
+		},
+		{
+			provider: v1.DigitalOcean,
+			document: `{
+				"name": "CloudCarbonExporter",
+				"scopes": ["droplet:read", "monitoring:read"]
+			}`,
+		},
+		{
+			provider: v1.Scaleway,
+			document: `{
+				"name": "CloudCarbonExporter",
+				"description": "` + description + `",
+				"rules": [{
+					"permission_set_names": ["ElasticMetalReadOnly", "InstancesReadOnly", "ObservabilityReadOnly"],
+					"project_ids": ["a", "b"]
+				}],
+				"cockpit_token_scopes": ["query_metrics"]
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.provider.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			doc, err := Document(cfg, test.provider, test.opts)
+			assert.NoError(err)
+
+			b, err := json.Marshal(doc)
+			assert.NoError(err)
+			assert.JSONEq(test.document, string(b))
+		})
+	}
+
+	// the statements of OCI are granted to the default group
+	doc, err := Document(cfg, v1.OCI, Options{})
+	require.NoError(t, err)
+	require.Contains(t, doc.(ociPolicy).Statements[0], "Allow group "+DefaultGroup+" ")
+}
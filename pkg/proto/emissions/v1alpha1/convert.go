@@ -0,0 +1,212 @@
+package emissionsv1alpha1
+
+import (
+	"maps"
+	"sort"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	resourceTypes = map[v1.ResourceType]ResourceType{
+		v1.CPU:     ResourceType_RESOURCE_TYPE_CPU,
+		v1.Memory:  ResourceType_RESOURCE_TYPE_MEMORY,
+		v1.Storage: ResourceType_RESOURCE_TYPE_STORAGE,
+		v1.Network: ResourceType_RESOURCE_TYPE_NETWORK,
+	}
+
+	powerSources = map[v1.PowerSource]PowerSource{
+		v1.PowerMeasured: PowerSource_POWER_SOURCE_MEASURED,
+		v1.PowerCurve:    PowerSource_POWER_SOURCE_CURVE,
+		v1.PowerFallback: PowerSource_POWER_SOURCE_FALLBACK,
+	}
+
+	intensitySources = map[v1.IntensitySource]IntensitySource{
+		v1.IntensityRealTime: IntensitySource_INTENSITY_SOURCE_REALTIME,
+		v1.IntensityAnnual:   IntensitySource_INTENSITY_SOURCE_ANNUAL,
+	}
+
+	tiers = map[v1.Tier]Tier{
+		v1.TierHigh:   Tier_TIER_HIGH,
+		v1.TierMedium: Tier_TIER_MEDIUM,
+		v1.TierLow:    Tier_TIER_LOW,
+	}
+)
+
+// FromInstance returns the message of the instance, its metrics are sorted
+// by name
+func FromInstance(i *v1.Instance) *Instance {
+	msg := &Instance{
+		Provider:                i.Provider.String(),
+		Service:                 i.Service,
+		Name:                    i.Name,
+		Region:                  i.Region,
+		Zone:                    i.Zone,
+		Kind:                    i.Kind,
+		Architecture:            i.Architecture,
+		CpuPlatform:             i.CPUPlatform,
+		StartedAt:               fromTime(i.StartedAt),
+		Metrics:                 make([]*Metric, 0, len(i.Metrics)),
+		OperationalCpuEmissions: FromEmissions(&i.OperationalCPUEmissions),
+		EmbodiedEmissions:       FromEmissions(&i.EmbodiedEmissions),
+		Labels:                  maps.Clone(i.Labels),
+	}
+
+	for name := range i.Metrics {
+		m := i.Metrics[name]
+		msg.Metrics = append(msg.Metrics, FromMetric(&m))
+	}
+	sort.Slice(msg.Metrics, func(a, b int) bool {
+		return msg.Metrics[a].Name < msg.Metrics[b].Name
+	})
+
+	return msg
+}
+
+// ToInstance returns the instance of the message
+func ToInstance(msg *Instance) *v1.Instance {
+	i := &v1.Instance{
+		Provider:                v1.Provider(msg.GetProvider()),
+		Service:                 msg.GetService(),
+		Name:                    msg.GetName(),
+		Region:                  msg.GetRegion(),
+		Zone:                    msg.GetZone(),
+		Kind:                    msg.GetKind(),
+		Architecture:            msg.GetArchitecture(),
+		CPUPlatform:             msg.GetCpuPlatform(),
+		StartedAt:               toTime(msg.GetStartedAt()),
+		Metrics:                 v1.Metrics{},
+		OperationalCPUEmissions: ToEmissions(msg.GetOperationalCpuEmissions()),
+		EmbodiedEmissions:       ToEmissions(msg.GetEmbodiedEmissions()),
+		Labels:                  v1.Labels{},
+	}
+
+	for _, m := range msg.GetMetrics() {
+		i.Metrics.Upsert(ToMetric(m))
+	}
+	for key, value := range msg.GetLabels() {
+		i.Labels[key] = value
+	}
+
+	return i
+}
+
+// FromMetric returns the message of the metric
+func FromMetric(m *v1.Metric) *Metric {
+	msg := &Metric{
+		Name:         m.Name,
+		ResourceType: resourceTypes[m.ResourceType],
+		Usage:        m.Usage,
+		UnitAmount:   m.UnitAmount,
+		Unit:         m.Unit.String(),
+		Power:        m.Power,
+		Iops:         m.IOPS,
+		Throughput:   m.Throughput,
+		Emissions:    FromEmissions(&m.Emissions),
+		UpdatedAt:    fromTime(m.UpdatedAt),
+		Labels:       maps.Clone(m.Labels),
+	}
+
+	if !m.Observed.IsZero() {
+		msg.Observed = &Window{
+			Start: fromTime(m.Observed.Start),
+			End:   fromTime(m.Observed.End),
+		}
+	}
+
+	return msg
+}
+
+// ToMetric returns the metric of the message
+func ToMetric(msg *Metric) *v1.Metric {
+	m := &v1.Metric{
+		Name:       msg.GetName(),
+		Usage:      msg.GetUsage(),
+		UnitAmount: msg.GetUnitAmount(),
+		Unit:       v1.ResourceUnit(msg.GetUnit()),
+		Power:      msg.GetPower(),
+		IOPS:       msg.GetIops(),
+		Throughput: msg.GetThroughput(),
+		Emissions:  ToEmissions(msg.GetEmissions()),
+		UpdatedAt:  toTime(msg.GetUpdatedAt()),
+		Labels:     v1.Labels{},
+	}
+
+	for resourceType, value := range resourceTypes {
+		if value == msg.GetResourceType() {
+			m.ResourceType = resourceType
+		}
+	}
+
+	if observed := msg.GetObserved(); observed != nil {
+		m.Observed = v1.Window{
+			Start: toTime(observed.GetStart()),
+			End:   toTime(observed.GetEnd()),
+		}
+	}
+
+	for key, value := range msg.GetLabels() {
+		m.Labels[key] = value
+	}
+
+	return m
+}
+
+// FromEmissions returns the message of the emissions, the tier of their
+// quality is set when their power source is known
+func FromEmissions(e *v1.ResourceEmissions) *Emission {
+	msg := &Emission{
+		Value: e.Value,
+		Unit:  e.Unit.String(),
+		Quality: &Quality{
+			Power:     powerSources[e.Quality.Power],
+			Intensity: intensitySources[e.Quality.Intensity],
+		},
+	}
+
+	if e.Quality.Power != "" {
+		msg.Quality.Tier = tiers[e.Quality.Tier()]
+	}
+
+	return msg
+}
+
+// ToEmissions returns the emissions of the message, the tier of their
+// quality is derived from the sources
+func ToEmissions(msg *Emission) v1.ResourceEmissions {
+	e := v1.ResourceEmissions{
+		Value: msg.GetValue(),
+		Unit:  v1.EmissionUnit(msg.GetUnit()),
+	}
+
+	for source, value := range powerSources {
+		if value == msg.GetQuality().GetPower() {
+			e.Quality.Power = source
+		}
+	}
+	for source, value := range intensitySources {
+		if value == msg.GetQuality().GetIntensity() {
+			e.Quality.Intensity = source
+		}
+	}
+
+	return e
+}
+
+// fromTime returns the timestamp of the time, nil when it's zero
+func fromTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// toTime returns the time of the timestamp, zero when it's nil
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
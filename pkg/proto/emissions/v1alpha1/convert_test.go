@@ -0,0 +1,94 @@
+package emissionsv1alpha1
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInstanceRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	instance := &v1.Instance{
+		Provider:     v1.AWS,
+		Service:      "instance",
+		Name:         "i-0123",
+		Region:       "eu-west-1",
+		Zone:         "eu-west-1a",
+		Kind:         "m5.xlarge",
+		Architecture: "x86_64",
+		StartedAt:    now.Add(-time.Hour),
+		Metrics:      v1.Metrics{},
+		OperationalCPUEmissions: v1.ResourceEmissions{
+			Value:   12.5,
+			Unit:    v1.GCO2eqkWh,
+			Quality: v1.Quality{Power: v1.PowerCurve, Intensity: v1.IntensityAnnual},
+		},
+		EmbodiedEmissions: v1.ResourceEmissions{
+			Value:   3,
+			Unit:    v1.GCO2eqkWh,
+			Quality: v1.Quality{Power: v1.PowerFallback},
+		},
+		Labels: v1.Labels{v1.AccountLabel: "prod"},
+	}
+	instance.Metrics.Upsert(&v1.Metric{
+		Name:         v1.Memory.String(),
+		ResourceType: v1.Memory,
+		Usage:        50,
+		UnitAmount:   16,
+		Unit:         v1.GB,
+		UpdatedAt:    now,
+		Labels:       v1.Labels{},
+	})
+	instance.Metrics.Upsert(&v1.Metric{
+		Name:         v1.CPU.String(),
+		ResourceType: v1.CPU,
+		Usage:        40,
+		UnitAmount:   4,
+		Unit:         v1.VCPU,
+		Power:        35,
+		Emissions:    v1.ResourceEmissions{Value: 12.5, Unit: v1.GCO2eqkWh, Quality: v1.Quality{Power: v1.PowerMeasured, Intensity: v1.IntensityAnnual}},
+		UpdatedAt:    now,
+		Observed:     v1.NewWindow(now, time.Minute),
+		Labels:       v1.Labels{},
+	})
+	instance.Metrics.Upsert(&v1.Metric{
+		Name:         "vol-0123456789abcdef0",
+		ResourceType: v1.Storage,
+		UnitAmount:   100,
+		Unit:         v1.GB,
+		IOPS:         250,
+		Throughput:   4e6,
+		UpdatedAt:    now,
+		Labels:       v1.Labels{v1.MediaLabel: v1.MediaSSD},
+	})
+
+	msg := FromInstance(instance)
+	assert.Len(msg.Metrics, 3)
+	assert.Equal("cpu", msg.Metrics[0].Name)
+	assert.Equal(ResourceType_RESOURCE_TYPE_CPU, msg.Metrics[0].ResourceType)
+	assert.Equal(Tier_TIER_HIGH, msg.Metrics[0].Emissions.Quality.Tier)
+	assert.Equal(Tier_TIER_MEDIUM, msg.OperationalCpuEmissions.Quality.Tier)
+	assert.Equal(Tier_TIER_MEDIUM, msg.EmbodiedEmissions.Quality.Tier)
+	assert.Nil(msg.Metrics[1].Observed)
+
+	// the instance is the same once sent over the wire
+	data, err := proto.Marshal(msg)
+	assert.NoError(err)
+
+	var decoded Instance
+	assert.NoError(proto.Unmarshal(data, &decoded))
+	assert.Equal(instance, ToInstance(&decoded))
+}
+
+func TestEmissionsWithoutQuality(t *testing.T) {
+	assert := require.New(t)
+
+	msg := FromEmissions(&v1.ResourceEmissions{Value: 1, Unit: v1.GCO2eqkWh})
+	assert.Equal(Tier_TIER_UNSPECIFIED, msg.Quality.Tier)
+	assert.Equal(v1.ResourceEmissions{Value: 1, Unit: v1.GCO2eqkWh}, ToEmissions(msg))
+}
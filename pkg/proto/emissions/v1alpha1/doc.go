@@ -0,0 +1,6 @@
+// Package emissionsv1alpha1 is the protobuf model of the instances and their
+// emissions, the stable contract of the external consumers, and its
+// conversions from the types of pkg/types/v1
+package emissionsv1alpha1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative emissions.proto
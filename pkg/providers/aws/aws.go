@@ -14,6 +14,8 @@ import (
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/patrickmn/go-cache"
 	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/flowlogs"
+	"github.com/re-cinq/aether/pkg/providers/util"
 )
 
 var (
@@ -30,9 +32,17 @@ type Client struct {
 	ec2Client        *ec2Client
 	cloudWatchClient *cloudWatchClient
 
+	// The managed control planes of the EKS clusters
+	controlPlanes []util.ControlPlane
+
+	// The summary of the flow logs, nil when there's none
+	flowLogs *flowlogs.Source
+
 	cache *cache.Cache
 }
 
+type options func(*Client)
+
 // NewClient creates a struct with the AWS config, EC2 Client, and CloudWatch Client
 // It allows to pass:
 //   - configFile: the location of the config file to load. If empty the default
@@ -40,32 +50,66 @@ type Client struct {
 //   - profile: the name of the profile to use to load the credentials
 //     if empty the default credentials will be used
 //
-// TODO: use options pattern
-func New(ctx context.Context, currentConfig *config.Account, customTransportConfig *config.TransportConfig) (*Client, error) {
+// The options can overwrite the service clients, e.g. with fakes
+func New(ctx context.Context, currentConfig *config.Account, customTransportConfig *config.TransportConfig, opts ...options) (*Client, error) {
 	cfg, err := buildAWSConfig(ctx, currentConfig, customTransportConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing AWS client: %s", err)
 	}
 
+	c := &Client{
+		cfg: cfg,
+		// TODO: configure expiry and deletion
+		cache: cache.New(12*time.Hour, 36*time.Minute),
+	}
+
+	// overwrite any options
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	// Init the ec2 client
-	ec2Client := NewEC2Client(cfg)
-	if ec2Client == nil {
-		return nil, errors.New("error initializing EC2 client")
+	if c.ec2Client == nil {
+		c.ec2Client = NewEC2Client(cfg)
+		if c.ec2Client == nil {
+			return nil, errors.New("error initializing EC2 client")
+		}
 	}
 
 	// Init the cloudwatch client
-	cloudWatchClient := NewCloudWatchClient(ctx, cfg)
-	if cloudWatchClient == nil {
-		return nil, errors.New("error initializing CloudWatch client")
+	if c.cloudWatchClient == nil {
+		c.cloudWatchClient = NewCloudWatchClient(ctx, cfg)
+		if c.cloudWatchClient == nil {
+			return nil, errors.New("error initializing CloudWatch client")
+		}
 	}
 
-	return &Client{
-		cfg:              cfg,
-		ec2Client:        ec2Client,
-		cloudWatchClient: cloudWatchClient,
-		// TODO: configure expiry and deletion
-		cache: cache.New(12*time.Hour, 36*time.Minute),
-	}, nil
+	// the private endpoints of the APIs, e.g. the VPC endpoints
+	c.ec2Client.endpoint = currentConfig.Endpoints[ec2API]
+	c.cloudWatchClient.endpoint = currentConfig.Endpoints[cloudWatchAPI]
+
+	// the volumes of the instances and their I/O
+	c.ec2Client.storage = currentConfig.Storage
+	c.cloudWatchClient.storage = currentConfig.Storage
+
+	// the snapshots and the images of the account
+	c.ec2Client.snapshots = currentConfig.Snapshots
+
+	// the load balancers and the CloudFront distributions
+	c.cloudWatchClient.network = currentConfig.Network
+
+	// the network and the I/O are collected at the granularity of the
+	// provider
+	c.cloudWatchClient.cadence = util.NewCadence(provider)
+
+	c.flowLogs = flowlogs.New(currentConfig.FlowLogs)
+
+	c.controlPlanes, err = util.ControlPlanes(eksControlPlane, currentConfig.ControlPlanes)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
 }
 
 // Helper function to builde the AWS config
@@ -103,6 +147,11 @@ func buildAWSConfig(ctx context.Context, currentConfig *config.Account, customTr
 		loadExternalConfigs = append(loadExternalConfigs, awsConfig.WithSharedConfigProfile(currentConfig.Config.Profile))
 	}
 
+	// The dual-stack endpoints are reachable from the IPv6-only networks
+	if currentConfig.DualStack {
+		loadExternalConfigs = append(loadExternalConfigs, awsConfig.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+
 	// -------------------------------------------------------------------
 	// Http client
 	httpClient := awshttp.NewBuildableClient().WithDialerOptions(func(d *net.Dialer) {
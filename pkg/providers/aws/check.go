@@ -0,0 +1,103 @@
+package amazon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Check verifies the permissions of the scrapes in the first region, the
+// IAM policies apply to all of them
+func (s *Scraper) Check(ctx context.Context) []v1.Permission {
+	if len(s.regions) == 0 {
+		return nil
+	}
+	region := s.regions[0]
+
+	permissions := s.Client.ec2Client.check(ctx, region)
+	return append(permissions, s.Client.cloudWatchClient.check(ctx, region))
+}
+
+// check makes a dry run of the EC2 calls of the scrapes in the region
+func (e *ec2Client) check(ctx context.Context, region string) []v1.Permission {
+	withRegion := func(o *ec2.Options) {
+		o.Region = region
+		if endpoint := regionalEndpoint(e.endpoint, region); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
+	}
+
+	_, err := e.client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{DryRun: aws.Bool(true)}, withRegion)
+	permissions := []v1.Permission{dryRun("ec2:DescribeInstances", err)}
+
+	if e.storage {
+		_, err := e.client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{DryRun: aws.Bool(true)}, withRegion)
+		permissions = append(permissions, dryRun("ec2:DescribeVolumes", err))
+	}
+
+	if e.snapshots {
+		_, err := e.client.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{DryRun: aws.Bool(true)}, withRegion)
+		permissions = append(permissions, dryRun("ec2:DescribeSnapshots", err))
+
+		_, err = e.client.DescribeImages(ctx, &ec2.DescribeImagesInput{DryRun: aws.Bool(true)}, withRegion)
+		permissions = append(permissions, dryRun("ec2:DescribeImages", err))
+	}
+
+	return permissions
+}
+
+// dryRun returns the permission checked by a dry-run call of the EC2 API,
+// which fails with DryRunOperation when it's granted
+func dryRun(name string, err error) v1.Permission {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "DryRunOperation" {
+		err = nil
+	}
+
+	return util.Permission(name, err)
+}
+
+// check queries a metric no instance has over the last minutes, the
+// CloudWatch API has no dry run. It returns no data, so nothing is billed
+func (e *cloudWatchClient) check(ctx context.Context, region string) v1.Permission {
+	withRegion := func(o *cloudwatch.Options) {
+		o.Region = region
+		if endpoint := regionalEndpoint(e.endpoint, region); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
+	}
+
+	end := time.Now().UTC().Truncate(time.Minute)
+	start := end.Add(-basicPeriod)
+
+	_, err := e.client.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+		StartTime: &start,
+		EndTime:   &end,
+		MetricDataQueries: []cwtypes.MetricDataQuery{
+			{
+				Id: aws.String("check"),
+				MetricStat: &cwtypes.MetricStat{
+					Metric: &cwtypes.Metric{
+						Namespace:  aws.String(ec2Service),
+						MetricName: aws.String("CPUUtilization"),
+						Dimensions: []cwtypes.Dimension{
+							{Name: aws.String("InstanceId"), Value: aws.String("i-00000000000000000")},
+						},
+					},
+					Period: aws.Int32(int32(basicPeriod.Seconds())),
+					Stat:   aws.String("Average"),
+				},
+			},
+		},
+	}, withRegion)
+
+	return util.Permission("cloudwatch:GetMetricData", err)
+}
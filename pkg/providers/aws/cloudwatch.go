@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,9 +18,31 @@ import (
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
 )
 
+//counterfeiter:generate -o fake_cloudwatch_test.go -fake-name fakeCloudWatch github.com/aws/aws-sdk-go-v2/service/cloudwatch.GetMetricDataAPIClient
+
 // Helper service to get CloudWatch data
 type cloudWatchClient struct {
-	client *cloudwatch.Client
+	// The CloudWatch API, faked by the tests
+	client cloudwatch.GetMetricDataAPIClient
+
+	// The configured endpoint of the API, e.g. a VPC endpoint
+	endpoint string
+
+	// Whether the I/O of the EBS volumes is collected
+	storage bool
+
+	// Whether the bytes transferred by the load balancers and the
+	// CloudFront distributions are collected
+	network bool
+
+	// When the network and the I/O of the volumes are collected, nil when
+	// they're collected at every scrape
+	cadence *util.Cadence
+
+	// The I/O of the volumes of the last collection by region, used until
+	// the next one
+	volumeIO   map[string]map[string]ioRates
+	volumeIOMu sync.Mutex
 }
 
 // New cloudwatch client instance
@@ -40,16 +64,16 @@ func NewCloudWatchClient(ctx context.Context, cfg *aws.Config) *cloudWatchClient
 	}
 }
 
-// Get the resource consumption of an ec2 instance
-func (e *cloudWatchClient) GetEC2Metrics(ca *cache.Cache, region string, interval time.Duration) ([]v1.Instance, error) {
+// Get the resource consumption of an ec2 instance over the window
+func (e *cloudWatchClient) GetEC2Metrics(ctx context.Context, ca *cache.Cache, region string, window v1.Window) ([]v1.Instance, error) {
 	instances := []v1.Instance{}
 	local := make(map[string]*v1.Instance)
 
-	end := time.Now().UTC()
-	start := end.Add(-interval)
+	start := window.Start.UTC()
+	end := window.End.UTC()
 
 	// Get the cpu consumption for all the instances in the region
-	cpuMetrics, err := e.getEC2CPU(region, start, end, interval)
+	cpuMetrics, err := e.getEC2CPU(ctx, region, start, end)
 	if err != nil {
 		return instances, err
 	}
@@ -58,10 +82,21 @@ func (e *cloudWatchClient) GetEC2Metrics(ca *cache.Cache, region string, interva
 		return instances, fmt.Errorf("no cpu metrics collected from CloudWatch")
 	}
 
+	// the I/O of the volumes is optional, the volumes are accounted for
+	// by their size when it can't be collected
+	var volumeIO map[string]ioRates
+	if e.storage {
+		volumeIO, err = e.ebsIO(ctx, region, window)
+		if err != nil {
+			slog.Warn("failed to retrieve the ebs volumes io", "region", region, "error", err)
+		}
+	}
+
 	// TODO: Will need to iterate cpuMetrics and memMetrics
 	for i := range cpuMetrics {
 		// to avoid Implicit memory aliasing in for loop
 		metric := cpuMetrics[i]
+		metric.UpdatedAt = end
 
 		instanceID, ok := metric.Labels["instanceID"]
 		if !ok {
@@ -83,23 +118,41 @@ func (e *cloudWatchClient) GetEC2Metrics(ca *cache.Cache, region string, interva
 		if !exists {
 			// Then create a new local instance from cached
 			s = &v1.Instance{
-				Name:     instanceID,
-				Provider: provider,
-				Service:  ec2Service, // EC2
-				Kind:     meta.Kind,
-				Region:   region,
+				Name:         instanceID,
+				Provider:     provider,
+				Service:      ec2Service, // EC2
+				Kind:         meta.Kind,
+				Region:       region,
+				Zone:         meta.Zone,
+				Architecture: meta.Architecture,
+			}
+
+			// the volumes cached with the instance
+			for _, volume := range meta.Metrics {
+				volume.UpdatedAt = end
+				volume.IOPS = volumeIO[volume.Name].ops
+				volume.Throughput = volumeIO[volume.Name].bytes
+				s.Metrics.Upsert(&volume)
+			}
+		}
+		s.Labels.Add(v1.NameLabel, meta.Labels[v1.NameLabel])
+		if site := meta.Labels[v1.SiteLabel]; site != "" {
+			s.Labels.Add(v1.SiteLabel, site)
+		}
+		for k, v := range meta.Labels {
+			if strings.HasPrefix(k, v1.TagLabelPrefix) {
+				s.Labels.Add(k, v)
 			}
 		}
-		s.Labels.Add("Name", meta.Name)
 
 		// ParseFloat returns 0 on failure, since that's the default
 		// value of an unassigned int, store it regardless of the
 		// error. This value for vCPUs is a fallback to that provided
 		// by the dataset.
-		if vCPUs, exists := meta.Labels["VCPUCount"]; exists {
+		if vCPUs := meta.Labels["VCPUCount"]; vCPUs != "" {
 			metric.UnitAmount, err = strconv.ParseFloat(vCPUs, 64)
 			if err != nil {
-				slog.Error("failed to parse GCP total VCPUs", "error", err)
+				slog.Error("failed to parse EC2 total VCPUs", "error", err)
 			}
 		}
 		s.Metrics.Upsert(&metric)
@@ -111,56 +164,205 @@ func (e *cloudWatchClient) GetEC2Metrics(ca *cache.Cache, region string, interva
 	return instances, nil
 }
 
+// The resolutions of the EC2 metrics with the basic and the detailed
+// monitoring
+const (
+	basicPeriod    = 5 * time.Minute
+	detailedPeriod = time.Minute
+)
+
 // Get the CPU resource consumption of an ec2 instance
-func (e *cloudWatchClient) getEC2CPU(region string, start, end time.Time, interval time.Duration) ([]v1.Metric, error) {
+func (e *cloudWatchClient) getEC2CPU(ctx context.Context, region string, start, end time.Time) ([]v1.Metric, error) {
 	// Override the region
 	withRegion := func(o *cloudwatch.Options) {
 		o.Region = region
+		if endpoint := regionalEndpoint(e.endpoint, region); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
 	}
 
-	period := int32(interval.Seconds())
-	// validate the casting from float64 to int32
-	if float64(period) != interval.Seconds() {
-		return nil, fmt.Errorf("error casting %+v to int32", interval.Seconds())
+	// the usage is queried at the resolution of the detailed monitoring,
+	// the instances with the basic monitoring have a sample every 5
+	// minutes. The query starts a basic period earlier so that the sample
+	// covering the start of the window is returned, the samples are then
+	// averaged over the exact window
+	window := v1.Window{Start: start, End: end}
+	queryStart := start.Add(-basicPeriod)
+
+	if err := util.WaitForAPI(ctx, provider, cloudWatchAPI); err != nil {
+		return nil, err
 	}
 
-	// Make the call to get the CPU metrics
-	output, err := e.client.GetMetricData(context.TODO(), &cloudwatch.GetMetricDataInput{
-		StartTime: &start,
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: &queryStart,
 		EndTime:   &end,
 		MetricDataQueries: []types.MetricDataQuery{
 			{
 				Id:         aws.String(v1.CPU.String()),
 				Expression: aws.String(`SELECT AVG(CPUUtilization) FROM "AWS/EC2" GROUP BY InstanceId`),
-				Period:     aws.Int32(period),
+				Period:     aws.Int32(int32(detailedPeriod.Seconds())),
 			},
 		},
+	}
+
+	// the samples of an instance can be split across the pages
+	var labels []string
+	samples := make(map[string][]util.Sample)
+	for {
+		output, err := e.client.GetMetricData(ctx, input, withRegion)
+		util.RecordAPICall(provider, cloudWatchAPI, "GetMetricData", err)
+		if err != nil {
+			return nil, err
+		}
+
+		// the metrics returned by the query are billed
+		util.RecordAPIUnits(provider, cloudWatchAPI, "GetMetricData", len(output.MetricDataResults))
+
+		for _, metric := range output.MetricDataResults {
+			instanceID := aws.ToString(metric.Label)
+			if instanceID == "Other" {
+				return nil, errors.New("error bad query passed to GetMetricData - instanceID not found in label")
+			}
+
+			if _, ok := samples[instanceID]; !ok {
+				labels = append(labels, instanceID)
+			}
+			for i, v := range metric.Values {
+				samples[instanceID] = append(samples[instanceID], util.Sample{
+					Time:  metric.Timestamps[i],
+					Value: v,
+				})
+			}
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	// Collector
+	cpuMetrics := make([]v1.Metric, 0, len(labels))
+
+	for _, instanceID := range labels {
+		resolution := util.Resolution(samples[instanceID], basicPeriod)
+
+		usage, observed, ok := util.Align(samples[instanceID], resolution, window)
+		if !ok {
+			continue
+		}
+
+		cpuMetrics = append(cpuMetrics, v1.Metric{
+			Name:         v1.CPU.String(),
+			ResourceType: v1.CPU,
+			Unit:         v1.VCPU,
+			Usage:        usage,
+			UpdatedAt:    time.Now().UTC(),
+			Observed:     observed,
+			Labels: v1.Labels{
+				"instanceID":         instanceID,
+				util.ResolutionLabel: util.FormatResolution(resolution),
+			},
+		})
+	}
+
+	return cpuMetrics, nil
+}
+
+// ioRates is the read and write operations and bytes per second of a volume
+type ioRates struct {
+	ops, bytes float64
+}
+
+// The metrics of the I/O of the EBS volumes, the operations first
+var ebsIOMetrics = []string{"VolumeReadOps", "VolumeWriteOps", "VolumeReadBytes", "VolumeWriteBytes"}
+
+// ebsIO returns the I/O of the EBS volumes in the region at the granularity
+// of the storage: the rates averaged over the last collection are used
+// until the next one
+func (e *cloudWatchClient) ebsIO(ctx context.Context, region string, window v1.Window) (map[string]ioRates, error) {
+	w, due := e.cadence.Due(v1.Storage, region, window)
+
+	e.volumeIOMu.Lock()
+	defer e.volumeIOMu.Unlock()
+
+	if !due {
+		return e.volumeIO[region], nil
+	}
+
+	volumes, err := e.getEBSIO(ctx, region, w.Start.UTC(), w.End.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if e.volumeIO == nil {
+		e.volumeIO = make(map[string]map[string]ioRates)
+	}
+	e.volumeIO[region] = volumes
+
+	return volumes, nil
+}
+
+// getEBSIO returns the I/O of the EBS volumes in the region averaged over
+// the window, by volume ID
+func (e *cloudWatchClient) getEBSIO(ctx context.Context, region string, start, end time.Time) (map[string]ioRates, error) {
+	withRegion := func(o *cloudwatch.Options) {
+		o.Region = region
+		if endpoint := regionalEndpoint(e.endpoint, region); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
+	}
+
+	seconds := end.Sub(start).Seconds()
+	period := int32(seconds)
+	if float64(period) != seconds {
+		return nil, fmt.Errorf("error casting %+v to int32", seconds)
+	}
+
+	queries := make([]types.MetricDataQuery, 0, len(ebsIOMetrics))
+	for _, metric := range ebsIOMetrics {
+		queries = append(queries, types.MetricDataQuery{
+			// the IDs must start with a lowercase letter
+			Id:         aws.String(strings.ToLower(metric)),
+			Expression: aws.String(fmt.Sprintf(`SELECT SUM(%s) FROM "AWS/EBS" GROUP BY VolumeId`, metric)),
+			Period:     aws.Int32(period),
+		})
+	}
+
+	if err := util.WaitForAPI(ctx, provider, cloudWatchAPI); err != nil {
+		return nil, err
+	}
+
+	output, err := e.client.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+		StartTime:         &start,
+		EndTime:           &end,
+		MetricDataQueries: queries,
 	}, withRegion)
+	util.RecordAPICall(provider, cloudWatchAPI, "GetMetricData", err)
 	if err != nil {
 		return nil, err
 	}
 
-	// Collector
-	var cpuMetrics []v1.Metric
+	// the metrics returned by the query are billed
+	util.RecordAPIUnits(provider, cloudWatchAPI, "GetMetricData", len(output.MetricDataResults))
+
+	volumes := make(map[string]ioRates)
+	for _, result := range output.MetricDataResults {
+		volumeID := aws.ToString(result.Label)
 
-	// Loop through the result and build the intermediate awsMetric model
-	for _, metric := range output.MetricDataResults {
-		instanceID := aws.ToString(metric.Label)
-		if instanceID == "Other" {
-			return nil, errors.New("error bad query passed to GetMetricData - instanceID not found in label")
+		var sum float64
+		for _, v := range result.Values {
+			sum += v
 		}
 
-		if len(metric.Values) > 0 {
-			cpu := v1.NewMetric(v1.CPU.String())
-			cpu.Unit = v1.VCPU
-			cpu.Usage = metric.Values[0]
-			cpu.ResourceType = v1.CPU
-			cpu.Labels = v1.Labels{
-				"instanceID": instanceID,
-			}
-			cpuMetrics = append(cpuMetrics, *cpu)
+		v := volumes[volumeID]
+		if strings.HasSuffix(aws.ToString(result.Id), "ops") {
+			v.ops += sum / seconds
+		} else {
+			v.bytes += sum / seconds
 		}
+		volumes[volumeID] = v
 	}
 
-	return cpuMetrics, nil
+	return volumes, nil
 }
@@ -20,35 +20,39 @@ func TestGetMetricsData(t *testing.T) {
 
 	region := "test region"
 	interval := 5 * time.Minute
-	start := time.Date(2024, 01, 15, 20, 34, 58, 651387237, time.UTC)
+	start := time.Date(2024, 01, 15, 20, 35, 0, 0, time.UTC)
 	end := start.Add(interval)
 
+	// the query starts a basic period earlier
+	queryStart := start.Add(-basicPeriod)
+
 	t.Run("get passing metrics data", func(t *testing.T) {
 		stubber.Add(testtools.Stub{
 			OperationName: "GetMetricData",
 			Input: &cloudwatch.GetMetricDataInput{
-				StartTime: &start,
+				StartTime: &queryStart,
 				EndTime:   &end,
 				MetricDataQueries: []types.MetricDataQuery{
 					{
 						Id:         aws.String(v1.CPU.String()),
 						Expression: aws.String(`SELECT AVG(CPUUtilization) FROM "AWS/EC2" GROUP BY InstanceId`),
-						Period:     aws.Int32(300), // 5 minutes
+						Period:     aws.Int32(60), // the detailed monitoring
 					},
 				},
 			},
 			Output: &cloudwatch.GetMetricDataOutput{
 				MetricDataResults: []types.MetricDataResult{
 					{
-						Id:     aws.String("testID"),
-						Label:  aws.String("i-00123456789"),
-						Values: []float64{.0000123},
+						Id:         aws.String("testID"),
+						Label:      aws.String("i-00123456789"),
+						Values:     []float64{.0000123},
+						Timestamps: []time.Time{start},
 					},
 				},
 			},
 		})
 
-		res, err := client.getEC2CPU(region, start, end, interval)
+		res, err := client.getEC2CPU(context.TODO(), region, start, end)
 		testtools.ExitTest(stubber, t)
 
 		expRes := v1.Metric{
@@ -58,6 +62,7 @@ func TestGetMetricsData(t *testing.T) {
 			ResourceType: v1.CPU,
 			Labels: v1.Labels{
 				"instanceID": "i-00123456789",
+				"resolution": "5m",
 			},
 		}
 
@@ -74,17 +79,157 @@ func TestGetMetricsData(t *testing.T) {
 		assert.Equalf(t, expRes.Unit, res[0].Unit, "Result should be: %v, got: %v", expRes, res)
 		// emissions should not yet be calculated at this point
 		assert.Equal(t, res[0].Emissions, v1.ResourceEmissions{})
+		// observed over the whole window
+		assert.True(t, res[0].Observed.IsZero())
 		// check no error
 		assert.Nil(t, err)
 	})
 
+	t.Run("get the metrics of an instance launched during the window", func(t *testing.T) {
+		interval := 15 * time.Minute
+		end := start.Add(interval)
+
+		stubber.Add(testtools.Stub{
+			OperationName: "GetMetricData",
+			Input: &cloudwatch.GetMetricDataInput{
+				StartTime: &queryStart,
+				EndTime:   &end,
+				MetricDataQueries: []types.MetricDataQuery{
+					{
+						Id:         aws.String(v1.CPU.String()),
+						Expression: aws.String(`SELECT AVG(CPUUtilization) FROM "AWS/EC2" GROUP BY InstanceId`),
+						Period:     aws.Int32(60),
+					},
+				},
+			},
+			Output: &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []types.MetricDataResult{
+					{
+						Id:         aws.String("testID"),
+						Label:      aws.String("i-00123456789"),
+						Values:     []float64{30, 10},
+						Timestamps: []time.Time{start.Add(10 * time.Minute), start.Add(5 * time.Minute)},
+					},
+					{
+						Id:         aws.String("testID"),
+						Label:      aws.String("i-00123456790"),
+						Values:     []float64{10, 20, 30},
+						Timestamps: []time.Time{start.Add(10 * time.Minute), start.Add(5 * time.Minute), start},
+					},
+				},
+			},
+		})
+
+		res, err := client.getEC2CPU(context.TODO(), region, start, end)
+		testtools.ExitTest(stubber, t)
+
+		assert.Nil(t, err)
+		assert.Len(t, res, 2)
+
+		// observed for the last 2 periods
+		assert.Equal(t, 20.0, res[0].Usage)
+		assert.Equal(t, v1.Window{Start: start.Add(5 * time.Minute), End: end}, res[0].Observed)
+		assert.Equal(t, 10*time.Minute, res[0].Observed.Within(interval))
+
+		// and for the whole window
+		assert.Equal(t, 20.0, res[1].Usage)
+		assert.True(t, res[1].Observed.IsZero())
+	})
+
+	t.Run("average the samples over the window", func(t *testing.T) {
+		// the window isn't aligned with the basic periods
+		start := time.Date(2024, 01, 15, 20, 37, 30, 0, time.UTC)
+		end := start.Add(interval)
+		queryStart := start.Add(-basicPeriod)
+
+		stubber.Add(testtools.Stub{
+			OperationName: "GetMetricData",
+			Input: &cloudwatch.GetMetricDataInput{
+				StartTime: &queryStart,
+				EndTime:   &end,
+				MetricDataQueries: []types.MetricDataQuery{
+					{
+						Id:         aws.String(v1.CPU.String()),
+						Expression: aws.String(`SELECT AVG(CPUUtilization) FROM "AWS/EC2" GROUP BY InstanceId`),
+						Period:     aws.Int32(60),
+					},
+				},
+			},
+			Output: &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []types.MetricDataResult{
+					{
+						// the basic monitoring, half of the window in
+						// each sample
+						Id:         aws.String("testID"),
+						Label:      aws.String("i-basic"),
+						Values:     []float64{30, 10},
+						Timestamps: []time.Time{start.Add(2*time.Minute + 30*time.Second), start.Add(-2*time.Minute - 30*time.Second)},
+					},
+				},
+				NextToken: aws.String("next"),
+			},
+		})
+		stubber.Add(testtools.Stub{
+			OperationName: "GetMetricData",
+			Input: &cloudwatch.GetMetricDataInput{
+				StartTime: &queryStart,
+				EndTime:   &end,
+				MetricDataQueries: []types.MetricDataQuery{
+					{
+						Id:         aws.String(v1.CPU.String()),
+						Expression: aws.String(`SELECT AVG(CPUUtilization) FROM "AWS/EC2" GROUP BY InstanceId`),
+						Period:     aws.Int32(60),
+					},
+				},
+				NextToken: aws.String("next"),
+			},
+			Output: &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []types.MetricDataResult{
+					{
+						// the detailed monitoring, the sample before the
+						// window is left out
+						Id:    aws.String("testID"),
+						Label: aws.String("i-detailed"),
+						Values: []float64{
+							5, 5, 5, 5, 5, 100,
+						},
+						Timestamps: []time.Time{
+							start.Add(4 * time.Minute),
+							start.Add(3 * time.Minute),
+							start.Add(2 * time.Minute),
+							start.Add(time.Minute),
+							start,
+							start.Add(-time.Minute),
+						},
+					},
+				},
+			},
+		})
+
+		res, err := client.getEC2CPU(context.TODO(), region, start, end)
+		testtools.ExitTest(stubber, t)
+
+		assert.Nil(t, err)
+		assert.Len(t, res, 2)
+
+		assert.Equal(t, "i-basic", res[0].Labels["instanceID"])
+		assert.Equal(t, 20.0, res[0].Usage)
+		assert.Equal(t, "5m", res[0].Labels["resolution"])
+		assert.True(t, res[0].Observed.IsZero())
+
+		assert.Equal(t, "i-detailed", res[1].Labels["instanceID"])
+		assert.Equal(t, 5.0, res[1].Usage)
+		assert.Equal(t, "1m", res[1].Labels["resolution"])
+		assert.True(t, res[1].Observed.IsZero())
+	})
+
 	t.Run("error getting metrics", func(t *testing.T) {
 		stubber.Add(testtools.Stub{
 			OperationName: "GetMetricData",
 			Error:         &testtools.StubError{Err: errors.New("Testing the error is handled")},
 		})
 
-		res, err := client.getEC2CPU(region, start, end, interval)
+		res, err := client.getEC2CPU(context.TODO(), region, start, end)
 		testtools.ExitTest(stubber, t)
 
 		assert.Nil(t, res)
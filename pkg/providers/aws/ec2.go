@@ -5,18 +5,44 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/patrickmn/go-cache"
 	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/relabel"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
 )
 
+// ec2Describer is the part of the EC2 API listing the instances, their
+// volumes, the specs of their types, and the snapshots and the images
+//
+//counterfeiter:generate -o fake_ec2_test.go -fake-name fakeEC2 . ec2Describer
+type ec2Describer interface {
+	ec2.DescribeInstancesAPIClient
+	ec2.DescribeInstanceTypesAPIClient
+	ec2.DescribeVolumesAPIClient
+	ec2.DescribeSnapshotsAPIClient
+	ec2.DescribeImagesAPIClient
+}
+
 // Helper service to get EC2 data
 type ec2Client struct {
-	client *ec2.Client
+	// The EC2 API, faked by the tests
+	client ec2Describer
+
+	// The configured endpoint of the API, e.g. a VPC endpoint
+	endpoint string
+
+	// Whether the EBS volumes of the instances are listed
+	storage bool
+
+	// Whether the EBS snapshots and the AMIs of the account are listed
+	snapshots bool
 }
 
 // New instance
@@ -43,51 +69,277 @@ func (e *ec2Client) Refresh(ctx context.Context, ca *cache.Cache, region string)
 	// Override the region
 	withRegion := func(o *ec2.Options) {
 		o.Region = region
+		if endpoint := regionalEndpoint(e.endpoint, region); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
 	}
 
 	// First request
+	if err := util.WaitForAPI(ctx, provider, ec2API); err != nil {
+		return err
+	}
 	output, err := e.client.DescribeInstances(ctx, buildListPaginationRequest(nil), withRegion)
+	util.RecordAPICall(provider, ec2API, "DescribeInstances", err)
 	if err != nil || output == nil {
-		return fmt.Errorf("failed to retrieve ec2 instances from region: %s: %s", region, err)
+		return fmt.Errorf("failed to retrieve ec2 instances from region: %s: %w", region, err)
 	}
 
 	// Collect all the responses for all the pages
 	instances := []ec2.DescribeInstancesOutput{*output}
 
 	for output.NextToken != nil {
+		if err := util.WaitForAPI(ctx, provider, ec2API); err != nil {
+			return err
+		}
 		output, err = e.client.DescribeInstances(ctx, buildListPaginationRequest(output.NextToken), withRegion)
+		util.RecordAPICall(provider, ec2API, "DescribeInstances", err)
 		if err != nil || output == nil {
-			return fmt.Errorf("failed to retrieve ec2 instances %s", err)
+			return fmt.Errorf("failed to retrieve ec2 instances: %w", err)
 		}
 
 		instances = append(instances, *output)
 	}
 
-	for _, reservation := range output.Reservations {
-		for index := range reservation.Instances {
-			instance := reservation.Instances[index]
+	// the volumes are optional, the instances are cached without them
+	// when they can't be listed, e.g. without the permission
+	var volumes map[string]v1.Metrics
+	if e.storage {
+		volumes, err = e.volumes(ctx, withRegion)
+		if err != nil {
+			slog.Warn("failed to retrieve the ebs volumes, the storage emissions are skipped", "region", region, "error", err)
+		}
+	}
+
+	// the specs of the types are shared by all the accounts and the
+	// regions, the instances are cached without them when they can't be
+	// fetched
+	var kinds []string
+	for _, page := range instances {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				kinds = append(kinds, string(instance.InstanceType))
+			}
+		}
+	}
+	slices.Sort(kinds)
+	specs, err := util.Specs(ctx, provider, slices.Compact(kinds), e.instanceTypes(withRegion))
+	if err != nil {
+		slog.Warn("failed to retrieve the ec2 instance types", "region", region, "error", err)
+	}
 
-			id := aws.ToString(instance.InstanceId)
-			ca.Set(util.CacheKey(region, ec2Service, id),
-				&v1.Instance{
-					Name:     id,
-					Provider: provider,
-					Service:  ec2Service,
-					Region:   region,
-					Kind:     string(instance.InstanceType),
-					Labels: v1.Labels{
-						"Name":      getInstanceTag(instance.Tags, "Name"),
-						"Lifecycle": string(instance.InstanceLifecycle),
-						"VCPUCount": string(aws.ToInt32(instance.CpuOptions.CoreCount) * aws.ToInt32(instance.CpuOptions.ThreadsPerCore)),
+	for _, page := range instances {
+		for _, reservation := range page.Reservations {
+			for index := range reservation.Instances {
+				instance := reservation.Instances[index]
+				spec := specs[string(instance.InstanceType)]
+
+				// the CPU options are the ones of the instance, e.g.
+				// with the hyper-threading disabled, the spec the
+				// default ones of its type
+				vCPU := vCPUCount(instance.CpuOptions)
+				if vCPU == "" && spec.VCPU > 0 {
+					vCPU = strconv.FormatFloat(spec.VCPU, 'f', -1, 64)
+				}
+				arch := string(instance.Architecture)
+				if arch == "" {
+					arch = spec.Architecture
+				}
+
+				id := aws.ToString(instance.InstanceId)
+				ca.Set(util.CacheKey(region, ec2Service, id),
+					&v1.Instance{
+						Name:         id,
+						Provider:     provider,
+						Service:      ec2Service,
+						Region:       region,
+						Zone:         availabilityZone(instance.Placement),
+						Kind:         string(instance.InstanceType),
+						Architecture: arch,
+						Labels: tagLabels(instance.Tags, v1.Labels{
+							v1.NameLabel: getInstanceTag(instance.Tags, "Name"),
+							"Lifecycle":  string(instance.InstanceLifecycle),
+							"VCPUCount":  vCPU,
+							v1.SiteLabel: outpostID(instance.OutpostArn),
+						}),
+						Metrics: volumes[id],
 					},
-				},
-				cache.DefaultExpiration,
-			)
+					cache.DefaultExpiration,
+				)
+			}
 		}
 	}
 	return nil
 }
 
+// The most instance types described by a request
+const instanceTypesPerRequest = 100
+
+// instanceTypes returns the fetcher of the specs of the instance types of
+// the region, a request is made per 100 types
+func (e *ec2Client) instanceTypes(withRegion func(*ec2.Options)) util.SpecFetcher {
+	return func(ctx context.Context, kinds []string) (map[string]util.Spec, error) {
+		specs := make(map[string]util.Spec, len(kinds))
+
+		for start := 0; start < len(kinds); start += instanceTypesPerRequest {
+			input := &ec2.DescribeInstanceTypesInput{}
+			for _, kind := range kinds[start:min(start+instanceTypesPerRequest, len(kinds))] {
+				input.InstanceTypes = append(input.InstanceTypes, types.InstanceType(kind))
+			}
+
+			for {
+				if err := util.WaitForAPI(ctx, provider, ec2API); err != nil {
+					return nil, err
+				}
+				output, err := e.client.DescribeInstanceTypes(ctx, input, withRegion)
+				util.RecordAPICall(provider, ec2API, "DescribeInstanceTypes", err)
+				if err != nil {
+					return nil, err
+				}
+				if output == nil {
+					break
+				}
+
+				for i := range output.InstanceTypes {
+					info := &output.InstanceTypes[i]
+					specs[string(info.InstanceType)] = instanceTypeSpec(info)
+				}
+
+				if output.NextToken == nil {
+					break
+				}
+				input.NextToken = output.NextToken
+			}
+		}
+
+		return specs, nil
+	}
+}
+
+// instanceTypeSpec returns the spec of an instance type, its architecture is
+// the first one it supports
+func instanceTypeSpec(info *types.InstanceTypeInfo) util.Spec {
+	var spec util.Spec
+	if info.VCpuInfo != nil {
+		spec.VCPU = float64(aws.ToInt32(info.VCpuInfo.DefaultVCpus))
+	}
+	if info.MemoryInfo != nil {
+		spec.MemoryGB = float64(aws.ToInt64(info.MemoryInfo.SizeInMiB)) / 1024
+	}
+	if info.ProcessorInfo != nil && len(info.ProcessorInfo.SupportedArchitectures) > 0 {
+		spec.Architecture = string(info.ProcessorInfo.SupportedArchitectures[0])
+	}
+	return spec
+}
+
+// volumes returns the storage metrics of the EBS volumes attached to the
+// instances, by instance ID. The metrics are named after the volumes and
+// have their size, their I/O is collected with the other metrics
+func (e *ec2Client) volumes(ctx context.Context, withRegion func(*ec2.Options)) (map[string]v1.Metrics, error) {
+	volumes := make(map[string]v1.Metrics)
+
+	input := &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("attachment.status"),
+				Values: []string{"attached"},
+			},
+		},
+		MaxResults: aws.Int32(500),
+	}
+
+	for {
+		if err := util.WaitForAPI(ctx, provider, ec2API); err != nil {
+			return nil, err
+		}
+		output, err := e.client.DescribeVolumes(ctx, input, withRegion)
+		util.RecordAPICall(provider, ec2API, "DescribeVolumes", err)
+		if err != nil {
+			return nil, err
+		}
+		if output == nil {
+			return volumes, nil
+		}
+
+		for _, volume := range output.Volumes {
+			for _, attachment := range volume.Attachments {
+				instanceID := aws.ToString(attachment.InstanceId)
+				metrics := volumes[instanceID]
+				metrics.Upsert(&v1.Metric{
+					Name:         aws.ToString(volume.VolumeId),
+					ResourceType: v1.Storage,
+					UnitAmount:   float64(aws.ToInt32(volume.Size)),
+					Unit:         v1.GB,
+					Labels: v1.Labels{
+						v1.MediaLabel: volumeMedia(volume.VolumeType),
+						"volume_type": string(volume.VolumeType),
+					},
+				})
+				volumes[instanceID] = metrics
+			}
+		}
+
+		if output.NextToken == nil {
+			return volumes, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// volumeMedia returns the media of an EBS volume type, the throughput
+// optimized, cold and magnetic volumes are HDDs
+func volumeMedia(t types.VolumeType) string {
+	switch t {
+	case types.VolumeTypeSt1, types.VolumeTypeSc1, types.VolumeTypeStandard:
+		return v1.MediaHDD
+	default:
+		return v1.MediaSSD
+	}
+}
+
+// vCPUCount returns the amount of vCPUs of the CPU options, empty when
+// they're unknown
+func vCPUCount(o *types.CpuOptions) string {
+	if o == nil || o.CoreCount == nil {
+		return ""
+	}
+
+	threads := aws.ToInt32(o.ThreadsPerCore)
+	if threads == 0 {
+		threads = 1
+	}
+
+	return strconv.Itoa(int(aws.ToInt32(o.CoreCount) * threads))
+}
+
+// availabilityZone returns the availability zone of the placement, empty
+// when it's unknown
+func availabilityZone(p *types.Placement) string {
+	if p == nil {
+		return ""
+	}
+	return aws.ToString(p.AvailabilityZone)
+}
+
+// outpostID returns the ID of the Outpost of an ARN, e.g. op-0123 for
+// arn:aws:outposts:us-west-2:123456789012:outpost/op-0123, empty for the
+// instances not running on an Outpost
+func outpostID(arn *string) string {
+	_, id, ok := strings.Cut(aws.ToString(arn), ":outpost/")
+	if !ok {
+		return ""
+	}
+	return id
+}
+
+// tagLabels adds the tags to the labels, prefixed with v1.TagLabelPrefix so
+// that the grouping rules can match them
+func tagLabels(tags []types.Tag, labels v1.Labels) v1.Labels {
+	for _, tag := range tags {
+		labels[v1.TagLabelPrefix+relabel.LabelName(aws.ToString(tag.Key))] = aws.ToString(tag.Value)
+	}
+	return labels
+}
+
 func getInstanceTag(tags []types.Tag, key string) string {
 	for _, tag := range tags {
 		if aws.ToString(tag.Key) == key {
@@ -1,17 +1,157 @@
 package amazon
 
-// TODO write unit tests that don't make API calls
-//  func TestEc2InstanceListing(t *testing.T) {
-//	  ctx := context.Background()
-//	  // Pass an empty provider config so that it loads the default credentials
-//	  cfg, err := buildAWSConfig(ctx, &config.Account{}, nil)
-//	  assert.NotNil(t, cfg)
-//	  assert.Nil(t, err)
-//
-//	  // Init the ec2 client
-//	  ec2Client := NewEC2Client(&cfg)
-//	  assert.NotNil(t, ec2Client)
-//
-//	  err = ec2Client.Refresh(ctx, "eu-north-1")
-//	  assert.Nil(t, err)
-//  }
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/patrickmn/go-cache"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// ec2Instance returns an EC2 instance with 2 cores of 2 threads
+func ec2Instance(id string) types.Instance {
+	return types.Instance{
+		InstanceId:   aws.String(id),
+		InstanceType: types.InstanceTypeM5Xlarge,
+		Architecture: types.ArchitectureValuesX8664,
+		Placement:    &types.Placement{AvailabilityZone: aws.String("eu-north-1a")},
+		CpuOptions: &types.CpuOptions{
+			CoreCount:      aws.Int32(2),
+			ThreadsPerCore: aws.Int32(2),
+		},
+		Tags: []types.Tag{
+			{Key: aws.String("Name"), Value: aws.String("web-" + id)},
+			{Key: aws.String("cost-center"), Value: aws.String("cc-42")},
+		},
+	}
+}
+
+func TestEC2Refresh(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	fake := &fakeEC2{}
+	fake.DescribeInstancesReturnsOnCall(0, &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{ec2Instance("i-1")}}},
+		NextToken:    aws.String("page-2"),
+	}, nil)
+	fake.DescribeInstancesReturnsOnCall(1, &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{ec2Instance("i-2")}}},
+	}, nil)
+
+	c := &ec2Client{client: fake}
+	ca := cache.New(cache.NoExpiration, cache.NoExpiration)
+	assert.NoError(c.Refresh(ctx, ca, "eu-north-1"))
+
+	// the pages are requested in order
+	assert.Equal(2, fake.DescribeInstancesCallCount())
+	_, input, _ := fake.DescribeInstancesArgsForCall(1)
+	assert.Equal("page-2", aws.ToString(input.NextToken))
+
+	// the instances of every page are cached
+	for _, id := range []string{"i-1", "i-2"} {
+		cached, ok := ca.Get(util.CacheKey("eu-north-1", ec2Service, id))
+		assert.True(ok, id)
+
+		i := cached.(*v1.Instance)
+		assert.Equal(id, i.Name)
+		assert.Equal("m5.xlarge", i.Kind)
+		assert.Equal("eu-north-1", i.Region)
+		assert.Equal("eu-north-1a", i.Zone)
+		assert.Equal("x86_64", i.Architecture)
+		assert.Equal("web-"+id, i.Labels["Name"])
+		assert.Equal("4", i.Labels["VCPUCount"])
+		assert.Equal("web-"+id, i.Labels["tag_Name"])
+		assert.Equal("cc-42", i.Labels["tag_cost_center"])
+	}
+
+	fake.DescribeInstancesReturns(nil, errors.New("unauthorized"))
+	assert.ErrorContains(c.Refresh(ctx, ca, "eu-north-1"), "unauthorized")
+}
+
+func TestEC2RefreshInstanceTypes(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	// an instance without its CPU options nor its architecture
+	instance := types.Instance{
+		InstanceId:   aws.String("i-1"),
+		InstanceType: types.InstanceTypeM7gMedium,
+	}
+
+	fake := &fakeEC2{}
+	fake.DescribeInstancesReturns(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{instance}}},
+	}, nil)
+	fake.DescribeInstanceTypesReturns(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []types.InstanceTypeInfo{
+			{
+				InstanceType:  types.InstanceTypeM7gMedium,
+				VCpuInfo:      &types.VCpuInfo{DefaultVCpus: aws.Int32(1)},
+				MemoryInfo:    &types.MemoryInfo{SizeInMiB: aws.Int64(4096)},
+				ProcessorInfo: &types.ProcessorInfo{SupportedArchitectures: []types.ArchitectureType{types.ArchitectureTypeArm64}},
+			},
+		},
+	}, nil)
+
+	c := &ec2Client{client: fake}
+	ca := cache.New(cache.NoExpiration, cache.NoExpiration)
+	assert.NoError(c.Refresh(ctx, ca, "eu-north-1"))
+	assert.NoError(c.Refresh(ctx, ca, "eu-west-1"))
+
+	// the specs of the type are shared by the regions
+	assert.Equal(1, fake.DescribeInstanceTypesCallCount())
+	_, input, _ := fake.DescribeInstanceTypesArgsForCall(0)
+	assert.Equal([]types.InstanceType{types.InstanceTypeM7gMedium}, input.InstanceTypes)
+
+	for _, region := range []string{"eu-north-1", "eu-west-1"} {
+		cached, ok := ca.Get(util.CacheKey(region, ec2Service, "i-1"))
+		assert.True(ok, region)
+
+		i := cached.(*v1.Instance)
+		assert.Equal("arm64", i.Architecture)
+		assert.Equal("1", i.Labels["VCPUCount"])
+	}
+}
+
+func TestInstanceTypeSpec(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(util.Spec{}, instanceTypeSpec(&types.InstanceTypeInfo{}))
+	assert.Equal(util.Spec{VCPU: 4, MemoryGB: 16, Architecture: "x86_64"}, instanceTypeSpec(&types.InstanceTypeInfo{
+		VCpuInfo:      &types.VCpuInfo{DefaultVCpus: aws.Int32(4)},
+		MemoryInfo:    &types.MemoryInfo{SizeInMiB: aws.Int64(16384)},
+		ProcessorInfo: &types.ProcessorInfo{SupportedArchitectures: []types.ArchitectureType{types.ArchitectureTypeX8664, types.ArchitectureTypeI386}},
+	}))
+}
+
+func TestVCPUCount(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("", vCPUCount(nil))
+	assert.Equal("", vCPUCount(&types.CpuOptions{}))
+	assert.Equal("2", vCPUCount(&types.CpuOptions{CoreCount: aws.Int32(2)}))
+	assert.Equal("8", vCPUCount(&types.CpuOptions{CoreCount: aws.Int32(4), ThreadsPerCore: aws.Int32(2)}))
+}
+
+func TestOutpostID(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("", outpostID(nil))
+	assert.Equal("", outpostID(aws.String("")))
+	assert.Equal("op-0123", outpostID(aws.String("arn:aws:outposts:us-west-2:123456789012:outpost/op-0123")))
+}
+
+func TestRegionalEndpoint(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Nil(regionalEndpoint("", "eu-west-1"))
+	assert.Equal("https://vpce-0123.ec2.eu-west-1.vpce.amazonaws.com", aws.ToString(regionalEndpoint("https://vpce-0123.ec2.{region}.vpce.amazonaws.com", "eu-west-1")))
+	assert.Equal("https://ec2.internal", aws.ToString(regionalEndpoint("https://ec2.internal", "eu-west-1")))
+}
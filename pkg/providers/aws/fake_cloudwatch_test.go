@@ -0,0 +1,124 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package amazon
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+)
+
+type fakeCloudWatch struct {
+	GetMetricDataStub        func(context.Context, *cloudwatch.GetMetricDataInput, ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+	getMetricDataMutex       sync.RWMutex
+	getMetricDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 *cloudwatch.GetMetricDataInput
+		arg3 []func(*cloudwatch.Options)
+	}
+	getMetricDataReturns struct {
+		result1 *cloudwatch.GetMetricDataOutput
+		result2 error
+	}
+	getMetricDataReturnsOnCall map[int]struct {
+		result1 *cloudwatch.GetMetricDataOutput
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeCloudWatch) GetMetricData(arg1 context.Context, arg2 *cloudwatch.GetMetricDataInput, arg3 ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	var arg3Copy []func(*cloudwatch.Options)
+	if arg3 != nil {
+		arg3Copy = make([]func(*cloudwatch.Options), len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.getMetricDataMutex.Lock()
+	ret, specificReturn := fake.getMetricDataReturnsOnCall[len(fake.getMetricDataArgsForCall)]
+	fake.getMetricDataArgsForCall = append(fake.getMetricDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 *cloudwatch.GetMetricDataInput
+		arg3 []func(*cloudwatch.Options)
+	}{arg1, arg2, arg3Copy})
+	stub := fake.GetMetricDataStub
+	fakeReturns := fake.getMetricDataReturns
+	fake.recordInvocation("GetMetricData", []interface{}{arg1, arg2, arg3Copy})
+	fake.getMetricDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeCloudWatch) GetMetricDataCallCount() int {
+	fake.getMetricDataMutex.RLock()
+	defer fake.getMetricDataMutex.RUnlock()
+	return len(fake.getMetricDataArgsForCall)
+}
+
+func (fake *fakeCloudWatch) GetMetricDataCalls(stub func(context.Context, *cloudwatch.GetMetricDataInput, ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)) {
+	fake.getMetricDataMutex.Lock()
+	defer fake.getMetricDataMutex.Unlock()
+	fake.GetMetricDataStub = stub
+}
+
+func (fake *fakeCloudWatch) GetMetricDataArgsForCall(i int) (context.Context, *cloudwatch.GetMetricDataInput, []func(*cloudwatch.Options)) {
+	fake.getMetricDataMutex.RLock()
+	defer fake.getMetricDataMutex.RUnlock()
+	argsForCall := fake.getMetricDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeCloudWatch) GetMetricDataReturns(result1 *cloudwatch.GetMetricDataOutput, result2 error) {
+	fake.getMetricDataMutex.Lock()
+	defer fake.getMetricDataMutex.Unlock()
+	fake.GetMetricDataStub = nil
+	fake.getMetricDataReturns = struct {
+		result1 *cloudwatch.GetMetricDataOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeCloudWatch) GetMetricDataReturnsOnCall(i int, result1 *cloudwatch.GetMetricDataOutput, result2 error) {
+	fake.getMetricDataMutex.Lock()
+	defer fake.getMetricDataMutex.Unlock()
+	fake.GetMetricDataStub = nil
+	if fake.getMetricDataReturnsOnCall == nil {
+		fake.getMetricDataReturnsOnCall = make(map[int]struct {
+			result1 *cloudwatch.GetMetricDataOutput
+			result2 error
+		})
+	}
+	fake.getMetricDataReturnsOnCall[i] = struct {
+		result1 *cloudwatch.GetMetricDataOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeCloudWatch) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeCloudWatch) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cloudwatch.GetMetricDataAPIClient = new(fakeCloudWatch)
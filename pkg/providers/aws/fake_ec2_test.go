@@ -0,0 +1,468 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package amazon
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+type fakeEC2 struct {
+	DescribeImagesStub        func(context.Context, *ec2.DescribeImagesInput, ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error)
+	describeImagesMutex       sync.RWMutex
+	describeImagesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeImagesInput
+		arg3 []func(*ec2.Options)
+	}
+	describeImagesReturns struct {
+		result1 *ec2.DescribeImagesOutput
+		result2 error
+	}
+	describeImagesReturnsOnCall map[int]struct {
+		result1 *ec2.DescribeImagesOutput
+		result2 error
+	}
+	DescribeInstanceTypesStub        func(context.Context, *ec2.DescribeInstanceTypesInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error)
+	describeInstanceTypesMutex       sync.RWMutex
+	describeInstanceTypesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeInstanceTypesInput
+		arg3 []func(*ec2.Options)
+	}
+	describeInstanceTypesReturns struct {
+		result1 *ec2.DescribeInstanceTypesOutput
+		result2 error
+	}
+	describeInstanceTypesReturnsOnCall map[int]struct {
+		result1 *ec2.DescribeInstanceTypesOutput
+		result2 error
+	}
+	DescribeInstancesStub        func(context.Context, *ec2.DescribeInstancesInput, ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	describeInstancesMutex       sync.RWMutex
+	describeInstancesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeInstancesInput
+		arg3 []func(*ec2.Options)
+	}
+	describeInstancesReturns struct {
+		result1 *ec2.DescribeInstancesOutput
+		result2 error
+	}
+	describeInstancesReturnsOnCall map[int]struct {
+		result1 *ec2.DescribeInstancesOutput
+		result2 error
+	}
+	DescribeSnapshotsStub        func(context.Context, *ec2.DescribeSnapshotsInput, ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error)
+	describeSnapshotsMutex       sync.RWMutex
+	describeSnapshotsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeSnapshotsInput
+		arg3 []func(*ec2.Options)
+	}
+	describeSnapshotsReturns struct {
+		result1 *ec2.DescribeSnapshotsOutput
+		result2 error
+	}
+	describeSnapshotsReturnsOnCall map[int]struct {
+		result1 *ec2.DescribeSnapshotsOutput
+		result2 error
+	}
+	DescribeVolumesStub        func(context.Context, *ec2.DescribeVolumesInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error)
+	describeVolumesMutex       sync.RWMutex
+	describeVolumesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeVolumesInput
+		arg3 []func(*ec2.Options)
+	}
+	describeVolumesReturns struct {
+		result1 *ec2.DescribeVolumesOutput
+		result2 error
+	}
+	describeVolumesReturnsOnCall map[int]struct {
+		result1 *ec2.DescribeVolumesOutput
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeEC2) DescribeImages(arg1 context.Context, arg2 *ec2.DescribeImagesInput, arg3 ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+	var arg3Copy []func(*ec2.Options)
+	if arg3 != nil {
+		arg3Copy = make([]func(*ec2.Options), len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.describeImagesMutex.Lock()
+	ret, specificReturn := fake.describeImagesReturnsOnCall[len(fake.describeImagesArgsForCall)]
+	fake.describeImagesArgsForCall = append(fake.describeImagesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeImagesInput
+		arg3 []func(*ec2.Options)
+	}{arg1, arg2, arg3Copy})
+	stub := fake.DescribeImagesStub
+	fakeReturns := fake.describeImagesReturns
+	fake.recordInvocation("DescribeImages", []interface{}{arg1, arg2, arg3Copy})
+	fake.describeImagesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeEC2) DescribeImagesCallCount() int {
+	fake.describeImagesMutex.RLock()
+	defer fake.describeImagesMutex.RUnlock()
+	return len(fake.describeImagesArgsForCall)
+}
+
+func (fake *fakeEC2) DescribeImagesCalls(stub func(context.Context, *ec2.DescribeImagesInput, ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error)) {
+	fake.describeImagesMutex.Lock()
+	defer fake.describeImagesMutex.Unlock()
+	fake.DescribeImagesStub = stub
+}
+
+func (fake *fakeEC2) DescribeImagesArgsForCall(i int) (context.Context, *ec2.DescribeImagesInput, []func(*ec2.Options)) {
+	fake.describeImagesMutex.RLock()
+	defer fake.describeImagesMutex.RUnlock()
+	argsForCall := fake.describeImagesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeEC2) DescribeImagesReturns(result1 *ec2.DescribeImagesOutput, result2 error) {
+	fake.describeImagesMutex.Lock()
+	defer fake.describeImagesMutex.Unlock()
+	fake.DescribeImagesStub = nil
+	fake.describeImagesReturns = struct {
+		result1 *ec2.DescribeImagesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeImagesReturnsOnCall(i int, result1 *ec2.DescribeImagesOutput, result2 error) {
+	fake.describeImagesMutex.Lock()
+	defer fake.describeImagesMutex.Unlock()
+	fake.DescribeImagesStub = nil
+	if fake.describeImagesReturnsOnCall == nil {
+		fake.describeImagesReturnsOnCall = make(map[int]struct {
+			result1 *ec2.DescribeImagesOutput
+			result2 error
+		})
+	}
+	fake.describeImagesReturnsOnCall[i] = struct {
+		result1 *ec2.DescribeImagesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeInstanceTypes(arg1 context.Context, arg2 *ec2.DescribeInstanceTypesInput, arg3 ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	var arg3Copy []func(*ec2.Options)
+	if arg3 != nil {
+		arg3Copy = make([]func(*ec2.Options), len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.describeInstanceTypesMutex.Lock()
+	ret, specificReturn := fake.describeInstanceTypesReturnsOnCall[len(fake.describeInstanceTypesArgsForCall)]
+	fake.describeInstanceTypesArgsForCall = append(fake.describeInstanceTypesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeInstanceTypesInput
+		arg3 []func(*ec2.Options)
+	}{arg1, arg2, arg3Copy})
+	stub := fake.DescribeInstanceTypesStub
+	fakeReturns := fake.describeInstanceTypesReturns
+	fake.recordInvocation("DescribeInstanceTypes", []interface{}{arg1, arg2, arg3Copy})
+	fake.describeInstanceTypesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeEC2) DescribeInstanceTypesCallCount() int {
+	fake.describeInstanceTypesMutex.RLock()
+	defer fake.describeInstanceTypesMutex.RUnlock()
+	return len(fake.describeInstanceTypesArgsForCall)
+}
+
+func (fake *fakeEC2) DescribeInstanceTypesCalls(stub func(context.Context, *ec2.DescribeInstanceTypesInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error)) {
+	fake.describeInstanceTypesMutex.Lock()
+	defer fake.describeInstanceTypesMutex.Unlock()
+	fake.DescribeInstanceTypesStub = stub
+}
+
+func (fake *fakeEC2) DescribeInstanceTypesArgsForCall(i int) (context.Context, *ec2.DescribeInstanceTypesInput, []func(*ec2.Options)) {
+	fake.describeInstanceTypesMutex.RLock()
+	defer fake.describeInstanceTypesMutex.RUnlock()
+	argsForCall := fake.describeInstanceTypesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeEC2) DescribeInstanceTypesReturns(result1 *ec2.DescribeInstanceTypesOutput, result2 error) {
+	fake.describeInstanceTypesMutex.Lock()
+	defer fake.describeInstanceTypesMutex.Unlock()
+	fake.DescribeInstanceTypesStub = nil
+	fake.describeInstanceTypesReturns = struct {
+		result1 *ec2.DescribeInstanceTypesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeInstanceTypesReturnsOnCall(i int, result1 *ec2.DescribeInstanceTypesOutput, result2 error) {
+	fake.describeInstanceTypesMutex.Lock()
+	defer fake.describeInstanceTypesMutex.Unlock()
+	fake.DescribeInstanceTypesStub = nil
+	if fake.describeInstanceTypesReturnsOnCall == nil {
+		fake.describeInstanceTypesReturnsOnCall = make(map[int]struct {
+			result1 *ec2.DescribeInstanceTypesOutput
+			result2 error
+		})
+	}
+	fake.describeInstanceTypesReturnsOnCall[i] = struct {
+		result1 *ec2.DescribeInstanceTypesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeInstances(arg1 context.Context, arg2 *ec2.DescribeInstancesInput, arg3 ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	var arg3Copy []func(*ec2.Options)
+	if arg3 != nil {
+		arg3Copy = make([]func(*ec2.Options), len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.describeInstancesMutex.Lock()
+	ret, specificReturn := fake.describeInstancesReturnsOnCall[len(fake.describeInstancesArgsForCall)]
+	fake.describeInstancesArgsForCall = append(fake.describeInstancesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeInstancesInput
+		arg3 []func(*ec2.Options)
+	}{arg1, arg2, arg3Copy})
+	stub := fake.DescribeInstancesStub
+	fakeReturns := fake.describeInstancesReturns
+	fake.recordInvocation("DescribeInstances", []interface{}{arg1, arg2, arg3Copy})
+	fake.describeInstancesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeEC2) DescribeInstancesCallCount() int {
+	fake.describeInstancesMutex.RLock()
+	defer fake.describeInstancesMutex.RUnlock()
+	return len(fake.describeInstancesArgsForCall)
+}
+
+func (fake *fakeEC2) DescribeInstancesCalls(stub func(context.Context, *ec2.DescribeInstancesInput, ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)) {
+	fake.describeInstancesMutex.Lock()
+	defer fake.describeInstancesMutex.Unlock()
+	fake.DescribeInstancesStub = stub
+}
+
+func (fake *fakeEC2) DescribeInstancesArgsForCall(i int) (context.Context, *ec2.DescribeInstancesInput, []func(*ec2.Options)) {
+	fake.describeInstancesMutex.RLock()
+	defer fake.describeInstancesMutex.RUnlock()
+	argsForCall := fake.describeInstancesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeEC2) DescribeInstancesReturns(result1 *ec2.DescribeInstancesOutput, result2 error) {
+	fake.describeInstancesMutex.Lock()
+	defer fake.describeInstancesMutex.Unlock()
+	fake.DescribeInstancesStub = nil
+	fake.describeInstancesReturns = struct {
+		result1 *ec2.DescribeInstancesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeInstancesReturnsOnCall(i int, result1 *ec2.DescribeInstancesOutput, result2 error) {
+	fake.describeInstancesMutex.Lock()
+	defer fake.describeInstancesMutex.Unlock()
+	fake.DescribeInstancesStub = nil
+	if fake.describeInstancesReturnsOnCall == nil {
+		fake.describeInstancesReturnsOnCall = make(map[int]struct {
+			result1 *ec2.DescribeInstancesOutput
+			result2 error
+		})
+	}
+	fake.describeInstancesReturnsOnCall[i] = struct {
+		result1 *ec2.DescribeInstancesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeSnapshots(arg1 context.Context, arg2 *ec2.DescribeSnapshotsInput, arg3 ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	var arg3Copy []func(*ec2.Options)
+	if arg3 != nil {
+		arg3Copy = make([]func(*ec2.Options), len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.describeSnapshotsMutex.Lock()
+	ret, specificReturn := fake.describeSnapshotsReturnsOnCall[len(fake.describeSnapshotsArgsForCall)]
+	fake.describeSnapshotsArgsForCall = append(fake.describeSnapshotsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeSnapshotsInput
+		arg3 []func(*ec2.Options)
+	}{arg1, arg2, arg3Copy})
+	stub := fake.DescribeSnapshotsStub
+	fakeReturns := fake.describeSnapshotsReturns
+	fake.recordInvocation("DescribeSnapshots", []interface{}{arg1, arg2, arg3Copy})
+	fake.describeSnapshotsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeEC2) DescribeSnapshotsCallCount() int {
+	fake.describeSnapshotsMutex.RLock()
+	defer fake.describeSnapshotsMutex.RUnlock()
+	return len(fake.describeSnapshotsArgsForCall)
+}
+
+func (fake *fakeEC2) DescribeSnapshotsCalls(stub func(context.Context, *ec2.DescribeSnapshotsInput, ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error)) {
+	fake.describeSnapshotsMutex.Lock()
+	defer fake.describeSnapshotsMutex.Unlock()
+	fake.DescribeSnapshotsStub = stub
+}
+
+func (fake *fakeEC2) DescribeSnapshotsArgsForCall(i int) (context.Context, *ec2.DescribeSnapshotsInput, []func(*ec2.Options)) {
+	fake.describeSnapshotsMutex.RLock()
+	defer fake.describeSnapshotsMutex.RUnlock()
+	argsForCall := fake.describeSnapshotsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeEC2) DescribeSnapshotsReturns(result1 *ec2.DescribeSnapshotsOutput, result2 error) {
+	fake.describeSnapshotsMutex.Lock()
+	defer fake.describeSnapshotsMutex.Unlock()
+	fake.DescribeSnapshotsStub = nil
+	fake.describeSnapshotsReturns = struct {
+		result1 *ec2.DescribeSnapshotsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeSnapshotsReturnsOnCall(i int, result1 *ec2.DescribeSnapshotsOutput, result2 error) {
+	fake.describeSnapshotsMutex.Lock()
+	defer fake.describeSnapshotsMutex.Unlock()
+	fake.DescribeSnapshotsStub = nil
+	if fake.describeSnapshotsReturnsOnCall == nil {
+		fake.describeSnapshotsReturnsOnCall = make(map[int]struct {
+			result1 *ec2.DescribeSnapshotsOutput
+			result2 error
+		})
+	}
+	fake.describeSnapshotsReturnsOnCall[i] = struct {
+		result1 *ec2.DescribeSnapshotsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeVolumes(arg1 context.Context, arg2 *ec2.DescribeVolumesInput, arg3 ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	var arg3Copy []func(*ec2.Options)
+	if arg3 != nil {
+		arg3Copy = make([]func(*ec2.Options), len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.describeVolumesMutex.Lock()
+	ret, specificReturn := fake.describeVolumesReturnsOnCall[len(fake.describeVolumesArgsForCall)]
+	fake.describeVolumesArgsForCall = append(fake.describeVolumesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *ec2.DescribeVolumesInput
+		arg3 []func(*ec2.Options)
+	}{arg1, arg2, arg3Copy})
+	stub := fake.DescribeVolumesStub
+	fakeReturns := fake.describeVolumesReturns
+	fake.recordInvocation("DescribeVolumes", []interface{}{arg1, arg2, arg3Copy})
+	fake.describeVolumesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeEC2) DescribeVolumesCallCount() int {
+	fake.describeVolumesMutex.RLock()
+	defer fake.describeVolumesMutex.RUnlock()
+	return len(fake.describeVolumesArgsForCall)
+}
+
+func (fake *fakeEC2) DescribeVolumesCalls(stub func(context.Context, *ec2.DescribeVolumesInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error)) {
+	fake.describeVolumesMutex.Lock()
+	defer fake.describeVolumesMutex.Unlock()
+	fake.DescribeVolumesStub = stub
+}
+
+func (fake *fakeEC2) DescribeVolumesArgsForCall(i int) (context.Context, *ec2.DescribeVolumesInput, []func(*ec2.Options)) {
+	fake.describeVolumesMutex.RLock()
+	defer fake.describeVolumesMutex.RUnlock()
+	argsForCall := fake.describeVolumesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeEC2) DescribeVolumesReturns(result1 *ec2.DescribeVolumesOutput, result2 error) {
+	fake.describeVolumesMutex.Lock()
+	defer fake.describeVolumesMutex.Unlock()
+	fake.DescribeVolumesStub = nil
+	fake.describeVolumesReturns = struct {
+		result1 *ec2.DescribeVolumesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) DescribeVolumesReturnsOnCall(i int, result1 *ec2.DescribeVolumesOutput, result2 error) {
+	fake.describeVolumesMutex.Lock()
+	defer fake.describeVolumesMutex.Unlock()
+	fake.DescribeVolumesStub = nil
+	if fake.describeVolumesReturnsOnCall == nil {
+		fake.describeVolumesReturnsOnCall = make(map[int]struct {
+			result1 *ec2.DescribeVolumesOutput
+			result2 error
+		})
+	}
+	fake.describeVolumesReturnsOnCall[i] = struct {
+		result1 *ec2.DescribeVolumesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeEC2) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeEC2) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ ec2Describer = new(fakeEC2)
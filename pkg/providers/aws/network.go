@@ -0,0 +1,163 @@
+package amazon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The services of the network resources
+const (
+	cloudFrontService = "cloudfront"
+	elbService        = "elb"
+)
+
+// The CloudFront metrics are only published in us-east-1, the distributions
+// are accounted for in this region
+const cloudFrontRegion = "us-east-1"
+
+// networkQuery is a CloudWatch Metrics Insights query of the bytes
+// transferred by the resources of a namespace
+type networkQuery struct {
+	// The ID of the query, it must start with a lowercase letter
+	id        string
+	service   string
+	namespace string
+	metric    string
+	dimension string
+}
+
+// The bytes processed by the application and the network load balancers of
+// a region
+var elbQueries = []networkQuery{
+	{id: "alb", service: elbService, namespace: "AWS/ApplicationELB", metric: "ProcessedBytes", dimension: "LoadBalancer"},
+	{id: "nlb", service: elbService, namespace: "AWS/NetworkELB", metric: "ProcessedBytes", dimension: "LoadBalancer"},
+}
+
+// The bytes downloaded from the CloudFront distributions of the account
+var cloudFrontQueries = []networkQuery{
+	{id: "cloudfront", service: cloudFrontService, namespace: "AWS/CloudFront", metric: "BytesDownloaded", dimension: "DistributionId"},
+}
+
+// GetNetworkMetrics returns the load balancers of the region as instances
+// with the GB they transferred since their last collection, none when
+// they're not due at the granularity of the network
+func (e *cloudWatchClient) GetNetworkMetrics(ctx context.Context, region string, window v1.Window) ([]v1.Instance, error) {
+	w, due := e.cadence.Due(v1.Network, region, window)
+	if !due {
+		return nil, nil
+	}
+	return e.getNetwork(ctx, region, elbQueries, w)
+}
+
+// GetCloudFrontMetrics returns the CloudFront distributions of the account
+// as instances with the GB they transferred since their last collection,
+// none when they're not due at the granularity of the network
+func (e *cloudWatchClient) GetCloudFrontMetrics(ctx context.Context, window v1.Window) ([]v1.Instance, error) {
+	w, due := e.cadence.Due(v1.Network, cloudFrontService, window)
+	if !due {
+		return nil, nil
+	}
+	return e.getNetwork(ctx, cloudFrontRegion, cloudFrontQueries, w)
+}
+
+// getNetwork runs the queries in the region and returns a network instance
+// per resource of their results
+func (e *cloudWatchClient) getNetwork(ctx context.Context, region string, queries []networkQuery, window v1.Window) ([]v1.Instance, error) {
+	withRegion := func(o *cloudwatch.Options) {
+		o.Region = region
+		if endpoint := regionalEndpoint(e.endpoint, region); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
+	}
+
+	start := window.Start.UTC()
+	end := window.End.UTC()
+
+	seconds := end.Sub(start).Seconds()
+	period := int32(seconds)
+	if float64(period) != seconds {
+		return nil, fmt.Errorf("error casting %+v to int32", seconds)
+	}
+
+	byID := make(map[string]networkQuery, len(queries))
+	input := make([]types.MetricDataQuery, 0, len(queries))
+	for _, q := range queries {
+		byID[q.id] = q
+		input = append(input, types.MetricDataQuery{
+			Id:         aws.String(q.id),
+			Expression: aws.String(fmt.Sprintf(`SELECT SUM(%s) FROM "%s" GROUP BY %s`, q.metric, q.namespace, q.dimension)),
+			Period:     aws.Int32(period),
+		})
+	}
+
+	if err := util.WaitForAPI(ctx, provider, cloudWatchAPI); err != nil {
+		return nil, err
+	}
+
+	output, err := e.client.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+		StartTime:         &start,
+		EndTime:           &end,
+		MetricDataQueries: input,
+	}, withRegion)
+	util.RecordAPICall(provider, cloudWatchAPI, "GetMetricData", err)
+	if err != nil {
+		return nil, err
+	}
+
+	// the metrics returned by the query are billed
+	util.RecordAPIUnits(provider, cloudWatchAPI, "GetMetricData", len(output.MetricDataResults))
+
+	instances := make([]v1.Instance, 0, len(output.MetricDataResults))
+	for i := range output.MetricDataResults {
+		result := &output.MetricDataResults[i]
+		q, ok := byID[aws.ToString(result.Id)]
+		if !ok || len(result.Values) == 0 {
+			continue
+		}
+
+		var bytes float64
+		for _, v := range result.Values {
+			bytes += v
+		}
+
+		instances = append(instances, networkInstance(q.service, aws.ToString(result.Label), region, bytes, window))
+	}
+
+	return instances, nil
+}
+
+// networkInstance returns the instance of a network resource with the GB it
+// transferred over the window, which are a total rather than a rate so the
+// partial windows aren't prorated. The window is the one observed when it's
+// longer than the scraping interval, at the granularity of the network. The
+// load balancers are named after their dimension, e.g.
+// app/web/50dc6c495c0c9188, and labeled with their name
+func networkInstance(service, id, region string, bytes float64, window v1.Window) v1.Instance {
+	name := id
+	if parts := strings.Split(id, "/"); len(parts) == 3 {
+		name = parts[1]
+	}
+
+	i := v1.NewInstance(id, provider)
+	i.Service = service
+	i.Region = region
+	i.Labels.Add(v1.NameLabel, name)
+	i.Metrics.Upsert(&v1.Metric{
+		Name:         v1.Network.String(),
+		ResourceType: v1.Network,
+		UnitAmount:   bytes / 1e9,
+		Unit:         v1.GB,
+		UpdatedAt:    window.End.UTC(),
+		Observed:     window,
+		Labels:       v1.Labels{},
+	})
+
+	return *i
+}
@@ -1,6 +1,49 @@
 package amazon
 
-import v1 "github.com/re-cinq/aether/pkg/types/v1"
+// The fakes of the AWS APIs used by the tests
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.13.0 -generate
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
 
 const provider = v1.AWS
 const ec2Service = "AWS/EC2"
+
+// The EKS control plane runs at least two API server and three etcd
+// instances across three availability zones
+var eksControlPlane = util.ControlPlane{
+	Service:  "eks",
+	Kind:     "m5.large",
+	VCPU:     2,
+	Replicas: 5,
+	Usage:    10,
+}
+
+// API families, used for rate limiting and to configure their endpoints
+const (
+	ec2API              = "ec2"
+	cloudWatchAPI       = "cloudwatch"
+	computeOptimizerAPI = "computeoptimizer"
+)
+
+func init() {
+	// The default quotas of the requests per minute: the refill rate of the
+	// EC2 describe actions and the GetMetricData transactions per second
+	util.SetDefaultQuota(provider, ec2API, 20*60)
+	util.SetDefaultQuota(provider, cloudWatchAPI, 50*60)
+}
+
+// regionalEndpoint returns the endpoint of an API in the region, the
+// {region} of the configured endpoint is replaced by it. It's nil when no
+// endpoint is configured, the default one of the region is used
+func regionalEndpoint(endpoint, region string) *string {
+	if endpoint == "" {
+		return nil
+	}
+	return aws.String(strings.ReplaceAll(endpoint, "{region}", region))
+}
@@ -0,0 +1,204 @@
+package amazon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/rightsizing"
+)
+
+// The findings of Compute Optimizer the instances are recommended another
+// type for
+var computeOptimizerFindings = map[string]string{
+	"Overprovisioned":  rightsizing.FindingOverprovisioned,
+	"Underprovisioned": rightsizing.FindingUnderprovisioned,
+}
+
+// Recommender pulls the recommendations of Compute Optimizer for the EC2
+// instances of an account, which must be opted in
+type Recommender struct {
+	account  string
+	regions  []string
+	endpoint string
+
+	cfg    *aws.Config
+	client aws.HTTPClient
+	signer *v4.Signer
+}
+
+// NewRecommender returns the recommender of the regions of the account
+func NewRecommender(ctx context.Context, account *config.Account) (*Recommender, error) {
+	cfg, err := buildAWSConfig(ctx, account, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recommender{
+		account:  account.ID(),
+		regions:  account.Regions,
+		endpoint: account.Endpoints[computeOptimizerAPI],
+		cfg:      cfg,
+		client:   cfg.HTTPClient,
+		signer:   v4.NewSigner(),
+	}, nil
+}
+
+// computeOptimizerResponse is the part of the response of
+// GetEC2InstanceRecommendations read
+type computeOptimizerResponse struct {
+	NextToken               string `json:"nextToken"`
+	InstanceRecommendations []struct {
+		InstanceArn         string `json:"instanceArn"`
+		CurrentInstanceType string `json:"currentInstanceType"`
+		Finding             string `json:"finding"`
+		UtilizationMetrics  []struct {
+			Name      string  `json:"name"`
+			Statistic string  `json:"statistic"`
+			Value     float64 `json:"value"`
+		} `json:"utilizationMetrics"`
+		RecommendationOptions []struct {
+			InstanceType       string `json:"instanceType"`
+			Rank               int    `json:"rank"`
+			SavingsOpportunity struct {
+				EstimatedMonthlySavings struct {
+					Currency string  `json:"currency"`
+					Value    float64 `json:"value"`
+				} `json:"estimatedMonthlySavings"`
+			} `json:"savingsOpportunity"`
+		} `json:"recommendationOptions"`
+	} `json:"instanceRecommendations"`
+}
+
+// Recommendations returns the best ranked option of the over and the under
+// provisioned instances of every region
+func (r *Recommender) Recommendations(ctx context.Context) ([]rightsizing.Recommendation, error) {
+	var out []rightsizing.Recommendation
+	for _, region := range r.regions {
+		recommendations, err := r.regionRecommendations(ctx, region)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", r.account, region, err)
+		}
+		out = append(out, recommendations...)
+	}
+	return out, nil
+}
+
+func (r *Recommender) regionRecommendations(ctx context.Context, region string) ([]rightsizing.Recommendation, error) {
+	var out []rightsizing.Recommendation
+
+	var token string
+	for {
+		var resp computeOptimizerResponse
+		input := struct {
+			NextToken string `json:"nextToken,omitempty"`
+		}{token}
+		if err := r.call(ctx, region, "GetEC2InstanceRecommendations", input, &resp); err != nil {
+			return nil, err
+		}
+
+		for i := range resp.InstanceRecommendations {
+			rec := &resp.InstanceRecommendations[i]
+
+			finding, ok := computeOptimizerFindings[rec.Finding]
+			if !ok || len(rec.RecommendationOptions) == 0 {
+				continue
+			}
+
+			best := 0
+			for j := range rec.RecommendationOptions {
+				if rec.RecommendationOptions[j].Rank < rec.RecommendationOptions[best].Rank {
+					best = j
+				}
+			}
+			option := &rec.RecommendationOptions[best]
+			if option.InstanceType == rec.CurrentInstanceType {
+				continue
+			}
+
+			// the maximum CPU utilization is the only one reported
+			var utilization float64
+			for _, m := range rec.UtilizationMetrics {
+				if m.Name == "CPU" {
+					utilization = m.Value
+				}
+			}
+
+			// arn:aws:ec2:eu-west-1:123456789012:instance/i-0123456789abcdef0
+			id := rec.InstanceArn[strings.LastIndex(rec.InstanceArn, "/")+1:]
+
+			out = append(out, rightsizing.Recommendation{
+				Provider:    provider,
+				Account:     r.account,
+				Name:        id,
+				Region:      region,
+				Kind:        rec.CurrentInstanceType,
+				Recommended: option.InstanceType,
+				Finding:     finding,
+				Utilization: utilization,
+				CostSavings: option.SavingsOpportunity.EstimatedMonthlySavings.Value,
+				Currency:    option.SavingsOpportunity.EstimatedMonthlySavings.Currency,
+			})
+		}
+
+		if resp.NextToken == "" {
+			return out, nil
+		}
+		token = resp.NextToken
+	}
+}
+
+// call sends a signed request of the JSON protocol of Compute Optimizer
+func (r *Recommender) call(ctx context.Context, region, operation string, input, output any) (err error) {
+	defer func() { util.RecordAPICall(provider, computeOptimizerAPI, operation, err) }()
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	endpoint := "https://compute-optimizer." + region + ".amazonaws.com/"
+	if e := regionalEndpoint(r.endpoint, region); e != nil {
+		endpoint = *e
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "ComputeOptimizerService."+operation)
+
+	creds, err := r.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(body)
+	if err := r.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "compute-optimizer", region, time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s: %s", operation, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(output)
+}
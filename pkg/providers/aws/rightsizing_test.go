@@ -0,0 +1,113 @@
+package amazon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/re-cinq/aether/pkg/rightsizing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendations(t *testing.T) {
+	assert := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("ComputeOptimizerService.GetEC2InstanceRecommendations", r.Header.Get("X-Amz-Target"))
+		assert.True(strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(r.Header.Get("Authorization"), "/eu-west-1/compute-optimizer/aws4_request")
+
+		var input struct {
+			NextToken string `json:"nextToken"`
+		}
+		assert.NoError(json.NewDecoder(r.Body).Decode(&input))
+
+		if input.NextToken == "" {
+			_, _ = w.Write([]byte(`{"nextToken": "page2", "instanceRecommendations": [
+				{
+					"instanceArn": "arn:aws:ec2:eu-west-1:123456789012:instance/i-0123456789abcdef0",
+					"currentInstanceType": "m5.xlarge",
+					"finding": "Overprovisioned",
+					"utilizationMetrics": [{"name": "CPU", "statistic": "MAXIMUM", "value": 12.5}],
+					"recommendationOptions": [
+						{"instanceType": "t3.large", "rank": 2, "savingsOpportunity": {"estimatedMonthlySavings": {"currency": "USD", "value": 80}}},
+						{"instanceType": "m5.large", "rank": 1, "savingsOpportunity": {"estimatedMonthlySavings": {"currency": "USD", "value": 70.08}}}
+					]
+				},
+				{
+					"instanceArn": "arn:aws:ec2:eu-west-1:123456789012:instance/i-1",
+					"currentInstanceType": "m5.large",
+					"finding": "Optimized",
+					"recommendationOptions": [{"instanceType": "m5.large", "rank": 1}]
+				}
+			]}`))
+			return
+		}
+
+		assert.Equal("page2", input.NextToken)
+		_, _ = w.Write([]byte(`{"instanceRecommendations": [
+			{
+				"instanceArn": "arn:aws:ec2:eu-west-1:123456789012:instance/i-2",
+				"currentInstanceType": "c5.large",
+				"finding": "Underprovisioned",
+				"recommendationOptions": [{"instanceType": "c5.xlarge", "rank": 1, "savingsOpportunity": {"estimatedMonthlySavings": {"currency": "USD", "value": 0}}}]
+			}
+		]}`))
+	}))
+	defer server.Close()
+
+	r := &Recommender{
+		account:  "production",
+		regions:  []string{"eu-west-1"},
+		endpoint: server.URL,
+		cfg: &aws.Config{
+			Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+			}),
+		},
+		client: server.Client(),
+		signer: v4.NewSigner(),
+	}
+
+	recommendations, err := r.Recommendations(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]rightsizing.Recommendation{
+		{
+			Provider:    provider,
+			Account:     "production",
+			Name:        "i-0123456789abcdef0",
+			Region:      "eu-west-1",
+			Kind:        "m5.xlarge",
+			Recommended: "m5.large",
+			Finding:     rightsizing.FindingOverprovisioned,
+			Utilization: 12.5,
+			CostSavings: 70.08,
+			Currency:    "USD",
+		},
+		{
+			Provider:    provider,
+			Account:     "production",
+			Name:        "i-2",
+			Region:      "eu-west-1",
+			Kind:        "c5.large",
+			Recommended: "c5.xlarge",
+			Finding:     rightsizing.FindingUnderprovisioned,
+			Currency:    "USD",
+		},
+	}, recommendations)
+
+	r.regions = []string{"us-east-1"}
+	r.endpoint = server.URL + "/{region}"
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/us-east-1", r.URL.Path)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type": "OptInRequiredException", "message": "The account is not opted in"}`))
+	})
+	_, err = r.Recommendations(context.TODO())
+	assert.ErrorContains(err, "OptInRequiredException")
+}
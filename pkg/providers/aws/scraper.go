@@ -2,22 +2,26 @@ package amazon
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
-	"time"
+	"sync/atomic"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/re-cinq/aether/pkg/bus"
 	"github.com/re-cinq/aether/pkg/config"
 	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/providers/util"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
 )
 
 type Scraper struct {
-	// Ticker
-	ticker *time.Ticker
-	Done   chan bool
-
 	Client *Client
 
+	// The account identifier
+	account string
+
 	// Regions to scrape
 	regions []string
 
@@ -26,107 +30,161 @@ type Scraper struct {
 	logger *slog.Logger
 }
 
-func SetupScrapers(ctx context.Context, b *bus.Bus) []v1.Scraper {
-	cfg, exists := config.AppConfig().Providers[provider]
+// NewScraper returns a scraper for the account and builds
+// its initial cache of instances
+func NewScraper(ctx context.Context, b *bus.Bus, account *config.Account) (v1.Scraper, error) {
 	logger := log.FromContext(ctx)
 
-	// If the provider is not configured - skip its initialization
-	if !exists {
-		return nil
+	c, err := New(ctx, account, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	var scrapers []v1.Scraper
+	// Get the list of regions
+	regions := account.Regions
 
-	for index := range cfg.Accounts {
-		account := cfg.Accounts[index]
+	// Build the initial cache of instances
+	err = util.ForEach(ctx, regions, func(ctx context.Context, region string) error {
+		return c.ec2Client.Refresh(ctx, c.cache, region)
+	})
+	if err != nil {
+		logger.Error("error refreshing cache", "error", err)
+	}
 
-		ticker := time.NewTicker(config.AppConfig().ProvidersConfig.Interval)
+	return &Scraper{
+		account: account.ID(),
+		regions: regions,
+		Bus:     b,
+		Client:  c,
+		logger:  logger,
+	}, nil
+}
 
-		c, err := New(ctx, &account, nil)
-		if err != nil {
-			return nil
-		}
+// Provider returns the provider the scraper is collecting data from
+func (s *Scraper) Provider() v1.Provider {
+	return provider
+}
+
+// Account returns the identifier of the account being scraped
+func (s *Scraper) Account() string {
+	return s.account
+}
 
-		// Get the list of regions
-		regions := account.Regions
+// Scrape refreshes the instances and collects their metrics for every region
+// The regions are scraped concurrently and a failing region does not stop the
+// scraping of the remaining ones
+func (s *Scraper) Scrape(ctx context.Context, window v1.Window) (int, error) {
+	if len(s.regions) == 0 {
+		return 0, errors.New("no AWS regions defined in the config")
+	}
+
+	// the flows of the previous summary are used when it can't be read
+	if err := s.Client.flowLogs.Refresh(); err != nil {
+		s.logger.Warn("failed to read the flow logs", "error", err)
+	}
 
-		// Build the initial cache of instances
-		for _, region := range regions {
-			err := c.ec2Client.Refresh(ctx, c.cache, region)
-			if err != nil {
-				logger.Error("error refreshing cache for region", "region", region, "error", err)
-				continue
-			}
+	var total atomic.Int64
+	err := util.ForEach(ctx, s.regions, func(ctx context.Context, region string) error {
+		n, err := s.scrapeRegion(ctx, region, window)
+		total.Add(int64(n))
+		return err
+	})
+
+	// the CloudFront distributions are global, they're scraped once
+	if s.Client.cloudWatchClient.network {
+		distributions, cfErr := s.Client.cloudWatchClient.GetCloudFrontMetrics(ctx, window)
+		if cfErr != nil {
+			s.logger.Warn("failed to retrieve the cloudfront distributions", "error", cfErr)
 		}
+		total.Add(int64(s.publish(ctx, distributions, window)))
+	}
 
-		scrapers = append(scrapers, &Scraper{
-			ticker:  ticker,
-			Done:    make(chan bool),
-			regions: regions,
-			Bus:     b,
-			Client:  c,
-			logger:  logger,
-		})
+	// the control planes are accounted for whatever the regions scraped
+	planes := make([]v1.Instance, 0, len(s.Client.controlPlanes))
+	for i := range s.Client.controlPlanes {
+		planes = append(planes, s.Client.controlPlanes[i].Instance(provider, window))
 	}
+	total.Add(int64(s.publish(ctx, planes, window)))
 
-	return scrapers
+	return int(total.Load()), throttled(err)
 }
 
-func (s *Scraper) Start(ctx context.Context) {
-	go func() {
-		for {
-			select {
-			case <-s.Done:
-				return
-			case <-s.ticker.C:
-				s.scrape(ctx)
-			}
+// publish publishes the metrics of the instances labeled with the account,
+// with their flows over the window, and returns their amount
+func (s *Scraper) publish(ctx context.Context, instances []v1.Instance, window v1.Window) int {
+	for i := range instances {
+		instances[i].Labels = instances[i].Labels.With(v1.AccountLabel, s.account)
+		s.Client.flowLogs.Apply(&instances[i], window)
+
+		// Publish the metrics
+		if err := s.Bus.PublishContext(ctx, &bus.Event{
+			Type: v1.MetricsCollectedEvent,
+			Data: instances[i],
+		}); err != nil {
+			s.logger.Error("failed publishing instance", "error", err, "instance", instances[i].Name)
 		}
-	}()
+	}
 
-	// do the first scrape
-	s.scrape(ctx)
+	return len(instances)
 }
 
-func (s *Scraper) scrape(ctx context.Context) {
-	if len(s.regions) == 0 {
-		s.logger.Error("no AWS regions defined in the config")
-		return
+// throttled marks the errors of the requests rejected by the AWS APIs
+// because of their rate with v1.ErrProviderThrottled
+func throttled(err error) error {
+	if err == nil {
+		return nil
 	}
 
-	interval := config.AppConfig().Interval
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary {
+		return fmt.Errorf("%w: %w", v1.ErrProviderThrottled, err)
+	}
 
-	for _, region := range s.regions {
-		// refresh instance cache
-		if err := s.Client.ec2Client.Refresh(ctx, s.Client.cache, region); err != nil {
-			s.logger.Error("error refreshing EC2 instances", "error", err)
-			return
-		}
+	return err
+}
 
-		instances, err := s.Client.cloudWatchClient.GetEC2Metrics(
-			s.Client.cache,
-			region,
-			interval,
-		)
+// scrapeRegion refreshes the instances of the region, publishes their metrics
+// and returns the amount of instances collected
+func (s *Scraper) scrapeRegion(ctx context.Context, region string, window v1.Window) (int, error) {
+	// refresh instance cache
+	if err := s.Client.ec2Client.Refresh(ctx, s.Client.cache, region); err != nil {
+		return 0, fmt.Errorf("error refreshing EC2 instances: %w", err)
+	}
+
+	instances, err := s.Client.cloudWatchClient.GetEC2Metrics(
+		ctx,
+		s.Client.cache,
+		region,
+		window,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error getting EC2 Metrics with cloudwatch in region %s: %w", region, err)
+	}
+
+	// the load balancers are optional, the instances are still published
+	// when they can't be collected
+	if s.Client.cloudWatchClient.network {
+		balancers, err := s.Client.cloudWatchClient.GetNetworkMetrics(ctx, region, window)
 		if err != nil {
-			s.logger.Error("error getting EC2 Metrics with cloudwatch", "error", err)
-			return
+			s.logger.Warn("failed to retrieve the load balancers", "region", region, "error", err)
 		}
+		instances = append(instances, balancers...)
+	}
 
-		for i := range instances {
-			// Publish the metrics
-			if err := s.Bus.Publish(&bus.Event{
-				Type: v1.MetricsCollectedEvent,
-				Data: instances[i],
-			}); err != nil {
-				s.logger.Error("failed publishing instance", "error", err, "instance", instances[i].Name)
-			}
+	// the snapshots are optional too
+	if s.Client.ec2Client.snapshots {
+		snapshots, err := s.Client.ec2Client.Snapshots(ctx, region, window)
+		if err != nil {
+			s.logger.Warn("failed to retrieve the snapshots and the images", "region", region, "error", err)
 		}
+		instances = append(instances, snapshots...)
 	}
+
+	return s.publish(ctx, instances, window), nil
 }
 
-func (s *Scraper) Stop(ctx context.Context) {
-	s.Done <- true
+func (s *Scraper) Stop(ctx context.Context) {}
 
-	s.ticker.Stop()
+// Flush drops the cached instances, they are fetched again by the next scrape
+func (s *Scraper) Flush() {
+	s.Client.cache.Flush()
 }
@@ -0,0 +1,546 @@
+package amazon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func withEC2TestClient(api ec2Describer) options {
+	return func(c *Client) {
+		c.ec2Client = &ec2Client{client: api}
+	}
+}
+
+func withCloudWatchTestClient(api cloudwatch.GetMetricDataAPIClient) options {
+	return func(c *Client) {
+		c.cloudWatchClient = &cloudWatchClient{client: api}
+	}
+}
+
+// The timestamp of the samples of the 5 minutes windows ending at 00:05
+var windowStart = []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+func TestScrape(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	fakeEC2 := &fakeEC2{}
+	fakeEC2.DescribeInstancesReturns(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{ec2Instance("i-1"), ec2Instance("i-2")}}},
+	}, nil)
+
+	fakeCloudWatch := &fakeCloudWatch{}
+	fakeCloudWatch.GetMetricDataReturns(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []cwtypes.MetricDataResult{
+			{Label: aws.String("i-1"), Values: []float64{42}, Timestamps: windowStart},
+			// not running anymore
+			{Label: aws.String("i-3"), Values: []float64{10}, Timestamps: windowStart},
+			// without datapoints in the window
+			{Label: aws.String("i-2")},
+		},
+	}, nil)
+
+	account := &config.Account{Name: "prod", Regions: []string{"eu-north-1"}}
+	c, err := New(ctx, account, nil, withEC2TestClient(fakeEC2), withCloudWatchTestClient(fakeCloudWatch))
+	assert.NoError(err)
+
+	events := make(bustest.Collector, 3)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, events)
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{
+		Client:  c,
+		account: account.ID(),
+		regions: account.Regions,
+		Bus:     b,
+	}
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	// the metrics of the window are requested
+	_, input, _ := fakeCloudWatch.GetMetricDataArgsForCall(0)
+	assert.True(end.Equal(aws.ToTime(input.EndTime)))
+	assert.Equal(int32(60), aws.ToInt32(input.MetricDataQueries[0].Period))
+
+	i := <-events
+	assert.Equal("i-1", i.Name)
+	assert.Equal(v1.AWS, i.Provider)
+	assert.Equal("m5.xlarge", i.Kind)
+	assert.Equal(account.ID(), i.Labels[v1.AccountLabel])
+
+	cpu := i.Metrics[v1.CPU.String()]
+	assert.Equal(42.0, cpu.Usage)
+	assert.Equal(4.0, cpu.UnitAmount)
+	assert.True(end.Equal(cpu.UpdatedAt))
+
+	// a failing API fails the scrape
+	fakeCloudWatch.GetMetricDataReturns(nil, errors.New("unavailable"))
+	_, err = s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.ErrorContains(err, "unavailable")
+	assert.Equal(v1.CodeInternal, v1.Code(err))
+
+	// and is marked when it's throttled
+	fakeCloudWatch.GetMetricDataReturns(nil, &smithy.GenericAPIError{Code: "Throttling"})
+	_, err = s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.ErrorIs(err, v1.ErrProviderThrottled)
+
+	s.regions = nil
+	_, err = s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.Error(err)
+}
+
+func TestScrapeVolumes(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	fakeEC2 := &fakeEC2{}
+	fakeEC2.DescribeInstancesReturns(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{ec2Instance("i-1")}}},
+	}, nil)
+	fakeEC2.DescribeVolumesReturns(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{
+				VolumeId:    aws.String("vol-1"),
+				Size:        aws.Int32(100),
+				VolumeType:  types.VolumeTypeGp3,
+				Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-1")}},
+			},
+			{
+				VolumeId:    aws.String("vol-2"),
+				Size:        aws.Int32(500),
+				VolumeType:  types.VolumeTypeSt1,
+				Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-1")}},
+			},
+		},
+	}, nil)
+
+	fakeCloudWatch := &fakeCloudWatch{}
+	fakeCloudWatch.GetMetricDataCalls(func(ctx context.Context, input *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+		if !strings.Contains(aws.ToString(input.MetricDataQueries[0].Expression), "AWS/EBS") {
+			return &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []cwtypes.MetricDataResult{
+					{Label: aws.String("i-1"), Values: []float64{42}, Timestamps: windowStart},
+				},
+			}, nil
+		}
+
+		// the sums over the window of 300 seconds
+		return &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []cwtypes.MetricDataResult{
+				{Id: aws.String("volumereadops"), Label: aws.String("vol-1"), Values: []float64{30000}},
+				{Id: aws.String("volumewriteops"), Label: aws.String("vol-1"), Values: []float64{15000}},
+				{Id: aws.String("volumereadbytes"), Label: aws.String("vol-1"), Values: []float64{3e9}},
+			},
+		}, nil
+	})
+
+	account := &config.Account{Name: "prod", Regions: []string{"eu-north-1"}, Storage: true}
+	c, err := New(ctx, account, nil, withEC2TestClient(fakeEC2), withCloudWatchTestClient(fakeCloudWatch))
+	assert.NoError(err)
+
+	events := make(bustest.Collector, 1)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, events)
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{
+		Client:  c,
+		account: account.ID(),
+		regions: account.Regions,
+		Bus:     b,
+	}
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	i := <-events
+	assert.Len(i.Metrics, 3)
+
+	ssd := i.Metrics["vol-1"]
+	assert.Equal(v1.Storage, ssd.ResourceType)
+	assert.Equal(100.0, ssd.UnitAmount)
+	assert.Equal(v1.MediaSSD, ssd.Labels[v1.MediaLabel])
+	assert.Equal(150.0, ssd.IOPS)
+	assert.Equal(1e7, ssd.Throughput)
+	assert.True(end.Equal(ssd.UpdatedAt))
+
+	// the volumes without I/O are accounted for by their size
+	hdd := i.Metrics["vol-2"]
+	assert.Equal(500.0, hdd.UnitAmount)
+	assert.Equal(v1.MediaHDD, hdd.Labels[v1.MediaLabel])
+	assert.Zero(hdd.IOPS)
+
+	// the volumes can't be listed without the permission, the instances
+	// are still scraped
+	fakeEC2.DescribeVolumesReturns(nil, errors.New("unauthorized"))
+	n, err = s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(1, n)
+	i = <-events
+	assert.Len(i.Metrics, 1)
+}
+
+func TestScrapeNetwork(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	fakeEC2 := &fakeEC2{}
+	fakeEC2.DescribeInstancesReturns(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{ec2Instance("i-1")}}},
+	}, nil)
+
+	var regions []string
+	fakeCloudWatch := &fakeCloudWatch{}
+	fakeCloudWatch.GetMetricDataCalls(func(ctx context.Context, input *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+		expression := aws.ToString(input.MetricDataQueries[0].Expression)
+		switch {
+		case strings.Contains(expression, "AWS/ApplicationELB"):
+			// the sums over the window
+			return &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []cwtypes.MetricDataResult{
+					{Id: aws.String("alb"), Label: aws.String("app/web/50dc6c495c0c9188"), Values: []float64{2e9, 1e9}},
+					{Id: aws.String("nlb"), Label: aws.String("net/db/7e6ba4d9b6bd2a3c"), Values: []float64{5e8}},
+					// idle in the window
+					{Id: aws.String("nlb"), Label: aws.String("net/old/1a2b3c4d5e6f7a8b")},
+				},
+			}, nil
+		case strings.Contains(expression, "AWS/CloudFront"):
+			o := cloudwatch.Options{}
+			for _, fn := range optFns {
+				fn(&o)
+			}
+			regions = append(regions, o.Region)
+			return &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []cwtypes.MetricDataResult{
+					{Id: aws.String("cloudfront"), Label: aws.String("E2QWRUHAPOMQZL"), Values: []float64{4e10}},
+				},
+			}, nil
+		}
+		return &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []cwtypes.MetricDataResult{
+				{Label: aws.String("i-1"), Values: []float64{42}, Timestamps: windowStart},
+			},
+		}, nil
+	})
+
+	account := &config.Account{Name: "prod", Regions: []string{"eu-north-1"}, Network: true}
+	c, err := New(ctx, account, nil, withEC2TestClient(fakeEC2), withCloudWatchTestClient(fakeCloudWatch))
+	assert.NoError(err)
+
+	events := make(bustest.Collector, 4)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, events)
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{
+		Client:  c,
+		account: account.ID(),
+		regions: account.Regions,
+		Bus:     b,
+	}
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(4, n)
+
+	// the distributions are scraped once, in the region of their metrics
+	assert.Equal([]string{"us-east-1"}, regions)
+
+	byName := make(map[string]v1.Instance)
+	for range n {
+		i := <-events
+		byName[i.Name] = i
+	}
+
+	alb := byName["app/web/50dc6c495c0c9188"]
+	assert.Equal("elb", alb.Service)
+	assert.Equal("eu-north-1", alb.Region)
+	assert.Empty(alb.Kind)
+	assert.Equal("web", alb.Labels[v1.NameLabel])
+	assert.Equal("prod", alb.Labels[v1.AccountLabel])
+	assert.Equal(v1.Network, alb.Metrics[v1.Network.String()].ResourceType)
+	assert.Equal(3.0, alb.Metrics[v1.Network.String()].UnitAmount)
+	assert.Equal(v1.GB, alb.Metrics[v1.Network.String()].Unit)
+
+	assert.Equal(0.5, byName["net/db/7e6ba4d9b6bd2a3c"].Metrics[v1.Network.String()].UnitAmount)
+
+	cdn := byName["E2QWRUHAPOMQZL"]
+	assert.Equal("cloudfront", cdn.Service)
+	assert.Equal("us-east-1", cdn.Region)
+	assert.Equal("E2QWRUHAPOMQZL", cdn.Labels[v1.NameLabel])
+	assert.Equal(40.0, cdn.Metrics[v1.Network.String()].UnitAmount)
+}
+
+func TestScrapeGranularity(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	util.SetGranularity(provider, map[string]time.Duration{"network": time.Hour, "storage": 15 * time.Minute})
+	defer util.SetGranularity(provider, nil)
+
+	fakeEC2 := &fakeEC2{}
+	fakeEC2.DescribeInstancesReturns(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{ec2Instance("i-1")}}},
+	}, nil)
+	fakeEC2.DescribeVolumesReturns(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{
+				VolumeId:    aws.String("vol-1"),
+				Size:        aws.Int32(100),
+				VolumeType:  types.VolumeTypeGp3,
+				Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-1")}},
+			},
+		},
+	}, nil)
+
+	windows := make(map[string][]time.Duration)
+	fakeCloudWatch := &fakeCloudWatch{}
+	fakeCloudWatch.GetMetricDataCalls(func(ctx context.Context, input *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+		window := aws.ToTime(input.EndTime).Sub(aws.ToTime(input.StartTime))
+		expression := aws.ToString(input.MetricDataQueries[0].Expression)
+		switch {
+		case strings.Contains(expression, "AWS/EBS"):
+			windows["ebs"] = append(windows["ebs"], window)
+			return &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []cwtypes.MetricDataResult{
+					{Id: aws.String("volumereadops"), Label: aws.String("vol-1"), Values: []float64{window.Seconds() * 150}},
+				},
+			}, nil
+		case strings.Contains(expression, "AWS/ApplicationELB"):
+			windows["elb"] = append(windows["elb"], window)
+			return &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []cwtypes.MetricDataResult{
+					{Id: aws.String("alb"), Label: aws.String("app/web/50dc6c495c0c9188"), Values: []float64{3e9}},
+				},
+			}, nil
+		case strings.Contains(expression, "AWS/CloudFront"):
+			windows["cloudfront"] = append(windows["cloudfront"], window)
+			return &cloudwatch.GetMetricDataOutput{}, nil
+		}
+		return &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []cwtypes.MetricDataResult{
+				{Label: aws.String("i-1"), Values: []float64{42}, Timestamps: []time.Time{aws.ToTime(input.EndTime).Add(-5 * time.Minute)}},
+			},
+		}, nil
+	})
+
+	account := &config.Account{Name: "prod", Regions: []string{"eu-north-1"}, Storage: true, Network: true}
+	c, err := New(ctx, account, nil, withEC2TestClient(fakeEC2), withCloudWatchTestClient(fakeCloudWatch))
+	assert.NoError(err)
+
+	events := make(bustest.Collector, 2)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, events)
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{
+		Client:  c,
+		account: account.ID(),
+		regions: account.Regions,
+		Bus:     b,
+	}
+
+	scrape := func(end time.Time) map[string]v1.Instance {
+		n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+		assert.NoError(err)
+
+		byName := make(map[string]v1.Instance)
+		for range n {
+			i := <-events
+			byName[i.Name] = i
+		}
+		return byName
+	}
+
+	// the first scrape collects everything over its window
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	byName := scrape(end)
+	assert.Len(byName, 2)
+	assert.Equal(150.0, byName["i-1"].Metrics["vol-1"].IOPS)
+
+	// the I/O of the last collection is used until the next one and the
+	// balancers aren't collected
+	byName = scrape(end.Add(5 * time.Minute))
+	assert.Len(byName, 1)
+	assert.Equal(150.0, byName["i-1"].Metrics["vol-1"].IOPS)
+
+	for i := 2; i <= 12; i++ {
+		byName = scrape(end.Add(time.Duration(i) * 5 * time.Minute))
+	}
+	assert.Equal([]time.Duration{5 * time.Minute, 15 * time.Minute, 15 * time.Minute, 15 * time.Minute, 15 * time.Minute}, windows["ebs"])
+	assert.Equal([]time.Duration{5 * time.Minute, time.Hour}, windows["elb"])
+	assert.Equal([]time.Duration{5 * time.Minute, time.Hour}, windows["cloudfront"])
+
+	// the GB are observed over the hour
+	network := byName["app/web/50dc6c495c0c9188"].Metrics[v1.Network.String()]
+	assert.Equal(3.0, network.UnitAmount)
+	assert.Equal(time.Hour, network.Observed.Duration())
+	assert.Equal(150.0, byName["i-1"].Metrics["vol-1"].IOPS)
+}
+
+func TestScrapeSnapshots(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	fakeEC2 := &fakeEC2{}
+	fakeEC2.DescribeInstancesReturns(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{ec2Instance("i-1")}}},
+	}, nil)
+	fakeEC2.DescribeImagesReturns(&ec2.DescribeImagesOutput{
+		Images: []types.Image{
+			{
+				ImageId: aws.String("ami-1"),
+				Name:    aws.String("web-2024-01"),
+				BlockDeviceMappings: []types.BlockDeviceMapping{
+					{Ebs: &types.EbsBlockDevice{SnapshotId: aws.String("snap-ami"), VolumeSize: aws.Int32(8)}},
+					{Ebs: &types.EbsBlockDevice{SnapshotId: aws.String("snap-ami-data"), VolumeSize: aws.Int32(100)}},
+					// the instance store volumes aren't stored
+					{VirtualName: aws.String("ephemeral0")},
+				},
+			},
+		},
+	}, nil)
+	fakeEC2.DescribeSnapshotsReturnsOnCall(0, &ec2.DescribeSnapshotsOutput{
+		Snapshots: []types.Snapshot{
+			{
+				SnapshotId:  aws.String("snap-1"),
+				VolumeId:    aws.String("vol-1"),
+				VolumeSize:  aws.Int32(500),
+				StorageTier: types.StorageTierArchive,
+				Tags:        []types.Tag{{Key: aws.String("Name"), Value: aws.String("db-backup")}},
+			},
+			// accounted for with its AMI
+			{SnapshotId: aws.String("snap-ami"), VolumeSize: aws.Int32(8)},
+		},
+		NextToken: aws.String("next"),
+	}, nil)
+	fakeEC2.DescribeSnapshotsReturnsOnCall(1, &ec2.DescribeSnapshotsOutput{
+		Snapshots: []types.Snapshot{
+			{SnapshotId: aws.String("snap-ami-data"), VolumeSize: aws.Int32(100)},
+			{SnapshotId: aws.String("snap-2"), VolumeSize: aws.Int32(20)},
+		},
+	}, nil)
+
+	fakeCloudWatch := &fakeCloudWatch{}
+	fakeCloudWatch.GetMetricDataReturns(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []cwtypes.MetricDataResult{
+			{Label: aws.String("i-1"), Values: []float64{42}, Timestamps: windowStart},
+		},
+	}, nil)
+
+	account := &config.Account{Name: "prod", Regions: []string{"eu-north-1"}, Snapshots: true}
+	c, err := New(ctx, account, nil, withEC2TestClient(fakeEC2), withCloudWatchTestClient(fakeCloudWatch))
+	assert.NoError(err)
+
+	events := make(bustest.Collector, 4)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, events)
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{
+		Client:  c,
+		account: account.ID(),
+		regions: account.Regions,
+		Bus:     b,
+	}
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(4, n)
+
+	// the pages of the snapshots are listed
+	assert.Equal(2, fakeEC2.DescribeSnapshotsCallCount())
+	_, input, _ := fakeEC2.DescribeSnapshotsArgsForCall(1)
+	assert.Equal("next", aws.ToString(input.NextToken))
+	assert.Equal([]string{"self"}, input.OwnerIds)
+
+	byName := make(map[string]v1.Instance)
+	for range n {
+		i := <-events
+		byName[i.Name] = i
+	}
+
+	image := byName["ami-1"]
+	assert.Equal("ami", image.Service)
+	assert.Equal("web-2024-01", image.Labels[v1.NameLabel])
+	assert.Equal(108.0, image.Metrics[v1.Storage.String()].UnitAmount)
+
+	snapshot := byName["snap-1"]
+	assert.Equal("ebs-snapshot", snapshot.Service)
+	assert.Equal("eu-north-1", snapshot.Region)
+	assert.Empty(snapshot.Kind)
+	assert.Equal("db-backup", snapshot.Labels[v1.NameLabel])
+	assert.Equal("vol-1", snapshot.Labels["volume_id"])
+	assert.Equal("archive", snapshot.Labels["storage_tier"])
+
+	storage := snapshot.Metrics[v1.Storage.String()]
+	assert.Equal(v1.Storage, storage.ResourceType)
+	assert.Equal(500.0, storage.UnitAmount)
+	assert.Equal(v1.MediaHDD, storage.Labels[v1.MediaLabel])
+
+	assert.Equal(20.0, byName["snap-2"].Metrics[v1.Storage.String()].UnitAmount)
+}
+
+func TestCheck(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	fakeEC2 := &fakeEC2{}
+	// the dry runs of the granted calls fail with DryRunOperation
+	fakeEC2.DescribeInstancesReturns(nil, &smithy.GenericAPIError{Code: "DryRunOperation"})
+	fakeEC2.DescribeVolumesReturns(nil, &smithy.GenericAPIError{Code: "UnauthorizedOperation", Message: "You are not authorized to perform this operation."})
+
+	fakeCloudWatch := &fakeCloudWatch{}
+	fakeCloudWatch.GetMetricDataReturns(&cloudwatch.GetMetricDataOutput{}, nil)
+
+	account := &config.Account{Name: "prod", Regions: []string{"eu-north-1", "eu-west-1"}, Storage: true}
+	c, err := New(ctx, account, nil, withEC2TestClient(fakeEC2), withCloudWatchTestClient(fakeCloudWatch))
+	assert.NoError(err)
+
+	s := &Scraper{Client: c, account: account.ID(), regions: account.Regions}
+
+	permissions := s.Check(ctx)
+	assert.Equal([]v1.Permission{
+		{Name: "ec2:DescribeInstances", Granted: true},
+		{Name: "ec2:DescribeVolumes", Error: "api error UnauthorizedOperation: You are not authorized to perform this operation."},
+		{Name: "cloudwatch:GetMetricData", Granted: true},
+	}, permissions)
+
+	// the calls are dry runs in the first region
+	_, input, _ := fakeEC2.DescribeInstancesArgsForCall(0)
+	assert.True(aws.ToBool(input.DryRun))
+	assert.Equal(1, fakeEC2.DescribeInstancesCallCount())
+
+	// no region, nothing to check
+	s.regions = nil
+	assert.Empty(s.Check(ctx))
+}
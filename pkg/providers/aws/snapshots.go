@@ -0,0 +1,143 @@
+package amazon
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The services of the snapshots and the images
+const (
+	snapshotService = "ebs-snapshot"
+	imageService    = "ami"
+)
+
+// Snapshots returns the EBS snapshots and the AMIs owned by the account in
+// the region as instances with the size they store. The snapshots of the
+// AMIs are accounted for with their AMI, not twice. The snapshots are
+// stored in S3, so they're accounted for as HDDs
+func (e *ec2Client) Snapshots(ctx context.Context, region string, window v1.Window) ([]v1.Instance, error) {
+	withRegion := func(o *ec2.Options) {
+		o.Region = region
+		if endpoint := regionalEndpoint(e.endpoint, region); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
+	}
+
+	images, err := e.images(ctx, withRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []v1.Instance
+	ofImages := make(map[string]bool)
+	for i := range images {
+		image := &images[i]
+
+		var gb float64
+		for _, mapping := range image.BlockDeviceMappings {
+			if mapping.Ebs == nil {
+				continue
+			}
+			gb += float64(aws.ToInt32(mapping.Ebs.VolumeSize))
+			ofImages[aws.ToString(mapping.Ebs.SnapshotId)] = true
+		}
+
+		instances = append(instances, storedInstance(
+			imageService, aws.ToString(image.ImageId), aws.ToString(image.Name), region, gb, window,
+		))
+	}
+
+	input := &ec2.DescribeSnapshotsInput{
+		OwnerIds:   []string{"self"},
+		MaxResults: aws.Int32(1000),
+	}
+	for {
+		if err := util.WaitForAPI(ctx, provider, ec2API); err != nil {
+			return nil, err
+		}
+		output, err := e.client.DescribeSnapshots(ctx, input, withRegion)
+		util.RecordAPICall(provider, ec2API, "DescribeSnapshots", err)
+		if err != nil {
+			return nil, err
+		}
+		if output == nil {
+			return instances, nil
+		}
+
+		for _, snapshot := range output.Snapshots {
+			id := aws.ToString(snapshot.SnapshotId)
+			if ofImages[id] {
+				continue
+			}
+
+			i := storedInstance(
+				snapshotService, id, getInstanceTag(snapshot.Tags, "Name"), region,
+				float64(aws.ToInt32(snapshot.VolumeSize)), window,
+			)
+			i.Labels = tagLabels(snapshot.Tags, i.Labels)
+			i.Labels.Add("volume_id", aws.ToString(snapshot.VolumeId))
+			i.Labels.Add("storage_tier", string(snapshot.StorageTier))
+			instances = append(instances, i)
+		}
+
+		if output.NextToken == nil {
+			return instances, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// images returns the AMIs owned by the account
+func (e *ec2Client) images(ctx context.Context, withRegion func(*ec2.Options)) ([]types.Image, error) {
+	var images []types.Image
+
+	input := &ec2.DescribeImagesInput{
+		Owners:     []string{"self"},
+		MaxResults: aws.Int32(1000),
+	}
+	for {
+		if err := util.WaitForAPI(ctx, provider, ec2API); err != nil {
+			return nil, err
+		}
+		output, err := e.client.DescribeImages(ctx, input, withRegion)
+		util.RecordAPICall(provider, ec2API, "DescribeImages", err)
+		if err != nil {
+			return nil, err
+		}
+		if output == nil {
+			return images, nil
+		}
+
+		images = append(images, output.Images...)
+
+		if output.NextToken == nil {
+			return images, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// storedInstance returns the instance of a snapshot or an image with the GB
+// it stores. The size is the one of the source volumes, the snapshots are
+// incremental so it's an upper bound of what they store
+func storedInstance(service, id, name, region string, gb float64, window v1.Window) v1.Instance {
+	i := v1.NewInstance(id, provider)
+	i.Service = service
+	i.Region = region
+	i.Labels.Add(v1.NameLabel, name)
+	i.Metrics.Upsert(&v1.Metric{
+		Name:         v1.Storage.String(),
+		ResourceType: v1.Storage,
+		UnitAmount:   gb,
+		Unit:         v1.GB,
+		UpdatedAt:    window.End.UTC(),
+		Labels:       v1.Labels{v1.MediaLabel: v1.MediaHDD},
+	})
+
+	return *i
+}
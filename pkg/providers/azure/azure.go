@@ -0,0 +1,382 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	cache "github.com/patrickmn/go-cache"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/relabel"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// samplePeriod is the resolution of the CPU utilization of the virtual
+// machines
+const samplePeriod = time.Minute
+
+// The metric of the CPU utilization and the dimension of the resource it's
+// split by at the subscription scope
+const (
+	cpuMetric         = "Percentage CPU"
+	resourceDimension = "Microsoft.ResourceId"
+)
+
+// How long the sizes of a region are cached, they rarely change
+const sizesExpiration = 24 * time.Hour
+
+// Client is the structure used as the provider for Azure
+type Client struct {
+	// Azure clients, faked by the tests
+	vms     vmLister
+	sizes   sizeLister
+	metrics metricsQuerier
+
+	// The regions scraped, all the ones of the virtual machines when empty
+	regions []string
+
+	// The running virtual machines, by resource ID, and the sizes of the
+	// regions
+	cache *cache.Cache
+}
+
+type options func(*Client)
+
+// New returns a new instance of the Azure provider for the subscription of
+// the account, authenticated with the default credentials of the environment
+func New(ctx context.Context, account *config.Account, opts ...options) (*Client, error) {
+	if account.Subscription == "" {
+		return nil, errors.New("no Azure subscription set")
+	}
+
+	c := &Client{
+		regions: account.Regions,
+		cache:   cache.New(time.Hour, time.Hour),
+	}
+
+	// overwrite any options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// the clients are only created when they aren't overwritten, the
+	// credentials aren't looked up otherwise
+	if c.vms != nil && c.sizes != nil && c.metrics != nil {
+		return c, nil
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading the Azure credentials: %w", err)
+	}
+
+	if c.vms == nil {
+		vc, err := armcompute.NewVirtualMachinesClient(account.Subscription, cred, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.vms = &vmsClient{vc}
+	}
+
+	if c.sizes == nil {
+		sc, err := armcompute.NewVirtualMachineSizesClient(account.Subscription, cred, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.sizes = &sizesClient{sc}
+	}
+
+	if c.metrics == nil {
+		mc, err := armmonitor.NewMetricsClient(account.Subscription, cred, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.metrics = &metricsClient{mc}
+	}
+
+	return c, nil
+}
+
+// Refresh fetches the running virtual machines of the subscription and the
+// sizes of their regions, and caches them for the metrics collection
+func (c *Client) Refresh(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+		return err
+	}
+
+	vms, err := c.vms.ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed listing the virtual machines: %w", err)
+	}
+
+	regions := make(map[string]bool)
+	for _, vm := range vms {
+		region := strings.ToLower(value(vm.Location))
+		if region == "" || len(c.regions) > 0 && !slices.Contains(c.regions, region) {
+			continue
+		}
+
+		key := vmKey(value(vm.ID))
+
+		// the deallocated virtual machines don't emit
+		if !running(vm) {
+			c.cache.Delete(key)
+			continue
+		}
+
+		c.cache.Set(key, instance(vm, region), cache.DefaultExpiration)
+		regions[region] = true
+	}
+
+	for region := range regions {
+		if _, ok := c.cache.Get(sizesKey(region)); ok {
+			continue
+		}
+
+		if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+			return err
+		}
+
+		// the vCPUs fall back to the ones of the emission factors
+		sizes, err := c.sizes.List(ctx, region)
+		if err != nil {
+			logger.Warn("failed listing the virtual machine sizes", "region", region, "error", err)
+			continue
+		}
+
+		cores := make(map[string]float64, len(sizes))
+		for _, s := range sizes {
+			cores[strings.ToLower(value(s.Name))] = float64(value(s.NumberOfCores))
+		}
+		c.cache.Set(sizesKey(region), cores, sizesExpiration)
+	}
+
+	return nil
+}
+
+// GetMetricsForInstances returns the CPU utilization of the cached virtual
+// machines over the window, the regions are queried concurrently
+func (c *Client) GetMetricsForInstances(ctx context.Context, window v1.Window) ([]v1.Instance, error) {
+	// the virtual machines of every region, their resource IDs start with a
+	// slash unlike the keys of the sizes
+	byRegion := make(map[string][]v1.Instance)
+	for key, item := range c.cache.Items() {
+		if vm, ok := item.Object.(v1.Instance); ok && strings.HasPrefix(key, "/") {
+			byRegion[vm.Region] = append(byRegion[vm.Region], vm)
+		}
+	}
+
+	regions := make([]string, 0, len(byRegion))
+	for region := range byRegion {
+		regions = append(regions, region)
+	}
+	slices.Sort(regions)
+
+	var (
+		instances []v1.Instance
+		mu        sync.Mutex
+	)
+
+	err := util.ForEach(ctx, regions, func(ctx context.Context, region string) error {
+		collected, err := c.regionMetrics(ctx, region, byRegion[region], window)
+		if err != nil {
+			return fmt.Errorf("failed getting the metrics of region %s: %w", region, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		instances = append(instances, collected...)
+		return nil
+	})
+
+	return instances, err
+}
+
+// regionMetrics queries the CPU utilization of all the virtual machines of
+// the region at once, split by resource
+func (c *Client) regionMetrics(ctx context.Context, region string, vms []v1.Instance, window v1.Window) ([]v1.Instance, error) {
+	if err := util.WaitForAPI(ctx, provider, monitorAPI); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.metrics.ListAtSubscriptionScope(ctx, region, &armmonitor.MetricsClientListAtSubscriptionScopeOptions{
+		Metricnamespace: to.Ptr(service),
+		Metricnames:     to.Ptr(cpuMetric),
+		Aggregation:     to.Ptr("Average"),
+		Interval:        to.Ptr("PT1M"),
+		Timespan:        to.Ptr(window.Start.UTC().Format(time.RFC3339) + "/" + window.End.UTC().Format(time.RFC3339)),
+		Filter:          to.Ptr(resourceDimension + " eq '*'"),
+		// a series per virtual machine, the default is 10
+		Top: to.Ptr(int32(len(vms))),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the samples of every virtual machine
+	samples := make(map[string][]util.Sample)
+	for _, m := range resp.Value {
+		for _, ts := range m.Timeseries {
+			id := dimension(ts, resourceDimension)
+			if id == "" {
+				continue
+			}
+
+			key := vmKey(id)
+			for _, d := range ts.Data {
+				// the minutes without data have no average
+				if d.TimeStamp == nil || d.Average == nil {
+					continue
+				}
+				samples[key] = append(samples[key], util.Sample{
+					Time:  *d.TimeStamp,
+					Value: *d.Average,
+				})
+			}
+		}
+	}
+
+	cores, _ := c.cache.Get(sizesKey(region))
+	sizes, _ := cores.(map[string]float64)
+
+	instances := make([]v1.Instance, 0, len(vms))
+	for _, vm := range vms {
+		key := vmKey(vm.Labels["ID"])
+		resolution := util.Resolution(samples[key], samplePeriod)
+
+		usage, observed, ok := util.Align(samples[key], resolution, window)
+		if !ok {
+			continue
+		}
+
+		m := v1.NewMetric(v1.CPU.String())
+		m.Unit = v1.VCPU
+		m.ResourceType = v1.CPU
+		m.Usage = usage
+		m.UpdatedAt = window.End.UTC()
+		m.Observed = observed
+		// the vCPUs of the size are a fallback to the ones of the dataset
+		m.UnitAmount = sizes[strings.ToLower(vm.Kind)]
+		m.Labels = v1.Labels{
+			util.ResolutionLabel: util.FormatResolution(resolution),
+		}
+
+		// the labels are shared with the cache
+		vm.Labels = vm.Labels.With(v1.NameLabel, vm.Name)
+		vm.Metrics = v1.Metrics{}
+		vm.Metrics.Upsert(m)
+		instances = append(instances, vm)
+	}
+
+	return instances, nil
+}
+
+// instance returns the instance of a running virtual machine
+func instance(vm *armcompute.VirtualMachine, region string) v1.Instance {
+	i := v1.NewInstance(value(vm.Name), provider)
+	i.Service = service
+	i.Region = region
+
+	// the availability zone, if the virtual machine is zonal
+	if len(vm.Zones) > 0 {
+		i.Zone = region + "-" + value(vm.Zones[0])
+	}
+
+	// the resource ID identifies the metrics of the virtual machine
+	i.Labels["ID"] = value(vm.ID)
+
+	if p := vm.Properties; p != nil {
+		if p.HardwareProfile != nil && p.HardwareProfile.VMSize != nil {
+			i.Kind = string(*p.HardwareProfile.VMSize)
+			i.Architecture = architecture(i.Kind)
+		}
+		if p.Priority != nil {
+			i.Labels["Lifecycle"] = string(*p.Priority)
+		}
+		if p.TimeCreated != nil {
+			i.StartedAt = p.TimeCreated.UTC()
+		}
+	}
+
+	// the labels of the virtual machine are its tags, matched by the
+	// grouping rules
+	for k, v := range vm.Tags {
+		i.Labels[v1.TagLabelPrefix+relabel.LabelName(k)] = value(v)
+	}
+
+	return *i
+}
+
+// running returns whether the power state of the virtual machine is running
+func running(vm *armcompute.VirtualMachine) bool {
+	if vm.Properties == nil || vm.Properties.InstanceView == nil {
+		return false
+	}
+
+	for _, s := range vm.Properties.InstanceView.Statuses {
+		if strings.EqualFold(value(s.Code), "PowerState/running") {
+			return true
+		}
+	}
+	return false
+}
+
+// The sizes of the Ampere Altra and Cobalt virtual machines have a p in the
+// features following their vCPUs, e.g. Standard_D4ps_v5
+var armSize = regexp.MustCompile(`(?i)^standard_[a-z]+[0-9]+(-[0-9]+)?[a-z]*p`)
+
+// architecture returns the CPU architecture of the size of a virtual
+// machine, empty when the size is unknown
+func architecture(size string) string {
+	switch {
+	case size == "":
+		return ""
+	case armSize.MatchString(size):
+		return "arm64"
+	default:
+		return "x86_64"
+	}
+}
+
+// dimension returns the value of the dimension of the series
+func dimension(ts *armmonitor.TimeSeriesElement, name string) string {
+	for _, m := range ts.Metadatavalues {
+		if m.Name != nil && strings.EqualFold(value(m.Name.Value), name) {
+			return value(m.Value)
+		}
+	}
+	return ""
+}
+
+// vmKey returns the cache key of a virtual machine, Azure Monitor doesn't
+// keep the case of the resource IDs
+func vmKey(id string) string {
+	return strings.ToLower(id)
+}
+
+// sizesKey returns the cache key of the sizes of a region
+func sizesKey(region string) string {
+	return "sizes/" + region
+}
+
+// value returns the value of the pointer, zero when nil
+func value[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,54 @@
+package azure
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Check verifies the permissions of the scrapes of the subscription. The
+// sizes and the metrics are checked in the first region scraped, the role
+// assignments apply to all of them
+func (c *Client) Check(ctx context.Context) []v1.Permission {
+	vms, err := c.vms.ListAll(ctx)
+	permissions := []v1.Permission{util.Permission("Microsoft.Compute/virtualMachines/read", err)}
+
+	regions := c.regions
+	if len(regions) == 0 {
+		for _, vm := range vms {
+			if region := strings.ToLower(value(vm.Location)); region != "" && !slices.Contains(regions, region) {
+				regions = append(regions, region)
+			}
+		}
+		slices.Sort(regions)
+	}
+
+	// nothing is scraped without a region
+	if len(regions) == 0 {
+		return permissions
+	}
+	region := regions[0]
+
+	_, err = c.sizes.List(ctx, region)
+	permissions = append(permissions, util.Permission("Microsoft.Compute/locations/vmSizes/read", err))
+
+	end := time.Now().UTC().Truncate(time.Minute)
+	_, err = c.metrics.ListAtSubscriptionScope(ctx, region, &armmonitor.MetricsClientListAtSubscriptionScopeOptions{
+		Metricnamespace: to.Ptr(service),
+		Metricnames:     to.Ptr(cpuMetric),
+		Aggregation:     to.Ptr("Average"),
+		Interval:        to.Ptr("PT1M"),
+		Timespan:        to.Ptr(end.Add(-samplePeriod).Format(time.RFC3339) + "/" + end.Format(time.RFC3339)),
+		Filter:          to.Ptr(resourceDimension + " eq '*'"),
+		Top:             to.Ptr(int32(1)),
+	})
+	permissions = append(permissions, util.Permission("Microsoft.Insights/metrics/read", err))
+
+	return permissions
+}
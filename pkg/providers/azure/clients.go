@@ -0,0 +1,95 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/re-cinq/aether/pkg/providers/util"
+)
+
+// vmLister lists the virtual machines of a subscription with their power
+// state, the pages of the results are read before returning
+//
+//counterfeiter:generate -o fake_vms_test.go -fake-name fakeVMs . vmLister
+type vmLister interface {
+	ListAll(ctx context.Context) ([]*armcompute.VirtualMachine, error)
+}
+
+// sizeLister lists the sizes of the virtual machines of a region
+//
+//counterfeiter:generate -o fake_sizes_test.go -fake-name fakeSizes . sizeLister
+type sizeLister interface {
+	List(ctx context.Context, region string) ([]*armcompute.VirtualMachineSize, error)
+}
+
+// metricsQuerier queries the metrics of all the resources of a region of
+// the subscription at once
+//
+//counterfeiter:generate -o fake_metrics_test.go -fake-name fakeMetrics . metricsQuerier
+type metricsQuerier interface {
+	ListAtSubscriptionScope(ctx context.Context, region string, options *armmonitor.MetricsClientListAtSubscriptionScopeOptions) (armmonitor.SubscriptionScopeMetricResponse, error)
+}
+
+// vmsClient lists the virtual machines with the compute API
+type vmsClient struct {
+	*armcompute.VirtualMachinesClient
+}
+
+// ListAll returns the virtual machines of every page, a request is made per
+// page
+func (c *vmsClient) ListAll(ctx context.Context) ([]*armcompute.VirtualMachine, error) {
+	var vms []*armcompute.VirtualMachine
+
+	pager := c.NewListAllPager(&armcompute.VirtualMachinesClientListAllOptions{
+		// the power state of the virtual machines
+		StatusOnly: to.Ptr("true"),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		util.RecordAPICall(provider, computeAPI, "ListAll", err)
+		if err != nil {
+			return nil, err
+		}
+
+		vms = append(vms, page.Value...)
+	}
+
+	return vms, nil
+}
+
+// sizesClient lists the sizes with the compute API
+type sizesClient struct {
+	*armcompute.VirtualMachineSizesClient
+}
+
+// List returns the sizes available in the region
+func (c *sizesClient) List(ctx context.Context, region string) ([]*armcompute.VirtualMachineSize, error) {
+	var sizes []*armcompute.VirtualMachineSize
+
+	pager := c.NewListPager(region, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		util.RecordAPICall(provider, computeAPI, "ListSizes", err)
+		if err != nil {
+			return nil, err
+		}
+
+		sizes = append(sizes, page.Value...)
+	}
+
+	return sizes, nil
+}
+
+// metricsClient queries the metrics with the Azure Monitor API
+type metricsClient struct {
+	*armmonitor.MetricsClient
+}
+
+// ListAtSubscriptionScope returns the metrics of the resources of the region
+func (c *metricsClient) ListAtSubscriptionScope(ctx context.Context, region string, options *armmonitor.MetricsClientListAtSubscriptionScopeOptions) (armmonitor.SubscriptionScopeMetricResponse, error) {
+	resp, err := c.MetricsClient.ListAtSubscriptionScope(ctx, region, options)
+	util.RecordAPICall(provider, monitorAPI, "ListAtSubscriptionScope", err)
+	return resp.SubscriptionScopeMetricResponse, err
+}
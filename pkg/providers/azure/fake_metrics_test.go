@@ -0,0 +1,119 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package azure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+type fakeMetrics struct {
+	ListAtSubscriptionScopeStub        func(context.Context, string, *armmonitor.MetricsClientListAtSubscriptionScopeOptions) (armmonitor.SubscriptionScopeMetricResponse, error)
+	listAtSubscriptionScopeMutex       sync.RWMutex
+	listAtSubscriptionScopeArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *armmonitor.MetricsClientListAtSubscriptionScopeOptions
+	}
+	listAtSubscriptionScopeReturns struct {
+		result1 armmonitor.SubscriptionScopeMetricResponse
+		result2 error
+	}
+	listAtSubscriptionScopeReturnsOnCall map[int]struct {
+		result1 armmonitor.SubscriptionScopeMetricResponse
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeMetrics) ListAtSubscriptionScope(arg1 context.Context, arg2 string, arg3 *armmonitor.MetricsClientListAtSubscriptionScopeOptions) (armmonitor.SubscriptionScopeMetricResponse, error) {
+	fake.listAtSubscriptionScopeMutex.Lock()
+	ret, specificReturn := fake.listAtSubscriptionScopeReturnsOnCall[len(fake.listAtSubscriptionScopeArgsForCall)]
+	fake.listAtSubscriptionScopeArgsForCall = append(fake.listAtSubscriptionScopeArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *armmonitor.MetricsClientListAtSubscriptionScopeOptions
+	}{arg1, arg2, arg3})
+	stub := fake.ListAtSubscriptionScopeStub
+	fakeReturns := fake.listAtSubscriptionScopeReturns
+	fake.recordInvocation("ListAtSubscriptionScope", []interface{}{arg1, arg2, arg3})
+	fake.listAtSubscriptionScopeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeMetrics) ListAtSubscriptionScopeCallCount() int {
+	fake.listAtSubscriptionScopeMutex.RLock()
+	defer fake.listAtSubscriptionScopeMutex.RUnlock()
+	return len(fake.listAtSubscriptionScopeArgsForCall)
+}
+
+func (fake *fakeMetrics) ListAtSubscriptionScopeCalls(stub func(context.Context, string, *armmonitor.MetricsClientListAtSubscriptionScopeOptions) (armmonitor.SubscriptionScopeMetricResponse, error)) {
+	fake.listAtSubscriptionScopeMutex.Lock()
+	defer fake.listAtSubscriptionScopeMutex.Unlock()
+	fake.ListAtSubscriptionScopeStub = stub
+}
+
+func (fake *fakeMetrics) ListAtSubscriptionScopeArgsForCall(i int) (context.Context, string, *armmonitor.MetricsClientListAtSubscriptionScopeOptions) {
+	fake.listAtSubscriptionScopeMutex.RLock()
+	defer fake.listAtSubscriptionScopeMutex.RUnlock()
+	argsForCall := fake.listAtSubscriptionScopeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeMetrics) ListAtSubscriptionScopeReturns(result1 armmonitor.SubscriptionScopeMetricResponse, result2 error) {
+	fake.listAtSubscriptionScopeMutex.Lock()
+	defer fake.listAtSubscriptionScopeMutex.Unlock()
+	fake.ListAtSubscriptionScopeStub = nil
+	fake.listAtSubscriptionScopeReturns = struct {
+		result1 armmonitor.SubscriptionScopeMetricResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) ListAtSubscriptionScopeReturnsOnCall(i int, result1 armmonitor.SubscriptionScopeMetricResponse, result2 error) {
+	fake.listAtSubscriptionScopeMutex.Lock()
+	defer fake.listAtSubscriptionScopeMutex.Unlock()
+	fake.ListAtSubscriptionScopeStub = nil
+	if fake.listAtSubscriptionScopeReturnsOnCall == nil {
+		fake.listAtSubscriptionScopeReturnsOnCall = make(map[int]struct {
+			result1 armmonitor.SubscriptionScopeMetricResponse
+			result2 error
+		})
+	}
+	fake.listAtSubscriptionScopeReturnsOnCall[i] = struct {
+		result1 armmonitor.SubscriptionScopeMetricResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeMetrics) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metricsQuerier = new(fakeMetrics)
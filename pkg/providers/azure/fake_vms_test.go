@@ -0,0 +1,115 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package azure
+
+import (
+	"context"
+	"sync"
+
+	armcompute "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+type fakeVMs struct {
+	ListAllStub        func(context.Context) ([]*armcompute.VirtualMachine, error)
+	listAllMutex       sync.RWMutex
+	listAllArgsForCall []struct {
+		arg1 context.Context
+	}
+	listAllReturns struct {
+		result1 []*armcompute.VirtualMachine
+		result2 error
+	}
+	listAllReturnsOnCall map[int]struct {
+		result1 []*armcompute.VirtualMachine
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeVMs) ListAll(arg1 context.Context) ([]*armcompute.VirtualMachine, error) {
+	fake.listAllMutex.Lock()
+	ret, specificReturn := fake.listAllReturnsOnCall[len(fake.listAllArgsForCall)]
+	fake.listAllArgsForCall = append(fake.listAllArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListAllStub
+	fakeReturns := fake.listAllReturns
+	fake.recordInvocation("ListAll", []interface{}{arg1})
+	fake.listAllMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeVMs) ListAllCallCount() int {
+	fake.listAllMutex.RLock()
+	defer fake.listAllMutex.RUnlock()
+	return len(fake.listAllArgsForCall)
+}
+
+func (fake *fakeVMs) ListAllCalls(stub func(context.Context) ([]*armcompute.VirtualMachine, error)) {
+	fake.listAllMutex.Lock()
+	defer fake.listAllMutex.Unlock()
+	fake.ListAllStub = stub
+}
+
+func (fake *fakeVMs) ListAllArgsForCall(i int) context.Context {
+	fake.listAllMutex.RLock()
+	defer fake.listAllMutex.RUnlock()
+	argsForCall := fake.listAllArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *fakeVMs) ListAllReturns(result1 []*armcompute.VirtualMachine, result2 error) {
+	fake.listAllMutex.Lock()
+	defer fake.listAllMutex.Unlock()
+	fake.ListAllStub = nil
+	fake.listAllReturns = struct {
+		result1 []*armcompute.VirtualMachine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeVMs) ListAllReturnsOnCall(i int, result1 []*armcompute.VirtualMachine, result2 error) {
+	fake.listAllMutex.Lock()
+	defer fake.listAllMutex.Unlock()
+	fake.ListAllStub = nil
+	if fake.listAllReturnsOnCall == nil {
+		fake.listAllReturnsOnCall = make(map[int]struct {
+			result1 []*armcompute.VirtualMachine
+			result2 error
+		})
+	}
+	fake.listAllReturnsOnCall[i] = struct {
+		result1 []*armcompute.VirtualMachine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeVMs) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeVMs) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ vmLister = new(fakeVMs)
@@ -0,0 +1,41 @@
+package azure
+
+// The fakes of the Azure APIs used by the tests
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.13.0 -generate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+const provider = v1.Azure
+const service = "Microsoft.Compute/virtualMachines"
+
+// API families, used for rate limiting
+const (
+	computeAPI = "compute"
+	monitorAPI = "monitor"
+)
+
+func init() {
+	// The default quotas of the requests per minute of a subscription: the
+	// reads of the Resource Manager and the queries of Azure Monitor
+	util.SetDefaultQuota(provider, computeAPI, 12000/60)
+	util.SetDefaultQuota(provider, monitorAPI, 12000/60)
+}
+
+// throttled marks the errors of the requests rejected by the Azure APIs
+// because of their rate with v1.ErrProviderThrottled
+func throttled(err error) error {
+	var rerr *azcore.ResponseError
+	if errors.As(err, &rerr) && rerr.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", v1.ErrProviderThrottled, err)
+	}
+
+	return err
+}
@@ -0,0 +1,242 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestClients overwrites the Azure clients with fakes
+func withTestClients(vms vmLister, sizes sizeLister, metrics metricsQuerier) options {
+	return func(c *Client) {
+		c.vms = vms
+		c.sizes = sizes
+		c.metrics = metrics
+	}
+}
+
+// vm returns a virtual machine of the subscription in its power state
+func vm(name, size, state string) *armcompute.VirtualMachine {
+	return &armcompute.VirtualMachine{
+		ID:       to.Ptr("/subscriptions/demo/resourceGroups/Web/providers/Microsoft.Compute/virtualMachines/" + name),
+		Name:     to.Ptr(name),
+		Location: to.Ptr("westeurope"),
+		Zones:    []*string{to.Ptr("2")},
+		Tags:     map[string]*string{"team": to.Ptr("checkout")},
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{
+				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(size)),
+			},
+			Priority: to.Ptr(armcompute.VirtualMachinePriorityTypesSpot),
+			InstanceView: &armcompute.VirtualMachineInstanceView{
+				Statuses: []*armcompute.InstanceViewStatus{
+					{Code: to.Ptr("ProvisioningState/succeeded")},
+					{Code: to.Ptr("PowerState/" + state)},
+				},
+			},
+		},
+	}
+}
+
+// series returns the CPU utilization of a virtual machine every minute
+// from the start
+func series(id string, start time.Time, values ...float64) *armmonitor.TimeSeriesElement {
+	ts := &armmonitor.TimeSeriesElement{
+		Metadatavalues: []*armmonitor.MetadataValue{
+			{Name: &armmonitor.LocalizableString{Value: to.Ptr("Microsoft.ResourceId")}, Value: to.Ptr(id)},
+		},
+	}
+	for i, v := range values {
+		ts.Data = append(ts.Data, &armmonitor.MetricValue{
+			TimeStamp: to.Ptr(start.Add(time.Duration(i) * time.Minute)),
+			Average:   to.Ptr(v),
+		})
+	}
+	return ts
+}
+
+func TestScrape(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	window := v1.NewWindow(end, 5*time.Minute)
+
+	vms := &fakeVMs{}
+	vms.ListAllReturns([]*armcompute.VirtualMachine{
+		vm("web", "Standard_D2s_v5", "running"),
+		vm("stopped", "Standard_D2s_v5", "deallocated"),
+	}, nil)
+
+	sizes := &fakeSizes{}
+	sizes.ListReturns([]*armcompute.VirtualMachineSize{
+		{Name: to.Ptr("Standard_D2s_v5"), NumberOfCores: to.Ptr(int32(2))},
+	}, nil)
+
+	metrics := &fakeMetrics{}
+	metrics.ListAtSubscriptionScopeReturns(armmonitor.SubscriptionScopeMetricResponse{
+		Value: []*armmonitor.SubscriptionScopeMetric{
+			{
+				Timeseries: []*armmonitor.TimeSeriesElement{
+					// Azure Monitor lowers the case of the resource IDs
+					series("/subscriptions/demo/resourcegroups/web/providers/microsoft.compute/virtualmachines/web", window.Start, 10, 20, 30, 40, 50),
+					// not running
+					series("/subscriptions/demo/resourcegroups/web/providers/microsoft.compute/virtualmachines/stopped", window.Start, 10),
+				},
+			},
+		},
+	}, nil)
+
+	account := &config.Account{Subscription: "demo"}
+	c, err := New(ctx, account, withTestClients(vms, sizes, metrics))
+	assert.NoError(err)
+
+	events := make(chan v1.Instance, 2)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, bustest.Collector(events))
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{Client: c, account: account.ID(), Bus: b}
+	defer s.Stop(ctx)
+
+	n, err := s.Scrape(ctx, window)
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	// the region is queried at once for the running virtual machines
+	assert.Equal(1, metrics.ListAtSubscriptionScopeCallCount())
+	_, region, opts := metrics.ListAtSubscriptionScopeArgsForCall(0)
+	assert.Equal("westeurope", region)
+	assert.Equal("Percentage CPU", *opts.Metricnames)
+	assert.Equal("2024-01-01T00:00:00Z/2024-01-01T00:05:00Z", *opts.Timespan)
+	assert.Equal(int32(1), *opts.Top)
+
+	i := <-events
+	assert.Equal("web", i.Name)
+	assert.Equal(v1.Azure, i.Provider)
+	assert.Equal("Standard_D2s_v5", i.Kind)
+	assert.Equal("westeurope", i.Region)
+	assert.Equal("westeurope-2", i.Zone)
+	assert.Equal("x86_64", i.Architecture)
+	assert.Equal("demo", i.Labels[v1.AccountLabel])
+	assert.Equal("web", i.Labels[v1.NameLabel])
+	assert.Equal("Spot", i.Labels["Lifecycle"])
+	assert.Equal("checkout", i.Labels["tag_team"])
+	assert.Equal(30.0, i.Metrics[v1.CPU.String()].Usage)
+	assert.Equal(2.0, i.Metrics[v1.CPU.String()].UnitAmount)
+	assert.Equal("1m", i.Metrics[v1.CPU.String()].Labels[util.ResolutionLabel])
+	assert.True(end.Equal(i.Metrics[v1.CPU.String()].UpdatedAt))
+
+	// the sizes are cached
+	_, err = s.Scrape(ctx, window)
+	assert.NoError(err)
+	<-events
+	assert.Equal(1, sizes.ListCallCount())
+
+	// a failing API fails the scrape
+	vms.ListAllReturns(nil, errors.New("authorization failed"))
+	_, err = s.Scrape(ctx, window)
+	assert.ErrorContains(err, "authorization failed")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background(), &config.Account{})
+	require.Error(t, err)
+}
+
+func TestArchitecture(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("x86_64", architecture("Standard_D4s_v5"))
+	assert.Equal("x86_64", architecture("Standard_E4-2ds_v4"))
+	assert.Equal("x86_64", architecture("Standard_NP10s"))
+	assert.Equal("arm64", architecture("Standard_D4ps_v5"))
+	assert.Equal("arm64", architecture("Standard_E8pds_v6"))
+	assert.Equal("", architecture(""))
+}
+
+func TestThrottled(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+	}{
+		{
+			name:      "too many requests",
+			err:       fmt.Errorf("listing: %w", &azcore.ResponseError{StatusCode: http.StatusTooManyRequests}),
+			throttled: true,
+		},
+		{
+			name: "forbidden",
+			err:  &azcore.ResponseError{StatusCode: http.StatusForbidden},
+		},
+		{
+			name: "other",
+			err:  errors.New("failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := throttled(test.err)
+			assert.ErrorIs(err, test.err)
+			assert.Equal(test.throttled, errors.Is(err, v1.ErrProviderThrottled))
+		})
+	}
+
+	require.NoError(t, throttled(nil))
+}
+
+func TestCheck(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	vms := &fakeVMs{}
+	vms.ListAllReturns([]*armcompute.VirtualMachine{vm("web", "Standard_D2s_v5", "running")}, nil)
+
+	sizes := &fakeSizes{}
+
+	metrics := &fakeMetrics{}
+	metrics.ListAtSubscriptionScopeReturns(armmonitor.SubscriptionScopeMetricResponse{}, &azcore.ResponseError{
+		StatusCode: http.StatusForbidden,
+		ErrorCode:  "AuthorizationFailed",
+	})
+
+	c, err := New(ctx, &config.Account{Subscription: "demo"}, withTestClients(vms, sizes, metrics))
+	assert.NoError(err)
+
+	permissions := c.Check(ctx)
+	assert.Len(permissions, 3)
+	assert.Equal(v1.Permission{Name: "Microsoft.Compute/virtualMachines/read", Granted: true}, permissions[0])
+	assert.Equal(v1.Permission{Name: "Microsoft.Compute/locations/vmSizes/read", Granted: true}, permissions[1])
+	assert.Equal("Microsoft.Insights/metrics/read", permissions[2].Name)
+	assert.False(permissions[2].Granted)
+	assert.Contains(permissions[2].Error, "AuthorizationFailed")
+
+	// the region of the virtual machines is checked when none is set
+	_, region := sizes.ListArgsForCall(0)
+	assert.Equal("westeurope", region)
+	_, _, opts := metrics.ListAtSubscriptionScopeArgsForCall(0)
+	assert.Equal(int32(1), *opts.Top)
+
+	// nothing is scraped without a virtual machine
+	vms.ListAllReturns(nil, nil)
+	assert.Len(c.Check(ctx), 1)
+}
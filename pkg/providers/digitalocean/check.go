@@ -0,0 +1,29 @@
+package digitalocean
+
+import (
+	"context"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Check verifies the scopes of the API token the scrapes need. The metrics
+// are queried for a droplet of the account, they can't be checked without
+// one
+func (c *Client) Check(ctx context.Context) []v1.Permission {
+	droplets, err := c.droplets.ListDroplets(ctx)
+	permissions := []v1.Permission{util.Permission("droplet:read", err)}
+	if err != nil || len(droplets) == 0 {
+		return permissions
+	}
+
+	end := time.Now().UTC().Truncate(time.Minute)
+	_, err = c.metrics.GetDropletCPU(ctx, &godo.DropletMetricsRequest{
+		HostID: newVM(&droplets[0]).id,
+		Start:  end.Add(-samplePeriod),
+		End:    end,
+	})
+	return append(permissions, util.Permission("monitoring:read", err))
+}
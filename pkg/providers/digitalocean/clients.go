@@ -0,0 +1,82 @@
+package digitalocean
+
+import (
+	"context"
+
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/metrics"
+	"github.com/re-cinq/aether/pkg/providers/util"
+)
+
+// The size of the pages of the droplets, the largest the API allows
+const pageSize = 200
+
+// dropletLister lists the droplets of the account, the pages of the results
+// are read before returning
+//
+//counterfeiter:generate -o fake_droplets_test.go -fake-name fakeDroplets . dropletLister
+type dropletLister interface {
+	ListDroplets(ctx context.Context) ([]godo.Droplet, error)
+}
+
+// metricsQuerier queries the metrics of a droplet reported by its metrics
+// agent
+//
+//counterfeiter:generate -o fake_metrics_test.go -fake-name fakeMetrics . metricsQuerier
+type metricsQuerier interface {
+	GetDropletCPU(ctx context.Context, request *godo.DropletMetricsRequest) ([]metrics.SampleStream, error)
+	GetDropletAvailableMemory(ctx context.Context, request *godo.DropletMetricsRequest) ([]metrics.SampleStream, error)
+}
+
+// dropletsClient lists the droplets with the DigitalOcean API
+type dropletsClient struct {
+	client *godo.Client
+}
+
+// ListDroplets returns the droplets of every page, a request is made per
+// page
+func (c *dropletsClient) ListDroplets(ctx context.Context) ([]godo.Droplet, error) {
+	var droplets []godo.Droplet
+
+	opt := &godo.ListOptions{Page: 1, PerPage: pageSize}
+	for {
+		page, resp, err := c.client.Droplets.List(ctx, opt)
+		util.RecordAPICall(provider, dropletsAPI, "ListDroplets", err)
+		if err != nil {
+			return nil, err
+		}
+
+		droplets = append(droplets, page...)
+
+		if resp.Links == nil || resp.Links.IsLastPage() {
+			return droplets, nil
+		}
+		opt.Page++
+	}
+}
+
+// monitoringClient queries the metrics with the Monitoring API
+type monitoringClient struct {
+	client *godo.Client
+}
+
+// GetDropletCPU returns the CPU time of the droplet by mode
+func (c *monitoringClient) GetDropletCPU(ctx context.Context, request *godo.DropletMetricsRequest) ([]metrics.SampleStream, error) {
+	resp, _, err := c.client.Monitoring.GetDropletCPU(ctx, request)
+	util.RecordAPICall(provider, monitoringAPI, "GetDropletCPU", err)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data.Result, nil
+}
+
+// GetDropletAvailableMemory returns the memory available to the droplet in
+// bytes
+func (c *monitoringClient) GetDropletAvailableMemory(ctx context.Context, request *godo.DropletMetricsRequest) ([]metrics.SampleStream, error) {
+	resp, _, err := c.client.Monitoring.GetDropletAvailableMemory(ctx, request)
+	util.RecordAPICall(provider, monitoringAPI, "GetDropletAvailableMemory", err)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data.Result, nil
+}
@@ -0,0 +1,357 @@
+package digitalocean
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/metrics"
+	cache "github.com/patrickmn/go-cache"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/relabel"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"golang.org/x/oauth2"
+)
+
+// samplePeriod is the resolution of the metrics of the droplets
+const samplePeriod = time.Minute
+
+// The environment variables of the API token, the one of the Terraform
+// provider and the one of doctl
+var tokenVariables = []string{"DIGITALOCEAN_TOKEN", "DIGITALOCEAN_ACCESS_TOKEN"}
+
+// The modes of the CPU time which isn't used by the droplet: idle, waiting
+// for I/O or stolen by the hypervisor
+var idleModes = []string{"idle", "iowait", "steal"}
+
+// The key of the droplets in the cache
+const dropletsKey = "droplets"
+
+// Client is the structure used as the provider for DigitalOcean
+type Client struct {
+	// DigitalOcean clients, faked by the tests
+	droplets dropletLister
+	metrics  metricsQuerier
+
+	// The regions scraped, all of them when empty
+	regions []string
+
+	// The active droplets
+	cache *cache.Cache
+}
+
+type options func(*Client)
+
+// vm is an active droplet and the resources of its size
+type vm struct {
+	instance v1.Instance
+	id       string
+	vCPU     float64
+	memoryGB float64
+}
+
+// New returns a new instance of the DigitalOcean provider for the droplets
+// of the account, authenticated with its API token
+func New(ctx context.Context, account *config.Account, opts ...options) (*Client, error) {
+	c := &Client{
+		regions: account.Regions,
+		cache:   cache.New(time.Hour, time.Hour),
+	}
+
+	// overwrite any options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.droplets != nil && c.metrics != nil {
+		return c, nil
+	}
+
+	token, err := apiToken(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var clientOpts []godo.ClientOpt
+	if endpoint := account.Endpoints["api"]; endpoint != "" {
+		clientOpts = append(clientOpts, godo.SetBaseURL(endpoint))
+	}
+
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	client, err := godo.New(httpClient, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating the DigitalOcean client: %w", err)
+	}
+
+	if c.droplets == nil {
+		c.droplets = &dropletsClient{client: client}
+	}
+	if c.metrics == nil {
+		c.metrics = &monitoringClient{client: client}
+	}
+
+	return c, nil
+}
+
+// apiToken returns the API token of the account: the content of the first
+// file of its credentials, e.g. a mounted secret, or the one of the
+// environment otherwise
+func apiToken(account *config.Account) (string, error) {
+	if len(account.Credentials.FilePaths) > 0 {
+		b, err := os.ReadFile(account.Credentials.FilePaths[0])
+		if err != nil {
+			return "", fmt.Errorf("failed reading the DigitalOcean token: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	for _, name := range tokenVariables {
+		if token := os.Getenv(name); token != "" {
+			return token, nil
+		}
+	}
+
+	return "", fmt.Errorf("no DigitalOcean token, set %s", strings.Join(tokenVariables, " or "))
+}
+
+// Refresh fetches the active droplets of the regions and caches them for
+// the metrics collection
+func (c *Client) Refresh(ctx context.Context) error {
+	if err := util.WaitForAPI(ctx, provider, dropletsAPI); err != nil {
+		return err
+	}
+
+	droplets, err := c.droplets.ListDroplets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed listing the droplets: %w", err)
+	}
+
+	vms := make([]vm, 0, len(droplets))
+	for i := range droplets {
+		d := &droplets[i]
+		if d.Status != "active" {
+			continue
+		}
+		if len(c.regions) > 0 && (d.Region == nil || !slices.Contains(c.regions, d.Region.Slug)) {
+			continue
+		}
+		vms = append(vms, newVM(d))
+	}
+
+	// the droplets destroyed in the meantime are dropped with the previous
+	// list
+	c.cache.Set(dropletsKey, vms, cache.DefaultExpiration)
+	return nil
+}
+
+// GetMetricsForInstances returns the CPU and memory utilization of the
+// cached droplets over the window. The metrics are queried by droplet,
+// concurrently, the droplets without the metrics agent aren't returned
+func (c *Client) GetMetricsForInstances(ctx context.Context, window v1.Window) ([]v1.Instance, error) {
+	cached, ok := c.cache.Get(dropletsKey)
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		instances []v1.Instance
+		mu        sync.Mutex
+	)
+
+	err := util.ForEach(ctx, cached.([]vm), func(ctx context.Context, vm vm) error {
+		i, ok, err := c.dropletMetrics(ctx, &vm, window)
+		if err != nil {
+			return fmt.Errorf("failed getting the metrics of droplet %s: %w", vm.id, err)
+		}
+		if !ok {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		instances = append(instances, i)
+		return nil
+	})
+
+	return instances, err
+}
+
+// dropletMetrics returns the droplet with its CPU and memory utilization
+// over the window, false when it has no CPU metrics
+func (c *Client) dropletMetrics(ctx context.Context, vm *vm, window v1.Window) (v1.Instance, bool, error) {
+	// the CPU time is a counter, the sample before the window is needed
+	// for the utilization of its first minute
+	req := &godo.DropletMetricsRequest{
+		HostID: vm.id,
+		Start:  window.Start.Add(-samplePeriod).UTC(),
+		End:    window.End.UTC(),
+	}
+
+	if err := util.WaitForAPI(ctx, provider, monitoringAPI); err != nil {
+		return v1.Instance{}, false, err
+	}
+	series, err := c.metrics.GetDropletCPU(ctx, req)
+	if err != nil {
+		return v1.Instance{}, false, err
+	}
+
+	cpu := utilization(series)
+	resolution := util.Resolution(cpu, samplePeriod)
+	usage, observed, ok := util.Align(cpu, resolution, window)
+	if !ok {
+		return v1.Instance{}, false, nil
+	}
+
+	i := vm.instance
+	i.Metrics = v1.Metrics{}
+
+	m := v1.NewMetric(v1.CPU.String())
+	m.Unit = v1.VCPU
+	m.ResourceType = v1.CPU
+	m.Usage = usage
+	m.UnitAmount = vm.vCPU
+	m.UpdatedAt = window.End.UTC()
+	m.Observed = observed
+	m.Labels = v1.Labels{
+		util.ResolutionLabel: util.FormatResolution(resolution),
+	}
+	i.Metrics.Upsert(m)
+
+	if vm.memoryGB <= 0 {
+		return i, true, nil
+	}
+
+	req.Start = window.Start.UTC()
+	if err := util.WaitForAPI(ctx, provider, monitoringAPI); err != nil {
+		return v1.Instance{}, false, err
+	}
+	series, err = c.metrics.GetDropletAvailableMemory(ctx, req)
+	if err != nil {
+		return v1.Instance{}, false, err
+	}
+
+	used := usedMemory(series, vm.memoryGB)
+	resolution = util.Resolution(used, samplePeriod)
+	if usage, observed, ok := util.Align(used, resolution, window); ok {
+		m := v1.NewMetric(v1.Memory.String())
+		m.Unit = v1.GB
+		m.ResourceType = v1.Memory
+		m.Usage = usage
+		m.UnitAmount = vm.memoryGB
+		m.UpdatedAt = window.End.UTC()
+		m.Observed = observed
+		m.Labels = v1.Labels{
+			util.ResolutionLabel: util.FormatResolution(resolution),
+		}
+		i.Metrics.Upsert(m)
+	}
+
+	return i, true, nil
+}
+
+// utilization returns the CPU utilization in % between the consecutive
+// samples of the CPU time of the modes, the CPU time is summed over the
+// vCPUs. A sample starts at the first of the two, the decreasing CPU times
+// of a reboot are skipped
+func utilization(series []metrics.SampleStream) []util.Sample {
+	total := make(map[metrics.Time]float64)
+	idle := make(map[metrics.Time]float64)
+	for _, s := range series {
+		isIdle := slices.Contains(idleModes, string(s.Metric["mode"]))
+		for _, p := range s.Values {
+			total[p.Timestamp] += float64(p.Value)
+			if isIdle {
+				idle[p.Timestamp] += float64(p.Value)
+			}
+		}
+	}
+
+	times := make([]metrics.Time, 0, len(total))
+	for t := range total {
+		times = append(times, t)
+	}
+	slices.Sort(times)
+
+	var samples []util.Sample
+	for n := 1; n < len(times); n++ {
+		prev, t := times[n-1], times[n]
+
+		spent := total[t] - total[prev]
+		unused := idle[t] - idle[prev]
+		if spent <= 0 || unused < 0 || unused > spent {
+			continue
+		}
+
+		samples = append(samples, util.Sample{
+			Time:  prev.Time().UTC(),
+			Value: 100 * (spent - unused) / spent,
+		})
+	}
+
+	return samples
+}
+
+// usedMemory returns the memory used in GB from the samples of the memory
+// available in bytes
+func usedMemory(series []metrics.SampleStream, memoryGB float64) []util.Sample {
+	var samples []util.Sample
+	for _, s := range series {
+		for _, p := range s.Values {
+			available := float64(p.Value) / (1 << 30)
+			samples = append(samples, util.Sample{
+				Time:  p.Timestamp.Time().UTC(),
+				Value: max(memoryGB-available, 0),
+			})
+		}
+	}
+
+	slices.SortFunc(samples, func(a, b util.Sample) int {
+		return a.Time.Compare(b.Time)
+	})
+	return samples
+}
+
+// newVM returns an active droplet and the resources of its size
+func newVM(d *godo.Droplet) vm {
+	id := strconv.Itoa(d.ID)
+
+	// the droplets are named after their ID, their name isn't unique
+	i := v1.NewInstance(id, provider)
+	i.Service = service
+	i.Kind = d.SizeSlug
+	// the droplets all run on x86 hosts
+	i.Architecture = "x86_64"
+	if d.Region != nil {
+		i.Region = d.Region.Slug
+	}
+	if created, err := time.Parse(time.RFC3339, d.Created); err == nil {
+		i.StartedAt = created.UTC()
+	}
+
+	i.Labels[v1.NameLabel] = d.Name
+
+	// the tags of the droplets have no value, the ones in the key:value
+	// format are split
+	for _, tag := range d.Tags {
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok {
+			value = "true"
+		}
+		i.Labels[v1.TagLabelPrefix+relabel.LabelName(key)] = value
+	}
+
+	return vm{
+		instance: *i,
+		id:       id,
+		vCPU:     float64(d.Vcpus),
+		// the memory of the sizes is in MiB
+		memoryGB: float64(d.Memory) / 1024,
+	}
+}
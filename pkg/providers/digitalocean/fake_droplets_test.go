@@ -0,0 +1,115 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package digitalocean
+
+import (
+	"context"
+	"sync"
+
+	"github.com/digitalocean/godo"
+)
+
+type fakeDroplets struct {
+	ListDropletsStub        func(context.Context) ([]godo.Droplet, error)
+	listDropletsMutex       sync.RWMutex
+	listDropletsArgsForCall []struct {
+		arg1 context.Context
+	}
+	listDropletsReturns struct {
+		result1 []godo.Droplet
+		result2 error
+	}
+	listDropletsReturnsOnCall map[int]struct {
+		result1 []godo.Droplet
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeDroplets) ListDroplets(arg1 context.Context) ([]godo.Droplet, error) {
+	fake.listDropletsMutex.Lock()
+	ret, specificReturn := fake.listDropletsReturnsOnCall[len(fake.listDropletsArgsForCall)]
+	fake.listDropletsArgsForCall = append(fake.listDropletsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListDropletsStub
+	fakeReturns := fake.listDropletsReturns
+	fake.recordInvocation("ListDroplets", []interface{}{arg1})
+	fake.listDropletsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeDroplets) ListDropletsCallCount() int {
+	fake.listDropletsMutex.RLock()
+	defer fake.listDropletsMutex.RUnlock()
+	return len(fake.listDropletsArgsForCall)
+}
+
+func (fake *fakeDroplets) ListDropletsCalls(stub func(context.Context) ([]godo.Droplet, error)) {
+	fake.listDropletsMutex.Lock()
+	defer fake.listDropletsMutex.Unlock()
+	fake.ListDropletsStub = stub
+}
+
+func (fake *fakeDroplets) ListDropletsArgsForCall(i int) context.Context {
+	fake.listDropletsMutex.RLock()
+	defer fake.listDropletsMutex.RUnlock()
+	argsForCall := fake.listDropletsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *fakeDroplets) ListDropletsReturns(result1 []godo.Droplet, result2 error) {
+	fake.listDropletsMutex.Lock()
+	defer fake.listDropletsMutex.Unlock()
+	fake.ListDropletsStub = nil
+	fake.listDropletsReturns = struct {
+		result1 []godo.Droplet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeDroplets) ListDropletsReturnsOnCall(i int, result1 []godo.Droplet, result2 error) {
+	fake.listDropletsMutex.Lock()
+	defer fake.listDropletsMutex.Unlock()
+	fake.ListDropletsStub = nil
+	if fake.listDropletsReturnsOnCall == nil {
+		fake.listDropletsReturnsOnCall = make(map[int]struct {
+			result1 []godo.Droplet
+			result2 error
+		})
+	}
+	fake.listDropletsReturnsOnCall[i] = struct {
+		result1 []godo.Droplet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeDroplets) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeDroplets) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ dropletLister = new(fakeDroplets)
@@ -0,0 +1,197 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package digitalocean
+
+import (
+	"context"
+	"sync"
+
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/metrics"
+)
+
+type fakeMetrics struct {
+	GetDropletAvailableMemoryStub        func(context.Context, *godo.DropletMetricsRequest) ([]metrics.SampleStream, error)
+	getDropletAvailableMemoryMutex       sync.RWMutex
+	getDropletAvailableMemoryArgsForCall []struct {
+		arg1 context.Context
+		arg2 *godo.DropletMetricsRequest
+	}
+	getDropletAvailableMemoryReturns struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}
+	getDropletAvailableMemoryReturnsOnCall map[int]struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}
+	GetDropletCPUStub        func(context.Context, *godo.DropletMetricsRequest) ([]metrics.SampleStream, error)
+	getDropletCPUMutex       sync.RWMutex
+	getDropletCPUArgsForCall []struct {
+		arg1 context.Context
+		arg2 *godo.DropletMetricsRequest
+	}
+	getDropletCPUReturns struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}
+	getDropletCPUReturnsOnCall map[int]struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeMetrics) GetDropletAvailableMemory(arg1 context.Context, arg2 *godo.DropletMetricsRequest) ([]metrics.SampleStream, error) {
+	fake.getDropletAvailableMemoryMutex.Lock()
+	ret, specificReturn := fake.getDropletAvailableMemoryReturnsOnCall[len(fake.getDropletAvailableMemoryArgsForCall)]
+	fake.getDropletAvailableMemoryArgsForCall = append(fake.getDropletAvailableMemoryArgsForCall, struct {
+		arg1 context.Context
+		arg2 *godo.DropletMetricsRequest
+	}{arg1, arg2})
+	stub := fake.GetDropletAvailableMemoryStub
+	fakeReturns := fake.getDropletAvailableMemoryReturns
+	fake.recordInvocation("GetDropletAvailableMemory", []interface{}{arg1, arg2})
+	fake.getDropletAvailableMemoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeMetrics) GetDropletAvailableMemoryCallCount() int {
+	fake.getDropletAvailableMemoryMutex.RLock()
+	defer fake.getDropletAvailableMemoryMutex.RUnlock()
+	return len(fake.getDropletAvailableMemoryArgsForCall)
+}
+
+func (fake *fakeMetrics) GetDropletAvailableMemoryCalls(stub func(context.Context, *godo.DropletMetricsRequest) ([]metrics.SampleStream, error)) {
+	fake.getDropletAvailableMemoryMutex.Lock()
+	defer fake.getDropletAvailableMemoryMutex.Unlock()
+	fake.GetDropletAvailableMemoryStub = stub
+}
+
+func (fake *fakeMetrics) GetDropletAvailableMemoryArgsForCall(i int) (context.Context, *godo.DropletMetricsRequest) {
+	fake.getDropletAvailableMemoryMutex.RLock()
+	defer fake.getDropletAvailableMemoryMutex.RUnlock()
+	argsForCall := fake.getDropletAvailableMemoryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeMetrics) GetDropletAvailableMemoryReturns(result1 []metrics.SampleStream, result2 error) {
+	fake.getDropletAvailableMemoryMutex.Lock()
+	defer fake.getDropletAvailableMemoryMutex.Unlock()
+	fake.GetDropletAvailableMemoryStub = nil
+	fake.getDropletAvailableMemoryReturns = struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) GetDropletAvailableMemoryReturnsOnCall(i int, result1 []metrics.SampleStream, result2 error) {
+	fake.getDropletAvailableMemoryMutex.Lock()
+	defer fake.getDropletAvailableMemoryMutex.Unlock()
+	fake.GetDropletAvailableMemoryStub = nil
+	if fake.getDropletAvailableMemoryReturnsOnCall == nil {
+		fake.getDropletAvailableMemoryReturnsOnCall = make(map[int]struct {
+			result1 []metrics.SampleStream
+			result2 error
+		})
+	}
+	fake.getDropletAvailableMemoryReturnsOnCall[i] = struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) GetDropletCPU(arg1 context.Context, arg2 *godo.DropletMetricsRequest) ([]metrics.SampleStream, error) {
+	fake.getDropletCPUMutex.Lock()
+	ret, specificReturn := fake.getDropletCPUReturnsOnCall[len(fake.getDropletCPUArgsForCall)]
+	fake.getDropletCPUArgsForCall = append(fake.getDropletCPUArgsForCall, struct {
+		arg1 context.Context
+		arg2 *godo.DropletMetricsRequest
+	}{arg1, arg2})
+	stub := fake.GetDropletCPUStub
+	fakeReturns := fake.getDropletCPUReturns
+	fake.recordInvocation("GetDropletCPU", []interface{}{arg1, arg2})
+	fake.getDropletCPUMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeMetrics) GetDropletCPUCallCount() int {
+	fake.getDropletCPUMutex.RLock()
+	defer fake.getDropletCPUMutex.RUnlock()
+	return len(fake.getDropletCPUArgsForCall)
+}
+
+func (fake *fakeMetrics) GetDropletCPUCalls(stub func(context.Context, *godo.DropletMetricsRequest) ([]metrics.SampleStream, error)) {
+	fake.getDropletCPUMutex.Lock()
+	defer fake.getDropletCPUMutex.Unlock()
+	fake.GetDropletCPUStub = stub
+}
+
+func (fake *fakeMetrics) GetDropletCPUArgsForCall(i int) (context.Context, *godo.DropletMetricsRequest) {
+	fake.getDropletCPUMutex.RLock()
+	defer fake.getDropletCPUMutex.RUnlock()
+	argsForCall := fake.getDropletCPUArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeMetrics) GetDropletCPUReturns(result1 []metrics.SampleStream, result2 error) {
+	fake.getDropletCPUMutex.Lock()
+	defer fake.getDropletCPUMutex.Unlock()
+	fake.GetDropletCPUStub = nil
+	fake.getDropletCPUReturns = struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) GetDropletCPUReturnsOnCall(i int, result1 []metrics.SampleStream, result2 error) {
+	fake.getDropletCPUMutex.Lock()
+	defer fake.getDropletCPUMutex.Unlock()
+	fake.GetDropletCPUStub = nil
+	if fake.getDropletCPUReturnsOnCall == nil {
+		fake.getDropletCPUReturnsOnCall = make(map[int]struct {
+			result1 []metrics.SampleStream
+			result2 error
+		})
+	}
+	fake.getDropletCPUReturnsOnCall[i] = struct {
+		result1 []metrics.SampleStream
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeMetrics) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metricsQuerier = new(fakeMetrics)
@@ -0,0 +1,33 @@
+package digitalocean
+
+// The fakes of the DigitalOcean APIs used by the tests
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.13.0 -generate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/digitalocean/godo"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+const provider = v1.DigitalOcean
+const service = "Droplets"
+
+// API families, used for rate limiting
+const (
+	dropletsAPI   = "droplets"
+	monitoringAPI = "monitoring"
+)
+
+// throttled marks the errors of the requests rejected by the DigitalOcean
+// API because of their rate with v1.ErrProviderThrottled
+func throttled(err error) error {
+	var rerr *godo.ErrorResponse
+	if errors.As(err, &rerr) && rerr.Response != nil && rerr.Response.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", v1.ErrProviderThrottled, err)
+	}
+
+	return err
+}
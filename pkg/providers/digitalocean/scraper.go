@@ -0,0 +1,95 @@
+package digitalocean
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Scraper is used to handle scraping the droplets of a DigitalOcean account
+type Scraper struct {
+	*Client
+
+	// The account identifier
+	account string
+
+	// Event bus for publishing
+	Bus *bus.Bus
+
+	logger *slog.Logger
+}
+
+// NewScraper returns a DigitalOcean scraper configured for the droplets of
+// the account and populates its cache
+func NewScraper(ctx context.Context, b *bus.Bus, account *config.Account) (v1.Scraper, error) {
+	logger := log.FromContext(ctx)
+
+	c, err := New(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	// this is where we populate the cache
+	if err := c.Refresh(ctx); err != nil {
+		logger.Error("error refreshing cache for account", "account", account.ID(), "error", err)
+	}
+
+	return &Scraper{
+		Client:  c,
+		account: account.ID(),
+		Bus:     b,
+		logger:  logger,
+	}, nil
+}
+
+// Provider returns the provider the scraper is collecting data from
+func (s *Scraper) Provider() v1.Provider {
+	return provider
+}
+
+// Account returns the identifier of the account being scraped
+func (s *Scraper) Account() string {
+	return s.account
+}
+
+// Scrape refreshes the droplets, collects their metrics and publishes them
+func (s *Scraper) Scrape(ctx context.Context, window v1.Window) (int, error) {
+	if err := s.Client.Refresh(ctx); err != nil {
+		return 0, throttled(err)
+	}
+
+	instances, err := s.Client.GetMetricsForInstances(ctx, window)
+
+	// the droplets collected are published even when some of them failed
+	for i := range instances {
+		instances[i].Labels = instances[i].Labels.With(v1.AccountLabel, s.account)
+
+		e := s.Bus.PublishContext(ctx, &bus.Event{
+			Type: v1.MetricsCollectedEvent,
+			Data: instances[i],
+		})
+		if e != nil {
+			s.logger.Error("failed to publish instance", "instance", instances[i].Name, "error", e)
+		}
+	}
+
+	if err != nil {
+		return len(instances), throttled(fmt.Errorf("failed getting instances: %w", err))
+	}
+
+	return len(instances), nil
+}
+
+// Stop is used to gracefully stop the scrapper
+func (s *Scraper) Stop(ctx context.Context) {}
+
+// Flush drops the cached droplets, they are fetched again by the next
+// scrape
+func (s *Scraper) Flush() {
+	s.Client.cache.Flush()
+}
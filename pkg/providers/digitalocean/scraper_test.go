@@ -0,0 +1,277 @@
+package digitalocean
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/metrics"
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestClients overwrites the DigitalOcean clients with fakes
+func withTestClients(droplets dropletLister, metrics metricsQuerier) options {
+	return func(c *Client) {
+		c.droplets = droplets
+		c.metrics = metrics
+	}
+}
+
+// droplet returns an active droplet of the size
+func droplet(id int, size, region string, vCPU, memoryMB int) godo.Droplet {
+	return godo.Droplet{
+		ID:       id,
+		Name:     "web",
+		Status:   "active",
+		SizeSlug: size,
+		Vcpus:    vCPU,
+		Memory:   memoryMB,
+		Region:   &godo.Region{Slug: region},
+		Created:  "2024-01-01T00:00:00Z",
+		Tags:     []string{"team:checkout", "production"},
+	}
+}
+
+// cpuTime returns the CPU time of a mode, increasing by the amount every
+// minute from the start
+func cpuTime(mode string, start time.Time, increase float64, n int) metrics.SampleStream {
+	s := metrics.SampleStream{
+		Metric: metrics.Metric{"mode": metrics.LabelValue(mode)},
+	}
+	for i := 0; i < n; i++ {
+		s.Values = append(s.Values, metrics.SamplePair{
+			Timestamp: metrics.TimeFromUnix(start.Add(time.Duration(i) * time.Minute).Unix()),
+			Value:     metrics.SampleValue(float64(i) * increase),
+		})
+	}
+	return s
+}
+
+func TestScrape(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	window := v1.NewWindow(end, 5*time.Minute)
+
+	stopped := droplet(3, "s-1vcpu-1gb", "ams3", 1, 1024)
+	stopped.Status = "off"
+
+	droplets := &fakeDroplets{}
+	droplets.ListDropletsReturns([]godo.Droplet{
+		droplet(1, "s-2vcpu-4gb", "ams3", 2, 4096),
+		// no metrics, e.g. without the metrics agent
+		droplet(2, "s-1vcpu-1gb", "ams3", 1, 1024),
+		stopped,
+		droplet(4, "s-1vcpu-1gb", "nyc3", 1, 1024),
+	}, nil)
+
+	// 30s of user time and 90s of idle time every minute over 2 vCPUs
+	start := window.Start.Add(-time.Minute)
+	m := &fakeMetrics{}
+	m.GetDropletCPUStub = func(ctx context.Context, req *godo.DropletMetricsRequest) ([]metrics.SampleStream, error) {
+		if req.HostID != "1" {
+			return nil, nil
+		}
+		return []metrics.SampleStream{
+			cpuTime("user", start, 30, 6),
+			cpuTime("idle", start, 90, 6),
+		}, nil
+	}
+	m.GetDropletAvailableMemoryReturns([]metrics.SampleStream{
+		{
+			Values: []metrics.SamplePair{
+				{Timestamp: metrics.TimeFromUnix(window.Start.Unix()), Value: 1 << 30},
+			},
+		},
+	}, nil)
+
+	c, err := New(ctx, &config.Account{Regions: []string{"ams3"}}, withTestClients(droplets, m))
+	assert.NoError(err)
+
+	events := make(chan v1.Instance, 2)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, bustest.Collector(events))
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{Client: c, account: "demo", Bus: b}
+	defer s.Stop(ctx)
+
+	n, err := s.Scrape(ctx, window)
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	// the active droplets of the region are queried
+	assert.Equal(2, m.GetDropletCPUCallCount())
+	_, req := m.GetDropletCPUArgsForCall(0)
+	assert.True(start.Equal(req.Start))
+	assert.True(end.Equal(req.End))
+
+	i := <-events
+	assert.Equal("1", i.Name)
+	assert.Equal(v1.DigitalOcean, i.Provider)
+	assert.Equal("s-2vcpu-4gb", i.Kind)
+	assert.Equal("ams3", i.Region)
+	assert.Equal("x86_64", i.Architecture)
+	assert.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), i.StartedAt)
+	assert.Equal("demo", i.Labels[v1.AccountLabel])
+	assert.Equal("web", i.Labels[v1.NameLabel])
+	assert.Equal("checkout", i.Labels["tag_team"])
+	assert.Equal("true", i.Labels["tag_production"])
+
+	cpu := i.Metrics[v1.CPU.String()]
+	assert.Equal(25.0, cpu.Usage)
+	assert.Equal(2.0, cpu.UnitAmount)
+	assert.Equal("1m", cpu.Labels[util.ResolutionLabel])
+	assert.True(end.Equal(cpu.UpdatedAt))
+
+	memory := i.Metrics[v1.Memory.String()]
+	assert.Equal(v1.GB, memory.Unit)
+	assert.Equal(3.0, memory.Usage)
+	assert.Equal(4.0, memory.UnitAmount)
+
+	// a failing API fails the scrape
+	droplets.ListDropletsReturns(nil, errors.New("unauthorized"))
+	_, err = s.Scrape(ctx, window)
+	assert.ErrorContains(err, "unauthorized")
+}
+
+func TestUtilization(t *testing.T) {
+	assert := require.New(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the CPU time drops with a reboot
+	user := cpuTime("user", start, 10, 4)
+	user.Values[3].Value = 5
+	samples := utilization([]metrics.SampleStream{
+		user,
+		cpuTime("steal", start, 10, 4),
+		cpuTime("idle", start, 20, 4),
+	})
+
+	assert.Equal([]util.Sample{
+		{Time: start, Value: 25},
+		{Time: start.Add(time.Minute), Value: 25},
+	}, samples)
+
+	assert.Empty(utilization(nil))
+}
+
+func TestCheck(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	droplets := &fakeDroplets{}
+	m := &fakeMetrics{}
+	m.GetDropletCPUReturns(nil, &godo.ErrorResponse{
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Request:    httptest.NewRequest(http.MethodGet, "/v2/monitoring/metrics/droplet/cpu", http.NoBody),
+		},
+		Message: "You are not authorized to perform this operation",
+	})
+
+	c, err := New(ctx, &config.Account{}, withTestClients(droplets, m))
+	assert.NoError(err)
+
+	// the metrics can't be checked without a droplet
+	assert.Equal([]v1.Permission{{Name: "droplet:read", Granted: true}}, c.Check(ctx))
+
+	droplets.ListDropletsReturns([]godo.Droplet{droplet(1, "s-1vcpu-1gb", "ams3", 1, 1024)}, nil)
+	permissions := c.Check(ctx)
+	assert.Len(permissions, 2)
+	assert.Equal("monitoring:read", permissions[1].Name)
+	assert.False(permissions[1].Granted)
+	assert.Contains(permissions[1].Error, "not authorized")
+
+	_, req := m.GetDropletCPUArgsForCall(0)
+	assert.Equal("1", req.HostID)
+}
+
+func TestAPIToken(t *testing.T) {
+	assert := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(os.WriteFile(path, []byte("dop_v1_file\n"), 0o600))
+
+	token, err := apiToken(&config.Account{Credentials: config.ProviderConfig{FilePaths: []string{path}}})
+	assert.NoError(err)
+	assert.Equal("dop_v1_file", token)
+
+	t.Setenv("DIGITALOCEAN_TOKEN", "")
+	t.Setenv("DIGITALOCEAN_ACCESS_TOKEN", "dop_v1_env")
+	token, err = apiToken(&config.Account{})
+	assert.NoError(err)
+	assert.Equal("dop_v1_env", token)
+
+	t.Setenv("DIGITALOCEAN_ACCESS_TOKEN", "")
+	_, err = New(context.Background(), &config.Account{})
+	assert.ErrorContains(err, "no DigitalOcean token")
+}
+
+func TestWriteFactors(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(WriteFactors(dir))
+
+	ef, err := factors.GetProviderEmissionFactors(provider, dir)
+	assert.NoError(err)
+
+	// every size has the wattage profile of its architecture
+	for kind, e := range ef.Embodied {
+		assert.NotZero(e.MaxWatts, kind)
+	}
+	assert.Equal("EPYC 2nd Gen", ef.Embodied["s-2vcpu-4gb-amd"].Architecture)
+	assert.Equal("Cascade Lake", ef.Embodied["c-8-intel"].Architecture)
+	assert.Contains(ef.Coefficient, "fra1")
+	assert.Equal("DE", ef.Locations["fra1"].Country)
+}
+
+func TestThrottled(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+	}{
+		{
+			name:      "too many requests",
+			err:       &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusTooManyRequests}},
+			throttled: true,
+		},
+		{
+			name: "unauthorized",
+			err:  &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}},
+		},
+		{
+			name: "other",
+			err:  errors.New("failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := throttled(test.err)
+			assert.ErrorIs(err, test.err)
+			assert.Equal(test.throttled, errors.Is(err, v1.ErrProviderThrottled))
+		})
+	}
+
+	require.NoError(t, throttled(nil))
+}
@@ -0,0 +1,220 @@
+// Package fakeprovider is a provider of synthetic instances. Its scrapers
+// publish the same instances and usage for the same window, so that the
+// scraping, the calculations and the exporters can be tested end to end
+// without cloud credentials:
+//
+//	factors.RegisterDataPath(fakeprovider.Provider, dir)
+//	fakeprovider.WriteFactors(dir)
+//	scraper.RegisterFactory(fakeprovider.Provider, fakeprovider.Factory)
+package fakeprovider
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Provider is the provider of the synthetic instances
+const Provider v1.Provider = "fake"
+
+// The regions and the kinds of the emission factors of the provider
+const (
+	RegionNorth = "fake-north"
+	RegionSouth = "fake-south"
+
+	Kind2 = "fake-2"
+	Kind4 = "fake-4"
+)
+
+// The emission factors of the provider, in the format of the emissions-data
+// repo
+//
+//go:embed factors/*.yaml
+var factorsFS embed.FS
+
+// WriteFactors writes the emission factors of the provider to dir. The
+// fake-north grid emits 100 gCO2eq/kWh and the fake-south one 400, the
+// vCPUs draw 10 to 20 W with a PUE of 1.5, and the embodied emissions of a
+// vCPU are 1.5 gCO2eq per hour
+func WriteFactors(dir string) error {
+	files, err := fs.Glob(factorsFS, "factors/*.yaml")
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		data, err := factorsFS.ReadFile(f)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(f)), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Instance is a synthetic instance
+type Instance struct {
+	Name   string
+	Region string
+	Kind   string
+
+	// The vCPUs of the instance
+	VCPU float64
+
+	// The memory of the instance in GB, no memory metric is published
+	// when zero
+	Memory float64
+
+	// Usage returns the CPU usage in percent of the window ending at the
+	// time
+	Usage func(time.Time) float64
+}
+
+// Steady is a constant usage
+func Steady(usage float64) func(time.Time) float64 {
+	return func(time.Time) float64 {
+		return usage
+	}
+}
+
+// Diurnal is a usage following the day, from low at 2am to high at 2pm UTC
+func Diurnal(low, high float64) func(time.Time) float64 {
+	return func(t time.Time) float64 {
+		hours := float64(t.UTC().Hour()) + float64(t.UTC().Minute())/60
+		phase := math.Cos(2 * math.Pi * (hours - 14) / 24)
+		return low + (high-low)*(1+phase)/2
+	}
+}
+
+// Scraper publishes synthetic instances
+type Scraper struct {
+	account   string
+	instances []Instance
+
+	bus *bus.Bus
+}
+
+// NewScraper returns the scraper of the instances of the account
+func NewScraper(b *bus.Bus, account string, instances []Instance) *Scraper {
+	return &Scraper{
+		account:   account,
+		instances: instances,
+		bus:       b,
+	}
+}
+
+// Factory creates the scrapers of the accounts of the provider. Every
+// region of the account, fake-north when empty, runs a steady fake-2 web
+// instance and a diurnal fake-4 batch instance
+func Factory(ctx context.Context, b *bus.Bus, account *config.Account) (v1.Scraper, error) {
+	regions := account.Regions
+	if len(regions) == 0 {
+		regions = []string{RegionNorth}
+	}
+
+	var instances []Instance
+	for _, region := range regions {
+		instances = append(instances,
+			Instance{
+				Name:   fmt.Sprintf("%s-%s-web", account.ID(), region),
+				Region: region,
+				Kind:   Kind2,
+				VCPU:   2,
+				Memory: 8,
+				Usage:  Steady(50),
+			},
+			Instance{
+				Name:   fmt.Sprintf("%s-%s-batch", account.ID(), region),
+				Region: region,
+				Kind:   Kind4,
+				VCPU:   4,
+				Memory: 16,
+				Usage:  Diurnal(10, 90),
+			},
+		)
+	}
+
+	return NewScraper(b, account.ID(), instances), nil
+}
+
+// Provider returns the provider the scraper is collecting data from
+func (s *Scraper) Provider() v1.Provider {
+	return Provider
+}
+
+// Account returns the identifier of the account being scraped
+func (s *Scraper) Account() string {
+	return s.account
+}
+
+// Scrape publishes the instances with their usage over the window
+func (s *Scraper) Scrape(ctx context.Context, window v1.Window) (int, error) {
+	for i := range s.instances {
+		instance := s.instance(&s.instances[i], window)
+
+		if err := s.bus.PublishContext(ctx, &bus.Event{
+			Type: v1.MetricsCollectedEvent,
+			Data: *instance,
+		}); err != nil {
+			return i, err
+		}
+	}
+
+	return len(s.instances), nil
+}
+
+// instance returns the instance as collected over the window
+func (s *Scraper) instance(i *Instance, window v1.Window) *v1.Instance {
+	instance := v1.NewInstance(i.Name, Provider)
+	instance.Service = "synthetic"
+	instance.Region = i.Region
+	instance.Zone = i.Region + "-a"
+	instance.Kind = i.Kind
+	instance.Labels.Add(v1.AccountLabel, s.account)
+
+	usage := 0.0
+	if i.Usage != nil {
+		usage = i.Usage(window.End)
+	}
+
+	instance.Metrics.Upsert(&v1.Metric{
+		Name:         v1.CPU.String(),
+		ResourceType: v1.CPU,
+		Usage:        usage,
+		UnitAmount:   i.VCPU,
+		Unit:         v1.VCPU,
+		UpdatedAt:    window.End,
+	})
+
+	if i.Memory > 0 {
+		instance.Metrics.Upsert(&v1.Metric{
+			Name:         v1.Memory.String(),
+			ResourceType: v1.Memory,
+			Usage:        i.Memory * usage / 100,
+			UnitAmount:   i.Memory,
+			Unit:         v1.GB,
+			UpdatedAt:    window.End,
+		})
+	}
+
+	return instance
+}
+
+// Flush does nothing, nothing is cached
+func (s *Scraper) Flush() {}
+
+// Stop does nothing, nothing runs in the background
+func (s *Scraper) Stop(ctx context.Context) {}
@@ -0,0 +1,105 @@
+package fakeprovider_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/exporter"
+	"github.com/re-cinq/aether/pkg/providers/fakeprovider"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/re-cinq/aether/pkg/units"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiurnal(t *testing.T) {
+	assert := require.New(t)
+
+	usage := fakeprovider.Diurnal(10, 90)
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.InDelta(90, usage(day.Add(14*time.Hour)), 1e-9)
+	assert.InDelta(10, usage(day.Add(2*time.Hour)), 1e-9)
+	assert.InDelta(50, usage(day.Add(8*time.Hour)), 1e-9)
+}
+
+// TestPipeline scrapes the synthetic instances and calculates their emissions
+// the way the exporter does
+func TestPipeline(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	assert.NoError(fakeprovider.WriteFactors(dir))
+	factors.RegisterDataPath(fakeprovider.Provider, dir)
+
+	b := bus.New()
+	b.Subscribe(
+		v1.MetricsCollectedEvent,
+		calculator.NewHandler(ctx, b,
+			calculator.WithInterval(5*time.Minute),
+			calculator.WithDataset(calculator.Dataset{Version: "test"}),
+		),
+	)
+	b.Subscribe(v1.EmissionsCalculatedEvent, exporter.NewHandler(ctx, b, units.Output{}, nil, exporter.ModeGauge, 0))
+
+	st, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+	b.Subscribe(v1.EmissionsCalculatedEvent, st)
+
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s, err := fakeprovider.Factory(ctx, b, &config.Account{Name: "demo", Regions: []string{fakeprovider.RegionNorth, fakeprovider.RegionSouth}})
+	assert.NoError(err)
+	assert.Equal(fakeprovider.Provider, s.Provider())
+
+	end := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(4, n)
+
+	var samples []store.Sample
+	assert.Eventually(func() bool {
+		samples = st.Select(end, end.Add(time.Second), nil)
+		return len(samples) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	operational, embodied := map[string]float64{}, map[string]float64{}
+	for _, s := range samples {
+		assert.Equal("demo", s.Labels[v1.AccountLabel])
+		operational[s.Name] = s.Operational
+		embodied[s.Name] = s.Embodied
+	}
+
+	// 15 W per vCPU at 50% and 19 W at 90% over 5 minutes with a PUE of 1.5
+	assert.InDeltaMapValues(map[string]float64{
+		"demo-fake-north-web":   0.375,
+		"demo-fake-north-batch": 0.95,
+		"demo-fake-south-web":   1.5,
+		"demo-fake-south-batch": 3.8,
+	}, operational, 1e-9)
+
+	// 1.5 gCO2eq per vCPU and hour
+	assert.InDeltaMapValues(map[string]float64{
+		"demo-fake-north-web":   0.25,
+		"demo-fake-north-batch": 0.5,
+		"demo-fake-south-web":   0.25,
+		"demo-fake-south-batch": 0.5,
+	}, embodied, 1e-9)
+
+	// and exported, the exporters of the previous runs of the test are still
+	// registered with -count
+	families, _ := prometheus.DefaultGatherer.Gather()
+
+	var exported bool
+	for _, f := range families {
+		exported = exported || f.GetName() == "emissions"
+	}
+	assert.True(exported)
+}
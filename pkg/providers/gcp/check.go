@@ -0,0 +1,47 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// The MQL query of the check of the monitoring API, it matches no instance
+const checkQuery = `fetch gce_instance
+| metric 'compute.googleapis.com/instance/cpu/utilization'
+| filter resource.instance_id == '0'
+| within 1m`
+
+// Check verifies the permissions of the scrapes of the project with calls
+// matching nothing, the sole-tenant nodes are listed when their hosts are
+// accounted for
+func (s *Scraper) Check(ctx context.Context) []v1.Permission {
+	if s.Project == nil {
+		return nil
+	}
+	project := *s.Project
+
+	_, err := s.Client.instances.AggregatedList(ctx, &computepb.AggregatedListInstancesRequest{
+		Project: project,
+		Filter:  proto.String(`name = "aether-permission-check"`),
+	})
+	permissions := []v1.Permission{util.Permission("compute.instances.list", err)}
+
+	_, err = s.Client.monitoring.QueryTimeSeries(ctx, &monitoringpb.QueryTimeSeriesRequest{
+		Name:  fmt.Sprintf("projects/%s", project),
+		Query: checkQuery,
+	})
+	permissions = append(permissions, util.Permission("monitoring.timeSeries.list", err))
+
+	if s.Client.nodes != nil {
+		_, err = s.Client.nodes.ListNodes(ctx, project)
+		permissions = append(permissions, util.Permission("compute.nodeGroups.list", err))
+	}
+
+	return permissions
+}
@@ -0,0 +1,281 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"google.golang.org/api/iterator"
+)
+
+// The fakes of the GCP APIs used by the tests
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.13.0 -generate
+
+// timeSeriesQuerier runs the MQL queries of the scrapers, the pages of the
+// results are read before returning
+//
+//counterfeiter:generate -o fake_monitoring_test.go -fake-name fakeMonitoring . timeSeriesQuerier
+type timeSeriesQuerier interface {
+	QueryTimeSeries(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error)
+	Close() error
+}
+
+// instanceLister lists the instances of a project in all its zones, the
+// pages of the results are read before returning
+//
+//counterfeiter:generate -o fake_instances_test.go -fake-name fakeInstances . instanceLister
+type instanceLister interface {
+	AggregatedList(ctx context.Context, req *computepb.AggregatedListInstancesRequest) ([]*computepb.Instance, error)
+	Close() error
+}
+
+// machineTypeGetter gets the machine types of the zones
+//
+//counterfeiter:generate -o fake_machine_types_test.go -fake-name fakeMachineTypes . machineTypeGetter
+type machineTypeGetter interface {
+	Get(ctx context.Context, req *computepb.GetMachineTypeRequest) (*computepb.MachineType, error)
+	Close() error
+}
+
+// queryClient runs the queries with the monitoring API
+type queryClient struct {
+	*monitoring.QueryClient
+}
+
+// QueryTimeSeries returns the time series of every page, a request is made
+// per page
+func (c *queryClient) QueryTimeSeries(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error) {
+	var (
+		data []*monitoringpb.TimeSeriesData
+		page interface{}
+	)
+
+	it := c.QueryClient.QueryTimeSeries(ctx, req)
+	for {
+		resp, err := it.Next()
+		if err == iterator.Done {
+			// the last page can be empty
+			if it.Response != page {
+				util.RecordAPICall(provider, monitoringAPI, "QueryTimeSeries", nil)
+			}
+			return data, nil
+		}
+		if err != nil {
+			util.RecordAPICall(provider, monitoringAPI, "QueryTimeSeries", err)
+			return nil, err
+		}
+
+		// the response is the one of the last page fetched
+		if it.Response != page {
+			page = it.Response
+			util.RecordAPICall(provider, monitoringAPI, "QueryTimeSeries", nil)
+		}
+
+		data = append(data, resp)
+	}
+}
+
+// instancesClient lists the instances with the compute API
+type instancesClient struct {
+	*compute.InstancesClient
+}
+
+// AggregatedList returns the instances of every page, a request is made per
+// page
+func (c *instancesClient) AggregatedList(ctx context.Context, req *computepb.AggregatedListInstancesRequest) ([]*computepb.Instance, error) {
+	var (
+		instances []*computepb.Instance
+		page      interface{}
+	)
+
+	it := c.InstancesClient.AggregatedList(ctx, req)
+	for {
+		resp, err := it.Next()
+		if err == iterator.Done {
+			// the last page can be empty
+			if it.Response != page {
+				util.RecordAPICall(provider, computeAPI, "AggregatedList", nil)
+			}
+			return instances, nil
+		}
+		if err != nil {
+			util.RecordAPICall(provider, computeAPI, "AggregatedList", err)
+			return nil, err
+		}
+
+		// the response is the one of the last page fetched
+		if it.Response != page {
+			page = it.Response
+			util.RecordAPICall(provider, computeAPI, "AggregatedList", nil)
+		}
+
+		instances = append(instances, resp.Value.GetInstances()...)
+	}
+}
+
+// machineTypesClient gets the machine types with the compute API
+type machineTypesClient struct {
+	*compute.MachineTypesClient
+}
+
+// Get returns the machine type of the zone
+func (c *machineTypesClient) Get(ctx context.Context, req *computepb.GetMachineTypeRequest) (*computepb.MachineType, error) {
+	mt, err := c.MachineTypesClient.Get(ctx, req)
+	util.RecordAPICall(provider, computeAPI, "GetMachineType", err)
+	return mt, err
+}
+
+// soleTenantNode is a node of a sole-tenant node group, a host dedicated to
+// the instances of the project
+type soleTenantNode struct {
+	// The zone and the name of the node group
+	Zone  string
+	Group string
+
+	Node *computepb.NodeGroupNode
+}
+
+// nodeLister lists the sole-tenant nodes of a project in all its zones, the
+// pages of the results are read before returning
+//
+//counterfeiter:generate -o fake_nodes_test.go -fake-name fakeNodes . nodeLister
+type nodeLister interface {
+	ListNodes(ctx context.Context, project string) ([]soleTenantNode, error)
+	Close() error
+}
+
+// nodesClient lists the sole-tenant nodes with the compute API
+type nodesClient struct {
+	*compute.NodeGroupsClient
+}
+
+// ListNodes returns the nodes of every node group of the project, a request
+// is made per page of the node groups and of the nodes of every group
+func (c *nodesClient) ListNodes(ctx context.Context, project string) ([]soleTenantNode, error) {
+	var groups []*computepb.NodeGroup
+
+	it := c.NodeGroupsClient.AggregatedList(ctx, &computepb.AggregatedListNodeGroupsRequest{
+		Project: project,
+	})
+	err := pages(it.Next, func() interface{} { return it.Response }, "AggregatedListNodeGroups", func(pair compute.NodeGroupsScopedListPair) {
+		groups = append(groups, pair.Value.GetNodeGroups()...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []soleTenantNode
+	for _, group := range groups {
+		zone, err := getValueFromURL(group.GetZone())
+		if err != nil {
+			return nil, err
+		}
+
+		it := c.NodeGroupsClient.ListNodes(ctx, &computepb.ListNodesNodeGroupsRequest{
+			Project:   project,
+			Zone:      zone,
+			NodeGroup: group.GetName(),
+		})
+		err = pages(it.Next, func() interface{} { return it.Response }, "ListNodes", func(node *computepb.NodeGroupNode) {
+			nodes = append(nodes, soleTenantNode{
+				Zone:  zone,
+				Group: group.GetName(),
+				Node:  node,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+// imageLister lists the snapshots and the images of a project, the pages of
+// the results are read before returning
+//
+//counterfeiter:generate -o fake_images_test.go -fake-name fakeImages . imageLister
+type imageLister interface {
+	ListSnapshots(ctx context.Context, project string) ([]*computepb.Snapshot, error)
+	ListImages(ctx context.Context, project string) ([]*computepb.Image, error)
+	Close() error
+}
+
+// imagesClient lists the snapshots and the images with the compute API
+type imagesClient struct {
+	snapshots *compute.SnapshotsClient
+	images    *compute.ImagesClient
+}
+
+// ListSnapshots returns the snapshots of every page, a request is made per
+// page
+func (c *imagesClient) ListSnapshots(ctx context.Context, project string) ([]*computepb.Snapshot, error) {
+	var snapshots []*computepb.Snapshot
+
+	it := c.snapshots.List(ctx, &computepb.ListSnapshotsRequest{
+		Project: project,
+	})
+	err := pages(it.Next, func() interface{} { return it.Response }, "ListSnapshots", func(snapshot *computepb.Snapshot) {
+		snapshots = append(snapshots, snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// ListImages returns the images of every page, a request is made per page
+func (c *imagesClient) ListImages(ctx context.Context, project string) ([]*computepb.Image, error) {
+	var images []*computepb.Image
+
+	it := c.images.List(ctx, &computepb.ListImagesRequest{
+		Project: project,
+	})
+	err := pages(it.Next, func() interface{} { return it.Response }, "ListImages", func(image *computepb.Image) {
+		images = append(images, image)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// Close closes the connections of both clients
+func (c *imagesClient) Close() error {
+	return errors.Join(c.snapshots.Close(), c.images.Close())
+}
+
+// pages reads the items of every page of an iterator of the compute API,
+// counting a request per page
+func pages[T any](next func() (T, error), response func() interface{}, operation string, add func(T)) error {
+	var page interface{}
+
+	for {
+		item, err := next()
+		if err == iterator.Done {
+			// the last page can be empty
+			if response() != page {
+				util.RecordAPICall(provider, computeAPI, operation, nil)
+			}
+			return nil
+		}
+		if err != nil {
+			util.RecordAPICall(provider, computeAPI, operation, err)
+			return err
+		}
+
+		// the response is the one of the last page fetched
+		if response() != page {
+			page = response()
+			util.RecordAPICall(provider, computeAPI, operation, nil)
+		}
+
+		add(item)
+	}
+}
@@ -0,0 +1,158 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+/*
+* An MQL query that will return the I/O of the disks of the instances with the
+* - Instance ID
+* - Device Name
+* - Storage Type, e.g. pd-standard or pd-ssd
+* - Read and written operations over the window
+* - Read and written bytes over the window
+* The query covers the window of the given duration ending at the given date
+ */
+var DiskQuery = `
+  fetch gce_instance
+  | { metric 'compute.googleapis.com/instance/disk/read_ops_count'
+    ; metric 'compute.googleapis.com/instance/disk/write_ops_count'
+    ; metric 'compute.googleapis.com/instance/disk/read_bytes_count'
+    ; metric 'compute.googleapis.com/instance/disk/write_bytes_count' }
+  | join
+  | filter project_id = '%s'
+  | group_by [
+    resource.instance_id,
+    metric.device_name,
+    metric.storage_type
+  ], [
+    ops: sum(t_0.value.read_ops_count) + sum(t_1.value.write_ops_count),
+    bytes: sum(t_2.value.read_bytes_count) + sum(t_3.value.write_bytes_count)
+  ]
+  | window %s
+  | within %s, d'%s'
+	`
+
+// diskIO is the media of a disk and its read and write operations and bytes
+// per second
+type diskIO struct {
+	media      string
+	ops, bytes float64
+}
+
+// disks returns the storage metrics of the disks attached to the instance,
+// named after their device name and with their size. The local SSDs are
+// known, the media of the persistent disks is the storage type of their I/O
+func disks(instance *computepb.Instance) v1.Metrics {
+	var metrics v1.Metrics
+	for _, disk := range instance.GetDisks() {
+		m := v1.Metric{
+			Name:         disk.GetDeviceName(),
+			ResourceType: v1.Storage,
+			UnitAmount:   float64(disk.GetDiskSizeGb()),
+			Unit:         v1.GB,
+			Labels:       v1.Labels{},
+		}
+		if disk.GetType() == computepb.AttachedDisk_SCRATCH.String() {
+			m.Labels[v1.MediaLabel] = v1.MediaSSD
+		}
+		metrics.Upsert(&m)
+	}
+	return metrics
+}
+
+// storageMedia returns the media of a storage type of the disk metrics, the
+// standard persistent disks are HDDs
+func storageMedia(storageType string) string {
+	if storageType == "pd-standard" {
+		return v1.MediaHDD
+	}
+	return v1.MediaSSD
+}
+
+// disksIO returns the I/O of the disks of the project at the granularity of
+// the storage: the rates averaged over the last collection are used until
+// the next one
+func (c *Client) disksIO(ctx context.Context, project string, window v1.Window) (map[string]diskIO, error) {
+	w, due := c.cadence.Due(v1.Storage, project, window)
+
+	c.diskIOMu.Lock()
+	defer c.diskIOMu.Unlock()
+
+	if !due {
+		return c.diskIO[project], nil
+	}
+
+	duration := w.Duration().String()
+	end := w.End.UTC().Format(mqlDateFormat)
+	disks, err := c.instanceDiskIO(
+		ctx, project, fmt.Sprintf(DiskQuery, project, duration, duration, end), w.Duration().Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.diskIO == nil {
+		c.diskIO = make(map[string]map[string]diskIO)
+	}
+	c.diskIO[project] = disks
+
+	return disks, nil
+}
+
+// instanceDiskIO runs a query on google cloud monitoring using MQL and
+// responds with the I/O of the disks averaged over the window of the given
+// seconds, by instance ID and device name
+func (c *Client) instanceDiskIO(
+	ctx context.Context,
+	project, query string,
+	seconds float64,
+) (map[string]diskIO, error) {
+	if err := util.WaitForAPI(ctx, provider, monitoringAPI); err != nil {
+		return nil, err
+	}
+
+	data, err := c.monitoring.QueryTimeSeries(ctx, &monitoringpb.QueryTimeSeriesRequest{
+		Name:  fmt.Sprintf("projects/%s", project),
+		Query: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	disks := make(map[string]diskIO, len(data))
+	for _, resp := range data {
+		// This is dependant on the MQL query
+		// label ordering
+		instanceID := resp.GetLabelValues()[0].GetStringValue()
+		deviceName := resp.GetLabelValues()[1].GetStringValue()
+		storageType := resp.GetLabelValues()[2].GetStringValue()
+
+		values := resp.GetPointData()[0].GetValues()
+		disks[diskKey(instanceID, deviceName)] = diskIO{
+			media: storageMedia(storageType),
+			ops:   number(values[0]) / seconds,
+			bytes: number(values[1]) / seconds,
+		}
+	}
+	return disks, nil
+}
+
+// diskKey identifies the disk of an instance
+func diskKey(instanceID, deviceName string) string {
+	return instanceID + "/" + deviceName
+}
+
+// number returns the value whether it's an integer or a double
+func number(v *monitoringpb.TypedValue) float64 {
+	if _, ok := v.GetValue().(*monitoringpb.TypedValue_Int64Value); ok {
+		return float64(v.GetInt64Value())
+	}
+	return v.GetDoubleValue()
+}
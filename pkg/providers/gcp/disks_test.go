@@ -0,0 +1,116 @@
+package gcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// diskSeries returns the I/O of a disk as returned by the MQL query
+func diskSeries(id, device, storageType string, ops, bytes int64) *monitoringpb.TimeSeriesData {
+	values := []*monitoringpb.LabelValue{}
+	for _, l := range []string{id, device, storageType} {
+		values = append(values, &monitoringpb.LabelValue{
+			Value: &monitoringpb.LabelValue_StringValue{StringValue: l},
+		})
+	}
+
+	return &monitoringpb.TimeSeriesData{
+		LabelValues: values,
+		PointData: []*monitoringpb.TimeSeriesData_PointData{
+			{Values: []*monitoringpb.TypedValue{
+				{Value: &monitoringpb.TypedValue_Int64Value{Int64Value: ops}},
+				{Value: &monitoringpb.TypedValue_Int64Value{Int64Value: bytes}},
+			}},
+		},
+	}
+}
+
+func TestDisks(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	instances := &fakeInstances{}
+	instances.AggregatedListReturns([]*computepb.Instance{
+		{
+			Id:          proto.Uint64(1),
+			Name:        proto.String("db"),
+			Zone:        proto.String("https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b"),
+			MachineType: proto.String("https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b/machineTypes/e2-standard-2"),
+			Status:      proto.String("RUNNING"),
+			Disks: []*computepb.AttachedDisk{
+				{DeviceName: proto.String("boot"), DiskSizeGb: proto.Int64(20), Type: proto.String("PERSISTENT")},
+				{DeviceName: proto.String("data"), DiskSizeGb: proto.Int64(500), Type: proto.String("PERSISTENT")},
+				{DeviceName: proto.String("local-ssd-0"), DiskSizeGb: proto.Int64(375), Type: proto.String("SCRATCH")},
+			},
+		},
+	}, nil)
+
+	monitoring := &fakeMonitoring{}
+	monitoring.QueryTimeSeriesCalls(func(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error) {
+		switch {
+		case strings.Contains(req.Query, "cpu/utilization"):
+			return []*monitoringpb.TimeSeriesData{
+				timeSeries("1", "db", &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: 0.5},
+				}, "2.000000"),
+			}, nil
+		case strings.Contains(req.Query, "disk/read_ops_count"):
+			// the sums over the window of 300 seconds
+			return []*monitoringpb.TimeSeriesData{
+				diskSeries("1", "data", "pd-standard", 30000, 3e9),
+				diskSeries("1", "boot", "pd-balanced", 300, 0),
+			}, nil
+		}
+		return nil, nil
+	})
+
+	account := &config.Account{Project: "demo", Storage: true}
+	c, teardown, err := New(ctx, account,
+		withMonitoringTestClient(monitoring),
+		withInstancesTestClient(instances),
+		withMachineTypesTestClient(machineTypes()),
+	)
+	assert.NoError(err)
+	defer teardown()
+
+	assert.NoError(c.Refresh(ctx, "demo"))
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	collected, err := c.GetMetricsForInstances(ctx, "demo", v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Len(collected, 1)
+	assert.Len(collected[0].Metrics, 4)
+
+	data := collected[0].Metrics["data"]
+	assert.Equal(v1.Storage, data.ResourceType)
+	assert.Equal(500.0, data.UnitAmount)
+	assert.Equal(v1.MediaHDD, data.Labels[v1.MediaLabel])
+	assert.Equal(100.0, data.IOPS)
+	assert.Equal(1e7, data.Throughput)
+	assert.True(end.Equal(data.UpdatedAt))
+
+	boot := collected[0].Metrics["boot"]
+	assert.Equal(v1.MediaSSD, boot.Labels[v1.MediaLabel])
+	assert.Equal(1.0, boot.IOPS)
+
+	// the local SSDs are known without their I/O
+	local := collected[0].Metrics["local-ssd-0"]
+	assert.Equal(375.0, local.UnitAmount)
+	assert.Equal(v1.MediaSSD, local.Labels[v1.MediaLabel])
+	assert.Zero(local.IOPS)
+
+	// the labels of the cached disks are left unchanged
+	cached, ok := c.cache.Get(util.CacheKey("europe-west1-b", service, "db"))
+	assert.True(ok)
+	assert.Empty(cached.(v1.Instance).Metrics["data"].Labels)
+}
@@ -0,0 +1,259 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package gcp
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+)
+
+type fakeImages struct {
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
+	closeReturns struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListImagesStub        func(context.Context, string) ([]*computepb.Image, error)
+	listImagesMutex       sync.RWMutex
+	listImagesArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listImagesReturns struct {
+		result1 []*computepb.Image
+		result2 error
+	}
+	listImagesReturnsOnCall map[int]struct {
+		result1 []*computepb.Image
+		result2 error
+	}
+	ListSnapshotsStub        func(context.Context, string) ([]*computepb.Snapshot, error)
+	listSnapshotsMutex       sync.RWMutex
+	listSnapshotsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listSnapshotsReturns struct {
+		result1 []*computepb.Snapshot
+		result2 error
+	}
+	listSnapshotsReturnsOnCall map[int]struct {
+		result1 []*computepb.Snapshot
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeImages) Close() error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fakeReturns := fake.closeReturns
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *fakeImages) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *fakeImages) CloseCalls(stub func() error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *fakeImages) CloseReturns(result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *fakeImages) CloseReturnsOnCall(i int, result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *fakeImages) ListImages(arg1 context.Context, arg2 string) ([]*computepb.Image, error) {
+	fake.listImagesMutex.Lock()
+	ret, specificReturn := fake.listImagesReturnsOnCall[len(fake.listImagesArgsForCall)]
+	fake.listImagesArgsForCall = append(fake.listImagesArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListImagesStub
+	fakeReturns := fake.listImagesReturns
+	fake.recordInvocation("ListImages", []interface{}{arg1, arg2})
+	fake.listImagesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeImages) ListImagesCallCount() int {
+	fake.listImagesMutex.RLock()
+	defer fake.listImagesMutex.RUnlock()
+	return len(fake.listImagesArgsForCall)
+}
+
+func (fake *fakeImages) ListImagesCalls(stub func(context.Context, string) ([]*computepb.Image, error)) {
+	fake.listImagesMutex.Lock()
+	defer fake.listImagesMutex.Unlock()
+	fake.ListImagesStub = stub
+}
+
+func (fake *fakeImages) ListImagesArgsForCall(i int) (context.Context, string) {
+	fake.listImagesMutex.RLock()
+	defer fake.listImagesMutex.RUnlock()
+	argsForCall := fake.listImagesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeImages) ListImagesReturns(result1 []*computepb.Image, result2 error) {
+	fake.listImagesMutex.Lock()
+	defer fake.listImagesMutex.Unlock()
+	fake.ListImagesStub = nil
+	fake.listImagesReturns = struct {
+		result1 []*computepb.Image
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeImages) ListImagesReturnsOnCall(i int, result1 []*computepb.Image, result2 error) {
+	fake.listImagesMutex.Lock()
+	defer fake.listImagesMutex.Unlock()
+	fake.ListImagesStub = nil
+	if fake.listImagesReturnsOnCall == nil {
+		fake.listImagesReturnsOnCall = make(map[int]struct {
+			result1 []*computepb.Image
+			result2 error
+		})
+	}
+	fake.listImagesReturnsOnCall[i] = struct {
+		result1 []*computepb.Image
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeImages) ListSnapshots(arg1 context.Context, arg2 string) ([]*computepb.Snapshot, error) {
+	fake.listSnapshotsMutex.Lock()
+	ret, specificReturn := fake.listSnapshotsReturnsOnCall[len(fake.listSnapshotsArgsForCall)]
+	fake.listSnapshotsArgsForCall = append(fake.listSnapshotsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListSnapshotsStub
+	fakeReturns := fake.listSnapshotsReturns
+	fake.recordInvocation("ListSnapshots", []interface{}{arg1, arg2})
+	fake.listSnapshotsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeImages) ListSnapshotsCallCount() int {
+	fake.listSnapshotsMutex.RLock()
+	defer fake.listSnapshotsMutex.RUnlock()
+	return len(fake.listSnapshotsArgsForCall)
+}
+
+func (fake *fakeImages) ListSnapshotsCalls(stub func(context.Context, string) ([]*computepb.Snapshot, error)) {
+	fake.listSnapshotsMutex.Lock()
+	defer fake.listSnapshotsMutex.Unlock()
+	fake.ListSnapshotsStub = stub
+}
+
+func (fake *fakeImages) ListSnapshotsArgsForCall(i int) (context.Context, string) {
+	fake.listSnapshotsMutex.RLock()
+	defer fake.listSnapshotsMutex.RUnlock()
+	argsForCall := fake.listSnapshotsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeImages) ListSnapshotsReturns(result1 []*computepb.Snapshot, result2 error) {
+	fake.listSnapshotsMutex.Lock()
+	defer fake.listSnapshotsMutex.Unlock()
+	fake.ListSnapshotsStub = nil
+	fake.listSnapshotsReturns = struct {
+		result1 []*computepb.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeImages) ListSnapshotsReturnsOnCall(i int, result1 []*computepb.Snapshot, result2 error) {
+	fake.listSnapshotsMutex.Lock()
+	defer fake.listSnapshotsMutex.Unlock()
+	fake.ListSnapshotsStub = nil
+	if fake.listSnapshotsReturnsOnCall == nil {
+		fake.listSnapshotsReturnsOnCall = make(map[int]struct {
+			result1 []*computepb.Snapshot
+			result2 error
+		})
+	}
+	fake.listSnapshotsReturnsOnCall[i] = struct {
+		result1 []*computepb.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeImages) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeImages) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ imageLister = new(fakeImages)
@@ -0,0 +1,180 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package gcp
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+)
+
+type fakeInstances struct {
+	AggregatedListStub        func(context.Context, *computepb.AggregatedListInstancesRequest) ([]*computepb.Instance, error)
+	aggregatedListMutex       sync.RWMutex
+	aggregatedListArgsForCall []struct {
+		arg1 context.Context
+		arg2 *computepb.AggregatedListInstancesRequest
+	}
+	aggregatedListReturns struct {
+		result1 []*computepb.Instance
+		result2 error
+	}
+	aggregatedListReturnsOnCall map[int]struct {
+		result1 []*computepb.Instance
+		result2 error
+	}
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
+	closeReturns struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeInstances) AggregatedList(arg1 context.Context, arg2 *computepb.AggregatedListInstancesRequest) ([]*computepb.Instance, error) {
+	fake.aggregatedListMutex.Lock()
+	ret, specificReturn := fake.aggregatedListReturnsOnCall[len(fake.aggregatedListArgsForCall)]
+	fake.aggregatedListArgsForCall = append(fake.aggregatedListArgsForCall, struct {
+		arg1 context.Context
+		arg2 *computepb.AggregatedListInstancesRequest
+	}{arg1, arg2})
+	stub := fake.AggregatedListStub
+	fakeReturns := fake.aggregatedListReturns
+	fake.recordInvocation("AggregatedList", []interface{}{arg1, arg2})
+	fake.aggregatedListMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeInstances) AggregatedListCallCount() int {
+	fake.aggregatedListMutex.RLock()
+	defer fake.aggregatedListMutex.RUnlock()
+	return len(fake.aggregatedListArgsForCall)
+}
+
+func (fake *fakeInstances) AggregatedListCalls(stub func(context.Context, *computepb.AggregatedListInstancesRequest) ([]*computepb.Instance, error)) {
+	fake.aggregatedListMutex.Lock()
+	defer fake.aggregatedListMutex.Unlock()
+	fake.AggregatedListStub = stub
+}
+
+func (fake *fakeInstances) AggregatedListArgsForCall(i int) (context.Context, *computepb.AggregatedListInstancesRequest) {
+	fake.aggregatedListMutex.RLock()
+	defer fake.aggregatedListMutex.RUnlock()
+	argsForCall := fake.aggregatedListArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeInstances) AggregatedListReturns(result1 []*computepb.Instance, result2 error) {
+	fake.aggregatedListMutex.Lock()
+	defer fake.aggregatedListMutex.Unlock()
+	fake.AggregatedListStub = nil
+	fake.aggregatedListReturns = struct {
+		result1 []*computepb.Instance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) AggregatedListReturnsOnCall(i int, result1 []*computepb.Instance, result2 error) {
+	fake.aggregatedListMutex.Lock()
+	defer fake.aggregatedListMutex.Unlock()
+	fake.AggregatedListStub = nil
+	if fake.aggregatedListReturnsOnCall == nil {
+		fake.aggregatedListReturnsOnCall = make(map[int]struct {
+			result1 []*computepb.Instance
+			result2 error
+		})
+	}
+	fake.aggregatedListReturnsOnCall[i] = struct {
+		result1 []*computepb.Instance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) Close() error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fakeReturns := fake.closeReturns
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *fakeInstances) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *fakeInstances) CloseCalls(stub func() error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *fakeInstances) CloseReturns(result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *fakeInstances) CloseReturnsOnCall(i int, result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *fakeInstances) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeInstances) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ instanceLister = new(fakeInstances)
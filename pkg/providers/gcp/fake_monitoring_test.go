@@ -0,0 +1,180 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package gcp
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+)
+
+type fakeMonitoring struct {
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
+	closeReturns struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	QueryTimeSeriesStub        func(context.Context, *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error)
+	queryTimeSeriesMutex       sync.RWMutex
+	queryTimeSeriesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *monitoringpb.QueryTimeSeriesRequest
+	}
+	queryTimeSeriesReturns struct {
+		result1 []*monitoringpb.TimeSeriesData
+		result2 error
+	}
+	queryTimeSeriesReturnsOnCall map[int]struct {
+		result1 []*monitoringpb.TimeSeriesData
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeMonitoring) Close() error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fakeReturns := fake.closeReturns
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *fakeMonitoring) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *fakeMonitoring) CloseCalls(stub func() error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *fakeMonitoring) CloseReturns(result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *fakeMonitoring) CloseReturnsOnCall(i int, result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *fakeMonitoring) QueryTimeSeries(arg1 context.Context, arg2 *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error) {
+	fake.queryTimeSeriesMutex.Lock()
+	ret, specificReturn := fake.queryTimeSeriesReturnsOnCall[len(fake.queryTimeSeriesArgsForCall)]
+	fake.queryTimeSeriesArgsForCall = append(fake.queryTimeSeriesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *monitoringpb.QueryTimeSeriesRequest
+	}{arg1, arg2})
+	stub := fake.QueryTimeSeriesStub
+	fakeReturns := fake.queryTimeSeriesReturns
+	fake.recordInvocation("QueryTimeSeries", []interface{}{arg1, arg2})
+	fake.queryTimeSeriesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeMonitoring) QueryTimeSeriesCallCount() int {
+	fake.queryTimeSeriesMutex.RLock()
+	defer fake.queryTimeSeriesMutex.RUnlock()
+	return len(fake.queryTimeSeriesArgsForCall)
+}
+
+func (fake *fakeMonitoring) QueryTimeSeriesCalls(stub func(context.Context, *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error)) {
+	fake.queryTimeSeriesMutex.Lock()
+	defer fake.queryTimeSeriesMutex.Unlock()
+	fake.QueryTimeSeriesStub = stub
+}
+
+func (fake *fakeMonitoring) QueryTimeSeriesArgsForCall(i int) (context.Context, *monitoringpb.QueryTimeSeriesRequest) {
+	fake.queryTimeSeriesMutex.RLock()
+	defer fake.queryTimeSeriesMutex.RUnlock()
+	argsForCall := fake.queryTimeSeriesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeMonitoring) QueryTimeSeriesReturns(result1 []*monitoringpb.TimeSeriesData, result2 error) {
+	fake.queryTimeSeriesMutex.Lock()
+	defer fake.queryTimeSeriesMutex.Unlock()
+	fake.QueryTimeSeriesStub = nil
+	fake.queryTimeSeriesReturns = struct {
+		result1 []*monitoringpb.TimeSeriesData
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMonitoring) QueryTimeSeriesReturnsOnCall(i int, result1 []*monitoringpb.TimeSeriesData, result2 error) {
+	fake.queryTimeSeriesMutex.Lock()
+	defer fake.queryTimeSeriesMutex.Unlock()
+	fake.QueryTimeSeriesStub = nil
+	if fake.queryTimeSeriesReturnsOnCall == nil {
+		fake.queryTimeSeriesReturnsOnCall = make(map[int]struct {
+			result1 []*monitoringpb.TimeSeriesData
+			result2 error
+		})
+	}
+	fake.queryTimeSeriesReturnsOnCall[i] = struct {
+		result1 []*monitoringpb.TimeSeriesData
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMonitoring) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeMonitoring) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ timeSeriesQuerier = new(fakeMonitoring)
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	compute "cloud.google.com/go/compute/apiv1"
@@ -14,18 +18,49 @@ import (
 	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
 	cache "github.com/patrickmn/go-cache"
 	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/flowlogs"
 	"github.com/re-cinq/aether/pkg/log"
 	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/relabel"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
-	"google.golang.org/api/iterator"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
 // Client is the structure used as the provider for Google Cloud Platform
 type Client struct {
-	// GCP Clients
-	monitoring *monitoring.QueryClient
-	instances  *compute.InstancesClient
+	// GCP Clients, faked by the tests
+	monitoring   timeSeriesQuerier
+	instances    instanceLister
+	machineTypes machineTypeGetter
+
+	// Lists the sole-tenant nodes, nil when their hosts aren't accounted
+	// for as a whole
+	nodes nodeLister
+
+	// Lists the snapshots and the images, nil when they aren't collected
+	images imageLister
+
+	// Whether the disks of the instances and their I/O are collected
+	storage bool
+
+	// Whether the bytes transferred by the load balancers and Cloud CDN
+	// are collected
+	network bool
+
+	// When the network and the I/O of the disks are collected
+	cadence *util.Cadence
+
+	// The I/O of the disks of the last collection by project, used until
+	// the next one
+	diskIO   map[string]map[string]diskIO
+	diskIOMu sync.Mutex
+
+	// The managed control planes of the GKE clusters
+	controlPlanes []util.ControlPlane
+
+	// The summary of the flow logs, nil when there's none
+	flowLogs *flowlogs.Source
 
 	// Caching mechanism
 	cache *cache.Cache
@@ -43,7 +78,16 @@ func New(
 	// set any defaults here
 	c = &Client{
 		// TODO do we want to expire cache?
-		cache: cache.New(3600*time.Minute, 3600*time.Minute),
+		cache:    cache.New(3600*time.Minute, 3600*time.Minute),
+		storage:  account.Storage,
+		network:  account.Network,
+		cadence:  util.NewCadence(provider),
+		flowLogs: flowlogs.New(account.FlowLogs),
+	}
+
+	c.controlPlanes, err = util.ControlPlanes(gkeControlPlane, account.ControlPlanes)
+	if err != nil {
+		return nil, func() {}, err
 	}
 
 	var clientOptions []option.ClientOption
@@ -56,6 +100,17 @@ func New(
 		)
 	}
 
+	// the private endpoints of the APIs, e.g. the Private Service Connect
+	// endpoints
+	monitoringOptions := clientOptions
+	if endpoint := account.Endpoints[monitoringAPI]; endpoint != "" {
+		monitoringOptions = append(slices.Clip(clientOptions), option.WithEndpoint(endpoint))
+	}
+	computeOptions := clientOptions
+	if endpoint := account.Endpoints[computeAPI]; endpoint != "" {
+		computeOptions = append(slices.Clip(clientOptions), option.WithEndpoint(endpoint))
+	}
+
 	// overwrite any options
 	for _, opt := range opts {
 		opt(c)
@@ -67,20 +122,52 @@ func New(
 	// it would try authenticate against google regardless of overwriting the
 	// client
 	if c.monitoring == nil {
-		mc, err := monitoring.NewQueryClient(ctx, clientOptions...)
+		mc, err := monitoring.NewQueryClient(ctx, monitoringOptions...)
 		if err != nil {
 			return nil, func() {}, err
 		}
-		c.monitoring = mc
+		c.monitoring = &queryClient{mc}
 	}
 
 	// This allows overwriting the default instances client
 	if c.instances == nil {
-		ic, err := compute.NewInstancesRESTClient(ctx, clientOptions...)
+		ic, err := compute.NewInstancesRESTClient(ctx, computeOptions...)
 		if err != nil {
 			return nil, func() {}, err
 		}
-		c.instances = ic
+		c.instances = &instancesClient{ic}
+	}
+
+	// This allows overwriting the default machine types client
+	if c.machineTypes == nil {
+		mc, err := compute.NewMachineTypesRESTClient(ctx, computeOptions...)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		c.machineTypes = &machineTypesClient{mc}
+	}
+
+	// This allows overwriting the default nodes client
+	if c.nodes == nil && account.SoleTenantNodes {
+		nc, err := compute.NewNodeGroupsRESTClient(ctx, computeOptions...)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		c.nodes = &nodesClient{nc}
+	}
+
+	// This allows overwriting the default images client
+	if c.images == nil && account.Snapshots {
+		sc, err := compute.NewSnapshotsRESTClient(ctx, computeOptions...)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		ic, err := compute.NewImagesRESTClient(ctx, computeOptions...)
+		if err != nil {
+			sc.Close()
+			return nil, func() {}, err
+		}
+		c.images = &imagesClient{snapshots: sc, images: ic}
 	}
 
 	// teardown is used to close relevant connections
@@ -88,41 +175,68 @@ func New(
 	teardown = func() {
 		c.monitoring.Close()
 		c.instances.Close()
+		c.machineTypes.Close()
+		if c.nodes != nil {
+			c.nodes.Close()
+		}
+		if c.images != nil {
+			c.images.Close()
+		}
 	}
 
 	return c, teardown, nil
 }
 
 // GetMetricsForInstances retrieves all the metrics for a given instance
+// over the window
 func (c *Client) GetMetricsForInstances(
 	ctx context.Context,
-	project, window string,
+	project string,
+	window v1.Window,
 ) ([]v1.Instance, error) {
 	var instances []v1.Instance
 
+	duration := window.Duration().String()
+	end := window.End.UTC().Format(mqlDateFormat)
+
 	cpumetrics, err := c.instanceCPUMetrics(
 		// TODO these parameters can be cleaned up
-		ctx, project, fmt.Sprintf(CPUQuery, project, window, window),
+		ctx, project, fmt.Sprintf(CPUQuery, project, duration, duration, end),
 	)
 	if err != nil {
 		return instances, err
 	}
 
 	memmetrics, err := c.instanceMemoryMetrics(
-		ctx, project, fmt.Sprintf(MEMQuery, project, window, window),
+		ctx, project, fmt.Sprintf(MEMQuery, project, duration, duration, end),
 	)
 	if err != nil {
 		return instances, err
 	}
 
+	// the I/O of the disks is optional, the disks are accounted for by
+	// their size when it can't be collected
+	var diskio map[string]diskIO
+	if c.storage {
+		diskio, err = c.disksIO(ctx, project, window)
+		if err != nil {
+			log.FromContext(ctx).Warn("failed to retrieve the disks io", "project", project, "error", err)
+		}
+	}
+
 	// we use a lookup to add different metrics to the same instance
 	lookup := make(map[string]*v1.Instance)
 
+	// the specs of the machine types of the instances
+	cachedSpecs, _ := c.cache.Get(specsKey)
+	specs, _ := cachedSpecs.(map[string]util.Spec)
+
 	// TODO there seems to be duplicated logic here
 	// Why not create instance whuile collecting metric instead of handeling
 	// it in two steps
 	for _, m := range append(cpumetrics, memmetrics...) {
 		metric := *m
+		metric.UpdatedAt = window.End.UTC()
 
 		meta, err := getMetadata(&metric)
 		if err != nil {
@@ -146,8 +260,54 @@ func (c *Client) GetMetricsForInstances(
 		}
 
 		i.Kind = meta.machineType
+		i.Labels.Add(v1.NameLabel, meta.name)
 		i.Region = meta.region
 		i.Zone = meta.zone
+		if cached, ok := cachedInstance.(v1.Instance); ok {
+			i.Architecture = cached.Architecture
+			i.CPUPlatform = cached.CPUPlatform
+			for k, v := range cached.Labels {
+				if strings.HasPrefix(k, v1.TagLabelPrefix) || k == v1.AcceleratorLabel || k == v1.AcceleratorCountLabel {
+					i.Labels.Add(k, v)
+				}
+			}
+
+			// the instances started during the window are only
+			// accounted for from their start
+			if cached.StartedAt.After(window.Start) {
+				metric.Observed = window.Intersect(v1.Window{
+					Start: cached.StartedAt,
+					End:   window.End,
+				})
+			}
+
+			// the disks cached with the instance
+			for _, disk := range cached.Metrics {
+				if _, ok := i.Metrics[disk.Name]; ok {
+					continue
+				}
+
+				disk.UpdatedAt = window.End.UTC()
+				disk.Observed = metric.Observed
+				if io, ok := diskio[diskKey(meta.id, disk.Name)]; ok {
+					// the labels are shared with the cache
+					disk.Labels = disk.Labels.With(v1.MediaLabel, io.media)
+					disk.IOPS = io.ops
+					disk.Throughput = io.bytes
+				}
+				i.Metrics.Upsert(&disk)
+			}
+		}
+		// the memory of the instance is the one of its machine type,
+		// the vCPUs reserved by the instance take precedence
+		if spec, ok := specs[meta.machineType]; ok {
+			switch {
+			case metric.ResourceType == v1.Memory:
+				metric.UnitAmount = spec.MemoryGB
+			case metric.ResourceType == v1.CPU && metric.UnitAmount == 0:
+				metric.UnitAmount = spec.VCPU
+			}
+		}
 		i.Metrics.Upsert(&metric)
 
 		lookup[meta.id] = i
@@ -159,6 +319,28 @@ func (c *Client) GetMetricsForInstances(
 		instances = append(instances, *v)
 	}
 
+	instances = c.hosts(instances, window)
+
+	// the forwarding rules are optional, the instances are still returned
+	// when they can't be collected. They're collected since their last
+	// collection at the granularity of the network
+	if c.network {
+		rules, err := c.networkRules(ctx, project, window)
+		if err != nil {
+			log.FromContext(ctx).Warn("failed to retrieve the forwarding rules", "project", project, "error", err)
+		}
+		instances = append(instances, rules...)
+	}
+
+	// the snapshots are optional too
+	if c.images != nil {
+		snapshots, err := c.snapshots(ctx, project, window)
+		if err != nil {
+			log.FromContext(ctx).Warn("failed to retrieve the snapshots and the images", "project", project, "error", err)
+		}
+		instances = append(instances, snapshots...)
+	}
+
 	return instances, nil
 }
 
@@ -203,61 +385,182 @@ func getMetadata(m *v1.Metric) (*metadata, error) {
 // Refresh fetches all the Instances
 // for a project and stores metadata in order to help with
 // metric collections
-func (c *Client) Refresh(ctx context.Context, project string) {
+func (c *Client) Refresh(ctx context.Context, project string) error {
 	logger := log.FromContext(ctx)
 
-	iter := c.instances.AggregatedList(
+	if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+		return err
+	}
+
+	instances, err := c.instances.AggregatedList(
 		ctx,
 		&computepb.AggregatedListInstancesRequest{
 			Project: project,
 		},
 	)
+	if err != nil {
+		return fmt.Errorf("failed processing GCE instances: %w", err)
+	}
 
-	for {
-		resp, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
+	// a zone of every machine type of the running instances
+	zones := make(map[string]string)
+
+	for _, instance := range instances {
+		zone, err := getValueFromURL(instance.GetZone())
 		if err != nil {
-			logger.Error("failed processesing GCE instance", "error", err)
-			return
+			logger.Error("failed to get zone from url")
 		}
+		instanceID := strconv.FormatUint(instance.GetId(), 10)
+		name := instance.GetName()
 
-		for _, instance := range resp.Value.Instances {
-			zone, err := getValueFromURL(instance.GetZone())
+		if zone == "" {
+			continue
+		}
+
+		if instance.GetStatus() == "TERMINATED" {
+			// delete the entry from the cache
+			c.cache.Delete(util.CacheKey(zone, service, name))
+			continue
+		}
+
+		if instance.GetStatus() == "RUNNING" {
+			kind, err := getValueFromURL(instance.GetMachineType())
 			if err != nil {
-				logger.Error("failed to get zone from url")
+				logger.Error("failed to get instance type from url")
+			}
+			if kind != "" {
+				zones[kind] = zone
+			}
+			labels := v1.Labels{
+				"Lifecycle": instance.GetScheduling().GetProvisioningModel(),
+				"ID":        instanceID,
 			}
-			instanceID := strconv.FormatUint(instance.GetId(), 10)
-			name := instance.GetName()
 
-			if zone == "" {
-				continue
+			// the labels of the instance are its tags, matched by the
+			// grouping rules
+			for k, v := range instance.GetLabels() {
+				labels[v1.TagLabelPrefix+relabel.LabelName(k)] = v
 			}
 
-			if instance.GetStatus() == "TERMINATED" {
-				// delete the entry from the cache
-				c.cache.Delete(util.CacheKey(zone, service, name))
-				continue
+			// the GPUs attached to the instance, including the ones
+			// of the accelerator-optimized machine types
+			for _, a := range instance.GetGuestAccelerators() {
+				accelerator, err := getValueFromURL(a.GetAcceleratorType())
+				if err != nil || accelerator == "" || a.GetAcceleratorCount() <= 0 {
+					continue
+				}
+				labels[v1.AcceleratorLabel] = accelerator
+				labels[v1.AcceleratorCountLabel] = strconv.Itoa(int(a.GetAcceleratorCount()))
 			}
 
-			if instance.GetStatus() == "RUNNING" {
-				kind, err := getValueFromURL(instance.GetMachineType())
-				if err != nil {
-					logger.Error("failed to get instance type from url")
+			// the E2 instances are migrated across CPU platforms, the
+			// wattage is the one of the platform at the last refresh
+			key := util.CacheKey(zone, service, name)
+			if cached, ok := c.cache.Get(key); ok {
+				if previous, ok := cached.(v1.Instance); ok && previous.CPUPlatform != instance.GetCpuPlatform() {
+					logger.Debug("instance moved to another CPU platform", "instance", name, "from", previous.CPUPlatform, "to", instance.GetCpuPlatform())
 				}
-				c.cache.Set(util.CacheKey(zone, service, name), v1.Instance{
-					Name:    name,
-					Zone:    zone,
-					Service: service,
-					Kind:    kind,
-					Labels: v1.Labels{
-						"Lifecycle": instance.GetScheduling().GetProvisioningModel(),
-						"ID":        instanceID,
-					},
-				}, cache.DefaultExpiration)
+			}
+
+			var metrics v1.Metrics
+			if c.storage {
+				metrics = disks(instance)
+			}
+
+			c.cache.Set(key, v1.Instance{
+				Name:         name,
+				Zone:         zone,
+				Service:      service,
+				Kind:         kind,
+				Architecture: architecture(instance.GetCpuPlatform()),
+				CPUPlatform:  instance.GetCpuPlatform(),
+				StartedAt:    startedAt(instance),
+				Labels:       labels,
+				Metrics:      metrics,
+			}, cache.DefaultExpiration)
+		}
+	}
+
+	// the specs of the machine types are shared by all the projects, the
+	// metrics are collected without them when they can't be fetched
+	kinds := make([]string, 0, len(zones))
+	for kind := range zones {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	specs, err := util.Specs(ctx, provider, kinds, c.machineTypeSpecs(project, zones))
+	if err != nil {
+		logger.Warn("failed to retrieve the machine types", "project", project, "error", err)
+	}
+	c.cache.Set(specsKey, specs, cache.DefaultExpiration)
+
+	if c.nodes != nil {
+		return c.refreshNodes(ctx, project)
+	}
+
+	return nil
+}
+
+// The key of the specs of the machine types in the cache
+const specsKey = "specs"
+
+// machineTypeSpecs returns the fetcher of the specs of the machine types,
+// each one is got in a zone of its instances. The custom machine types
+// aren't listed but can be got like the predefined ones
+func (c *Client) machineTypeSpecs(project string, zones map[string]string) util.SpecFetcher {
+	return func(ctx context.Context, kinds []string) (map[string]util.Spec, error) {
+		specs := make(map[string]util.Spec, len(kinds))
+		for _, kind := range kinds {
+			if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+				return nil, err
+			}
+
+			mt, err := c.machineTypes.Get(ctx, &computepb.GetMachineTypeRequest{
+				Project:     project,
+				Zone:        zones[kind],
+				MachineType: kind,
+			})
+			var apiErr *googleapi.Error
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			specs[kind] = util.Spec{
+				VCPU:     float64(mt.GetGuestCpus()),
+				MemoryGB: float64(mt.GetMemoryMb()) / 1024,
 			}
 		}
+		return specs, nil
+	}
+}
+
+// startedAt returns when the instance was last started, zero when unknown
+func startedAt(instance *computepb.Instance) time.Time {
+	timestamp := instance.GetLastStartTimestamp()
+	if timestamp == "" {
+		timestamp = instance.GetCreationTimestamp()
+	}
+
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// architecture returns the CPU architecture of the CPU platform of an
+// instance, e.g. arm64 for Ampere Altra, empty when the platform is unknown
+func architecture(cpuPlatform string) string {
+	switch {
+	case cpuPlatform == "" || cpuPlatform == "Unknown CPU Platform":
+		return ""
+	case strings.HasPrefix(cpuPlatform, "Ampere"), strings.HasPrefix(cpuPlatform, "Google Axion"):
+		return "arm64"
+	default:
+		return "x86_64"
 	}
 }
 
@@ -4,13 +4,21 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
 	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/providers/util"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
-	"google.golang.org/api/iterator"
 )
 
+// mqlDateFormat is the format of a date literal in MQL
+const mqlDateFormat = "2006/01/02-15:04:05"
+
+// samplePeriod is the resolution of the CPU utilization and the memory
+// usage of the instances
+const samplePeriod = time.Minute
+
 var (
 	/*
 	* An MQL query that will return data from Google Cloud with the
@@ -20,6 +28,9 @@ var (
 	* - Machine Type
 	* - Reserved CPUs
 	* - Utilization
+	* The query covers the window of the given duration ending at the given date,
+	* the output period is the window so that the single point returned is the
+	* mean of the samples of the exact window, whatever their resolution
 	* NOTE: Using reserved CPUs as vCPUs, because they are equivalent for visible
 	* vCPUs within a guest instance, except for shared-core machines:
 	* https://cloud.google.com/monitoring/api/metrics_gcp
@@ -38,8 +49,9 @@ var (
 		metadata.system.machine_type,
     reserved_cores: format(t_1.value.reserved_cores, '%%f')
   ], [max(t_0.value.utilization)]
-  | window %s
-  | within %s
+  | window %[2]s
+  | every %[2]s
+  | within %s, d'%s'
 	`
 	/*
 	* An MQL query that will return memory data from Google Cloud with the
@@ -63,8 +75,9 @@ var (
 		resource.zone,
 		metadata.system.machine_type,
 	], [max(value.ram_used)]
-	| window %s
-	| within %s
+	| window %[2]s
+	| every %[2]s
+	| within %s, d'%s'
 	`
 )
 
@@ -74,21 +87,20 @@ func (c *Client) instanceMemoryMetrics(
 	ctx context.Context,
 	project, query string,
 ) ([]*v1.Metric, error) {
-	var metrics []*v1.Metric
+	if err := util.WaitForAPI(ctx, provider, monitoringAPI); err != nil {
+		return nil, err
+	}
 
-	it := c.monitoring.QueryTimeSeries(ctx, &monitoringpb.QueryTimeSeriesRequest{
+	data, err := c.monitoring.QueryTimeSeries(ctx, &monitoringpb.QueryTimeSeriesRequest{
 		Name:  fmt.Sprintf("projects/%s", project),
 		Query: query,
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
+	metrics := make([]*v1.Metric, 0, len(data))
+	for _, resp := range data {
 
 		// This is dependant on the MQL query
 		// label ordering
@@ -109,6 +121,8 @@ func (c *Client) instanceMemoryMetrics(
 			"region":       region,
 			"zone":         zone,
 			"machine_type": instanceType,
+
+			util.ResolutionLabel: util.FormatResolution(samplePeriod),
 		}
 		metrics = append(metrics, m)
 	}
@@ -121,23 +135,22 @@ func (c *Client) instanceCPUMetrics(
 	ctx context.Context,
 	project, query string,
 ) ([]*v1.Metric, error) {
-	var metrics []*v1.Metric
-
 	logger := log.FromContext(ctx)
 
-	it := c.monitoring.QueryTimeSeries(ctx, &monitoringpb.QueryTimeSeriesRequest{
+	if err := util.WaitForAPI(ctx, provider, monitoringAPI); err != nil {
+		return nil, err
+	}
+
+	data, err := c.monitoring.QueryTimeSeries(ctx, &monitoringpb.QueryTimeSeriesRequest{
 		Name:  fmt.Sprintf("projects/%s", project),
 		Query: query,
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
+	metrics := make([]*v1.Metric, 0, len(data))
+	for _, resp := range data {
 
 		// This is dependant on the MQL query
 		// label ordering
@@ -170,6 +183,8 @@ func (c *Client) instanceCPUMetrics(
 			"region":       region,
 			"zone":         zone,
 			"machine_type": instanceType,
+
+			util.ResolutionLabel: util.FormatResolution(samplePeriod),
 		}
 		metrics = append(metrics, m)
 	}
@@ -17,20 +17,39 @@ import (
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
 )
 
-func withMonitoringTestClient(mc *monitoring.QueryClient) options {
+func withMonitoringTestClient(mc timeSeriesQuerier) options {
 	return func(c *Client) {
 		c.monitoring = mc
 	}
 }
 
-func withInstancesTestClient(ic *compute.InstancesClient) options {
+func withInstancesTestClient(ic instanceLister) options {
 	return func(c *Client) {
 		c.instances = ic
 	}
 }
 
+func withMachineTypesTestClient(mg machineTypeGetter) options {
+	return func(c *Client) {
+		c.machineTypes = mg
+	}
+}
+
+// machineTypes returns the machine types of the tests, the specs are
+// shared by all of them
+func machineTypes() *fakeMachineTypes {
+	fake := &fakeMachineTypes{}
+	fake.GetReturns(&computepb.MachineType{
+		Name:      proto.String("e2-standard-2"),
+		GuestCpus: proto.Int32(2),
+		MemoryMb:  proto.Int32(8192),
+	}, nil)
+	return fake
+}
+
 type fakeMonitoringServer struct {
 	monitoringpb.UnimplementedQueryServiceServer
 	// Response that will return from the fake server
@@ -163,8 +182,9 @@ func RunTestData(t *testing.T, testdata []TestScenario) {
 
 			g, teardown, err := New(ctx,
 				&config.Account{},
-				withMonitoringTestClient(m),
-				withInstancesTestClient(in),
+				withMonitoringTestClient(&queryClient{m}),
+				withInstancesTestClient(&instancesClient{in}),
+				withMachineTypesTestClient(machineTypes()),
 			)
 			assert.NoError(err)
 			defer teardown()
@@ -202,7 +222,7 @@ func TestGetCPUMetrics(t *testing.T) {
 		{
 			description:  "cpu metrics",
 			scenariotype: st,
-			query:        fmt.Sprintf(CPUQuery, "foobar", "5m", "5m"),
+			query:        fmt.Sprintf(CPUQuery, "foobar", "5m", "5m", "2024/01/01-00:00:00"),
 			responsePointData: []*monitoringpb.TimeSeriesData_PointData{
 				{
 					Values: []*monitoringpb.TypedValue{
@@ -223,6 +243,7 @@ func TestGetCPUMetrics(t *testing.T) {
 						"name":         "foobar",
 						"region":       "europe-west-1",
 						"zone":         "europe-west",
+						"resolution":   "1m",
 					},
 					Usage:      1,
 					UnitAmount: 2.0000,
@@ -232,7 +253,7 @@ func TestGetCPUMetrics(t *testing.T) {
 		{
 			description:  "error occurs in query",
 			scenariotype: st,
-			query:        fmt.Sprintf(CPUQuery, "foobar", "5m", "5m"),
+			query:        fmt.Sprintf(CPUQuery, "foobar", "5m", "5m", "2024/01/01-00:00:00"),
 			err:          errors.New("random error occurred cpu query"),
 		},
 	}
@@ -245,7 +266,7 @@ func TestInstanceMemoryMetrics(t *testing.T) {
 		{
 			description:  "memory metrics returned",
 			scenariotype: st,
-			query:        fmt.Sprintf(MEMQuery, "foobar", "5m", "5m"),
+			query:        fmt.Sprintf(MEMQuery, "foobar", "5m", "5m", "2024/01/01-00:00:00"),
 			responsePointData: []*monitoringpb.TimeSeriesData_PointData{
 				{
 					Values: []*monitoringpb.TypedValue{
@@ -267,6 +288,7 @@ func TestInstanceMemoryMetrics(t *testing.T) {
 						"name":         "foobar",
 						"region":       "europe-west-1",
 						"zone":         "europe-west",
+						"resolution":   "1m",
 					},
 					Usage:      10.0,
 					UnitAmount: 0.0000,
@@ -276,7 +298,7 @@ func TestInstanceMemoryMetrics(t *testing.T) {
 		{
 			description:  "error occurs in query",
 			scenariotype: st,
-			query:        fmt.Sprintf(MEMQuery, "foobar", "5m", "5m"),
+			query:        fmt.Sprintf(MEMQuery, "foobar", "5m", "5m", "2024/01/01-00:00:00"),
 			err:          errors.New("random error occurred in memory query"),
 		},
 	}
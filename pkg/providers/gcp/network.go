@@ -0,0 +1,169 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The services of the network resources
+const (
+	loadBalancingService = "load-balancing"
+	cdnService           = "cloud-cdn"
+)
+
+/*
+* An MQL query that will return the bytes transferred by the external
+* Application Load Balancers with the
+* - Forwarding Rule Name
+* - Backend Scope, the zone or the region of the backend
+* - Cache Result, DISABLED when Cloud CDN isn't enabled on the backend
+* - Request and response bytes over the window
+* The query covers the window of the given duration ending at the given date
+ */
+var NetworkQuery = `
+  fetch https_lb_rule
+  | { metric 'loadbalancing.googleapis.com/https/request_bytes_count'
+    ; metric 'loadbalancing.googleapis.com/https/response_bytes_count' }
+  | join
+  | filter project_id = '%s'
+  | group_by [
+    resource.forwarding_rule_name,
+    resource.backend_scope,
+    metric.cache_result
+  ], [
+    bytes: sum(t_0.value.request_bytes_count) + sum(t_1.value.response_bytes_count)
+  ]
+  | window %s
+  | within %s, d'%s'
+	`
+
+// forwardingRule is the bytes transferred by a forwarding rule of a service
+// in a region
+type forwardingRule struct {
+	name, service, region string
+}
+
+// networkRules returns the forwarding rules of the project with the GB they
+// transferred since their last collection, none when they're not due at the
+// granularity of the network
+func (c *Client) networkRules(ctx context.Context, project string, window v1.Window) ([]v1.Instance, error) {
+	w, due := c.cadence.Due(v1.Network, project, window)
+	if !due {
+		return nil, nil
+	}
+
+	duration := w.Duration().String()
+	end := w.End.UTC().Format(mqlDateFormat)
+	return c.forwardingRules(ctx, project, fmt.Sprintf(NetworkQuery, project, duration, duration, end), w)
+}
+
+// forwardingRules runs a query on google cloud monitoring using MQL and
+// returns the forwarding rules as instances with the GB they transferred
+// over the window, observed over it. The bytes served by Cloud CDN are the ones of its
+// service, and the cache hits, which have no backend, are accounted for in
+// the region of the other backends of the rule
+func (c *Client) forwardingRules(ctx context.Context, project, query string, window v1.Window) ([]v1.Instance, error) {
+	if err := util.WaitForAPI(ctx, provider, monitoringAPI); err != nil {
+		return nil, err
+	}
+
+	data, err := c.monitoring.QueryTimeSeries(ctx, &monitoringpb.QueryTimeSeriesRequest{
+		Name:  fmt.Sprintf("projects/%s", project),
+		Query: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type series struct {
+		rule, scope, cacheResult string
+		bytes                    float64
+	}
+
+	// This is dependant on the MQL query
+	// label ordering
+	all := make([]series, 0, len(data))
+	regions := make(map[string]string)
+	for _, resp := range data {
+		s := series{
+			rule:        resp.GetLabelValues()[0].GetStringValue(),
+			scope:       scopeRegion(resp.GetLabelValues()[1].GetStringValue()),
+			cacheResult: resp.GetLabelValues()[2].GetStringValue(),
+			bytes:       number(resp.GetPointData()[0].GetValues()[0]),
+		}
+		all = append(all, s)
+
+		if _, ok := regions[s.rule]; !ok && s.scope != "" {
+			regions[s.rule] = s.scope
+		}
+	}
+
+	bytes := make(map[forwardingRule]float64)
+	for _, s := range all {
+		region := s.scope
+		if region == "" {
+			region = regions[s.rule]
+		}
+		if region == "" {
+			continue
+		}
+
+		service := cdnService
+		if s.cacheResult == "DISABLED" {
+			service = loadBalancingService
+		}
+
+		bytes[forwardingRule{name: s.rule, service: service, region: region}] += s.bytes
+	}
+
+	rules := make([]forwardingRule, 0, len(bytes))
+	for r := range bytes {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(a, b int) bool {
+		if rules[a].name != rules[b].name {
+			return rules[a].name < rules[b].name
+		}
+		return rules[a].service < rules[b].service
+	})
+
+	instances := make([]v1.Instance, 0, len(rules))
+	for _, r := range rules {
+		i := v1.NewInstance(fmt.Sprintf("%s/%s", r.service, r.name), provider)
+		i.Service = r.service
+		i.Region = r.region
+		i.Labels.Add(v1.NameLabel, r.name)
+		i.Metrics.Upsert(&v1.Metric{
+			Name:         v1.Network.String(),
+			ResourceType: v1.Network,
+			UnitAmount:   bytes[r] / 1e9,
+			Unit:         v1.GB,
+			UpdatedAt:    window.End.UTC(),
+			Observed:     window,
+			Labels:       v1.Labels{},
+		})
+		instances = append(instances, *i)
+	}
+
+	return instances, nil
+}
+
+// scopeRegion returns the region of a backend scope, which is a zone or a
+// region, empty when the scope isn't a location, e.g. for the cache hits
+func scopeRegion(scope string) string {
+	parts := strings.Split(scope, "-")
+	switch {
+	case len(parts) == 3 && len(parts[2]) == 1:
+		return strings.Join(parts[:2], "-")
+	case len(parts) == 2:
+		return scope
+	default:
+		return ""
+	}
+}
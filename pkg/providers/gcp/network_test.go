@@ -0,0 +1,177 @@
+package gcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// ruleSeries returns the bytes of a forwarding rule as returned by the MQL
+// query
+func ruleSeries(rule, scope, cacheResult string, bytes int64) *monitoringpb.TimeSeriesData {
+	values := []*monitoringpb.LabelValue{}
+	for _, l := range []string{rule, scope, cacheResult} {
+		values = append(values, &monitoringpb.LabelValue{
+			Value: &monitoringpb.LabelValue_StringValue{StringValue: l},
+		})
+	}
+
+	return &monitoringpb.TimeSeriesData{
+		LabelValues: values,
+		PointData: []*monitoringpb.TimeSeriesData_PointData{
+			{Values: []*monitoringpb.TypedValue{
+				{Value: &monitoringpb.TypedValue_Int64Value{Int64Value: bytes}},
+			}},
+		},
+	}
+}
+
+func TestScopeRegion(t *testing.T) {
+	assert := require.New(t)
+
+	type testcase struct {
+		scope    string
+		expected string
+	}
+	tt := []testcase{
+		{scope: "europe-west1-b", expected: "europe-west1"},
+		{scope: "northamerica-northeast1-a", expected: "northamerica-northeast1"},
+		{scope: "us-central1", expected: "us-central1"},
+		{scope: "global", expected: ""},
+		{scope: "INVALID_BACKEND_SCOPE", expected: ""},
+		{scope: "", expected: ""},
+	}
+
+	for _, test := range tt {
+		assert.Equal(test.expected, scopeRegion(test.scope), test.scope)
+	}
+}
+
+func TestForwardingRules(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	monitoring := &fakeMonitoring{}
+	monitoring.QueryTimeSeriesCalls(func(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error) {
+		switch {
+		case strings.Contains(req.Query, "cpu/utilization"):
+			return []*monitoringpb.TimeSeriesData{
+				timeSeries("1", "web", &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: 0.5},
+				}, "2.000000"),
+			}, nil
+		case strings.Contains(req.Query, "https_lb_rule"):
+			return []*monitoringpb.TimeSeriesData{
+				ruleSeries("api", "europe-west1-b", "DISABLED", 2e9),
+				ruleSeries("api", "europe-west1-c", "DISABLED", 1e9),
+				ruleSeries("static", "us-central1", "MISS", 5e8),
+				// the cache hits have no backend
+				ruleSeries("static", "INVALID_BACKEND_SCOPE", "HIT", 4e9),
+				// neither a backend nor another series of the rule
+				ruleSeries("orphan", "INVALID_BACKEND_SCOPE", "HIT", 1e9),
+			}, nil
+		}
+		return nil, nil
+	})
+
+	account := &config.Account{Project: "demo", Network: true}
+	c, teardown, err := New(ctx, account,
+		withMonitoringTestClient(monitoring),
+		withInstancesTestClient(&fakeInstances{}),
+		withMachineTypesTestClient(machineTypes()),
+	)
+	assert.NoError(err)
+	defer teardown()
+
+	assert.NoError(c.Refresh(ctx, "demo"))
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	collected, err := c.GetMetricsForInstances(ctx, "demo", v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Len(collected, 2)
+
+	api := collected[0]
+	assert.Equal("load-balancing/api", api.Name)
+	assert.Equal("load-balancing", api.Service)
+	assert.Equal("europe-west1", api.Region)
+	assert.Empty(api.Kind)
+	assert.Equal("api", api.Labels[v1.NameLabel])
+	assert.Equal(v1.Network, api.Metrics[v1.Network.String()].ResourceType)
+	assert.Equal(3.0, api.Metrics[v1.Network.String()].UnitAmount)
+	assert.True(end.Equal(api.Metrics[v1.Network.String()].UpdatedAt))
+
+	// the bytes served by the CDN are accounted for in the region of the
+	// backends
+	static := collected[1]
+	assert.Equal("cloud-cdn/static", static.Name)
+	assert.Equal("cloud-cdn", static.Service)
+	assert.Equal("us-central1", static.Region)
+	assert.Equal(4.5, static.Metrics[v1.Network.String()].UnitAmount)
+}
+
+func TestGranularity(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	util.SetGranularity(provider, map[string]time.Duration{"network": time.Hour, "storage": 15 * time.Minute})
+	defer util.SetGranularity(provider, nil)
+
+	var queries []string
+	monitoring := &fakeMonitoring{}
+	monitoring.QueryTimeSeriesCalls(func(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error) {
+		switch {
+		case strings.Contains(req.Query, "https_lb_rule"):
+			queries = append(queries, req.Query)
+			return []*monitoringpb.TimeSeriesData{
+				ruleSeries("api", "europe-west1-b", "DISABLED", 2e9),
+			}, nil
+		case strings.Contains(req.Query, "disk/read_ops_count"):
+			queries = append(queries, req.Query)
+		}
+		return nil, nil
+	})
+
+	account := &config.Account{Project: "demo", Network: true, Storage: true}
+	c, teardown, err := New(ctx, account,
+		withMonitoringTestClient(monitoring),
+		withInstancesTestClient(&fakeInstances{}),
+		withMachineTypesTestClient(machineTypes()),
+	)
+	assert.NoError(err)
+	defer teardown()
+
+	// the first scrape collects them over its window
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	collected, err := c.GetMetricsForInstances(ctx, "demo", v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Len(collected, 1)
+	assert.Len(queries, 2)
+	assert.Contains(queries[1], "within 5m0s")
+
+	// then once their interval elapsed
+	for i := 1; i < 12; i++ {
+		collected, err = c.GetMetricsForInstances(ctx, "demo", v1.NewWindow(end.Add(time.Duration(i)*5*time.Minute), 5*time.Minute))
+		assert.NoError(err)
+		assert.Empty(collected)
+	}
+	assert.Len(queries, 5)
+	assert.Contains(queries[2], "within 15m0s")
+
+	collected, err = c.GetMetricsForInstances(ctx, "demo", v1.NewWindow(end.Add(time.Hour), 5*time.Minute))
+	assert.NoError(err)
+	assert.Len(collected, 1)
+	assert.Len(queries, 7)
+	assert.Contains(queries[6], "within 1h0m0s")
+
+	// the GB are observed over the hour
+	network := collected[0].Metrics[v1.Network.String()]
+	assert.Equal(time.Hour, network.Observed.Duration())
+	assert.True(end.Add(time.Hour).Equal(network.Observed.End))
+}
@@ -0,0 +1,287 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cache "github.com/patrickmn/go-cache"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The service of the sole-tenant nodes in the cache
+const nodeService = "node"
+
+// The labels of the sole-tenant nodes
+const (
+	nodeGroupLabel = "node_group"
+	nodeTypeLabel  = "node_type"
+
+	// The names of the instances running on the node
+	tenantsLabel = "tenants"
+)
+
+// host is a sole-tenant node and the instances running on it
+type host struct {
+	Name  string
+	Zone  string
+	Group string
+
+	// The node type, e.g. n2-node-80-640, and its vCPUs
+	Type string
+	VCPU float64
+
+	// The names of the instances running on the node
+	Instances []string
+}
+
+// refreshNodes caches the ready sole-tenant nodes of the project
+func (c *Client) refreshNodes(ctx context.Context, project string) error {
+	logger := log.FromContext(ctx)
+
+	if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+		return err
+	}
+
+	nodes, err := c.nodes.ListNodes(ctx, project)
+	if err != nil {
+		return fmt.Errorf("failed listing the sole-tenant nodes: %w", err)
+	}
+
+	// the nodes deleted since the last refresh are dropped
+	for key, item := range c.cache.Items() {
+		if _, ok := item.Object.(host); ok {
+			c.cache.Delete(key)
+		}
+	}
+
+	for _, n := range nodes {
+		if n.Node.GetStatus() != "READY" {
+			continue
+		}
+
+		h := host{
+			Name:  n.Node.GetName(),
+			Zone:  n.Zone,
+			Group: n.Group,
+			Type:  n.Node.GetNodeType(),
+			VCPU:  float64(n.Node.GetTotalResources().GetGuestCpus()),
+		}
+		if h.VCPU == 0 {
+			h.VCPU = nodeTypeVCPU(h.Type)
+		}
+		if h.VCPU == 0 {
+			logger.Warn("unknown vCPUs of the sole-tenant node", "node", h.Name, "type", h.Type)
+			continue
+		}
+
+		for _, instance := range n.Node.GetInstances() {
+			name, err := getValueFromURL(instance)
+			if err != nil {
+				continue
+			}
+			h.Instances = append(h.Instances, name)
+		}
+
+		c.cache.Set(util.CacheKey(h.Zone, nodeService, h.Name), h, cache.DefaultExpiration)
+	}
+
+	return nil
+}
+
+// nodeTypeVCPU returns the vCPUs of a node type, e.g. 80 for n2-node-80-640,
+// zero when the type has another format
+func nodeTypeVCPU(nodeType string) float64 {
+	parts := strings.Split(nodeType, "-")
+	if len(parts) != 4 || parts[1] != "node" {
+		return 0
+	}
+
+	vCPU, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0
+	}
+	return vCPU
+}
+
+// hosts replaces the instances running on the sole-tenant nodes by their
+// nodes. The tenant pays for the whole host, so the emissions of a node are
+// the ones of all its vCPUs, the idle ones included, rather than the share of
+// its instances. The usage of a node is the one of the vCPUs of its instances
+// over all its vCPUs, and it's calculated as the kind of its largest instance.
+// The memory and the disks of the instances are the ones of their node
+func (c *Client) hosts(instances []v1.Instance, window v1.Window) []v1.Instance {
+	var hosts []host
+	for _, item := range c.cache.Items() {
+		if h, ok := item.Object.(host); ok {
+			hosts = append(hosts, h)
+		}
+	}
+	if len(hosts) == 0 {
+		return instances
+	}
+
+	// the node of every instance, by zone and name
+	nodeOf := make(map[string]int)
+	for i, h := range hosts {
+		for _, name := range h.Instances {
+			nodeOf[util.CacheKey(h.Zone, service, name)] = i
+		}
+	}
+
+	tenants := make([][]*v1.Instance, len(hosts))
+	result := make([]v1.Instance, 0, len(instances))
+	for i := range instances {
+		n, ok := nodeOf[util.CacheKey(instances[i].Zone, service, instances[i].Labels[v1.NameLabel])]
+		if !ok {
+			result = append(result, instances[i])
+			continue
+		}
+		tenants[n] = append(tenants[n], &instances[i])
+	}
+
+	for i := range hosts {
+		result = append(result, hostInstance(&hosts[i], tenants[i], window))
+	}
+
+	return result
+}
+
+// hostInstance returns the instance of a sole-tenant node, with the usage of
+// the vCPUs of its tenants over all its vCPUs, their memory, their disks and
+// the labels they share
+func hostInstance(h *host, tenants []*v1.Instance, window v1.Window) v1.Instance {
+	i := v1.NewInstance(h.Name, provider)
+	i.Service = service
+	i.Zone = h.Zone
+	i.Region = h.Zone[:max(strings.LastIndex(h.Zone, "-"), 0)]
+	i.HostVCPU = h.VCPU
+	i.Labels.Add(v1.NameLabel, h.Name)
+	i.Labels.Add(nodeGroupLabel, h.Group)
+	i.Labels.Add(nodeTypeLabel, h.Type)
+
+	// the kind of the largest instance, the first by name on ties, is the
+	// one of the family of the node
+	sort.Slice(tenants, func(a, b int) bool {
+		va, vb := tenants[a].Metrics[v1.CPU.String()].UnitAmount, tenants[b].Metrics[v1.CPU.String()].UnitAmount
+		if va != vb {
+			return va > vb
+		}
+		return tenants[a].Labels[v1.NameLabel] < tenants[b].Labels[v1.NameLabel]
+	})
+
+	var used float64
+	for _, t := range tenants {
+		cpu := t.Metrics[v1.CPU.String()]
+		used += cpu.Usage * cpu.UnitAmount
+	}
+
+	if len(tenants) > 0 {
+		i.Kind = tenants[0].Kind
+		i.Region = tenants[0].Region
+		i.Architecture = tenants[0].Architecture
+		i.CPUPlatform = tenants[0].CPUPlatform
+	} else {
+		// the empty nodes are calculated as the standard machine type
+		// of their vCPUs
+		family, _, _ := strings.Cut(h.Type, "-")
+		i.Kind = fmt.Sprintf("%s-standard-%g", family, h.VCPU)
+	}
+
+	i.Metrics.Upsert(&v1.Metric{
+		Name:         v1.CPU.String(),
+		ResourceType: v1.CPU,
+		Usage:        min(used/h.VCPU, 100),
+		UnitAmount:   h.VCPU,
+		Unit:         v1.VCPU,
+		UpdatedAt:    window.End.UTC(),
+		Labels:       v1.Labels{},
+	})
+
+	tenantMetrics(i, tenants)
+	tenantLabels(i, tenants)
+
+	return *i
+}
+
+// tenantMetrics adds the metrics of the tenants but the CPU to the node:
+// their memory is summed and their disks, unique by name in the zone of the
+// node, are added as they are
+func tenantMetrics(i *v1.Instance, tenants []*v1.Instance) {
+	for _, t := range tenants {
+		for _, m := range t.Metrics {
+			switch m.ResourceType {
+			case v1.CPU:
+				continue
+			case v1.Memory:
+				memory, ok := i.Metrics[m.Name]
+				if !ok {
+					// the labels are the ones of the first tenant
+					memory = m
+					memory.Labels = v1.Labels{}
+					if r, ok := m.Labels[util.ResolutionLabel]; ok {
+						memory.Labels.Add(util.ResolutionLabel, r)
+					}
+				} else {
+					memory.Usage += m.Usage
+					memory.UnitAmount += m.UnitAmount
+					memory.Observed = union(memory.Observed, m.Observed)
+				}
+				i.Metrics.Upsert(&memory)
+			default:
+				i.Metrics.Upsert(&m)
+			}
+		}
+	}
+}
+
+// tenantLabels adds the labels all the tenants have with the same value to
+// the node, but their names, and lists their names in the tenants label
+func tenantLabels(i *v1.Instance, tenants []*v1.Instance) {
+	if len(tenants) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(tenants))
+	for _, t := range tenants {
+		names = append(names, t.Labels[v1.NameLabel])
+	}
+	sort.Strings(names)
+	i.Labels.Add(tenantsLabel, strings.Join(names, ","))
+
+	for k, v := range tenants[0].Labels {
+		if k == v1.NameLabel {
+			continue
+		}
+		shared := true
+		for _, t := range tenants[1:] {
+			if w, ok := t.Labels[k]; !ok || w != v {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			i.Labels.Add(k, v)
+		}
+	}
+}
+
+// union returns the window covering both windows, the zero one when either
+// covers the whole interval
+func union(a, b v1.Window) v1.Window {
+	if a.IsZero() || b.IsZero() {
+		return v1.Window{}
+	}
+
+	if b.Start.Before(a.Start) {
+		a.Start = b.Start
+	}
+	if b.End.After(a.End) {
+		a.End = b.End
+	}
+	return a
+}
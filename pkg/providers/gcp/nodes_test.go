@@ -0,0 +1,175 @@
+package gcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func withNodesTestClient(nl nodeLister) options {
+	return func(c *Client) {
+		c.nodes = nl
+	}
+}
+
+func TestNodeTypeVCPU(t *testing.T) {
+	assert := require.New(t)
+
+	type testcase struct {
+		nodeType string
+		expected float64
+	}
+	tt := []testcase{
+		{nodeType: "n2-node-80-640", expected: 80},
+		{nodeType: "c2-node-60-240", expected: 60},
+		{nodeType: "n1-standard-2", expected: 0},
+		{nodeType: "n2-node-eighty-640", expected: 0},
+		{nodeType: "", expected: 0},
+	}
+
+	for _, test := range tt {
+		assert.Equal(test.expected, nodeTypeVCPU(test.nodeType), test.nodeType)
+	}
+}
+
+func TestHosts(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	nodes := &fakeNodes{}
+	nodes.ListNodesReturns([]soleTenantNode{
+		{
+			Zone:  "europe-west1-b",
+			Group: "tenants",
+			Node: &computepb.NodeGroupNode{
+				Name:     proto.String("node-1"),
+				NodeType: proto.String("n2-node-80-640"),
+				Status:   proto.String("READY"),
+				Instances: []string{
+					"https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b/instances/web",
+					"https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b/instances/db",
+				},
+			},
+		},
+		{
+			Zone:  "europe-west1-b",
+			Group: "tenants",
+			Node: &computepb.NodeGroupNode{
+				Name:           proto.String("node-2"),
+				NodeType:       proto.String("n2-custom-node"),
+				Status:         proto.String("READY"),
+				TotalResources: &computepb.InstanceConsumptionInfo{GuestCpus: proto.Int32(96)},
+			},
+		},
+		// the nodes being created or deleted aren't accounted for
+		{
+			Zone:  "europe-west1-b",
+			Group: "tenants",
+			Node: &computepb.NodeGroupNode{
+				Name:     proto.String("node-3"),
+				NodeType: proto.String("n2-node-80-640"),
+				Status:   proto.String("CREATING"),
+			},
+		},
+	}, nil)
+
+	c, teardown, err := New(ctx, &config.Account{Project: "demo"},
+		withMonitoringTestClient(&fakeMonitoring{}),
+		withInstancesTestClient(&fakeInstances{}),
+		withMachineTypesTestClient(machineTypes()),
+		withNodesTestClient(nodes),
+	)
+	assert.NoError(err)
+	defer teardown()
+
+	assert.NoError(c.Refresh(ctx, "demo"))
+	assert.Equal(1, nodes.ListNodesCallCount())
+
+	instance := func(name, kind string, vCPU, usage float64) v1.Instance {
+		i := v1.NewInstance(name, provider)
+		i.Kind = kind
+		i.Region = "europe-west1"
+		i.Zone = "europe-west1-b"
+		i.Labels.Add(v1.NameLabel, name)
+		i.Labels.Add("tag_team", "shop")
+		i.Labels.Add("tag_app", name)
+		i.Metrics.Upsert(&v1.Metric{
+			Name:         v1.CPU.String(),
+			ResourceType: v1.CPU,
+			Usage:        usage,
+			UnitAmount:   vCPU,
+		})
+		i.Metrics.Upsert(&v1.Metric{
+			Name:         v1.Memory.String(),
+			ResourceType: v1.Memory,
+			Unit:         v1.GB,
+			Usage:        vCPU,
+			UnitAmount:   vCPU * 4,
+			Labels:       v1.Labels{"name": name, util.ResolutionLabel: "1m"},
+		})
+		i.Metrics.Upsert(&v1.Metric{
+			Name:         name + "-disk",
+			ResourceType: v1.Storage,
+			Unit:         v1.GB,
+			UnitAmount:   100,
+		})
+		return *i
+	}
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	hosts := c.hosts([]v1.Instance{
+		instance("web", "n2-standard-16", 16, 50),
+		instance("db", "n2-standard-32", 32, 25),
+		instance("shared", "e2-standard-2", 2, 10),
+	}, v1.NewWindow(end, 5*time.Minute))
+	assert.Len(hosts, 3)
+
+	byName := make(map[string]v1.Instance)
+	for _, h := range hosts {
+		byName[h.Name] = h
+	}
+
+	// the instances outside of the nodes are left as they are
+	assert.Equal(10.0, byName["shared"].Metrics[v1.CPU.String()].Usage)
+	assert.Zero(byName["shared"].HostVCPU)
+
+	// the node is accounted for as a whole, as the kind of its largest
+	// instance
+	node := byName["node-1"]
+	assert.Equal("n2-standard-32", node.Kind)
+	assert.Equal("europe-west1", node.Region)
+	assert.Equal(80.0, node.HostVCPU)
+	assert.Equal("tenants", node.Labels[nodeGroupLabel])
+	assert.Equal("n2-node-80-640", node.Labels[nodeTypeLabel])
+	assert.Equal(80.0, node.Metrics[v1.CPU.String()].UnitAmount)
+	assert.Equal((16*50+32*25)/80.0, node.Metrics[v1.CPU.String()].Usage)
+	assert.True(end.Equal(node.Metrics[v1.CPU.String()].UpdatedAt))
+
+	// with the memory and the disks of its instances, and the labels they
+	// share
+	memory := node.Metrics[v1.Memory.String()]
+	assert.Equal(v1.Memory, memory.ResourceType)
+	assert.Equal(16*4+32*4.0, memory.UnitAmount)
+	assert.Equal(16+32.0, memory.Usage)
+	assert.Equal(v1.Labels{util.ResolutionLabel: "1m"}, memory.Labels)
+	assert.Equal(100.0, node.Metrics["web-disk"].UnitAmount)
+	assert.Equal(100.0, node.Metrics["db-disk"].UnitAmount)
+	assert.Len(node.Metrics, 4)
+	assert.Equal("shop", node.Labels["tag_team"])
+	assert.Equal("db,web", node.Labels[tenantsLabel])
+	assert.NotContains(node.Labels, "tag_app")
+
+	// the empty nodes are idle
+	node = byName["node-2"]
+	assert.Equal("n2-standard-96", node.Kind)
+	assert.Equal("europe-west1", node.Region)
+	assert.Equal(96.0, node.HostVCPU)
+	assert.Zero(node.Metrics[v1.CPU.String()].Usage)
+}
@@ -1,6 +1,33 @@
 package gcp
 
-import v1 "github.com/re-cinq/aether/pkg/types/v1"
+import (
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
 
 const provider = v1.GCP
 const service = "GCE"
+
+// The control plane of a regional GKE cluster is replicated in three zones,
+// the zonal clusters have a single replica
+var gkeControlPlane = util.ControlPlane{
+	Service:  "gke",
+	Kind:     "e2-standard-4",
+	VCPU:     4,
+	Replicas: 3,
+	Usage:    10,
+}
+
+// API families, used for rate limiting
+const (
+	computeAPI     = "compute"
+	monitoringAPI  = "monitoring"
+	recommenderAPI = "recommender"
+)
+
+func init() {
+	// The default quotas of the requests per minute of a project: the read
+	// requests of the compute API and the time series queries
+	util.SetDefaultQuota(provider, computeAPI, 1500)
+	util.SetDefaultQuota(provider, monitoringAPI, 6000)
+}
@@ -0,0 +1,252 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/rightsizing"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// The endpoint of the Recommender API
+const defaultRecommenderEndpoint = "https://recommender.googleapis.com"
+
+// The recommender of the machine types of the instances
+const machineTypeRecommender = "google.compute.instance.MachineTypeRecommender"
+
+// Recommender pulls the machine type recommendations of the GCP Recommender
+// for the instances of a project
+type Recommender struct {
+	project  string
+	endpoint string
+
+	// The zones are the ones of the running instances
+	instances instanceLister
+	client    *http.Client
+}
+
+// NewRecommender returns the recommender of the project of the account
+func NewRecommender(ctx context.Context, account *config.Account) (*Recommender, error) {
+	var clientOptions []option.ClientOption
+	if account.Credentials.IsPresent() {
+		clientOptions = append(clientOptions, option.WithCredentialsFile(account.Credentials.FilePaths[0]))
+	}
+
+	computeOptions := clientOptions
+	if endpoint := account.Endpoints[computeAPI]; endpoint != "" {
+		computeOptions = append(slices.Clip(clientOptions), option.WithEndpoint(endpoint))
+	}
+	ic, err := compute.NewInstancesRESTClient(ctx, computeOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	client, _, err := htransport.NewClient(ctx, append(slices.Clip(clientOptions), option.WithScopes("https://www.googleapis.com/auth/cloud-platform"))...)
+	if err != nil {
+		ic.Close()
+		return nil, err
+	}
+
+	endpoint := account.Endpoints[recommenderAPI]
+	if endpoint == "" {
+		endpoint = defaultRecommenderEndpoint
+	}
+
+	return &Recommender{
+		project:   account.Project,
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		instances: &instancesClient{ic},
+		client:    client,
+	}, nil
+}
+
+// recommendation is the part of the recommendations read
+type recommendation struct {
+	PrimaryImpact struct {
+		CostProjection struct {
+			Cost struct {
+				CurrencyCode string `json:"currencyCode"`
+				Units        string `json:"units"`
+				Nanos        int64  `json:"nanos"`
+			} `json:"cost"`
+			Duration string `json:"duration"`
+		} `json:"costProjection"`
+	} `json:"primaryImpact"`
+	StateInfo struct {
+		State string `json:"state"`
+	} `json:"stateInfo"`
+	Content struct {
+		OperationGroups []struct {
+			Operations []struct {
+				Action   string `json:"action"`
+				Resource string `json:"resource"`
+				Path     string `json:"path"`
+				Value    any    `json:"value"`
+			} `json:"operations"`
+		} `json:"operationGroups"`
+	} `json:"content"`
+}
+
+// Recommendations returns the active machine type recommendations of the
+// zones of the running instances of the project
+func (r *Recommender) Recommendations(ctx context.Context) ([]rightsizing.Recommendation, error) {
+	if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+		return nil, err
+	}
+
+	instances, err := r.instances.AggregatedList(ctx, &computepb.AggregatedListInstancesRequest{
+		Project: r.project,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed listing the instances: %w", r.project, err)
+	}
+
+	zones := make(map[string]bool)
+	for _, instance := range instances {
+		if instance.GetStatus() == "RUNNING" && instance.GetZone() != "" {
+			zones[path.Base(instance.GetZone())] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(zones))
+	for zone := range zones {
+		sorted = append(sorted, zone)
+	}
+	sort.Strings(sorted)
+
+	var out []rightsizing.Recommendation
+	for _, zone := range sorted {
+		recommendations, err := r.zoneRecommendations(ctx, zone)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", r.project, zone, err)
+		}
+		out = append(out, recommendations...)
+	}
+
+	return out, nil
+}
+
+func (r *Recommender) zoneRecommendations(ctx context.Context, zone string) ([]rightsizing.Recommendation, error) {
+	var out []rightsizing.Recommendation
+
+	var token string
+	for {
+		var resp struct {
+			Recommendations []recommendation `json:"recommendations"`
+			NextPageToken   string           `json:"nextPageToken"`
+		}
+		if err := r.list(ctx, zone, token, &resp); err != nil {
+			return nil, err
+		}
+
+		for i := range resp.Recommendations {
+			if rec, ok := r.convert(&resp.Recommendations[i], zone); ok {
+				out = append(out, rec)
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		token = resp.NextPageToken
+	}
+}
+
+// convert returns the recommendation of an active machine type change
+func (r *Recommender) convert(rec *recommendation, zone string) (rightsizing.Recommendation, bool) {
+	if rec.StateInfo.State != "ACTIVE" {
+		return rightsizing.Recommendation{}, false
+	}
+
+	// the operations test the current machine type and replace it
+	var name, current, recommended string
+	for _, group := range rec.Content.OperationGroups {
+		for _, op := range group.Operations {
+			value, ok := op.Value.(string)
+			if op.Path != "/machineType" || !ok {
+				continue
+			}
+			switch op.Action {
+			case "test":
+				current = path.Base(value)
+			case "replace":
+				recommended = path.Base(value)
+				name = path.Base(op.Resource)
+			}
+		}
+	}
+	if name == "" || current == "" || recommended == "" || current == recommended {
+		return rightsizing.Recommendation{}, false
+	}
+
+	// the cost projection is the cost added over its duration, negative
+	// when saved
+	cost := &rec.PrimaryImpact.CostProjection.Cost
+	units, _ := strconv.ParseFloat(cost.Units, 64)
+	savings := -(units + float64(cost.Nanos)/1e9)
+	if d, err := time.ParseDuration(rec.PrimaryImpact.CostProjection.Duration); err == nil && d > 0 {
+		savings *= float64(730*time.Hour) / float64(d)
+	}
+
+	finding := rightsizing.FindingOverprovisioned
+	if savings < 0 {
+		finding = rightsizing.FindingUnderprovisioned
+	}
+
+	return rightsizing.Recommendation{
+		Provider:    provider,
+		Account:     r.project,
+		Name:        name,
+		Region:      zone[:strings.LastIndex(zone, "-")],
+		Zone:        zone,
+		Kind:        current,
+		Recommended: recommended,
+		Finding:     finding,
+		CostSavings: savings,
+		Currency:    cost.CurrencyCode,
+	}, true
+}
+
+// list reads a page of the recommendations of the zone
+func (r *Recommender) list(ctx context.Context, zone, token string, out any) (err error) {
+	defer func() { util.RecordAPICall(provider, recommenderAPI, "ListRecommendations", err) }()
+
+	u := fmt.Sprintf("%s/v1/projects/%s/locations/%s/recommenders/%s/recommendations",
+		r.endpoint, url.PathEscape(r.project), url.PathEscape(zone), machineTypeRecommender)
+	if token != "" {
+		u += "?pageToken=" + url.QueryEscape(token)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ListRecommendations: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
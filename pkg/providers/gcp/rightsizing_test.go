@@ -0,0 +1,97 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/re-cinq/aether/pkg/rightsizing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRecommendations(t *testing.T) {
+	assert := require.New(t)
+
+	instances := &fakeInstances{}
+	instances.AggregatedListReturns([]*computepb.Instance{
+		{Name: proto.String("vm-1"), Status: proto.String("RUNNING"), Zone: proto.String("https://www.googleapis.com/compute/v1/projects/shop/zones/europe-west1-b")},
+		{Name: proto.String("vm-2"), Status: proto.String("RUNNING"), Zone: proto.String("https://www.googleapis.com/compute/v1/projects/shop/zones/europe-west1-b")},
+		{Name: proto.String("vm-3"), Status: proto.String("TERMINATED"), Zone: proto.String("https://www.googleapis.com/compute/v1/projects/shop/zones/us-central1-a")},
+	}, nil)
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.String())
+
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"nextPageToken": "page2", "recommendations": [
+				{
+					"primaryImpact": {"category": "COST", "costProjection": {"cost": {"currencyCode": "USD", "units": "-48", "nanos": -500000000}, "duration": "2592000s"}},
+					"stateInfo": {"state": "ACTIVE"},
+					"content": {"operationGroups": [{"operations": [
+						{"action": "test", "resource": "//compute.googleapis.com/projects/shop/zones/europe-west1-b/instances/vm-1", "path": "/machineType", "value": "zones/europe-west1-b/machineTypes/e2-standard-4"},
+						{"action": "replace", "resource": "//compute.googleapis.com/projects/shop/zones/europe-west1-b/instances/vm-1", "path": "/machineType", "value": "zones/europe-west1-b/machineTypes/e2-standard-2"}
+					]}]}
+				},
+				{
+					"primaryImpact": {"category": "COST", "costProjection": {"cost": {"currencyCode": "USD", "units": "-10"}, "duration": "2592000s"}},
+					"stateInfo": {"state": "DISMISSED"},
+					"content": {"operationGroups": [{"operations": [
+						{"action": "test", "resource": "//compute.googleapis.com/projects/shop/zones/europe-west1-b/instances/vm-2", "path": "/machineType", "value": "zones/europe-west1-b/machineTypes/e2-standard-4"},
+						{"action": "replace", "resource": "//compute.googleapis.com/projects/shop/zones/europe-west1-b/instances/vm-2", "path": "/machineType", "value": "zones/europe-west1-b/machineTypes/e2-standard-2"}
+					]}]}
+				}
+			]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"recommendations": [
+			{
+				"primaryImpact": {"category": "PERFORMANCE", "costProjection": {"cost": {"currencyCode": "USD", "units": "24"}, "duration": "2592000s"}},
+				"stateInfo": {"state": "ACTIVE"},
+				"content": {"operationGroups": [{"operations": [
+					{"action": "test", "resource": "//compute.googleapis.com/projects/shop/zones/europe-west1-b/instances/vm-2", "path": "/machineType", "value": "zones/europe-west1-b/machineTypes/n2-standard-2"},
+					{"action": "replace", "resource": "//compute.googleapis.com/projects/shop/zones/europe-west1-b/instances/vm-2", "path": "/machineType", "value": "zones/europe-west1-b/machineTypes/n2-standard-4"}
+				]}]}
+			}
+		]}`))
+	}))
+	defer server.Close()
+
+	r := &Recommender{
+		project:   "shop",
+		endpoint:  server.URL,
+		instances: instances,
+		client:    server.Client(),
+	}
+
+	recommendations, err := r.Recommendations(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]string{
+		"/v1/projects/shop/locations/europe-west1-b/recommenders/google.compute.instance.MachineTypeRecommender/recommendations",
+		"/v1/projects/shop/locations/europe-west1-b/recommenders/google.compute.instance.MachineTypeRecommender/recommendations?pageToken=page2",
+	}, requests)
+
+	assert.Len(recommendations, 2)
+	assert.InDelta(49.17, recommendations[0].CostSavings, 0.01)
+	recommendations[0].CostSavings = 0
+	assert.Equal(rightsizing.Recommendation{
+		Provider:    provider,
+		Account:     "shop",
+		Name:        "vm-1",
+		Region:      "europe-west1",
+		Zone:        "europe-west1-b",
+		Kind:        "e2-standard-4",
+		Recommended: "e2-standard-2",
+		Finding:     rightsizing.FindingOverprovisioned,
+		Currency:    "USD",
+	}, recommendations[0])
+
+	assert.Equal("vm-2", recommendations[1].Name)
+	assert.Equal("n2-standard-4", recommendations[1].Recommended)
+	assert.Equal(rightsizing.FindingUnderprovisioned, recommendations[1].Finding)
+	assert.Less(recommendations[1].CostSavings, 0.0)
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"time"
+	"net/http"
 
 	"github.com/re-cinq/aether/pkg/bus"
 	"github.com/re-cinq/aether/pkg/config"
 	"github.com/re-cinq/aether/pkg/log"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Scraper is used to handle scraping google for various metrics
@@ -18,9 +21,8 @@ type Scraper struct {
 	// Google Client
 	*Client
 
-	// Ticker
-	ticker *time.Ticker
-	Done   chan bool
+	// The account identifier
+	account string
 
 	// The Project that Client is setup for
 	Project *string
@@ -34,110 +36,105 @@ type Scraper struct {
 	logger *slog.Logger
 }
 
-// SetupScrapers instantiates a slice of instances of the Google Scraper configured
-// for use, one per project configured
-func SetupScrapers(ctx context.Context, b *bus.Bus) []v1.Scraper {
-	cfg, exists := config.AppConfig().Providers[provider]
+// NewScraper returns a Google Scraper configured for the project of the
+// account and populates its cache
+func NewScraper(ctx context.Context, b *bus.Bus, account *config.Account) (v1.Scraper, error) {
 	logger := log.FromContext(ctx)
 
-	// If the provider is not configured - skip its initialization
-	if !exists {
-		return nil
+	c, shutdown, err := New(ctx, account)
+	if err != nil {
+		return nil, err
 	}
 
-	var scrapers []v1.Scraper
-
-	// we instantiate a scraper per project
-	for index := range cfg.Accounts {
-		account := cfg.Accounts[index]
-
-		ticker := time.NewTicker(config.AppConfig().ProvidersConfig.Interval)
-
-		c, shutdown, err := New(ctx, &account)
-		if err != nil {
-			return nil
-		}
-
-		// this is where we populate the cache
-		c.Refresh(ctx, account.Project)
-
-		scrapers = append(scrapers, &Scraper{
-			ticker:   ticker,
-			Done:     make(chan bool),
-			Project:  &account.Project,
-			Bus:      b,
-			Client:   c,
-			Shutdown: shutdown,
-			logger:   logger,
-		})
+	// this is where we populate the cache
+	if err := c.Refresh(ctx, account.Project); err != nil {
+		logger.Error("error refreshing cache for project", "project", account.Project, "error", err)
 	}
 
-	return scrapers
+	project := account.Project
+
+	return &Scraper{
+		account:  account.ID(),
+		Project:  &project,
+		Bus:      b,
+		Client:   c,
+		Shutdown: shutdown,
+		logger:   logger,
+	}, nil
 }
 
-// Start runs the scraper at the interval set by the ticker
-func (s *Scraper) Start(ctx context.Context) {
-	go func() {
-		for {
-			select {
-			case <-s.Done:
-				return
-			case <-s.ticker.C:
-				s.logger.Info("running scraper for gcp")
-				err := s.scrape(ctx)
-				if err != nil {
-					s.logger.Error("scraping error", "error", err)
-				}
-			}
-		}
-	}()
+// Provider returns the provider the scraper is collecting data from
+func (s *Scraper) Provider() v1.Provider {
+	return provider
+}
 
-	// we run the scraper once first in order to populate data as quickly as
-	// possible
-	err := s.scrape(ctx)
-	if err != nil {
-		s.logger.Error("scraping error", "error", err)
-	}
+// Account returns the identifier of the account being scraped
+func (s *Scraper) Account() string {
+	return s.account
 }
 
-// scrape handles updating and fetching the data from google
-func (s *Scraper) scrape(ctx context.Context) error {
+// Scrape handles updating and fetching the data from google
+func (s *Scraper) Scrape(ctx context.Context, window v1.Window) (int, error) {
 	if s.Project == nil {
-		return errors.New("no project set")
+		return 0, errors.New("no project set")
 	}
 
 	// we need to repopulate the cahce on every scrape
 	// TODO: maybe we dont need cache?
-	s.Client.Refresh(ctx, *s.Project)
+	if err := s.Client.Refresh(ctx, *s.Project); err != nil {
+		return 0, throttled(err)
+	}
 
-	interval := config.AppConfig().Interval
+	// the flows of the previous summary are used when it can't be read
+	if err := s.flowLogs.Refresh(); err != nil {
+		s.logger.Warn("failed to read the flow logs", "error", err)
+	}
 
-	instances, err := s.Client.GetMetricsForInstances(ctx, *s.Project, interval.String())
+	instances, err := s.Client.GetMetricsForInstances(ctx, *s.Project, window)
 
 	if err != nil {
-		return fmt.Errorf("failed getting instances: %v", err)
+		return 0, throttled(fmt.Errorf("failed getting instances: %w", err))
+	}
+
+	// the control planes are accounted for without metrics
+	for i := range s.controlPlanes {
+		instances = append(instances, s.controlPlanes[i].Instance(provider, window))
 	}
 
 	for i := range instances {
-		e := s.Bus.Publish(&bus.Event{
+		instances[i].Labels = instances[i].Labels.With(v1.AccountLabel, s.account).With(v1.ProjectLabel, *s.Project)
+		s.flowLogs.Apply(&instances[i], window)
+
+		e := s.Bus.PublishContext(ctx, &bus.Event{
 			Type: v1.MetricsCollectedEvent,
 			Data: instances[i],
 		})
 		if e != nil {
-			err = errors.Join(
-				err,
-				fmt.Errorf("failed to publish for instance %s: %v", instances[i].Name, e),
-			)
+			s.logger.Error("failed to publish instance", "instance", instances[i].Name, "error", e)
 		}
 	}
 
-	return err
+	return len(instances), nil
 }
 
 // Stop is used to gracefully stop the scrapper
 func (s *Scraper) Stop(ctx context.Context) {
-	s.Done <- true
-
-	s.ticker.Stop()
 	s.Shutdown()
 }
+
+// Flush drops the cached instances, they are fetched again by the next scrape
+func (s *Scraper) Flush() {
+	s.Client.cache.Flush()
+}
+
+// throttled marks the errors of the requests rejected by the Google APIs
+// because of their rate, or of the quota, with v1.ErrProviderThrottled
+func throttled(err error) error {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) && gerr.Code == http.StatusTooManyRequests ||
+		status.Code(err) == codes.ResourceExhausted {
+		return fmt.Errorf("%w: %w", v1.ErrProviderThrottled, err)
+	}
+
+	return err
+}
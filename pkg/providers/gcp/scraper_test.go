@@ -0,0 +1,272 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// timeSeries returns the data of an instance as returned by the MQL queries
+func timeSeries(id, name string, value *monitoringpb.TypedValue, labels ...string) *monitoringpb.TimeSeriesData {
+	values := []*monitoringpb.LabelValue{}
+	for _, l := range append([]string{id, name, "europe-west1", "europe-west1-b", "e2-standard-2"}, labels...) {
+		values = append(values, &monitoringpb.LabelValue{
+			Value: &monitoringpb.LabelValue_StringValue{StringValue: l},
+		})
+	}
+
+	return &monitoringpb.TimeSeriesData{
+		LabelValues: values,
+		PointData: []*monitoringpb.TimeSeriesData_PointData{
+			{Values: []*monitoringpb.TypedValue{value}},
+		},
+	}
+}
+
+func TestScrape(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	instances := &fakeInstances{}
+	instances.AggregatedListReturns([]*computepb.Instance{
+		{
+			Id:          proto.Uint64(1),
+			Name:        proto.String("web"),
+			Zone:        proto.String("https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b"),
+			MachineType: proto.String("https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b/machineTypes/e2-standard-2"),
+			CpuPlatform: proto.String("Intel Broadwell"),
+			Status:      proto.String("RUNNING"),
+			Labels:      map[string]string{"team": "checkout"},
+			GuestAccelerators: []*computepb.AcceleratorConfig{
+				{
+					AcceleratorType:  proto.String("https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b/acceleratorTypes/nvidia-tesla-t4"),
+					AcceleratorCount: proto.Int32(2),
+				},
+			},
+			// started 3 minutes into the window
+			CreationTimestamp:  proto.String("2023-06-01T08:00:00.000-07:00"),
+			LastStartTimestamp: proto.String("2023-12-31T16:03:00.000-08:00"),
+		},
+		{
+			Id:     proto.Uint64(2),
+			Name:   proto.String("stopped"),
+			Zone:   proto.String("https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b"),
+			Status: proto.String("TERMINATED"),
+		},
+	}, nil)
+
+	monitoring := &fakeMonitoring{}
+	monitoring.QueryTimeSeriesCalls(func(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) ([]*monitoringpb.TimeSeriesData, error) {
+		if strings.Contains(req.Query, "cpu/utilization") {
+			return []*monitoringpb.TimeSeriesData{
+				timeSeries("1", "web", &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: 0.25},
+				}, "2.000000"),
+				// not in the instances of the project
+				timeSeries("3", "gone", &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: 0.5},
+				}, "2.000000"),
+			}, nil
+		}
+
+		return []*monitoringpb.TimeSeriesData{
+			timeSeries("1", "web", &monitoringpb.TypedValue{
+				Value: &monitoringpb.TypedValue_Int64Value{Int64Value: 2 * 1024 * 1024 * 1024},
+			}),
+		}, nil
+	})
+
+	account := &config.Account{Name: "demo", Project: "demo"}
+	c, teardown, err := New(ctx, account,
+		withMonitoringTestClient(monitoring),
+		withInstancesTestClient(instances),
+		withMachineTypesTestClient(machineTypes()),
+	)
+	assert.NoError(err)
+
+	events := make(chan v1.Instance, 2)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, bustest.Collector(events))
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	project := account.Project
+	s := &Scraper{
+		Client:   c,
+		account:  account.ID(),
+		Project:  &project,
+		Bus:      b,
+		Shutdown: teardown,
+	}
+	defer s.Stop(ctx)
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	// the queries cover the window of the project
+	assert.Equal(2, monitoring.QueryTimeSeriesCallCount())
+	_, req := monitoring.QueryTimeSeriesArgsForCall(0)
+	assert.Equal("projects/demo", req.Name)
+	assert.Contains(req.Query, "d'2024/01/01-00:05:00'")
+
+	i := <-events
+	assert.Equal("1", i.Name)
+	assert.Equal(v1.GCP, i.Provider)
+	assert.Equal("e2-standard-2", i.Kind)
+	assert.Equal("europe-west1", i.Region)
+	assert.Equal("europe-west1-b", i.Zone)
+	assert.Equal("Intel Broadwell", i.CPUPlatform)
+	assert.Equal("x86_64", i.Architecture)
+	assert.Equal(account.ID(), i.Labels[v1.AccountLabel])
+	assert.Equal("web", i.Labels[v1.NameLabel])
+	assert.Equal("checkout", i.Labels["tag_team"])
+	assert.Equal("nvidia-tesla-t4", i.Labels[v1.AcceleratorLabel])
+	assert.Equal("2", i.Labels[v1.AcceleratorCountLabel])
+	assert.Equal(25.0, i.Metrics[v1.CPU.String()].Usage)
+	assert.Equal(2.0, i.Metrics[v1.CPU.String()].UnitAmount)
+	assert.Equal(2.0, i.Metrics[v1.Memory.String()].Usage)
+	assert.Equal(8.0, i.Metrics[v1.Memory.String()].UnitAmount)
+	assert.True(end.Equal(i.Metrics[v1.CPU.String()].UpdatedAt))
+	assert.Equal(2*time.Minute, i.Observed(5*time.Minute))
+	assert.Equal(v1.NewWindow(end, 2*time.Minute), i.Metrics[v1.Memory.String()].Observed)
+
+	// a failing API fails the scrape
+	instances.AggregatedListReturns(nil, errors.New("permission denied"))
+	_, err = s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.ErrorContains(err, "permission denied")
+}
+
+func TestArchitecture(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("x86_64", architecture("Intel Cascade Lake"))
+	assert.Equal("x86_64", architecture("AMD Milan"))
+	assert.Equal("arm64", architecture("Ampere Altra"))
+	assert.Equal("", architecture("Unknown CPU Platform"))
+	assert.Equal("", architecture(""))
+}
+
+func TestMachineTypeSpecs(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	mt := &fakeMachineTypes{}
+	mt.GetStub = func(ctx context.Context, req *computepb.GetMachineTypeRequest) (*computepb.MachineType, error) {
+		if req.MachineType == "n4-retired-2" {
+			return nil, &googleapi.Error{Code: http.StatusNotFound}
+		}
+		return &computepb.MachineType{
+			Name:      proto.String(req.MachineType),
+			GuestCpus: proto.Int32(4),
+			MemoryMb:  proto.Int32(16384),
+		}, nil
+	}
+
+	c := &Client{machineTypes: mt}
+	fetch := c.machineTypeSpecs("demo", map[string]string{
+		"n2-custom-4-16384": "europe-west1-b",
+		"n4-retired-2":      "europe-west1-c",
+	})
+
+	// the types not found are left out
+	specs, err := fetch(ctx, []string{"n2-custom-4-16384", "n4-retired-2"})
+	assert.NoError(err)
+	assert.Equal(map[string]util.Spec{
+		"n2-custom-4-16384": {VCPU: 4, MemoryGB: 16},
+	}, specs)
+
+	_, req := mt.GetArgsForCall(0)
+	assert.Equal("demo", req.Project)
+	assert.Equal("europe-west1-b", req.Zone)
+
+	mt.GetStub = nil
+	mt.GetReturns(nil, &googleapi.Error{Code: http.StatusForbidden})
+	_, err = fetch(ctx, []string{"n2-custom-4-16384"})
+	assert.Error(err)
+}
+
+func TestThrottled(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+	}{
+		{
+			name:      "rest",
+			err:       fmt.Errorf("listing: %w", &googleapi.Error{Code: http.StatusTooManyRequests}),
+			throttled: true,
+		},
+		{
+			name:      "grpc",
+			err:       status.Error(codes.ResourceExhausted, "quota exceeded"),
+			throttled: true,
+		},
+		{
+			name: "denied",
+			err:  status.Error(codes.PermissionDenied, "permission denied"),
+		},
+		{
+			name: "other",
+			err:  errors.New("failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := throttled(test.err)
+			assert.ErrorIs(err, test.err)
+			assert.Equal(test.throttled, errors.Is(err, v1.ErrProviderThrottled))
+		})
+	}
+
+	require.NoError(t, throttled(nil))
+}
+
+func TestCheck(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	instances := &fakeInstances{}
+	monitoring := &fakeMonitoring{}
+	monitoring.QueryTimeSeriesReturns(nil, status.Error(codes.PermissionDenied, "Permission monitoring.timeSeries.list denied (or the resource may not exist)."))
+
+	account := &config.Account{Project: "demo"}
+	c, teardown, err := New(ctx, account, withInstancesTestClient(instances), withMonitoringTestClient(monitoring), withMachineTypesTestClient(machineTypes()))
+	assert.NoError(err)
+	defer teardown()
+
+	project := "demo"
+	s := &Scraper{Client: c, account: account.ID(), Project: &project}
+
+	permissions := s.Check(ctx)
+	assert.Equal([]v1.Permission{
+		{Name: "compute.instances.list", Granted: true},
+		{Name: "monitoring.timeSeries.list", Error: "rpc error: code = PermissionDenied desc = Permission monitoring.timeSeries.list denied (or the resource may not exist)."},
+	}, permissions)
+
+	// the calls match nothing
+	_, req := instances.AggregatedListArgsForCall(0)
+	assert.Equal("demo", req.GetProject())
+	assert.NotEmpty(req.GetFilter())
+}
@@ -0,0 +1,104 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The services of the snapshots and the images
+const (
+	snapshotService = "snapshot"
+	imageService    = "image"
+)
+
+// The regions the multi-regional storage locations are accounted for in,
+// the grid intensity of a multi-region isn't known
+var multiRegions = map[string]string{
+	"us":   "us-central1",
+	"eu":   "europe-west1",
+	"asia": "asia-east1",
+}
+
+// snapshots returns the snapshots and the images of the project as instances
+// with the size they store. The snapshots and the images are stored in
+// Cloud Storage, so they're accounted for as HDDs
+func (c *Client) snapshots(ctx context.Context, project string, window v1.Window) ([]v1.Instance, error) {
+	if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := c.images.ListSnapshots(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the snapshots: %w", err)
+	}
+
+	if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+		return nil, err
+	}
+
+	images, err := c.images.ListImages(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the images: %w", err)
+	}
+
+	instances := make([]v1.Instance, 0, len(snapshots)+len(images))
+	for _, s := range snapshots {
+		// the size of the snapshots being updated isn't known yet
+		if s.GetStatus() != "READY" || s.GetStorageBytes() == 0 {
+			continue
+		}
+
+		i := storedInstance(snapshotService, s.GetName(), s.GetStorageLocations(), float64(s.GetStorageBytes()), window)
+		for k, v := range s.GetLabels() {
+			i.Labels.Add(v1.TagLabelPrefix+k, v)
+		}
+		if disk, err := getValueFromURL(s.GetSourceDisk()); err == nil {
+			i.Labels.Add("source_disk", disk)
+		}
+		instances = append(instances, i)
+	}
+
+	for _, image := range images {
+		if image.GetStatus() != "READY" {
+			continue
+		}
+
+		i := storedInstance(imageService, image.GetName(), image.GetStorageLocations(), float64(image.GetArchiveSizeBytes()), window)
+		for k, v := range image.GetLabels() {
+			i.Labels.Add(v1.TagLabelPrefix+k, v)
+		}
+		instances = append(instances, i)
+	}
+
+	return instances, nil
+}
+
+// storedInstance returns the instance of a snapshot or an image with the
+// bytes it stores, in the region of its first storage location
+func storedInstance(service, name string, locations []string, bytes float64, window v1.Window) v1.Instance {
+	var region string
+	if len(locations) > 0 {
+		region = locations[0]
+	}
+	if r, ok := multiRegions[region]; ok {
+		region = r
+	}
+
+	i := v1.NewInstance(fmt.Sprintf("%s/%s", service, name), provider)
+	i.Service = service
+	i.Region = region
+	i.Labels.Add(v1.NameLabel, name)
+	i.Metrics.Upsert(&v1.Metric{
+		Name:         v1.Storage.String(),
+		ResourceType: v1.Storage,
+		UnitAmount:   bytes / 1e9,
+		Unit:         v1.GB,
+		UpdatedAt:    window.End.UTC(),
+		Labels:       v1.Labels{v1.MediaLabel: v1.MediaHDD},
+	})
+
+	return *i
+}
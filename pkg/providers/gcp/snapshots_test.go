@@ -0,0 +1,103 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func withImagesTestClient(il imageLister) options {
+	return func(c *Client) {
+		c.images = il
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	images := &fakeImages{}
+	images.ListSnapshotsReturns([]*computepb.Snapshot{
+		{
+			Name:             proto.String("db-backup"),
+			Status:           proto.String("READY"),
+			StorageBytes:     proto.Int64(25e9),
+			StorageLocations: []string{"europe-west1"},
+			SourceDisk:       proto.String("https://www.googleapis.com/compute/v1/projects/demo/zones/europe-west1-b/disks/db"),
+			Labels:           map[string]string{"team": "data"},
+		},
+		{
+			Name:             proto.String("web-backup"),
+			Status:           proto.String("READY"),
+			StorageBytes:     proto.Int64(2e9),
+			StorageLocations: []string{"eu"},
+		},
+		// being created
+		{
+			Name:   proto.String("new-backup"),
+			Status: proto.String("CREATING"),
+		},
+	}, nil)
+	images.ListImagesReturns([]*computepb.Image{
+		{
+			Name:             proto.String("web-2024-01"),
+			Status:           proto.String("READY"),
+			ArchiveSizeBytes: proto.Int64(4e9),
+			StorageLocations: []string{"us-central1"},
+		},
+	}, nil)
+
+	c, teardown, err := New(ctx, &config.Account{Project: "demo", Snapshots: true},
+		withMonitoringTestClient(&fakeMonitoring{}),
+		withInstancesTestClient(&fakeInstances{}),
+		withMachineTypesTestClient(machineTypes()),
+		withImagesTestClient(images),
+	)
+	assert.NoError(err)
+	defer teardown()
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	collected, err := c.GetMetricsForInstances(ctx, "demo", v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Len(collected, 3)
+
+	byName := make(map[string]v1.Instance)
+	for _, i := range collected {
+		byName[i.Name] = i
+	}
+
+	snapshot := byName["snapshot/db-backup"]
+	assert.Equal("snapshot", snapshot.Service)
+	assert.Equal("europe-west1", snapshot.Region)
+	assert.Empty(snapshot.Kind)
+	assert.Equal("db-backup", snapshot.Labels[v1.NameLabel])
+	assert.Equal("db", snapshot.Labels["source_disk"])
+	assert.Equal("data", snapshot.Labels[v1.TagLabelPrefix+"team"])
+
+	storage := snapshot.Metrics[v1.Storage.String()]
+	assert.Equal(v1.Storage, storage.ResourceType)
+	assert.Equal(25.0, storage.UnitAmount)
+	assert.Equal(v1.MediaHDD, storage.Labels[v1.MediaLabel])
+	assert.True(end.Equal(storage.UpdatedAt))
+
+	// the multi-regions are accounted for in one of their regions
+	assert.Equal("europe-west1", byName["snapshot/web-backup"].Region)
+
+	image := byName["image/web-2024-01"]
+	assert.Equal("image", image.Service)
+	assert.Equal("us-central1", image.Region)
+	assert.Equal(4.0, image.Metrics[v1.Storage.String()].UnitAmount)
+
+	// the instances are still collected without the snapshots
+	images.ListImagesReturns(nil, errors.New("forbidden"))
+	collected, err = c.GetMetricsForInstances(ctx, "demo", v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Empty(collected)
+}
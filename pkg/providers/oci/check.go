@@ -0,0 +1,42 @@
+package oci
+
+import (
+	"context"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Check verifies the permissions of the scrapes in every compartment of the
+// first region, the policies apply to all the regions
+func (c *Client) Check(ctx context.Context) []v1.Permission {
+	if len(c.regions) == 0 {
+		return nil
+	}
+	region := c.regions[0]
+
+	end := time.Now().UTC().Truncate(time.Minute)
+
+	var permissions []v1.Permission
+	for _, compartment := range c.compartments {
+		_, err := c.instances.ListInstances(ctx, region, compartment)
+		permissions = append(permissions, util.Permission("inspect instances in "+compartment, err))
+
+		_, err = c.metrics.SummarizeMetricsData(ctx, region, monitoring.SummarizeMetricsDataRequest{
+			CompartmentId: common.String(compartment),
+			SummarizeMetricsDataDetails: monitoring.SummarizeMetricsDataDetails{
+				Namespace:  common.String(agentNamespace),
+				Query:      common.String(cpuMetric + `[1m]{resourceId = "none"}.mean()`),
+				StartTime:  &common.SDKTime{Time: end.Add(-samplePeriod)},
+				EndTime:    &common.SDKTime{Time: end},
+				Resolution: common.String("1m"),
+			},
+		})
+		permissions = append(permissions, util.Permission("read metrics in "+compartment, err))
+	}
+
+	return permissions
+}
@@ -0,0 +1,137 @@
+package oci
+
+import (
+	"context"
+	"sync"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+	"github.com/re-cinq/aether/pkg/providers/util"
+)
+
+// instanceLister lists the running instances of a compartment in a region,
+// the pages of the results are read before returning
+//
+//counterfeiter:generate -o fake_instances_test.go -fake-name fakeInstances . instanceLister
+type instanceLister interface {
+	ListInstances(ctx context.Context, region, compartment string) ([]core.Instance, error)
+}
+
+// metricsQuerier queries the metrics of all the resources of a compartment
+// in a region at once
+//
+//counterfeiter:generate -o fake_metrics_test.go -fake-name fakeMetrics . metricsQuerier
+type metricsQuerier interface {
+	SummarizeMetricsData(ctx context.Context, region string, request monitoring.SummarizeMetricsDataRequest) ([]monitoring.MetricData, error)
+}
+
+// regional creates the clients of the regions on first use, the OCI clients
+// are bound to a region
+type regional[T any] struct {
+	create  func(region string) (T, error)
+	clients map[string]T
+	mu      sync.Mutex
+}
+
+// client returns the client of the region
+func (r *regional[T]) client(region string) (T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.clients[region]; ok {
+		return c, nil
+	}
+
+	c, err := r.create(region)
+	if err != nil {
+		return c, err
+	}
+
+	if r.clients == nil {
+		r.clients = make(map[string]T)
+	}
+	r.clients[region] = c
+
+	return c, nil
+}
+
+// computeClient lists the instances with the compute API
+type computeClient struct {
+	regional[core.ComputeClient]
+}
+
+// newComputeClient returns the compute client of the configuration
+func newComputeClient(cfg common.ConfigurationProvider) *computeClient {
+	return &computeClient{regional[core.ComputeClient]{
+		create: func(region string) (core.ComputeClient, error) {
+			c, err := core.NewComputeClientWithConfigurationProvider(cfg)
+			if err != nil {
+				return c, err
+			}
+			c.SetRegion(region)
+			return c, nil
+		},
+	}}
+}
+
+// ListInstances returns the running instances of every page, a request is
+// made per page
+func (c *computeClient) ListInstances(ctx context.Context, region, compartment string) ([]core.Instance, error) {
+	client, err := c.client(region)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []core.Instance
+
+	req := core.ListInstancesRequest{
+		CompartmentId:  common.String(compartment),
+		LifecycleState: core.InstanceLifecycleStateRunning,
+	}
+	for {
+		resp, err := client.ListInstances(ctx, req)
+		util.RecordAPICall(provider, computeAPI, "ListInstances", err)
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, resp.Items...)
+
+		if resp.OpcNextPage == nil {
+			return instances, nil
+		}
+		req.Page = resp.OpcNextPage
+	}
+}
+
+// monitoringClient queries the metrics with the Monitoring API
+type monitoringClient struct {
+	regional[monitoring.MonitoringClient]
+}
+
+// newMonitoringClient returns the monitoring client of the configuration
+func newMonitoringClient(cfg common.ConfigurationProvider) *monitoringClient {
+	return &monitoringClient{regional[monitoring.MonitoringClient]{
+		create: func(region string) (monitoring.MonitoringClient, error) {
+			c, err := monitoring.NewMonitoringClientWithConfigurationProvider(cfg)
+			if err != nil {
+				return c, err
+			}
+			c.SetRegion(region)
+			return c, nil
+		},
+	}}
+}
+
+// SummarizeMetricsData returns the metrics of the resources of the region
+func (c *monitoringClient) SummarizeMetricsData(ctx context.Context, region string, request monitoring.SummarizeMetricsDataRequest) ([]monitoring.MetricData, error) {
+	client, err := c.client(region)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.SummarizeMetricsData(ctx, request)
+	util.RecordAPICall(provider, monitoringAPI, "SummarizeMetricsData", err)
+	return resp.Items, err
+}
@@ -0,0 +1,119 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package oci
+
+import (
+	"context"
+	"sync"
+
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+type fakeInstances struct {
+	ListInstancesStub        func(context.Context, string, string) ([]core.Instance, error)
+	listInstancesMutex       sync.RWMutex
+	listInstancesArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	listInstancesReturns struct {
+		result1 []core.Instance
+		result2 error
+	}
+	listInstancesReturnsOnCall map[int]struct {
+		result1 []core.Instance
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeInstances) ListInstances(arg1 context.Context, arg2 string, arg3 string) ([]core.Instance, error) {
+	fake.listInstancesMutex.Lock()
+	ret, specificReturn := fake.listInstancesReturnsOnCall[len(fake.listInstancesArgsForCall)]
+	fake.listInstancesArgsForCall = append(fake.listInstancesArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ListInstancesStub
+	fakeReturns := fake.listInstancesReturns
+	fake.recordInvocation("ListInstances", []interface{}{arg1, arg2, arg3})
+	fake.listInstancesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeInstances) ListInstancesCallCount() int {
+	fake.listInstancesMutex.RLock()
+	defer fake.listInstancesMutex.RUnlock()
+	return len(fake.listInstancesArgsForCall)
+}
+
+func (fake *fakeInstances) ListInstancesCalls(stub func(context.Context, string, string) ([]core.Instance, error)) {
+	fake.listInstancesMutex.Lock()
+	defer fake.listInstancesMutex.Unlock()
+	fake.ListInstancesStub = stub
+}
+
+func (fake *fakeInstances) ListInstancesArgsForCall(i int) (context.Context, string, string) {
+	fake.listInstancesMutex.RLock()
+	defer fake.listInstancesMutex.RUnlock()
+	argsForCall := fake.listInstancesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeInstances) ListInstancesReturns(result1 []core.Instance, result2 error) {
+	fake.listInstancesMutex.Lock()
+	defer fake.listInstancesMutex.Unlock()
+	fake.ListInstancesStub = nil
+	fake.listInstancesReturns = struct {
+		result1 []core.Instance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) ListInstancesReturnsOnCall(i int, result1 []core.Instance, result2 error) {
+	fake.listInstancesMutex.Lock()
+	defer fake.listInstancesMutex.Unlock()
+	fake.ListInstancesStub = nil
+	if fake.listInstancesReturnsOnCall == nil {
+		fake.listInstancesReturnsOnCall = make(map[int]struct {
+			result1 []core.Instance
+			result2 error
+		})
+	}
+	fake.listInstancesReturnsOnCall[i] = struct {
+		result1 []core.Instance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeInstances) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ instanceLister = new(fakeInstances)
@@ -0,0 +1,119 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package oci
+
+import (
+	"context"
+	"sync"
+
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+)
+
+type fakeMetrics struct {
+	SummarizeMetricsDataStub        func(context.Context, string, monitoring.SummarizeMetricsDataRequest) ([]monitoring.MetricData, error)
+	summarizeMetricsDataMutex       sync.RWMutex
+	summarizeMetricsDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 monitoring.SummarizeMetricsDataRequest
+	}
+	summarizeMetricsDataReturns struct {
+		result1 []monitoring.MetricData
+		result2 error
+	}
+	summarizeMetricsDataReturnsOnCall map[int]struct {
+		result1 []monitoring.MetricData
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeMetrics) SummarizeMetricsData(arg1 context.Context, arg2 string, arg3 monitoring.SummarizeMetricsDataRequest) ([]monitoring.MetricData, error) {
+	fake.summarizeMetricsDataMutex.Lock()
+	ret, specificReturn := fake.summarizeMetricsDataReturnsOnCall[len(fake.summarizeMetricsDataArgsForCall)]
+	fake.summarizeMetricsDataArgsForCall = append(fake.summarizeMetricsDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 monitoring.SummarizeMetricsDataRequest
+	}{arg1, arg2, arg3})
+	stub := fake.SummarizeMetricsDataStub
+	fakeReturns := fake.summarizeMetricsDataReturns
+	fake.recordInvocation("SummarizeMetricsData", []interface{}{arg1, arg2, arg3})
+	fake.summarizeMetricsDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeMetrics) SummarizeMetricsDataCallCount() int {
+	fake.summarizeMetricsDataMutex.RLock()
+	defer fake.summarizeMetricsDataMutex.RUnlock()
+	return len(fake.summarizeMetricsDataArgsForCall)
+}
+
+func (fake *fakeMetrics) SummarizeMetricsDataCalls(stub func(context.Context, string, monitoring.SummarizeMetricsDataRequest) ([]monitoring.MetricData, error)) {
+	fake.summarizeMetricsDataMutex.Lock()
+	defer fake.summarizeMetricsDataMutex.Unlock()
+	fake.SummarizeMetricsDataStub = stub
+}
+
+func (fake *fakeMetrics) SummarizeMetricsDataArgsForCall(i int) (context.Context, string, monitoring.SummarizeMetricsDataRequest) {
+	fake.summarizeMetricsDataMutex.RLock()
+	defer fake.summarizeMetricsDataMutex.RUnlock()
+	argsForCall := fake.summarizeMetricsDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeMetrics) SummarizeMetricsDataReturns(result1 []monitoring.MetricData, result2 error) {
+	fake.summarizeMetricsDataMutex.Lock()
+	defer fake.summarizeMetricsDataMutex.Unlock()
+	fake.SummarizeMetricsDataStub = nil
+	fake.summarizeMetricsDataReturns = struct {
+		result1 []monitoring.MetricData
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) SummarizeMetricsDataReturnsOnCall(i int, result1 []monitoring.MetricData, result2 error) {
+	fake.summarizeMetricsDataMutex.Lock()
+	defer fake.summarizeMetricsDataMutex.Unlock()
+	fake.SummarizeMetricsDataStub = nil
+	if fake.summarizeMetricsDataReturnsOnCall == nil {
+		fake.summarizeMetricsDataReturnsOnCall = make(map[int]struct {
+			result1 []monitoring.MetricData
+			result2 error
+		})
+	}
+	fake.summarizeMetricsDataReturnsOnCall[i] = struct {
+		result1 []monitoring.MetricData
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeMetrics) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metricsQuerier = new(fakeMetrics)
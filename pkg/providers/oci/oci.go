@@ -0,0 +1,392 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+	cache "github.com/patrickmn/go-cache"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/relabel"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// samplePeriod is the resolution of the utilization of the instances
+const samplePeriod = time.Minute
+
+// The namespace of the metrics of the Compute Instance Monitoring plugin of
+// the Oracle Cloud Agent, the utilization metrics in % and the dimension of
+// the instance they're split by
+const (
+	agentNamespace    = "oci_computeagent"
+	cpuMetric         = "CpuUtilization"
+	memoryMetric      = "MemoryUtilization"
+	resourceDimension = "resourceId"
+)
+
+// Client is the structure used as the provider for OCI
+type Client struct {
+	// OCI clients, faked by the tests
+	instances instanceLister
+	metrics   metricsQuerier
+
+	// The regions and the compartments scraped
+	regions      []string
+	compartments []string
+
+	// The running instances of every region
+	cache *cache.Cache
+}
+
+type options func(*Client)
+
+// vm is a running instance and the resources of its shape
+type vm struct {
+	instance    v1.Instance
+	compartment string
+	vCPU        float64
+	memoryGB    float64
+}
+
+// New returns a new instance of the OCI provider for the compartments of the
+// account, authenticated with a profile of the OCI configuration file
+func New(ctx context.Context, account *config.Account, opts ...options) (*Client, error) {
+	c := &Client{
+		regions:      account.Regions,
+		compartments: account.Compartments,
+		cache:        cache.New(time.Hour, time.Hour),
+	}
+
+	// overwrite any options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// the configuration is only loaded when it's needed
+	if c.instances != nil && c.metrics != nil && len(c.regions) > 0 && len(c.compartments) > 0 {
+		return c, nil
+	}
+
+	cfg, err := configuration(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading the OCI configuration: %w", err)
+	}
+
+	if len(c.regions) == 0 {
+		region, err := cfg.Region()
+		if err != nil {
+			return nil, fmt.Errorf("failed loading the OCI configuration: %w", err)
+		}
+		c.regions = []string{region}
+	}
+
+	// the root compartment is the tenancy
+	if len(c.compartments) == 0 {
+		tenancy, err := cfg.TenancyOCID()
+		if err != nil {
+			return nil, fmt.Errorf("failed loading the OCI configuration: %w", err)
+		}
+		c.compartments = []string{tenancy}
+	}
+
+	if c.instances == nil {
+		c.instances = newComputeClient(cfg)
+	}
+	if c.metrics == nil {
+		c.metrics = newMonitoringClient(cfg)
+	}
+
+	return c, nil
+}
+
+// configuration returns the configuration of the profile of the account, the
+// default one of the OCI configuration file and the environment otherwise
+func configuration(account *config.Account) (common.ConfigurationProvider, error) {
+	if !account.Credentials.IsPresent() {
+		cfg := common.DefaultConfigProvider()
+		if ok, err := common.IsConfigurationProviderValid(cfg); !ok {
+			return nil, err
+		}
+		return cfg, nil
+	}
+
+	var path string
+	if len(account.Credentials.FilePaths) > 0 {
+		path = account.Credentials.FilePaths[0]
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".oci", "config")
+	}
+
+	profile := account.Credentials.Profile
+	if profile == "" {
+		profile = "DEFAULT"
+	}
+
+	return common.ConfigurationProviderFromFileWithProfile(path, profile, "")
+}
+
+// Refresh fetches the running instances of the compartments of every region
+// and caches them for the metrics collection
+func (c *Client) Refresh(ctx context.Context) error {
+	for _, region := range c.regions {
+		var vms []vm
+
+		for _, compartment := range c.compartments {
+			if err := util.WaitForAPI(ctx, provider, computeAPI); err != nil {
+				return err
+			}
+
+			instances, err := c.instances.ListInstances(ctx, region, compartment)
+			if err != nil {
+				return fmt.Errorf("failed listing the instances of compartment %s: %w", compartment, err)
+			}
+
+			for i := range instances {
+				vms = append(vms, newVM(&instances[i], region, compartment))
+			}
+		}
+
+		// the instances stopped in the meantime are dropped with the
+		// previous list
+		c.cache.Set(instancesKey(region), vms, cache.DefaultExpiration)
+	}
+
+	return nil
+}
+
+// GetMetricsForInstances returns the CPU and memory utilization of the
+// cached instances over the window, the regions are queried concurrently
+func (c *Client) GetMetricsForInstances(ctx context.Context, window v1.Window) ([]v1.Instance, error) {
+	var (
+		instances []v1.Instance
+		mu        sync.Mutex
+	)
+
+	err := util.ForEach(ctx, c.regions, func(ctx context.Context, region string) error {
+		cached, ok := c.cache.Get(instancesKey(region))
+		if !ok {
+			return nil
+		}
+
+		collected, err := c.regionMetrics(ctx, region, cached.([]vm), window)
+		if err != nil {
+			return fmt.Errorf("failed getting the metrics of region %s: %w", region, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		instances = append(instances, collected...)
+		return nil
+	})
+
+	return instances, err
+}
+
+// regionMetrics queries the utilization of all the instances of every
+// compartment of the region at once, split by instance
+func (c *Client) regionMetrics(ctx context.Context, region string, vms []vm, window v1.Window) ([]v1.Instance, error) {
+	compartments := make([]string, 0, len(c.compartments))
+	for _, vm := range vms {
+		if !slices.Contains(compartments, vm.compartment) {
+			compartments = append(compartments, vm.compartment)
+		}
+	}
+
+	cpu := make(map[string][]util.Sample)
+	memory := make(map[string][]util.Sample)
+	for _, compartment := range compartments {
+		if err := c.query(ctx, region, compartment, cpuMetric, window, cpu); err != nil {
+			return nil, err
+		}
+		// the instances without a memory utilization only have their CPU
+		// metric
+		if err := c.query(ctx, region, compartment, memoryMetric, window, memory); err != nil {
+			return nil, err
+		}
+	}
+
+	instances := make([]v1.Instance, 0, len(vms))
+	for _, vm := range vms {
+		id := vm.instance.Name
+		resolution := util.Resolution(cpu[id], samplePeriod)
+
+		usage, observed, ok := util.Align(cpu[id], resolution, window)
+		if !ok {
+			continue
+		}
+
+		i := vm.instance
+		i.Metrics = v1.Metrics{}
+
+		m := v1.NewMetric(v1.CPU.String())
+		m.Unit = v1.VCPU
+		m.ResourceType = v1.CPU
+		m.Usage = usage
+		m.UnitAmount = vm.vCPU
+		m.UpdatedAt = window.End.UTC()
+		m.Observed = observed
+		m.Labels = v1.Labels{
+			util.ResolutionLabel: util.FormatResolution(resolution),
+		}
+		i.Metrics.Upsert(m)
+
+		resolution = util.Resolution(memory[id], samplePeriod)
+		if usage, observed, ok := util.Align(memory[id], resolution, window); ok && vm.memoryGB > 0 {
+			m := v1.NewMetric(v1.Memory.String())
+			m.Unit = v1.GB
+			m.ResourceType = v1.Memory
+			m.Usage = usage / 100 * vm.memoryGB
+			m.UnitAmount = vm.memoryGB
+			m.UpdatedAt = window.End.UTC()
+			m.Observed = observed
+			m.Labels = v1.Labels{
+				util.ResolutionLabel: util.FormatResolution(resolution),
+			}
+			i.Metrics.Upsert(m)
+		}
+
+		instances = append(instances, i)
+	}
+
+	return instances, nil
+}
+
+// query adds the samples of the metric of every instance of the compartment
+// to the ones by instance OCID
+func (c *Client) query(ctx context.Context, region, compartment, metric string, window v1.Window, samples map[string][]util.Sample) error {
+	if err := util.WaitForAPI(ctx, provider, monitoringAPI); err != nil {
+		return err
+	}
+
+	data, err := c.metrics.SummarizeMetricsData(ctx, region, monitoring.SummarizeMetricsDataRequest{
+		CompartmentId: common.String(compartment),
+		SummarizeMetricsDataDetails: monitoring.SummarizeMetricsDataDetails{
+			Namespace:  common.String(agentNamespace),
+			Query:      common.String(metric + "[1m].mean()"),
+			StartTime:  &common.SDKTime{Time: window.Start.UTC()},
+			EndTime:    &common.SDKTime{Time: window.End.UTC()},
+			Resolution: common.String("1m"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		id := d.Dimensions[resourceDimension]
+		if id == "" {
+			continue
+		}
+
+		for _, p := range d.AggregatedDatapoints {
+			if p.Timestamp == nil || p.Value == nil {
+				continue
+			}
+			samples[id] = append(samples[id], util.Sample{
+				Time:  p.Timestamp.Time,
+				Value: *p.Value,
+			})
+		}
+	}
+
+	return nil
+}
+
+// newVM returns a running instance and the resources of its shape
+func newVM(instance *core.Instance, region, compartment string) vm {
+	// the instances are named after their OCID, their display name isn't
+	// unique
+	i := v1.NewInstance(value(instance.Id), provider)
+	i.Service = service
+	i.Region = region
+	i.Zone = value(instance.AvailabilityDomain)
+	i.Kind = value(instance.Shape)
+	i.Architecture = architecture(i.Kind)
+
+	if instance.TimeCreated != nil {
+		i.StartedAt = instance.TimeCreated.UTC()
+	}
+	if instance.ShapeConfig != nil {
+		i.CPUPlatform = value(instance.ShapeConfig.ProcessorDescription)
+	}
+
+	i.Labels[v1.NameLabel] = value(instance.DisplayName)
+	i.Labels["Compartment"] = compartment
+	if instance.PreemptibleInstanceConfig != nil {
+		i.Labels["Lifecycle"] = "Preemptible"
+	}
+
+	// the labels of the instance are its free-form tags, matched by the
+	// grouping rules
+	for k, v := range instance.FreeformTags {
+		i.Labels[v1.TagLabelPrefix+relabel.LabelName(k)] = v
+	}
+
+	v := vm{
+		instance:    *i,
+		compartment: compartment,
+	}
+	if s := instance.ShapeConfig; s != nil {
+		v.vCPU = vCPUs(s, i.Architecture)
+		v.memoryGB = float64(value(s.MemoryInGBs))
+	}
+
+	return v
+}
+
+// vCPUs returns the vCPUs of the shape, an OCPU is a core: two vCPUs of the
+// x86 shapes and one of the Arm ones
+func vCPUs(s *core.InstanceShapeConfig, arch string) float64 {
+	if n := value(s.Vcpus); n > 0 {
+		return float64(n)
+	}
+
+	ocpus := float64(value(s.Ocpus))
+	if arch == "arm64" {
+		return ocpus
+	}
+	return 2 * ocpus
+}
+
+// The Ampere shapes, e.g. VM.Standard.A1.Flex
+var armShape = regexp.MustCompile(`\.A[0-9]+\.`)
+
+// architecture returns the CPU architecture of a shape, empty when the shape
+// is unknown
+func architecture(shape string) string {
+	switch {
+	case shape == "":
+		return ""
+	case armShape.MatchString(shape):
+		return "arm64"
+	default:
+		return "x86_64"
+	}
+}
+
+// instancesKey returns the cache key of the instances of a region
+func instancesKey(region string) string {
+	return "instances/" + region
+}
+
+// value returns the value of the pointer, zero when nil
+func value[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,31 @@
+package oci
+
+// The fakes of the OCI APIs used by the tests
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.13.0 -generate
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+const provider = v1.OCI
+const service = "Compute"
+
+// API families, used for rate limiting
+const (
+	computeAPI    = "compute"
+	monitoringAPI = "monitoring"
+)
+
+// throttled marks the errors of the requests rejected by the OCI APIs
+// because of their rate with v1.ErrProviderThrottled
+func throttled(err error) error {
+	if serr, ok := common.IsServiceError(err); ok && serr.GetHTTPStatusCode() == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", v1.ErrProviderThrottled, err)
+	}
+
+	return err
+}
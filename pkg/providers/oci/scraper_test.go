@@ -0,0 +1,241 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestClients overwrites the OCI clients with fakes
+func withTestClients(instances instanceLister, metrics metricsQuerier) options {
+	return func(c *Client) {
+		c.instances = instances
+		c.metrics = metrics
+	}
+}
+
+// instance returns a running instance of the flexible shape
+func instance(id, shape string, ocpus, memoryGB float32) core.Instance {
+	return core.Instance{
+		Id:                 common.String(id),
+		DisplayName:        common.String("web"),
+		AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+		Shape:              common.String(shape),
+		FreeformTags:       map[string]string{"team": "checkout"},
+		ShapeConfig: &core.InstanceShapeConfig{
+			Ocpus:                common.Float32(ocpus),
+			MemoryInGBs:          common.Float32(memoryGB),
+			ProcessorDescription: common.String("2.55 GHz AMD EPYC 7J13 (Milan)"),
+		},
+	}
+}
+
+// series returns the utilization of an instance every minute from the start
+func series(id string, start time.Time, values ...float64) monitoring.MetricData {
+	d := monitoring.MetricData{
+		Dimensions: map[string]string{"resourceId": id},
+	}
+	for i, v := range values {
+		d.AggregatedDatapoints = append(d.AggregatedDatapoints, monitoring.AggregatedDatapoint{
+			Timestamp: &common.SDKTime{Time: start.Add(time.Duration(i) * time.Minute)},
+			Value:     common.Float64(v),
+		})
+	}
+	return d
+}
+
+func TestScrape(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	window := v1.NewWindow(end, 5*time.Minute)
+
+	instances := &fakeInstances{}
+	instances.ListInstancesReturns([]core.Instance{
+		instance("ocid1.instance.web", "VM.Standard.E4.Flex", 2, 32),
+		// no metrics, e.g. without the Oracle Cloud Agent
+		instance("ocid1.instance.agentless", "VM.Standard.A1.Flex", 1, 6),
+	}, nil)
+
+	metrics := &fakeMetrics{}
+	metrics.SummarizeMetricsDataReturnsOnCall(0, []monitoring.MetricData{
+		series("ocid1.instance.web", window.Start, 10, 20, 30, 40, 50),
+	}, nil)
+	metrics.SummarizeMetricsDataReturnsOnCall(1, []monitoring.MetricData{
+		series("ocid1.instance.web", window.Start, 25, 25, 25, 25, 25),
+	}, nil)
+
+	account := &config.Account{
+		Regions:      []string{"us-phoenix-1"},
+		Compartments: []string{"ocid1.compartment.demo"},
+	}
+	c, err := New(ctx, account, withTestClients(instances, metrics))
+	assert.NoError(err)
+
+	events := make(chan v1.Instance, 2)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, bustest.Collector(events))
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{Client: c, account: "demo", Bus: b}
+	defer s.Stop(ctx)
+
+	n, err := s.Scrape(ctx, window)
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	_, region, compartment := instances.ListInstancesArgsForCall(0)
+	assert.Equal("us-phoenix-1", region)
+	assert.Equal("ocid1.compartment.demo", compartment)
+
+	// the compartment is queried at once for every metric
+	assert.Equal(2, metrics.SummarizeMetricsDataCallCount())
+	_, region, req := metrics.SummarizeMetricsDataArgsForCall(0)
+	assert.Equal("us-phoenix-1", region)
+	assert.Equal("ocid1.compartment.demo", *req.CompartmentId)
+	assert.Equal("oci_computeagent", *req.Namespace)
+	assert.Equal("CpuUtilization[1m].mean()", *req.Query)
+	_, _, req = metrics.SummarizeMetricsDataArgsForCall(1)
+	assert.Equal("MemoryUtilization[1m].mean()", *req.Query)
+
+	i := <-events
+	assert.Equal("ocid1.instance.web", i.Name)
+	assert.Equal(v1.OCI, i.Provider)
+	assert.Equal("VM.Standard.E4.Flex", i.Kind)
+	assert.Equal("us-phoenix-1", i.Region)
+	assert.Equal("Uocm:PHX-AD-1", i.Zone)
+	assert.Equal("x86_64", i.Architecture)
+	assert.Equal("2.55 GHz AMD EPYC 7J13 (Milan)", i.CPUPlatform)
+	assert.Equal("demo", i.Labels[v1.AccountLabel])
+	assert.Equal("web", i.Labels[v1.NameLabel])
+	assert.Equal("ocid1.compartment.demo", i.Labels["Compartment"])
+	assert.Equal("checkout", i.Labels["tag_team"])
+
+	cpu := i.Metrics[v1.CPU.String()]
+	assert.Equal(30.0, cpu.Usage)
+	// an OCPU is two vCPUs of the x86 shapes
+	assert.Equal(4.0, cpu.UnitAmount)
+	assert.Equal("1m", cpu.Labels[util.ResolutionLabel])
+	assert.True(end.Equal(cpu.UpdatedAt))
+
+	memory := i.Metrics[v1.Memory.String()]
+	assert.Equal(v1.GB, memory.Unit)
+	assert.Equal(8.0, memory.Usage)
+	assert.Equal(32.0, memory.UnitAmount)
+
+	// a failing API fails the scrape
+	instances.ListInstancesReturns(nil, errors.New("not authorized"))
+	_, err = s.Scrape(ctx, window)
+	assert.ErrorContains(err, "not authorized")
+}
+
+func TestCheck(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	instances := &fakeInstances{}
+	instances.ListInstancesReturns(nil, serviceError{status: http.StatusNotFound})
+
+	metrics := &fakeMetrics{}
+
+	account := &config.Account{
+		Regions:      []string{"us-phoenix-1", "us-ashburn-1"},
+		Compartments: []string{"ocid1.compartment.demo"},
+	}
+	c, err := New(ctx, account, withTestClients(instances, metrics))
+	assert.NoError(err)
+
+	assert.Equal([]v1.Permission{
+		{Name: "inspect instances in ocid1.compartment.demo", Error: "Not Found"},
+		{Name: "read metrics in ocid1.compartment.demo", Granted: true},
+	}, c.Check(ctx))
+
+	// the first region is checked
+	_, region, _ := instances.ListInstancesArgsForCall(0)
+	assert.Equal("us-phoenix-1", region)
+	assert.Equal(1, metrics.SummarizeMetricsDataCallCount())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background(), &config.Account{
+		Credentials: config.ProviderConfig{FilePaths: []string{"/nonexistent/config"}},
+	})
+	require.Error(t, err)
+}
+
+func TestVCPUs(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(4.0, vCPUs(&core.InstanceShapeConfig{Ocpus: common.Float32(2)}, "x86_64"))
+	assert.Equal(2.0, vCPUs(&core.InstanceShapeConfig{Ocpus: common.Float32(2)}, "arm64"))
+	assert.Equal(6.0, vCPUs(&core.InstanceShapeConfig{Ocpus: common.Float32(2), Vcpus: common.Int(6)}, "x86_64"))
+}
+
+func TestArchitecture(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("x86_64", architecture("VM.Standard.E4.Flex"))
+	assert.Equal("x86_64", architecture("BM.GPU.A100-v2.8"))
+	assert.Equal("arm64", architecture("VM.Standard.A1.Flex"))
+	assert.Equal("arm64", architecture("BM.Standard.A1.160"))
+	assert.Equal("", architecture(""))
+}
+
+func TestThrottled(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+	}{
+		{
+			name:      "too many requests",
+			err:       serviceError{status: http.StatusTooManyRequests},
+			throttled: true,
+		},
+		{
+			name: "not authorized",
+			err:  serviceError{status: http.StatusUnauthorized},
+		},
+		{
+			name: "other",
+			err:  errors.New("failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := throttled(test.err)
+			assert.ErrorIs(err, test.err)
+			assert.Equal(test.throttled, errors.Is(err, v1.ErrProviderThrottled))
+		})
+	}
+
+	require.NoError(t, throttled(nil))
+}
+
+// serviceError is an error of the OCI APIs with its HTTP status
+type serviceError struct {
+	status int
+}
+
+func (e serviceError) Error() string           { return http.StatusText(e.status) }
+func (e serviceError) GetHTTPStatusCode() int  { return e.status }
+func (e serviceError) GetMessage() string      { return e.Error() }
+func (e serviceError) GetCode() string         { return "" }
+func (e serviceError) GetOpcRequestID() string { return "" }
@@ -0,0 +1,144 @@
+// Package plugin runs the providers implemented by external programs, so
+// that private clouds or internal platforms can be scraped without forking
+// the exporter. The plugins are served with hashicorp/go-plugin over net/rpc,
+// the instances are sent as aether.emissions.v1alpha1 protobuf messages:
+//
+//	func main() {
+//		plugin.Serve(&mainframe{})
+//	}
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/rpc"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/re-cinq/aether/pkg/config"
+	emissionsv1alpha1 "github.com/re-cinq/aether/pkg/proto/emissions/v1alpha1"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// The name of the plugin served by the executables
+const pluginName = "provider"
+
+// Handshake makes sure the executables are aether provider plugins of a
+// compatible version. The version 2 sends the instances as protobuf messages
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  2,
+	MagicCookieKey:   "AETHER_PLUGIN",
+	MagicCookieValue: "provider",
+}
+
+// Provider collects the instances of a provider, it's implemented by the
+// plugins. A plugin process scrapes a single account
+type Provider interface {
+	// Configure is called once, before the first scrape, with the account
+	Configure(account *config.Account) error
+
+	// Scrape returns the instances of the account and the usage of their
+	// resources over the window. The provider of the instances is set by
+	// the exporter
+	Scrape(window v1.Window) ([]v1.Instance, error)
+
+	// Flush drops the cached data, so that it's fetched again by the next
+	// scrape
+	Flush() error
+}
+
+// Serve runs the provider as a plugin, it's called by the main function of
+// the plugin executables and returns when the exporter stops it
+func Serve(p Provider) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: goplugin.PluginSet{
+			pluginName: &providerPlugin{impl: p},
+		},
+	})
+}
+
+// providerPlugin serves the provider on one side and dispenses its client
+// on the other
+type providerPlugin struct {
+	impl Provider
+}
+
+func (p *providerPlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &rpcServer{impl: p.impl}, nil
+}
+
+func (p *providerPlugin) Client(b *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &rpcClient{client: c}, nil
+}
+
+// rpcServer exposes the provider to net/rpc
+type rpcServer struct {
+	impl Provider
+}
+
+func (s *rpcServer) Configure(account *config.Account, _ *struct{}) error {
+	return s.impl.Configure(account)
+}
+
+func (s *rpcServer) Scrape(window v1.Window, messages *[][]byte) error {
+	instances, err := s.impl.Scrape(window)
+	if err != nil {
+		return err
+	}
+
+	*messages = make([][]byte, 0, len(instances))
+	for i := range instances {
+		msg, err := proto.Marshal(emissionsv1alpha1.FromInstance(&instances[i]))
+		if err != nil {
+			return fmt.Errorf("failed encoding instance %s: %w", instances[i].Name, err)
+		}
+		*messages = append(*messages, msg)
+	}
+
+	return nil
+}
+
+func (s *rpcServer) Flush(_ struct{}, _ *struct{}) error {
+	return s.impl.Flush()
+}
+
+// rpcClient calls the provider of a plugin
+type rpcClient struct {
+	client *rpc.Client
+}
+
+func (c *rpcClient) Configure(account *config.Account) error {
+	return c.client.Call("Plugin.Configure", account, &struct{}{})
+}
+
+// Scrape returns the instances collected by the plugin, the plugin keeps
+// scraping when the context is done but its instances are dropped
+func (c *rpcClient) Scrape(ctx context.Context, window v1.Window) ([]v1.Instance, error) {
+	var messages [][]byte
+	call := c.client.Go("Plugin.Scrape", window, &messages, nil)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.Done:
+		if call.Error != nil {
+			return nil, call.Error
+		}
+	}
+
+	instances := make([]v1.Instance, 0, len(messages))
+	for _, msg := range messages {
+		var instance emissionsv1alpha1.Instance
+		if err := proto.Unmarshal(msg, &instance); err != nil {
+			return nil, fmt.Errorf("failed decoding instance: %w", err)
+		}
+		instances = append(instances, *emissionsv1alpha1.ToInstance(&instance))
+	}
+
+	return instances, nil
+}
+
+func (c *rpcClient) Flush() error {
+	return c.client.Call("Plugin.Flush", struct{}{}, &struct{}{})
+}
@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// The test binary is also the plugin of the tests when this is set
+const testPluginEnv = "AETHER_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) != "" {
+		Serve(&fakeProvider{})
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// fakeProvider returns an instance named after the account
+type fakeProvider struct {
+	account string
+}
+
+func (p *fakeProvider) Configure(account *config.Account) error {
+	if account.Name == "invalid" {
+		return errors.New("unknown account")
+	}
+	p.account = account.Name
+	return nil
+}
+
+func (p *fakeProvider) Scrape(window v1.Window) ([]v1.Instance, error) {
+	i := v1.NewInstance(p.account+"-vm", v1.AWS)
+	i.Region = "dc-1"
+	i.Kind = "large"
+	i.Metrics.Upsert(&v1.Metric{
+		Name:         "cpu",
+		ResourceType: v1.CPU,
+		Usage:        42,
+		UnitAmount:   4,
+		UpdatedAt:    window.End,
+	})
+	return []v1.Instance{*i}, nil
+}
+
+func (p *fakeProvider) Flush() error { return nil }
+
+func TestScraper(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	t.Setenv(testPluginEnv, "1")
+	exe, err := os.Executable()
+	assert.NoError(err)
+
+	events := make(bustest.Collector, 1)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, events)
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	_, err = NewScraper(ctx, b, "mainframe", exe, &config.Account{Name: "invalid"})
+	assert.ErrorContains(err, "unknown account")
+
+	s, err := NewScraper(ctx, b, "mainframe", exe, &config.Account{Name: "zos"})
+	assert.NoError(err)
+	defer s.Stop(ctx)
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	n, err := s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	i := <-events
+	assert.Equal(v1.Provider("mainframe"), i.Provider)
+	assert.Equal("zos-vm", i.Name)
+	assert.Equal("zos", i.Labels[v1.AccountLabel])
+	assert.Equal(42.0, i.Metrics["cpu"].Usage)
+	assert.True(end.Equal(i.Metrics["cpu"].UpdatedAt))
+
+	// the plugin is started again when it exits
+	s.(*Scraper).client.Kill()
+	n, err = s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.NoError(err)
+	assert.Equal(1, n)
+	<-events
+
+	s.Stop(ctx)
+	_, err = s.Scrape(ctx, v1.NewWindow(end, 5*time.Minute))
+	assert.Error(err)
+}
+
+func TestRegister(t *testing.T) {
+	assert := require.New(t)
+	defer delete(v1.Providers, "tape")
+
+	provider, factory, err := Register(&config.PluginConfig{Provider: "tape", Path: "/usr/local/bin/aether-tape"})
+	assert.NoError(err)
+	assert.Equal(v1.Provider("tape"), provider)
+	assert.NotNil(factory)
+
+	_, _, err = Register(&config.PluginConfig{Provider: "aws", Path: "/usr/local/bin/aether-aws"})
+	assert.EqualError(err, "provider aws already exists")
+
+	_, _, err = Register(&config.PluginConfig{Provider: "disk"})
+	assert.Error(err)
+}
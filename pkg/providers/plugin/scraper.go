@@ -0,0 +1,204 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+)
+
+// Factory creates the scrapers of the accounts of a plugin provider
+type Factory func(context.Context, *bus.Bus, *config.Account) (v1.Scraper, error)
+
+// Register adds the provider of the plugin to the supported providers and
+// returns the factory of its scrapers
+func Register(cfg *config.PluginConfig) (v1.Provider, Factory, error) {
+	if cfg.Provider == "" || cfg.Path == "" {
+		return "", nil, errors.New("the plugins need a provider and a path")
+	}
+
+	provider, err := v1.RegisterProvider(cfg.Provider)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if cfg.Factors != "" {
+		factors.RegisterDataPath(provider, cfg.Factors)
+	}
+
+	return provider, func(ctx context.Context, b *bus.Bus, account *config.Account) (v1.Scraper, error) {
+		return NewScraper(ctx, b, provider, cfg.Path, account)
+	}, nil
+}
+
+// Scraper collects the instances of an account from a plugin process
+type Scraper struct {
+	provider v1.Provider
+	account  config.Account
+
+	// The plugin executable and its process, started again when it exited
+	path    string
+	client  *goplugin.Client
+	plugin  *rpcClient
+	stopped bool
+	mu      sync.Mutex
+
+	bus    *bus.Bus
+	logger *slog.Logger
+}
+
+// NewScraper starts the plugin and configures it with the account
+func NewScraper(ctx context.Context, b *bus.Bus, provider v1.Provider, path string, account *config.Account) (v1.Scraper, error) {
+	s := &Scraper{
+		provider: provider,
+		account:  *account,
+		path:     path,
+		bus:      b,
+		logger:   log.FromContext(ctx),
+	}
+
+	if _, err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Provider returns the provider the scraper is collecting data from
+func (s *Scraper) Provider() v1.Provider {
+	return s.provider
+}
+
+// Account returns the identifier of the account being scraped
+func (s *Scraper) Account() string {
+	return s.account.ID()
+}
+
+// Scrape collects the instances of the account from the plugin and
+// publishes their metrics
+func (s *Scraper) Scrape(ctx context.Context, window v1.Window) (int, error) {
+	p, err := s.connect()
+	if err != nil {
+		return 0, err
+	}
+
+	instances, err := p.Scrape(ctx, window)
+	if err != nil {
+		return 0, fmt.Errorf("plugin %s failed scraping: %w", s.provider, err)
+	}
+
+	for i := range instances {
+		// the plugins can't scrape for another provider
+		instances[i].Provider = s.provider
+		instances[i].Labels = instances[i].Labels.With(v1.AccountLabel, s.account.ID())
+
+		if err := s.bus.PublishContext(ctx, &bus.Event{
+			Type: v1.MetricsCollectedEvent,
+			Data: instances[i],
+		}); err != nil {
+			s.logger.Error("failed publishing instance", "error", err, "instance", instances[i].Name)
+		}
+	}
+
+	return len(instances), nil
+}
+
+// Flush drops the data cached by the plugin
+func (s *Scraper) Flush() {
+	p, err := s.connect()
+	if err != nil {
+		s.logger.Error("failed flushing the plugin", "provider", s.provider, "error", err)
+		return
+	}
+
+	if err := p.Flush(); err != nil {
+		s.logger.Error("failed flushing the plugin", "provider", s.provider, "error", err)
+	}
+}
+
+// Stop kills the plugin process
+func (s *Scraper) Stop(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	if s.client != nil {
+		s.client.Kill()
+	}
+}
+
+// connect returns the client of the plugin, starting and configuring the
+// process when it isn't running
+func (s *Scraper) connect() (*rpcClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, errors.New("the scraper is stopped")
+	}
+
+	if s.client != nil && !s.client.Exited() {
+		return s.plugin, nil
+	}
+
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          goplugin.PluginSet{pluginName: &providerPlugin{}},
+		Cmd:              exec.Command(s.path),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolNetRPC},
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:       "plugin." + s.provider.String(),
+			Output:     os.Stdout,
+			Level:      hclog.Info,
+			JSONFormat: true,
+		}),
+	})
+
+	p, err := dispense(client)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("failed starting plugin %s: %w", s.path, err)
+	}
+
+	account := s.account
+	if err := p.Configure(&account); err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("plugin %s failed configuring account %s: %w", s.provider, account.ID(), err)
+	}
+
+	s.client = client
+	s.plugin = p
+
+	return p, nil
+}
+
+// dispense starts the plugin process and returns its provider
+func dispense(client *goplugin.Client) (*rpcClient, error) {
+	conn, err := client.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := conn.Dispense(pluginName)
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := raw.(*rpcClient)
+	if !ok {
+		return nil, fmt.Errorf("unexpected plugin %T", raw)
+	}
+
+	return p, nil
+}
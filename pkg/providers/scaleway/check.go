@@ -0,0 +1,38 @@
+package scaleway
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+// Check verifies the permission sets of the API key and the scope of the
+// Cockpit token the scrapes need. The servers are listed in the first zone
+// scraped of each API
+func (c *Client) Check(ctx context.Context) []v1.Permission {
+	var permissions []v1.Permission
+
+	if i := slices.IndexFunc(c.zones, func(z scw.Zone) bool { return slices.Contains(instanceZones, z) }); i >= 0 {
+		_, err := c.instances.ListServers(ctx, c.zones[i])
+		permissions = append(permissions, util.Permission("InstancesReadOnly", err))
+	}
+
+	if i := slices.IndexFunc(c.zones, func(z scw.Zone) bool { return slices.Contains(elasticMetalZones, z) }); i >= 0 {
+		_, err := c.elasticMetal.ListServers(ctx, c.zones[i])
+		permissions = append(permissions, util.Permission("ElasticMetalReadOnly", err))
+	}
+
+	// any query needs the scope, whatever the servers
+	end := time.Now().UTC().Truncate(time.Minute)
+	_, err := c.metrics.QueryRange(ctx, "vector(1)", promv1.Range{
+		Start: end.Add(-samplePeriod),
+		End:   end,
+		Step:  samplePeriod,
+	})
+	return append(permissions, util.Permission("query_metrics", err))
+}
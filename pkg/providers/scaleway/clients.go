@@ -0,0 +1,124 @@
+package scaleway
+
+import (
+	"context"
+	"fmt"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/scaleway/scaleway-sdk-go/api/baremetal/v1"
+	"github.com/scaleway/scaleway-sdk-go/api/instance/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+// instanceLister lists the instances of a zone and the types they're
+// created from, the pages of the results are read before returning
+//
+//counterfeiter:generate -o fake_instances_test.go -fake-name fakeInstances . instanceLister
+type instanceLister interface {
+	ListServers(ctx context.Context, zone scw.Zone) ([]*instance.Server, error)
+	ListServerTypes(ctx context.Context, zone scw.Zone) (map[string]*instance.ServerType, error)
+}
+
+// elasticMetalLister lists the Elastic Metal servers of a zone and the
+// offers they're ordered from, the pages of the results are read before
+// returning
+//
+//counterfeiter:generate -o fake_elastic_metal_test.go -fake-name fakeElasticMetal . elasticMetalLister
+type elasticMetalLister interface {
+	ListServers(ctx context.Context, zone scw.Zone) ([]*baremetal.Server, error)
+	ListOffers(ctx context.Context, zone scw.Zone) ([]*baremetal.Offer, error)
+}
+
+// metricsQuerier queries the metrics of the servers stored in Cockpit
+//
+//counterfeiter:generate -o fake_metrics_test.go -fake-name fakeMetrics . metricsQuerier
+type metricsQuerier interface {
+	QueryRange(ctx context.Context, query string, r promv1.Range) (model.Matrix, error)
+}
+
+// instancesClient lists the instances with the Instance API
+type instancesClient struct {
+	api     *instance.API
+	project string
+}
+
+// ListServers returns the instances of the project in the zone, all the
+// ones of the organization without a project
+func (c *instancesClient) ListServers(ctx context.Context, zone scw.Zone) ([]*instance.Server, error) {
+	req := &instance.ListServersRequest{Zone: zone}
+	if c.project != "" {
+		req.Project = scw.StringPtr(c.project)
+	}
+
+	resp, err := c.api.ListServers(req, scw.WithContext(ctx), scw.WithAllPages())
+	util.RecordAPICall(provider, instanceAPI, "ListServers", err)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Servers, nil
+}
+
+// ListServerTypes returns the Instance types of the zone by name
+func (c *instancesClient) ListServerTypes(ctx context.Context, zone scw.Zone) (map[string]*instance.ServerType, error) {
+	resp, err := c.api.ListServersTypes(&instance.ListServersTypesRequest{Zone: zone}, scw.WithContext(ctx), scw.WithAllPages())
+	util.RecordAPICall(provider, instanceAPI, "ListServersTypes", err)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Servers, nil
+}
+
+// elasticMetalClient lists the Elastic Metal servers with the Elastic Metal
+// API
+type elasticMetalClient struct {
+	api     *baremetal.API
+	project string
+}
+
+// ListServers returns the Elastic Metal servers of the project in the
+// zone, all the ones of the organization without a project
+func (c *elasticMetalClient) ListServers(ctx context.Context, zone scw.Zone) ([]*baremetal.Server, error) {
+	req := &baremetal.ListServersRequest{Zone: zone}
+	if c.project != "" {
+		req.ProjectID = scw.StringPtr(c.project)
+	}
+
+	resp, err := c.api.ListServers(req, scw.WithContext(ctx), scw.WithAllPages())
+	util.RecordAPICall(provider, baremetalAPI, "ListServers", err)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Servers, nil
+}
+
+// ListOffers returns the Elastic Metal offers of the zone
+func (c *elasticMetalClient) ListOffers(ctx context.Context, zone scw.Zone) ([]*baremetal.Offer, error) {
+	resp, err := c.api.ListOffers(&baremetal.ListOffersRequest{Zone: zone}, scw.WithContext(ctx), scw.WithAllPages())
+	util.RecordAPICall(provider, baremetalAPI, "ListOffers", err)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Offers, nil
+}
+
+// cockpitClient queries the metrics with the Prometheus API of Cockpit
+type cockpitClient struct {
+	api promv1.API
+}
+
+// QueryRange returns the series of the query over the range
+func (c *cockpitClient) QueryRange(ctx context.Context, query string, r promv1.Range) (model.Matrix, error) {
+	value, _, err := c.api.QueryRange(ctx, query, r)
+	util.RecordAPICall(provider, cockpitAPI, "QueryRange", err)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix, ok := value.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result of type %s", value.Type())
+	}
+	return matrix, nil
+}
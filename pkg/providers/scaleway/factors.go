@@ -0,0 +1,38 @@
+package scaleway
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// The emission factors of Scaleway, missing from the emissions-data repo, in
+// its format. The Instance types and the Elastic Metal offers are mapped to
+// the wattage profile of the architecture of their CPUs, see
+// scaleway-embodied.yaml
+//
+//go:embed factors/*.yaml
+var factorsFS embed.FS
+
+// WriteFactors writes the emission factors of Scaleway to dir, to be
+// read with factors.RegisterDataPath
+func WriteFactors(dir string) error {
+	files, err := fs.Glob(factorsFS, "factors/*.yaml")
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		data, err := factorsFS.ReadFile(f)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(f)), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
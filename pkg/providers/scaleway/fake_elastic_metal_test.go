@@ -0,0 +1,197 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package scaleway
+
+import (
+	"context"
+	"sync"
+
+	baremetal "github.com/scaleway/scaleway-sdk-go/api/baremetal/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+type fakeElasticMetal struct {
+	ListOffersStub        func(context.Context, scw.Zone) ([]*baremetal.Offer, error)
+	listOffersMutex       sync.RWMutex
+	listOffersArgsForCall []struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}
+	listOffersReturns struct {
+		result1 []*baremetal.Offer
+		result2 error
+	}
+	listOffersReturnsOnCall map[int]struct {
+		result1 []*baremetal.Offer
+		result2 error
+	}
+	ListServersStub        func(context.Context, scw.Zone) ([]*baremetal.Server, error)
+	listServersMutex       sync.RWMutex
+	listServersArgsForCall []struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}
+	listServersReturns struct {
+		result1 []*baremetal.Server
+		result2 error
+	}
+	listServersReturnsOnCall map[int]struct {
+		result1 []*baremetal.Server
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeElasticMetal) ListOffers(arg1 context.Context, arg2 scw.Zone) ([]*baremetal.Offer, error) {
+	fake.listOffersMutex.Lock()
+	ret, specificReturn := fake.listOffersReturnsOnCall[len(fake.listOffersArgsForCall)]
+	fake.listOffersArgsForCall = append(fake.listOffersArgsForCall, struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}{arg1, arg2})
+	stub := fake.ListOffersStub
+	fakeReturns := fake.listOffersReturns
+	fake.recordInvocation("ListOffers", []interface{}{arg1, arg2})
+	fake.listOffersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeElasticMetal) ListOffersCallCount() int {
+	fake.listOffersMutex.RLock()
+	defer fake.listOffersMutex.RUnlock()
+	return len(fake.listOffersArgsForCall)
+}
+
+func (fake *fakeElasticMetal) ListOffersCalls(stub func(context.Context, scw.Zone) ([]*baremetal.Offer, error)) {
+	fake.listOffersMutex.Lock()
+	defer fake.listOffersMutex.Unlock()
+	fake.ListOffersStub = stub
+}
+
+func (fake *fakeElasticMetal) ListOffersArgsForCall(i int) (context.Context, scw.Zone) {
+	fake.listOffersMutex.RLock()
+	defer fake.listOffersMutex.RUnlock()
+	argsForCall := fake.listOffersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeElasticMetal) ListOffersReturns(result1 []*baremetal.Offer, result2 error) {
+	fake.listOffersMutex.Lock()
+	defer fake.listOffersMutex.Unlock()
+	fake.ListOffersStub = nil
+	fake.listOffersReturns = struct {
+		result1 []*baremetal.Offer
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeElasticMetal) ListOffersReturnsOnCall(i int, result1 []*baremetal.Offer, result2 error) {
+	fake.listOffersMutex.Lock()
+	defer fake.listOffersMutex.Unlock()
+	fake.ListOffersStub = nil
+	if fake.listOffersReturnsOnCall == nil {
+		fake.listOffersReturnsOnCall = make(map[int]struct {
+			result1 []*baremetal.Offer
+			result2 error
+		})
+	}
+	fake.listOffersReturnsOnCall[i] = struct {
+		result1 []*baremetal.Offer
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeElasticMetal) ListServers(arg1 context.Context, arg2 scw.Zone) ([]*baremetal.Server, error) {
+	fake.listServersMutex.Lock()
+	ret, specificReturn := fake.listServersReturnsOnCall[len(fake.listServersArgsForCall)]
+	fake.listServersArgsForCall = append(fake.listServersArgsForCall, struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}{arg1, arg2})
+	stub := fake.ListServersStub
+	fakeReturns := fake.listServersReturns
+	fake.recordInvocation("ListServers", []interface{}{arg1, arg2})
+	fake.listServersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeElasticMetal) ListServersCallCount() int {
+	fake.listServersMutex.RLock()
+	defer fake.listServersMutex.RUnlock()
+	return len(fake.listServersArgsForCall)
+}
+
+func (fake *fakeElasticMetal) ListServersCalls(stub func(context.Context, scw.Zone) ([]*baremetal.Server, error)) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = stub
+}
+
+func (fake *fakeElasticMetal) ListServersArgsForCall(i int) (context.Context, scw.Zone) {
+	fake.listServersMutex.RLock()
+	defer fake.listServersMutex.RUnlock()
+	argsForCall := fake.listServersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeElasticMetal) ListServersReturns(result1 []*baremetal.Server, result2 error) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = nil
+	fake.listServersReturns = struct {
+		result1 []*baremetal.Server
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeElasticMetal) ListServersReturnsOnCall(i int, result1 []*baremetal.Server, result2 error) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = nil
+	if fake.listServersReturnsOnCall == nil {
+		fake.listServersReturnsOnCall = make(map[int]struct {
+			result1 []*baremetal.Server
+			result2 error
+		})
+	}
+	fake.listServersReturnsOnCall[i] = struct {
+		result1 []*baremetal.Server
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeElasticMetal) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeElasticMetal) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ elasticMetalLister = new(fakeElasticMetal)
@@ -0,0 +1,197 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package scaleway
+
+import (
+	"context"
+	"sync"
+
+	instance "github.com/scaleway/scaleway-sdk-go/api/instance/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+type fakeInstances struct {
+	ListServerTypesStub        func(context.Context, scw.Zone) (map[string]*instance.ServerType, error)
+	listServerTypesMutex       sync.RWMutex
+	listServerTypesArgsForCall []struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}
+	listServerTypesReturns struct {
+		result1 map[string]*instance.ServerType
+		result2 error
+	}
+	listServerTypesReturnsOnCall map[int]struct {
+		result1 map[string]*instance.ServerType
+		result2 error
+	}
+	ListServersStub        func(context.Context, scw.Zone) ([]*instance.Server, error)
+	listServersMutex       sync.RWMutex
+	listServersArgsForCall []struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}
+	listServersReturns struct {
+		result1 []*instance.Server
+		result2 error
+	}
+	listServersReturnsOnCall map[int]struct {
+		result1 []*instance.Server
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeInstances) ListServerTypes(arg1 context.Context, arg2 scw.Zone) (map[string]*instance.ServerType, error) {
+	fake.listServerTypesMutex.Lock()
+	ret, specificReturn := fake.listServerTypesReturnsOnCall[len(fake.listServerTypesArgsForCall)]
+	fake.listServerTypesArgsForCall = append(fake.listServerTypesArgsForCall, struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}{arg1, arg2})
+	stub := fake.ListServerTypesStub
+	fakeReturns := fake.listServerTypesReturns
+	fake.recordInvocation("ListServerTypes", []interface{}{arg1, arg2})
+	fake.listServerTypesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeInstances) ListServerTypesCallCount() int {
+	fake.listServerTypesMutex.RLock()
+	defer fake.listServerTypesMutex.RUnlock()
+	return len(fake.listServerTypesArgsForCall)
+}
+
+func (fake *fakeInstances) ListServerTypesCalls(stub func(context.Context, scw.Zone) (map[string]*instance.ServerType, error)) {
+	fake.listServerTypesMutex.Lock()
+	defer fake.listServerTypesMutex.Unlock()
+	fake.ListServerTypesStub = stub
+}
+
+func (fake *fakeInstances) ListServerTypesArgsForCall(i int) (context.Context, scw.Zone) {
+	fake.listServerTypesMutex.RLock()
+	defer fake.listServerTypesMutex.RUnlock()
+	argsForCall := fake.listServerTypesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeInstances) ListServerTypesReturns(result1 map[string]*instance.ServerType, result2 error) {
+	fake.listServerTypesMutex.Lock()
+	defer fake.listServerTypesMutex.Unlock()
+	fake.ListServerTypesStub = nil
+	fake.listServerTypesReturns = struct {
+		result1 map[string]*instance.ServerType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) ListServerTypesReturnsOnCall(i int, result1 map[string]*instance.ServerType, result2 error) {
+	fake.listServerTypesMutex.Lock()
+	defer fake.listServerTypesMutex.Unlock()
+	fake.ListServerTypesStub = nil
+	if fake.listServerTypesReturnsOnCall == nil {
+		fake.listServerTypesReturnsOnCall = make(map[int]struct {
+			result1 map[string]*instance.ServerType
+			result2 error
+		})
+	}
+	fake.listServerTypesReturnsOnCall[i] = struct {
+		result1 map[string]*instance.ServerType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) ListServers(arg1 context.Context, arg2 scw.Zone) ([]*instance.Server, error) {
+	fake.listServersMutex.Lock()
+	ret, specificReturn := fake.listServersReturnsOnCall[len(fake.listServersArgsForCall)]
+	fake.listServersArgsForCall = append(fake.listServersArgsForCall, struct {
+		arg1 context.Context
+		arg2 scw.Zone
+	}{arg1, arg2})
+	stub := fake.ListServersStub
+	fakeReturns := fake.listServersReturns
+	fake.recordInvocation("ListServers", []interface{}{arg1, arg2})
+	fake.listServersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeInstances) ListServersCallCount() int {
+	fake.listServersMutex.RLock()
+	defer fake.listServersMutex.RUnlock()
+	return len(fake.listServersArgsForCall)
+}
+
+func (fake *fakeInstances) ListServersCalls(stub func(context.Context, scw.Zone) ([]*instance.Server, error)) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = stub
+}
+
+func (fake *fakeInstances) ListServersArgsForCall(i int) (context.Context, scw.Zone) {
+	fake.listServersMutex.RLock()
+	defer fake.listServersMutex.RUnlock()
+	argsForCall := fake.listServersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *fakeInstances) ListServersReturns(result1 []*instance.Server, result2 error) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = nil
+	fake.listServersReturns = struct {
+		result1 []*instance.Server
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) ListServersReturnsOnCall(i int, result1 []*instance.Server, result2 error) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = nil
+	if fake.listServersReturnsOnCall == nil {
+		fake.listServersReturnsOnCall = make(map[int]struct {
+			result1 []*instance.Server
+			result2 error
+		})
+	}
+	fake.listServersReturnsOnCall[i] = struct {
+		result1 []*instance.Server
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeInstances) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeInstances) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ instanceLister = new(fakeInstances)
@@ -0,0 +1,120 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package scaleway
+
+import (
+	"context"
+	"sync"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+type fakeMetrics struct {
+	QueryRangeStub        func(context.Context, string, v1.Range) (model.Matrix, error)
+	queryRangeMutex       sync.RWMutex
+	queryRangeArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 v1.Range
+	}
+	queryRangeReturns struct {
+		result1 model.Matrix
+		result2 error
+	}
+	queryRangeReturnsOnCall map[int]struct {
+		result1 model.Matrix
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *fakeMetrics) QueryRange(arg1 context.Context, arg2 string, arg3 v1.Range) (model.Matrix, error) {
+	fake.queryRangeMutex.Lock()
+	ret, specificReturn := fake.queryRangeReturnsOnCall[len(fake.queryRangeArgsForCall)]
+	fake.queryRangeArgsForCall = append(fake.queryRangeArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 v1.Range
+	}{arg1, arg2, arg3})
+	stub := fake.QueryRangeStub
+	fakeReturns := fake.queryRangeReturns
+	fake.recordInvocation("QueryRange", []interface{}{arg1, arg2, arg3})
+	fake.queryRangeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *fakeMetrics) QueryRangeCallCount() int {
+	fake.queryRangeMutex.RLock()
+	defer fake.queryRangeMutex.RUnlock()
+	return len(fake.queryRangeArgsForCall)
+}
+
+func (fake *fakeMetrics) QueryRangeCalls(stub func(context.Context, string, v1.Range) (model.Matrix, error)) {
+	fake.queryRangeMutex.Lock()
+	defer fake.queryRangeMutex.Unlock()
+	fake.QueryRangeStub = stub
+}
+
+func (fake *fakeMetrics) QueryRangeArgsForCall(i int) (context.Context, string, v1.Range) {
+	fake.queryRangeMutex.RLock()
+	defer fake.queryRangeMutex.RUnlock()
+	argsForCall := fake.queryRangeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *fakeMetrics) QueryRangeReturns(result1 model.Matrix, result2 error) {
+	fake.queryRangeMutex.Lock()
+	defer fake.queryRangeMutex.Unlock()
+	fake.QueryRangeStub = nil
+	fake.queryRangeReturns = struct {
+		result1 model.Matrix
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) QueryRangeReturnsOnCall(i int, result1 model.Matrix, result2 error) {
+	fake.queryRangeMutex.Lock()
+	defer fake.queryRangeMutex.Unlock()
+	fake.QueryRangeStub = nil
+	if fake.queryRangeReturnsOnCall == nil {
+		fake.queryRangeReturnsOnCall = make(map[int]struct {
+			result1 model.Matrix
+			result2 error
+		})
+	}
+	fake.queryRangeReturnsOnCall[i] = struct {
+		result1 model.Matrix
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *fakeMetrics) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *fakeMetrics) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metricsQuerier = new(fakeMetrics)
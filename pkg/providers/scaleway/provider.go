@@ -0,0 +1,39 @@
+package scaleway
+
+// The fakes of the Scaleway APIs used by the tests
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.13.0 -generate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+const provider = v1.Scaleway
+
+// The services of the servers
+const (
+	instancesService    = "Instances"
+	elasticMetalService = "Elastic Metal"
+)
+
+// API families, used for rate limiting
+const (
+	instanceAPI  = "instance"
+	baremetalAPI = "baremetal"
+	cockpitAPI   = "cockpit"
+)
+
+// throttled marks the errors of the requests rejected by the Scaleway API
+// because of their rate with v1.ErrProviderThrottled
+func throttled(err error) error {
+	var rerr *scw.ResponseError
+	if errors.As(err, &rerr) && rerr.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", v1.ErrProviderThrottled, err)
+	}
+
+	return err
+}
@@ -0,0 +1,627 @@
+package scaleway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	promconfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	"github.com/re-cinq/aether/pkg/relabel"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/scaleway/scaleway-sdk-go/api/baremetal/v1"
+	cockpit "github.com/scaleway/scaleway-sdk-go/api/cockpit/v1beta1"
+	"github.com/scaleway/scaleway-sdk-go/api/instance/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+// samplePeriod is the resolution the metrics of the servers are queried at
+const samplePeriod = time.Minute
+
+// The environment variable of the Cockpit token with the query:read scope,
+// the API keys are the ones of the Scaleway CLI: SCW_ACCESS_KEY and
+// SCW_SECRET_KEY
+const cockpitTokenVariable = "SCW_COCKPIT_TOKEN"
+
+// The CPU time of the instances in seconds, summed over their vCPUs, which
+// the hypervisors push to Cockpit
+const instanceCPUQuery = `sum(instance_server_cpu_seconds_total{resource_id=%q})`
+
+// The CPU time of the Elastic Metal servers by mode. The servers aren't
+// virtualized, the CPU time is the one of their node_exporter pushed to
+// Cockpit with the ID of the server in the resource_id label
+const elasticMetalCPUQuery = `sum by (mode) (node_cpu_seconds_total{resource_id=%q})`
+
+// The modes of the CPU time which isn't used by the server: idle or
+// waiting for I/O
+var idleModes = []string{"idle", "iowait"}
+
+// The zones of the APIs, the other ones have none of their servers
+var (
+	instanceZones     = (&instance.API{}).Zones()
+	elasticMetalZones = (&baremetal.API{}).Zones()
+)
+
+// The key of the servers in the cache
+const serversKey = "servers"
+
+// Client is the structure used as the provider for Scaleway
+type Client struct {
+	// Scaleway clients, faked by the tests
+	instances    instanceLister
+	elasticMetal elasticMetalLister
+	metrics      metricsQuerier
+
+	// The zones scraped
+	zones []scw.Zone
+
+	// The running instances and the ready Elastic Metal servers
+	cache *cache.Cache
+}
+
+type options func(*Client)
+
+// vm is a running instance or a ready Elastic Metal server
+type vm struct {
+	instance v1.Instance
+	id       string
+	vCPU     float64
+}
+
+// New returns a new instance of the Scaleway provider for the instances and
+// the Elastic Metal servers of the project of the account, all the ones of
+// the organization of the API key without a project
+func New(ctx context.Context, account *config.Account, opts ...options) (*Client, error) {
+	c := &Client{
+		zones: zones(account.Regions),
+		cache: cache.New(time.Hour, time.Hour),
+	}
+
+	// overwrite any options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.instances != nil && c.elasticMetal != nil && c.metrics != nil {
+		return c, nil
+	}
+
+	p, err := profile(account)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts := []scw.ClientOption{scw.WithProfile(p)}
+	if endpoint := account.Endpoints["api"]; endpoint != "" {
+		clientOpts = append(clientOpts, scw.WithAPIURL(endpoint))
+	}
+
+	client, err := scw.NewClient(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating the Scaleway client: %w", err)
+	}
+
+	project := account.Project
+	if project == "" && p.DefaultProjectID != nil {
+		project = *p.DefaultProjectID
+	}
+
+	if c.instances == nil {
+		c.instances = &instancesClient{api: instance.NewAPI(client), project: project}
+	}
+	if c.elasticMetal == nil {
+		c.elasticMetal = &elasticMetalClient{api: baremetal.NewAPI(client), project: project}
+	}
+	if c.metrics == nil {
+		c.metrics, err = newCockpitClient(ctx, client, account, project)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// profile returns the profile of the Scaleway configuration file, the one
+// of the first file of the credentials or ~/.config/scw/config.yaml, with
+// the environment taking precedence like with the Scaleway CLI
+func profile(account *config.Account) (*scw.Profile, error) {
+	var (
+		cfg *scw.Config
+		err error
+	)
+	if len(account.Credentials.FilePaths) > 0 {
+		cfg, err = scw.LoadConfigFromPath(account.Credentials.FilePaths[0])
+	} else {
+		cfg, err = scw.LoadConfig()
+	}
+
+	p := &scw.Profile{}
+	var notFound *scw.ConfigFileNotFoundError
+	switch {
+	case err == nil:
+		if account.Credentials.Profile != "" {
+			p, err = cfg.GetProfile(account.Credentials.Profile)
+		} else {
+			p, err = cfg.GetActiveProfile()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the Scaleway profile: %w", err)
+		}
+	// the default configuration file is optional
+	case len(account.Credentials.FilePaths) == 0 && errors.As(err, &notFound):
+	default:
+		return nil, fmt.Errorf("failed reading the Scaleway configuration: %w", err)
+	}
+
+	p = scw.MergeProfiles(p, scw.LoadEnvProfile())
+	if p.AccessKey == nil || p.SecretKey == nil {
+		return nil, errors.New("no Scaleway API key, set SCW_ACCESS_KEY and SCW_SECRET_KEY")
+	}
+
+	return p, nil
+}
+
+// newCockpitClient returns the client of the Prometheus API of the Cockpit
+// of the project, authenticated with a Cockpit token: the second file of
+// the credentials, e.g. a mounted secret read at every query, or the one of
+// the environment otherwise
+func newCockpitClient(ctx context.Context, client *scw.Client, account *config.Account, project string) (*cockpitClient, error) {
+	var rt http.RoundTripper
+	switch {
+	case len(account.Credentials.FilePaths) > 1:
+		rt = promconfig.NewAuthorizationCredentialsFileRoundTripper("Bearer", account.Credentials.FilePaths[1], api.DefaultRoundTripper)
+	case os.Getenv(cockpitTokenVariable) != "":
+		rt = promconfig.NewAuthorizationCredentialsRoundTripper("Bearer", promconfig.Secret(os.Getenv(cockpitTokenVariable)), api.DefaultRoundTripper)
+	default:
+		return nil, fmt.Errorf("no Cockpit token, set %s", cockpitTokenVariable)
+	}
+
+	// the metrics URL of the Cockpit of the project, unless set
+	address := account.Endpoints["metrics"]
+	if address == "" {
+		if project == "" {
+			return nil, errors.New("no Scaleway project, set the project of the account or SCW_DEFAULT_PROJECT_ID")
+		}
+
+		if err := util.WaitForAPI(ctx, provider, cockpitAPI); err != nil {
+			return nil, err
+		}
+		cp, err := cockpit.NewAPI(client).GetCockpit(&cockpit.GetCockpitRequest{ProjectID: project}, scw.WithContext(ctx))
+		util.RecordAPICall(provider, cockpitAPI, "GetCockpit", err)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting the Cockpit of project %s: %w", project, err)
+		}
+		if cp.Endpoints == nil || cp.Endpoints.MetricsURL == "" {
+			return nil, fmt.Errorf("the Cockpit of project %s has no metrics URL", project)
+		}
+		address = cp.Endpoints.MetricsURL
+	}
+
+	promClient, err := api.NewClient(api.Config{
+		Address:      strings.TrimSuffix(address, "/") + "/prometheus",
+		RoundTripper: rt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating the Cockpit client: %w", err)
+	}
+
+	return &cockpitClient{api: promv1.NewAPI(promClient)}, nil
+}
+
+// zones returns the zones of the regions: a zone, e.g. fr-par-2, or all the
+// zones of a region, e.g. nl-ams. All the zones when empty
+func zones(regions []string) []scw.Zone {
+	if len(regions) == 0 {
+		return scw.AllZones
+	}
+
+	var zones []scw.Zone
+	for _, r := range regions {
+		if region := scw.Region(r); region.Exists() {
+			zones = append(zones, region.GetZones()...)
+			continue
+		}
+		zones = append(zones, scw.Zone(r))
+	}
+
+	slices.Sort(zones)
+	return slices.Compact(zones)
+}
+
+// Refresh fetches the running instances and the ready Elastic Metal servers
+// of the zones and caches them for the metrics collection
+func (c *Client) Refresh(ctx context.Context) error {
+	var vms []vm
+
+	// the zone of a server of every type, where its specs are fetched
+	types := make(map[string]scw.Zone)
+	offers := make(map[string]scw.Zone)
+
+	for _, zone := range c.zones {
+		if slices.Contains(instanceZones, zone) {
+			if err := util.WaitForAPI(ctx, provider, instanceAPI); err != nil {
+				return err
+			}
+
+			servers, err := c.instances.ListServers(ctx, zone)
+			if err != nil {
+				return fmt.Errorf("failed listing the instances of %s: %w", zone, err)
+			}
+
+			for _, s := range servers {
+				if s.State != instance.ServerStateRunning {
+					continue
+				}
+				vms = append(vms, newInstance(s))
+				types[s.CommercialType] = zone
+			}
+		}
+
+		if slices.Contains(elasticMetalZones, zone) {
+			if err := util.WaitForAPI(ctx, provider, baremetalAPI); err != nil {
+				return err
+			}
+
+			servers, err := c.elasticMetal.ListServers(ctx, zone)
+			if err != nil {
+				return fmt.Errorf("failed listing the Elastic Metal servers of %s: %w", zone, err)
+			}
+
+			for _, s := range servers {
+				if s.Status != baremetal.ServerStatusReady {
+					continue
+				}
+				vms = append(vms, newElasticMetal(s))
+				offers[s.OfferName] = zone
+			}
+		}
+	}
+
+	// the specs of the types and the offers are shared by all the projects,
+	// the servers without them can't be accounted for and are left out
+	specs, err := util.Specs(ctx, provider, sortedKeys(types), c.instanceTypeSpecs(types))
+	if err != nil {
+		log.FromContext(ctx).Warn("failed to retrieve the Instance types", "error", err)
+	}
+	offerSpecs, err := util.Specs(ctx, provider, sortedKeys(offers), c.offerSpecs(offers))
+	if err != nil {
+		log.FromContext(ctx).Warn("failed to retrieve the Elastic Metal offers", "error", err)
+	}
+
+	known := make([]vm, 0, len(vms))
+	for _, vm := range vms {
+		spec, ok := specs[vm.instance.Kind]
+		if vm.instance.Service == elasticMetalService {
+			spec, ok = offerSpecs[vm.instance.Kind]
+		}
+		if !ok || spec.VCPU <= 0 {
+			continue
+		}
+
+		vm.vCPU = spec.VCPU
+		known = append(known, vm)
+	}
+
+	// the servers deleted in the meantime are dropped with the previous
+	// list
+	c.cache.Set(serversKey, known, cache.DefaultExpiration)
+	return nil
+}
+
+// instanceTypeSpecs returns the fetcher of the specs of the Instance types,
+// the types of a zone are listed at once
+func (c *Client) instanceTypeSpecs(types map[string]scw.Zone) util.SpecFetcher {
+	return func(ctx context.Context, kinds []string) (map[string]util.Spec, error) {
+		specs := make(map[string]util.Spec, len(kinds))
+		listed := make(map[scw.Zone]map[string]*instance.ServerType)
+		for _, kind := range kinds {
+			zone := types[kind]
+			if _, ok := listed[zone]; !ok {
+				if err := util.WaitForAPI(ctx, provider, instanceAPI); err != nil {
+					return nil, err
+				}
+
+				serverTypes, err := c.instances.ListServerTypes(ctx, zone)
+				if err != nil {
+					return nil, err
+				}
+				listed[zone] = serverTypes
+			}
+
+			t, ok := listed[zone][kind]
+			if !ok {
+				continue
+			}
+			specs[kind] = util.Spec{
+				VCPU:     float64(t.Ncpus),
+				MemoryGB: float64(t.RAM) / (1 << 30),
+			}
+		}
+		return specs, nil
+	}
+}
+
+// offerSpecs returns the fetcher of the specs of the Elastic Metal offers,
+// the offers of a zone are listed at once. The vCPUs of a server are the
+// threads of its CPUs
+func (c *Client) offerSpecs(offers map[string]scw.Zone) util.SpecFetcher {
+	return func(ctx context.Context, kinds []string) (map[string]util.Spec, error) {
+		specs := make(map[string]util.Spec, len(kinds))
+		listed := make(map[scw.Zone][]*baremetal.Offer)
+		for _, kind := range kinds {
+			zone := offers[kind]
+			if _, ok := listed[zone]; !ok {
+				if err := util.WaitForAPI(ctx, provider, baremetalAPI); err != nil {
+					return nil, err
+				}
+
+				zoneOffers, err := c.elasticMetal.ListOffers(ctx, zone)
+				if err != nil {
+					return nil, err
+				}
+				listed[zone] = zoneOffers
+			}
+
+			i := slices.IndexFunc(listed[zone], func(o *baremetal.Offer) bool {
+				return o.Name == kind
+			})
+			if i < 0 {
+				continue
+			}
+
+			var spec util.Spec
+			for _, cpu := range listed[zone][i].CPUs {
+				spec.VCPU += float64(cpu.ThreadCount)
+			}
+			for _, memory := range listed[zone][i].Memories {
+				spec.MemoryGB += float64(memory.Capacity) / (1 << 30)
+			}
+			specs[kind] = spec
+		}
+		return specs, nil
+	}
+}
+
+// GetMetricsForInstances returns the CPU utilization of the cached servers
+// over the window. The metrics are queried by server, concurrently, the
+// servers without metrics in Cockpit aren't returned
+func (c *Client) GetMetricsForInstances(ctx context.Context, window v1.Window) ([]v1.Instance, error) {
+	cached, ok := c.cache.Get(serversKey)
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		instances []v1.Instance
+		mu        sync.Mutex
+	)
+
+	err := util.ForEach(ctx, cached.([]vm), func(ctx context.Context, vm vm) error {
+		i, ok, err := c.serverMetrics(ctx, &vm, window)
+		if err != nil {
+			return fmt.Errorf("failed getting the metrics of server %s: %w", vm.id, err)
+		}
+		if !ok {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		instances = append(instances, i)
+		return nil
+	})
+
+	return instances, err
+}
+
+// serverMetrics returns the server with its CPU utilization over the
+// window, false when it has no CPU metrics
+func (c *Client) serverMetrics(ctx context.Context, vm *vm, window v1.Window) (v1.Instance, bool, error) {
+	query := fmt.Sprintf(instanceCPUQuery, vm.id)
+	if vm.instance.Service == elasticMetalService {
+		query = fmt.Sprintf(elasticMetalCPUQuery, vm.id)
+	}
+
+	// the CPU time is a counter, the sample before the window is needed
+	// for the utilization of its first minute
+	if err := util.WaitForAPI(ctx, provider, cockpitAPI); err != nil {
+		return v1.Instance{}, false, err
+	}
+	series, err := c.metrics.QueryRange(ctx, query, promv1.Range{
+		Start: window.Start.Add(-samplePeriod).UTC(),
+		End:   window.End.UTC(),
+		Step:  samplePeriod,
+	})
+	if err != nil {
+		return v1.Instance{}, false, err
+	}
+
+	var cpu []util.Sample
+	if vm.instance.Service == elasticMetalService {
+		cpu = modeUtilization(series)
+	} else {
+		cpu = utilization(series, vm.vCPU)
+	}
+
+	resolution := util.Resolution(cpu, samplePeriod)
+	usage, observed, ok := util.Align(cpu, resolution, window)
+	if !ok {
+		return v1.Instance{}, false, nil
+	}
+
+	i := vm.instance
+	i.Metrics = v1.Metrics{}
+
+	m := v1.NewMetric(v1.CPU.String())
+	m.Unit = v1.VCPU
+	m.ResourceType = v1.CPU
+	m.Usage = usage
+	m.UnitAmount = vm.vCPU
+	m.UpdatedAt = window.End.UTC()
+	m.Observed = observed
+	m.Labels = v1.Labels{
+		util.ResolutionLabel: util.FormatResolution(resolution),
+	}
+	i.Metrics.Upsert(m)
+
+	return i, true, nil
+}
+
+// utilization returns the CPU utilization in % between the consecutive
+// samples of the CPU time summed over the vCPUs. A sample starts at the
+// first of the two, the decreasing CPU times of a reboot are skipped
+func utilization(series model.Matrix, vCPU float64) []util.Sample {
+	var samples []util.Sample
+	for _, s := range series {
+		for n := 1; n < len(s.Values); n++ {
+			prev, p := s.Values[n-1], s.Values[n]
+
+			spent := float64(p.Value - prev.Value)
+			elapsed := p.Timestamp.Sub(prev.Timestamp).Seconds() * vCPU
+			if spent < 0 || elapsed <= 0 {
+				continue
+			}
+
+			samples = append(samples, util.Sample{
+				Time:  prev.Timestamp.Time().UTC(),
+				Value: min(100*spent/elapsed, 100),
+			})
+		}
+	}
+
+	slices.SortFunc(samples, func(a, b util.Sample) int {
+		return a.Time.Compare(b.Time)
+	})
+	return samples
+}
+
+// modeUtilization returns the CPU utilization in % between the consecutive
+// samples of the CPU time of the modes, the CPU time is summed over the
+// CPUs. A sample starts at the first of the two, the decreasing CPU times
+// of a reboot are skipped
+func modeUtilization(series model.Matrix) []util.Sample {
+	total := make(map[model.Time]float64)
+	idle := make(map[model.Time]float64)
+	for _, s := range series {
+		isIdle := slices.Contains(idleModes, string(s.Metric["mode"]))
+		for _, p := range s.Values {
+			total[p.Timestamp] += float64(p.Value)
+			if isIdle {
+				idle[p.Timestamp] += float64(p.Value)
+			}
+		}
+	}
+
+	times := make([]model.Time, 0, len(total))
+	for t := range total {
+		times = append(times, t)
+	}
+	slices.Sort(times)
+
+	var samples []util.Sample
+	for n := 1; n < len(times); n++ {
+		prev, t := times[n-1], times[n]
+
+		spent := total[t] - total[prev]
+		unused := idle[t] - idle[prev]
+		if spent <= 0 || unused < 0 || unused > spent {
+			continue
+		}
+
+		samples = append(samples, util.Sample{
+			Time:  prev.Time().UTC(),
+			Value: 100 * (spent - unused) / spent,
+		})
+	}
+
+	return samples
+}
+
+// newInstance returns a running instance, its vCPUs are the ones of its
+// type
+func newInstance(s *instance.Server) vm {
+	// the instances are named after their ID, their name isn't unique
+	i := v1.NewInstance(s.ID, provider)
+	i.Service = instancesService
+	i.Kind = s.CommercialType
+	i.Architecture = architecture(s.Arch)
+	if s.CreationDate != nil {
+		i.StartedAt = s.CreationDate.UTC()
+	}
+	setLocation(i, s.Zone)
+	setLabels(i, s.Name, s.Tags)
+
+	return vm{instance: *i, id: s.ID}
+}
+
+// newElasticMetal returns a ready Elastic Metal server, its vCPUs are the
+// threads of the CPUs of its offer
+func newElasticMetal(s *baremetal.Server) vm {
+	i := v1.NewInstance(s.ID, provider)
+	i.Service = elasticMetalService
+	i.Kind = s.OfferName
+	// the Elastic Metal servers all run on x86 CPUs
+	i.Architecture = "x86_64"
+	if s.CreatedAt != nil {
+		i.StartedAt = s.CreatedAt.UTC()
+	}
+	setLocation(i, s.Zone)
+	setLabels(i, s.Name, s.Tags)
+
+	return vm{instance: *i, id: s.ID}
+}
+
+// setLocation sets the zone of the server and its region, e.g. fr-par for
+// fr-par-2, the emission factors are the ones of the region
+func setLocation(i *v1.Instance, zone scw.Zone) {
+	i.Zone = zone.String()
+	if region, err := zone.Region(); err == nil {
+		i.Region = region.String()
+	}
+}
+
+// setLabels sets the name label and the tags of the server. The tags have
+// no value, the ones in the key=value format are split
+func setLabels(i *v1.Instance, name string, tags []string) {
+	i.Labels[v1.NameLabel] = name
+
+	for _, tag := range tags {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			value = "true"
+		}
+		i.Labels[v1.TagLabelPrefix+relabel.LabelName(key)] = value
+	}
+}
+
+// architecture returns the architecture of an instance, e.g. x86_64 or
+// arm64, empty when unknown
+func architecture(arch instance.Arch) string {
+	if arch == instance.ArchUnknownArch {
+		return ""
+	}
+	return arch.String()
+}
+
+// sortedKeys returns the types or the offers sorted
+func sortedKeys(m map[string]scw.Zone) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
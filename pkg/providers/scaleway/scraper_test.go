@@ -0,0 +1,361 @@
+package scaleway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/providers/util"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/scaleway/scaleway-sdk-go/api/baremetal/v1"
+	"github.com/scaleway/scaleway-sdk-go/api/instance/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestClients overwrites the Scaleway clients with fakes
+func withTestClients(instances instanceLister, elasticMetal elasticMetalLister, metrics metricsQuerier) options {
+	return func(c *Client) {
+		c.instances = instances
+		c.elasticMetal = elasticMetal
+		c.metrics = metrics
+	}
+}
+
+// server returns a running instance of the type
+func server(id, kind string, zone scw.Zone) *instance.Server {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &instance.Server{
+		ID:             id,
+		Name:           "web",
+		State:          instance.ServerStateRunning,
+		CommercialType: kind,
+		Arch:           instance.ArchX86_64,
+		CreationDate:   &created,
+		Zone:           zone,
+		Tags:           []string{"team=checkout", "production"},
+	}
+}
+
+// cpuTime returns the CPU time of a mode, increasing by the amount every
+// minute from the start
+func cpuTime(mode string, start time.Time, increase float64, n int) *model.SampleStream {
+	s := &model.SampleStream{
+		Metric: model.Metric{},
+	}
+	if mode != "" {
+		s.Metric["mode"] = model.LabelValue(mode)
+	}
+	for i := 0; i < n; i++ {
+		s.Values = append(s.Values, model.SamplePair{
+			Timestamp: model.TimeFromUnix(start.Add(time.Duration(i) * time.Minute).Unix()),
+			Value:     model.SampleValue(float64(i) * increase),
+		})
+	}
+	return s
+}
+
+func TestScrape(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	window := v1.NewWindow(end, 5*time.Minute)
+
+	stopped := server("i-3", "DEV1-S", scw.ZoneFrPar1)
+	stopped.State = instance.ServerStateStopped
+
+	instances := &fakeInstances{}
+	instances.ListServersStub = func(ctx context.Context, zone scw.Zone) ([]*instance.Server, error) {
+		if zone != scw.ZoneFrPar1 {
+			return nil, nil
+		}
+		return []*instance.Server{
+			server("i-1", "PRO2-XS", scw.ZoneFrPar1),
+			// no metrics, e.g. just started
+			server("i-2", "PRO2-XS", scw.ZoneFrPar1),
+			stopped,
+			// unknown to the API
+			server("i-4", "RETIRED-S", scw.ZoneFrPar1),
+		}, nil
+	}
+	instances.ListServerTypesReturns(map[string]*instance.ServerType{
+		"PRO2-XS": {Ncpus: 4, RAM: 16 << 30, Arch: instance.ArchX86_64},
+	}, nil)
+
+	elasticMetal := &fakeElasticMetal{}
+	elasticMetal.ListServersStub = func(ctx context.Context, zone scw.Zone) ([]*baremetal.Server, error) {
+		if zone != scw.ZoneFrPar2 {
+			return nil, nil
+		}
+		return []*baremetal.Server{
+			{
+				ID:        "em-1",
+				Name:      "db",
+				Status:    baremetal.ServerStatusReady,
+				OfferName: "EM-B112X-SSD",
+				Zone:      scw.ZoneFrPar2,
+			},
+		}, nil
+	}
+	elasticMetal.ListOffersReturns([]*baremetal.Offer{
+		{
+			Name:     "EM-B112X-SSD",
+			CPUs:     []*baremetal.CPU{{CoreCount: 6, ThreadCount: 12}},
+			Memories: []*baremetal.Memory{{Capacity: 16 << 30}, {Capacity: 16 << 30}},
+		},
+	}, nil)
+
+	// 60s of CPU time every minute over 4 vCPUs for the instance, 30s of
+	// user time and 90s of idle time every minute for the server
+	start := window.Start.Add(-time.Minute)
+	m := &fakeMetrics{}
+	m.QueryRangeStub = func(ctx context.Context, query string, r promv1.Range) (model.Matrix, error) {
+		switch query {
+		case `sum(instance_server_cpu_seconds_total{resource_id="i-1"})`:
+			return model.Matrix{cpuTime("", start, 60, 6)}, nil
+		case `sum by (mode) (node_cpu_seconds_total{resource_id="em-1"})`:
+			return model.Matrix{
+				cpuTime("user", start, 30, 6),
+				cpuTime("idle", start, 90, 6),
+			}, nil
+		}
+		return nil, nil
+	}
+
+	c, err := New(ctx, &config.Account{Regions: []string{"fr-par"}}, withTestClients(instances, elasticMetal, m))
+	assert.NoError(err)
+
+	events := make(chan v1.Instance, 2)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, bustest.Collector(events))
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	s := &Scraper{Client: c, account: "demo", Bus: b}
+	defer s.Stop(ctx)
+
+	n, err := s.Scrape(ctx, window)
+	assert.NoError(err)
+	assert.Equal(2, n)
+
+	// the zones of the region are listed, the servers of known types are
+	// queried
+	assert.Equal(3, instances.ListServersCallCount())
+	assert.Equal(2, elasticMetal.ListServersCallCount())
+	assert.Equal(3, m.QueryRangeCallCount())
+	_, _, r := m.QueryRangeArgsForCall(0)
+	assert.True(start.Equal(r.Start))
+	assert.True(end.Equal(r.End))
+	assert.Equal(time.Minute, r.Step)
+
+	collected := make(map[string]v1.Instance)
+	for range 2 {
+		i := <-events
+		collected[i.Name] = i
+	}
+
+	i := collected["i-1"]
+	assert.Equal(v1.Scaleway, i.Provider)
+	assert.Equal("Instances", i.Service)
+	assert.Equal("PRO2-XS", i.Kind)
+	assert.Equal("fr-par", i.Region)
+	assert.Equal("fr-par-1", i.Zone)
+	assert.Equal("x86_64", i.Architecture)
+	assert.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), i.StartedAt)
+	assert.Equal("demo", i.Labels[v1.AccountLabel])
+	assert.Equal("web", i.Labels[v1.NameLabel])
+	assert.Equal("checkout", i.Labels["tag_team"])
+	assert.Equal("true", i.Labels["tag_production"])
+
+	cpu := i.Metrics[v1.CPU.String()]
+	assert.Equal(25.0, cpu.Usage)
+	assert.Equal(4.0, cpu.UnitAmount)
+	assert.Equal("1m", cpu.Labels[util.ResolutionLabel])
+	assert.True(end.Equal(cpu.UpdatedAt))
+
+	em := collected["em-1"]
+	assert.Equal("Elastic Metal", em.Service)
+	assert.Equal("EM-B112X-SSD", em.Kind)
+	assert.Equal("fr-par-2", em.Zone)
+	assert.Equal("db", em.Labels[v1.NameLabel])
+
+	cpu = em.Metrics[v1.CPU.String()]
+	assert.Equal(25.0, cpu.Usage)
+	assert.Equal(12.0, cpu.UnitAmount)
+
+	// a failing API fails the scrape
+	instances.ListServersReturns(nil, &scw.ResponseError{StatusCode: http.StatusUnauthorized, Message: "unauthorized"})
+	instances.ListServersStub = nil
+	_, err = s.Scrape(ctx, window)
+	assert.ErrorContains(err, "unauthorized")
+}
+
+func TestUtilization(t *testing.T) {
+	assert := require.New(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the CPU time drops with a reboot
+	total := cpuTime("", start, 60, 4)
+	total.Values[3].Value = 5
+	assert.Equal([]util.Sample{
+		{Time: start, Value: 50},
+		{Time: start.Add(time.Minute), Value: 50},
+	}, utilization(model.Matrix{total}, 2))
+
+	user := cpuTime("user", start, 10, 4)
+	user.Values[3].Value = 5
+	samples := modeUtilization(model.Matrix{
+		user,
+		cpuTime("iowait", start, 10, 4),
+		cpuTime("idle", start, 20, 4),
+	})
+	assert.Equal([]util.Sample{
+		{Time: start, Value: 25},
+		{Time: start.Add(time.Minute), Value: 25},
+	}, samples)
+
+	assert.Empty(utilization(nil, 2))
+	assert.Empty(modeUtilization(nil))
+}
+
+func TestZones(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(scw.AllZones, zones(nil))
+	assert.Equal([]scw.Zone{scw.ZoneFrPar1, scw.ZoneFrPar2, scw.ZoneFrPar3, scw.ZoneNlAms2}, zones([]string{"nl-ams-2", "fr-par", "fr-par-2"}))
+}
+
+func TestCheck(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	instances := &fakeInstances{}
+	elasticMetal := &fakeElasticMetal{}
+	elasticMetal.ListServersReturns(nil, &scw.ResponseError{StatusCode: http.StatusForbidden, Message: "insufficient permissions"})
+	m := &fakeMetrics{}
+
+	c, err := New(ctx, &config.Account{Regions: []string{"nl-ams-3", "fr-par-2"}}, withTestClients(instances, elasticMetal, m))
+	assert.NoError(err)
+
+	permissions := c.Check(ctx)
+	assert.Len(permissions, 3)
+	assert.Equal(v1.Permission{Name: "InstancesReadOnly", Granted: true}, permissions[0])
+	assert.Equal("ElasticMetalReadOnly", permissions[1].Name)
+	assert.False(permissions[1].Granted)
+	assert.Contains(permissions[1].Error, "insufficient permissions")
+	assert.Equal(v1.Permission{Name: "query_metrics", Granted: true}, permissions[2])
+
+	// nl-ams-3 has no Elastic Metal servers
+	_, zone := instances.ListServersArgsForCall(0)
+	assert.Equal(scw.ZoneFrPar2, zone)
+	_, zone = elasticMetal.ListServersArgsForCall(0)
+	assert.Equal(scw.ZoneFrPar2, zone)
+}
+
+func TestProfile(t *testing.T) {
+	assert := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(os.WriteFile(path, []byte(`access_key: SCWFILEFILEFILEFILE1
+secret_key: 11111111-1111-1111-1111-111111111111
+profiles:
+  audit:
+    access_key: SCWAUDITAUDITAUDIT1
+`), 0o600))
+
+	// the variables set, even empty, take precedence
+	for _, name := range []string{"SCW_ACCESS_KEY", "SCW_SECRET_KEY", "SCW_PROFILE"} {
+		t.Setenv(name, "")
+		assert.NoError(os.Unsetenv(name))
+	}
+
+	p, err := profile(&config.Account{Credentials: config.ProviderConfig{FilePaths: []string{path}}})
+	assert.NoError(err)
+	assert.Equal("SCWFILEFILEFILEFILE1", *p.AccessKey)
+
+	// the profile is merged on the default one
+	p, err = profile(&config.Account{Credentials: config.ProviderConfig{Profile: "audit", FilePaths: []string{path}}})
+	assert.NoError(err)
+	assert.Equal("SCWAUDITAUDITAUDIT1", *p.AccessKey)
+	assert.Equal("11111111-1111-1111-1111-111111111111", *p.SecretKey)
+
+	// the environment takes precedence
+	t.Setenv("SCW_ACCESS_KEY", "SCWENVENVENVENVENV1")
+	p, err = profile(&config.Account{Credentials: config.ProviderConfig{FilePaths: []string{path}}})
+	assert.NoError(err)
+	assert.Equal("SCWENVENVENVENVENV1", *p.AccessKey)
+
+	_, err = profile(&config.Account{Credentials: config.ProviderConfig{FilePaths: []string{filepath.Join(t.TempDir(), "missing.yaml")}}})
+	assert.ErrorContains(err, "failed reading the Scaleway configuration")
+
+	t.Setenv("SCW_CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NoError(os.Unsetenv("SCW_ACCESS_KEY"))
+	_, err = New(context.Background(), &config.Account{})
+	assert.ErrorContains(err, "no Scaleway API key")
+}
+
+func TestWriteFactors(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(WriteFactors(dir))
+
+	ef, err := factors.GetProviderEmissionFactors(provider, dir)
+	assert.NoError(err)
+
+	// every type has the wattage profile of its architecture
+	for kind, e := range ef.Embodied {
+		assert.NotZero(e.MaxWatts, kind)
+	}
+	assert.Equal("EPYC 3rd Gen", ef.Embodied["PRO2-XS"].Architecture)
+	assert.Equal("Ampere Altra", ef.Embodied["COPARM1-4C-16G"].Architecture)
+	assert.Equal(ef.Embodied["EM-B112X-SSD"].VCPU, ef.Embodied["EM-B112X-SSD"].TotalVCPU)
+	assert.Contains(ef.Coefficient, "nl-ams")
+	assert.Equal("FR", ef.Locations["fr-par"].Country)
+}
+
+func TestThrottled(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+	}{
+		{
+			name:      "too many requests",
+			err:       &scw.ResponseError{StatusCode: http.StatusTooManyRequests},
+			throttled: true,
+		},
+		{
+			name: "unauthorized",
+			err:  &scw.ResponseError{StatusCode: http.StatusUnauthorized},
+		},
+		{
+			name: "other",
+			err:  errors.New("failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := throttled(test.err)
+			assert.ErrorIs(err, test.err)
+			assert.Equal(test.throttled, errors.Is(err, v1.ErrProviderThrottled))
+		})
+	}
+
+	require.NoError(t, throttled(nil))
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"sync"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+var (
+	// The collection intervals of the resource types by provider, the key
+	// is provider/resource
+	granularities = make(map[string]time.Duration)
+
+	granularitiesLock sync.RWMutex
+)
+
+// SetGranularity updates how often the metrics of the resource types of a
+// provider are collected, e.g. network: 1h. The resource types not present
+// in the map are collected at every scrape
+func SetGranularity(provider v1.Provider, intervals map[string]time.Duration) {
+	granularitiesLock.Lock()
+	defer granularitiesLock.Unlock()
+
+	for _, r := range v1.ResourceTypes {
+		delete(granularities, granularityKey(provider, r))
+	}
+	for name, interval := range intervals {
+		if r, ok := v1.ResourceTypes[name]; ok && interval > 0 {
+			granularities[granularityKey(provider, r)] = interval
+		}
+	}
+}
+
+// granularity returns the collection interval of the resource type of the
+// provider, zero when it's collected at every scrape
+func granularity(provider v1.Provider, resource v1.ResourceType) time.Duration {
+	granularitiesLock.RLock()
+	defer granularitiesLock.RUnlock()
+
+	return granularities[granularityKey(provider, resource)]
+}
+
+func granularityKey(provider v1.Provider, resource v1.ResourceType) string {
+	return provider.String() + "/" + resource.String()
+}
+
+// Cadence tracks when the metrics of the resource types collected less often
+// than every scrape, e.g. the network ones every hour, were last collected by
+// a scraper, so that it collects them once their interval elapsed
+type Cadence struct {
+	provider v1.Provider
+
+	// The end of the last window collected, by resource type and scope,
+	// e.g. the region
+	last map[string]time.Time
+
+	mu sync.Mutex
+}
+
+// NewCadence returns the cadence of the scraper of an account of the
+// provider
+func NewCadence(provider v1.Provider) *Cadence {
+	return &Cadence{
+		provider: provider,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Due returns whether the metrics of the resource type in the scope, e.g. a
+// region, are collected at the scrape of the window, and the window they're
+// collected over: the one since their last collection, at most their
+// interval. The metrics are collected at every scrape when their interval
+// isn't longer than the window, and at the first one
+func (c *Cadence) Due(resource v1.ResourceType, scope string, window v1.Window) (v1.Window, bool) {
+	if c == nil {
+		return window, true
+	}
+
+	interval := granularity(c.provider, resource)
+	if interval <= window.Duration() {
+		return window, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := resource.String() + "/" + scope
+	if last, ok := c.last[key]; ok {
+		if window.End.Sub(last) < interval {
+			return v1.Window{}, false
+		}
+
+		// the window before the first collection isn't collected, like
+		// the one of the other metrics, nor the gaps longer than the
+		// interval, e.g. while the circuit was open
+		window.Start = window.End.Add(-min(window.End.Sub(last), interval))
+	}
+
+	c.last[key] = window.End
+	return window, true
+}
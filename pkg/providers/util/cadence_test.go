@@ -0,0 +1,68 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCadence(t *testing.T) {
+	assert := require.New(t)
+
+	SetGranularity(v1.GCP, map[string]time.Duration{
+		"network": time.Hour,
+		// not longer than the scraping interval
+		"storage": time.Minute,
+		"unknown": time.Hour,
+	})
+	defer SetGranularity(v1.GCP, nil)
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	window := func(i int) v1.Window {
+		return v1.NewWindow(end.Add(time.Duration(i)*5*time.Minute), 5*time.Minute)
+	}
+
+	c := NewCadence(v1.GCP)
+
+	// the first scrape collects over its window
+	w, due := c.Due(v1.Network, "demo", window(0))
+	assert.True(due)
+	assert.Equal(window(0), w)
+
+	// then once the interval elapsed, since the last collection
+	for i := 1; i < 12; i++ {
+		_, due = c.Due(v1.Network, "demo", window(i))
+		assert.False(due, i)
+	}
+	w, due = c.Due(v1.Network, "demo", window(12))
+	assert.True(due)
+	assert.Equal(v1.NewWindow(window(12).End, time.Hour), w)
+
+	// the scopes are collected apart
+	w, due = c.Due(v1.Network, "other", window(13))
+	assert.True(due)
+	assert.Equal(window(13), w)
+
+	// the gaps longer than the interval aren't collected
+	w, due = c.Due(v1.Network, "demo", window(48))
+	assert.True(due)
+	assert.Equal(v1.NewWindow(window(48).End, time.Hour), w)
+
+	// the other resource types are collected at every scrape
+	for _, r := range []v1.ResourceType{v1.Storage, v1.CPU} {
+		w, due = c.Due(r, "demo", window(49))
+		assert.True(due)
+		assert.Equal(window(49), w)
+	}
+
+	// and so are all of them without a cadence or a granularity
+	var none *Cadence
+	_, due = none.Due(v1.Network, "demo", window(1))
+	assert.True(due)
+
+	SetGranularity(v1.GCP, nil)
+	_, due = c.Due(v1.Network, "demo", window(50))
+	assert.True(due)
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// ClusterLabel is set on the instances of the managed control planes to the
+// name of their cluster
+const ClusterLabel = "cluster"
+
+// ControlPlane is the equivalent of a managed Kubernetes control plane in
+// instances, the providers don't report the machines it runs on
+type ControlPlane struct {
+	// The service of the control plane, e.g. eks
+	Service string
+
+	// The cluster of the control plane and its region, empty for the
+	// defaults of the provider
+	Cluster string
+	Region  string
+
+	// The instance kind equivalent to a replica, and its vCPUs
+	Kind string
+	VCPU float64
+
+	// The replicas of the control plane, e.g. the API servers and etcd
+	Replicas int
+
+	// The CPU utilization of the replicas in %
+	Usage float64
+}
+
+// ControlPlanes returns the control planes of the clusters with the defaults
+// of the provider for what they don't set. The vCPUs of a kind other than the
+// default one must be set
+func ControlPlanes(defaults ControlPlane, clusters []config.ControlPlaneConfig) ([]ControlPlane, error) {
+	planes := make([]ControlPlane, 0, len(clusters))
+	for _, c := range clusters {
+		if c.Cluster == "" || c.Region == "" {
+			return nil, fmt.Errorf("the cluster and the region of the %s control planes are required", defaults.Service)
+		}
+
+		p := defaults
+		p.Cluster = c.Cluster
+		p.Region = c.Region
+		if c.Kind != "" && c.Kind != defaults.Kind {
+			if c.VCPU <= 0 {
+				return nil, fmt.Errorf("the vCPUs of the kind %s of the control plane of %s are required", c.Kind, c.Cluster)
+			}
+			p.Kind = c.Kind
+			p.VCPU = c.VCPU
+		}
+		if c.Replicas > 0 {
+			p.Replicas = c.Replicas
+		}
+		if c.Usage > 0 {
+			p.Usage = c.Usage
+		}
+
+		planes = append(planes, p)
+	}
+
+	return planes, nil
+}
+
+// Instance returns the control plane of the cluster as an instance of its
+// kind whose host has the vCPUs of all the replicas, so that both its
+// operational and embodied emissions are the ones of the replicas
+func (p *ControlPlane) Instance(provider v1.Provider, window v1.Window) v1.Instance {
+	vCPU := p.VCPU * float64(p.Replicas)
+
+	i := v1.NewInstance(fmt.Sprintf("%s/%s", p.Service, p.Cluster), provider)
+	i.Service = p.Service
+	i.Kind = p.Kind
+	i.Region = p.Region
+	i.HostVCPU = vCPU
+	i.Labels.Add(v1.NameLabel, p.Cluster)
+	i.Labels.Add(ClusterLabel, p.Cluster)
+	i.Metrics.Upsert(&v1.Metric{
+		Name:         v1.CPU.String(),
+		ResourceType: v1.CPU,
+		Usage:        p.Usage,
+		UnitAmount:   vCPU,
+		Unit:         v1.VCPU,
+		UpdatedAt:    window.End.UTC(),
+		Labels:       v1.Labels{},
+	})
+
+	return *i
+}
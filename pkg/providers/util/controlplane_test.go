@@ -0,0 +1,115 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlPlanes(t *testing.T) {
+	assert := require.New(t)
+
+	defaults := ControlPlane{
+		Service:  "eks",
+		Kind:     "m5.large",
+		VCPU:     2,
+		Replicas: 5,
+		Usage:    10,
+	}
+
+	type testcase struct {
+		description string
+		cluster     config.ControlPlaneConfig
+		expected    ControlPlane
+		err         string
+	}
+	tt := []testcase{
+		{
+			description: "the defaults of the provider",
+			cluster:     config.ControlPlaneConfig{Cluster: "prod", Region: "eu-west-1"},
+			expected: ControlPlane{
+				Service:  "eks",
+				Cluster:  "prod",
+				Region:   "eu-west-1",
+				Kind:     "m5.large",
+				VCPU:     2,
+				Replicas: 5,
+				Usage:    10,
+			},
+		},
+		{
+			description: "another kind with its vCPUs",
+			cluster: config.ControlPlaneConfig{
+				Cluster:  "prod",
+				Region:   "eu-west-1",
+				Kind:     "m5.xlarge",
+				VCPU:     4,
+				Replicas: 3,
+				Usage:    25,
+			},
+			expected: ControlPlane{
+				Service:  "eks",
+				Cluster:  "prod",
+				Region:   "eu-west-1",
+				Kind:     "m5.xlarge",
+				VCPU:     4,
+				Replicas: 3,
+				Usage:    25,
+			},
+		},
+		{
+			description: "another kind without its vCPUs",
+			cluster:     config.ControlPlaneConfig{Cluster: "prod", Region: "eu-west-1", Kind: "m5.xlarge"},
+			err:         "the vCPUs of the kind m5.xlarge of the control plane of prod are required",
+		},
+		{
+			description: "no region",
+			cluster:     config.ControlPlaneConfig{Cluster: "prod"},
+			err:         "the cluster and the region of the eks control planes are required",
+		},
+	}
+
+	for _, test := range tt {
+		planes, err := ControlPlanes(defaults, []config.ControlPlaneConfig{test.cluster})
+		if test.err != "" {
+			assert.EqualError(err, test.err, test.description)
+			continue
+		}
+		assert.NoError(err, test.description)
+		assert.Equal([]ControlPlane{test.expected}, planes, test.description)
+	}
+}
+
+func TestControlPlaneInstance(t *testing.T) {
+	assert := require.New(t)
+
+	p := ControlPlane{
+		Service:  "gke",
+		Cluster:  "prod",
+		Region:   "europe-west1",
+		Kind:     "e2-standard-4",
+		VCPU:     4,
+		Replicas: 3,
+		Usage:    10,
+	}
+
+	end := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	i := p.Instance(v1.GCP, v1.NewWindow(end, 5*time.Minute))
+
+	// the host of the instance has the vCPUs of all the replicas
+	assert.Equal("gke/prod", i.Name)
+	assert.Equal("gke", i.Service)
+	assert.Equal("e2-standard-4", i.Kind)
+	assert.Equal("europe-west1", i.Region)
+	assert.Equal(12.0, i.HostVCPU)
+	assert.Equal("prod", i.Labels[ClusterLabel])
+	assert.Equal("prod", i.Labels[v1.NameLabel])
+
+	cpu := i.Metrics[v1.CPU.String()]
+	assert.Equal(10.0, cpu.Usage)
+	assert.Equal(12.0, cpu.UnitAmount)
+	assert.True(end.Equal(cpu.UpdatedAt))
+}
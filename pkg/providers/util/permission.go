@@ -0,0 +1,17 @@
+package util
+
+import v1 "github.com/re-cinq/aether/pkg/types/v1"
+
+// Permission returns the outcome of the check of the permission from the
+// error of the dry-run call of the API needing it
+func Permission(name string, err error) v1.Permission {
+	p := v1.Permission{
+		Name:    name,
+		Granted: err == nil,
+	}
+	if err != nil {
+		p.Error = err.Error()
+	}
+
+	return p
+}
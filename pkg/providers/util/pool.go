@@ -0,0 +1,89 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// defaultWorkers is the default amount of concurrent tasks
+const defaultWorkers = 10
+
+// workerPool bounds the amount of tasks running concurrently across all the
+// scrapers, so that refreshing many regions and accounts at once does not
+// open an unbounded amount of connections
+type workerPool struct {
+	slots chan struct{}
+	mu    sync.RWMutex
+}
+
+// the pool shared by all the scrapers
+var pool = &workerPool{
+	slots: make(chan struct{}, defaultWorkers),
+}
+
+// SetWorkers updates the amount of tasks that can run concurrently
+// Running tasks are not affected
+func SetWorkers(n int) {
+	if n < 1 {
+		n = defaultWorkers
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if cap(pool.slots) != n {
+		pool.slots = make(chan struct{}, n)
+	}
+}
+
+// acquire blocks until a slot is free and returns the function to release it
+func (p *workerPool) acquire(ctx context.Context) (func(), error) {
+	p.mu.RLock()
+	slots := p.slots
+	p.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	}
+}
+
+// ForEach runs the function for every item concurrently, bounded by the
+// shared worker pool, and waits for all of them to complete.
+// The errors of all the items are joined
+func ForEach[T any](ctx context.Context, items []T, fn func(context.Context, T) error) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+
+	for _, item := range items {
+		release, err := pool.acquire(ctx)
+		if err != nil {
+			mu.Lock()
+			errs = errors.Join(errs, err)
+			mu.Unlock()
+			break
+		}
+
+		wg.Add(1)
+		go func(item T) {
+			defer wg.Done()
+			defer release()
+
+			if err := fn(ctx, item); err != nil {
+				mu.Lock()
+				errs = errors.Join(errs, err)
+				mu.Unlock()
+			}
+		}(item)
+	}
+
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	SetWorkers(2)
+	defer SetWorkers(defaultWorkers)
+
+	var running, maxRunning, done int32
+	items := []int{1, 2, 3, 4, 5, 6}
+
+	err := ForEach(ctx, items, func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&done, 1)
+
+		if i%3 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	assert.Error(err)
+	assert.Equal(int32(len(items)), done)
+	assert.LessOrEqual(maxRunning, int32(2))
+}
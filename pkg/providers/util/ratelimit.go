@@ -0,0 +1,156 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// RateLimiter is a token bucket limiting the amount of requests per second
+// made to an API. The bucket holds up to burst tokens and is refilled at
+// the configured rate. A rate of 0 means no limit
+type RateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// Used to mock the time in the tests
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewRateLimiter returns a full token bucket
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	r := &RateLimiter{now: time.Now}
+	r.SetLimit(rate, burst)
+	return r
+}
+
+// SetLimit updates the rate and the burst of the limiter
+// The burst is at least 1 so that a request can be made at all
+func (r *RateLimiter) SetLimit(rate float64, burst int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	// keep the current tokens if nothing changed
+	if r.rate == rate && r.burst == float64(burst) {
+		return
+	}
+
+	r.rate = rate
+	r.burst = float64(burst)
+	r.tokens = r.burst
+	r.last = r.now()
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (r *RateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rate <= 0 {
+		return 0
+	}
+
+	// refill the bucket with the tokens accumulated since the last call
+	now := r.now()
+	r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+
+	// take the token, going negative means waiting for it to be refilled
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-r.tokens / r.rate * float64(time.Second))
+}
+
+// Wait blocks until a request can be made or the context is canceled
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	delay := r.reserve()
+	if delay == 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var (
+	// The limiter shared by all the API calls of all the providers
+	globalLimiter = NewRateLimiter(0, 1)
+
+	// The limiters of every API family, the key is provider/api
+	limiters = make(map[string]*RateLimiter)
+
+	limitersLock sync.Mutex
+)
+
+// limiter returns the limiter of the API family, creating it if needed
+func limiter(provider v1.Provider, api string) *RateLimiter {
+	limitersLock.Lock()
+	defer limitersLock.Unlock()
+
+	key := fmt.Sprintf("%s/%s", provider, api)
+	l, ok := limiters[key]
+	if !ok {
+		l = NewRateLimiter(0, 1)
+		limiters[key] = l
+	}
+
+	return l
+}
+
+// WaitForAPI blocks until a request to the API family of the provider can be
+// made without exceeding neither its own limit nor the global one
+func WaitForAPI(ctx context.Context, provider v1.Provider, api string) error {
+	if err := globalLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	return limiter(provider, api).Wait(ctx)
+}
+
+// SetGlobalRateLimit updates the limit shared by all the API calls
+func SetGlobalRateLimit(global config.RateLimitConfig) {
+	globalLimiter.SetLimit(global.RequestsPerSecond, global.Burst)
+}
+
+// SetRateLimits updates the limit of the API families of a provider
+// The APIs not present in the map are not limited
+func SetRateLimits(provider v1.Provider, apis map[string]config.RateLimitConfig) {
+	limitersLock.Lock()
+	prefix := fmt.Sprintf("%s/", provider)
+	for key, l := range limiters {
+		if strings.HasPrefix(key, prefix) {
+			if _, ok := apis[strings.TrimPrefix(key, prefix)]; !ok {
+				l.SetLimit(0, 1)
+			}
+		}
+	}
+	limitersLock.Unlock()
+
+	for api, rl := range apis {
+		limiter(provider, api).SetLimit(rl.RequestsPerSecond, rl.Burst)
+	}
+}
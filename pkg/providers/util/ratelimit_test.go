@@ -0,0 +1,63 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRateLimiter(0, 1)
+	r.now = func() time.Time { return now }
+	r.SetLimit(2, 2)
+
+	// the burst can be used right away
+	assert.Equal(time.Duration(0), r.reserve())
+	assert.Equal(time.Duration(0), r.reserve())
+
+	// then we need to wait for the bucket to refill
+	assert.Equal(500*time.Millisecond, r.reserve())
+	assert.Equal(time.Second, r.reserve())
+
+	// after enough time the bucket is full again
+	now = now.Add(10 * time.Second)
+	assert.Equal(time.Duration(0), r.reserve())
+
+	// no limit
+	r.SetLimit(0, 1)
+	for i := 0; i < 100; i++ {
+		assert.Equal(time.Duration(0), r.reserve())
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	assert := require.New(t)
+
+	r := NewRateLimiter(0.001, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	assert.NoError(r.Wait(ctx))
+
+	cancel()
+	assert.ErrorIs(r.Wait(ctx), context.Canceled)
+}
+
+func TestSetRateLimits(t *testing.T) {
+	assert := require.New(t)
+
+	SetRateLimits(v1.AWS, map[string]config.RateLimitConfig{
+		"ec2": {RequestsPerSecond: 5, Burst: 10},
+	})
+	assert.Equal(5.0, limiter(v1.AWS, "ec2").rate)
+
+	// removed limits are reset
+	SetRateLimits(v1.AWS, nil)
+	assert.Equal(0.0, limiter(v1.AWS, "ec2").rate)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// ResolutionLabel is the label of the usage metrics noting the resolution
+// of the samples the provider returned, e.g. 1m or 5m
+const ResolutionLabel = "resolution"
+
+// Sample is the value of a metric over the period of its resolution
+// starting at its time
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Resolution returns the smallest interval between the samples, which is
+// the resolution of the metric unless samples are missing. It's the
+// fallback when there are less than 2 samples
+func Resolution(samples []Sample, fallback time.Duration) time.Duration {
+	var resolution time.Duration
+	for i := 1; i < len(samples); i++ {
+		d := samples[i].Time.Sub(samples[i-1].Time).Abs()
+		if d > 0 && (resolution == 0 || d < resolution) {
+			resolution = d
+		}
+	}
+
+	if resolution == 0 {
+		return fallback
+	}
+	return resolution
+}
+
+// Align returns the average of the samples over the window, every sample
+// is weighted by the part of the window its period covers, so that the
+// usage is the one of the exact window whatever the resolution of the
+// samples. It also returns the window covered by the samples, which is zero
+// when they cover the whole window, and false when none of them overlaps it
+func Align(samples []Sample, resolution time.Duration, window v1.Window) (float64, v1.Window, bool) {
+	var sum float64
+	var covered time.Duration
+	var first, last time.Time
+
+	for _, s := range samples {
+		overlap := window.Intersect(v1.Window{
+			Start: s.Time,
+			End:   s.Time.Add(resolution),
+		})
+
+		d := overlap.Duration()
+		if d <= 0 {
+			continue
+		}
+
+		sum += s.Value * d.Seconds()
+		covered += d
+
+		if first.IsZero() || overlap.Start.Before(first) {
+			first = overlap.Start
+		}
+		if overlap.End.After(last) {
+			last = overlap.End
+		}
+	}
+
+	if covered <= 0 {
+		return 0, v1.Window{}, false
+	}
+
+	usage := sum / covered.Seconds()
+	if covered >= window.Duration() {
+		return usage, v1.Window{}, true
+	}
+
+	return usage, v1.Window{Start: first, End: last}, true
+}
+
+// FormatResolution returns the value of the resolution label, in minutes
+// or in seconds when it isn't a whole number of minutes
+func FormatResolution(resolution time.Duration) string {
+	if resolution%time.Minute == 0 {
+		return fmt.Sprintf("%dm", resolution/time.Minute)
+	}
+	return fmt.Sprintf("%ds", resolution/time.Second)
+}
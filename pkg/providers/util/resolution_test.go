@@ -0,0 +1,104 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolution(t *testing.T) {
+	assert := require.New(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the samples are returned newest first
+	assert.Equal(time.Minute, Resolution([]Sample{
+		{Time: start.Add(3 * time.Minute)},
+		{Time: start.Add(2 * time.Minute)},
+		{Time: start},
+	}, 5*time.Minute))
+
+	assert.Equal(5*time.Minute, Resolution([]Sample{{Time: start}}, 5*time.Minute))
+	assert.Equal(5*time.Minute, Resolution(nil, 5*time.Minute))
+}
+
+func TestAlign(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 2, 30, 0, time.UTC)
+	window := v1.NewWindow(start.Add(5*time.Minute), 5*time.Minute)
+
+	for _, test := range []struct {
+		name       string
+		samples    []Sample
+		resolution time.Duration
+		usage      float64
+		observed   v1.Window
+		ok         bool
+	}{
+		{
+			name: "5m samples straddling the window",
+			samples: []Sample{
+				{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: 10},
+				{Time: time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), Value: 30},
+			},
+			resolution: 5 * time.Minute,
+			usage:      20,
+			ok:         true,
+		},
+		{
+			name: "1m samples, the ones outside of the window are left out",
+			samples: []Sample{
+				{Time: time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC), Value: 100},
+				{Time: time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC), Value: 10},
+				{Time: time.Date(2024, 1, 1, 0, 3, 0, 0, time.UTC), Value: 10},
+				{Time: time.Date(2024, 1, 1, 0, 4, 0, 0, time.UTC), Value: 10},
+				{Time: time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), Value: 10},
+				{Time: time.Date(2024, 1, 1, 0, 6, 0, 0, time.UTC), Value: 10},
+				{Time: time.Date(2024, 1, 1, 0, 7, 0, 0, time.UTC), Value: 10},
+				{Time: time.Date(2024, 1, 1, 0, 8, 0, 0, time.UTC), Value: 100},
+			},
+			resolution: time.Minute,
+			usage:      10,
+			ok:         true,
+		},
+		{
+			name: "launched during the window",
+			samples: []Sample{
+				{Time: time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), Value: 40},
+				{Time: time.Date(2024, 1, 1, 0, 6, 0, 0, time.UTC), Value: 20},
+			},
+			resolution: time.Minute,
+			usage:      30,
+			observed: v1.Window{
+				Start: time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC),
+				End:   time.Date(2024, 1, 1, 0, 7, 0, 0, time.UTC),
+			},
+			ok: true,
+		},
+		{
+			name: "before the window",
+			samples: []Sample{
+				{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: 40},
+			},
+			resolution: time.Minute,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			usage, observed, ok := Align(test.samples, test.resolution, window)
+			assert.Equal(test.ok, ok)
+			assert.InDelta(test.usage, usage, 1e-9)
+			assert.Equal(test.observed, observed)
+		})
+	}
+}
+
+func TestFormatResolution(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("1m", FormatResolution(time.Minute))
+	assert.Equal("5m", FormatResolution(5*time.Minute))
+	assert.Equal("10s", FormatResolution(10*time.Second))
+}
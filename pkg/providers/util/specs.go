@@ -0,0 +1,240 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Spec is the specification of an instance type, the same for the instances
+// of the type in all the accounts and the regions
+type Spec struct {
+	VCPU         float64 `json:"vcpu"`
+	MemoryGB     float64 `json:"memoryGB"`
+	Architecture string  `json:"architecture,omitempty"`
+
+	// When the spec was fetched, it's fetched again once expired
+	Fetched time.Time `json:"fetched"`
+}
+
+// SpecFetcher fetches the specs of the instance types with the API of the
+// provider, the types unknown to it are left out
+type SpecFetcher func(ctx context.Context, kinds []string) (map[string]Spec, error)
+
+// specCache is a read-through cache of the specs of the instance types,
+// shared by all the scrapers and persisted to a file
+type specCache struct {
+	// The specs by provider/kind, the types unknown to the provider have no
+	// vCPUs so that they aren't fetched again until expired
+	specs map[string]Spec
+
+	path       string
+	expiration time.Duration
+
+	// The fetches of a provider are made one at a time, so that the
+	// accounts refreshed concurrently fetch a type once
+	fetching map[v1.Provider]*sync.Mutex
+
+	// Used to mock the time in the tests
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// The specs shared by all the scrapers
+var specs = newSpecCache()
+
+func newSpecCache() *specCache {
+	return &specCache{
+		specs:      make(map[string]Spec),
+		expiration: 7 * 24 * time.Hour,
+		fetching:   make(map[v1.Provider]*sync.Mutex),
+		now:        time.Now,
+	}
+}
+
+// SetSpecs updates the file the specs of the instance types are persisted to
+// and how long they're used, the specs of a new file are loaded
+func SetSpecs(cfg config.SpecsConfig) error {
+	return specs.configure(cfg)
+}
+
+// Specs returns the specs of the instance types of the provider. The types
+// missing from the cache, or expired, are fetched at once and persisted.
+// When the fetch fails, the specs cached are returned with the error, the
+// expired ones included
+func Specs(ctx context.Context, provider v1.Provider, kinds []string, fetch SpecFetcher) (map[string]Spec, error) {
+	return specs.get(ctx, provider, kinds, fetch)
+}
+
+func (c *specCache) configure(cfg config.SpecsConfig) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cfg.Expiration > 0 {
+		c.expiration = cfg.Expiration
+	}
+
+	if cfg.Path == c.path {
+		return nil
+	}
+	c.path = cfg.Path
+	if c.path == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed reading the instance type specs: %w", err)
+	}
+
+	var loaded map[string]Spec
+	if err := json.Unmarshal(b, &loaded); err != nil {
+		return fmt.Errorf("failed reading the instance type specs: %w", err)
+	}
+
+	// the specs fetched since the start are more recent
+	for key, spec := range loaded {
+		if cached, ok := c.specs[key]; !ok || cached.Fetched.Before(spec.Fetched) {
+			c.specs[key] = spec
+		}
+	}
+
+	return nil
+}
+
+func (c *specCache) get(ctx context.Context, provider v1.Provider, kinds []string, fetch SpecFetcher) (map[string]Spec, error) {
+	result, missing := c.lookup(provider, kinds)
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	lock := c.fetchLock(provider)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// another account may have fetched them in the meantime
+	_, missing = c.lookup(provider, missing)
+	if len(missing) == 0 {
+		return c.known(provider, kinds), nil
+	}
+
+	fetched, err := fetch(ctx, missing)
+	if err != nil {
+		return c.stale(provider, kinds), err
+	}
+
+	now := c.now()
+
+	c.mu.Lock()
+	for _, kind := range missing {
+		spec := fetched[kind]
+		spec.Fetched = now
+		c.specs[specKey(provider, kind)] = spec
+	}
+	err = c.save()
+	c.mu.Unlock()
+
+	// the specs are fetched again on restart when they can't be persisted
+	if err != nil {
+		slog.Warn("failed persisting the instance type specs", "error", err)
+	}
+
+	return c.known(provider, kinds), nil
+}
+
+// lookup returns the specs of the types which are cached and the types
+// missing or expired
+func (c *specCache) lookup(provider v1.Provider, kinds []string) (map[string]Spec, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]Spec, len(kinds))
+	var missing []string
+	for _, kind := range kinds {
+		spec, ok := c.specs[specKey(provider, kind)]
+		if !ok || c.now().Sub(spec.Fetched) > c.expiration {
+			missing = append(missing, kind)
+			continue
+		}
+		if spec.VCPU > 0 {
+			result[kind] = spec
+		}
+	}
+
+	return result, missing
+}
+
+// known returns the cached specs of the types known to the provider
+func (c *specCache) known(provider v1.Provider, kinds []string) map[string]Spec {
+	result, _ := c.lookup(provider, kinds)
+	return result
+}
+
+// stale returns the cached specs of the types, the expired ones included
+func (c *specCache) stale(provider v1.Provider, kinds []string) map[string]Spec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]Spec, len(kinds))
+	for _, kind := range kinds {
+		if spec, ok := c.specs[specKey(provider, kind)]; ok && spec.VCPU > 0 {
+			result[kind] = spec
+		}
+	}
+	return result
+}
+
+// fetchLock returns the lock of the fetches of the provider
+func (c *specCache) fetchLock(provider v1.Provider) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lock, ok := c.fetching[provider]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.fetching[provider] = lock
+	}
+	return lock
+}
+
+// save writes the specs to the file, replacing it at once so that a partial
+// write isn't loaded. The lock must be held
+func (c *specCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(c.specs)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, c.path)
+}
+
+// specKey returns the cache key of the spec of an instance type
+func specKey(provider v1.Provider, kind string) string {
+	return fmt.Sprintf("%s/%s", provider, kind)
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecs(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "aether", "specs.json")
+
+	c := newSpecCache()
+	c.now = func() time.Time { return now }
+	assert.NoError(c.configure(config.SpecsConfig{Path: path, Expiration: time.Hour}))
+
+	var fetched [][]string
+	fetch := func(ctx context.Context, kinds []string) (map[string]Spec, error) {
+		fetched = append(fetched, kinds)
+		return map[string]Spec{
+			"m5.large": {VCPU: 2, MemoryGB: 8, Architecture: "x86_64"},
+		}, nil
+	}
+
+	// the types unknown to the provider are left out
+	specs, err := c.get(ctx, v1.AWS, []string{"m5.large", "retired.large"}, fetch)
+	assert.NoError(err)
+	assert.Equal(map[string]Spec{
+		"m5.large": {VCPU: 2, MemoryGB: 8, Architecture: "x86_64", Fetched: now},
+	}, specs)
+
+	// the types are fetched once, the unknown ones too
+	specs, err = c.get(ctx, v1.AWS, []string{"m5.large", "retired.large"}, fetch)
+	assert.NoError(err)
+	assert.Len(specs, 1)
+	assert.Equal([][]string{{"m5.large", "retired.large"}}, fetched)
+
+	// only the missing types are fetched, the providers don't share them
+	_, err = c.get(ctx, v1.AWS, []string{"m5.large", "c5.large"}, fetch)
+	assert.NoError(err)
+	_, err = c.get(ctx, v1.GCP, []string{"m5.large"}, fetch)
+	assert.NoError(err)
+	assert.Equal([][]string{{"m5.large", "retired.large"}, {"c5.large"}, {"m5.large"}}, fetched)
+
+	// the specs are loaded from the file on restart
+	restarted := newSpecCache()
+	restarted.now = c.now
+	assert.NoError(restarted.configure(config.SpecsConfig{Path: path, Expiration: time.Hour}))
+	specs, err = restarted.get(ctx, v1.AWS, []string{"m5.large"}, fetch)
+	assert.NoError(err)
+	assert.Equal(2.0, specs["m5.large"].VCPU)
+	assert.Len(fetched, 3)
+
+	// the expired specs are used when they can't be fetched again
+	now = now.Add(2 * time.Hour)
+	specs, err = c.get(ctx, v1.AWS, []string{"m5.large"}, func(ctx context.Context, kinds []string) (map[string]Spec, error) {
+		return nil, errors.New("unauthorized")
+	})
+	assert.ErrorContains(err, "unauthorized")
+	assert.Equal(2.0, specs["m5.large"].VCPU)
+
+	// and fetched again otherwise
+	_, err = c.get(ctx, v1.AWS, []string{"m5.large"}, fetch)
+	assert.NoError(err)
+	assert.Equal([]string{"m5.large"}, fetched[3])
+}
+
+func TestSpecsConfigure(t *testing.T) {
+	assert := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "specs.json")
+	assert.NoError(os.WriteFile(path, []byte("{"), 0o644))
+
+	c := newSpecCache()
+	assert.ErrorContains(c.configure(config.SpecsConfig{Path: path}), "failed reading the instance type specs")
+
+	// a missing file is created on the first fetch
+	assert.NoError(c.configure(config.SpecsConfig{Path: filepath.Join(t.TempDir(), "specs.json")}))
+
+	// the specs are only kept in memory without a path
+	assert.NoError(c.configure(config.SpecsConfig{}))
+	assert.Equal(7*24*time.Hour, c.expiration)
+}
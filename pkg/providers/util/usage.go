@@ -0,0 +1,187 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The window the quota usage is measured over, the quotas are per minute
+const quotaWindow = time.Minute
+
+var (
+	apiRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cloud_api_requests_total",
+			Help: "The requests made to the provider APIs, by operation and the code of their error, ok when they succeeded",
+		},
+		[]string{"provider", "api", "operation", "code"},
+	)
+
+	apiBillableUnits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cloud_api_billable_units_total",
+			Help: "The units the requests made to the provider APIs are billed by, e.g. the metrics returned by CloudWatch GetMetricData",
+		},
+		[]string{"provider", "api", "operation"},
+	)
+
+	// The usage of the quotas shared by all the scrapers
+	quotas = &quotaCollector{
+		defaults:  make(map[string]float64),
+		overrides: make(map[string]float64),
+		requests:  make(map[string][]time.Time),
+		now:       time.Now,
+	}
+)
+
+func init() {
+	prometheus.MustRegister(apiRequests, apiBillableUnits, quotas)
+}
+
+// RecordAPICall counts a request made to an operation of the API family of
+// the provider, along with the code of its error
+func RecordAPICall(provider v1.Provider, api, operation string, err error) {
+	code := "ok"
+	if err != nil {
+		code = string(v1.Code(err))
+	}
+	apiRequests.WithLabelValues(provider.String(), api, operation, code).Inc()
+	quotas.record(apiKey(provider, api))
+}
+
+// RecordAPIUnits counts the billable units of a request, for the operations
+// which aren't billed by request
+func RecordAPIUnits(provider v1.Provider, api, operation string, units int) {
+	apiBillableUnits.WithLabelValues(provider.String(), api, operation).Add(float64(units))
+}
+
+// SetDefaultQuota sets the documented default quota of the requests per
+// minute of the API family of the provider
+func SetDefaultQuota(provider v1.Provider, api string, perMinute float64) {
+	quotas.mu.Lock()
+	defer quotas.mu.Unlock()
+
+	quotas.defaults[apiKey(provider, api)] = perMinute
+}
+
+// SetQuotas updates the quotas of the requests per minute of the API
+// families of a provider, the ones not in the map use their default
+func SetQuotas(provider v1.Provider, apis map[string]float64) {
+	quotas.mu.Lock()
+	defer quotas.mu.Unlock()
+
+	prefix := fmt.Sprintf("%s/", provider)
+	for key := range quotas.overrides {
+		if strings.HasPrefix(key, prefix) {
+			delete(quotas.overrides, key)
+		}
+	}
+
+	for api, perMinute := range apis {
+		quotas.overrides[apiKey(provider, api)] = perMinute
+	}
+}
+
+// apiKey identifies the API family of a provider
+func apiKey(provider v1.Provider, api string) string {
+	return fmt.Sprintf("%s/%s", provider, api)
+}
+
+// quotaCollector exports the requests made to every API family over the
+// last minute relative to their quota. The quotas apply per account or
+// project while the requests of all the accounts are counted, so the usage
+// is an upper bound of the one of each account
+type quotaCollector struct {
+	// The quotas per minute, the key is provider/api
+	defaults  map[string]float64
+	overrides map[string]float64
+
+	// The requests made over the last minute, the oldest first
+	requests map[string][]time.Time
+
+	mu sync.Mutex
+
+	// Used to mock the time in the tests
+	now func() time.Time
+}
+
+var quotaUsageDesc = prometheus.NewDesc(
+	"cloud_api_quota_usage_ratio",
+	"The requests made to the provider APIs over the last minute relative to their quota per minute",
+	[]string{"provider", "api"},
+	nil,
+)
+
+// record adds a request made to the API family
+func (c *quotaCollector) record(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.requests[key] = append(c.expire(key, now), now)
+}
+
+// expire drops the requests older than the quota window
+func (c *quotaCollector) expire(key string, now time.Time) []time.Time {
+	requests := c.requests[key]
+
+	i := 0
+	for i < len(requests) && now.Sub(requests[i]) >= quotaWindow {
+		i++
+	}
+
+	return requests[i:]
+}
+
+// usage returns the ratio of the quota of the API family used over the
+// last minute, false when its quota is unknown
+func (c *quotaCollector) usage(key string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	quota, ok := c.overrides[key]
+	if !ok {
+		quota, ok = c.defaults[key]
+	}
+	if !ok || quota <= 0 {
+		return 0, false
+	}
+
+	requests := c.expire(key, c.now())
+	c.requests[key] = requests
+
+	return float64(len(requests)) / quota, true
+}
+
+// Describe sends the descriptor of the quota usage
+func (c *quotaCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- quotaUsageDesc
+}
+
+// Collect sends the usage of the quota of every API family with a quota
+func (c *quotaCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	keys := make(map[string]bool, len(c.defaults)+len(c.overrides))
+	for key := range c.defaults {
+		keys[key] = true
+	}
+	for key := range c.overrides {
+		keys[key] = true
+	}
+	c.mu.Unlock()
+
+	for key := range keys {
+		ratio, ok := c.usage(key)
+		if !ok {
+			continue
+		}
+
+		provider, api, _ := strings.Cut(key, "/")
+		ch <- prometheus.MustNewConstMetric(quotaUsageDesc, prometheus.GaugeValue, ratio, provider, api)
+	}
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAPICall(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	quotas.now = func() time.Time { return now }
+	defer func() { quotas.now = time.Now }()
+
+	const provider = v1.Provider("test")
+	SetDefaultQuota(provider, "compute", 4)
+
+	RecordAPICall(provider, "compute", "List", nil)
+	RecordAPICall(provider, "compute", "List", errors.New("unauthorized"))
+	RecordAPICall(provider, "compute", "List", v1.ErrProviderThrottled)
+	RecordAPIUnits(provider, "compute", "List", 10)
+
+	assert.Equal(1.0, testutil.ToFloat64(apiRequests.WithLabelValues("test", "compute", "List", "ok")))
+	assert.Equal(1.0, testutil.ToFloat64(apiRequests.WithLabelValues("test", "compute", "List", "internal")))
+	assert.Equal(1.0, testutil.ToFloat64(apiRequests.WithLabelValues("test", "compute", "List", "provider_throttled")))
+	assert.Equal(10.0, testutil.ToFloat64(apiBillableUnits.WithLabelValues("test", "compute", "List")))
+
+	// the requests of the last minute over the quota
+	ratio, ok := quotas.usage(apiKey(provider, "compute"))
+	assert.True(ok)
+	assert.Equal(0.75, ratio)
+
+	// the configured quota overrides the default one
+	SetQuotas(provider, map[string]float64{"compute": 6})
+	ratio, _ = quotas.usage(apiKey(provider, "compute"))
+	assert.Equal(0.5, ratio)
+
+	SetQuotas(provider, nil)
+	ratio, _ = quotas.usage(apiKey(provider, "compute"))
+	assert.Equal(0.75, ratio)
+
+	// the requests expire after a minute
+	now = now.Add(time.Minute)
+	RecordAPICall(provider, "compute", "List", nil)
+	ratio, _ = quotas.usage(apiKey(provider, "compute"))
+	assert.Equal(0.25, ratio)
+
+	// the APIs without a quota have no usage
+	RecordAPICall(provider, "storage", "Get", nil)
+	_, ok = quotas.usage(apiKey(provider, "storage"))
+	assert.False(ok)
+
+	assert.NoError(testutil.CollectAndCompare(quotas, strings.NewReader(`
+# HELP cloud_api_quota_usage_ratio The requests made to the provider APIs over the last minute relative to their quota per minute
+# TYPE cloud_api_quota_usage_ratio gauge
+cloud_api_quota_usage_ratio{api="compute",provider="test"} 0.25
+`), "cloud_api_quota_usage_ratio"))
+}
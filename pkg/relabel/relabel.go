@@ -0,0 +1,197 @@
+// Package relabel rewrites label sets with rules that follow the semantics
+// of the Prometheus relabel_config
+package relabel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The supported actions
+const (
+	Replace   = "replace"
+	Keep      = "keep"
+	Drop      = "drop"
+	LabelMap  = "labelmap"
+	LabelDrop = "labeldrop"
+	LabelKeep = "labelkeep"
+)
+
+// The defaults of the rules, the same as Prometheus
+const (
+	defaultSeparator   = ";"
+	defaultRegex       = "(.*)"
+	defaultReplacement = "$1"
+)
+
+// labelName matches the valid Prometheus label names
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// rule is a validated relabel config
+type rule struct {
+	sourceLabels []string
+	separator    string
+	regex        *regexp.Regexp
+	targetLabel  string
+	replacement  string
+	action       string
+}
+
+// Rules are applied to the label sets in order
+type Rules []rule
+
+// New validates the relabel configs
+func New(configs []config.RelabelConfig) (Rules, error) {
+	rules := make(Rules, 0, len(configs))
+
+	for i := range configs {
+		c := &configs[i]
+		r := rule{
+			sourceLabels: c.SourceLabels,
+			separator:    c.Separator,
+			targetLabel:  c.TargetLabel,
+			replacement:  c.Replacement,
+			action:       strings.ToLower(c.Action),
+		}
+
+		if r.separator == "" {
+			r.separator = defaultSeparator
+		}
+		if r.replacement == "" {
+			r.replacement = defaultReplacement
+		}
+		if r.action == "" {
+			r.action = Replace
+		}
+
+		expr := c.Regex
+		if expr == "" {
+			expr = defaultRegex
+		}
+
+		// the regex is anchored on both ends
+		var err error
+		r.regex, err = regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("relabel config %d: invalid regex: %w", i, err)
+		}
+
+		switch r.action {
+		case Replace:
+			if r.targetLabel == "" {
+				return nil, fmt.Errorf("relabel config %d: the %s action requires a target label", i, r.action)
+			}
+		case Keep, Drop:
+			if len(r.sourceLabels) == 0 {
+				return nil, fmt.Errorf("relabel config %d: the %s action requires source labels", i, r.action)
+			}
+		case LabelMap, LabelDrop, LabelKeep:
+		default:
+			return nil, fmt.Errorf("relabel config %d: unknown action %q", i, c.Action)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// Process applies the rules to a copy of the labels
+// It returns false when the label set is dropped by a keep or drop rule
+func (rules Rules) Process(labels v1.Labels) (v1.Labels, bool) {
+	out := make(v1.Labels, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+
+	for i := range rules {
+		if !rules[i].apply(out) {
+			return nil, false
+		}
+	}
+
+	return out, true
+}
+
+// apply runs the rule on the labels, returns false if they are dropped
+func (r *rule) apply(labels v1.Labels) bool {
+	values := make([]string, len(r.sourceLabels))
+	for i, l := range r.sourceLabels {
+		values[i] = labels[l]
+	}
+	value := strings.Join(values, r.separator)
+
+	switch r.action {
+	case Keep:
+		return r.regex.MatchString(value)
+	case Drop:
+		return !r.regex.MatchString(value)
+	case Replace:
+		match := r.regex.FindStringSubmatchIndex(value)
+		if match == nil {
+			return true
+		}
+
+		target := string(r.regex.ExpandString(nil, r.targetLabel, value, match))
+		if !labelName.MatchString(target) {
+			return true
+		}
+
+		replacement := string(r.regex.ExpandString(nil, r.replacement, value, match))
+		if replacement == "" {
+			labels.Delete(target)
+			return true
+		}
+		labels.Add(target, replacement)
+	case LabelMap:
+		// the mapped labels are added after matching all the names, so that
+		// they are not matched again
+		mapped := make(v1.Labels)
+		for name, v := range labels {
+			match := r.regex.FindStringSubmatchIndex(name)
+			if match == nil {
+				continue
+			}
+			mapped[string(r.regex.ExpandString(nil, r.replacement, name, match))] = v
+		}
+		for name, v := range mapped {
+			labels.Add(name, v)
+		}
+	case LabelDrop:
+		for name := range labels {
+			if r.regex.MatchString(name) {
+				labels.Delete(name)
+			}
+		}
+	case LabelKeep:
+		for name := range labels {
+			if !r.regex.MatchString(name) {
+				labels.Delete(name)
+			}
+		}
+	}
+
+	return true
+}
+
+// LabelName converts a Kubernetes label or annotation key to a valid label
+// name, e.g. app.kubernetes.io/name becomes app_kubernetes_io_name
+func LabelName(key string) string {
+	b := []byte(key)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+
+	// label names can't start with a digit
+	if len(b) > 0 && b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+
+	return string(b)
+}
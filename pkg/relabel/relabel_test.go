@@ -0,0 +1,127 @@
+package relabel
+
+import (
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcess(t *testing.T) {
+	labels := v1.Labels{
+		"namespace":             "shop",
+		"pod":                   "web-1",
+		"label_team":            "checkout",
+		"label_app":             "web",
+		"annotation_costcenter": "cc-42",
+	}
+
+	tests := []struct {
+		name     string
+		configs  []config.RelabelConfig
+		expected v1.Labels
+	}{
+		{
+			name: "replace",
+			configs: []config.RelabelConfig{
+				{SourceLabels: []string{"label_team"}, TargetLabel: "team"},
+				{SourceLabels: []string{"namespace", "label_app"}, Regex: "(.+);(.+)", TargetLabel: "workload", Replacement: "$1/$2"},
+			},
+			expected: v1.Labels{
+				"namespace":             "shop",
+				"pod":                   "web-1",
+				"label_team":            "checkout",
+				"label_app":             "web",
+				"annotation_costcenter": "cc-42",
+				"team":                  "checkout",
+				"workload":              "shop/web",
+			},
+		},
+		{
+			name: "labelmap and labeldrop",
+			configs: []config.RelabelConfig{
+				{Action: "labelmap", Regex: "label_(.+)"},
+				{Action: "labeldrop", Regex: "label_.+|annotation_.+"},
+			},
+			expected: v1.Labels{
+				"namespace": "shop",
+				"pod":       "web-1",
+				"team":      "checkout",
+				"app":       "web",
+			},
+		},
+		{
+			name: "labelkeep",
+			configs: []config.RelabelConfig{
+				{Action: "labelkeep", Regex: "namespace|pod"},
+			},
+			expected: v1.Labels{
+				"namespace": "shop",
+				"pod":       "web-1",
+			},
+		},
+		{
+			name: "replacing with an empty value deletes the label",
+			configs: []config.RelabelConfig{
+				{SourceLabels: []string{"missing"}, TargetLabel: "label_app"},
+			},
+			expected: v1.Labels{
+				"namespace":             "shop",
+				"pod":                   "web-1",
+				"label_team":            "checkout",
+				"annotation_costcenter": "cc-42",
+			},
+		},
+		{
+			name: "keep",
+			configs: []config.RelabelConfig{
+				{Action: "keep", SourceLabels: []string{"namespace"}, Regex: "shop|payments"},
+				{Action: "labelkeep", Regex: "namespace"},
+			},
+			expected: v1.Labels{"namespace": "shop"},
+		},
+		{
+			name: "drop",
+			configs: []config.RelabelConfig{
+				{Action: "drop", SourceLabels: []string{"label_team"}, Regex: "check.*"},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			rules, err := New(test.configs)
+			assert.NoError(err)
+
+			out, ok := rules.Process(labels)
+			assert.Equal(test.expected != nil, ok)
+			assert.Equal(test.expected, out)
+
+			// the input is not modified
+			assert.Len(labels, 5)
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, c := range []config.RelabelConfig{
+		{Regex: "(", TargetLabel: "a"},
+		{Action: "hashmod"},
+		{Action: "replace"},
+		{Action: "keep"},
+	} {
+		_, err := New([]config.RelabelConfig{c})
+		require.Error(t, err, c)
+	}
+}
+
+func TestLabelName(t *testing.T) {
+	assert := require.New(t)
+	assert.Equal("app_kubernetes_io_name", LabelName("app.kubernetes.io/name"))
+	assert.Equal("_1st", LabelName("1st"))
+	assert.Equal("cost_center", LabelName("cost-center"))
+}
@@ -0,0 +1,222 @@
+// Package replay records the instances collected by the scrapers and replays
+// them, so that the emissions of a recording are calculated again, through
+// the same calculations, without the cloud credentials. It's used by the
+// integration tests, the demos and to reproduce the calculations reported
+// by the users.
+//
+// A recording is a JSON Lines file, every line is the record of an instance
+// as collected by a scraper, before its emissions are calculated.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The maximum size of a record
+const maxRecordSize = 1 << 20
+
+// Record is an instance as collected by a scraper
+type Record struct {
+	// The scrape interval the emissions of the instance are calculated over
+	Interval Duration `json:"interval"`
+
+	// The version of the emission factors used when recording
+	Factors string `json:"factors,omitempty"`
+
+	// The instance, the providers and the units are plain strings so that
+	// the recordings of the plugin providers can be read too
+	Provider     string            `json:"provider"`
+	Service      string            `json:"service,omitempty"`
+	Name         string            `json:"name"`
+	Region       string            `json:"region"`
+	Zone         string            `json:"zone,omitempty"`
+	Kind         string            `json:"kind"`
+	Architecture string            `json:"architecture,omitempty"`
+	CPUPlatform  string            `json:"cpuPlatform,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Metrics      []Metric          `json:"metrics"`
+}
+
+// Metric is the usage of a resource of a recorded instance
+type Metric struct {
+	Name         string            `json:"name"`
+	ResourceType string            `json:"resourceType"`
+	Usage        float64           `json:"usage"`
+	UnitAmount   float64           `json:"unitAmount"`
+	Unit         string            `json:"unit,omitempty"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// Duration is a time.Duration written as a string, e.g. 5m0s
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// NewRecord returns the record of an instance collected by a scraper, whose
+// emissions are calculated over the interval with the factors version
+func NewRecord(i *v1.Instance, interval time.Duration, factors string) Record {
+	r := Record{
+		Interval: Duration(interval),
+		Factors:  factors,
+		Provider: i.Provider.String(),
+		Service:  i.Service,
+		Name:     i.Name,
+		Region:   i.Region,
+		Zone:     i.Zone,
+		Kind:     i.Kind,
+		Labels:   i.Labels,
+		Metrics:  make([]Metric, 0, len(i.Metrics)),
+
+		Architecture: i.Architecture,
+		CPUPlatform:  i.CPUPlatform,
+	}
+
+	for _, m := range i.Metrics {
+		r.Metrics = append(r.Metrics, Metric{
+			Name:         m.Name,
+			ResourceType: m.ResourceType.String(),
+			Usage:        m.Usage,
+			UnitAmount:   m.UnitAmount,
+			Unit:         m.Unit.String(),
+			UpdatedAt:    m.UpdatedAt,
+			Labels:       m.Labels,
+		})
+	}
+
+	// the same instances are written the same way
+	sort.Slice(r.Metrics, func(a, b int) bool {
+		return r.Metrics[a].Name < r.Metrics[b].Name
+	})
+
+	return r
+}
+
+// Instance returns the instance as collected by the scraper, without its
+// emissions
+func (r *Record) Instance() *v1.Instance {
+	i := &v1.Instance{
+		Provider: v1.Provider(r.Provider),
+		Service:  r.Service,
+		Name:     r.Name,
+		Region:   r.Region,
+		Zone:     r.Zone,
+		Kind:     r.Kind,
+		Metrics:  v1.Metrics{},
+		Labels:   v1.Labels{},
+
+		Architecture: r.Architecture,
+		CPUPlatform:  r.CPUPlatform,
+	}
+
+	for k, v := range r.Labels {
+		i.Labels[k] = v
+	}
+
+	for _, m := range r.Metrics {
+		metric := &v1.Metric{
+			Name:         m.Name,
+			ResourceType: v1.ResourceType(m.ResourceType),
+			Usage:        m.Usage,
+			UnitAmount:   m.UnitAmount,
+			Unit:         v1.ResourceUnit(m.Unit),
+			UpdatedAt:    m.UpdatedAt,
+			Labels:       v1.Labels{},
+		}
+		for k, v := range m.Labels {
+			metric.Labels[k] = v
+		}
+		i.Metrics.Upsert(metric)
+	}
+
+	return i
+}
+
+// Time returns when the instance was collected, the end of the scrape window
+func (r *Record) Time() time.Time {
+	var t time.Time
+	for _, m := range r.Metrics {
+		if m.UpdatedAt.After(t) {
+			t = m.UpdatedAt
+		}
+	}
+
+	return t
+}
+
+// Read returns the records of a recording
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if record.Provider == "" || record.Name == "" {
+			return nil, fmt.Errorf("line %d: the records need a provider and a name", line)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Load returns the records of a recording file, or of all the objects under
+// an object storage prefix, e.g. s3://bucket/prefix or gs://bucket/prefix, in
+// the order of their keys
+func Load(ctx context.Context, path string) ([]Record, error) {
+	b, prefix, ok, err := openBucket(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return readBucket(ctx, b, prefix)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
@@ -0,0 +1,116 @@
+package replay
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/calculator"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// dataset is the emission factors version of the recordings
+type dataset string
+
+func (d dataset) Dataset() calculator.Dataset {
+	return calculator.Dataset{Version: string(d)}
+}
+
+func TestRecorder(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "recordings", "recording.jsonl")
+	r, err := NewRecorder(ctx, path, 5*time.Minute, dataset("v1.2.0"))
+	assert.NoError(err)
+
+	updated := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	i := v1.NewInstance("vm-1", v1.GCP)
+	i.Region = "europe-west1"
+	i.Zone = "europe-west1-b"
+	i.Kind = "n2-standard-2"
+	i.Labels.Add(v1.AccountLabel, "prod")
+	for _, m := range []v1.Metric{
+		{Name: "memory", ResourceType: v1.Memory, Usage: 20, UnitAmount: 8, Unit: v1.GB, UpdatedAt: updated},
+		{Name: "cpu", ResourceType: v1.CPU, Usage: 42, UnitAmount: 2, Unit: v1.VCPU, UpdatedAt: updated},
+	} {
+		i.Metrics.Upsert(&m)
+	}
+
+	// the calculated emissions aren't recorded
+	calculated := *i
+	calculated.EmbodiedEmissions = v1.NewResourceEmission(1, v1.GCO2eqkWh)
+
+	r.Handle(ctx, &bus.Event{Type: v1.MetricsCollectedEvent, Data: calculated})
+	r.Handle(ctx, &bus.Event{Type: v1.EmissionsCalculatedEvent, Data: calculated})
+	r.Stop(ctx)
+	r.Stop(ctx)
+	r.Handle(ctx, &bus.Event{Type: v1.MetricsCollectedEvent, Data: calculated})
+
+	records, err := Load(ctx, path)
+	assert.NoError(err)
+	assert.Len(records, 1)
+
+	record := records[0]
+	assert.Equal(Duration(5*time.Minute), record.Interval)
+	assert.Equal("v1.2.0", record.Factors)
+	assert.Equal([]string{"cpu", "memory"}, []string{record.Metrics[0].Name, record.Metrics[1].Name})
+	assert.True(updated.Equal(record.Time()))
+
+	// the instance is replayed as collected
+	replayed := record.Instance()
+	assert.Equal(i.Provider, replayed.Provider)
+	assert.Equal(i.Zone, replayed.Zone)
+	assert.Equal(i.Kind, replayed.Kind)
+	assert.Equal(i.Labels, replayed.Labels)
+	assert.Len(replayed.Metrics, 2)
+	assert.Equal(v1.VCPU, replayed.Metrics["cpu"].Unit)
+	assert.Equal(v1.Memory, replayed.Metrics["memory"].ResourceType)
+	assert.Equal(42.0, replayed.Metrics["cpu"].Usage)
+	assert.Zero(replayed.EmbodiedEmissions.Value)
+
+	// appended to the recording
+	r, err = NewRecorder(ctx, path, 5*time.Minute, nil)
+	assert.NoError(err)
+	r.Handle(ctx, &bus.Event{Type: v1.MetricsCollectedEvent, Data: *i})
+	r.Stop(ctx)
+
+	records, err = Load(ctx, path)
+	assert.NoError(err)
+	assert.Len(records, 2)
+	assert.Empty(records[1].Factors)
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		len  int
+		err  string
+	}{
+		{name: "empty", data: ""},
+		{name: "blank lines", data: "\n{\"interval\":\"1m0s\",\"provider\":\"aws\",\"name\":\"i-1\",\"metrics\":[]}\n\n", len: 1},
+		{name: "invalid json", data: "{\"provider\":", err: "line 1: unexpected end of JSON input"},
+		{name: "invalid interval", data: "{\"interval\":\"5\",\"provider\":\"aws\",\"name\":\"i-1\"}", err: `line 1: time: missing unit in duration "5"`},
+		{name: "missing name", data: "{\"interval\":\"1m0s\",\"provider\":\"aws\"}", err: "line 1: the records need a provider and a name"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			records, err := Read(strings.NewReader(test.data))
+			if test.err != "" {
+				assert.EqualError(err, test.err)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Len(records, test.len)
+		})
+	}
+}
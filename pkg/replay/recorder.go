@@ -0,0 +1,186 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// datasetReader returns the emission factors in use
+type datasetReader interface {
+	Dataset() calculator.Dataset
+}
+
+// Recorder appends the instances collected by the scrapers to a recording,
+// it's subscribed to the v1.MetricsCollectedEvent before the calculator so
+// that the instances are recorded as collected
+type Recorder struct {
+	// The recording file, or the records buffered until they're uploaded
+	// to the object storage
+	out io.Writer
+	enc *json.Encoder
+
+	// The object storage the records are uploaded to every interval, nil
+	// when the recording is a file
+	bucket bucket
+	prefix string
+	buf    bytes.Buffer
+
+	// Used to stop uploading the records and wait for the last upload
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	interval time.Duration
+	dataset  datasetReader
+	closed   bool
+	mu       sync.Mutex
+
+	logger *slog.Logger
+}
+
+// NewRecorder opens the recording, the instances are appended to it. When
+// the path is the URL of an object storage prefix, e.g. s3://bucket/prefix or
+// gs://bucket/prefix, the instances of every interval are uploaded as a new
+// object under the prefix
+func NewRecorder(ctx context.Context, path string, interval time.Duration, dataset datasetReader) (*Recorder, error) {
+	r := &Recorder{
+		interval: interval,
+		dataset:  dataset,
+		logger:   log.FromContext(ctx),
+	}
+
+	b, prefix, ok, err := openBucket(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		r.bucket, r.prefix = b, prefix
+		r.out = &r.buf
+		r.enc = json.NewEncoder(r.out)
+		r.start(ctx)
+		return r, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening the recording: %w", err)
+	}
+	r.out = f
+	r.enc = json.NewEncoder(f)
+
+	return r, nil
+}
+
+// start uploads the buffered records every interval, until the recorder is
+// stopped
+func (r *Recorder) start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.upload(ctx)
+			}
+		}
+	}()
+}
+
+// upload writes the buffered records as a new object, named after the time
+// and the host so that the objects of the replicas don't collide and are
+// listed in order
+func (r *Recorder) upload(ctx context.Context) {
+	r.mu.Lock()
+	if r.buf.Len() == 0 {
+		r.mu.Unlock()
+		return
+	}
+	data := bytes.Clone(r.buf.Bytes())
+	r.buf.Reset()
+	r.mu.Unlock()
+
+	host, _ := os.Hostname()
+	key := objectKey(r.prefix, fmt.Sprintf("%s-%s.jsonl", time.Now().UTC().Format("20060102T150405.000000000Z"), host))
+
+	if err := r.bucket.Put(ctx, key, data); err != nil {
+		r.logger.Error("failed uploading the recording", "key", key, "error", err)
+	}
+}
+
+// Handle records the instances of the v1.MetricsCollectedEvent
+func (r *Recorder) Handle(ctx context.Context, e *bus.Event) {
+	if e.Type != v1.MetricsCollectedEvent {
+		return
+	}
+
+	instance, ok := e.Data.(v1.Instance)
+	if !ok {
+		r.logger.Error("recorder got an unknown event", "event", e)
+		return
+	}
+
+	var version string
+	if r.dataset != nil {
+		version = r.dataset.Dataset().Version
+	}
+	record := NewRecord(&instance, r.interval, version)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	if err := r.enc.Encode(record); err != nil {
+		r.logger.Error("failed recording the instance", "instance", instance.Name, "error", err)
+	}
+}
+
+// Stop closes the recording, the records not uploaded yet are uploaded
+func (r *Recorder) Stop(ctx context.Context) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	if r.bucket != nil {
+		r.cancel()
+		<-r.done
+		r.upload(ctx)
+		return
+	}
+
+	if f, ok := r.out.(*os.File); ok {
+		if err := f.Close(); err != nil {
+			r.logger.Error("failed closing the recording", "error", err)
+		}
+	}
+}
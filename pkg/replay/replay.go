@@ -0,0 +1,182 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Result is the emissions of a recorded instance
+type Result struct {
+	Sample store.Sample `json:"sample"`
+
+	// How the emissions were calculated
+	Breakdown *calculator.Breakdown `json:"breakdown,omitempty"`
+
+	// Why the emissions couldn't be calculated, the exporter drops these
+	// instances
+	Error string `json:"error,omitempty"`
+}
+
+// Calculate runs the records through the calculations of the exporter with
+// the emission factors in use, in order. The emissions are calculated over
+// the recorded interval unless interval is set. The results only depend on
+// the records and the factors
+func Calculate(ctx context.Context, records []Record, interval time.Duration) []Result {
+	results := make([]Result, 0, len(records))
+
+	for i := range records {
+		d := interval
+		if d <= 0 {
+			d = time.Duration(records[i].Interval)
+		}
+
+		instance := records[i].Instance()
+		breakdown, err := calculator.Calculate(ctx, instance, d)
+		if err != nil {
+			results = append(results, Result{
+				Sample: store.Sample{
+					Time:     records[i].Time(),
+					Provider: instance.Provider,
+					Service:  instance.Service,
+					Name:     instance.Name,
+					Region:   instance.Region,
+					Zone:     instance.Zone,
+					Kind:     instance.Kind,
+					Labels:   instance.Labels,
+				},
+				Error: err.Error(),
+			})
+			continue
+		}
+
+		// calculated when collected, not now
+		breakdown.CalculatedAt = records[i].Time()
+
+		results = append(results, Result{
+			Sample:    store.NewSample(instance, d),
+			Breakdown: breakdown,
+		})
+	}
+
+	return results
+}
+
+// Scrapes groups the records by the scrape they were collected by, in order
+func Scrapes(records []Record) [][]Record {
+	byTime := map[time.Time][]Record{}
+	var times []time.Time
+
+	for i := range records {
+		t := records[i].Time()
+		if _, ok := byTime[t]; !ok {
+			times = append(times, t)
+		}
+		byTime[t] = append(byTime[t], records[i])
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	scrapes := make([][]Record, len(times))
+	for i, t := range times {
+		scrapes[i] = byTime[t]
+	}
+
+	return scrapes
+}
+
+// Player publishes a recording on the bus instead of the scrapers, one
+// scrape every interval, starting over at the end of the recording. The
+// instances are published as collected now, so that the exporter serves
+// them like scraped ones
+type Player struct {
+	bus      *bus.Bus
+	scrapes  [][]Record
+	interval time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	logger *slog.Logger
+}
+
+// NewPlayer returns the player of the records
+func NewPlayer(ctx context.Context, b *bus.Bus, records []Record, interval time.Duration) (*Player, error) {
+	if len(records) == 0 {
+		return nil, errors.New("the recording is empty")
+	}
+	if interval <= 0 {
+		return nil, errors.New("the interval must be positive")
+	}
+
+	return &Player{
+		bus:      b,
+		scrapes:  Scrapes(records),
+		interval: interval,
+		logger:   log.FromContext(ctx),
+	}, nil
+}
+
+// Start publishes the first scrape now and then the next one every
+// interval, until the context is done or the player is stopped
+func (p *Player) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for i := 0; ; i++ {
+			p.publish(p.scrapes[i%len(p.scrapes)], time.Now().UTC())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops publishing the recording, it can be called more than once
+func (p *Player) Stop(ctx context.Context) {
+	if p.cancel == nil {
+		return
+	}
+
+	p.cancel()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+	}
+}
+
+// publish publishes the instances of a scrape as collected at the time
+func (p *Player) publish(scrape []Record, at time.Time) {
+	for i := range scrape {
+		instance := scrape[i].Instance()
+		for name, m := range instance.Metrics {
+			m.UpdatedAt = at
+			instance.Metrics[name] = m
+		}
+
+		if err := p.bus.Publish(&bus.Event{
+			Type: v1.MetricsCollectedEvent,
+			Data: *instance,
+		}); err != nil {
+			p.logger.Error("failed publishing the recorded instance", "instance", instance.Name, "error", err)
+		}
+	}
+}
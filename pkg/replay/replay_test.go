@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/internal/bustest"
+	"github.com/re-cinq/aether/pkg/bus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+// testFactors uses the emission factors of TestEstimateEmissions of the
+// calculator until the test ends
+func testFactors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"test-default.yaml":  "name: test\naveragePUE: 1.5\n",
+		"test-grid.yaml":     "- region: north\n  co2e: 0.0001\n",
+		"test-use.yaml":      "- architecture: A\n  minwatts: 10\n  maxwatts: 20\n",
+		"test-embodied.yaml": "- type: t-2\n  total: 315360\n  vCPU: 2\n  totalVCPU: 4\n  architecture: A\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	dataPath := factors.DataPath
+	factors.DataPath = dir
+	t.Cleanup(func() { factors.DataPath = dataPath })
+}
+
+func TestCalculate(t *testing.T) {
+	assert := require.New(t)
+	testFactors(t)
+
+	records, err := Load(context.Background(), "testdata/recording.jsonl")
+	assert.NoError(err)
+	assert.Len(records, 3)
+
+	ctx := context.Background()
+	results := Calculate(ctx, records, 0)
+	assert.Len(results, 3)
+
+	// 0.015 kW * 2 vCPU * 5 min * 1.5 PUE * 100 gCO2eq/kWh and
+	// 3 gCO2eq per hour
+	first := results[0]
+	assert.Empty(first.Error)
+	assert.Equal("vm-1", first.Sample.Name)
+	assert.Equal("prod", first.Sample.Labels[v1.AccountLabel])
+	assert.True(time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC).Equal(first.Sample.Time))
+	assert.InDelta(0.375, first.Sample.Operational, 1e-9)
+	assert.InDelta(0.25, first.Sample.Embodied, 1e-9)
+	assert.Equal(5*time.Minute, first.Breakdown.Interval)
+	assert.True(first.Sample.Time.Equal(first.Breakdown.CalculatedAt))
+
+	// the kind is unknown
+	assert.Equal("vm-2", results[1].Sample.Name)
+	assert.NotEmpty(results[1].Error)
+	assert.Nil(results[1].Breakdown)
+
+	assert.InDelta(0.5, results[2].Sample.Operational, 1e-9)
+
+	// over another interval, the same every time
+	results = Calculate(ctx, records, 10*time.Minute)
+	assert.InDelta(0.75, results[0].Sample.Operational, 1e-9)
+	assert.InDelta(0.5, results[0].Sample.Embodied, 1e-9)
+	assert.Equal(results, Calculate(ctx, records, 10*time.Minute))
+}
+
+func TestScrapes(t *testing.T) {
+	assert := require.New(t)
+
+	records, err := Load(context.Background(), "testdata/recording.jsonl")
+	assert.NoError(err)
+
+	// the scrapes are ordered by time
+	scrapes := Scrapes([]Record{records[2], records[0], records[1]})
+	assert.Len(scrapes, 2)
+	assert.Equal([]Record{records[0], records[1]}, scrapes[0])
+	assert.Equal([]Record{records[2]}, scrapes[1])
+}
+
+func TestPlayer(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	records, err := Load(context.Background(), "testdata/recording.jsonl")
+	assert.NoError(err)
+
+	_, err = NewPlayer(ctx, bus.New(), nil, time.Minute)
+	assert.Error(err)
+
+	events := make(bustest.Collector, 3)
+	b := bus.New()
+	b.Subscribe(v1.MetricsCollectedEvent, events)
+	b.Start(ctx)
+	defer b.Stop(ctx)
+
+	p, err := NewPlayer(ctx, b, records, time.Hour)
+	assert.NoError(err)
+
+	start := time.Now()
+	p.Start(ctx)
+	defer p.Stop(ctx)
+
+	// the first scrape is published as collected now
+	names := map[string]bool{}
+	for range 2 {
+		i := <-events
+		names[i.Name] = true
+		assert.Equal(v1.Provider("test"), i.Provider)
+		assert.False(i.Metrics["cpu"].UpdatedAt.Before(start.Truncate(time.Second)))
+		assert.Equal(50.0, i.Metrics["cpu"].Usage)
+	}
+	assert.Equal(map[string]bool{"vm-1": true, "vm-2": true}, names)
+
+	p.Stop(ctx)
+	p.Stop(ctx)
+}
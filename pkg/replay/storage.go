@@ -0,0 +1,214 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"google.golang.org/api/storage/v1"
+)
+
+// bucket stores the recordings in an object storage, as JSON Lines objects
+// under a prefix
+type bucket interface {
+	// Put writes the object
+	Put(ctx context.Context, key string, data []byte) error
+
+	// List returns the keys of the objects under the prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Get reads the object
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// The object storages of the recordings, by the scheme of their URL
+var buckets = map[string]func(ctx context.Context, name string) (bucket, error){
+	"s3": newS3Bucket,
+	"gs": newGCSBucket,
+}
+
+// openBucket returns the bucket and the prefix of a recording URL, e.g.
+// s3://bucket/recordings, false when the recording is a local file
+func openBucket(ctx context.Context, location string) (bucket, string, bool, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, "", false, nil
+	}
+
+	open, ok := buckets[u.Scheme]
+	if !ok {
+		return nil, "", false, fmt.Errorf("unsupported recording storage %q", u.Scheme)
+	}
+
+	b, err := open(ctx, u.Host)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed opening the recording bucket %s: %w", u.Host, err)
+	}
+
+	return b, strings.Trim(u.Path, "/"), true, nil
+}
+
+// objectKey returns the key of an object under the prefix
+func objectKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+// readBucket returns the records of all the objects under the prefix, in the
+// order of their keys
+func readBucket(ctx context.Context, b bucket, prefix string) ([]Record, error) {
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	keys, err := b.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the recordings: %w", err)
+	}
+
+	var records []Record
+	for _, key := range keys {
+		r, err := b.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the recording %s: %w", key, err)
+		}
+
+		read, err := Read(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		records = append(records, read...)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("no recording found")
+	}
+
+	return records, nil
+}
+
+// s3Bucket stores the recordings in an S3 bucket, with the default
+// credentials of the AWS SDK
+type s3Bucket struct {
+	client *s3.Client
+	name   string
+}
+
+func newS3Bucket(ctx context.Context, name string) (bucket, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Bucket{
+		client: s3.NewFromConfig(cfg),
+		name:   name,
+	}, nil
+}
+
+func (b *s3Bucket) Put(ctx context.Context, key string, data []byte) error {
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: &b.name,
+		Key:    &key,
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (b *s3Bucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: &b.name,
+		Prefix: &prefix,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page.Contents {
+			keys = append(keys, *o.Key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *s3Bucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &b.name,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+// gcsBucket stores the recordings in a Cloud Storage bucket, with the
+// application default credentials
+type gcsBucket struct {
+	service *storage.Service
+	name    string
+}
+
+func newGCSBucket(ctx context.Context, name string) (bucket, error) {
+	service, err := storage.NewService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcsBucket{
+		service: service,
+		name:    name,
+	}, nil
+}
+
+func (b *gcsBucket) Put(ctx context.Context, key string, data []byte) error {
+	_, err := b.service.Objects.
+		Insert(b.name, &storage.Object{Name: key, ContentType: "application/jsonl"}).
+		Media(bytes.NewReader(data)).
+		Context(ctx).
+		Do()
+	return err
+}
+
+func (b *gcsBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+
+	err := b.service.Objects.List(b.name).Prefix(prefix).Pages(ctx, func(objects *storage.Objects) error {
+		for _, o := range objects.Items {
+			keys = append(keys, o.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *gcsBucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.service.Objects.Get(b.name, key).Context(ctx).Download()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,101 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// memBucket stores the objects in memory
+type memBucket struct {
+	objects map[string][]byte
+	mu      sync.Mutex
+}
+
+func (b *memBucket) Put(ctx context.Context, key string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.objects[key] = data
+	return nil
+}
+
+func (b *memBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var keys []string
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *memBucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestBucketRecording(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	mem := &memBucket{objects: map[string][]byte{}}
+	buckets["mem"] = func(ctx context.Context, name string) (bucket, error) {
+		assert.Equal("recordings", name)
+		return mem, nil
+	}
+	defer delete(buckets, "mem")
+
+	r, err := NewRecorder(ctx, "mem://recordings/prod/", time.Millisecond, nil)
+	assert.NoError(err)
+
+	instance := func(name string) *bus.Event {
+		i := v1.NewInstance(name, v1.AWS)
+		i.Metrics.Upsert(&v1.Metric{Name: "cpu", ResourceType: v1.CPU, Usage: 10, UnitAmount: 2, UpdatedAt: time.Now().UTC()})
+		return &bus.Event{Type: v1.MetricsCollectedEvent, Data: *i}
+	}
+
+	// the records of every interval are uploaded as a new object
+	r.Handle(ctx, instance("i-1"))
+	assert.Eventually(func() bool {
+		keys, _ := mem.List(ctx, "prod/")
+		return len(keys) == 1
+	}, time.Second, time.Millisecond)
+
+	// the last records are uploaded when stopped
+	r.Handle(ctx, instance("i-2"))
+	r.Stop(ctx)
+	r.Stop(ctx)
+
+	records, err := Load(ctx, "mem://recordings/prod")
+	assert.NoError(err)
+	assert.Len(records, 2)
+	assert.Equal("i-1", records[0].Name)
+	assert.Equal("i-2", records[1].Name)
+
+	_, err = Load(ctx, "mem://recordings/dev")
+	assert.EqualError(err, "no recording found")
+
+	_, err = Load(ctx, "ftp://recordings/prod")
+	assert.EqualError(err, `unsupported recording storage "ftp"`)
+}
@@ -0,0 +1,509 @@
+package report
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/re-cinq/aether/pkg/store"
+)
+
+// The color of the summaries without a brand color
+const defaultColor = "#2e7d32"
+
+// The groups listed by default, the others are summed
+const defaultTop = 5
+
+// The group the groups not listed are summed in
+const other = "(other)"
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Brand is the branding of the executive summaries
+type Brand struct {
+	Organization string
+
+	// The path of a PNG or JPEG logo
+	Logo string
+
+	// The color of the header and the charts, as #rrggbb
+	Color string
+}
+
+// ExecutiveRequest describes an executive summary
+type ExecutiveRequest struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+
+	// How many groups are listed, the others are summed
+	Top int
+
+	Brand Brand
+}
+
+// Executive is the summary of the emissions of a date range for the
+// leadership: their total and trend compared to the previous range, and
+// the groups and providers emitting the most
+type Executive struct {
+	Organization string    `json:"organization,omitempty"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	GroupBy      string    `json:"groupBy"`
+
+	Total Row `json:"total"`
+
+	// The total of the range of the same length before, and the change of
+	// the emissions since in %, empty without emissions before
+	Previous Row      `json:"previous"`
+	Change   *float64 `json:"change,omitempty"`
+
+	// The groups emitting the most, the others summed in a last group
+	Top []Row `json:"top"`
+
+	// The emissions by provider, the highest first
+	Providers []Row `json:"providers"`
+
+	// The emissions by day
+	Trend []Day `json:"trend"`
+
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	brand Brand
+}
+
+// Day is the emissions of a day in gCO2eq
+type Day struct {
+	Day       time.Time `json:"day"`
+	Emissions float64   `json:"emissions"`
+}
+
+// NewExecutive returns the executive summary of the samples collected in
+// [from, to)
+func NewExecutive(s *store.Store, r *ExecutiveRequest, now time.Time) (*Executive, error) {
+	brand := r.Brand
+	if brand.Color == "" {
+		brand.Color = defaultColor
+	}
+	if !hexColor.MatchString(brand.Color) {
+		return nil, fmt.Errorf("invalid color %q, expected #rrggbb", brand.Color)
+	}
+	if brand.Logo != "" {
+		if _, err := logoType(brand.Logo); err != nil {
+			return nil, err
+		}
+	}
+
+	top := r.Top
+	if top <= 0 {
+		top = defaultTop
+	}
+
+	current := New(s, r.From, r.To, r.GroupBy)
+	previous := New(s, r.From.Add(-r.To.Sub(r.From)), r.From, r.GroupBy)
+
+	e := &Executive{
+		Organization: brand.Organization,
+		From:         r.From,
+		To:           r.To,
+		GroupBy:      r.GroupBy,
+		Total:        current.Total,
+		Previous:     previous.Total,
+		Top:          current.Rows,
+		Providers:    New(s, r.From, r.To, "provider").Rows,
+		Trend:        []Day{},
+		GeneratedAt:  now.UTC(),
+		brand:        brand,
+	}
+
+	if e.Previous.Emissions > 0 {
+		change := (e.Total.Emissions - e.Previous.Emissions) / e.Previous.Emissions * 100
+		e.Change = &change
+	}
+
+	if len(e.Top) > top {
+		rest := Row{Group: other}
+		for _, row := range e.Top[top:] {
+			rest.Instances += row.Instances
+			rest.Operational += row.Operational
+			rest.Embodied += row.Embodied
+			rest.Emissions += row.Emissions
+		}
+		e.Top = append(e.Top[:top:top], rest)
+	}
+
+	// the daily rollups are read when the range is whole days
+	q, err := store.ParseQuery("sum(emissions)")
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.QueryRange(q, r.From, r.To, store.DailyStep)
+	if err != nil {
+		return nil, err
+	}
+	for _, series := range m.Series {
+		for _, p := range series.Points {
+			e.Trend = append(e.Trend, Day{Day: p.Time, Emissions: p.Value})
+		}
+	}
+
+	return e, nil
+}
+
+// Write writes the summary in the format: html, pdf or json
+func (e *Executive) Write(w io.Writer, format string) error {
+	switch format {
+	case "html":
+		return e.WriteHTML(w)
+	case "pdf":
+		return e.WritePDF(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// title returns the title of the summary
+func (e *Executive) title() string {
+	if e.Organization == "" {
+		return "Emissions summary"
+	}
+	return e.Organization + " emissions summary"
+}
+
+// period returns the days of the summary, the end is exclusive
+func (e *Executive) period() string {
+	return fmt.Sprintf("%s to %s", e.From.Format(time.DateOnly), e.To.Add(-time.Nanosecond).Format(time.DateOnly))
+}
+
+// share returns the share of the emissions in the total, in %
+func (e *Executive) share(g float64) float64 {
+	if e.Total.Emissions == 0 {
+		return 0
+	}
+	return g / e.Total.Emissions * 100
+}
+
+// change returns the change since the previous range, n/a without one
+func (e *Executive) change() string {
+	if e.Change == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", *e.Change)
+}
+
+// maxDay returns the highest daily emissions, the scale of the trend
+func (e *Executive) maxDay() float64 {
+	var m float64
+	for _, d := range e.Trend {
+		m = math.Max(m, d.Emissions)
+	}
+	return m
+}
+
+// mass formats emissions in gCO2eq in kg or t
+func mass(g float64) string {
+	if math.Abs(g) >= 1e6 {
+		return strconv.FormatFloat(g/1e6, 'f', 2, 64) + " tCO2eq"
+	}
+	return strconv.FormatFloat(g/1e3, 'f', 2, 64) + " kgCO2eq"
+}
+
+// logoType returns the image type of the logo from its extension
+func logoType(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return "png", nil
+	case ".jpg", ".jpeg":
+		return "jpg", nil
+	default:
+		return "", fmt.Errorf("invalid logo %q, expected a PNG or JPEG file", path)
+	}
+}
+
+// WriteHTML writes a standalone HTML page, the logo is embedded
+func (e *Executive) WriteHTML(w io.Writer) error {
+	var logo template.URL
+	if e.brand.Logo != "" {
+		b, err := os.ReadFile(e.brand.Logo)
+		if err != nil {
+			return err
+		}
+		logo = template.URL("data:" + mime.TypeByExtension(strings.ToLower(filepath.Ext(e.brand.Logo))) + ";base64," + base64.StdEncoding.EncodeToString(b))
+	}
+
+	// the bars of the trend in a 100x40 box, the highest day is full height
+	type bar struct {
+		Day   string
+		Label string
+
+		X, Y, Width, Height string
+	}
+	var bars []bar
+	if peak := e.maxDay(); peak > 0 {
+		width := 100 / float64(len(e.Trend))
+		f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+		for i, d := range e.Trend {
+			h := d.Emissions / peak * 40
+			bars = append(bars, bar{
+				Day:    d.Day.Format(time.DateOnly),
+				Label:  mass(d.Emissions),
+				X:      f(float64(i)*width + width*0.1),
+				Y:      f(40 - h),
+				Width:  f(width * 0.8),
+				Height: f(h),
+			})
+		}
+	}
+
+	return executivePage.Execute(w, struct {
+		*Executive
+		Title  string
+		Period string
+		Delta  string
+		Color  template.CSS
+		Logo   template.URL
+		Bars   []bar
+	}{
+		Executive: e,
+		Title:     e.title(),
+		Period:    e.period(),
+		Delta:     e.change(),
+		Color:     template.CSS(e.brand.Color),
+		Logo:      logo,
+		Bars:      bars,
+	})
+}
+
+var executivePage = template.Must(template.New("executive").Funcs(template.FuncMap{
+	"mass":  mass,
+	"share": func(e *Executive, g float64) string { return strconv.FormatFloat(e.share(g), 'f', 1, 64) + "%" },
+	"date":  func(t time.Time) string { return t.Format(time.DateOnly) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Period}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; color: #222; }
+header { background: {{.Color}}; color: #fff; padding: 1.5em 2em; display: flex; align-items: center; gap: 1.5em; }
+header img { max-height: 3.5em; }
+header h1 { margin: 0; font-size: 1.6em; }
+main { padding: 1em 2em; }
+.kpis { display: flex; gap: 1em; flex-wrap: wrap; }
+.kpi { border: 1px solid #ddd; border-top: 4px solid {{.Color}}; padding: 0.8em 1.2em; min-width: 10em; }
+.kpi .v { font-size: 1.4em; font-weight: bold; }
+.kpi .l { color: #666; font-size: 0.9em; }
+svg rect { fill: {{.Color}}; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { padding: 0.4em 1em; border-bottom: 1px solid #ddd; text-align: left; }
+td.n { text-align: right; }
+footer { color: #666; font-size: 0.8em; padding: 1em 2em; }
+</style>
+</head>
+<body>
+<header>
+{{- if .Logo}}<img src="{{.Logo}}" alt="">{{end}}
+<div><h1>{{.Title}}</h1><div>{{.Period}}</div></div>
+</header>
+<main>
+<div class="kpis">
+<div class="kpi"><div class="v">{{mass .Total.Emissions}}</div><div class="l">Total emissions</div></div>
+<div class="kpi"><div class="v">{{.Delta}}</div><div class="l">Change since the previous period</div></div>
+<div class="kpi"><div class="v">{{share .Executive .Total.Embodied}}</div><div class="l">Embodied share</div></div>
+<div class="kpi"><div class="v">{{.Total.Instances}}</div><div class="l">Instances</div></div>
+</div>
+{{- if .Bars}}
+<h2>Daily emissions</h2>
+<svg viewBox="0 0 100 40" preserveAspectRatio="none" width="100%" height="160" role="img">
+{{- range .Bars}}
+<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Day}}: {{.Label}}</title></rect>
+{{- end}}
+</svg>
+{{- end}}
+<h2>Top {{.GroupBy}}</h2>
+<table>
+<thead><tr><th>{{.GroupBy}}</th><th>Instances</th><th>Emissions</th><th>Share</th></tr></thead>
+<tbody>
+{{- range .Top}}
+<tr><td>{{.Group}}</td><td class="n">{{.Instances}}</td><td class="n">{{mass .Emissions}}</td><td class="n">{{share $.Executive .Emissions}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<h2>Providers</h2>
+<table>
+<thead><tr><th>Provider</th><th>Instances</th><th>Operational</th><th>Embodied</th><th>Emissions</th></tr></thead>
+<tbody>
+{{- range .Providers}}
+<tr><td>{{.Group}}</td><td class="n">{{.Instances}}</td><td class="n">{{mass .Operational}}</td><td class="n">{{mass .Embodied}}</td><td class="n">{{mass .Emissions}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</main>
+<footer>Generated on {{date .GeneratedAt}} from the emissions stored by aether.</footer>
+</body>
+</html>
+`))
+
+// WritePDF writes an A4 page
+func (e *Executive) WritePDF(w io.Writer) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(e.title()+" "+e.period(), true)
+	pdf.SetCreator("aether", true)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	// the core fonts are encoded in cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	red, green, blue := rgb(e.brand.Color)
+	const left, width = 15.0, 180.0
+
+	// header
+	pdf.SetFillColor(red, green, blue)
+	pdf.Rect(0, 0, 210, 32, "F")
+	x := left
+	if e.brand.Logo != "" {
+		kind, _ := logoType(e.brand.Logo)
+		pdf.ImageOptions(e.brand.Logo, left, 8, 0, 16, false, fpdf.ImageOptions{ImageType: kind}, 0, "")
+		if info := pdf.GetImageInfo(e.brand.Logo); info != nil && info.Height() > 0 {
+			x += 16*info.Width()/info.Height() + 6
+		}
+	}
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.Text(x, 15, tr(e.title()))
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.Text(x, 23, e.period())
+
+	// key figures
+	pdf.SetTextColor(34, 34, 34)
+	kpis := [][2]string{
+		{mass(e.Total.Emissions), "Total emissions"},
+		{e.change(), "Change since the previous period"},
+		{strconv.FormatFloat(e.share(e.Total.Embodied), 'f', 1, 64) + "%", "Embodied share"},
+		{strconv.Itoa(e.Total.Instances), "Instances"},
+	}
+	boxWidth := (width - 3*4) / 4
+	for i, kpi := range kpis {
+		bx := left + float64(i)*(boxWidth+4)
+		pdf.SetDrawColor(221, 221, 221)
+		pdf.Rect(bx, 40, boxWidth, 22, "D")
+		pdf.SetFillColor(red, green, blue)
+		pdf.Rect(bx, 40, boxWidth, 1.5, "F")
+		pdf.SetXY(bx+2, 45)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(boxWidth-4, 6, kpi[0], "", 0, "L", false, 0, "")
+		pdf.SetXY(bx+2, 52)
+		pdf.SetFont("Helvetica", "", 7.5)
+		pdf.MultiCell(boxWidth-4, 3.5, kpi[1], "", "L", false)
+	}
+
+	y := 72.0
+
+	// daily trend
+	if peak := e.maxDay(); peak > 0 {
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.Text(left, y, "Daily emissions")
+		const height = 45.0
+		top := y + 4
+		pdf.SetDrawColor(221, 221, 221)
+		pdf.Line(left, top+height, left+width, top+height)
+		pdf.SetFillColor(red, green, blue)
+		step := width / float64(len(e.Trend))
+		for i, d := range e.Trend {
+			h := d.Emissions / peak * height
+			pdf.Rect(left+float64(i)*step+step*0.1, top+height-h, step*0.8, h, "F")
+		}
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.Text(left, top+height+4, e.Trend[0].Day.Format(time.DateOnly))
+		last := e.Trend[len(e.Trend)-1].Day.Format(time.DateOnly)
+		pdf.Text(left+width-pdf.GetStringWidth(last), top+height+4, last)
+		pdf.Text(left, top-0.5+3, "max "+mass(peak))
+		y = top + height + 14
+	}
+
+	table := func(title string, header []string, widths []float64, rows [][]string) {
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.Text(left, y, tr(title))
+		pdf.SetXY(left, y+3)
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(red, green, blue)
+		pdf.SetTextColor(255, 255, 255)
+		for i, h := range header {
+			align := "R"
+			if i == 0 {
+				align = "L"
+			}
+			pdf.CellFormat(widths[i], 7, tr(h), "", 0, align, true, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(34, 34, 34)
+		pdf.SetDrawColor(221, 221, 221)
+		for _, row := range rows {
+			pdf.SetX(left)
+			for i, v := range row {
+				align := "R"
+				if i == 0 {
+					align = "L"
+				}
+				pdf.CellFormat(widths[i], 6.5, tr(v), "B", 0, align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		y = pdf.GetY() + 12
+	}
+
+	var rows [][]string
+	for _, row := range e.Top {
+		rows = append(rows, []string{
+			row.Group,
+			strconv.Itoa(row.Instances),
+			mass(row.Emissions),
+			strconv.FormatFloat(e.share(row.Emissions), 'f', 1, 64) + "%",
+		})
+	}
+	table("Top "+e.GroupBy, []string{e.GroupBy, "Instances", "Emissions", "Share"}, []float64{75, 30, 45, 30}, rows)
+
+	rows = rows[:0]
+	for _, row := range e.Providers {
+		rows = append(rows, []string{
+			row.Group,
+			strconv.Itoa(row.Instances),
+			mass(row.Operational),
+			mass(row.Embodied),
+			mass(row.Emissions),
+		})
+	}
+	table("Providers", []string{"Provider", "Instances", "Operational", "Embodied", "Emissions"}, []float64{45, 25, 37, 36, 37}, rows)
+
+	// footer
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(102, 102, 102)
+	pdf.Text(left, 287, "Generated on "+e.GeneratedAt.Format(time.DateOnly)+" from the emissions stored by aether.")
+
+	return pdf.Output(w)
+}
+
+// rgb returns the components of a #rrggbb color
+func rgb(color string) (r, g, b int) {
+	v, _ := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)
+}
@@ -0,0 +1,131 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// executiveStore returns a store with the emissions of January and of the
+// last days of December
+func executiveStore(t *testing.T) *store.Store {
+	t.Helper()
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 12, 0, 0, 0, time.UTC) }
+	for _, sample := range []store.Sample{
+		{Time: day(time.January, 1), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "web"}, Operational: 3000, Embodied: 1000},
+		{Time: day(time.January, 2), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "web"}, Operational: 3000, Embodied: 1000},
+		{Time: day(time.January, 2), Provider: v1.GCP, Name: "b", Labels: v1.Labels{"team": "data"}, Operational: 1500, Embodied: 500},
+		{Time: day(time.January, 3), Provider: v1.GCP, Name: "c", Labels: v1.Labels{"team": "ml"}, Operational: 1000},
+		// the previous period
+		{Time: time.Date(2023, time.December, 31, 12, 0, 0, 0, time.UTC), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "web"}, Operational: 4000},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	return s
+}
+
+func TestExecutive(t *testing.T) {
+	assert := require.New(t)
+
+	s := executiveStore(t)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 4, 1, 0, 0, 0, time.UTC)
+
+	e, err := NewExecutive(s, &ExecutiveRequest{From: from, To: to, GroupBy: "team", Top: 2, Brand: Brand{Organization: "ACME"}}, now)
+	assert.NoError(err)
+
+	assert.Equal(Row{Group: "total", Instances: 3, Operational: 8500, Embodied: 2500, Emissions: 11000}, e.Total)
+	assert.InDelta(4000, e.Previous.Emissions, 1e-9)
+	assert.NotNil(e.Change)
+	assert.InDelta(175, *e.Change, 1e-9)
+
+	assert.Equal([]Row{
+		{Group: "web", Instances: 1, Operational: 6000, Embodied: 2000, Emissions: 8000},
+		{Group: "data", Instances: 1, Operational: 1500, Embodied: 500, Emissions: 2000},
+		{Group: other, Instances: 1, Operational: 1000, Emissions: 1000},
+	}, e.Top)
+	assert.Len(e.Providers, 2)
+	assert.Equal("aws", e.Providers[0].Group)
+
+	assert.Equal([]Day{
+		{Day: from, Emissions: 4000},
+		{Day: from.AddDate(0, 0, 1), Emissions: 6000},
+		{Day: from.AddDate(0, 0, 2), Emissions: 1000},
+	}, e.Trend)
+
+	var buf bytes.Buffer
+	assert.NoError(e.Write(&buf, "html"))
+	assert.Contains(buf.String(), "<h1>ACME emissions summary</h1><div>2024-01-01 to 2024-01-03</div>")
+	assert.Contains(buf.String(), "background: #2e7d32;")
+	assert.Contains(buf.String(), `<div class="v">&#43;175.0%</div>`)
+	assert.Contains(buf.String(), `<td>web</td><td class="n">1</td><td class="n">8.00 kgCO2eq</td><td class="n">72.7%</td>`)
+	assert.Contains(buf.String(), `<rect x="3.333" y="13.333" width="26.667" height="26.667"><title>2024-01-01: 4.00 kgCO2eq</title></rect>`)
+
+	buf.Reset()
+	assert.NoError(e.Write(&buf, "pdf"))
+	assert.True(bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+
+	assert.Error(e.Write(&buf, "docx"))
+
+	// without emissions before
+	e, err = NewExecutive(s, &ExecutiveRequest{From: to.AddDate(0, 0, 1), To: to.AddDate(0, 0, 2), GroupBy: "team"}, now)
+	assert.NoError(err)
+	assert.Nil(e.Change)
+	assert.Empty(e.Trend)
+	buf.Reset()
+	assert.NoError(e.Write(&buf, "pdf"))
+
+	_, err = NewExecutive(s, &ExecutiveRequest{From: from, To: to, Brand: Brand{Color: "green"}}, now)
+	assert.Error(err)
+	_, err = NewExecutive(s, &ExecutiveRequest{From: from, To: to, Brand: Brand{Logo: "logo.svg"}}, now)
+	assert.Error(err)
+}
+
+func TestExecutiveLogo(t *testing.T) {
+	assert := require.New(t)
+
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	f, err := os.Create(logo)
+	assert.NoError(err)
+	assert.NoError(png.Encode(f, img))
+	assert.NoError(f.Close())
+
+	s := executiveStore(t)
+	e, err := NewExecutive(s, &ExecutiveRequest{
+		From:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		GroupBy: "provider",
+		Brand:   Brand{Organization: "ACME", Logo: logo, Color: "#0055AA"},
+	}, time.Now())
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(e.Write(&buf, "html"))
+	assert.Contains(buf.String(), `<img src="data:image/png;base64,`)
+	assert.Contains(buf.String(), "background: #0055AA;")
+
+	buf.Reset()
+	assert.NoError(e.Write(&buf, "pdf"))
+	assert.Contains(buf.String(), "/Subtype /Image")
+}
@@ -0,0 +1,187 @@
+// Package report summarizes the stored emissions over a date range, e.g. for
+// the monthly sustainability reviews
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+)
+
+// The value of the samples without the label the report is grouped by
+const unset = "(none)"
+
+// Report is the emissions of a date range, grouped by a label
+type Report struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	GroupBy string    `json:"groupBy"`
+
+	// The groups sorted by emissions, the highest first
+	Rows []Row `json:"rows"`
+
+	// The sum of all the groups
+	Total Row `json:"total"`
+}
+
+// Row is the emissions of a group, in gCO2eq
+type Row struct {
+	Group       string  `json:"group"`
+	Instances   int     `json:"instances"`
+	Operational float64 `json:"operational"`
+	Embodied    float64 `json:"embodied"`
+	Emissions   float64 `json:"emissions"`
+}
+
+// New returns the report of the samples collected in [from, to), grouped by
+// a field or a label of the samples
+func New(s *store.Store, from, to time.Time, groupBy string) *Report {
+	r := &Report{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Total:   Row{Group: "total"},
+	}
+
+	rows := make(map[string]*Row)
+	instances := make(map[string]map[string]bool)
+	all := make(map[string]bool)
+
+	for _, sample := range s.Select(from, to, nil) {
+		group := sample.Label(groupBy)
+		if group == "" {
+			group = unset
+		}
+
+		row, ok := rows[group]
+		if !ok {
+			row = &Row{Group: group}
+			rows[group] = row
+			instances[group] = make(map[string]bool)
+		}
+
+		row.Operational += sample.Operational
+		row.Embodied += sample.Embodied
+
+		key := sample.Provider.String() + "/" + sample.Name
+		instances[group][key] = true
+		all[key] = true
+
+		r.Total.Operational += sample.Operational
+		r.Total.Embodied += sample.Embodied
+	}
+
+	r.Rows = make([]Row, 0, len(rows))
+	for group, row := range rows {
+		row.Instances = len(instances[group])
+		row.Emissions = row.Operational + row.Embodied
+		r.Rows = append(r.Rows, *row)
+	}
+
+	sort.Slice(r.Rows, func(i, j int) bool {
+		if r.Rows[i].Emissions != r.Rows[j].Emissions {
+			return r.Rows[i].Emissions > r.Rows[j].Emissions
+		}
+		return r.Rows[i].Group < r.Rows[j].Group
+	})
+
+	r.Total.Instances = len(all)
+	r.Total.Emissions = r.Total.Operational + r.Total.Embodied
+
+	return r
+}
+
+// Write writes the report in the format: csv, json or html
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "csv":
+		return r.WriteCSV(w)
+	case "json":
+		return r.WriteJSON(w)
+	case "html":
+		return r.WriteHTML(w)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// WriteCSV writes a line per group followed by the total
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{r.GroupBy, "instances", "operational_gco2eq", "embodied_gco2eq", "emissions_gco2eq"}); err != nil {
+		return err
+	}
+
+	for _, row := range append(r.Rows, r.Total) {
+		if err := cw.Write([]string{
+			row.Group,
+			strconv.Itoa(row.Instances),
+			strconv.FormatFloat(row.Operational, 'f', 4, 64),
+			strconv.FormatFloat(row.Embodied, 'f', 4, 64),
+			strconv.FormatFloat(row.Emissions, 'f', 4, 64),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteHTML writes a standalone HTML page, which can be attached as is
+func (r *Report) WriteHTML(w io.Writer) error {
+	return page.Execute(w, r)
+}
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format(time.DateOnly) },
+	// the end of the range is exclusive, show the last day included
+	"last": func(t time.Time) string { return t.Add(-time.Nanosecond).Format(time.DateOnly) },
+	"kg":   func(g float64) string { return strconv.FormatFloat(g/1000, 'f', 3, 64) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Emissions report {{date .From}} - {{last .To}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.4em 1em; border-bottom: 1px solid #ddd; }
+td.n { text-align: right; }
+tfoot td { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Emissions report</h1>
+<p>From {{date .From}} to {{last .To}}, grouped by {{.GroupBy}}.</p>
+<table>
+<thead>
+<tr><th>{{.GroupBy}}</th><th>Instances</th><th>Operational kgCO2eq</th><th>Embodied kgCO2eq</th><th>Total kgCO2eq</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{.Group}}</td><td class="n">{{.Instances}}</td><td class="n">{{kg .Operational}}</td><td class="n">{{kg .Embodied}}</td><td class="n">{{kg .Emissions}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr><td>Total</td><td class="n">{{.Total.Instances}}</td><td class="n">{{kg .Total.Operational}}</td><td class="n">{{kg .Total.Embodied}}</td><td class="n">{{kg .Total.Emissions}}</td></tr>
+</tfoot>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := context.Background()
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, sample := range []store.Sample{
+		{Time: from, Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "web"}, Operational: 10, Embodied: 1},
+		{Time: from.Add(time.Hour), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "web"}, Operational: 10, Embodied: 1},
+		{Time: from.Add(time.Hour), Provider: v1.GCP, Name: "b", Labels: v1.Labels{"team": "data"}, Operational: 30, Embodied: 2},
+		{Time: from.Add(2 * time.Hour), Provider: v1.GCP, Name: "c", Operational: 1},
+		// out of the range
+		{Time: from.Add(-time.Hour), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "web"}, Operational: 100},
+		{Time: to, Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "web"}, Operational: 100},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	r := New(s, from, to, "team")
+	assert.Equal([]Row{
+		{Group: "data", Instances: 1, Operational: 30, Embodied: 2, Emissions: 32},
+		{Group: "web", Instances: 1, Operational: 20, Embodied: 2, Emissions: 22},
+		{Group: unset, Instances: 1, Operational: 1, Emissions: 1},
+	}, r.Rows)
+	assert.Equal(Row{Group: "total", Instances: 3, Operational: 51, Embodied: 4, Emissions: 55}, r.Total)
+
+	var buf bytes.Buffer
+	assert.NoError(r.Write(&buf, "csv"))
+	assert.Equal(`team,instances,operational_gco2eq,embodied_gco2eq,emissions_gco2eq
+data,1,30.0000,2.0000,32.0000
+web,1,20.0000,2.0000,22.0000
+(none),1,1.0000,0.0000,1.0000
+total,3,51.0000,4.0000,55.0000
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(r.Write(&buf, "json"))
+	var decoded Report
+	assert.NoError(json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(r.Rows, decoded.Rows)
+
+	buf.Reset()
+	assert.NoError(r.Write(&buf, "html"))
+	assert.Contains(buf.String(), "From 2024-01-01 to 2024-01-31, grouped by team.")
+	assert.Contains(buf.String(), "<td>data</td><td class=\"n\">1</td><td class=\"n\">0.030</td>")
+
+	assert.Error(r.Write(&buf, "pdf"))
+}
@@ -0,0 +1,190 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+)
+
+// Scheduler renders the executive summaries of the schedules at the end of
+// every period, the files already rendered are kept
+type Scheduler struct {
+	store     *store.Store
+	schedules []config.ReportSchedule
+	brand     Brand
+
+	// How often the schedules are checked for a period ended
+	interval time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	logger *slog.Logger
+}
+
+// NewScheduler returns the scheduler of the summaries of the config
+func NewScheduler(ctx context.Context, cfg *config.ReportsConfig, s *store.Store) (*Scheduler, error) {
+	brand := Brand{
+		Organization: cfg.Brand.Organization,
+		Logo:         cfg.Brand.Logo,
+		Color:        cfg.Brand.Color,
+	}
+	if brand.Color != "" && !hexColor.MatchString(brand.Color) {
+		return nil, fmt.Errorf("invalid color %q, expected #rrggbb", brand.Color)
+	}
+	if brand.Logo != "" {
+		if _, err := logoType(brand.Logo); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(brand.Logo); err != nil {
+			return nil, err
+		}
+	}
+
+	schedules := make([]config.ReportSchedule, len(cfg.Schedules))
+	for i, sc := range cfg.Schedules {
+		if sc.Name == "" {
+			return nil, fmt.Errorf("report %d: missing name", i)
+		}
+		if sc.Directory == "" {
+			return nil, fmt.Errorf("report %s: missing directory", sc.Name)
+		}
+		switch store.Period(sc.Period) {
+		case store.Day, store.Week, store.Month:
+		default:
+			return nil, fmt.Errorf("report %s: invalid period %q, expected day, week or month", sc.Name, sc.Period)
+		}
+		if sc.Format == "" {
+			sc.Format = "pdf"
+		}
+		if sc.Format != "pdf" && sc.Format != "html" {
+			return nil, fmt.Errorf("report %s: invalid format %q, expected html or pdf", sc.Name, sc.Format)
+		}
+		if sc.GroupBy == "" {
+			sc.GroupBy = "provider"
+		}
+		schedules[i] = sc
+	}
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &Scheduler{
+		store:     s,
+		schedules: schedules,
+		brand:     brand,
+		interval:  interval,
+		logger:    log.FromContext(ctx),
+	}, nil
+}
+
+// Start renders the summaries of the periods ended now and then every
+// interval, until the context is done or the scheduler is stopped
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.run(time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops rendering the summaries, it can be called more than once
+func (s *Scheduler) Stop(ctx context.Context) {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+	}
+}
+
+// run renders the summaries of the last period ended of every schedule,
+// unless they're rendered already or the period has no emissions
+func (s *Scheduler) run(now time.Time) {
+	loc := s.store.Location()
+
+	for i := range s.schedules {
+		sc := &s.schedules[i]
+
+		period := store.Period(sc.Period)
+		to := period.Start(now, loc)
+		from := period.Start(to.Add(-time.Nanosecond), loc)
+
+		path := filepath.Join(sc.Directory, fmt.Sprintf("%s-%s.%s", sc.Name, from.Format(time.DateOnly), sc.Format))
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
+		if len(s.store.Select(from, to, nil)) == 0 {
+			continue
+		}
+
+		if err := s.render(path, sc, from, to, now); err != nil {
+			s.logger.Error("failed rendering the report", "report", sc.Name, "error", err)
+			continue
+		}
+		s.logger.Info("rendered the report", "report", sc.Name, "path", path)
+	}
+}
+
+// render writes the summary of the period to the path, the file appears
+// once complete
+func (s *Scheduler) render(path string, sc *config.ReportSchedule, from, to, now time.Time) error {
+	e, err := NewExecutive(s.store, &ExecutiveRequest{
+		From:    from,
+		To:      to,
+		GroupBy: sc.GroupBy,
+		Top:     sc.Top,
+		Brand:   s.brand,
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(sc.Directory, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(sc.Directory, ".report-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := e.Write(f, sc.Format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
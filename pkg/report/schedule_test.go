@@ -0,0 +1,73 @@
+package report
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScheduler(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	s, err := NewScheduler(ctx, &config.ReportsConfig{
+		Schedules: []config.ReportSchedule{{Name: "monthly", Period: "month", Directory: t.TempDir()}},
+	}, nil)
+	assert.NoError(err)
+	assert.Equal(time.Hour, s.interval)
+	assert.Equal("pdf", s.schedules[0].Format)
+	assert.Equal("provider", s.schedules[0].GroupBy)
+
+	for _, cfg := range []config.ReportsConfig{
+		{Schedules: []config.ReportSchedule{{Period: "month", Directory: "reports"}}},
+		{Schedules: []config.ReportSchedule{{Name: "monthly", Period: "month"}}},
+		{Schedules: []config.ReportSchedule{{Name: "yearly", Period: "year", Directory: "reports"}}},
+		{Schedules: []config.ReportSchedule{{Name: "monthly", Period: "month", Format: "csv", Directory: "reports"}}},
+		{Brand: config.ReportBrand{Color: "blue"}},
+		{Brand: config.ReportBrand{Logo: "missing.png"}},
+	} {
+		_, err := NewScheduler(ctx, &cfg, nil)
+		assert.Error(err, cfg)
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	s, err := NewScheduler(context.TODO(), &config.ReportsConfig{
+		Schedules: []config.ReportSchedule{
+			{Name: "daily", Period: "day", Format: "html", Directory: dir},
+			{Name: "weekly", Period: "week", Directory: dir},
+		},
+	}, executiveStore(t))
+	assert.NoError(err)
+
+	// the week of January 1st, a Monday, hasn't ended
+	s.run(time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC))
+	daily := filepath.Join(dir, "daily-2024-01-02.html")
+	b, err := os.ReadFile(daily)
+	assert.NoError(err)
+	assert.Contains(string(b), "2024-01-02 to 2024-01-02")
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(err)
+	assert.Len(entries, 2)
+	assert.Equal("weekly-2023-12-25.pdf", entries[1].Name())
+
+	// the rendered files are kept
+	assert.NoError(os.WriteFile(daily, []byte("edited"), 0o600))
+	s.run(time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC))
+	b, err = os.ReadFile(daily)
+	assert.NoError(err)
+	assert.Equal("edited", string(b))
+
+	s.run(time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC))
+	_, err = os.Stat(filepath.Join(dir, "weekly-2024-01-01.pdf"))
+	assert.NoError(err)
+}
@@ -0,0 +1,444 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The patterns of the shiftable workloads
+const (
+	// Most of the load runs in a few hours of the day
+	PatternBatch = "batch"
+
+	// The load follows the day and the nights are idle
+	PatternDiurnal = "diurnal"
+)
+
+const (
+	// The share of the operational emissions above the idle floor under
+	// which an instance is steady, and not worth shifting
+	minShiftable = 0.1
+
+	// The busiest hours of a batch workload and the share of the load they
+	// hold
+	batchHours = 6
+	batchShare = 0.6
+
+	// The consecutive hours of the idle nights and the most of the load they
+	// hold, whatever the timezone
+	nightHours = 8
+	nightShare = 0.1
+)
+
+// Profiles are the grid intensities of the regions by UTC hour of the day,
+// only their shape is used: they are scaled to their daily mean
+type Profiles map[string][24]float64
+
+// LoadProfiles reads the profiles from a CSV file with a region, hour and
+// intensity header, e.g. averaged from the history of Electricity Maps
+func LoadProfiles(path string) (Profiles, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading the header: %w", err)
+	}
+	if len(header) != 3 || header[0] != "region" || header[1] != "hour" || header[2] != "intensity" {
+		return nil, errors.New("the header must be: region,hour,intensity")
+	}
+
+	profiles := make(Profiles)
+	seen := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		hour, err := strconv.Atoi(record[1])
+		if err != nil || hour < 0 || hour > 23 {
+			return nil, fmt.Errorf("line %d: invalid hour %q", line, record[1])
+		}
+
+		intensity, err := strconv.ParseFloat(record[2], 64)
+		if err != nil || intensity < 0 {
+			return nil, fmt.Errorf("line %d: invalid intensity %q", line, record[2])
+		}
+
+		p := profiles[record[0]]
+		p[hour] = intensity
+		profiles[record[0]] = p
+		seen[record[0]]++
+	}
+
+	for region, hours := range seen {
+		if hours != 24 {
+			return nil, fmt.Errorf("the profile of %s has %d hours, not 24", region, hours)
+		}
+	}
+
+	return profiles, nil
+}
+
+// Shifting is the instances of a date range whose load could be moved to the
+// hours of the day with the lowest grid intensity
+type Shifting struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// The shiftable instances sorted by savings, then by shiftable
+	// emissions, the highest first
+	Recommendations []Recommendation `json:"recommendations"`
+
+	// The operational emissions of all the instances and the savings of
+	// the recommendations, in gCO2eq
+	Operational float64 `json:"operational"`
+	Savings     float64 `json:"savings"`
+}
+
+// Recommendation is an instance whose load could be shifted, the emissions
+// are in gCO2eq over the date range
+type Recommendation struct {
+	Provider v1.Provider `json:"provider"`
+	Name     string      `json:"name"`
+	Region   string      `json:"region"`
+	Pattern  string      `json:"pattern"`
+
+	// The operational emissions and the share above the idle floor of the
+	// instance, which follows the load
+	Operational float64 `json:"operational"`
+	Shiftable   float64 `json:"shiftable"`
+
+	// The UTC hours holding half of the shiftable emissions
+	PeakHours []int `json:"peakHours"`
+
+	// The greenest UTC hours the load could run in and the savings, set
+	// with the profile of the region
+	GreenHours []int   `json:"greenHours,omitempty"`
+	Savings    float64 `json:"savings"`
+}
+
+// NewShifting analyses the samples collected in [from, to) hour by hour. The
+// instances need samples in every hour of the day, and are shiftable when
+// their load runs in a few hours or leaves the nights idle. The savings move
+// the load to the greenest hours of the profile of their region, up to the
+// peak load of the instance per hour
+func NewShifting(samples []store.Sample, from, to time.Time, profiles Profiles) *Shifting {
+	type instance struct {
+		latest  *store.Sample
+		samples []*store.Sample
+
+		// the samples and their operational emissions by hour
+		count [24]int
+		sums  [24]float64
+	}
+
+	s := &Shifting{From: from, To: to, Recommendations: []Recommendation{}}
+
+	instances := make(map[string]*instance)
+	var keys []string
+	for i := range samples {
+		sample := &samples[i]
+		if sample.Time.Before(from) || !sample.Time.Before(to) {
+			continue
+		}
+		s.Operational += sample.Operational
+
+		key := sample.Provider.String() + "/" + sample.Name
+		in, ok := instances[key]
+		if !ok {
+			in = &instance{}
+			instances[key] = in
+			keys = append(keys, key)
+		}
+
+		h := sample.Time.UTC().Hour()
+		in.count[h]++
+		in.sums[h] += sample.Operational
+		in.latest = sample
+		in.samples = append(in.samples, sample)
+	}
+
+	for _, key := range keys {
+		in := instances[key]
+
+		// the idle floor is the lowest hourly average
+		floor := -1.0
+		for h := 0; h < 24; h++ {
+			if in.count[h] == 0 {
+				floor = -1
+				break
+			}
+			if avg := in.sums[h] / float64(in.count[h]); floor < 0 || avg < floor {
+				floor = avg
+			}
+		}
+		if floor < 0 {
+			continue
+		}
+
+		var operational, shiftable float64
+		var load [24]float64
+		for _, sample := range in.samples {
+			operational += sample.Operational
+			if v := sample.Operational - floor; v > 0 {
+				load[sample.Time.UTC().Hour()] += v
+				shiftable += v
+			}
+		}
+
+		if shiftable == 0 || shiftable < minShiftable*operational {
+			continue
+		}
+
+		pattern := loadPattern(&load, shiftable)
+		if pattern == "" {
+			continue
+		}
+
+		r := Recommendation{
+			Provider:    in.latest.Provider,
+			Name:        in.latest.Name,
+			Region:      in.latest.Region,
+			Pattern:     pattern,
+			Operational: operational,
+			Shiftable:   shiftable,
+			PeakHours:   peakHours(&load, shiftable),
+		}
+
+		if profile, ok := profiles[r.Region]; ok {
+			r.GreenHours, r.Savings = shift(&load, &profile)
+		}
+
+		s.Savings += r.Savings
+		s.Recommendations = append(s.Recommendations, r)
+	}
+
+	sort.Slice(s.Recommendations, func(i, j int) bool {
+		a, b := &s.Recommendations[i], &s.Recommendations[j]
+		if a.Savings != b.Savings {
+			return a.Savings > b.Savings
+		}
+		if a.Shiftable != b.Shiftable {
+			return a.Shiftable > b.Shiftable
+		}
+		return a.Name < b.Name
+	})
+
+	return s
+}
+
+// loadPattern returns the pattern of the load above the idle floor by hour,
+// empty when it is spread over the day
+func loadPattern(load *[24]float64, total float64) string {
+	sorted := append([]float64(nil), load[:]...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+
+	var busiest float64
+	for _, v := range sorted[:batchHours] {
+		busiest += v
+	}
+	if busiest >= batchShare*total {
+		return PatternBatch
+	}
+
+	// the quietest consecutive hours, wrapping around midnight
+	quietest := total
+	for start := 0; start < 24; start++ {
+		var night float64
+		for h := start; h < start+nightHours; h++ {
+			night += load[h%24]
+		}
+		if night < quietest {
+			quietest = night
+		}
+	}
+	if quietest <= nightShare*total {
+		return PatternDiurnal
+	}
+
+	return ""
+}
+
+// peakHours returns the busiest hours holding half of the load, in order
+func peakHours(load *[24]float64, total float64) []int {
+	hours := make([]int, 24)
+	for h := range hours {
+		hours[h] = h
+	}
+	sort.SliceStable(hours, func(i, j int) bool { return load[hours[i]] > load[hours[j]] })
+
+	var peak []int
+	var sum float64
+	for _, h := range hours {
+		if sum >= total/2 {
+			break
+		}
+		peak = append(peak, h)
+		sum += load[h]
+	}
+
+	sort.Ints(peak)
+	return peak
+}
+
+// shift moves the load to the greenest hours of the profile, each one taking
+// up to the busiest hour of the load, and returns the hours used and the
+// emissions saved. The emissions of the load are scaled by the intensity of
+// their hour relative to the daily mean
+func shift(load *[24]float64, profile *[24]float64) (green []int, savings float64) {
+	var mean, capacity, remaining float64
+	for h := 0; h < 24; h++ {
+		mean += profile[h] / 24
+		capacity = max(capacity, load[h])
+		remaining += load[h]
+	}
+	if mean == 0 {
+		return nil, 0
+	}
+
+	var current float64
+	for h := 0; h < 24; h++ {
+		current += load[h] * profile[h] / mean
+	}
+
+	hours := make([]int, 24)
+	for h := range hours {
+		hours[h] = h
+	}
+	sort.SliceStable(hours, func(i, j int) bool { return profile[hours[i]] < profile[hours[j]] })
+
+	var shifted float64
+	for _, h := range hours {
+		if remaining <= 0 {
+			break
+		}
+		moved := min(capacity, remaining)
+		shifted += moved * profile[h] / mean
+		remaining -= moved
+		green = append(green, h)
+	}
+
+	sort.Ints(green)
+	return green, current - shifted
+}
+
+// Write writes the recommendations in the format: csv, json or html
+func (s *Shifting) Write(w io.Writer, format string) error {
+	switch format {
+	case "csv":
+		return s.WriteCSV(w)
+	case "json":
+		return s.WriteJSON(w)
+	case "html":
+		return s.WriteHTML(w)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// WriteCSV writes a line per recommendation, the hours are space separated
+func (s *Shifting) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"provider", "name", "region", "pattern", "operational_gco2eq", "shiftable_gco2eq", "savings_gco2eq", "peak_hours", "green_hours"}); err != nil {
+		return err
+	}
+
+	for i := range s.Recommendations {
+		r := &s.Recommendations[i]
+		if err := cw.Write([]string{
+			r.Provider.String(),
+			r.Name,
+			r.Region,
+			r.Pattern,
+			strconv.FormatFloat(r.Operational, 'f', 4, 64),
+			strconv.FormatFloat(r.Shiftable, 'f', 4, 64),
+			strconv.FormatFloat(r.Savings, 'f', 4, 64),
+			hours(r.PeakHours),
+			hours(r.GreenHours),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the recommendations as indented JSON
+func (s *Shifting) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// WriteHTML writes a standalone HTML page, which can be attached as is
+func (s *Shifting) WriteHTML(w io.Writer) error {
+	return shiftingPage.Execute(w, s)
+}
+
+// hours returns the hours space separated
+func hours(h []int) string {
+	s := make([]string, len(h))
+	for i := range h {
+		s[i] = strconv.Itoa(h[i])
+	}
+	return strings.Join(s, " ")
+}
+
+var shiftingPage = template.Must(template.New("shifting").Funcs(template.FuncMap{
+	"date":  func(t time.Time) string { return t.Format(time.DateOnly) },
+	"last":  func(t time.Time) string { return t.Add(-time.Nanosecond).Format(time.DateOnly) },
+	"kg":    func(g float64) string { return strconv.FormatFloat(g/1000, 'f', 3, 64) },
+	"hours": hours,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Workload shifting {{date .From}} - {{last .To}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.4em 1em; border-bottom: 1px solid #ddd; }
+td.n { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Workload shifting</h1>
+<p>From {{date .From}} to {{last .To}}, the instances could save {{kg .Savings}} of {{kg .Operational}} operational kgCO2eq by moving their load to the greenest hours of the day. The hours are UTC.</p>
+<table>
+<thead>
+<tr><th>Provider</th><th>Instance</th><th>Region</th><th>Pattern</th><th>Operational kgCO2eq</th><th>Shiftable kgCO2eq</th><th>Savings kgCO2eq</th><th>Peak hours</th><th>Green hours</th></tr>
+</thead>
+<tbody>
+{{- range .Recommendations}}
+<tr><td>{{.Provider}}</td><td>{{.Name}}</td><td>{{.Region}}</td><td>{{.Pattern}}</td><td class="n">{{kg .Operational}}</td><td class="n">{{kg .Shiftable}}</td><td class="n">{{kg .Savings}}</td><td>{{hours .PeakHours}}</td><td>{{hours .GreenHours}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShifting(t *testing.T) {
+	assert := require.New(t)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+
+	// the hourly operational emissions of the instances over two days
+	load := map[string]func(h int) float64{
+		// runs at 2 and 3 am
+		"batch": func(h int) float64 {
+			if h == 2 || h == 3 {
+				return 11
+			}
+			return 1
+		},
+		// idle from midnight to 8 am
+		"diurnal": func(h int) float64 {
+			if h < 8 {
+				return 1
+			}
+			return 3
+		},
+		"steady": func(h int) float64 { return 5 },
+		// busy most of the day
+		"spread": func(h int) float64 {
+			if h == 0 {
+				return 1
+			}
+			return 2
+		},
+	}
+
+	var samples []store.Sample
+	for t := from; t.Before(to); t = t.Add(time.Hour) {
+		for _, name := range []string{"batch", "diurnal", "steady", "spread"} {
+			region := "eu"
+			if name == "diurnal" {
+				region = "us"
+			}
+			samples = append(samples, store.Sample{
+				Time:        t,
+				Provider:    v1.AWS,
+				Name:        name,
+				Region:      region,
+				Operational: load[name](t.Hour()),
+			})
+		}
+	}
+	// without samples in every hour
+	samples = append(samples, store.Sample{Time: from, Provider: v1.AWS, Name: "new", Region: "eu", Operational: 100})
+
+	// the greenest hours are 12 and 13, the batch runs in the dirtiest ones
+	var profile [24]float64
+	for h := range profile {
+		profile[h] = 100
+	}
+	profile[2], profile[3] = 160, 160
+	profile[12], profile[13] = 40, 40
+
+	s := NewShifting(samples, from, to, Profiles{"eu": profile})
+	assert.InDelta(2*(44+56+120+47)+100, s.Operational, 1e-9)
+	assert.InDelta(48, s.Savings, 1e-9)
+
+	assert.Len(s.Recommendations, 2)
+
+	batch := s.Recommendations[0]
+	assert.Equal("batch", batch.Name)
+	assert.Equal(PatternBatch, batch.Pattern)
+	assert.InDelta(88, batch.Operational, 1e-9)
+	assert.InDelta(40, batch.Shiftable, 1e-9)
+	assert.Equal([]int{2}, batch.PeakHours)
+	// the load is 20 per hour, the current emissions 64 and the shifted 16
+	assert.Equal([]int{12, 13}, batch.GreenHours)
+	assert.InDelta(48, batch.Savings, 1e-9)
+
+	diurnal := s.Recommendations[1]
+	assert.Equal("diurnal", diurnal.Name)
+	assert.Equal(PatternDiurnal, diurnal.Pattern)
+	assert.InDelta(64, diurnal.Shiftable, 1e-9)
+	assert.Equal([]int{8, 9, 10, 11, 12, 13, 14, 15}, diurnal.PeakHours)
+	// no profile for the region
+	assert.Nil(diurnal.GreenHours)
+	assert.Zero(diurnal.Savings)
+
+	var buf bytes.Buffer
+	assert.NoError(s.Write(&buf, "csv"))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 3)
+	assert.Equal("aws,batch,eu,batch,88.0000,40.0000,48.0000,2,12 13", lines[1])
+
+	buf.Reset()
+	assert.NoError(s.Write(&buf, "html"))
+	assert.Contains(buf.String(), "<td>12 13</td>")
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	var complete strings.Builder
+	complete.WriteString("region,hour,intensity\n")
+	for h := 0; h < 24; h++ {
+		fmt.Fprintf(&complete, "eu-west-1,%d,%d\n", h, 100+h)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "complete", data: complete.String()},
+		{name: "missing hours", data: "region,hour,intensity\neu-west-1,0,100\n", err: "the profile of eu-west-1 has 1 hours, not 24"},
+		{name: "invalid hour", data: "region,hour,intensity\neu-west-1,24,100\n", err: `line 2: invalid hour "24"`},
+		{name: "invalid header", data: "region,intensity\neu-west-1,100\n", err: "the header must be: region,hour,intensity"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			path := filepath.Join(dir, strings.ReplaceAll(test.name, " ", "-")+".csv")
+			assert.NoError(os.WriteFile(path, []byte(test.data), 0o600))
+
+			profiles, err := LoadProfiles(path)
+			if test.err != "" {
+				assert.EqualError(err, test.err)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Len(profiles, 1)
+			assert.Equal(100.0, profiles["eu-west-1"][0])
+			assert.Equal(123.0, profiles["eu-west-1"][23])
+		})
+	}
+}
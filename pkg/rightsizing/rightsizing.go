@@ -0,0 +1,239 @@
+// Package rightsizing pulls the rightsizing recommendations of the instances
+// from the recommenders of the providers, AWS Compute Optimizer and the GCP
+// Recommender, and estimates the emissions they would save
+package rightsizing
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The findings of the recommendations, the optimized instances aren't
+// recommended anything
+const (
+	FindingOverprovisioned  = "overprovisioned"
+	FindingUnderprovisioned = "underprovisioned"
+)
+
+// The utilization of the instances the recommenders don't report one for
+const defaultUtilization = 50
+
+// month is the period of the savings, 730 hours like the providers
+const month = 730 * time.Hour
+
+// Recommendation is an instance whose type the recommender of its provider
+// recommends to change, with the monthly savings of the change
+type Recommendation struct {
+	Provider v1.Provider `json:"provider"`
+	Account  string      `json:"account"`
+	Name     string      `json:"name"`
+	Region   string      `json:"region"`
+	Zone     string      `json:"zone,omitempty"`
+
+	// The current and the recommended instance types
+	Kind        string `json:"kind"`
+	Recommended string `json:"recommended"`
+
+	Finding string `json:"finding"`
+
+	// The CPU utilization in % of the instance the emissions are estimated
+	// with, the recommended type runs the same load on its vCPUs
+	Utilization float64 `json:"utilization"`
+
+	// The cost the recommender estimates is saved a month, negative when the
+	// recommended type costs more
+	CostSavings float64 `json:"costSavings"`
+	Currency    string  `json:"currency,omitempty"`
+
+	// The emissions a month in gCO2eq of the current and of the recommended
+	// type, and the difference
+	Emissions            float64 `json:"emissions"`
+	RecommendedEmissions float64 `json:"recommendedEmissions"`
+	CarbonSavings        float64 `json:"carbonSavings"`
+
+	// Why the emissions couldn't be estimated, e.g. a type missing from the
+	// emission factors
+	Error string `json:"error,omitempty"`
+}
+
+// Source returns the recommendations of an account
+type Source interface {
+	Recommendations(ctx context.Context) ([]Recommendation, error)
+}
+
+// Collector pulls the recommendations of the accounts periodically
+type Collector struct {
+	sources []Source
+
+	// How often the recommendations are pulled, the recommenders refresh
+	// them daily
+	interval time.Duration
+
+	// Estimates the emissions of the instance types
+	estimate func(ctx context.Context, req *calculator.EstimateRequest) (*calculator.Estimate, error)
+
+	// The recommendations by source, the ones of the sources failing are
+	// the ones of their last pull
+	recommendations [][]Recommendation
+	mu              sync.RWMutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	logger *slog.Logger
+}
+
+// New returns a collector of the recommendations of the sources, their
+// savings estimated with estimate, e.g. calculator.EstimateEmissions
+func New(ctx context.Context, cfg *config.RightsizingConfig, sources []Source, estimate func(ctx context.Context, req *calculator.EstimateRequest) (*calculator.Estimate, error)) *Collector {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	return &Collector{
+		sources:         sources,
+		interval:        interval,
+		estimate:        estimate,
+		recommendations: make([][]Recommendation, len(sources)),
+		logger:          log.FromContext(ctx),
+	}
+}
+
+// Recommendations returns the recommendations of the provider, of all of
+// them when empty, the highest carbon savings first
+func (c *Collector) Recommendations(provider v1.Provider) []Recommendation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := []Recommendation{}
+	for _, recommendations := range c.recommendations {
+		for i := range recommendations {
+			if provider == "" || recommendations[i].Provider == provider {
+				out = append(out, recommendations[i])
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].CarbonSavings != out[j].CarbonSavings {
+			return out[i].CarbonSavings > out[j].CarbonSavings
+		}
+		return out[i].CostSavings > out[j].CostSavings
+	})
+
+	return out
+}
+
+// Start pulls the recommendations now and then every interval, until the
+// context is done or the collector is stopped
+func (c *Collector) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.collect(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops pulling the recommendations, it can be called more than once
+func (c *Collector) Stop(ctx context.Context) {
+	if c.cancel == nil {
+		return
+	}
+
+	c.cancel()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+}
+
+// collect pulls the recommendations of every source and estimates their
+// carbon savings
+func (c *Collector) collect(ctx context.Context) {
+	for i, src := range c.sources {
+		recommendations, err := src.Recommendations(ctx)
+		if err != nil {
+			c.logger.Error("failed pulling the rightsizing recommendations", "error", err)
+			continue
+		}
+
+		for j := range recommendations {
+			c.annotate(ctx, &recommendations[j])
+		}
+
+		c.mu.Lock()
+		c.recommendations[i] = recommendations
+		c.mu.Unlock()
+	}
+}
+
+// annotate estimates the emissions of the current and of the recommended
+// type over a month, the recommended type running the same load
+func (c *Collector) annotate(ctx context.Context, r *Recommendation) {
+	if r.Utilization <= 0 {
+		r.Utilization = defaultUtilization
+	}
+
+	current, err := c.estimate(ctx, &calculator.EstimateRequest{
+		Provider:    r.Provider,
+		Kind:        r.Kind,
+		Region:      r.Region,
+		Utilization: r.Utilization,
+		Duration:    month,
+	})
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+
+	req := calculator.EstimateRequest{
+		Provider:    r.Provider,
+		Kind:        r.Recommended,
+		Region:      r.Region,
+		Utilization: r.Utilization,
+		Duration:    month,
+	}
+	recommended, err := c.estimate(ctx, &req)
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+
+	// the load of the current vCPUs on the recommended ones
+	if recommended.VCPU > 0 && recommended.VCPU != current.VCPU {
+		req.Utilization = math.Min(100, r.Utilization*current.VCPU/recommended.VCPU)
+		if recommended, err = c.estimate(ctx, &req); err != nil {
+			r.Error = err.Error()
+			return
+		}
+	}
+
+	r.Emissions = current.Total
+	r.RecommendedEmissions = recommended.Total
+	r.CarbonSavings = current.Total - recommended.Total
+}
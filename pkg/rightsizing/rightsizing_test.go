@@ -0,0 +1,88 @@
+package rightsizing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource returns fixed recommendations
+type fakeSource struct {
+	recommendations []Recommendation
+	err             error
+}
+
+func (f *fakeSource) Recommendations(ctx context.Context) ([]Recommendation, error) {
+	out := append([]Recommendation(nil), f.recommendations...)
+	return out, f.err
+}
+
+// fakeEstimate returns 1gCO2eq per vCPU and per point of utilization, and
+// 10g of embodied emissions per vCPU
+func fakeEstimate(ctx context.Context, req *calculator.EstimateRequest) (*calculator.Estimate, error) {
+	vcpus := map[string]float64{"m5.xlarge": 4, "m5.large": 2, "m5.2xlarge": 8}
+	v, ok := vcpus[req.Kind]
+	if !ok {
+		return nil, calculator.ErrUnknownKind
+	}
+	return &calculator.Estimate{
+		VCPU:  v,
+		Total: v*req.Utilization + 10*v,
+	}, nil
+}
+
+func TestCollect(t *testing.T) {
+	assert := require.New(t)
+
+	aws := &fakeSource{recommendations: []Recommendation{
+		{Provider: v1.AWS, Name: "i-1", Kind: "m5.xlarge", Recommended: "m5.large", Finding: FindingOverprovisioned, Utilization: 20, CostSavings: 70},
+		{Provider: v1.AWS, Name: "i-2", Kind: "m5.xlarge", Recommended: "m5.2xlarge", Finding: FindingUnderprovisioned, Utilization: 90, CostSavings: -140},
+		{Provider: v1.AWS, Name: "i-3", Kind: "m5.xlarge", Recommended: "t4g.nano", Finding: FindingOverprovisioned, CostSavings: 100},
+	}}
+	gcp := &fakeSource{recommendations: []Recommendation{
+		{Provider: v1.GCP, Name: "vm-1", Kind: "m5.xlarge", Recommended: "m5.large", Finding: FindingOverprovisioned},
+	}}
+
+	c := New(context.TODO(), &config.RightsizingConfig{}, []Source{aws, gcp}, fakeEstimate)
+	assert.Equal(24*time.Hour, c.interval)
+
+	c.collect(context.TODO())
+
+	recommendations := c.Recommendations("")
+	assert.Len(recommendations, 4)
+
+	// 4*20+40 and the same load on 2 vCPUs 2*40+20, the ties are sorted by
+	// the cost savings
+	assert.Equal("i-1", recommendations[0].Name)
+	assert.Equal(120.0, recommendations[0].Emissions)
+	assert.Equal(100.0, recommendations[0].RecommendedEmissions)
+	assert.Equal(20.0, recommendations[0].CarbonSavings)
+
+	// the default utilization, 4*50+40 and 2*100+20
+	assert.Equal("vm-1", recommendations[1].Name)
+	assert.Equal(50.0, recommendations[1].Utilization)
+	assert.Equal(240.0, recommendations[1].Emissions)
+	assert.Equal(220.0, recommendations[1].RecommendedEmissions)
+
+	// the type missing from the emission factors has no savings
+	assert.Equal("i-3", recommendations[2].Name)
+	assert.Equal(calculator.ErrUnknownKind.Error(), recommendations[2].Error)
+	assert.Zero(recommendations[2].CarbonSavings)
+
+	// 4*90+40 and 8*45+80
+	assert.Equal("i-2", recommendations[3].Name)
+	assert.Equal(-40.0, recommendations[3].CarbonSavings)
+
+	assert.Len(c.Recommendations(v1.GCP), 1)
+
+	// the recommendations of a failing source are the ones of its last pull
+	gcp.recommendations, gcp.err = nil, errors.New("permission denied")
+	c.collect(context.TODO())
+	assert.Len(c.Recommendations(v1.GCP), 1)
+}
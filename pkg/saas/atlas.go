@@ -0,0 +1,200 @@
+package saas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// defaultAtlasURL is the URL of the Atlas Administration API
+const defaultAtlasURL = "https://cloud.mongodb.com"
+
+// atlasAccept is the version of the Atlas Administration API used
+const atlasAccept = "application/vnd.atlas.2023-01-01+json"
+
+// The line items of the instances of the clusters, e.g. ATLAS_AWS_INSTANCE_M30
+// or ATLAS_GCP_INSTANCE_R40_NVME
+var atlasInstanceSKU = regexp.MustCompile(`^ATLAS_(AWS|GCP|AZURE)_INSTANCE_([MR][0-9]+)`)
+
+// The clouds of the SKUs
+var atlasClouds = map[string]v1.Provider{
+	"AWS":   v1.AWS,
+	"AZURE": v1.Azure,
+	"GCP":   v1.GCP,
+}
+
+// The vCPUs of the tiers of the clusters on AWS, the R tiers have less vCPUs
+// for the memory
+var atlasTierVCPUs = map[string]float64{
+	"M10":  2,
+	"M20":  2,
+	"M30":  2,
+	"M40":  4,
+	"M50":  8,
+	"M60":  16,
+	"M80":  32,
+	"M140": 48,
+	"M200": 64,
+	"M300": 96,
+	"M400": 64,
+	"M700": 96,
+	"R40":  2,
+	"R50":  4,
+	"R60":  8,
+	"R80":  16,
+	"R200": 32,
+	"R300": 48,
+	"R400": 64,
+	"R700": 96,
+}
+
+// atlas reads the server hours of the clusters from the invoices of the
+// organization
+type atlas struct {
+	url          string
+	organization string
+	client       *http.Client
+}
+
+// newAtlas returns the connector of an organization, the access tokens of
+// the service account are requested and renewed when needed
+func newAtlas(ctx context.Context, u, organization, clientID, clientSecret string, client *http.Client) *atlas {
+	credentials := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     u + "/api/oauth/token",
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+
+	return &atlas{
+		url:          u,
+		organization: organization,
+		client:       credentials.Client(context.WithValue(ctx, oauth2.HTTPClient, client)),
+	}
+}
+
+// atlasInvoice is the part of the invoices read
+type atlasInvoice struct {
+	ID        string          `json:"id"`
+	StartDate time.Time       `json:"startDate"`
+	EndDate   time.Time       `json:"endDate"`
+	LineItems []atlasLineItem `json:"lineItems"`
+}
+
+type atlasLineItem struct {
+	SKU         string    `json:"sku"`
+	ClusterName string    `json:"clusterName"`
+	GroupID     string    `json:"groupId"`
+	GroupName   string    `json:"groupName"`
+	Quantity    float64   `json:"quantity"`
+	StartDate   time.Time `json:"startDate"`
+	EndDate     time.Time `json:"endDate"`
+}
+
+// Usage returns the vCPU hours of the clusters, by day. They're read from
+// the pending invoice and from the invoices of the previous months
+func (a *atlas) Usage(ctx context.Context, from, to time.Time) ([]Usage, error) {
+	invoices, err := a.invoices(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage []Usage
+	for i := range invoices {
+		for j := range invoices[i].LineItems {
+			item := &invoices[i].LineItems[j]
+			if item.StartDate.Before(from) || item.EndDate.After(to) {
+				continue
+			}
+
+			m := atlasInstanceSKU.FindStringSubmatch(item.SKU)
+			if m == nil {
+				continue
+			}
+			vcpus, ok := atlasTierVCPUs[m[2]]
+			if !ok {
+				continue
+			}
+
+			project := item.GroupName
+			if project == "" {
+				project = item.GroupID
+			}
+
+			usage = append(usage, Usage{
+				Time:     item.EndDate,
+				Provider: atlasClouds[m[1]],
+				Service:  "cluster",
+				Name:     item.ClusterName,
+				Kind:     m[2],
+				Labels:   v1.Labels{"project": project},
+				Units:    item.Quantity * vcpus,
+			})
+		}
+	}
+
+	return usage, nil
+}
+
+// invoices returns the pending invoice and the ones ending after from, with
+// their line items
+func (a *atlas) invoices(ctx context.Context, from time.Time) ([]atlasInvoice, error) {
+	base := a.url + "/api/atlas/v2/orgs/" + url.PathEscape(a.organization) + "/invoices"
+
+	var pending atlasInvoice
+	if err := a.get(ctx, base+"/pending", &pending); err != nil {
+		return nil, err
+	}
+	invoices := []atlasInvoice{pending}
+
+	for page := 1; ; page++ {
+		var list struct {
+			Results    []atlasInvoice `json:"results"`
+			TotalCount int            `json:"totalCount"`
+		}
+		if err := a.get(ctx, base+"?itemsPerPage=100&pageNum="+strconv.Itoa(page), &list); err != nil {
+			return nil, err
+		}
+
+		for i := range list.Results {
+			if list.Results[i].ID == pending.ID || !list.Results[i].EndDate.After(from) {
+				continue
+			}
+
+			// the listed invoices have no line items
+			var invoice atlasInvoice
+			if err := a.get(ctx, base+"/"+url.PathEscape(list.Results[i].ID), &invoice); err != nil {
+				return nil, err
+			}
+			invoices = append(invoices, invoice)
+		}
+
+		if len(list.Results) == 0 || page*100 >= list.TotalCount {
+			break
+		}
+	}
+
+	return invoices, nil
+}
+
+// get reads a resource of the API into out
+func (a *atlas) get(ctx context.Context, u string, out any) error {
+	req, err := newJSONRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", atlasAccept)
+
+	if err := doJSON(a.client, req, out); err != nil {
+		return fmt.Errorf("atlas: %w", err)
+	}
+	return nil
+}
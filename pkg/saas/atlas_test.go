@@ -0,0 +1,74 @@
+package saas
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtlasUsage(t *testing.T) {
+	assert := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/oauth/token" {
+			id, secret, ok := r.BasicAuth()
+			assert.True(ok)
+			assert.Equal("id", id)
+			assert.Equal("secret", secret)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`))
+			return
+		}
+
+		assert.Equal("Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(atlasAccept, r.Header.Get("Accept"))
+
+		switch r.URL.Path {
+		case "/api/atlas/v2/orgs/org/invoices/pending":
+			_, _ = w.Write([]byte(`{"id": "feb", "lineItems": [
+				{"sku": "ATLAS_AWS_INSTANCE_M30", "clusterName": "Cluster0", "groupId": "g1", "groupName": "shop", "quantity": 72, "unit": "server hours", "startDate": "2024-02-01T00:00:00Z", "endDate": "2024-02-02T00:00:00Z"},
+				{"sku": "ATLAS_AWS_STORAGE_PROVISIONED", "clusterName": "Cluster0", "groupId": "g1", "quantity": 10, "unit": "GB months", "startDate": "2024-02-01T00:00:00Z", "endDate": "2024-02-02T00:00:00Z"}
+			]}`))
+		case "/api/atlas/v2/orgs/org/invoices":
+			_, _ = w.Write([]byte(`{"totalCount": 3, "results": [{"id": "feb", "endDate": "2024-03-01T00:00:00Z"}, {"id": "jan", "endDate": "2024-02-01T00:00:00Z"}, {"id": "dec", "endDate": "2024-01-01T00:00:00Z"}]}`))
+		case "/api/atlas/v2/orgs/org/invoices/jan":
+			_, _ = w.Write([]byte(`{"id": "jan", "lineItems": [
+				{"sku": "ATLAS_GCP_INSTANCE_R40_NVME", "clusterName": "Cluster1", "groupId": "g2", "quantity": 24, "startDate": "2024-01-31T00:00:00Z", "endDate": "2024-02-01T00:00:00Z"},
+				{"sku": "ATLAS_GCP_INSTANCE_M40", "clusterName": "Cluster1", "groupId": "g2", "quantity": 24, "startDate": "2024-01-30T00:00:00Z", "endDate": "2024-01-31T00:00:00Z"}
+			]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	a := newAtlas(context.TODO(), server.URL, "org", "id", "secret", server.Client())
+	usage, err := a.Usage(context.TODO(), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC))
+	assert.NoError(err)
+	assert.Equal([]Usage{
+		{
+			Time:     time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC),
+			Provider: v1.AWS,
+			Service:  "cluster",
+			Name:     "Cluster0",
+			Kind:     "M30",
+			Labels:   v1.Labels{"project": "shop"},
+			Units:    144,
+		},
+		{
+			Time:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Provider: v1.GCP,
+			Service:  "cluster",
+			Name:     "Cluster1",
+			Kind:     "R40",
+			Labels:   v1.Labels{"project": "g2"},
+			Units:    48,
+		},
+	}, usage)
+}
@@ -0,0 +1,157 @@
+package saas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The DBUs of the workspaces of the account by resource, read from the
+// billing system table
+const databricksQuery = `SELECT date_format(usage_end_time, "yyyy-MM-dd'T'HH:mm:ssXXX"), cloud, workspace_id, billing_origin_product, sku_name,
+  coalesce(usage_metadata.cluster_id, usage_metadata.warehouse_id, usage_metadata.job_id, usage_metadata.dlt_pipeline_id, ''), usage_quantity
+FROM system.billing.usage
+WHERE usage_unit = 'DBU' AND usage_start_time >= :from AND usage_end_time <= :to`
+
+// The clouds of the billing table
+var databricksClouds = map[string]v1.Provider{
+	"AWS":   v1.AWS,
+	"AZURE": v1.Azure,
+	"GCP":   v1.GCP,
+}
+
+// databricks reads the DBUs with the statement execution API of a SQL
+// warehouse
+type databricks struct {
+	url       string
+	token     string
+	warehouse string
+	client    *http.Client
+}
+
+// databricksResponse is the part of the responses of the statement
+// execution API read, the chunks of the result are returned without status
+type databricksResponse struct {
+	StatementID string `json:"statement_id"`
+	Status      struct {
+		State string `json:"state"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"status"`
+	Result databricksChunk `json:"result"`
+
+	// The error of the requests rejected by the API
+	Message string `json:"message"`
+}
+
+type databricksChunk struct {
+	DataArray [][]*string `json:"data_array"`
+	NextChunk string      `json:"next_chunk_internal_link"`
+}
+
+// Usage returns the DBUs of the clusters, the warehouses, the jobs and the
+// pipelines
+func (d *databricks) Usage(ctx context.Context, from, to time.Time) ([]Usage, error) {
+	body, err := json.Marshal(map[string]any{
+		"warehouse_id":    d.warehouse,
+		"statement":       databricksQuery,
+		"wait_timeout":    "50s",
+		"on_wait_timeout": "CONTINUE",
+		"format":          "JSON_ARRAY",
+		"disposition":     "INLINE",
+		"parameters": []map[string]string{
+			{"name": "from", "value": from.UTC().Format(time.RFC3339), "type": "TIMESTAMP"},
+			{"name": "to", "value": to.UTC().Format(time.RFC3339), "type": "TIMESTAMP"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp databricksResponse
+	if err := d.do(ctx, http.MethodPost, "/api/2.0/sql/statements", body, &resp); err != nil {
+		return nil, err
+	}
+
+	for resp.Status.State == "PENDING" || resp.Status.State == "RUNNING" {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		id := resp.StatementID
+		resp = databricksResponse{}
+		if err := d.do(ctx, http.MethodGet, "/api/2.0/sql/statements/"+url.PathEscape(id), nil, &resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.Status.State != "SUCCEEDED" {
+		return nil, fmt.Errorf("databricks statement %s: %s", strings.ToLower(resp.Status.State), resp.Status.Error.Message)
+	}
+
+	rows := resp.Result.DataArray
+	for next := resp.Result.NextChunk; next != ""; {
+		var chunk databricksChunk
+		if err := d.do(ctx, http.MethodGet, next, nil, &chunk); err != nil {
+			return nil, err
+		}
+		rows = append(rows, chunk.DataArray...)
+		next = chunk.NextChunk
+	}
+
+	usage := make([]Usage, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 7 {
+			return nil, fmt.Errorf("invalid databricks row of %d columns", len(row))
+		}
+
+		values := make([]string, len(row))
+		for i := range row {
+			if row[i] != nil {
+				values[i] = *row[i]
+			}
+		}
+
+		end, err := time.Parse(time.RFC3339, values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid databricks end time %q", values[0])
+		}
+		dbus, err := strconv.ParseFloat(values[6], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid databricks usage %q", values[6])
+		}
+
+		usage = append(usage, Usage{
+			Time:     end,
+			Provider: databricksClouds[values[1]],
+			Service:  strings.ToLower(values[3]),
+			Name:     values[5],
+			Kind:     values[4],
+			Labels:   v1.Labels{"workspace": values[2]},
+			Units:    dbus,
+		})
+	}
+
+	return usage, nil
+}
+
+// do sends a request to the API and decodes its response into out
+func (d *databricks) do(ctx context.Context, method, path string, body []byte, out any) error {
+	req, err := newJSONRequest(ctx, method, d.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+d.token)
+
+	return doJSON(d.client, req, out)
+}
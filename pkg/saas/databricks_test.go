@@ -0,0 +1,68 @@
+package saas
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabricksUsage(t *testing.T) {
+	assert := require.New(t)
+	pollInterval = time.Millisecond
+
+	state := "SUCCEEDED"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/api/2.0/sql/statements":
+			_, _ = w.Write([]byte(`{"statement_id": "s1", "status": {"state": "PENDING"}}`))
+		case "/api/2.0/sql/statements/s1":
+			_, _ = w.Write([]byte(`{
+				"statement_id": "s1",
+				"status": {"state": "` + state + `", "error": {"message": "TABLE_OR_VIEW_NOT_FOUND"}},
+				"result": {
+					"data_array": [["2024-01-31T01:00:00Z", "AWS", "1234", "JOBS", "PREMIUM_JOBS_COMPUTE", "0131-cluster", "2.5"]],
+					"next_chunk_internal_link": "/api/2.0/sql/statements/s1/result/chunks/1"
+				}
+			}`))
+		case "/api/2.0/sql/statements/s1/result/chunks/1":
+			_, _ = w.Write([]byte(`{"data_array": [["2024-01-31T02:00:00Z", "GCP", "1234", "SQL", "PREMIUM_SERVERLESS_SQL_COMPUTE", null, "1"]]}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	d := &databricks{url: server.URL, token: "token", warehouse: "abc", client: server.Client()}
+	usage, err := d.Usage(context.TODO(), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC))
+	assert.NoError(err)
+	assert.Equal([]Usage{
+		{
+			Time:     time.Date(2024, 1, 31, 1, 0, 0, 0, time.UTC),
+			Provider: v1.AWS,
+			Service:  "jobs",
+			Name:     "0131-cluster",
+			Kind:     "PREMIUM_JOBS_COMPUTE",
+			Labels:   v1.Labels{"workspace": "1234"},
+			Units:    2.5,
+		},
+		{
+			Time:     time.Date(2024, 1, 31, 2, 0, 0, 0, time.UTC),
+			Provider: v1.GCP,
+			Service:  "sql",
+			Kind:     "PREMIUM_SERVERLESS_SQL_COMPUTE",
+			Labels:   v1.Labels{"workspace": "1234"},
+			Units:    1,
+		},
+	}, usage)
+
+	state = "FAILED"
+	_, err = d.Usage(context.TODO(), time.Time{}, time.Now())
+	assert.ErrorContains(err, "TABLE_OR_VIEW_NOT_FOUND")
+}
@@ -0,0 +1,403 @@
+// Package saas pulls the usage of the SaaS and DBaaS vendors, e.g. the
+// credits of Snowflake, from their APIs and stores its emissions alongside
+// the ones of the instances
+package saas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The vendors the usage is pulled from
+const (
+	VendorSnowflake  = "snowflake"
+	VendorDatabricks = "databricks"
+	VendorAtlas      = "atlas"
+)
+
+// The estimates of the energy in kWh and of the embodied emissions in gCO2eq
+// of a vCPU hour of a cloud server: half used, with 2GB of memory per vCPU
+// and a PUE of 1.135, built with 1.2tCO2eq for 96 vCPUs over 4 years
+const (
+	vcpuHourEnergy   = 0.0033
+	vcpuHourEmbodied = 0.36
+)
+
+// The vCPU hours of a unit of the vendors, their default factors
+var vendors = map[string]float64{
+	// a credit is an hour of a node of an XS warehouse, about 8 vCPUs
+	VendorSnowflake: 8,
+	// a DBU is about an hour of a 4 vCPUs node
+	VendorDatabricks: 4,
+	// the usage of atlas is in vCPU hours
+	VendorAtlas: 1,
+}
+
+// Usage is the consumption of a resource of a vendor over a period
+type Usage struct {
+	// The end of the period
+	Time time.Time
+
+	// The cloud the resource runs on and its region, the ones of the
+	// account when empty
+	Provider v1.Provider
+	Region   string
+
+	Service string
+	Name    string
+	Kind    string
+	Labels  v1.Labels
+
+	// The units used, e.g. the credits of a Snowflake warehouse
+	Units float64
+}
+
+// connector returns the usage of the periods in [from, to) of an account
+type connector interface {
+	Usage(ctx context.Context, from, to time.Time) ([]Usage, error)
+}
+
+// sampleStore returns and records the emissions
+type sampleStore interface {
+	Select(from, to time.Time, filter func(*store.Sample) bool) []store.Sample
+	Add(ctx context.Context, sample store.Sample) error
+}
+
+// account is a validated connector of the config
+type account struct {
+	vendor string
+	name   string
+
+	provider v1.Provider
+	region   string
+
+	// The factors of a unit
+	energy   float64
+	embodied float64
+
+	connector connector
+
+	// The end of the usage stored, the next pull starts there
+	pulled time.Time
+}
+
+// Collector pulls the usage of the accounts of the vendors periodically and
+// stores its emissions
+type Collector struct {
+	accounts []*account
+
+	// How often the usage is pulled, how far back the first time, and how
+	// long the vendors take to report it
+	interval time.Duration
+	lookback time.Duration
+	delay    time.Duration
+
+	store sampleStore
+
+	// The grid intensity of the regions in gCO2eq/kWh
+	intensity func(provider v1.Provider, region string) (float64, error)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	logger *slog.Logger
+}
+
+// New returns a collector storing the emissions of the connectors of the
+// config in the store, with the grid intensity of their regions, e.g. the
+// one of calculator.GridIntensity
+func New(ctx context.Context, cfg *config.SaaSConfig, s sampleStore, intensity func(provider v1.Provider, region string) (float64, error)) (*Collector, error) {
+	client := &http.Client{Timeout: time.Minute}
+
+	accounts := make([]*account, 0, len(cfg.Connectors))
+	for i := range cfg.Connectors {
+		a, err := newAccount(ctx, &cfg.Connectors[i], client)
+		if err != nil {
+			return nil, fmt.Errorf("connector %d: %w", i, err)
+		}
+		accounts = append(accounts, a)
+	}
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	lookback := cfg.Lookback
+	if lookback <= 0 {
+		lookback = 7 * 24 * time.Hour
+	}
+
+	return &Collector{
+		accounts:  accounts,
+		interval:  interval,
+		lookback:  lookback,
+		delay:     cfg.Delay,
+		store:     s,
+		intensity: intensity,
+		logger:    log.FromContext(ctx),
+	}, nil
+}
+
+// newAccount validates the connector and returns its account
+func newAccount(ctx context.Context, c *config.SaaSConnector, client *http.Client) (*account, error) {
+	vcpuHours, ok := vendors[c.Vendor]
+	if !ok {
+		return nil, fmt.Errorf("unknown vendor %q", c.Vendor)
+	}
+	if c.Account == "" {
+		return nil, fmt.Errorf("missing account")
+	}
+	if c.EnergyPerUnit < 0 || c.EmbodiedPerUnit < 0 {
+		return nil, fmt.Errorf("negative factors")
+	}
+
+	a := &account{
+		vendor:   c.Vendor,
+		name:     c.Account,
+		provider: c.Provider,
+		region:   c.Region,
+		energy:   c.EnergyPerUnit,
+		embodied: c.EmbodiedPerUnit,
+	}
+	if a.energy == 0 {
+		a.energy = vcpuHours * vcpuHourEnergy
+	}
+	if a.embodied == 0 {
+		a.embodied = vcpuHours * vcpuHourEmbodied
+	}
+
+	url := strings.TrimSuffix(c.URL, "/")
+
+	switch c.Vendor {
+	case VendorSnowflake, VendorDatabricks:
+		if url == "" {
+			return nil, fmt.Errorf("missing %s url", c.Vendor)
+		}
+		if c.Token == "" {
+			return nil, fmt.Errorf("missing %s token", c.Vendor)
+		}
+		if c.Vendor == VendorSnowflake {
+			if c.Provider == "" || c.Region == "" {
+				return nil, fmt.Errorf("missing snowflake provider or region")
+			}
+			a.connector = &snowflake{url: url, token: c.Token, client: client}
+			break
+		}
+		if c.Warehouse == "" {
+			return nil, fmt.Errorf("missing databricks warehouse")
+		}
+		if c.Region == "" {
+			return nil, fmt.Errorf("missing databricks region")
+		}
+		a.connector = &databricks{url: url, token: c.Token, warehouse: c.Warehouse, client: client}
+	case VendorAtlas:
+		if c.Organization == "" {
+			return nil, fmt.Errorf("missing atlas organization")
+		}
+		if c.ClientID == "" || c.ClientSecret == "" {
+			return nil, fmt.Errorf("missing atlas client id or secret")
+		}
+		if c.Region == "" {
+			return nil, fmt.Errorf("missing atlas region")
+		}
+		if url == "" {
+			url = defaultAtlasURL
+		}
+		a.connector = newAtlas(ctx, url, c.Organization, c.ClientID, c.ClientSecret, client)
+	}
+
+	return a, nil
+}
+
+// Start pulls the usage now and then every interval, until the context is
+// done or the collector is stopped
+func (c *Collector) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.collect(ctx, time.Now().UTC())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops pulling the usage, it can be called more than once
+func (c *Collector) Stop(ctx context.Context) {
+	if c.cancel == nil {
+		return
+	}
+
+	c.cancel()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+}
+
+// collect pulls the usage of every account up to the delay before now. The
+// accounts whose usage can't be pulled are pulled again by the next run
+func (c *Collector) collect(ctx context.Context, now time.Time) {
+	to := now.Add(-c.delay).Truncate(time.Hour)
+
+	for _, a := range c.accounts {
+		from := c.resume(a, now)
+		if !to.After(from) {
+			continue
+		}
+
+		usage, err := a.connector.Usage(ctx, from, to)
+		if err != nil {
+			c.logger.Error("failed pulling the usage", "vendor", a.vendor, "account", a.name, "error", err)
+			continue
+		}
+
+		n, err := c.add(ctx, a, usage)
+		if err != nil {
+			c.logger.Error("failed storing the usage", "vendor", a.vendor, "account", a.name, "error", err)
+		}
+		c.logger.Debug("pulled the usage", "vendor", a.vendor, "account", a.name, "from", from, "to", to, "samples", n)
+	}
+}
+
+// resume returns the start of the usage to pull: the end of the usage stored
+// by the previous runs, including the ones before a restart, or the lookback
+func (c *Collector) resume(a *account, now time.Time) time.Time {
+	if !a.pulled.IsZero() {
+		return a.pulled
+	}
+
+	from := now.Add(-c.lookback).Truncate(time.Hour)
+	for _, s := range c.store.Select(from, now, func(s *store.Sample) bool {
+		return s.Labels[v1.SourceLabel] == a.vendor && s.Labels[v1.AccountLabel] == a.name
+	}) {
+		if s.Time.After(from) {
+			from = s.Time
+		}
+	}
+
+	a.pulled = from
+	return from
+}
+
+// add stores the emissions of the usage and returns the number of samples.
+// The usage of the regions without a grid intensity is left out
+func (c *Collector) add(ctx context.Context, a *account, usage []Usage) (int, error) {
+	var n int
+	for i := range usage {
+		u := &usage[i]
+
+		provider, region := u.Provider, u.Region
+		if provider == "" {
+			provider = a.provider
+		}
+		if region == "" {
+			region = a.region
+		}
+
+		intensity, err := c.intensity(provider, region)
+		if err != nil {
+			c.logger.Warn("no grid intensity for the usage", "vendor", a.vendor, "account", a.name, "error", err)
+			continue
+		}
+
+		name := u.Name
+		if name == "" {
+			name = a.name
+		}
+
+		sample := store.Sample{
+			Time:     u.Time.UTC(),
+			Provider: provider,
+			Service:  u.Service,
+			Name:     name,
+			Region:   region,
+			Kind:     u.Kind,
+			Labels: u.Labels.
+				With(v1.SourceLabel, a.vendor).
+				With(v1.AccountLabel, a.name),
+			Operational: u.Units * a.energy * intensity,
+			Embodied:    u.Units * a.embodied,
+			Quality:     v1.TierLow,
+
+			GridIntensity: intensity,
+		}
+
+		if err := c.store.Add(ctx, sample); err != nil {
+			return n, err
+		}
+		n++
+
+		if sample.Time.After(a.pulled) {
+			a.pulled = sample.Time
+		}
+	}
+
+	return n, nil
+}
+
+// newJSONRequest returns a request of the APIs of the vendors, with the JSON
+// body if any
+func newJSONRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	var r io.Reader = http.NoBody
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// doJSON sends the request and decodes its response into out, the errors
+// include the start of the body of the failed requests
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response of %s: %w", req.URL.Path, err)
+	}
+
+	return nil
+}
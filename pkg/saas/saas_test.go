@@ -0,0 +1,143 @@
+package saas
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnector returns the usage of the periods in [from, to) and records
+// the ranges it was asked for
+type fakeConnector struct {
+	usage  []Usage
+	err    error
+	ranges [][2]time.Time
+}
+
+func (f *fakeConnector) Usage(ctx context.Context, from, to time.Time) ([]Usage, error) {
+	f.ranges = append(f.ranges, [2]time.Time{from, to})
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var out []Usage
+	for i := range f.usage {
+		if f.usage[i].Time.After(from) && !f.usage[i].Time.After(to) {
+			out = append(out, f.usage[i])
+		}
+	}
+	return out, nil
+}
+
+func TestNew(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := New(context.TODO(), &config.SaaSConfig{
+		Connectors: []config.SaaSConnector{
+			{Vendor: VendorSnowflake, Account: "analytics", URL: "https://org-analytics.snowflakecomputing.com/", Token: "token", Provider: v1.AWS, Region: "eu-west-1"},
+			{Vendor: VendorDatabricks, Account: "lakehouse", URL: "https://dbc-1234.cloud.databricks.com", Token: "token", Warehouse: "abc", Region: "eu-west-1", EnergyPerUnit: 0.1},
+			{Vendor: VendorAtlas, Account: "mongo", Organization: "org", ClientID: "id", ClientSecret: "secret", Region: "europe-west1"},
+		},
+	}, nil, nil)
+	assert.NoError(err)
+	assert.Equal(time.Hour, c.interval)
+	assert.Equal(7*24*time.Hour, c.lookback)
+	assert.Len(c.accounts, 3)
+
+	assert.Equal("https://org-analytics.snowflakecomputing.com", c.accounts[0].connector.(*snowflake).url)
+	assert.InDelta(0.0264, c.accounts[0].energy, 1e-9)
+	assert.InDelta(2.88, c.accounts[0].embodied, 1e-9)
+	assert.InDelta(0.1, c.accounts[1].energy, 1e-9)
+	assert.Equal(defaultAtlasURL, c.accounts[2].connector.(*atlas).url)
+
+	for _, connector := range []config.SaaSConnector{
+		{Vendor: "salesforce", Account: "crm"},
+		{Vendor: VendorSnowflake, URL: "https://org.snowflakecomputing.com", Token: "token", Provider: v1.AWS, Region: "eu-west-1"},
+		{Vendor: VendorSnowflake, Account: "analytics", Token: "token", Provider: v1.AWS, Region: "eu-west-1"},
+		{Vendor: VendorSnowflake, Account: "analytics", URL: "https://org.snowflakecomputing.com", Provider: v1.AWS, Region: "eu-west-1"},
+		{Vendor: VendorSnowflake, Account: "analytics", URL: "https://org.snowflakecomputing.com", Token: "token"},
+		{Vendor: VendorSnowflake, Account: "analytics", URL: "https://org.snowflakecomputing.com", Token: "token", Provider: v1.AWS, Region: "eu-west-1", EnergyPerUnit: -1},
+		{Vendor: VendorDatabricks, Account: "lakehouse", URL: "https://dbc-1234.cloud.databricks.com", Token: "token", Region: "eu-west-1"},
+		{Vendor: VendorAtlas, Account: "mongo", ClientID: "id", ClientSecret: "secret", Region: "europe-west1"},
+		{Vendor: VendorAtlas, Account: "mongo", Organization: "org", Region: "europe-west1"},
+	} {
+		_, err := New(context.TODO(), &config.SaaSConfig{Connectors: []config.SaaSConnector{connector}}, nil, nil)
+		assert.Error(err, connector)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.TODO()
+
+	s, err := store.New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	now := time.Date(2024, 1, 31, 12, 30, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return time.Date(2024, 1, 31, h, 0, 0, 0, time.UTC) }
+
+	connector := &fakeConnector{
+		usage: []Usage{
+			{Time: hour(3), Service: "warehouse", Name: "ANALYTICS", Units: 2},
+			{Time: hour(4), Service: "warehouse", Name: "ANALYTICS", Units: 1},
+			// the region of the vendor has no grid intensity
+			{Time: hour(4), Region: "moon-1", Service: "warehouse", Name: "LOADING", Units: 1},
+			// reported after the delay
+			{Time: hour(9), Service: "warehouse", Name: "ANALYTICS", Units: 1},
+		},
+	}
+
+	c := &Collector{
+		accounts: []*account{
+			{vendor: VendorSnowflake, name: "analytics", provider: v1.AWS, region: "eu-west-1", energy: 0.5, embodied: 2, connector: connector},
+			{vendor: VendorDatabricks, name: "lakehouse", provider: v1.AWS, region: "eu-west-1", connector: &fakeConnector{err: errors.New("unauthorized")}},
+		},
+		lookback: 12 * time.Hour,
+		delay:    4 * time.Hour,
+		store:    s,
+		intensity: func(provider v1.Provider, region string) (float64, error) {
+			if region != "eu-west-1" {
+				return 0, errors.New("unknown region")
+			}
+			return 100, nil
+		},
+		logger: log.FromContext(ctx),
+	}
+
+	c.collect(ctx, now)
+	assert.Equal([][2]time.Time{{hour(0), hour(8)}}, connector.ranges)
+
+	samples := s.Select(time.Time{}, now, nil)
+	assert.Equal([]store.Sample{
+		{
+			Time: hour(3), Provider: v1.AWS, Service: "warehouse", Name: "ANALYTICS", Region: "eu-west-1",
+			Labels:      v1.Labels{v1.SourceLabel: VendorSnowflake, v1.AccountLabel: "analytics"},
+			Operational: 100, Embodied: 4, Quality: v1.TierLow,
+			GridIntensity: 100,
+		},
+		{
+			Time: hour(4), Provider: v1.AWS, Service: "warehouse", Name: "ANALYTICS", Region: "eu-west-1",
+			Labels:      v1.Labels{v1.SourceLabel: VendorSnowflake, v1.AccountLabel: "analytics"},
+			Operational: 50, Embodied: 2, Quality: v1.TierLow,
+			GridIntensity: 100,
+		},
+	}, samples)
+
+	// the next run pulls from the end of the stored usage
+	c.collect(ctx, now.Add(2*time.Hour))
+	assert.Equal([2]time.Time{hour(4), hour(10)}, connector.ranges[1])
+	assert.Len(s.Select(time.Time{}, now.Add(2*time.Hour), nil), 3)
+
+	// a restarted collector resumes from the stored usage
+	restarted := &fakeConnector{}
+	c.accounts = []*account{{vendor: VendorSnowflake, name: "analytics", connector: restarted}}
+	c.collect(ctx, now.Add(2*time.Hour))
+	assert.Equal([][2]time.Time{{hour(9), hour(10)}}, restarted.ranges)
+}
@@ -0,0 +1,133 @@
+package saas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// The warehouse credits of the account, compute and cloud services, by hour
+const snowflakeQuery = `SELECT TO_VARCHAR(end_time, 'YYYY-MM-DD"T"HH24:MI:SSTZH:TZM'), warehouse_name, credits_used
+FROM snowflake.account_usage.warehouse_metering_history
+WHERE start_time >= TO_TIMESTAMP_TZ(?) AND end_time <= TO_TIMESTAMP_TZ(?)`
+
+// How often a running statement is polled
+var pollInterval = time.Second
+
+// snowflake reads the credits of the warehouses with the SQL API
+type snowflake struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// snowflakeResponse is the part of the responses of the SQL API read
+type snowflakeResponse struct {
+	Message           string     `json:"message"`
+	StatementHandle   string     `json:"statementHandle"`
+	Data              [][]string `json:"data"`
+	ResultSetMetaData struct {
+		PartitionInfo []json.RawMessage `json:"partitionInfo"`
+	} `json:"resultSetMetaData"`
+}
+
+// Usage returns the credits of the warehouses by hour
+func (s *snowflake) Usage(ctx context.Context, from, to time.Time) ([]Usage, error) {
+	body, err := json.Marshal(map[string]any{
+		"statement": snowflakeQuery,
+		"timeout":   300,
+		"bindings": map[string]any{
+			"1": map[string]string{"type": "TEXT", "value": from.UTC().Format(time.RFC3339)},
+			"2": map[string]string{"type": "TEXT", "value": to.UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp snowflakeResponse
+	status, err := s.do(ctx, http.MethodPost, "/api/v2/statements", body, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// the statement is still running
+	for status == http.StatusAccepted {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		handle := resp.StatementHandle
+		resp = snowflakeResponse{}
+		status, err = s.do(ctx, http.MethodGet, "/api/v2/statements/"+url.PathEscape(handle), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows := resp.Data
+	for i := 1; i < len(resp.ResultSetMetaData.PartitionInfo); i++ {
+		var partition snowflakeResponse
+		path := "/api/v2/statements/" + url.PathEscape(resp.StatementHandle) + "?partition=" + strconv.Itoa(i)
+		if _, err := s.do(ctx, http.MethodGet, path, nil, &partition); err != nil {
+			return nil, err
+		}
+		rows = append(rows, partition.Data...)
+	}
+
+	usage := make([]Usage, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 3 {
+			return nil, fmt.Errorf("invalid snowflake row of %d columns", len(row))
+		}
+
+		end, err := time.Parse(time.RFC3339, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid snowflake end time %q", row[0])
+		}
+		credits, err := strconv.ParseFloat(row[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snowflake credits %q", row[2])
+		}
+
+		usage = append(usage, Usage{
+			Time:    end,
+			Service: "warehouse",
+			Name:    row[1],
+			Units:   credits,
+		})
+	}
+
+	return usage, nil
+}
+
+// do sends a request to the SQL API and decodes its response into out
+func (s *snowflake) do(ctx context.Context, method, path string, body []byte, out *snowflakeResponse) (int, error) {
+	req, err := newJSONRequest(ctx, method, s.url+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("X-Snowflake-Authorization-Token-Type", "PROGRAMMATIC_ACCESS_TOKEN")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("invalid snowflake response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return 0, fmt.Errorf("snowflake statement failed (%s): %s", resp.Status, out.Message)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,61 @@
+package saas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnowflakeUsage(t *testing.T) {
+	assert := require.New(t)
+	pollInterval = time.Millisecond
+
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("Bearer token", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/statements":
+			var body struct {
+				Bindings map[string]struct{ Value string } `json:"bindings"`
+			}
+			assert.NoError(json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal("2024-01-31T00:00:00Z", body.Bindings["1"].Value)
+			assert.Equal("2024-01-31T08:00:00Z", body.Bindings["2"].Value)
+
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"statementHandle": "01b2", "message": "Asynchronous execution in progress."}`))
+		case r.URL.Path == "/api/v2/statements/01b2" && r.URL.Query().Get("partition") == "1":
+			_, _ = w.Write([]byte(`{"data": [["2024-01-31T02:00:00+00:00", "LOADING", "0.5"]]}`))
+		case r.URL.Path == "/api/v2/statements/01b2":
+			polls++
+			_, _ = w.Write([]byte(`{
+				"statementHandle": "01b2",
+				"resultSetMetaData": {"partitionInfo": [{"rowCount": 1}, {"rowCount": 1}]},
+				"data": [["2024-01-31T01:00:00-08:00", "ANALYTICS", "1.25"]]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	s := &snowflake{url: server.URL, token: "token", client: server.Client()}
+	usage, err := s.Usage(context.TODO(), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC))
+	assert.NoError(err)
+	assert.Equal(1, polls)
+	assert.Len(usage, 2)
+	assert.True(time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC).Equal(usage[0].Time))
+	assert.Equal(Usage{Time: usage[0].Time, Service: "warehouse", Name: "ANALYTICS", Units: 1.25}, usage[0])
+	assert.Equal("LOADING", usage[1].Name)
+
+	s.url += "/missing"
+	_, err = s.Usage(context.TODO(), time.Time{}, time.Now())
+	assert.ErrorContains(err, "not found")
+}
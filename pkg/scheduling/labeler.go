@@ -0,0 +1,202 @@
+// Package scheduling exposes the carbon intensity of the Kubernetes nodes,
+// so that the scheduler, the cluster autoscaler and the descheduler can
+// prefer the greener ones
+package scheduling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/kube"
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+var nodesResource = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+
+const (
+	// The label set to the grid intensity of the region of the node, in
+	// gCO2eq/kWh rounded to an integer so that it can be used with the Gt
+	// and Lt operators of the node affinity
+	IntensityLabel = "aether.re-cinq.com/grid-intensity"
+
+	// The labels of the region of a node
+	regionLabel       = "topology.kubernetes.io/region"
+	legacyRegionLabel = "failure-domain.beta.kubernetes.io/region"
+)
+
+// providerIDs maps the scheme of the node provider IDs to the providers
+var providerIDs = map[string]v1.Provider{
+	"aws":   v1.AWS,
+	"gce":   v1.GCP,
+	"azure": v1.Azure,
+}
+
+// intensityFunc returns the grid intensity of a region in gCO2eq/kWh
+type intensityFunc func(provider v1.Provider, region string) (float64, error)
+
+// NodeLabeler sets the grid intensity of the region of every node as a
+// node label. The intensity comes from the emission factors in use, there
+// is no forecast of it
+type NodeLabeler struct {
+	client dynamic.Interface
+
+	// How often the labels are updated
+	interval time.Duration
+
+	intensity intensityFunc
+	logger    *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewNodeLabeler returns a labeler connected to the cluster set in the
+// config, labeling the nodes with the grid intensity of their region, e.g.
+// the one of calculator.GridIntensity
+func NewNodeLabeler(ctx context.Context, cfg *config.NodeLabelsConfig, intensity func(provider v1.Provider, region string) (float64, error)) (*NodeLabeler, error) {
+	client, err := kube.NewClient(cfg.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNodeLabeler(ctx, client, cfg, intensity), nil
+}
+
+// newNodeLabeler returns a labeler using the client
+func newNodeLabeler(ctx context.Context, client dynamic.Interface, cfg *config.NodeLabelsConfig, intensity intensityFunc) *NodeLabeler {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	return &NodeLabeler{
+		client:    client,
+		interval:  interval,
+		intensity: intensity,
+		logger:    log.FromContext(ctx),
+	}
+}
+
+// Start labels the nodes every interval
+// NOTE: this is not a blocking call
+func (l *NodeLabeler) Start(ctx context.Context) {
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.done = make(chan struct{})
+
+	go func() {
+		defer close(l.done)
+
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+
+		for {
+			// a slow update is cancelled instead of delaying the next ones
+			tick, cancel := context.WithTimeout(ctx, l.interval)
+			if err := l.label(tick); err != nil {
+				l.logger.Error("failed labeling the nodes", "error", err)
+			}
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the running update to complete
+func (l *NodeLabeler) Stop(ctx context.Context) {
+	if l.cancel == nil {
+		return
+	}
+
+	l.cancel()
+
+	select {
+	case <-l.done:
+	case <-ctx.Done():
+	}
+}
+
+// label updates the intensity label of the nodes whose region is known
+func (l *NodeLabeler) label(ctx context.Context) error {
+	list, err := l.client.Resource(nodesResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed listing the nodes: %w", err)
+	}
+
+	for i := range list.Items {
+		node := &list.Items[i]
+
+		provider, region, ok := location(node)
+		if !ok {
+			l.logger.Debug("unknown provider or region of the node", "node", node.GetName())
+			continue
+		}
+
+		intensity, err := l.intensity(provider, region)
+		if err != nil {
+			l.logger.Warn("failed getting the grid intensity of the node", "node", node.GetName(), "error", err)
+			continue
+		}
+
+		value := strconv.Itoa(int(math.Round(intensity)))
+		if node.GetLabels()[IntensityLabel] == value {
+			continue
+		}
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]string{IntensityLabel: value},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := l.client.Resource(nodesResource).Patch(ctx, node.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			l.logger.Error("failed labeling the node", "node", node.GetName(), "error", err)
+			continue
+		}
+
+		l.logger.Debug("node labeled", "node", node.GetName(), "intensity", value)
+	}
+
+	return nil
+}
+
+// location returns the provider and the region of the node
+func location(node *unstructured.Unstructured) (v1.Provider, string, bool) {
+	providerID, _, _ := unstructured.NestedString(node.Object, "spec", "providerID")
+	scheme, _, ok := strings.Cut(providerID, "://")
+	if !ok {
+		return "", "", false
+	}
+
+	provider, ok := providerIDs[scheme]
+	if !ok {
+		return "", "", false
+	}
+
+	region := node.GetLabels()[regionLabel]
+	if region == "" {
+		region = node.GetLabels()[legacyRegionLabel]
+	}
+
+	return provider, region, region != ""
+}
@@ -0,0 +1,64 @@
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newNode(name, providerID string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"providerID": providerID},
+	}}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("Node")
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestLabel(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{nodesResource: "NodeList"},
+		newNode("aws", "aws:///eu-north-1a/i-0123", map[string]string{regionLabel: "eu-north-1"}),
+		newNode("gcp", "gce://project/europe-west4-a/gke-node", map[string]string{legacyRegionLabel: "europe-west4"}),
+		newNode("unknown-region", "aws:///mars-1a/i-0456", map[string]string{regionLabel: "mars-1"}),
+		newNode("bare-metal", "", map[string]string{regionLabel: "eu-north-1"}),
+	)
+
+	intensity := func(provider v1.Provider, region string) (float64, error) {
+		switch {
+		case provider == v1.AWS && region == "eu-north-1":
+			return 8.4, nil
+		case provider == v1.GCP && region == "europe-west4":
+			return 283.6, nil
+		}
+		return 0, errors.New("unknown region")
+	}
+
+	l := newNodeLabeler(ctx, client, &config.NodeLabelsConfig{}, intensity)
+	assert.NoError(l.label(ctx))
+
+	labels := func(name string) map[string]string {
+		node, err := client.Resource(nodesResource).Get(ctx, name, metav1.GetOptions{})
+		assert.NoError(err)
+		return node.GetLabels()
+	}
+
+	assert.Equal(map[string]string{regionLabel: "eu-north-1", IntensityLabel: "8"}, labels("aws"))
+	assert.Equal(map[string]string{legacyRegionLabel: "europe-west4", IntensityLabel: "284"}, labels("gcp"))
+	assert.NotContains(labels("unknown-region"), IntensityLabel)
+	assert.NotContains(labels("bare-metal"), IntensityLabel)
+}
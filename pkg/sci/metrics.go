@@ -0,0 +1,47 @@
+package sci
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// The functional units of the applications over the window
+	applicationUnits = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "application_functional_units",
+			Help: "functional units, e.g. requests, of an application over the functional units window",
+		},
+		[]string{"application", "unit"},
+	)
+
+	// The emissions of the applications per functional unit over the window
+	applicationIntensity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "application_gco2e_per_unit",
+			Help: "co2eq of the instances of an application per functional unit over the functional units window",
+		},
+		[]string{"application", "unit"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		applicationUnits,
+		applicationIntensity,
+	)
+}
+
+// export replaces the exported metrics with the intensities, the emissions
+// per unit are left out when there were no units
+func export(intensities []Intensity) {
+	applicationUnits.Reset()
+	applicationIntensity.Reset()
+
+	for i := range intensities {
+		in := &intensities[i]
+		applicationUnits.WithLabelValues(in.Application, in.Unit).Set(in.Units)
+		if in.Units > 0 {
+			applicationIntensity.WithLabelValues(in.Application, in.Unit).Set(in.GCO2ePerUnit)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package sci divides the emissions of the applications by their functional
+// unit, e.g. their requests, read from the metrics of the applications. It's
+// the R of the Software Carbon Intensity of the Green Software Foundation
+package sci
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The unit of the applications which don't set one
+const defaultUnit = "request"
+
+// sampleSelector returns the stored emissions
+type sampleSelector interface {
+	Select(from, to time.Time, filter func(*store.Sample) bool) []store.Sample
+}
+
+// unit is a validated functional unit
+type unit struct {
+	application string
+	name        string
+	source      source
+}
+
+// Intensity is the emissions of an application per functional unit over a
+// window
+type Intensity struct {
+	Application string    `json:"application"`
+	Unit        string    `json:"unit"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+
+	// The emissions of the instances of the application in gCO2eq
+	Emissions float64 `json:"emissions"`
+
+	// The number of units, e.g. the requests served
+	Units float64 `json:"units"`
+
+	// The emissions per unit, 0 when there were no units
+	GCO2ePerUnit float64 `json:"gco2ePerUnit"`
+}
+
+// Collector queries the throughput of the applications periodically and
+// exports their emissions per functional unit
+type Collector struct {
+	units []unit
+
+	// How often the throughput is queried and how far back it's summed
+	interval time.Duration
+	window   time.Duration
+
+	// The emissions the throughput is joined with
+	store sampleSelector
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	logger *slog.Logger
+}
+
+// New returns a collector joining the throughput of the applications of the
+// config with their stored emissions
+func New(ctx context.Context, cfg *config.FunctionalUnitsConfig, s sampleSelector) (*Collector, error) {
+	units := make([]unit, 0, len(cfg.Units))
+	for i := range cfg.Units {
+		u := &cfg.Units[i]
+		if u.Application == "" {
+			return nil, fmt.Errorf("functional unit %d: missing application", i)
+		}
+
+		src, err := newSource(ctx, u)
+		if err != nil {
+			return nil, fmt.Errorf("functional unit %d: %w", i, err)
+		}
+
+		name := u.Unit
+		if name == "" {
+			name = defaultUnit
+		}
+
+		units = append(units, unit{
+			application: u.Application,
+			name:        name,
+			source:      src,
+		})
+	}
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	return &Collector{
+		units:    units,
+		interval: interval,
+		window:   window,
+		store:    s,
+		logger:   log.FromContext(ctx),
+	}, nil
+}
+
+// Start queries the throughput now and then every interval, until the
+// context is done or the collector is stopped
+func (c *Collector) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			export(c.collect(ctx, time.Now()))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops querying the throughput, it can be called more than once
+func (c *Collector) Stop(ctx context.Context) {
+	if c.cancel == nil {
+		return
+	}
+
+	c.cancel()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+}
+
+// collect joins the throughput of the window ending at the time with the
+// emissions. The applications whose throughput can't be queried are left
+// out until the next run
+func (c *Collector) collect(ctx context.Context, to time.Time) []Intensity {
+	from := to.Add(-c.window)
+
+	emissions := make(map[string]float64)
+	for _, s := range c.store.Select(from, to, nil) {
+		if app := s.Labels[v1.ApplicationLabel]; app != "" {
+			emissions[app] += s.Operational + s.Embodied
+		}
+	}
+
+	intensities := make([]Intensity, 0, len(c.units))
+	for i := range c.units {
+		u := &c.units[i]
+
+		throughput, err := u.source.Throughput(ctx, from, to)
+		if err != nil {
+			c.logger.Error("failed querying the throughput", "application", u.application, "unit", u.name, "error", err)
+			continue
+		}
+
+		intensity := Intensity{
+			Application: u.application,
+			Unit:        u.name,
+			From:        from,
+			To:          to,
+			Emissions:   emissions[u.application],
+			Units:       throughput,
+		}
+		if throughput > 0 {
+			intensity.GCO2ePerUnit = intensity.Emissions / throughput
+		}
+		intensities = append(intensities, intensity)
+	}
+
+	sort.Slice(intensities, func(i, j int) bool {
+		if intensities[i].Application != intensities[j].Application {
+			return intensities[i].Application < intensities[j].Application
+		}
+		return intensities[i].Unit < intensities[j].Unit
+	})
+
+	return intensities
+}
@@ -0,0 +1,98 @@
+package sci
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore returns the samples in [from, to)
+type fakeStore []store.Sample
+
+func (f fakeStore) Select(from, to time.Time, filter func(*store.Sample) bool) []store.Sample {
+	var out []store.Sample
+	for i := range f {
+		if !f[i].Time.Before(from) && f[i].Time.Before(to) {
+			out = append(out, f[i])
+		}
+	}
+	return out
+}
+
+// fakeSource returns a fixed throughput
+type fakeSource struct {
+	throughput float64
+	err        error
+}
+
+func (f *fakeSource) Throughput(ctx context.Context, from, to time.Time) (float64, error) {
+	return f.throughput, f.err
+}
+
+func TestNew(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := New(context.TODO(), &config.FunctionalUnitsConfig{
+		Units: []config.FunctionalUnit{
+			{
+				Application: "shop",
+				Prometheus:  config.PrometheusThroughput{URL: "http://prometheus:9090", Query: "sum(increase(http_requests_total[$window]))"},
+			},
+		},
+	}, fakeStore{})
+	assert.NoError(err)
+	assert.Equal(5*time.Minute, c.interval)
+	assert.Equal(time.Hour, c.window)
+	assert.Equal("request", c.units[0].name)
+
+	for _, u := range []config.FunctionalUnit{
+		{Prometheus: config.PrometheusThroughput{URL: "http://prometheus:9090", Query: "up"}},
+		{Application: "shop"},
+		{Application: "shop", Prometheus: config.PrometheusThroughput{Query: "up"}},
+		{Application: "shop", CloudWatch: config.CloudWatchThroughput{MetricName: "RequestCount"}},
+		{
+			Application: "shop",
+			Prometheus:  config.PrometheusThroughput{URL: "http://prometheus:9090", Query: "up"},
+			CloudWatch:  config.CloudWatchThroughput{Namespace: "AWS/ApplicationELB", MetricName: "RequestCount"},
+		},
+	} {
+		_, err := New(context.TODO(), &config.FunctionalUnitsConfig{Units: []config.FunctionalUnit{u}}, fakeStore{})
+		assert.Error(err)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	assert := require.New(t)
+
+	to := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := &Collector{
+		units: []unit{
+			{application: "shop", name: "request", source: &fakeSource{throughput: 1000}},
+			{application: "shop", name: "order", source: &fakeSource{throughput: 0}},
+			{application: "search", name: "request", source: &fakeSource{err: errors.New("unreachable")}},
+		},
+		window: time.Hour,
+		store: fakeStore{
+			{Time: to.Add(-30 * time.Minute), Name: "a", Labels: v1.Labels{"application": "shop"}, Operational: 40, Embodied: 10},
+			{Time: to.Add(-10 * time.Minute), Name: "b", Labels: v1.Labels{"application": "shop"}, Operational: 50},
+			{Time: to.Add(-10 * time.Minute), Name: "c", Labels: v1.Labels{"application": "search"}, Operational: 70},
+			{Time: to.Add(-10 * time.Minute), Name: "d", Operational: 100},
+			// before the window
+			{Time: to.Add(-2 * time.Hour), Name: "a", Labels: v1.Labels{"application": "shop"}, Operational: 40},
+		},
+		logger: log.FromContext(context.TODO()),
+	}
+
+	from := to.Add(-time.Hour)
+	assert.Equal([]Intensity{
+		{Application: "shop", Unit: "order", From: from, To: to, Emissions: 100},
+		{Application: "shop", Unit: "request", From: from, To: to, Emissions: 100, Units: 1000, GCO2ePerUnit: 0.1},
+	}, c.collect(context.TODO(), to))
+}
@@ -0,0 +1,210 @@
+package sci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/re-cinq/aether/pkg/config"
+)
+
+// source returns the throughput of an application in [from, to)
+type source interface {
+	Throughput(ctx context.Context, from, to time.Time) (float64, error)
+}
+
+// newSource returns the source of the throughput configured for the unit
+func newSource(ctx context.Context, u *config.FunctionalUnit) (source, error) {
+	prom, cw := u.Prometheus.Query != "", u.CloudWatch.MetricName != ""
+
+	switch {
+	case prom && cw:
+		return nil, fmt.Errorf("both a prometheus query and a cloudwatch metric")
+	case prom:
+		if u.Prometheus.URL == "" {
+			return nil, fmt.Errorf("missing prometheus url")
+		}
+		return &prometheusSource{
+			url:    strings.TrimSuffix(u.Prometheus.URL, "/"),
+			query:  u.Prometheus.Query,
+			client: &http.Client{Timeout: 30 * time.Second},
+		}, nil
+	case cw:
+		if u.CloudWatch.Namespace == "" {
+			return nil, fmt.Errorf("missing cloudwatch namespace")
+		}
+		cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(u.CloudWatch.Region))
+		if err != nil {
+			return nil, err
+		}
+		return newCloudWatchSource(cloudwatch.NewFromConfig(cfg), &u.CloudWatch), nil
+	default:
+		return nil, fmt.Errorf("missing prometheus query or cloudwatch metric")
+	}
+}
+
+// prometheusSource runs an instant query at the end of the window
+type prometheusSource struct {
+	url    string
+	query  string
+	client *http.Client
+}
+
+// queryResponse is the part of the response of /api/v1/query read
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// Throughput returns the sum of the samples the query returns, the query
+// is given the window in seconds as $window
+func (s *prometheusSource) Throughput(ctx context.Context, from, to time.Time) (float64, error) {
+	window := strconv.FormatInt(int64(to.Sub(from).Seconds()), 10) + "s"
+
+	params := url.Values{}
+	params.Set("query", strings.ReplaceAll(s.query, "$window", window))
+	params.Set("time", strconv.FormatInt(to.Unix(), 10))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/api/v1/query?"+params.Encode(), http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var r queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return 0, fmt.Errorf("invalid prometheus response: %w", err)
+	}
+	if r.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed: %s", r.Error)
+	}
+
+	switch r.Data.ResultType {
+	case "scalar":
+		var sample []any
+		if err := json.Unmarshal(r.Data.Result, &sample); err != nil {
+			return 0, err
+		}
+		return sampleValue(sample)
+	case "vector":
+		var series []struct {
+			Value []any `json:"value"`
+		}
+		if err := json.Unmarshal(r.Data.Result, &series); err != nil {
+			return 0, err
+		}
+
+		var sum float64
+		for i := range series {
+			v, err := sampleValue(series[i].Value)
+			if err != nil {
+				return 0, err
+			}
+			sum += v
+		}
+		return sum, nil
+	default:
+		return 0, fmt.Errorf("unsupported prometheus result type %q", r.Data.ResultType)
+	}
+}
+
+// sampleValue returns the value of a [timestamp, "value"] sample
+func sampleValue(sample []any) (float64, error) {
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("invalid prometheus sample")
+	}
+	s, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid prometheus sample value")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// metricDataGetter gets the values of CloudWatch metrics
+type metricDataGetter interface {
+	GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// cloudWatchSource sums a CloudWatch metric over the window
+type cloudWatchSource struct {
+	client metricDataGetter
+	metric types.Metric
+}
+
+// newCloudWatchSource returns the source of the metric of the config
+func newCloudWatchSource(client metricDataGetter, cfg *config.CloudWatchThroughput) *cloudWatchSource {
+	dimensions := make([]types.Dimension, 0, len(cfg.Dimensions))
+	for _, d := range cfg.Dimensions {
+		dimensions = append(dimensions, types.Dimension{
+			Name:  aws.String(d.Name),
+			Value: aws.String(d.Value),
+		})
+	}
+
+	return &cloudWatchSource{
+		client: client,
+		metric: types.Metric{
+			Namespace:  aws.String(cfg.Namespace),
+			MetricName: aws.String(cfg.MetricName),
+			Dimensions: dimensions,
+		},
+	}
+}
+
+// Throughput returns the sum of the metric over the window, the window is
+// a single period, rounded up to the minute CloudWatch requires
+func (s *cloudWatchSource) Throughput(ctx context.Context, from, to time.Time) (float64, error) {
+	period := int32((to.Sub(from) + time.Minute - 1) / time.Minute * 60)
+	if period < 60 {
+		period = 60
+	}
+
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(from),
+		EndTime:   aws.Time(to),
+		MetricDataQueries: []types.MetricDataQuery{
+			{
+				Id: aws.String("throughput"),
+				MetricStat: &types.MetricStat{
+					Metric: &s.metric,
+					Period: aws.Int32(period),
+					Stat:   aws.String("Sum"),
+				},
+			},
+		},
+	}
+
+	var sum float64
+	paginator := cloudwatch.NewGetMetricDataPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, r := range output.MetricDataResults {
+			for _, v := range r.Values {
+				sum += v
+			}
+		}
+	}
+
+	return sum, nil
+}
@@ -0,0 +1,117 @@
+package sci
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusSource(t *testing.T) {
+	assert := require.New(t)
+
+	to := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		name     string
+		response string
+		expected float64
+		err      bool
+	}{
+		{
+			name:     "vector",
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"route":"/"},"value":[1704110400,"600"]},{"metric":{"route":"/cart"},"value":[1704110400,"400.5"]}]}}`,
+			expected: 1000.5,
+		},
+		{
+			name:     "empty vector",
+			response: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		},
+		{
+			name:     "scalar",
+			response: `{"status":"success","data":{"resultType":"scalar","result":[1704110400,"42"]}}`,
+			expected: 42,
+		},
+		{
+			name:     "error",
+			response: `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			err:      true,
+		},
+		{
+			name:     "matrix",
+			response: `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			err:      true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal("/api/v1/query", r.URL.Path)
+				assert.Equal("sum(increase(http_requests_total[3600s]))", r.URL.Query().Get("query"))
+				assert.Equal("1704110400", r.URL.Query().Get("time"))
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			s := &prometheusSource{
+				url:    server.URL,
+				query:  "sum(increase(http_requests_total[$window]))",
+				client: server.Client(),
+			}
+
+			throughput, err := s.Throughput(context.TODO(), to.Add(-time.Hour), to)
+			if test.err {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(test.expected, throughput)
+		})
+	}
+}
+
+// fakeMetricData returns fixed values and records the input
+type fakeMetricData struct {
+	input  *cloudwatch.GetMetricDataInput
+	values []float64
+}
+
+func (f *fakeMetricData) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	f.input = params
+	return &cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{
+			{Id: aws.String("throughput"), Values: f.values},
+		},
+	}, nil
+}
+
+func TestCloudWatchSource(t *testing.T) {
+	assert := require.New(t)
+
+	client := &fakeMetricData{values: []float64{700, 300}}
+	s := newCloudWatchSource(client, &config.CloudWatchThroughput{
+		Namespace:  "AWS/ApplicationELB",
+		MetricName: "RequestCount",
+		Dimensions: []config.CloudWatchDimension{
+			{Name: "LoadBalancer", Value: "app/shop/50dc6c495c0c9188"},
+		},
+	})
+
+	to := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	throughput, err := s.Throughput(context.TODO(), to.Add(-90*time.Second), to)
+	assert.NoError(err)
+	assert.Equal(1000.0, throughput)
+
+	stat := client.input.MetricDataQueries[0].MetricStat
+	assert.Equal("Sum", *stat.Stat)
+	// rounded up to the minute
+	assert.Equal(int32(120), *stat.Period)
+	assert.Equal("RequestCount", *stat.Metric.MetricName)
+	assert.Equal("LoadBalancer", *stat.Metric.Dimensions[0].Name)
+}
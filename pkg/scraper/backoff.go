@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"context"
+	"time"
+)
+
+// backoff computes the wait time between retries of a failed scrape
+// The wait time doubles on every attempt until it reaches the maximum
+type backoff struct {
+	// The wait time before the first retry
+	initial time.Duration
+
+	// The upper limit of the wait time
+	max time.Duration
+}
+
+// duration returns the wait time for the given attempt, starting from 0
+func (b backoff) duration(attempt int) time.Duration {
+	d := b.initial
+	for i := 0; i < attempt; i++ {
+		d *= 2
+		if b.max > 0 && d >= b.max {
+			return b.max
+		}
+	}
+
+	if b.max > 0 && d > b.max {
+		return b.max
+	}
+
+	return d
+}
+
+// retry runs the function until it succeeds, the maximum amount of attempts
+// is reached or the context is canceled, waiting between every attempt.
+// The error of the last attempt is returned
+func retry(ctx context.Context, attempts int, b backoff, fn func(context.Context) error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		// no need to wait after the last attempt
+		if attempt == attempts-1 {
+			break
+		}
+
+		timer := time.NewTimer(b.duration(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffDuration(t *testing.T) {
+	assert := require.New(t)
+
+	b := backoff{
+		initial: time.Second,
+		max:     5 * time.Second,
+	}
+
+	assert.Equal(time.Second, b.duration(0))
+	assert.Equal(2*time.Second, b.duration(1))
+	assert.Equal(4*time.Second, b.duration(2))
+	assert.Equal(5*time.Second, b.duration(3))
+	assert.Equal(5*time.Second, b.duration(10))
+}
+
+func TestRetry(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	b := backoff{initial: time.Millisecond, max: time.Millisecond}
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		calls := 0
+		err := retry(ctx, 3, b, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		assert.NoError(err)
+		assert.Equal(3, calls)
+	})
+
+	t.Run("returns the last error", func(t *testing.T) {
+		calls := 0
+		err := retry(ctx, 2, b, func(context.Context) error {
+			calls++
+			return errors.New("failed")
+		})
+		assert.EqualError(err, "failed")
+		assert.Equal(2, calls)
+	})
+
+	t.Run("stops when the context is canceled", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		calls := 0
+		err := retry(cancelCtx, 5, backoff{initial: time.Hour}, func(context.Context) error {
+			calls++
+			cancel()
+			return errors.New("failed")
+		})
+		assert.Error(err)
+		assert.Equal(1, calls)
+	})
+}
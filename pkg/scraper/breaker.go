@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitState is the state of a circuit breaker
+type circuitState int
+
+const (
+	// The account is healthy and scraped at every interval
+	circuitClosed circuitState = iota
+
+	// The cooldown expired and a single trial scrape is allowed
+	circuitHalfOpen
+
+	// The account failed too many times and scraping is paused
+	circuitOpen
+)
+
+// Returns the circuit state as string
+func (s circuitState) String() string {
+	switch s {
+	case circuitHalfOpen:
+		return "half-open"
+	case circuitOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker stops scraping an account after a number of consecutive
+// failures, so that a broken provider is not hammered at full rate
+type circuitBreaker struct {
+	// Amount of consecutive failures before opening the circuit
+	threshold int
+
+	// How long the circuit stays open
+	cooldown time.Duration
+
+	// Current state
+	state    circuitState
+	failures int
+	openedAt time.Time
+
+	// Used to mock the time in the tests
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// newCircuitBreaker returns a closed circuit breaker
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     circuitClosed,
+		now:       time.Now,
+	}
+}
+
+// allow returns whether a scrape can be run
+// When the cooldown of an open circuit expired, the circuit becomes half-open
+// and a single trial scrape is allowed
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.state = circuitHalfOpen
+		return true
+	default:
+		return true
+	}
+}
+
+// success closes the circuit and resets the failures
+func (cb *circuitBreaker) success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = circuitClosed
+	cb.failures = 0
+}
+
+// failure records a failed scrape and opens the circuit if the threshold
+// was reached or the trial scrape of an half-open circuit failed
+func (cb *circuitBreaker) failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.failures >= cb.threshold {
+		cb.state = circuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// current returns the current state of the circuit
+func (cb *circuitBreaker) current() circuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state
+}
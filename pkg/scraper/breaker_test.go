@@ -0,0 +1,47 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cb := newCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	// closed by default
+	assert.True(cb.allow())
+	assert.Equal(circuitClosed, cb.current())
+
+	// opens once the threshold is reached
+	cb.failure()
+	assert.Equal(circuitClosed, cb.current())
+	cb.failure()
+	assert.Equal(circuitOpen, cb.current())
+	assert.False(cb.allow())
+
+	// half-open once the cooldown expired
+	now = now.Add(time.Minute)
+	assert.True(cb.allow())
+	assert.Equal(circuitHalfOpen, cb.current())
+
+	// a failed trial opens it again
+	cb.failure()
+	assert.Equal(circuitOpen, cb.current())
+	assert.False(cb.allow())
+
+	// a successful trial closes it
+	now = now.Add(time.Minute)
+	assert.True(cb.allow())
+	cb.success()
+	assert.Equal(circuitClosed, cb.current())
+
+	// the failures are reset after a success
+	cb.failure()
+	assert.Equal(circuitClosed, cb.current())
+}
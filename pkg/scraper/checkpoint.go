@@ -0,0 +1,128 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// checkpoints keeps track of the end of the last successfully scraped
+// window of every account, and persists it to a file so that the missed
+// intervals can be collected after a restart
+type checkpoints struct {
+	// Where the checkpoints are persisted, if empty they are only kept in memory
+	path string
+
+	// key is provider/account
+	last map[string]time.Time
+
+	mu sync.Mutex
+}
+
+// newCheckpoints loads the checkpoints from the file
+// A missing file is not an error, as it's the case on the first start
+func newCheckpoints(path string) (*checkpoints, error) {
+	c := &checkpoints{
+		path: path,
+		last: make(map[string]time.Time),
+	}
+
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+
+	if err := json.Unmarshal(data, &c.last); err != nil {
+		return c, fmt.Errorf("failed parsing checkpoints file %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// checkpointKey returns the key of the scraper in the checkpoints
+func checkpointKey(s v1.Scraper) string {
+	return jobKey(s.Provider(), s.Account())
+}
+
+// get returns the end of the last successfully scraped window
+func (c *checkpoints) get(key string) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.last[key]
+	return t, ok
+}
+
+// set stores the end of the last successfully scraped window and persists it
+func (c *checkpoints) set(key string, t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// never move the checkpoint backwards
+	if last, ok := c.last[key]; ok && last.After(t) {
+		return nil
+	}
+	c.last[key] = t
+
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(c.last)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o750); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that a crash does not corrupt the file
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, c.path)
+}
+
+// missedWindows returns the windows of the given duration between the last
+// checkpoint and the start of the current window, bounded by the lookback.
+// The remainder that doesn't fill a whole interval is returned as a shorter
+// window, its emissions are prorated to how long it was observed
+func missedWindows(last time.Time, current v1.Window, interval, lookback time.Duration) []v1.Window {
+	var windows []v1.Window
+
+	if interval <= 0 || last.IsZero() {
+		return windows
+	}
+
+	start := last
+	if oldest := current.Start.Add(-lookback); start.Before(oldest) {
+		start = oldest
+	}
+
+	end := start
+	for ; !end.Add(interval).After(current.Start); end = end.Add(interval) {
+		windows = append(windows, v1.NewWindow(end.Add(interval), interval))
+	}
+
+	if end.Before(current.Start) {
+		windows = append(windows, v1.Window{Start: end, End: current.Start})
+	}
+
+	return windows
+}
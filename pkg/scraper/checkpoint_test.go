@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissedWindows(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	interval := 5 * time.Minute
+	current := v1.NewWindow(now, interval)
+
+	t.Run("no checkpoint", func(t *testing.T) {
+		assert.Empty(missedWindows(time.Time{}, current, interval, time.Hour))
+	})
+
+	t.Run("no gap", func(t *testing.T) {
+		assert.Empty(missedWindows(current.Start, current, interval, time.Hour))
+	})
+
+	t.Run("gap is collected", func(t *testing.T) {
+		last := now.Add(-20 * time.Minute)
+		windows := missedWindows(last, current, interval, time.Hour)
+		assert.Equal([]v1.Window{
+			v1.NewWindow(now.Add(-15*time.Minute), interval),
+			v1.NewWindow(now.Add(-10*time.Minute), interval),
+			v1.NewWindow(now.Add(-5*time.Minute), interval),
+		}, windows)
+	})
+
+	t.Run("partial interval is collected", func(t *testing.T) {
+		// down for 7 minutes, the last 2 don't fill a whole interval
+		last := current.Start.Add(-7 * time.Minute)
+		windows := missedWindows(last, current, interval, time.Hour)
+		assert.Equal([]v1.Window{
+			{Start: last, End: last.Add(interval)},
+			{Start: last.Add(interval), End: current.Start},
+		}, windows)
+	})
+
+	t.Run("gap is bounded by the lookback", func(t *testing.T) {
+		last := now.Add(-24 * time.Hour)
+		windows := missedWindows(last, current, interval, 10*time.Minute)
+		assert.Len(windows, 2)
+		assert.Equal(current.Start.Add(-10*time.Minute), windows[0].Start)
+	})
+}
+
+func TestCheckpoints(t *testing.T) {
+	assert := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "state", "checkpoints.json")
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	c, err := newCheckpoints(path)
+	assert.NoError(err)
+
+	_, ok := c.get("aws/default")
+	assert.False(ok)
+
+	assert.NoError(c.set("aws/default", now))
+	// never moves backwards
+	assert.NoError(c.set("aws/default", now.Add(-time.Hour)))
+
+	// reload from disk
+	c, err = newCheckpoints(path)
+	assert.NoError(err)
+
+	last, ok := c.get("aws/default")
+	assert.True(ok)
+	assert.True(now.Equal(last))
+}
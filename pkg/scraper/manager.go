@@ -2,45 +2,582 @@ package scraper
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
 	amazon "github.com/re-cinq/aether/pkg/providers/aws"
+	"github.com/re-cinq/aether/pkg/providers/azure"
+	"github.com/re-cinq/aether/pkg/providers/digitalocean"
 	"github.com/re-cinq/aether/pkg/providers/gcp"
+	"github.com/re-cinq/aether/pkg/providers/oci"
+	"github.com/re-cinq/aether/pkg/providers/scaleway"
+	"github.com/re-cinq/aether/pkg/providers/util"
 	v1 "github.com/re-cinq/aether/pkg/types/v1"
 )
 
+// scraperFactory creates the scraper of a provider account
+type scraperFactory func(context.Context, *bus.Bus, *config.Account) (v1.Scraper, error)
+
+// factories contains the scraper factory of every supported provider
+var factories = map[v1.Provider]scraperFactory{
+	v1.AWS:          amazon.NewScraper,
+	v1.GCP:          gcp.NewScraper,
+	v1.Azure:        azure.NewScraper,
+	v1.OCI:          oci.NewScraper,
+	v1.DigitalOcean: digitalocean.NewScraper,
+	v1.Scaleway:     scaleway.NewScraper,
+}
+
+// RegisterFactory adds the scraper factory of a provider implemented outside
+// of the exporter, e.g. by a plugin. It must be called before the manager is
+// started
+func RegisterFactory(provider v1.Provider, f func(context.Context, *bus.Bus, *config.Account) (v1.Scraper, error)) {
+	factories[provider] = f
+}
+
+// ErrAccountNotFound is returned when the account has no running scraper
+var ErrAccountNotFound = errors.New("account is not scraped")
+
+// job is a running scraper and its scheduler
+type job struct {
+	scraper   v1.Scraper
+	scheduler *scheduler
+
+	// The account config the scraper was created with
+	account config.Account
+}
+
+// pending is an account whose scraper failed to be created, e.g. because of
+// broken credentials. Its creation is retried in the background until it
+// succeeds or the account is removed
+type pending struct {
+	provider v1.Provider
+	account  config.Account
+
+	// The failed attempts, when the last one started and its error
+	attempts    int
+	lastAttempt time.Time
+	err         error
+
+	// Cancels the retries
+	cancel context.CancelFunc
+}
+
+// status returns the status of the account, degraded until its scraper is
+// created
+func (p *pending) status() v1.ScrapeStatus {
+	return v1.ScrapeStatus{
+		Provider:            p.provider,
+		Account:             p.account.ID(),
+		Degraded:            true,
+		Initializing:        true,
+		LastAttempt:         p.lastAttempt,
+		ConsecutiveFailures: p.attempts,
+		LastError:           p.err.Error(),
+		LastErrorCode:       v1.Code(p.err),
+	}
+}
+
+// The wait time between the attempts to create the scraper of an account
+var initBackoff = backoff{
+	initial: 30 * time.Second,
+	max:     10 * time.Minute,
+}
+
 // ScraperManager used to handle the various scrapers
+// The scrapers are started and stopped at runtime whenever accounts are
+// added, changed or removed from the config
 type ScrapingManager struct {
-	scrapers []v1.Scraper
+	bus *bus.Bus
+
+	// The running jobs, the key is provider/account
+	jobs map[string]*job
+
+	// The accounts whose scraper failed to be created, the key is
+	// provider/account
+	pending map[string]*pending
+
+	// The accounts whose scraper is being created, the key is
+	// provider/account. A creation is dropped when its account is removed
+	// or changed in the meantime
+	creating map[string]*created
+
+	// The goroutines retrying the pending accounts
+	retries sync.WaitGroup
+
+	// The providers config the jobs were scheduled with
+	providersConfig config.ProvidersConfig
+
+	// The last config the jobs were reconciled with
+	cfg *config.ApplicationConfig
+
+	// The accounts managed outside of the config file, e.g. by the operator
+	external map[v1.Provider][]config.Account
+
+	checkpoints *checkpoints
+
+	// The context the jobs are scheduled with
+	ctx context.Context
+
+	logger *slog.Logger
+
+	mu sync.Mutex
+}
+
+// NewManager returns a ScraperManager scheduling the accounts of the config
+func NewManager(ctx context.Context, b *bus.Bus, cfg *config.ApplicationConfig) *ScrapingManager {
+	// the checkpoints are only persisted when catching up is enabled
+	var path string
+	if cfg.ProvidersConfig.CatchUp.Enabled {
+		path = cfg.ProvidersConfig.CatchUp.StateFile
+	}
+
+	c, err := newCheckpoints(path)
+	if err != nil {
+		log.FromContext(ctx).Error("failed loading checkpoints, missed intervals will not be collected", "error", err)
+	}
+
+	return &ScrapingManager{
+		bus:         b,
+		jobs:        make(map[string]*job),
+		pending:     make(map[string]*pending),
+		creating:    make(map[string]*created),
+		cfg:         cfg,
+		checkpoints: c,
+		logger:      log.FromContext(ctx),
+	}
+}
+
+// Start schedules a scraper for every configured account
+// NOTE: this is not a blocking call
+func (m *ScrapingManager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	cfg := m.cfg
+	m.mu.Unlock()
+
+	m.reconcile(cfg)
+}
+
+// Reload keeps the scrapers in sync with the new config, e.g. once the
+// config file is reloaded
+func (m *ScrapingManager) Reload(cfg *config.ApplicationConfig) {
+	m.reconcile(cfg)
+}
+
+// Stop cancels all the schedulers and stops their scrapers. The config
+// reloads that follow are ignored
+func (m *ScrapingManager) Stop(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = nil
+	for key := range m.jobs {
+		m.stopJob(ctx, key)
+	}
+	for key := range m.pending {
+		m.stopPending(key)
+	}
+	clear(m.creating)
+	m.mu.Unlock()
+
+	m.retries.Wait()
+}
+
+// Status returns the outcome of the last scrapes of every running scraper,
+// and the accounts whose scraper failed to be created, sorted by provider
+// and account
+func (m *ScrapingManager) Status() []v1.ScrapeStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]v1.ScrapeStatus, 0, len(m.jobs)+len(m.pending))
+	for _, j := range m.jobs {
+		statuses = append(statuses, j.scheduler.status())
+	}
+	for _, p := range m.pending {
+		statuses = append(statuses, p.status())
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Provider != statuses[j].Provider {
+			return statuses[i].Provider < statuses[j].Provider
+		}
+		return statuses[i].Account < statuses[j].Account
+	})
+
+	return statuses
+}
+
+// Trigger runs a scrape of the account without waiting for the next interval
+func (m *ScrapingManager) Trigger(provider v1.Provider, account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[jobKey(provider, account)]
+	if !ok {
+		return fmt.Errorf("%w: %s/%s", ErrAccountNotFound, provider, account)
+	}
+
+	if !j.scheduler.Trigger() {
+		m.logger.Debug("scrape already triggered", "provider", provider, "account", account)
+	}
+
+	return nil
+}
+
+// Refresh scrapes the accounts whose last scrape isn't fresh in the pull
+// mode, and waits for their emissions to be calculated until the context is
+// done
+func (m *ScrapingManager) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	schedulers := make([]*scheduler, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		schedulers = append(schedulers, j.scheduler)
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, s := range schedulers {
+		wg.Add(1)
+		go func(s *scheduler) {
+			defer wg.Done()
+			s.Pull(ctx)
+		}(s)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if m.bus == nil {
+		return nil
+	}
+	return m.bus.Wait(ctx)
+}
+
+// FlushCaches drops the data cached by all the scrapers
+func (m *ScrapingManager) FlushCaches() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, j := range m.jobs {
+		j.scraper.Flush()
+	}
+}
+
+// SetExternalAccounts sets the accounts managed outside of the config file,
+// they are scraped along with the configured ones and replace the ones
+// previously set
+func (m *ScrapingManager) SetExternalAccounts(accounts map[v1.Provider][]config.Account) {
+	m.mu.Lock()
+	m.external = accounts
+	cfg := m.cfg
+	m.mu.Unlock()
+
+	// the accounts are scheduled once the manager is started
+	if cfg != nil {
+		m.reconcile(cfg)
+	}
 }
 
-// NewManager returns a configured instance of a ScraperManager
-func NewManager(ctx context.Context, b *bus.Bus) *ScrapingManager {
-	var scrapers []v1.Scraper
+// created is the scraper of an account created by a reconcile
+type created struct {
+	key      string
+	provider v1.Provider
+	account  config.Account
+	scraper  v1.Scraper
+	err      error
+}
 
-	// Add aws
-	scrapers = append(scrapers, amazon.SetupScrapers(ctx, b)...)
-	// Add GCP
-	scrapers = append(scrapers, gcp.SetupScrapers(ctx, b)...)
+// reconcile starts the scrapers of new accounts, stops the ones of removed
+// accounts and restarts the ones whose config changed. The scrapers are
+// created without holding the lock, so that a slow or hung account API
+// doesn't hold up the status, the triggers or the other accounts
+func (m *ScrapingManager) reconcile(cfg *config.ApplicationConfig) {
+	ctx, results := m.plan(cfg)
+	if len(results) == 0 {
+		return
+	}
 
-	return &ScrapingManager{scrapers}
+	// create the scrapers of the new or changed jobs concurrently, so that
+	// a slow or broken account doesn't hold up the other ones
+	var wg sync.WaitGroup
+	for _, r := range results {
+		wg.Add(1)
+		go func(r *created) {
+			defer wg.Done()
+			r.scraper, r.err = m.create(ctx, r.provider, &r.account)
+		}(r)
+	}
+	wg.Wait()
+
+	m.install(ctx, results)
 }
 
-// Start iterates through each scraper and starts them
-// NOTE: a scraper should not have a blocking call to start
-func (m ScrapingManager) Start(ctx context.Context) {
-	for _, scraper := range m.scrapers {
-		if scraper != nil {
-			scraper.Start(ctx)
+// plan stops the jobs of the removed or changed accounts and returns the
+// ones of the new or changed accounts to create, along with the context
+// they're scheduled with
+func (m *ScrapingManager) plan(cfg *config.ApplicationConfig) (context.Context, []*created) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the manager was not started or it was stopped
+	if m.ctx == nil {
+		return nil, nil
+	}
+	m.cfg = cfg
+
+	// update the settings shared by all the scrapers
+	util.SetWorkers(cfg.ProvidersConfig.Workers)
+	util.SetGlobalRateLimit(cfg.ProvidersConfig.RateLimit)
+	for provider := range factories {
+		util.SetRateLimits(provider, cfg.Providers[provider].RateLimits)
+		util.SetQuotas(provider, cfg.Providers[provider].Quotas)
+		util.SetGranularity(provider, cfg.Providers[provider].Granularity)
+	}
+	if err := util.SetSpecs(cfg.ProvidersConfig.Specs); err != nil {
+		m.logger.Warn("failed loading the instance type specs, they are fetched again", "error", err)
+	}
+
+	// a change of the scheduling settings affects all the jobs
+	if schedulingChanged(&m.providersConfig, &cfg.ProvidersConfig) {
+		for key := range m.jobs {
+			m.stopJob(m.ctx, key)
 		}
+		m.providersConfig = cfg.ProvidersConfig
+	}
+
+	// only the accounts assigned to this replica are scraped
+	sh, err := newShard(&cfg.Sharding)
+	if err != nil {
+		m.logger.Error("invalid sharding config, keeping the current scrapers", "error", err)
+		return nil, nil
+	}
+
+	// build the desired state
+	desired := make(map[string]config.Account)
+	providers := make(map[string]v1.Provider)
+	accounts := make(map[v1.Provider][]config.Account)
+	for provider, p := range cfg.Providers {
+		accounts[provider] = append(accounts[provider], p.Accounts...)
 	}
+	for provider, external := range m.external {
+		accounts[provider] = append(accounts[provider], external...)
+	}
+
+	for provider, list := range accounts {
+		for _, account := range list {
+			key := jobKey(provider, account.ID())
+			if !sh.owns(key) {
+				continue
+			}
+			if _, exists := desired[key]; exists {
+				m.logger.Warn("duplicated account, make sure the account names are unique", "provider", provider, "account", account.ID())
+				continue
+			}
+			desired[key] = account
+			providers[key] = provider
+		}
+	}
+
+	// stop the removed or changed jobs
+	for key, j := range m.jobs {
+		account, ok := desired[key]
+		if !ok || !reflect.DeepEqual(account, j.account) {
+			m.stopJob(m.ctx, key)
+		}
+	}
+	for key, p := range m.pending {
+		account, ok := desired[key]
+		if !ok || !reflect.DeepEqual(account, p.account) {
+			m.stopPending(key)
+		}
+	}
+	for key, c := range m.creating {
+		account, ok := desired[key]
+		if !ok || !reflect.DeepEqual(account, c.account) {
+			delete(m.creating, key)
+		}
+	}
+
+	var results []*created
+	for key, account := range desired {
+		_, running := m.jobs[key]
+		_, retried := m.pending[key]
+		_, creating := m.creating[key]
+		if !running && !retried && !creating {
+			r := &created{key: key, provider: providers[key], account: account}
+			m.creating[key] = r
+			results = append(results, r)
+		}
+	}
+
+	return m.ctx, results
 }
 
-// Stop iterates through scrapers and stops them
-func (m ScrapingManager) Stop(ctx context.Context) {
-	for _, scraper := range m.scrapers {
-		if scraper != nil {
-			scraper.Stop(ctx)
+// install schedules the scrapers created, the accounts which failed are
+// retried in the background. The ones whose account was removed or changed
+// in the meantime, or whose manager was stopped, are stopped
+func (m *ScrapingManager) install(ctx context.Context, results []*created) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range results {
+		if m.creating[r.key] != r {
+			if r.scraper != nil {
+				r.scraper.Stop(ctx)
+			}
+			continue
+		}
+		delete(m.creating, r.key)
+
+		if r.err != nil {
+			m.logger.Error("failed starting scraper, retrying in the background", "provider", r.provider, "account", r.account.ID(), "error", r.err)
+			m.retry(r.key, r.provider, &r.account, r.err)
+			continue
 		}
+		m.startJob(ctx, r.provider, &r.account, r.scraper)
 	}
 }
+
+// create creates the scraper of the account
+func (m *ScrapingManager) create(ctx context.Context, provider v1.Provider, account *config.Account) (v1.Scraper, error) {
+	factory, ok := factories[provider]
+	if !ok {
+		return nil, fmt.Errorf("provider %s is not supported", provider)
+	}
+
+	return factory(ctx, m.bus, account)
+}
+
+// retry creates the scraper of the account in the background, waiting
+// longer after every failed attempt, and schedules it once created
+func (m *ScrapingManager) retry(key string, provider v1.Provider, account *config.Account, err error) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	p := &pending{
+		provider:    provider,
+		account:     *account,
+		attempts:    1,
+		lastAttempt: time.Now().UTC(),
+		err:         err,
+		cancel:      cancel,
+	}
+	m.pending[key] = p
+
+	initFailures.WithLabelValues(provider.String(), account.ID()).Inc()
+	providerDegraded.WithLabelValues(provider.String(), account.ID()).Set(1)
+
+	wait := initBackoff
+
+	m.retries.Add(1)
+	go func() {
+		defer m.retries.Done()
+
+		for {
+			timer := time.NewTimer(wait.duration(p.attempts - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			started := time.Now().UTC()
+			s, err := m.create(ctx, provider, &p.account)
+
+			m.mu.Lock()
+			// the account was removed or changed in the meantime
+			if m.pending[key] != p {
+				m.mu.Unlock()
+				if s != nil {
+					s.Stop(ctx)
+				}
+				return
+			}
+
+			if err != nil {
+				p.attempts++
+				p.lastAttempt = started
+				p.err = err
+				m.mu.Unlock()
+
+				initFailures.WithLabelValues(provider.String(), p.account.ID()).Inc()
+				m.logger.Error("failed starting scraper", "provider", provider, "account", p.account.ID(), "attempts", p.attempts, "error", err)
+				continue
+			}
+
+			delete(m.pending, key)
+			cancel()
+			providerDegraded.WithLabelValues(provider.String(), p.account.ID()).Set(0)
+			m.startJob(m.ctx, provider, &p.account, s)
+			m.mu.Unlock()
+			return
+		}
+	}()
+}
+
+// stopPending stops retrying to create the scraper of the account
+func (m *ScrapingManager) stopPending(key string) {
+	p, ok := m.pending[key]
+	if !ok {
+		return
+	}
+
+	p.cancel()
+	delete(m.pending, key)
+	deleteMetrics(p.provider.String(), p.account.ID())
+}
+
+// startJob schedules the scraper of the account
+func (m *ScrapingManager) startJob(ctx context.Context, provider v1.Provider, account *config.Account, s v1.Scraper) {
+	sched := newScheduler(ctx, s, m.bus, &m.providersConfig, m.checkpoints)
+	sched.Schedule(ctx)
+
+	m.jobs[jobKey(provider, account.ID())] = &job{
+		scraper:   s,
+		scheduler: sched,
+		account:   *account,
+	}
+
+	m.logger.Info("scraper started", "provider", provider, "account", account.ID())
+}
+
+// stopJob cancels the scheduler of the job and stops its scraper
+func (m *ScrapingManager) stopJob(ctx context.Context, key string) {
+	j, ok := m.jobs[key]
+	if !ok {
+		return
+	}
+
+	j.scheduler.Cancel()
+	j.scraper.Stop(ctx)
+
+	delete(m.jobs, key)
+	deleteMetrics(j.scraper.Provider().String(), j.scraper.Account())
+
+	m.logger.Info("scraper stopped", "provider", j.scraper.Provider(), "account", j.scraper.Account())
+}
+
+// schedulingChanged returns whether the settings used by the schedulers
+// changed. The rate limits, the workers and the specs cache are applied
+// without restarting the schedulers
+func schedulingChanged(current, updated *config.ProvidersConfig) bool {
+	a, b := *current, *updated
+	a.RateLimit, b.RateLimit = config.RateLimitConfig{}, config.RateLimitConfig{}
+	a.Workers, b.Workers = 0, 0
+	a.Specs, b.Specs = config.SpecsConfig{}, config.SpecsConfig{}
+	return !reflect.DeepEqual(a, b)
+}
+
+// jobKey returns the unique key of a provider account
+func jobKey(provider v1.Provider, account string) string {
+	return fmt.Sprintf("%s/%s", provider, account)
+}
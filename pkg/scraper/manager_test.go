@@ -0,0 +1,263 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScraper counts the scrapes and whether it was stopped
+type fakeScraper struct {
+	provider v1.Provider
+	account  string
+	err      error
+
+	scrapes int
+	stopped bool
+	mu      sync.Mutex
+}
+
+func (f *fakeScraper) Scrape(ctx context.Context, w v1.Window) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scrapes++
+	if f.err != nil {
+		return 0, f.err
+	}
+	return 2, nil
+}
+
+func (f *fakeScraper) Stop(ctx context.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+}
+
+func (f *fakeScraper) Flush() {}
+
+func (f *fakeScraper) Provider() v1.Provider { return f.provider }
+
+func (f *fakeScraper) Account() string { return f.account }
+
+func (f *fakeScraper) isStopped() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stopped
+}
+
+func TestManagerReconcile(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	created := make(map[string]*fakeScraper)
+	var mu sync.Mutex
+
+	defaults := factories
+	defer func() { factories = defaults }()
+
+	// the scrapers are created concurrently
+	factories = map[v1.Provider]scraperFactory{
+		v1.AWS: func(ctx context.Context, b *bus.Bus, a *config.Account) (v1.Scraper, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			s := &fakeScraper{provider: v1.AWS, account: a.ID()}
+			created[a.ID()] = s
+			return s, nil
+		},
+	}
+
+	cfg := &config.ApplicationConfig{
+		ProvidersConfig: config.ProvidersConfig{Interval: time.Hour},
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {
+				Accounts: []config.Account{
+					{Name: "first", Regions: []string{"eu-north-1"}},
+					{Name: "second", Regions: []string{"eu-north-1"}},
+				},
+			},
+		},
+	}
+
+	// the accounts of the config are scheduled once started
+	m := NewManager(ctx, bus.New(), cfg)
+	assert.Empty(m.jobs)
+	m.Start(ctx)
+	assert.Len(m.jobs, 2)
+	first := created["first"]
+	second := created["second"]
+
+	// remove an account and change the other one
+	cfg.Providers[v1.AWS] = config.Provider{
+		Accounts: []config.Account{
+			{Name: "first", Regions: []string{"eu-north-1", "eu-west-1"}},
+		},
+	}
+	m.Reload(cfg)
+
+	assert.Len(m.jobs, 1)
+	assert.True(second.isStopped())
+	assert.True(first.isStopped())
+	assert.NotSame(first, created["first"])
+
+	// a reload without changes keeps the running jobs
+	current := created["first"]
+	m.Reload(cfg)
+	assert.Same(current, m.jobs[jobKey(v1.AWS, "first")].scraper)
+
+	// the external accounts are scraped along with the configured ones
+	m.SetExternalAccounts(map[v1.Provider][]config.Account{
+		v1.AWS: {{Name: "external", Regions: []string{"eu-north-1"}}},
+	})
+	assert.Len(m.jobs, 2)
+	external := created["external"]
+	assert.Same(current, m.jobs[jobKey(v1.AWS, "first")].scraper)
+
+	// and kept when the config file is reloaded
+	m.Reload(cfg)
+	assert.Same(external, m.jobs[jobKey(v1.AWS, "external")].scraper)
+
+	m.SetExternalAccounts(nil)
+	assert.Len(m.jobs, 1)
+	assert.True(external.isStopped())
+
+	m.Stop(ctx)
+	assert.Empty(m.jobs)
+	assert.True(current.isStopped())
+
+	// a config reload after the shutdown doesn't start any scraper
+	created = make(map[string]*fakeScraper)
+	m.Reload(cfg)
+	assert.Empty(m.jobs)
+	assert.Empty(created)
+}
+
+func TestManagerRetriesFailedScrapers(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	defaults, defaultBackoff := factories, initBackoff
+	defer func() { factories, initBackoff = defaults, defaultBackoff }()
+	initBackoff = backoff{initial: time.Millisecond, max: time.Millisecond}
+
+	// the credentials of the broken account are fixed after a few attempts
+	var attempts atomic.Int32
+	factories = map[v1.Provider]scraperFactory{
+		v1.AWS: func(ctx context.Context, b *bus.Bus, a *config.Account) (v1.Scraper, error) {
+			if a.ID() == "broken" && attempts.Add(1) < 3 {
+				return nil, errors.New("invalid credentials")
+			}
+			return &fakeScraper{provider: v1.AWS, account: a.ID()}, nil
+		},
+		v1.GCP: func(ctx context.Context, b *bus.Bus, a *config.Account) (v1.Scraper, error) {
+			return nil, errors.New("invalid credentials")
+		},
+	}
+
+	cfg := &config.ApplicationConfig{
+		ProvidersConfig: config.ProvidersConfig{Interval: time.Hour},
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {Accounts: []config.Account{{Name: "healthy"}, {Name: "broken"}}},
+			v1.GCP: {Accounts: []config.Account{{Project: "project"}}},
+		},
+	}
+
+	// the healthy accounts are started along with the broken ones
+	m := NewManager(ctx, bus.New(), cfg)
+	m.Start(ctx)
+
+	statuses := m.Status()
+	assert.Len(statuses, 3)
+	assert.Equal("healthy", statuses[1].Account)
+	assert.False(statuses[1].Initializing)
+	assert.Equal("project", statuses[2].Account)
+	assert.True(statuses[2].Initializing)
+	assert.True(statuses[2].Degraded)
+	assert.Equal("invalid credentials", statuses[2].LastError)
+	assert.Equal(v1.CodeInternal, statuses[2].LastErrorCode)
+
+	// the broken account is started once it can be
+	assert.Eventually(func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		_, ok := m.jobs[jobKey(v1.AWS, "broken")]
+		return ok
+	}, time.Second, time.Millisecond)
+
+	// the removed accounts aren't retried anymore
+	delete(cfg.Providers, v1.GCP)
+	m.Reload(cfg)
+	assert.Len(m.Status(), 2)
+	assert.Empty(m.pending)
+
+	m.Stop(ctx)
+}
+
+func TestManagerCreatesWithoutLock(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	defaults := factories
+	defer func() { factories = defaults }()
+
+	// the API of the hung account doesn't answer until released
+	release := make(chan struct{})
+	hung := &fakeScraper{provider: v1.AWS, account: "hung"}
+	factories = map[v1.Provider]scraperFactory{
+		v1.AWS: func(ctx context.Context, b *bus.Bus, a *config.Account) (v1.Scraper, error) {
+			if a.ID() == "hung" {
+				<-release
+				return hung, nil
+			}
+			return &fakeScraper{provider: v1.AWS, account: a.ID()}, nil
+		},
+	}
+
+	cfg := &config.ApplicationConfig{
+		ProvidersConfig: config.ProvidersConfig{Interval: time.Hour},
+		Providers: map[v1.Provider]config.Provider{
+			v1.AWS: {Accounts: []config.Account{{Name: "healthy"}}},
+		},
+	}
+
+	m := NewManager(ctx, bus.New(), cfg)
+	m.Start(ctx)
+
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		m.Reload(&config.ApplicationConfig{
+			ProvidersConfig: cfg.ProvidersConfig,
+			Providers: map[v1.Provider]config.Provider{
+				v1.AWS: {Accounts: []config.Account{{Name: "healthy"}, {Name: "hung"}}},
+			},
+		})
+	}()
+
+	// the other accounts are served while the hung one is created
+	assert.Eventually(func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.creating) == 1
+	}, time.Second, time.Millisecond)
+	assert.Len(m.Status(), 1)
+	assert.NoError(m.Trigger(v1.AWS, "healthy"))
+	m.FlushCaches()
+
+	// the account removed before it's created isn't started
+	m.Reload(cfg)
+	close(release)
+	<-reloaded
+	assert.Len(m.Status(), 1)
+	assert.Empty(m.creating)
+	assert.True(hung.isStopped())
+
+	m.Stop(ctx)
+}
@@ -0,0 +1,100 @@
+package scraper
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// Whether the account is currently degraded (1) or healthy (0)
+	providerDegraded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "cloud_carbon",
+			Name:      "provider_degraded",
+			Help:      "Whether the scraping of a provider account is degraded because its circuit breaker is open",
+		},
+		[]string{"provider", "account"},
+	)
+
+	// The amount of failed scrapes, after all the retries, by the code of
+	// their error
+	scrapeFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cloud_carbon",
+			Name:      "scrape_failures_total",
+			Help:      "The amount of scrapes of a provider account that failed after all the retries, by the code of their error",
+		},
+		[]string{"provider", "account", "code"},
+	)
+
+	// The amount of failed attempts to create the scraper of an account
+	initFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cloud_carbon",
+			Name:      "scraper_init_failures_total",
+			Help:      "The amount of failed attempts to create the scraper of a provider account, e.g. because of broken credentials. The attempts are retried in the background",
+		},
+		[]string{"provider", "account"},
+	)
+
+	// The time of the last successful scrape
+	lastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "cloud_carbon",
+			Name:      "scrape_last_success_timestamp_seconds",
+			Help:      "The unix time of the last successful scrape of a provider account",
+		},
+		[]string{"provider", "account"},
+	)
+
+	// How long the last scrape took, including the retries
+	scrapeDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "cloud_carbon",
+			Name:      "scrape_duration_seconds",
+			Help:      "How long the last scrape of a provider account took, including the retries",
+		},
+		[]string{"provider", "account"},
+	)
+
+	// The amount of instances found by the last successful scrape
+	scrapeInstances = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "cloud_carbon",
+			Name:      "scrape_instances",
+			Help:      "The amount of instances collected by the last successful scrape of a provider account",
+		},
+		[]string{"provider", "account"},
+	)
+
+	// The amount of permissions missing by the last check of the account
+	missingPermissions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "cloud_carbon",
+			Name:      "missing_permissions",
+			Help:      "The amount of permissions the scrapes of a provider account need which were missing by its last check",
+		},
+		[]string{"provider", "account"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		providerDegraded,
+		scrapeFailures,
+		initFailures,
+		lastSuccess,
+		scrapeDuration,
+		scrapeInstances,
+		missingPermissions,
+	)
+}
+
+// deleteMetrics removes the series of a provider account, so that stopped
+// scrapers are not reported anymore
+func deleteMetrics(provider, account string) {
+	providerDegraded.DeleteLabelValues(provider, account)
+	scrapeFailures.DeletePartialMatch(prometheus.Labels{"provider": provider, "account": account})
+	initFailures.DeleteLabelValues(provider, account)
+	lastSuccess.DeleteLabelValues(provider, account)
+	scrapeDuration.DeleteLabelValues(provider, account)
+	scrapeInstances.DeleteLabelValues(provider, account)
+	missingPermissions.DeleteLabelValues(provider, account)
+}
@@ -0,0 +1,412 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The modes of the scraping
+const (
+	// The accounts are scraped every interval
+	ModeSchedule = "schedule"
+
+	// The accounts are scraped when the metrics are, unless they're fresh
+	ModePull = "pull"
+)
+
+// scheduler runs a scraper at every interval, or when pulled in the pull
+// mode. Failed scrapes are retried with an exponential backoff and after too
+// many consecutive failures the circuit breaker pauses the scraping of the
+// account
+type scheduler struct {
+	scraper v1.Scraper
+
+	// Used to publish the completed collections, can be nil
+	bus *bus.Bus
+
+	// Ticker
+	ticker   *time.Ticker
+	interval time.Duration
+
+	// Used to stop the scheduling and wait for it to return
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Used to run a scrape without waiting for the next tick
+	trigger chan struct{}
+
+	// Whether the scrapes are only run when pulled, and how long a scrape
+	// is fresh for
+	pull      bool
+	freshness time.Duration
+
+	// How often the permissions of the account are checked, never when 0
+	checkInterval time.Duration
+
+	// Retry settings
+	attempts int
+	backoff  backoff
+
+	breaker *circuitBreaker
+
+	// Used to collect the intervals that were missed
+	catchUp     config.CatchUpConfig
+	checkpoints *checkpoints
+
+	// The outcome of the last scrapes, and the channel closed once the
+	// next one ran
+	state   v1.ScrapeStatus
+	ran     chan struct{}
+	stateMu sync.RWMutex
+
+	logger *slog.Logger
+}
+
+// newScheduler returns a scheduler configured with the providers config
+func newScheduler(ctx context.Context, s v1.Scraper, b *bus.Bus, cfg *config.ProvidersConfig, c *checkpoints) *scheduler {
+	freshness := cfg.Freshness
+	if freshness <= 0 {
+		freshness = cfg.Interval
+	}
+
+	return &scheduler{
+		scraper:   s,
+		bus:       b,
+		cancel:    func() {},
+		trigger:   make(chan struct{}, 1),
+		pull:      cfg.Mode == ModePull,
+		freshness: freshness,
+		interval:  cfg.Interval,
+		attempts:  cfg.Retry.MaxAttempts,
+		backoff: backoff{
+			initial: cfg.Retry.InitialBackoff,
+			max:     cfg.Retry.MaxBackoff,
+		},
+		breaker: newCircuitBreaker(
+			cfg.CircuitBreaker.FailureThreshold,
+			cfg.CircuitBreaker.Cooldown,
+		),
+		catchUp:       cfg.CatchUp,
+		checkpoints:   c,
+		checkInterval: cfg.CheckInterval,
+		state: v1.ScrapeStatus{
+			Provider: s.Provider(),
+			Account:  s.Account(),
+		},
+		ran: make(chan struct{}),
+		logger: log.FromContext(ctx).With(
+			"provider", s.Provider(),
+			"account", s.Account(),
+		),
+	}
+}
+
+// Schedule runs the scraper once and then at every interval, or only when
+// triggered in the pull mode
+// NOTE: this is not a blocking call
+func (s *scheduler) Schedule(ctx context.Context) {
+	var tick <-chan time.Time
+	if !s.pull {
+		s.ticker = time.NewTicker(s.interval)
+		tick = s.ticker.C
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	// the permissions are checked alongside the scrapes, whatever the mode
+	if checker, ok := s.scraper.(v1.Checker); ok && s.checkInterval > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(s.checkInterval)
+			defer ticker.Stop()
+
+			for {
+				s.check(ctx, checker)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		// we run the scraper once first in order to populate data as quickly as
+		// possible
+		if !s.pull {
+			s.run(ctx)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+				s.run(ctx)
+			case <-s.trigger:
+				s.run(ctx)
+			}
+		}
+	}()
+}
+
+// Cancel stops the scheduling, aborting any running scrape, and waits for it
+// to return so that the scraper can be safely stopped afterwards
+func (s *scheduler) Cancel() {
+	s.cancel()
+
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+
+	s.wg.Wait()
+}
+
+// Trigger requests a scrape without waiting for the next interval
+// It returns false if a triggered scrape is already pending
+func (s *scheduler) Trigger() bool {
+	select {
+	case s.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Pull runs a scrape in the pull mode, unless the last one is fresh, and
+// waits for it until the context is done
+func (s *scheduler) Pull(ctx context.Context) {
+	if !s.pull {
+		return
+	}
+
+	s.stateMu.RLock()
+	fresh := time.Since(s.state.LastAttempt) < s.freshness
+	ran := s.ran
+	s.stateMu.RUnlock()
+
+	if fresh {
+		return
+	}
+
+	// a scrape already running is fresh enough once it ran
+	s.Trigger()
+
+	select {
+	case <-ran:
+	case <-ctx.Done():
+	}
+}
+
+// run executes a single scrape, retrying it on failure.
+// If catching up is enabled, the windows missed since the last successful
+// scrape are collected first
+func (s *scheduler) run(ctx context.Context) {
+	provider := s.scraper.Provider().String()
+	account := s.scraper.Account()
+
+	defer s.done()
+
+	if !s.breaker.allow() {
+		s.logger.Debug("circuit breaker open, skipping scrape")
+		return
+	}
+
+	interval := s.interval
+
+	// the retries should not overlap with the next interval
+	ctx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+
+	current := v1.NewWindow(time.Now().UTC(), interval)
+
+	// the checkpoint is only moved forward if there are no gaps
+	contiguous := true
+
+	// the collection spans the missed windows collected with the current one
+	collection := v1.Collection{
+		Provider: s.scraper.Provider(),
+		Account:  account,
+		Window:   current,
+	}
+
+	if s.catchUp.Enabled {
+		if last, ok := s.checkpoints.get(checkpointKey(s.scraper)); ok {
+			missed := missedWindows(last, current, interval, s.catchUp.MaxLookback)
+			if len(missed) > 0 {
+				s.logger.Info("catching up missed intervals", "from", missed[0].Start, "count", len(missed))
+			}
+
+			for _, w := range missed {
+				instances, err := s.scrape(ctx, w)
+				collection.Instances += instances
+				if err != nil {
+					// the remaining windows are collected at the next interval
+					s.logger.Warn("failed catching up missed interval", "start", w.Start, "error", err)
+					contiguous = false
+					break
+				}
+				s.checkpoint(w)
+
+				if w.Start.Before(collection.Window.Start) {
+					collection.Window.Start = w.Start
+				}
+			}
+		}
+	}
+
+	started := time.Now()
+	instances, err := s.scrape(ctx, current)
+	duration := time.Since(started)
+
+	if err != nil {
+		s.breaker.failure()
+		scrapeFailures.WithLabelValues(provider, account, string(v1.Code(err))).Inc()
+		s.logger.Error("scraping failed", "error", err, "code", v1.Code(err), "circuit", s.breaker.current())
+	} else {
+		s.breaker.success()
+		if contiguous {
+			s.checkpoint(current)
+		}
+	}
+
+	degraded := s.breaker.current() == circuitOpen
+	if degraded {
+		providerDegraded.WithLabelValues(provider, account).Set(1)
+	} else {
+		providerDegraded.WithLabelValues(provider, account).Set(0)
+	}
+
+	s.record(started, duration, instances, degraded, err)
+
+	collection.Instances += instances
+	collection.Err = err
+	s.publish(ctx, &collection)
+}
+
+// publish sends the completed collection on the bus, so that the emissions of
+// its instances can be summed up once calculated
+func (s *scheduler) publish(ctx context.Context, c *v1.Collection) {
+	if s.bus == nil {
+		return
+	}
+
+	if err := s.bus.PublishContext(ctx, &bus.Event{
+		Type: v1.CollectionCompletedEvent,
+		Data: *c,
+	}); err != nil {
+		s.logger.Error("failed publishing the collection", "error", err)
+	}
+}
+
+// scrape collects the window, retrying it on failure, and returns the amount
+// of instances collected
+func (s *scheduler) scrape(ctx context.Context, w v1.Window) (int, error) {
+	var instances int
+	err := retry(ctx, s.attempts, s.backoff, func(ctx context.Context) error {
+		var err error
+		instances, err = s.scraper.Scrape(ctx, w)
+		if err != nil {
+			s.logger.Warn("scraping attempt failed", "error", err)
+		}
+		return err
+	})
+
+	return instances, err
+}
+
+// record updates the status and the metrics with the outcome of a scrape
+func (s *scheduler) record(started time.Time, duration time.Duration, instances int, degraded bool, err error) {
+	provider := s.scraper.Provider().String()
+	account := s.scraper.Account()
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.state.LastAttempt = started
+	s.state.LastDuration = duration
+	s.state.Degraded = degraded
+
+	scrapeDuration.WithLabelValues(provider, account).Set(duration.Seconds())
+
+	if err != nil {
+		s.state.ConsecutiveFailures++
+		s.state.LastError = err.Error()
+		s.state.LastErrorCode = v1.Code(err)
+		return
+	}
+
+	s.state.LastSuccess = started
+	s.state.Instances = instances
+	s.state.ConsecutiveFailures = 0
+	s.state.LastError = ""
+	s.state.LastErrorCode = ""
+
+	lastSuccess.WithLabelValues(provider, account).Set(float64(started.Unix()))
+	scrapeInstances.WithLabelValues(provider, account).Set(float64(instances))
+}
+
+// done releases the pulls waiting for the scrape
+func (s *scheduler) done() {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	close(s.ran)
+	s.ran = make(chan struct{})
+}
+
+// status returns the outcome of the last scrapes
+func (s *scheduler) status() v1.ScrapeStatus {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+
+	return s.state
+}
+
+// check verifies the permissions of the account and keeps their outcome in
+// the status, the missing ones are logged
+func (s *scheduler) check(ctx context.Context, checker v1.Checker) {
+	started := time.Now().UTC()
+	permissions := checker.Check(ctx)
+
+	// the checks aborted by the cancellation aren't reported
+	if ctx.Err() != nil {
+		return
+	}
+
+	missing := 0
+	for _, p := range permissions {
+		if !p.Granted {
+			missing++
+			s.logger.Warn("missing permission", "permission", p.Name, "error", p.Error)
+		}
+	}
+	missingPermissions.WithLabelValues(s.scraper.Provider().String(), s.scraper.Account()).Set(float64(missing))
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.state.LastCheck = started
+	s.state.Permissions = permissions
+}
+
+// checkpoint stores the window as the last successfully collected one
+func (s *scheduler) checkpoint(w v1.Window) {
+	if err := s.checkpoints.set(checkpointKey(s.scraper), w.End); err != nil {
+		s.logger.Warn("failed storing checkpoint", "error", err)
+	}
+}
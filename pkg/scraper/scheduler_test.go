@@ -0,0 +1,133 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerStatus(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	c, err := newCheckpoints("")
+	assert.NoError(err)
+
+	f := &fakeScraper{provider: v1.AWS, account: "test"}
+	s := newScheduler(ctx, f, nil, &config.ProvidersConfig{
+		Interval: time.Hour,
+		Retry:    config.RetryConfig{MaxAttempts: 1},
+	}, c)
+
+	s.run(ctx)
+	status := s.status()
+	assert.Equal(v1.AWS, status.Provider)
+	assert.Equal("test", status.Account)
+	assert.Equal(2, status.Instances)
+	assert.False(status.LastSuccess.IsZero())
+	assert.Zero(status.ConsecutiveFailures)
+	assert.Empty(status.LastError)
+
+	// failures keep the last successful scrape
+	f.err = errors.New("failed")
+	s.run(ctx)
+	s.run(ctx)
+	failed := s.status()
+	assert.Equal(status.LastSuccess, failed.LastSuccess)
+	assert.Equal(2, failed.Instances)
+	assert.Equal(2, failed.ConsecutiveFailures)
+	assert.Equal("failed", failed.LastError)
+
+	f.err = nil
+	s.run(ctx)
+	assert.Zero(s.status().ConsecutiveFailures)
+}
+
+func TestSchedulerCheck(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	f := &checkingScraper{
+		fakeScraper: &fakeScraper{provider: v1.AWS, account: "test"},
+		permissions: []v1.Permission{
+			{Name: "ec2:DescribeInstances", Granted: true},
+			{Name: "cloudwatch:GetMetricData", Error: "access denied"},
+		},
+	}
+	s := newScheduler(ctx, f, nil, &config.ProvidersConfig{
+		Interval:      time.Hour,
+		CheckInterval: time.Hour,
+	}, nil)
+
+	s.check(ctx, f)
+	status := s.status()
+	assert.False(status.LastCheck.IsZero())
+	assert.Equal(f.permissions, status.Permissions)
+	assert.Equal(1.0, testutil.ToFloat64(missingPermissions.WithLabelValues("aws", "test")))
+
+	// the checks aborted by the cancellation keep the previous outcome
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	f.permissions = nil
+	s.check(cancelled, f)
+	assert.Equal(status.LastCheck, s.status().LastCheck)
+	assert.Len(s.status().Permissions, 2)
+}
+
+func TestSchedulerTrigger(t *testing.T) {
+	assert := require.New(t)
+
+	s := newScheduler(context.Background(), &fakeScraper{provider: v1.AWS}, nil, &config.ProvidersConfig{
+		Interval: time.Hour,
+	}, nil)
+
+	assert.True(s.Trigger())
+
+	// only one triggered scrape can be pending
+	assert.False(s.Trigger())
+}
+
+func TestSchedulerPull(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	c, err := newCheckpoints("")
+	assert.NoError(err)
+
+	f := &fakeScraper{provider: v1.AWS, account: "test"}
+	s := newScheduler(ctx, f, nil, &config.ProvidersConfig{
+		Mode:      ModePull,
+		Interval:  time.Hour,
+		Freshness: time.Minute,
+		Retry:     config.RetryConfig{MaxAttempts: 1},
+	}, c)
+
+	// nothing is scraped until pulled
+	s.Schedule(ctx)
+	defer s.Cancel()
+	assert.Zero(s.status().Instances)
+
+	s.Pull(ctx)
+	assert.Equal(2, s.status().Instances)
+	assert.Equal(1, f.scrapes)
+
+	// the fresh scrape is kept
+	s.Pull(ctx)
+	assert.Equal(1, f.scrapes)
+}
+
+// checkingScraper is a scraper which checks its permissions
+type checkingScraper struct {
+	*fakeScraper
+	permissions []v1.Permission
+}
+
+func (c *checkingScraper) Check(ctx context.Context) []v1.Permission {
+	return c.permissions
+}
@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/re-cinq/aether/pkg/config"
+)
+
+// shard decides which accounts are scraped by this replica.
+// The accounts are assigned using rendezvous hashing, so that changing the
+// amount of replicas only moves the accounts of the added or removed replica
+type shard struct {
+	// index of this replica, starting from 0
+	index int
+
+	// total amount of replicas
+	replicas int
+}
+
+// newShard returns the shard of this replica
+// If the index is not set, it's derived from the ordinal suffix of the
+// hostname, as set by a Kubernetes StatefulSet (e.g. aether-2)
+func newShard(cfg *config.ShardingConfig) (shard, error) {
+	if cfg.Replicas <= 1 {
+		return shard{index: 0, replicas: 1}, nil
+	}
+
+	index := cfg.Index
+	if index < 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return shard{}, fmt.Errorf("failed getting the hostname to derive the shard index: %w", err)
+		}
+
+		index, err = ordinal(hostname)
+		if err != nil {
+			return shard{}, err
+		}
+	}
+
+	if index >= cfg.Replicas {
+		return shard{}, fmt.Errorf("shard index %d is out of range for %d replicas", index, cfg.Replicas)
+	}
+
+	return shard{index: index, replicas: cfg.Replicas}, nil
+}
+
+// ordinal returns the number after the last dash of the hostname
+func ordinal(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, fmt.Errorf("hostname %s has no ordinal suffix", hostname)
+	}
+
+	n, err := strconv.Atoi(hostname[i+1:])
+	if err != nil {
+		return 0, fmt.Errorf("hostname %s has no ordinal suffix", hostname)
+	}
+
+	return n, nil
+}
+
+// owner returns the replica the key is assigned to
+func (s shard) owner(key string) int {
+	var (
+		owner int
+		best  uint64
+	)
+
+	for r := 0; r < s.replicas; r++ {
+		h := fnv.New64a()
+		// the error is always nil
+		_, _ = fmt.Fprintf(h, "%d/%s", r, key)
+
+		if w := mix(h.Sum64()); r == 0 || w > best {
+			owner, best = r, w
+		}
+	}
+
+	return owner
+}
+
+// mix spreads the bits of the hash so that similar keys get unrelated weights
+// it's the finalizer of splitmix64
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// owns returns whether the key is assigned to this replica
+func (s shard) owns(key string) bool {
+	if s.replicas <= 1 {
+		return true
+	}
+
+	return s.owner(key) == s.index
+}
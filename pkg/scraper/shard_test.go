@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShard(t *testing.T) {
+	keys := make([]string, 300)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("aws/account-%d", i)
+	}
+
+	t.Run("every key is owned by exactly one replica", func(t *testing.T) {
+		assert := require.New(t)
+		counts := make([]int, 3)
+		for _, key := range keys {
+			owners := 0
+			for i := 0; i < 3; i++ {
+				s, err := newShard(&config.ShardingConfig{Replicas: 3, Index: i})
+				assert.NoError(err)
+				if s.owns(key) {
+					owners++
+					counts[i]++
+				}
+			}
+			assert.Equal(1, owners)
+		}
+
+		// the keys are spread across the replicas
+		for _, c := range counts {
+			assert.Greater(c, 50)
+		}
+	})
+
+	t.Run("adding a replica only moves keys to it", func(t *testing.T) {
+		assert := require.New(t)
+		three := shard{replicas: 3}
+		four := shard{replicas: 4}
+		for _, key := range keys {
+			if owner := four.owner(key); owner != 3 {
+				assert.Equal(three.owner(key), owner)
+			}
+		}
+	})
+
+	t.Run("single replica owns everything", func(t *testing.T) {
+		assert := require.New(t)
+		s, err := newShard(&config.ShardingConfig{})
+		assert.NoError(err)
+		assert.True(s.owns("gcp/project"))
+	})
+
+	t.Run("index out of range", func(t *testing.T) {
+		assert := require.New(t)
+		_, err := newShard(&config.ShardingConfig{Replicas: 2, Index: 2})
+		assert.Error(err)
+	})
+}
+
+func TestOrdinal(t *testing.T) {
+	assert := require.New(t)
+
+	n, err := ordinal("aether-12")
+	assert.NoError(err)
+	assert.Equal(12, n)
+
+	_, err = ordinal("aether")
+	assert.Error(err)
+
+	_, err = ordinal("aether-abc")
+	assert.Error(err)
+}
@@ -0,0 +1,153 @@
+package statement
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// PayloadType is the type of the statements in the envelopes
+const PayloadType = "application/vnd.aether.emissions-statement.v1+json"
+
+// ErrInvalidSignature is returned when an envelope isn't signed by the key
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Envelope is a DSSE envelope of a statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is the signature of an envelope
+type Signature struct {
+	// The hex SHA-256 of the public key
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// Sign returns the statement in an envelope signed with the key
+func Sign(s *Statement, key ed25519.PrivateKey) (*Envelope, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures: []Signature{{
+			KeyID: KeyID(key.Public().(ed25519.PublicKey)),
+			Sig:   ed25519.Sign(key, pae(PayloadType, payload)),
+		}},
+	}, nil
+}
+
+// Verify returns the statement of the envelope if it's signed by the key
+func Verify(e *Envelope, key ed25519.PublicKey) (*Statement, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", e.PayloadType)
+	}
+
+	id := KeyID(key)
+	msg := pae(e.PayloadType, e.Payload)
+
+	for _, sig := range e.Signatures {
+		if sig.KeyID != "" && sig.KeyID != id {
+			continue
+		}
+		if !ed25519.Verify(key, msg, sig.Sig) {
+			continue
+		}
+
+		var s Statement
+		if err := json.Unmarshal(e.Payload, &s); err != nil {
+			return nil, fmt.Errorf("failed decoding the statement: %w", err)
+		}
+		return &s, nil
+	}
+
+	return nil, ErrInvalidSignature
+}
+
+// KeyID returns the identifier of the key in the signatures
+func KeyID(key ed25519.PublicKey) string {
+	h := sha256.Sum256(key)
+	return hex.EncodeToString(h[:])
+}
+
+// pae is the pre-authentication encoding of the payload which is signed
+func pae(payloadType string, payload []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	b.Write(payload)
+	return b.Bytes()
+}
+
+// LoadPrivateKey reads an Ed25519 private key from a PKCS #8 PEM file, as
+// created by: openssl genpkey -algorithm ed25519
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing the private key: %w", err)
+	}
+
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("the private key is not an Ed25519 key")
+	}
+
+	return ed, nil
+}
+
+// LoadPublicKey reads an Ed25519 public key from a PKIX PEM file, as
+// created by: openssl pkey -in key.pem -pubout
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing the public key: %w", err)
+	}
+
+	ed, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("the public key is not an Ed25519 key")
+	}
+
+	return ed, nil
+}
+
+// readPEM returns the content of the first PEM block of the type
+func readPEM(path, blockType string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			return nil, fmt.Errorf("no %s found in %s", blockType, path)
+		}
+		if block.Type == blockType {
+			return block.Bytes, nil
+		}
+	}
+}
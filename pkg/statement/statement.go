@@ -0,0 +1,182 @@
+// Package statement issues signed emissions statements: the emissions of an
+// account over a period, with the datasets and the methodology they are
+// calculated with, to be included in sustainability disclosures.
+// The statements follow the schema in schema.json and are signed in a DSSE
+// envelope (https://github.com/secure-systems-lab/dsse), like the SBOM and
+// provenance attestations
+package statement
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+const (
+	// SchemaID identifies the schema of the statements
+	SchemaID = "https://github.com/re-cinq/aether/schemas/emissions-statement/v1"
+
+	// MethodologyID identifies how the emissions are calculated
+	MethodologyID = "aether/teads-ccf/v1"
+
+	// The documentation of the methodology
+	methodologyURL = "https://github.com/re-cinq/aether/blob/main/docs/methodologies.md"
+
+	// The unit of the emissions
+	unit = "gCO2eq"
+)
+
+// Schema is the JSON schema of the statements
+//
+//go:embed schema.json
+var Schema []byte
+
+// Statement is the emissions of an account over a period
+type Statement struct {
+	Schema   string    `json:"$schema"`
+	ID       string    `json:"id"`
+	IssuedAt time.Time `json:"issuedAt"`
+	Issuer   string    `json:"issuer,omitempty"`
+
+	// The account the emissions are of, empty for all the accounts
+	Account string `json:"account,omitempty"`
+
+	Period    Period      `json:"period"`
+	Emissions Emissions   `json:"emissions"`
+	Breakdown []Emissions `json:"breakdown"`
+
+	Methodology Methodology `json:"methodology"`
+	Generator   Generator   `json:"generator"`
+}
+
+// Period is the time range of a statement, the end is excluded
+type Period struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Emissions is the emissions of the instances of a scope: the whole
+// statement, or a region of a provider
+type Emissions struct {
+	Provider v1.Provider `json:"provider,omitempty"`
+	Region   string      `json:"region,omitempty"`
+
+	Instances   int     `json:"instances"`
+	Operational float64 `json:"operational"`
+	Embodied    float64 `json:"embodied"`
+	Total       float64 `json:"total"`
+	Unit        string  `json:"unit"`
+}
+
+// Methodology describes how the emissions are calculated
+type Methodology struct {
+	ID            string               `json:"id"`
+	Documentation string               `json:"documentation"`
+	Datasets      []calculator.Dataset `json:"datasets"`
+}
+
+// Generator is the software which issued the statement
+type Generator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Request describes the statement to issue
+type Request struct {
+	Account string
+	From    time.Time
+	To      time.Time
+	Issuer  string
+
+	// The emission factors the emissions are calculated with
+	Dataset calculator.Dataset
+
+	// The version of the exporter
+	Version string
+}
+
+// New returns the statement of the samples, which must be the ones of the
+// account over the period
+func New(samples []store.Sample, r *Request, now time.Time) *Statement {
+	s := &Statement{
+		Schema:   SchemaID,
+		IssuedAt: now.UTC(),
+		Issuer:   r.Issuer,
+		Account:  r.Account,
+		Period:   Period{From: r.From.UTC(), To: r.To.UTC()},
+		Emissions: Emissions{
+			Unit: unit,
+		},
+		Breakdown: []Emissions{},
+		Methodology: Methodology{
+			ID:            MethodologyID,
+			Documentation: methodologyURL,
+			Datasets:      []calculator.Dataset{r.Dataset},
+		},
+		Generator: Generator{
+			Name:    "aether",
+			Version: r.Version,
+		},
+	}
+
+	type scope struct {
+		emissions *Emissions
+		instances map[string]bool
+	}
+
+	scopes := make(map[string]*scope)
+	all := make(map[string]bool)
+
+	for i := range samples {
+		sample := &samples[i]
+
+		key := sample.Provider.String() + "/" + sample.Region
+		sc, ok := scopes[key]
+		if !ok {
+			sc = &scope{
+				emissions: &Emissions{Provider: sample.Provider, Region: sample.Region, Unit: unit},
+				instances: make(map[string]bool),
+			}
+			scopes[key] = sc
+		}
+
+		instance := sample.Provider.String() + "/" + sample.Name
+		sc.instances[instance] = true
+		all[instance] = true
+
+		sc.emissions.Operational += sample.Operational
+		sc.emissions.Embodied += sample.Embodied
+		s.Emissions.Operational += sample.Operational
+		s.Emissions.Embodied += sample.Embodied
+	}
+
+	for _, sc := range scopes {
+		sc.emissions.Instances = len(sc.instances)
+		sc.emissions.Total = sc.emissions.Operational + sc.emissions.Embodied
+		s.Breakdown = append(s.Breakdown, *sc.emissions)
+	}
+
+	sort.Slice(s.Breakdown, func(i, j int) bool {
+		a, b := s.Breakdown[i], s.Breakdown[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Region < b.Region
+	})
+
+	s.Emissions.Instances = len(all)
+	s.Emissions.Total = s.Emissions.Operational + s.Emissions.Embodied
+
+	// the id is stable for the same account, period and issuing time
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s", s.Account, s.Period.From.Format(time.RFC3339Nano), s.Period.To.Format(time.RFC3339Nano), s.IssuedAt.Format(time.RFC3339Nano))))
+	s.ID = hex.EncodeToString(h[:16])
+
+	return s
+}
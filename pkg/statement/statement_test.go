@@ -0,0 +1,117 @@
+package statement
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	"github.com/re-cinq/aether/pkg/store"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatement(t *testing.T) {
+	assert := require.New(t)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &Request{
+		Account: "prod",
+		From:    from,
+		To:      from.AddDate(0, 1, 0),
+		Issuer:  "ACME",
+		Dataset: calculator.Dataset{Source: "emissions-data", Version: "abc"},
+		Version: "1.0.0",
+	}
+
+	s := New([]store.Sample{
+		{Time: from, Provider: v1.AWS, Name: "a", Region: "eu-west-1", Operational: 10, Embodied: 2},
+		{Time: from.Add(time.Hour), Provider: v1.AWS, Name: "a", Region: "eu-west-1", Operational: 10, Embodied: 2},
+		{Time: from, Provider: v1.AWS, Name: "b", Region: "us-east-1", Operational: 5},
+		{Time: from, Provider: v1.GCP, Name: "c", Region: "europe-west1", Operational: 1, Embodied: 1},
+	}, req, from.AddDate(0, 1, 1))
+
+	assert.Equal(SchemaID, s.Schema)
+	assert.NotEmpty(s.ID)
+	assert.Equal(Emissions{Instances: 3, Operational: 26, Embodied: 5, Total: 31, Unit: "gCO2eq"}, s.Emissions)
+	assert.Equal([]Emissions{
+		{Provider: v1.AWS, Region: "eu-west-1", Instances: 1, Operational: 20, Embodied: 4, Total: 24, Unit: "gCO2eq"},
+		{Provider: v1.AWS, Region: "us-east-1", Instances: 1, Operational: 5, Total: 5, Unit: "gCO2eq"},
+		{Provider: v1.GCP, Region: "europe-west1", Instances: 1, Operational: 1, Embodied: 1, Total: 2, Unit: "gCO2eq"},
+	}, s.Breakdown)
+	assert.Equal(MethodologyID, s.Methodology.ID)
+	assert.Equal("abc", s.Methodology.Datasets[0].Version)
+
+	// the schema is valid JSON and describes all the fields
+	var schema struct {
+		Required   []string       `json:"required"`
+		Properties map[string]any `json:"properties"`
+	}
+	assert.NoError(json.Unmarshal(Schema, &schema))
+
+	b, err := json.Marshal(s)
+	assert.NoError(err)
+	var fields map[string]any
+	assert.NoError(json.Unmarshal(b, &fields))
+	for field := range fields {
+		assert.Contains(schema.Properties, field)
+	}
+	for _, field := range schema.Required {
+		assert.Contains(fields, field)
+	}
+}
+
+func TestSignStatement(t *testing.T) {
+	assert := require.New(t)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+
+	// the keys are read from PEM files
+	dir := t.TempDir()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(filepath.Join(dir, "key.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	der, err = x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(filepath.Join(dir, "pub.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	priv, err = LoadPrivateKey(filepath.Join(dir, "key.pem"))
+	assert.NoError(err)
+	pub, err = LoadPublicKey(filepath.Join(dir, "pub.pem"))
+	assert.NoError(err)
+	_, err = LoadPublicKey(filepath.Join(dir, "key.pem"))
+	assert.Error(err)
+
+	s := New(nil, &Request{Account: "prod"}, time.Now())
+	e, err := Sign(s, priv)
+	assert.NoError(err)
+	assert.Equal(KeyID(pub), e.Signatures[0].KeyID)
+
+	// the envelope survives encoding
+	b, err := json.Marshal(e)
+	assert.NoError(err)
+	var decoded Envelope
+	assert.NoError(json.Unmarshal(b, &decoded))
+
+	verified, err := Verify(&decoded, pub)
+	assert.NoError(err)
+	assert.Equal("prod", verified.Account)
+
+	// a tampered statement is rejected
+	decoded.Payload = []byte(`{"account":"dev"}`)
+	_, err = Verify(&decoded, pub)
+	assert.ErrorIs(err, ErrInvalidSignature)
+
+	// and so is another key
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+	_, err = Verify(e, other)
+	assert.ErrorIs(err, ErrInvalidSignature)
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// Period is a calendar period, it starts at midnight in the time zone of
+// the store
+type Period string
+
+// The calendar periods
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+	Year  Period = "year"
+)
+
+// periods are the calendar periods of the queries
+var periods = map[string]Period{
+	string(Day):   Day,
+	string(Week):  Week,
+	string(Month): Month,
+	string(Year):  Year,
+}
+
+// Start returns the start of the period containing the time in the
+// location, the weeks start on Monday
+func (p Period) Start(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+
+	switch p {
+	case Week:
+		day -= (int(t.Weekday()) + 6) % 7
+	case Month:
+		day = 1
+	case Year:
+		month, day = time.January, 1
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// loadLocation returns the time zone of its IANA name, UTC when empty
+func loadLocation(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,447 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidQuery is returned when a query cannot be parsed
+var ErrInvalidQuery = errors.New("invalid query")
+
+// The default time range of a query
+const defaultRange = 24 * time.Hour
+
+// Query aggregates the stored emissions, it's parsed from expressions like:
+//
+//	sum(emissions) by (team) where provider=aws and region=eu-west-1 and range=30d
+//
+// The supported functions are sum, avg, min, max and count, over the
+// emissions, operational or embodied values. The samples can be grouped and
+// filtered by provider, service, name, region, zone, kind, quality or any label.
+//
+// The intensity is the grid carbon intensity in gCO2eq/kWh the emissions
+// were calculated with, e.g. avg(intensity) by (region). It can't be summed
+// and the samples recorded without it are left out
+//
+// The range is rolling, e.g. 30d, or 3mo for the last 3 months, or the
+// calendar day, week, month or year to date, starting at midnight in the
+// time zone of the store
+type Query struct {
+	// The aggregation function
+	Function string
+
+	// The aggregated value
+	Field string
+
+	// The labels the results are grouped by
+	By []string
+
+	// The label matchers, all of them must match
+	Matchers []Matcher
+
+	// How far back the query looks
+	Range time.Duration
+
+	// The calendar period the query covers to date instead of the range
+	Period Period
+
+	// The months the query looks back instead of the range, from the same
+	// day and time
+	Months int
+}
+
+// Start returns the start of the query ending at the time, the calendar
+// periods and the months are the ones of the location
+func (q *Query) Start(end time.Time, loc *time.Location) time.Time {
+	switch {
+	case q.Period != "":
+		return q.Period.Start(end, loc)
+	case q.Months > 0:
+		return end.In(loc).AddDate(0, -q.Months, 0)
+	default:
+		return end.Add(-q.Range)
+	}
+}
+
+// IntensityField is the field of the grid carbon intensity of the samples
+const IntensityField = "intensity"
+
+// matches returns whether the sample matches all the matchers and has the
+// queried field
+func (q *Query) matches(s *Sample) bool {
+	if q.Field == IntensityField && s.GridIntensity <= 0 {
+		return false
+	}
+
+	for i := range q.Matchers {
+		if !q.Matchers[i].Matches(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matcher filters the samples by the value of a label
+type Matcher struct {
+	Label string
+	Value string
+	Not   bool
+}
+
+// Matches returns whether the sample matches the matcher
+func (m *Matcher) Matches(s *Sample) bool {
+	return (s.Label(m.Label) == m.Value) != m.Not
+}
+
+// Result is the aggregated value of a group
+type Result struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+var (
+	functions = map[string]bool{"sum": true, "avg": true, "min": true, "max": true, "count": true}
+	fields    = map[string]bool{"emissions": true, "operational": true, "embodied": true, IntensityField: true}
+)
+
+// ParseQuery parses a query expression
+func ParseQuery(expr string) (*Query, error) {
+	p := &parser{tokens: tokenize(expr)}
+	q := &Query{Range: defaultRange}
+
+	q.Function = p.next()
+	if !functions[q.Function] {
+		return nil, fmt.Errorf("%w: unknown function %q", ErrInvalidQuery, q.Function)
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	q.Field = p.next()
+	if !fields[q.Field] {
+		return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, q.Field)
+	}
+	if q.Field == IntensityField && q.Function == "sum" {
+		return nil, fmt.Errorf("%w: the intensity can't be summed, use avg, min or max", ErrInvalidQuery)
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if p.peek() == "by" {
+		p.next()
+		by, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+		q.By = by
+	}
+
+	if p.peek() == "where" {
+		p.next()
+		for {
+			if err := p.condition(q); err != nil {
+				return nil, err
+			}
+			if p.peek() != "and" {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if t := p.peek(); t != "" {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, t)
+	}
+
+	return q, nil
+}
+
+// parser walks through the tokens of a query
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// peek returns the next token without consuming it
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// next consumes the next token
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// expect consumes the next token and fails if it's not the expected one
+func (p *parser) expect(token string) error {
+	if t := p.next(); t != token {
+		return fmt.Errorf("%w: expected %q, got %q", ErrInvalidQuery, token, t)
+	}
+	return nil
+}
+
+// list parses a list of identifiers between parentheses
+func (p *parser) list() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var items []string
+	for {
+		item := p.next()
+		if item == "" || strings.ContainsAny(item, "(),=!") {
+			return nil, fmt.Errorf("%w: expected a label, got %q", ErrInvalidQuery, item)
+		}
+		items = append(items, item)
+
+		switch t := p.next(); t {
+		case ",":
+			continue
+		case ")":
+			return items, nil
+		default:
+			return nil, fmt.Errorf("%w: expected \",\" or \")\", got %q", ErrInvalidQuery, t)
+		}
+	}
+}
+
+// condition parses a label matcher or the range of the query
+func (p *parser) condition(q *Query) error {
+	label := p.next()
+	if label == "" {
+		return fmt.Errorf("%w: expected a condition", ErrInvalidQuery)
+	}
+
+	op := p.next()
+	if op != "=" && op != "!=" {
+		return fmt.Errorf("%w: expected \"=\" or \"!=\", got %q", ErrInvalidQuery, op)
+	}
+
+	value := p.next()
+	if value == "" {
+		return fmt.Errorf("%w: missing value of %q", ErrInvalidQuery, label)
+	}
+
+	if label == "range" {
+		if op != "=" {
+			return fmt.Errorf("%w: range only supports \"=\"", ErrInvalidQuery)
+		}
+
+		if period, ok := periods[value]; ok {
+			q.Period = period
+			return nil
+		}
+
+		if n, ok := strings.CutSuffix(value, "mo"); ok {
+			months, err := strconv.Atoi(n)
+			if err != nil || months <= 0 {
+				return fmt.Errorf("%w: invalid months %q", ErrInvalidQuery, value)
+			}
+			q.Months = months
+			return nil
+		}
+
+		d, err := ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+		q.Range = d
+		return nil
+	}
+
+	q.Matchers = append(q.Matchers, Matcher{
+		Label: label,
+		Value: value,
+		Not:   op == "!=",
+	})
+
+	return nil
+}
+
+// tokenize splits the expression in identifiers, values and operators
+// Values can be quoted to contain spaces or operators
+func tokenize(expr string) []string {
+	var (
+		tokens []string
+		cur    strings.Builder
+	)
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	runes := []rune(expr)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '"' || r == '\'':
+			flush()
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				j++
+			}
+			tokens = append(tokens, string(runes[i+1:min(j, len(runes))]))
+			i = j
+		case r == '(' || r == ')' || r == ',' || r == '=':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			flush()
+			tokens = append(tokens, "!=")
+			i++
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// ParseDuration parses a duration, in addition to the units supported by
+// time.ParseDuration it supports days (d) and weeks (w)
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v <= 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	return d, nil
+}
+
+// ParseDate parses a date, as 2006-01-02, or a time, as RFC3339. The dates
+// start at midnight in the location. It returns whether only the date was
+// set
+func ParseDate(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q, expected 2006-01-02 or RFC3339", s)
+	}
+
+	return t, false, nil
+}
+
+// Query runs the query over the stored samples
+// The results are sorted by value, the highest first
+func (s *Store) Query(q *Query) []Result {
+	to := s.now()
+	samples := s.Select(q.Start(to, s.location), to.Add(time.Nanosecond), q.matches)
+
+	type group struct {
+		labels map[string]string
+		values []float64
+	}
+
+	groups := make(map[string]*group)
+	var order []string
+	for i := range samples {
+		labels := make(map[string]string, len(q.By))
+		parts := make([]string, len(q.By))
+		for j, l := range q.By {
+			labels[l] = samples[i].Label(l)
+			parts[j] = labels[l]
+		}
+
+		key := strings.Join(parts, "\x00")
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.values = append(g.values, q.value(&samples[i]))
+	}
+
+	results := make([]Result, 0, len(groups))
+	for _, key := range order {
+		g := groups[key]
+		results = append(results, Result{
+			Labels: g.labels,
+			Value:  aggregate(q.Function, g.values),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Value > results[j].Value
+	})
+
+	return results
+}
+
+// value returns the queried field of the sample
+func (q *Query) value(s *Sample) float64 {
+	switch q.Field {
+	case "operational":
+		return s.Operational
+	case "embodied":
+		return s.Embodied
+	case IntensityField:
+		return s.GridIntensity
+	default:
+		return s.Operational + s.Embodied
+	}
+}
+
+// aggregate applies the function to the values
+func aggregate(function string, values []float64) float64 {
+	switch function {
+	case "count":
+		return float64(len(values))
+	case "min":
+		v := math.Inf(1)
+		for _, x := range values {
+			v = math.Min(v, x)
+		}
+		return v
+	case "max":
+		v := math.Inf(-1)
+		for _, x := range values {
+			v = math.Max(v, x)
+		}
+		return v
+	}
+
+	var sum float64
+	for _, x := range values {
+		sum += x
+	}
+
+	if function == "avg" {
+		return sum / float64(len(values))
+	}
+
+	return sum
+}
@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		query *Query
+		err   bool
+	}{
+		{
+			name: "full query",
+			expr: "sum(emissions) by (team) where provider=aws and region=eu-west-1 and range=30d",
+			query: &Query{
+				Function: "sum",
+				Field:    "emissions",
+				By:       []string{"team"},
+				Matchers: []Matcher{
+					{Label: "provider", Value: "aws"},
+					{Label: "region", Value: "eu-west-1"},
+				},
+				Range: 30 * 24 * time.Hour,
+			},
+		},
+		{
+			name: "default range",
+			expr: "max(embodied)",
+			query: &Query{
+				Function: "max",
+				Field:    "embodied",
+				Range:    defaultRange,
+			},
+		},
+		{
+			name: "negated and quoted matchers",
+			expr: `avg(operational) by (provider, region) where team != "data platform"`,
+			query: &Query{
+				Function: "avg",
+				Field:    "operational",
+				By:       []string{"provider", "region"},
+				Matchers: []Matcher{
+					{Label: "team", Value: "data platform", Not: true},
+				},
+				Range: defaultRange,
+			},
+		},
+		{
+			name: "calendar month",
+			expr: "sum(emissions) where range=month",
+			query: &Query{
+				Function: "sum",
+				Field:    "emissions",
+				Range:    defaultRange,
+				Period:   Month,
+			},
+		},
+		{
+			name: "rolling months",
+			expr: "sum(emissions) where range=3mo",
+			query: &Query{
+				Function: "sum",
+				Field:    "emissions",
+				Range:    defaultRange,
+				Months:   3,
+			},
+		},
+		{
+			name: "grid intensity",
+			expr: "avg(intensity) by (region)",
+			query: &Query{
+				Function: "avg",
+				Field:    IntensityField,
+				By:       []string{"region"},
+				Range:    defaultRange,
+			},
+		},
+		{name: "summed grid intensity", expr: "sum(intensity)", err: true},
+		{name: "invalid months", expr: "sum(emissions) where range=0mo", err: true},
+		{name: "unknown function", expr: "rate(emissions)", err: true},
+		{name: "unknown field", expr: "sum(cpu)", err: true},
+		{name: "missing parenthesis", expr: "sum(emissions by (team)", err: true},
+		{name: "invalid range", expr: "sum(emissions) where range=soon", err: true},
+		{name: "trailing tokens", expr: "sum(emissions) team", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			q, err := ParseQuery(test.expr)
+			if test.err {
+				assert.ErrorIs(err, ErrInvalidQuery)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Equal(test.query, q)
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		value    string
+		loc      *time.Location
+		date     time.Time
+		dateOnly bool
+		err      bool
+	}{
+		{value: "2024-01-31", date: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{value: "2024-01-31T12:00:00Z", date: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		// the dates start at midnight in the location, not the times
+		{value: "2024-01-31", loc: berlin, date: time.Date(2024, 1, 30, 23, 0, 0, 0, time.UTC), dateOnly: true},
+		{value: "2024-01-31T12:00:00Z", loc: berlin, date: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{value: "31/01/2024", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			assert := require.New(t)
+
+			loc := test.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			date, dateOnly, err := ParseDate(test.value, loc)
+			if test.err {
+				assert.Error(err)
+				return
+			}
+
+			assert.NoError(err)
+			assert.True(test.date.Equal(date))
+			assert.Equal(test.dateOnly, dateOnly)
+		})
+	}
+}
+
+func TestStoreQuery(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	s, err := New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+	s.now = func() time.Time { return now }
+
+	samples := []Sample{
+		{Time: now.Add(-time.Hour), Provider: v1.AWS, Region: "eu-west-1", Labels: v1.Labels{"team": "a"}, Operational: 1, Embodied: 1},
+		{Time: now.Add(-2 * time.Hour), Provider: v1.AWS, Region: "eu-west-1", Labels: v1.Labels{"team": "b"}, Operational: 5},
+		{Time: now.Add(-3 * time.Hour), Provider: v1.AWS, Region: "eu-west-1", Labels: v1.Labels{"team": "a"}, Operational: 2},
+		{Time: now.Add(-time.Hour), Provider: v1.AWS, Region: "us-east-1", Labels: v1.Labels{"team": "a"}, Operational: 10},
+		{Time: now.Add(-time.Hour), Provider: v1.GCP, Region: "eu-west-1", Labels: v1.Labels{"team": "a"}, Operational: 10},
+		{Time: now.Add(-48 * time.Hour), Provider: v1.AWS, Region: "eu-west-1", Labels: v1.Labels{"team": "a"}, Operational: 10},
+	}
+	for _, sample := range samples {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	q, err := ParseQuery("sum(emissions) by (team) where provider=aws and region=eu-west-1 and range=1d")
+	assert.NoError(err)
+	assert.Equal([]Result{
+		{Labels: map[string]string{"team": "b"}, Value: 5},
+		{Labels: map[string]string{"team": "a"}, Value: 4},
+	}, s.Query(q))
+
+	q, err = ParseQuery("count(emissions) where provider!=gcp and range=7d")
+	assert.NoError(err)
+	assert.Equal([]Result{{Labels: map[string]string{}, Value: 5}}, s.Query(q))
+}
+
+func TestStoreQueryPeriods(t *testing.T) {
+	assert := require.New(t)
+
+	// 2024-03-01 01:00 in Berlin
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	s, err := New(ctx, &config.StoreConfig{Timezone: "Europe/Berlin"})
+	assert.NoError(err)
+	s.now = func() time.Time { return now }
+
+	for _, sample := range []Sample{
+		// the 1st of March in Berlin, still February in UTC
+		{Time: now.Add(-30 * time.Minute), Provider: v1.AWS, Operational: 1},
+		{Time: now.Add(-2 * time.Hour), Provider: v1.AWS, Operational: 2},
+		{Time: now.Add(-20 * 24 * time.Hour), Provider: v1.AWS, Operational: 4},
+		{Time: now.Add(-40 * 24 * time.Hour), Provider: v1.AWS, Operational: 8},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	for expr, value := range map[string]float64{
+		"sum(emissions) where range=day":   1,
+		"sum(emissions) where range=month": 1,
+		"sum(emissions) where range=year":  15,
+		"sum(emissions) where range=1mo":   7,
+		"sum(emissions) where range=30d":   7,
+		"sum(emissions) where range=2mo":   15,
+	} {
+		q, err := ParseQuery(expr)
+		assert.NoError(err)
+		assert.Equal([]Result{{Labels: map[string]string{}, Value: value}}, s.Query(q), expr)
+	}
+
+	_, err = New(ctx, &config.StoreConfig{Timezone: "Mars/Olympus"})
+	assert.ErrorContains(err, "invalid time zone")
+}
+
+func TestPeriodStart(t *testing.T) {
+	assert := require.New(t)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(err)
+
+	// Sunday 2024-03-31 01:30 in Berlin, the day the clocks change
+	now := time.Date(2024, 3, 31, 0, 30, 0, 0, time.UTC)
+
+	assert.Equal(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), Day.Start(now, time.UTC))
+	assert.Equal(time.Date(2024, 3, 30, 23, 0, 0, 0, time.UTC), Day.Start(now, berlin).UTC())
+	assert.Equal(time.Date(2024, 3, 24, 23, 0, 0, 0, time.UTC), Week.Start(now, berlin).UTC())
+	assert.Equal(time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC), Month.Start(now, berlin).UTC())
+	assert.Equal(time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC), Year.Start(now, berlin).UTC())
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxPoints bounds the points of a series of a range query, like Prometheus
+const maxPoints = 11000
+
+// The resolutions of the range queries, the samples they read
+var resolutions = map[time.Duration]string{
+	HourlyStep: "hourly",
+	DailyStep:  "daily",
+}
+
+// Matrix is the result of a range query, the values of every group by step
+type Matrix struct {
+	// The samples the query read: raw, hourly or daily
+	Resolution string
+
+	// The groups, the highest total first
+	Series []Series
+}
+
+// Series are the values of a group over the steps of a range query, the
+// steps without samples are left out
+type Series struct {
+	Labels map[string]string
+	Points []Point
+}
+
+// Point is the value of a step, starting at its time
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// QueryRange runs the query over every step of [from, to), the range of the
+// query is ignored. The value of a step aggregates the emissions of every
+// instance summed over the step, e.g. the avg is the average emissions of
+// an instance over the step. The grid intensities are averaged instead.
+// The steps which are a multiple of a rollup, from and to the bounds of its
+// buckets, read the rollup instead of the raw samples
+func (s *Store) QueryRange(q *Query, from, to time.Time, step time.Duration) (*Matrix, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("%w: the step must be positive", ErrInvalidQuery)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: the end must be after the start", ErrInvalidQuery)
+	}
+	if n := to.Sub(from) / step; n >= maxPoints {
+		return nil, fmt.Errorf("%w: %d points exceed the maximum of %d, increase the step", ErrInvalidQuery, n, maxPoints)
+	}
+
+	m := &Matrix{Resolution: ResolutionRaw}
+	var samples []Sample
+	if r := s.rollupOf(from, to, step); r != nil {
+		m.Resolution = resolutions[r.step]
+		s.mu.RLock()
+		samples = r.selectBuckets(from, to, q.matches)
+		s.mu.RUnlock()
+	} else {
+		samples = s.Select(from, to, q.matches)
+	}
+
+	// the emissions of every instance are summed by step before they're
+	// aggregated
+	type group struct {
+		labels map[string]string
+		steps  map[int64]map[string]*stepValue
+	}
+
+	groups := make(map[string]*group)
+	var order []string
+	for i := range samples {
+		labels := make(map[string]string, len(q.By))
+		parts := make([]string, len(q.By))
+		for j, l := range q.By {
+			labels[l] = samples[i].Label(l)
+			parts[j] = labels[l]
+		}
+
+		key := strings.Join(parts, "\x00")
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels, steps: make(map[int64]map[string]*stepValue)}
+			groups[key] = g
+			order = append(order, key)
+		}
+
+		n := int64(samples[i].Time.Sub(from) / step)
+		series, ok := g.steps[n]
+		if !ok {
+			series = make(map[string]*stepValue)
+			g.steps[n] = series
+		}
+		id := seriesKey(&samples[i])
+		v, ok := series[id]
+		if !ok {
+			v = &stepValue{}
+			series[id] = v
+		}
+		v.sum += q.value(&samples[i])
+		v.samples++
+	}
+
+	type result struct {
+		series Series
+		total  float64
+	}
+
+	results := make([]result, 0, len(groups))
+	for _, key := range order {
+		g := groups[key]
+
+		steps := make([]int64, 0, len(g.steps))
+		for n := range g.steps {
+			steps = append(steps, n)
+		}
+		sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+
+		series := Series{Labels: g.labels, Points: make([]Point, 0, len(steps))}
+		var total float64
+		for _, n := range steps {
+			values := make([]float64, 0, len(g.steps[n]))
+			for _, v := range g.steps[n] {
+				if q.Field == IntensityField {
+					values = append(values, v.sum/float64(v.samples))
+					continue
+				}
+				values = append(values, v.sum)
+			}
+			// the map order changes the last digits of the sums
+			sort.Float64s(values)
+
+			v := aggregate(q.Function, values)
+			total += v
+			series.Points = append(series.Points, Point{
+				Time:  from.Add(time.Duration(n) * step),
+				Value: v,
+			})
+		}
+
+		results = append(results, result{series: series, total: total})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].total > results[j].total
+	})
+
+	m.Series = make([]Series, len(results))
+	for i := range results {
+		m.Series[i] = results[i].series
+	}
+
+	return m, nil
+}
+
+// stepValue is the sum of the values of a series over a step
+type stepValue struct {
+	sum     float64
+	samples int
+}
+
+// rollupOf returns the coarsest rollup the steps of [from, to) can be summed
+// from, nil when the raw samples must be read
+func (s *Store) rollupOf(from, to time.Time, step time.Duration) *rollup {
+	for _, r := range s.rollups {
+		if step%r.step == 0 && from.Equal(from.Truncate(r.step)) && to.Equal(to.Truncate(r.step)) {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreQueryRange(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	s, err := New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+	s.now = func() time.Time { return now }
+
+	day := now.Add(-24 * time.Hour)
+	for _, sample := range []Sample{
+		// two samples of a and one of b in the first hour
+		{Time: day.Add(10 * time.Minute), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "shop"}, Operational: 1, Embodied: 1},
+		{Time: day.Add(40 * time.Minute), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "shop"}, Operational: 2},
+		{Time: day.Add(20 * time.Minute), Provider: v1.AWS, Name: "b", Labels: v1.Labels{"team": "shop"}, Operational: 6},
+		{Time: day.Add(90 * time.Minute), Provider: v1.AWS, Name: "a", Labels: v1.Labels{"team": "shop"}, Operational: 4},
+		{Time: day.Add(30 * time.Minute), Provider: v1.GCP, Name: "c", Labels: v1.Labels{"team": "search"}, Operational: 1},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		from, to   time.Time
+		step       time.Duration
+		resolution string
+		expected   []Series
+	}{
+		{
+			name:       "summed by hour from the hourly rollup",
+			query:      "sum(emissions) by (team)",
+			from:       day,
+			to:         day.Add(3 * time.Hour),
+			step:       time.Hour,
+			resolution: "hourly",
+			expected: []Series{
+				{Labels: map[string]string{"team": "shop"}, Points: []Point{
+					{Time: day, Value: 10},
+					{Time: day.Add(time.Hour), Value: 4},
+				}},
+				{Labels: map[string]string{"team": "search"}, Points: []Point{
+					{Time: day, Value: 1},
+				}},
+			},
+		},
+		{
+			name:       "the instances are summed over the step before they're aggregated",
+			query:      "max(emissions) where provider=aws",
+			from:       day,
+			to:         now,
+			step:       24 * time.Hour,
+			resolution: "daily",
+			expected: []Series{
+				{Labels: map[string]string{}, Points: []Point{{Time: day, Value: 8}}},
+			},
+		},
+		{
+			name:       "the steps not aligned with a rollup read the raw samples",
+			query:      "count(emissions)",
+			from:       day.Add(30 * time.Minute),
+			to:         day.Add(90 * time.Minute),
+			step:       30 * time.Minute,
+			resolution: ResolutionRaw,
+			expected: []Series{
+				{Labels: map[string]string{}, Points: []Point{
+					{Time: day.Add(30 * time.Minute), Value: 2},
+				}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery(test.query)
+			assert.NoError(err)
+
+			m, err := s.QueryRange(q, test.from, test.to, test.step)
+			assert.NoError(err)
+			assert.Equal(test.resolution, m.Resolution)
+			assert.Equal(test.expected, m.Series)
+		})
+	}
+
+	q, err := ParseQuery("sum(emissions)")
+	assert.NoError(err)
+	_, err = s.QueryRange(q, day, now, time.Second)
+	assert.ErrorIs(err, ErrInvalidQuery)
+	_, err = s.QueryRange(q, now, day, time.Hour)
+	assert.ErrorIs(err, ErrInvalidQuery)
+}
+
+func TestStoreQueryRangeIntensity(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	s, err := New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+	s.now = func() time.Time { return now }
+
+	day := now.Add(-24 * time.Hour)
+	for _, sample := range []Sample{
+		{Time: day.Add(10 * time.Minute), Provider: v1.AWS, Name: "a", Region: "eu-west-1", GridIntensity: 300},
+		{Time: day.Add(40 * time.Minute), Provider: v1.AWS, Name: "a", Region: "eu-west-1", GridIntensity: 200},
+		{Time: day.Add(20 * time.Minute), Provider: v1.AWS, Name: "b", Region: "eu-west-1", GridIntensity: 400},
+		{Time: day.Add(30 * time.Minute), Provider: v1.AWS, Name: "c", Region: "eu-north-1", GridIntensity: 40},
+		// recorded without the intensity
+		{Time: day.Add(50 * time.Minute), Provider: v1.AWS, Name: "d", Region: "eu-north-1", Operational: 1},
+	} {
+		assert.NoError(s.Add(ctx, sample))
+	}
+
+	// the intensities of an instance are averaged over the step, from the
+	// rollups as from the raw samples
+	q, err := ParseQuery("avg(intensity) by (region)")
+	assert.NoError(err)
+	for from, resolution := range map[time.Time]string{
+		day:                       "hourly",
+		day.Add(10 * time.Minute): ResolutionRaw,
+	} {
+		m, err := s.QueryRange(q, from, from.Add(time.Hour), time.Hour)
+		assert.NoError(err)
+		assert.Equal(resolution, m.Resolution)
+		assert.Equal([]Series{
+			{Labels: map[string]string{"region": "eu-west-1"}, Points: []Point{{Time: from, Value: 325}}},
+			{Labels: map[string]string{"region": "eu-north-1"}, Points: []Point{{Time: from, Value: 40}}},
+		}, m.Series)
+	}
+
+	q, err = ParseQuery("max(intensity) where region=eu-west-1")
+	assert.NoError(err)
+	m, err := s.QueryRange(q, day, now, DailyStep)
+	assert.NoError(err)
+	assert.Equal([]Series{
+		{Labels: map[string]string{}, Points: []Point{{Time: day, Value: 400}}},
+	}, m.Series)
+}
+
+func TestRollupPrune(t *testing.T) {
+	assert := require.New(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newRollup(time.Hour)
+	for i := range 3 {
+		r.add(&Sample{Time: start.Add(time.Duration(i) * time.Hour), Name: "a", Operational: 1})
+	}
+
+	// the buckets are dropped once they ended
+	r.prune(start.Add(90 * time.Minute))
+	assert.Equal([]time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)}, r.starts)
+	assert.Len(r.buckets, 2)
+	assert.Len(r.selectBuckets(start, start.Add(3*time.Hour), nil), 2)
+}
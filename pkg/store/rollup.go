@@ -0,0 +1,151 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// The steps of the rollups of the samples, the range queries whose step is
+// a multiple of one of them read its buckets instead of the raw samples
+const (
+	HourlyStep = time.Hour
+	DailyStep  = 24 * time.Hour
+)
+
+// ResolutionRaw is the resolution of the range queries reading the raw
+// samples
+const ResolutionRaw = "raw"
+
+// rollup sums the emissions of every series, an instance with its labels,
+// by bucket of the step, and averages its grid intensity. The buckets start
+// at the multiples of the step since the epoch, i.e. the days start at
+// midnight UTC
+type rollup struct {
+	step time.Duration
+
+	// The starts of the buckets, sorted
+	starts []time.Time
+
+	// The buckets by start
+	buckets map[time.Time]*bucket
+}
+
+// bucket are the samples of a rollup summed by series, their time is the
+// start of the bucket
+type bucket struct {
+	samples []Sample
+
+	// The index of the series in the samples
+	series map[string]int
+
+	// The samples with a grid intensity of every series, by index
+	intensities []int
+}
+
+func newRollup(step time.Duration) *rollup {
+	return &rollup{
+		step:    step,
+		buckets: make(map[time.Time]*bucket),
+	}
+}
+
+// seriesKey identifies the series of a sample
+func seriesKey(s *Sample) string {
+	var b strings.Builder
+	for _, v := range []string{s.Provider.String(), s.Service, s.Name, s.Region, s.Zone, s.Kind} {
+		b.WriteString(v)
+		b.WriteByte(0)
+	}
+
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(s.Labels[k])
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
+
+// add sums the sample into the bucket of its series
+func (r *rollup) add(sample *Sample) {
+	start := sample.Time.Truncate(r.step)
+
+	b, ok := r.buckets[start]
+	if !ok {
+		b = &bucket{series: make(map[string]int)}
+		r.buckets[start] = b
+
+		i := sort.Search(len(r.starts), func(i int) bool {
+			return r.starts[i].After(start)
+		})
+		r.starts = append(r.starts, time.Time{})
+		copy(r.starts[i+1:], r.starts[i:])
+		r.starts[i] = start
+	}
+
+	key := seriesKey(sample)
+	i, ok := b.series[key]
+	if !ok {
+		summed := *sample
+		summed.Time = start
+		b.series[key] = len(b.samples)
+		b.samples = append(b.samples, summed)
+		b.intensities = append(b.intensities, 0)
+		if sample.GridIntensity > 0 {
+			b.intensities[len(b.intensities)-1] = 1
+		}
+		return
+	}
+
+	summed := &b.samples[i]
+	summed.Operational += sample.Operational
+	summed.Embodied += sample.Embodied
+	if sample.Quality != "" {
+		summed.Quality = summed.Quality.Lowest(sample.Quality)
+	}
+	if sample.GridIntensity > 0 {
+		b.intensities[i]++
+		summed.GridIntensity += (sample.GridIntensity - summed.GridIntensity) / float64(b.intensities[i])
+	}
+}
+
+// prune drops the buckets which ended before the cutoff
+func (r *rollup) prune(cutoff time.Time) {
+	i := sort.Search(len(r.starts), func(i int) bool {
+		return r.starts[i].Add(r.step).After(cutoff)
+	})
+
+	for _, start := range r.starts[:i] {
+		delete(r.buckets, start)
+	}
+	if i > 0 {
+		r.starts = append([]time.Time(nil), r.starts[i:]...)
+	}
+}
+
+// selectBuckets returns the summed samples of the buckets starting in
+// [from, to) that match the filter
+func (r *rollup) selectBuckets(from, to time.Time, filter func(*Sample) bool) []Sample {
+	start := sort.Search(len(r.starts), func(i int) bool {
+		return !r.starts[i].Before(from)
+	})
+
+	var out []Sample
+	for i := start; i < len(r.starts) && r.starts[i].Before(to); i++ {
+		b := r.buckets[r.starts[i]]
+		for j := range b.samples {
+			if filter == nil || filter(&b.samples[j]) {
+				out = append(out, b.samples[j])
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// How many entries are read from the stream at once
+	readCount = 1000
+
+	// How long a read waits for new entries, bounds how long stopping takes
+	readBlock = time.Second
+
+	// How long adding an entry can take
+	publishTimeout = 5 * time.Second
+)
+
+// shared keeps the samples of the replicas in sync through a Redis stream.
+// Every replica adds its samples to the stream and reads the ones of the
+// other replicas, so that all of them can answer the queries over the same
+// samples. The stream is trimmed to the retention of the store
+type shared struct {
+	store  *Store
+	client *redis.Client
+	stream string
+
+	// Identifies the entries added by this replica, which are already in
+	// the store
+	replica string
+
+	// The id of the last entry read
+	lastID string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newShared loads the samples of the stream in the store, start follows the
+// entries added by the other replicas afterwards
+func newShared(ctx context.Context, s *Store, cfg *config.RedisConfig) (*shared, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	stream := cfg.Stream
+	if stream == "" {
+		stream = "aether:emissions"
+	}
+
+	sh := &shared{
+		store: s,
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Address,
+			Username: cfg.Username,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}),
+		stream:  stream,
+		replica: hex.EncodeToString(id),
+		lastID:  "0-0",
+	}
+
+	// the entries are read from the start of the retention
+	if s.retention > 0 {
+		sh.lastID = fmt.Sprintf("%d-0", s.now().Add(-s.retention).UnixMilli())
+	}
+
+	// load the existing entries, until a read returns less than a full batch
+	for {
+		n, err := sh.read(ctx, -1)
+		if err != nil {
+			sh.client.Close()
+			return nil, fmt.Errorf("failed loading the store from redis: %w", err)
+		}
+		if n < readCount {
+			break
+		}
+	}
+
+	sh.ctx, sh.cancel = context.WithCancel(context.Background())
+	sh.done = make(chan struct{})
+
+	return sh, nil
+}
+
+// start follows the entries added by the other replicas until stopped
+func (sh *shared) start() {
+	go sh.follow()
+}
+
+// publish adds the sample to the stream, dropping the expired entries. It
+// gives up when the context is done or the store is stopped
+func (sh *shared) publish(ctx context.Context, sample *Sample) error {
+	b, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+
+	args := &redis.XAddArgs{
+		Stream: sh.stream,
+		Values: map[string]interface{}{"replica": sh.replica, "sample": b},
+	}
+	if sh.store.retention > 0 {
+		args.MinID = fmt.Sprintf("%d-0", sh.store.now().Add(-sh.store.retention).UnixMilli())
+		args.Approx = true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+	stop := context.AfterFunc(sh.ctx, cancel)
+	defer stop()
+
+	if err := sh.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed adding the sample to redis: %w", err)
+	}
+
+	return nil
+}
+
+// follow reads the entries added by the other replicas until stopped
+func (sh *shared) follow() {
+	defer close(sh.done)
+
+	for {
+		_, err := sh.read(sh.ctx, readBlock)
+		if sh.ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			sh.store.logger.Error("failed reading the store from redis", "error", err)
+
+			select {
+			case <-sh.ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// read adds the entries after the last one read to the store, a negative
+// block doesn't wait for new entries. It returns how many were read
+func (sh *shared) read(ctx context.Context, block time.Duration) (int, error) {
+	streams, err := sh.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{sh.stream, sh.lastID},
+		Count:   readCount,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, stream := range streams {
+		sh.store.mu.Lock()
+		for _, msg := range stream.Messages {
+			sh.lastID = msg.ID
+			n++
+
+			if replica, _ := msg.Values["replica"].(string); replica == sh.replica {
+				continue
+			}
+
+			data, _ := msg.Values["sample"].(string)
+
+			var sample Sample
+			if err := json.Unmarshal([]byte(data), &sample); err != nil {
+				sh.store.logger.Warn("skipped invalid sample in redis", "id", msg.ID, "error", err)
+				continue
+			}
+
+			sh.store.insert(sample)
+		}
+		sh.store.mu.Unlock()
+	}
+
+	return n, nil
+}
+
+// stop waits for the running read to complete and closes the connection, it
+// can be called more than once
+func (sh *shared) stop(ctx context.Context) {
+	if sh.ctx.Err() != nil {
+		return
+	}
+
+	sh.cancel()
+
+	select {
+	case <-sh.done:
+	case <-ctx.Done():
+	}
+
+	if err := sh.client.Close(); err != nil {
+		sh.store.logger.Error("failed closing the redis connection", "error", err)
+	}
+}
@@ -0,0 +1,515 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/bus"
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/re-cinq/aether/pkg/log"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// Sample is the emissions of an instance over a scraping interval
+type Sample struct {
+	// When the metrics were collected
+	Time time.Time `json:"time"`
+
+	// The instance
+	Provider v1.Provider `json:"provider"`
+	Service  string      `json:"service"`
+	Name     string      `json:"name"`
+	Region   string      `json:"region"`
+	Zone     string      `json:"zone"`
+	Kind     string      `json:"kind"`
+	Labels   v1.Labels   `json:"labels,omitempty"`
+
+	// The emissions in gCO2eq
+	Operational float64 `json:"operational"`
+	Embodied    float64 `json:"embodied"`
+
+	// The lowest quality tier of the emissions, empty for the samples
+	// recorded without it
+	Quality v1.Tier `json:"quality,omitempty"`
+
+	// The vCPUs of the instance, their utilization in % and the hours they
+	// were observed over the interval, zero for the instances without a CPU
+	// metric and the samples recorded without them
+	VCPU        float64 `json:"vcpu,omitempty"`
+	Utilization float64 `json:"utilization,omitempty"`
+	Hours       float64 `json:"hours,omitempty"`
+
+	// The grid carbon intensity of the region in gCO2eq/kWh the emissions
+	// were calculated with, zero for the samples recorded without it
+	GridIntensity float64 `json:"gridIntensity,omitempty"`
+}
+
+// NewSample returns the sample of an instance whose emissions were calculated
+// over the interval
+func NewSample(i *v1.Instance, interval time.Duration) Sample {
+	s := Sample{
+		Provider: i.Provider,
+		Service:  i.Service,
+		Name:     i.Name,
+		Region:   i.Region,
+		Zone:     i.Zone,
+		Kind:     i.Kind,
+		Labels:   i.Labels,
+		Embodied: i.EmbodiedEmissions.Value,
+
+		GridIntensity: i.GridIntensity,
+	}
+
+	if i.EmbodiedEmissions.Quality.Power != "" {
+		s.Quality = i.EmbodiedEmissions.Quality.Tier()
+	}
+
+	for _, m := range i.Metrics {
+		s.Operational += m.Emissions.Value
+		if m.Emissions.Quality.Power != "" {
+			s.Quality = s.Quality.Lowest(m.Emissions.Quality.Tier())
+		}
+		if m.UpdatedAt.After(s.Time) {
+			s.Time = m.UpdatedAt
+		}
+	}
+
+	if s.Time.IsZero() {
+		s.Time = time.Now().UTC()
+	}
+
+	if cpu, ok := i.Metrics[v1.CPU.String()]; ok && cpu.UnitAmount > 0 {
+		s.VCPU = cpu.UnitAmount
+		s.Utilization = cpu.Usage
+		s.Hours = cpu.Observed.Within(interval).Hours()
+	}
+
+	return s
+}
+
+// VCPUHours returns the vCPU hours of the sample
+func (s *Sample) VCPUHours() float64 {
+	return s.VCPU * s.Hours
+}
+
+// Label returns the value of a field or a label of the sample
+func (s *Sample) Label(key string) string {
+	switch key {
+	case "provider":
+		return s.Provider.String()
+	case "service":
+		return s.Service
+	case "name", "instance":
+		return s.Name
+	case "region":
+		return s.Region
+	case "zone":
+		return s.Zone
+	case "kind":
+		return s.Kind
+	case "quality":
+		return string(s.Quality)
+	default:
+		return s.Labels[key]
+	}
+}
+
+// Store keeps the calculated emissions for the configured retention, so that
+// they can be queried over time ranges.
+// If a path is set, the samples are appended to it and loaded again at
+// startup. If Redis is set, the samples of all the replicas are shared
+// through a stream. Otherwise they are only kept in memory
+type Store struct {
+	// The samples sorted by time
+	samples []Sample
+
+	// The samples summed by hour and by day, the coarsest first
+	rollups []*rollup
+
+	retention time.Duration
+
+	// The file the samples are persisted to
+	path string
+	file *os.File
+
+	// The stream the samples are shared through
+	shared *shared
+
+	// The time zone the calendar periods start in
+	location *time.Location
+
+	// The scraping interval the emissions of the instances are calculated
+	// over
+	interval time.Duration
+
+	now    func() time.Time
+	logger *slog.Logger
+
+	mu sync.RWMutex
+}
+
+// Option configures the store
+type Option func(*Store)
+
+// WithInterval records the hours of the instances over the scraping interval
+// their emissions are calculated over
+func WithInterval(interval time.Duration) Option {
+	return func(s *Store) {
+		s.interval = interval
+	}
+}
+
+// New returns a store configured with the store config, loading the samples
+// persisted by a previous run
+func New(ctx context.Context, cfg *config.StoreConfig, opts ...Option) (*Store, error) {
+	location, err := loadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		retention: cfg.Retention,
+		path:      cfg.Path,
+		rollups:   []*rollup{newRollup(DailyStep), newRollup(HourlyStep)},
+		location:  location,
+		now:       time.Now,
+		logger:    log.FromContext(ctx),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if cfg.Redis.Address != "" {
+		if s.path != "" {
+			return nil, errors.New("the store path and redis are exclusive")
+		}
+
+		s.shared, err = newShared(ctx, s, &cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		s.shared.start()
+
+		return s, nil
+	}
+
+	if s.path == "" {
+		return s, nil
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Open returns a read-only copy of the samples persisted by an exporter,
+// e.g. to report on them while it runs. The file or the stream are left
+// untouched and the samples added to the copy are not persisted
+func Open(ctx context.Context, cfg *config.StoreConfig) (*Store, error) {
+	location, err := loadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		retention: cfg.Retention,
+		path:      cfg.Path,
+		rollups:   []*rollup{newRollup(DailyStep), newRollup(HourlyStep)},
+		location:  location,
+		now:       time.Now,
+		logger:    log.FromContext(ctx),
+	}
+
+	if cfg.Redis.Address != "" {
+		sh, err := newShared(ctx, s, &cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		sh.cancel()
+		sh.client.Close()
+
+		return s, nil
+	}
+
+	if s.path == "" {
+		return nil, errors.New("the store has no path nor redis set, the emissions are not persisted")
+	}
+
+	if err := s.read(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load reads the persisted samples and rewrites the file without the
+// expired ones
+func (s *Store) load() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed creating the store directory: %w", err)
+	}
+
+	if err := s.read(); err != nil {
+		return err
+	}
+
+	// compact the file, so that it only contains the retained samples
+	tmp := s.path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed compacting the store: %w", err)
+	}
+
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for i := range s.samples {
+		if err := enc.Encode(&s.samples[i]); err != nil {
+			out.Close()
+			return fmt.Errorf("failed compacting the store: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed compacting the store: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed compacting the store: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed compacting the store: %w", err)
+	}
+
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed opening the store: %w", err)
+	}
+
+	return nil
+}
+
+// read loads the persisted samples, sorted by time and without the expired
+// ones
+func (s *Store) read() error {
+	f, err := os.Open(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed opening the store: %w", err)
+	}
+
+	if f != nil {
+		var skipped int
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var sample Sample
+			// a partially written line is skipped
+			if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+				skipped++
+				continue
+			}
+			s.samples = append(s.samples, sample)
+		}
+		f.Close()
+
+		if skipped > 0 {
+			s.logger.Warn("skipped invalid samples in the store", "path", s.path, "count", skipped)
+		}
+
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed reading the store: %w", err)
+		}
+	}
+
+	sort.SliceStable(s.samples, func(i, j int) bool {
+		return s.samples[i].Time.Before(s.samples[j].Time)
+	})
+	s.prune()
+
+	for _, r := range s.rollups {
+		for i := range s.samples {
+			r.add(&s.samples[i])
+		}
+	}
+
+	return nil
+}
+
+// buffers are the buffers the samples are encoded to before they're
+// persisted, reused across the samples
+var buffers = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// Location returns the time zone the days and the months start in
+func (s *Store) Location() *time.Location {
+	return s.location
+}
+
+// Add records the sample, the context bounds sharing it with the other
+// replicas
+func (s *Store) Add(ctx context.Context, sample Sample) error {
+	if s.shared != nil {
+		if err := s.shared.publish(ctx, &sample); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.insert(sample)
+
+	if s.file == nil {
+		return nil
+	}
+
+	buf := buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		buffers.Put(buf)
+	}()
+
+	// the sample is followed by a newline
+	if err := json.NewEncoder(buf).Encode(&sample); err != nil {
+		return err
+	}
+
+	_, err := s.file.Write(buf.Bytes())
+	return err
+}
+
+// insert adds the sample to the sorted samples and drops the expired ones
+// NOTE: the caller must hold the lock
+func (s *Store) insert(sample Sample) {
+	// keep the samples sorted, they are mostly added in order
+	i := sort.Search(len(s.samples), func(i int) bool {
+		return s.samples[i].Time.After(sample.Time)
+	})
+	s.samples = append(s.samples, Sample{})
+	copy(s.samples[i+1:], s.samples[i:])
+	s.samples[i] = sample
+
+	for _, r := range s.rollups {
+		r.add(&sample)
+	}
+
+	s.prune()
+}
+
+// prune drops the samples older than the retention
+// NOTE: the caller must hold the lock
+func (s *Store) prune() {
+	if s.retention <= 0 {
+		return
+	}
+
+	cutoff := s.now().Add(-s.retention)
+	i := sort.Search(len(s.samples), func(i int) bool {
+		return !s.samples[i].Time.Before(cutoff)
+	})
+
+	if i > 0 {
+		s.samples = append([]Sample(nil), s.samples[i:]...)
+	}
+
+	for _, r := range s.rollups {
+		r.prune(cutoff)
+	}
+}
+
+// Select returns the samples collected in [from, to) that match the filter
+// A nil filter matches all the samples
+func (s *Store) Select(from, to time.Time, filter func(*Sample) bool) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	start := sort.Search(len(s.samples), func(i int) bool {
+		return !s.samples[i].Time.Before(from)
+	})
+
+	var out []Sample
+	for i := start; i < len(s.samples) && s.samples[i].Time.Before(to); i++ {
+		if filter == nil || filter(&s.samples[i]) {
+			out = append(out, s.samples[i])
+		}
+	}
+
+	return out
+}
+
+// Handle is used to fulfill the EventHandler interface and records the
+// instances whose emissions were calculated
+func (s *Store) Handle(ctx context.Context, e *bus.Event) {
+	if e.Type != v1.EmissionsCalculatedEvent {
+		return
+	}
+
+	instance, ok := e.Data.(v1.Instance)
+	if !ok {
+		s.logger.Error("store got an unknown event", "event", e)
+		return
+	}
+
+	if err := s.Add(ctx, NewSample(&instance, s.interval)); err != nil {
+		s.logger.Error("failed storing sample", "instance", instance.Name, "error", err)
+	}
+}
+
+// Stop is used to fulfill the EventHandler interface and closes the file
+// or the stream the samples are persisted to
+func (s *Store) Stop(ctx context.Context) {
+	if s.shared != nil {
+		s.shared.stop(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return
+	}
+
+	if err := s.file.Close(); err != nil {
+		s.logger.Error("failed closing the store", "error", err)
+	}
+	s.file = nil
+}
+
+// Latest returns the most recent sample of every instance that matches the
+// filter. A nil filter matches all the samples
+func (s *Store) Latest(filter func(*Sample) bool) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var out []Sample
+	for i := len(s.samples) - 1; i >= 0; i-- {
+		key := s.samples[i].Provider.String() + "/" + s.samples[i].Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if filter == nil || filter(&s.samples[i]) {
+			out = append(out, s.samples[i])
+		}
+	}
+
+	return out
+}
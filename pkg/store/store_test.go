@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/re-cinq/aether/pkg/config"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorePersistence(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	// the samples are pruned when loaded, so they must be recent
+	now := time.Now().UTC()
+	cfg := &config.StoreConfig{
+		Retention: 24 * time.Hour,
+		Path:      filepath.Join(t.TempDir(), "store", "emissions.jsonl"),
+	}
+
+	s, err := New(ctx, cfg)
+	assert.NoError(err)
+	s.now = func() time.Time { return now }
+
+	assert.NoError(s.Add(ctx, Sample{Time: now.Add(-time.Hour), Provider: v1.AWS, Name: "new"}))
+	assert.NoError(s.Add(ctx, Sample{Time: now.Add(-2 * time.Hour), Provider: v1.AWS, Name: "older"}))
+	s.Stop(ctx)
+
+	// the samples are loaded again and sorted by time
+	s, err = New(ctx, cfg)
+	assert.NoError(err)
+	samples := s.Select(now.Add(-24*time.Hour), now, nil)
+	assert.Len(samples, 2)
+	assert.Equal("older", samples[0].Name)
+	assert.Equal("new", samples[1].Name)
+
+	// the expired samples are dropped
+	s.now = func() time.Time { return now.Add(23 * time.Hour) }
+	assert.NoError(s.Add(ctx, Sample{Time: now.Add(23 * time.Hour), Provider: v1.AWS, Name: "latest"}))
+	samples = s.Select(time.Time{}, now.Add(24*time.Hour), nil)
+	assert.Len(samples, 2)
+	assert.Equal("new", samples[0].Name)
+	s.Stop(ctx)
+}
+
+func TestNewSample(t *testing.T) {
+	assert := require.New(t)
+
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	i := v1.NewInstance("vm", v1.AWS)
+	i.Region = "eu-west-1"
+	i.EmbodiedEmissions = v1.NewResourceEmission(2, v1.GCO2eqkWh)
+	i.EmbodiedEmissions.Quality = v1.Quality{Power: v1.PowerCurve}
+	i.GridIntensity = 300
+	cpu := v1.Metric{
+		Name:       "cpu",
+		Usage:      25,
+		UnitAmount: 2,
+		Emissions:  v1.NewResourceEmission(3, v1.GCO2eqkWh),
+		UpdatedAt:  updated,
+	}
+	cpu.Emissions.Quality = v1.Quality{Power: v1.PowerCurve, Intensity: v1.IntensityAnnual}
+	i.Metrics.Upsert(&cpu)
+
+	s := NewSample(i, 5*time.Minute)
+	assert.Equal(3.0, s.Operational)
+	assert.Equal(2.0, s.Embodied)
+	assert.Equal(updated, s.Time)
+	assert.Equal("eu-west-1", s.Label("region"))
+	assert.Equal(300.0, s.GridIntensity)
+
+	// the sample has the lowest tier of its emissions
+	assert.Equal(v1.TierMedium, s.Quality)
+	assert.Equal("medium", s.Label("quality"))
+
+	// the vCPUs and their utilization over the hours observed
+	assert.Equal(2.0, s.VCPU)
+	assert.Equal(25.0, s.Utilization)
+	assert.InDelta(10.0/60, s.VCPUHours(), 1e-9)
+
+	// launched during the interval
+	cpu.Observed = v1.NewWindow(updated, 3*time.Minute)
+	i.Metrics.Upsert(&cpu)
+	assert.Equal(0.05, NewSample(i, 5*time.Minute).Hours)
+}
+
+func TestStoreLatest(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Now().UTC()
+	ctx := context.Background()
+	s, err := New(ctx, &config.StoreConfig{})
+	assert.NoError(err)
+
+	assert.NoError(s.Add(ctx, Sample{Time: now.Add(-2 * time.Hour), Provider: v1.AWS, Name: "a", Operational: 1}))
+	assert.NoError(s.Add(ctx, Sample{Time: now.Add(-time.Hour), Provider: v1.AWS, Name: "a", Operational: 2}))
+	assert.NoError(s.Add(ctx, Sample{Time: now.Add(-time.Hour), Provider: v1.GCP, Name: "a", Operational: 3}))
+
+	latest := s.Latest(nil)
+	assert.Len(latest, 2)
+	for _, l := range latest {
+		if l.Provider == v1.AWS {
+			assert.Equal(2.0, l.Operational)
+		}
+	}
+
+	latest = s.Latest(func(s *Sample) bool { return s.Provider == v1.GCP })
+	assert.Len(latest, 1)
+}
+
+func TestStoreShared(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	server := miniredis.RunT(t)
+	now := time.Now().UTC()
+	cfg := &config.StoreConfig{
+		Retention: 24 * time.Hour,
+		Redis:     config.RedisConfig{Address: server.Addr()},
+	}
+
+	a, err := New(ctx, cfg)
+	assert.NoError(err)
+	defer a.Stop(ctx)
+
+	b, err := New(ctx, cfg)
+	assert.NoError(err)
+	defer b.Stop(ctx)
+
+	// the samples of a replica are seen by the other ones
+	assert.NoError(a.Add(ctx, Sample{Time: now.Add(-time.Hour), Provider: v1.AWS, Name: "a"}))
+	assert.NoError(b.Add(ctx, Sample{Time: now.Add(-2 * time.Hour), Provider: v1.GCP, Name: "b"}))
+
+	for _, s := range []*Store{a, b} {
+		assert.Eventually(func() bool {
+			return len(s.Select(now.Add(-24*time.Hour), now, nil)) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		samples := s.Select(now.Add(-24*time.Hour), now, nil)
+		assert.Equal("b", samples[0].Name)
+		assert.Equal("a", samples[1].Name)
+	}
+
+	// a new replica loads the existing samples
+	c, err := New(ctx, cfg)
+	assert.NoError(err)
+	assert.Len(c.Select(now.Add(-24*time.Hour), now, nil), 2)
+	c.Stop(ctx)
+
+	// the path and redis are exclusive
+	_, err = New(ctx, &config.StoreConfig{Path: "emissions.jsonl", Redis: cfg.Redis})
+	assert.Error(err)
+}
+
+func TestStoreOpen(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	cfg := &config.StoreConfig{
+		Path: filepath.Join(t.TempDir(), "emissions.jsonl"),
+	}
+
+	s, err := New(ctx, cfg)
+	assert.NoError(err)
+	assert.NoError(s.Add(ctx, Sample{Time: now, Provider: v1.AWS, Name: "vm"}))
+
+	// the samples of the running store can be read
+	ro, err := Open(ctx, cfg)
+	assert.NoError(err)
+	assert.Len(ro.Select(time.Time{}, now.Add(time.Hour), nil), 1)
+
+	// neither the file nor the running store are changed
+	assert.NoError(ro.Add(ctx, Sample{Time: now, Provider: v1.AWS, Name: "other"}))
+	s.Stop(ctx)
+
+	s, err = New(ctx, cfg)
+	assert.NoError(err)
+	assert.Len(s.Select(time.Time{}, now.Add(time.Hour), nil), 1)
+	s.Stop(ctx)
+
+	// the emissions must be persisted
+	_, err = Open(ctx, &config.StoreConfig{})
+	assert.Error(err)
+}
@@ -0,0 +1,330 @@
+// Package taxonomy maps the instance kinds of the providers to a common
+// taxonomy, their family and their size, so that the fleets of different
+// providers can be compared
+package taxonomy
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The families of the instances, by the ratio of their memory to their vCPUs
+// and their accelerators
+const (
+	General     = "general"
+	Compute     = "compute"
+	Memory      = "memory"
+	Accelerated = "accelerated"
+)
+
+// Class is the family and the size of an instance kind, the fields are empty
+// when unknown
+type Class struct {
+	Family   string
+	VCPU     float64
+	MemoryGB float64
+}
+
+// Classify returns the class of the kind of the provider from its name. The
+// sizes are the ones of the naming conventions of the providers, the memory
+// is estimated from the ratio of the family when the name doesn't have it
+func Classify(provider v1.Provider, kind string) Class {
+	switch provider {
+	case v1.AWS:
+		return classifyAWS(kind)
+	case v1.GCP:
+		return classifyGCP(kind)
+	case v1.Azure:
+		return classifyAzure(kind)
+	case v1.OCI:
+		return classifyOCI(kind)
+	case v1.DigitalOcean:
+		return classifyDigitalOcean(kind)
+	case v1.Scaleway:
+		return classifyScaleway(kind)
+	default:
+		return Class{}
+	}
+}
+
+// Apply sets the family, vcpu and memory_gb labels of the instance. The size
+// collected from the provider takes precedence over the one of the name
+func Apply(instance *v1.Instance) {
+	c := Classify(instance.Provider, instance.Kind)
+
+	if cpu, ok := instance.Metrics[v1.CPU.String()]; ok && cpu.UnitAmount > 0 {
+		c.VCPU = cpu.UnitAmount
+	}
+	if memory, ok := instance.Metrics[v1.Memory.String()]; ok && memory.Unit == v1.GB && memory.UnitAmount > 0 {
+		c.MemoryGB = memory.UnitAmount
+	}
+
+	// the labels are copied, they can be shared with the caches of the
+	// scrapers
+	if c.Family != "" {
+		instance.Labels = instance.Labels.With(v1.FamilyLabel, c.Family)
+	}
+	if c.VCPU > 0 {
+		instance.Labels = instance.Labels.With(v1.VCPULabel, format(c.VCPU))
+	}
+	if c.MemoryGB > 0 {
+		instance.Labels = instance.Labels.With(v1.MemoryGBLabel, format(c.MemoryGB))
+	}
+}
+
+// The families of the EC2 instances by the prefix of their type, the storage
+// optimized ones are general purpose
+var awsFamilies = map[string]string{
+	"a": General, "m": General, "mac": General, "t": General,
+	"d": General, "h": General, "i": General, "im": General, "is": General,
+	"c": Compute, "hpc": Compute,
+	"r": Memory, "u": Memory, "x": Memory, "z": Memory,
+	"dl": Accelerated, "f": Accelerated, "g": Accelerated, "inf": Accelerated,
+	"p": Accelerated, "trn": Accelerated, "vt": Accelerated,
+}
+
+// The GB of memory per vCPU of the EC2 families
+var awsMemory = map[string]float64{"m": 4, "c": 2, "r": 8, "x": 16, "z": 8}
+
+// The EC2 instance types, e.g. m5d.2xlarge: the prefix of the family, its
+// generation and features, and the size
+var awsKind = regexp.MustCompile(`^([a-z]+)[0-9-][a-z0-9-]*\.([0-9]*)(x?large|metal|[a-z]+)$`)
+
+// classifyAWS returns the class of an EC2 instance type. The sizes are
+// known from large, 2 vCPUs, on
+func classifyAWS(kind string) Class {
+	m := awsKind.FindStringSubmatch(kind)
+	if m == nil {
+		return Class{}
+	}
+
+	prefix := m[1]
+	c := Class{Family: awsFamilies[prefix]}
+
+	switch {
+	case m[3] == "large":
+		c.VCPU = 2
+	case m[3] == "xlarge" && m[2] == "":
+		c.VCPU = 4
+	case m[3] == "xlarge":
+		n, _ := strconv.Atoi(m[2])
+		c.VCPU = float64(4 * n)
+	default:
+		// the smaller sizes have burstable or fractional vCPUs, and the
+		// bare metal ones depend on the host
+		return c
+	}
+	c.MemoryGB = c.VCPU * awsMemory[prefix]
+
+	return c
+}
+
+// The GB of memory per vCPU of the GCE machine types
+var gcpMemory = map[string]float64{"standard": 4, "highmem": 8, "highcpu": 1}
+
+// classifyGCP returns the class of a GCE machine type, e.g. n2-highmem-8:
+// the series, the type and the vCPUs, or the vCPUs and the MB of memory of
+// the custom ones
+func classifyGCP(kind string) Class {
+	parts := strings.Split(kind, "-")
+	// the custom types of the first series have no series, e.g. custom-2-4096
+	if parts[0] == "custom" {
+		parts = append([]string{"n1"}, parts...)
+	}
+	if len(parts) < 2 {
+		return Class{}
+	}
+	series, typ := parts[0], parts[1]
+
+	c := Class{Family: General}
+	switch {
+	case series == "a2" || series == "a3" || series == "g2":
+		c.Family = Accelerated
+	case series == "m1" || series == "m2" || series == "m3" || series == "x4":
+		c.Family = Memory
+	case series == "c2" || series == "c2d" || series == "h3":
+		c.Family = Compute
+	case typ == "highcpu":
+		c.Family = Compute
+	case strings.HasSuffix(typ, "mem"):
+		c.Family = Memory
+	}
+
+	switch {
+	case typ == "custom" && len(parts) >= 4:
+		vCPU, _ := strconv.ParseFloat(parts[2], 64)
+		mb, _ := strconv.ParseFloat(parts[3], 64)
+		c.VCPU, c.MemoryGB = vCPU, mb/1024
+	case len(parts) == 3:
+		// the vCPUs of the accelerator-optimized types aren't in their
+		// name, it ends with their GPUs, e.g. a2-highgpu-1g
+		if vCPU, err := strconv.ParseFloat(parts[2], 64); err == nil {
+			c.VCPU = vCPU
+			c.MemoryGB = vCPU * gcpMemory[typ]
+		}
+	}
+
+	return c
+}
+
+// The families of the Azure sizes by their first letter
+var azureFamilies = map[byte]string{
+	'A': General, 'B': General, 'D': General, 'L': General,
+	'F': Compute, 'H': Compute,
+	'E': Memory, 'G': Memory, 'M': Memory,
+	'N': Accelerated,
+}
+
+// The GB of memory per vCPU of the Azure families
+var azureMemory = map[byte]float64{'D': 4, 'E': 8, 'F': 2, 'L': 8}
+
+// The Azure sizes, e.g. Standard_E16-4ds_v5: the family, the vCPUs and the
+// active ones of the constrained sizes
+var azureKind = regexp.MustCompile(`^(?i:standard_)?([A-Z]+)([0-9]+)(?:-([0-9]+))?`)
+
+// classifyAzure returns the class of an Azure size, the memory of the
+// constrained sizes is the one of their full size
+func classifyAzure(kind string) Class {
+	m := azureKind.FindStringSubmatch(kind)
+	if m == nil {
+		return Class{}
+	}
+
+	family := m[1][0]
+	vCPU, _ := strconv.ParseFloat(m[2], 64)
+
+	c := Class{
+		Family:   azureFamilies[family],
+		MemoryGB: vCPU * azureMemory[family],
+		VCPU:     vCPU,
+	}
+	if m[3] != "" {
+		c.VCPU, _ = strconv.ParseFloat(m[3], 64)
+	}
+
+	return c
+}
+
+// classifyOCI returns the family of an OCI shape, e.g. VM.Optimized3.Flex,
+// the size of the flexible shapes is the one collected from the provider
+func classifyOCI(kind string) Class {
+	parts := strings.Split(kind, ".")
+	if len(parts) < 2 {
+		return Class{}
+	}
+
+	series := parts[1]
+	switch {
+	case strings.HasPrefix(series, "GPU"):
+		return Class{Family: Accelerated}
+	case strings.HasPrefix(series, "Optimized") || strings.HasPrefix(series, "HPC"):
+		return Class{Family: Compute}
+	case strings.HasPrefix(series, "Standard") || strings.HasPrefix(series, "DenseIO"):
+		return Class{Family: General}
+	default:
+		return Class{}
+	}
+}
+
+// The families of the DigitalOcean sizes by their prefix, the storage
+// optimized ones have the memory ratio of the memory optimized ones
+var digitalOceanFamilies = map[string]string{
+	"s":     General,
+	"g":     General,
+	"gd":    General,
+	"c":     Compute,
+	"c2":    Compute,
+	"m":     Memory,
+	"m3":    Memory,
+	"so":    Memory,
+	"so1_5": Memory,
+}
+
+// The DigitalOcean sizes, e.g. s-2vcpu-4gb-amd or c-8-intel: the family,
+// the vCPUs and the memory when the slug has it
+var digitalOceanKind = regexp.MustCompile(`^([a-z0-9_]+)-([0-9]+)(?:vcpu-([0-9]+)(mb|gb))?(?:-|$)`)
+
+// classifyDigitalOcean returns the class of a DigitalOcean droplet size, the
+// memory of the CPU optimized sizes named after their vCPUs is 2 GB per
+// vCPU
+func classifyDigitalOcean(kind string) Class {
+	if strings.HasPrefix(kind, "gpu-") {
+		return Class{Family: Accelerated}
+	}
+
+	m := digitalOceanKind.FindStringSubmatch(kind)
+	if m == nil {
+		return Class{}
+	}
+
+	family, ok := digitalOceanFamilies[m[1]]
+	if !ok {
+		return Class{}
+	}
+
+	vCPU, _ := strconv.ParseFloat(m[2], 64)
+	c := Class{Family: family, VCPU: vCPU}
+
+	switch {
+	case m[4] == "gb":
+		c.MemoryGB, _ = strconv.ParseFloat(m[3], 64)
+	case m[4] == "mb":
+		mb, _ := strconv.ParseFloat(m[3], 64)
+		c.MemoryGB = mb / 1024
+	case family == Compute:
+		c.MemoryGB = 2 * vCPU
+	}
+
+	return c
+}
+
+// The prefixes of the Scaleway GPU Instance types
+var scalewayAccelerated = []string{"GPU-", "H100-", "L4-", "L40S-", "RENDER-"}
+
+// The families of the Scaleway Instance types named after their size, e.g.
+// PRO2-XS, by their range
+var scalewayFamilies = map[string]string{
+	"DEV1": General, "GP1": General, "PLAY2": General, "PRO2": General,
+	"ENT1": General, "STARDUST1": General,
+}
+
+// The Scaleway Instance types named after their vCPUs and memory, e.g.
+// POP2-HM-4C-32G: the range, the high memory or high CPU variant, the vCPUs
+// and the memory
+var scalewayKind = regexp.MustCompile(`^([A-Z0-9]+)(?:-(HM|HC|HN))?-([0-9]+)C-([0-9]+)G$`)
+
+// classifyScaleway returns the class of a Scaleway Instance type, the
+// Elastic Metal offers are unknown
+func classifyScaleway(kind string) Class {
+	for _, prefix := range scalewayAccelerated {
+		if strings.HasPrefix(kind, prefix) {
+			return Class{Family: Accelerated}
+		}
+	}
+
+	m := scalewayKind.FindStringSubmatch(kind)
+	if m == nil {
+		r, _, _ := strings.Cut(kind, "-")
+		return Class{Family: scalewayFamilies[r]}
+	}
+
+	family := General
+	switch m[2] {
+	case "HM":
+		family = Memory
+	case "HC":
+		family = Compute
+	}
+
+	vCPU, _ := strconv.ParseFloat(m[3], 64)
+	memoryGB, _ := strconv.ParseFloat(m[4], 64)
+	return Class{Family: family, VCPU: vCPU, MemoryGB: memoryGB}
+}
+
+// format returns the value of a size label
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,231 @@
+package taxonomy
+
+import (
+	"testing"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider v1.Provider
+		kind     string
+		class    Class
+	}{
+		{
+			name:     "aws general",
+			provider: v1.AWS,
+			kind:     "m5.large",
+			class:    Class{Family: General, VCPU: 2, MemoryGB: 8},
+		},
+		{
+			name:     "aws compute with features",
+			provider: v1.AWS,
+			kind:     "c6gn.4xlarge",
+			class:    Class{Family: Compute, VCPU: 16, MemoryGB: 32},
+		},
+		{
+			name:     "aws memory",
+			provider: v1.AWS,
+			kind:     "r6i.xlarge",
+			class:    Class{Family: Memory, VCPU: 4, MemoryGB: 32},
+		},
+		{
+			name:     "aws accelerated with unknown memory",
+			provider: v1.AWS,
+			kind:     "inf2.8xlarge",
+			class:    Class{Family: Accelerated, VCPU: 32},
+		},
+		{
+			name:     "aws burstable small size",
+			provider: v1.AWS,
+			kind:     "t3.micro",
+			class:    Class{Family: General},
+		},
+		{
+			name:     "aws bare metal",
+			provider: v1.AWS,
+			kind:     "u-6tb1.metal",
+			class:    Class{Family: Memory},
+		},
+		{
+			name:     "gcp standard",
+			provider: v1.GCP,
+			kind:     "n2-standard-8",
+			class:    Class{Family: General, VCPU: 8, MemoryGB: 32},
+		},
+		{
+			name:     "gcp highcpu",
+			provider: v1.GCP,
+			kind:     "e2-highcpu-4",
+			class:    Class{Family: Compute, VCPU: 4, MemoryGB: 4},
+		},
+		{
+			name:     "gcp memory series",
+			provider: v1.GCP,
+			kind:     "m1-ultramem-40",
+			class:    Class{Family: Memory, VCPU: 40},
+		},
+		{
+			name:     "gcp custom",
+			provider: v1.GCP,
+			kind:     "n2-custom-4-16384",
+			class:    Class{Family: General, VCPU: 4, MemoryGB: 16},
+		},
+		{
+			name:     "gcp first series custom",
+			provider: v1.GCP,
+			kind:     "custom-2-6144",
+			class:    Class{Family: General, VCPU: 2, MemoryGB: 6},
+		},
+		{
+			name:     "gcp accelerated",
+			provider: v1.GCP,
+			kind:     "a2-highgpu-1g",
+			class:    Class{Family: Accelerated},
+		},
+		{
+			name:     "azure general",
+			provider: v1.Azure,
+			kind:     "Standard_D4s_v5",
+			class:    Class{Family: General, VCPU: 4, MemoryGB: 16},
+		},
+		{
+			name:     "azure constrained",
+			provider: v1.Azure,
+			kind:     "Standard_E16-4ds_v5",
+			class:    Class{Family: Memory, VCPU: 4, MemoryGB: 128},
+		},
+		{
+			name:     "azure accelerated",
+			provider: v1.Azure,
+			kind:     "Standard_NC24ads_A100_v4",
+			class:    Class{Family: Accelerated, VCPU: 24},
+		},
+		{
+			name:     "oci general",
+			provider: v1.OCI,
+			kind:     "VM.Standard.E4.Flex",
+			class:    Class{Family: General},
+		},
+		{
+			name:     "oci compute",
+			provider: v1.OCI,
+			kind:     "BM.Optimized3.36",
+			class:    Class{Family: Compute},
+		},
+		{
+			name:     "oci accelerated",
+			provider: v1.OCI,
+			kind:     "VM.GPU.A10.1",
+			class:    Class{Family: Accelerated},
+		},
+		{
+			name:     "digitalocean basic",
+			provider: v1.DigitalOcean,
+			kind:     "s-2vcpu-4gb-amd",
+			class:    Class{Family: General, VCPU: 2, MemoryGB: 4},
+		},
+		{
+			name:     "digitalocean memory in mb",
+			provider: v1.DigitalOcean,
+			kind:     "s-1vcpu-512mb-10gb",
+			class:    Class{Family: General, VCPU: 1, MemoryGB: 0.5},
+		},
+		{
+			name:     "digitalocean storage",
+			provider: v1.DigitalOcean,
+			kind:     "so1_5-4vcpu-32gb-intel",
+			class:    Class{Family: Memory, VCPU: 4, MemoryGB: 32},
+		},
+		{
+			name:     "digitalocean cpu named after the vcpus",
+			provider: v1.DigitalOcean,
+			kind:     "c-8-intel",
+			class:    Class{Family: Compute, VCPU: 8, MemoryGB: 16},
+		},
+		{
+			name:     "digitalocean accelerated",
+			provider: v1.DigitalOcean,
+			kind:     "gpu-h100x1-80gb",
+			class:    Class{Family: Accelerated},
+		},
+		{
+			name:     "scaleway named after the size",
+			provider: v1.Scaleway,
+			kind:     "PRO2-XS",
+			class:    Class{Family: General},
+		},
+		{
+			name:     "scaleway high memory",
+			provider: v1.Scaleway,
+			kind:     "POP2-HM-4C-32G",
+			class:    Class{Family: Memory, VCPU: 4, MemoryGB: 32},
+		},
+		{
+			name:     "scaleway arm",
+			provider: v1.Scaleway,
+			kind:     "COPARM1-8C-32G",
+			class:    Class{Family: General, VCPU: 8, MemoryGB: 32},
+		},
+		{
+			name:     "scaleway accelerated",
+			provider: v1.Scaleway,
+			kind:     "H100-1-80G",
+			class:    Class{Family: Accelerated},
+		},
+		{
+			name:     "scaleway elastic metal",
+			provider: v1.Scaleway,
+			kind:     "EM-B112X-SSD",
+		},
+		{
+			name:     "unknown kind",
+			provider: v1.AWS,
+			kind:     "unknown",
+		},
+		{
+			name:     "unknown provider",
+			provider: v1.Prometheus,
+			kind:     "m5.large",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.class, Classify(test.provider, test.kind))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	assert := require.New(t)
+
+	labels := v1.Labels{v1.AccountLabel: "demo"}
+
+	i := v1.NewInstance("web", v1.AWS)
+	i.Kind = "m5.xlarge"
+	i.Labels = labels
+
+	// the vCPUs collected take precedence over the ones of the name
+	cpu := v1.NewMetric(v1.CPU.String())
+	cpu.UnitAmount = 2
+	i.Metrics.Upsert(cpu)
+
+	Apply(i)
+	assert.Equal("general", i.Labels[v1.FamilyLabel])
+	assert.Equal("2", i.Labels[v1.VCPULabel])
+	assert.Equal("16", i.Labels[v1.MemoryGBLabel])
+
+	// the labels shared with the scrapers are unchanged
+	assert.NotContains(labels, v1.FamilyLabel)
+
+	// nothing is set for the unknown kinds
+	i = v1.NewInstance("node", v1.Prometheus)
+	Apply(i)
+	assert.NotContains(i.Labels, v1.FamilyLabel)
+	assert.NotContains(i.Labels, v1.VCPULabel)
+	assert.NotContains(i.Labels, v1.MemoryGBLabel)
+}
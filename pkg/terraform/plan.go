@@ -0,0 +1,259 @@
+// Package terraform estimates the emissions of the instances added or
+// removed by a Terraform plan, so that they can be reviewed with the change
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/re-cinq/aether/pkg/calculator"
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+)
+
+// The actions of an instance change
+const (
+	Create = "create"
+	Delete = "delete"
+)
+
+// Change is an instance created or deleted by the plan. An update of the
+// type or a replacement is a deletion followed by a creation
+type Change struct {
+	Address  string      `json:"address"`
+	Action   string      `json:"action"`
+	Provider v1.Provider `json:"provider"`
+	Kind     string      `json:"kind"`
+	Region   string      `json:"region"`
+}
+
+// plan is the part of the JSON plan, as written by terraform show -json,
+// which is read
+type plan struct {
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Mode    string `json:"mode"`
+		Change  struct {
+			Actions []string       `json:"actions"`
+			Before  map[string]any `json:"before"`
+			After   map[string]any `json:"after"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+
+	Configuration struct {
+		ProviderConfig map[string]struct {
+			Expressions map[string]struct {
+				ConstantValue any `json:"constant_value"`
+			} `json:"expressions"`
+		} `json:"provider_config"`
+	} `json:"configuration"`
+}
+
+// resource describes where the type and the region of an instance resource
+// are set
+type resource struct {
+	provider v1.Provider
+	kind     string
+	region   string
+	zone     string
+}
+
+// The supported resources
+var resources = map[string]resource{
+	"aws_instance":                    {provider: v1.AWS, kind: "instance_type", zone: "availability_zone"},
+	"google_compute_instance":         {provider: v1.GCP, kind: "machine_type", zone: "zone"},
+	"azurerm_linux_virtual_machine":   {provider: v1.Azure, kind: "size", region: "location"},
+	"azurerm_windows_virtual_machine": {provider: v1.Azure, kind: "size", region: "location"},
+	"azurerm_virtual_machine":         {provider: v1.Azure, kind: "vm_size", region: "location"},
+}
+
+// ParsePlan returns the instances created and deleted by the JSON plan. The
+// region of the instances without one is read from the provider config, or
+// else defaults to region
+func ParsePlan(r io.Reader, region string) ([]Change, error) {
+	var p plan
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed parsing the plan: %w", err)
+	}
+
+	// the region of the AWS and Google providers
+	regions := make(map[v1.Provider]string)
+	for name, provider := range map[string]v1.Provider{"aws": v1.AWS, "google": v1.GCP} {
+		if cfg, ok := p.Configuration.ProviderConfig[name]; ok {
+			if v, ok := cfg.Expressions["region"].ConstantValue.(string); ok {
+				regions[provider] = v
+			}
+		}
+	}
+
+	var changes []Change
+	for _, rc := range p.ResourceChanges {
+		res, ok := resources[rc.Type]
+		if !ok || rc.Mode == "data" {
+			continue
+		}
+
+		instance := func(action string, values map[string]any) Change {
+			c := Change{
+				Address:  rc.Address,
+				Action:   action,
+				Provider: res.provider,
+				Kind:     attribute(values, res.kind),
+				Region:   attribute(values, res.region),
+			}
+
+			// the Google machine types can be URLs
+			if c.Kind != "" {
+				c.Kind = path.Base(c.Kind)
+			}
+
+			// the region of the zone, e.g. eu-west-1a or europe-west1-b
+			if c.Region == "" {
+				if zone := attribute(values, res.zone); zone != "" {
+					c.Region = zoneRegion(res.provider, path.Base(zone))
+				}
+			}
+			if c.Region == "" {
+				c.Region = regions[res.provider]
+			}
+			if c.Region == "" {
+				c.Region = region
+			}
+
+			return c
+		}
+
+		actions := rc.Change.Actions
+		deleted := slices.Contains(actions, Delete)
+		created := slices.Contains(actions, Create)
+
+		// an update only changes the emissions with the type or the region
+		if slices.Contains(actions, "update") {
+			before, after := instance(Delete, rc.Change.Before), instance(Create, rc.Change.After)
+			if before.Kind != after.Kind || before.Region != after.Region {
+				deleted, created = true, true
+			}
+		}
+
+		if deleted {
+			changes = append(changes, instance(Delete, rc.Change.Before))
+		}
+		if created {
+			changes = append(changes, instance(Create, rc.Change.After))
+		}
+	}
+
+	return changes, nil
+}
+
+// attribute returns the value of a string attribute, it's empty when the
+// value is only known after the apply
+func attribute(values map[string]any, name string) string {
+	if name == "" {
+		return ""
+	}
+	v, _ := values[name].(string)
+	return v
+}
+
+// zoneRegion returns the region of a zone
+func zoneRegion(provider v1.Provider, zone string) string {
+	switch provider {
+	case v1.AWS:
+		// eu-west-1a
+		return strings.TrimRight(zone, "abcdefghijklmnopqrstuvwxyz")
+	case v1.GCP:
+		// europe-west1-b
+		if i := strings.LastIndex(zone, "-"); i > 0 {
+			return zone[:i]
+		}
+	}
+	return zone
+}
+
+// Estimate is the emissions of the instances changed by a plan over a
+// duration, in gCO2eq
+type Estimate struct {
+	Instances []InstanceEstimate `json:"instances"`
+
+	// The emissions of the created instances
+	Added float64 `json:"added"`
+
+	// The emissions of the deleted instances
+	Removed float64 `json:"removed"`
+
+	// The added emissions less the removed ones
+	Delta float64 `json:"delta"`
+
+	Utilization float64       `json:"utilization"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// InstanceEstimate is the emissions of an instance changed by a plan, the
+// error is set when they can't be estimated, e.g. for unknown types
+type InstanceEstimate struct {
+	Change
+
+	Emissions float64 `json:"emissions"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// EstimatePlan estimates the emissions of the changed instances running at
+// the utilization over the duration, with the emission factors in use
+func EstimatePlan(ctx context.Context, changes []Change, utilization float64, duration time.Duration) (*Estimate, error) {
+	if duration <= 0 {
+		return nil, errors.New("the duration must be positive")
+	}
+	if utilization < 0 || utilization > 100 {
+		return nil, fmt.Errorf("invalid utilization %g, must be between 0 and 100", utilization)
+	}
+
+	e := &Estimate{
+		Instances:   make([]InstanceEstimate, 0, len(changes)),
+		Utilization: utilization,
+		Duration:    duration,
+	}
+
+	for _, c := range changes {
+		ie := InstanceEstimate{Change: c}
+
+		switch {
+		case c.Kind == "":
+			ie.Error = "the instance type is only known after apply"
+		case c.Region == "":
+			ie.Error = "the region is only known after apply, set it with the default region"
+		default:
+			est, err := calculator.EstimateEmissions(ctx, &calculator.EstimateRequest{
+				Provider:    c.Provider,
+				Kind:        c.Kind,
+				Region:      c.Region,
+				Utilization: utilization,
+				Duration:    duration,
+			})
+			if err != nil {
+				ie.Error = err.Error()
+				break
+			}
+
+			ie.Emissions = est.Total
+			if c.Action == Create {
+				e.Added += est.Total
+			} else {
+				e.Removed += est.Total
+			}
+		}
+
+		e.Instances = append(e.Instances, ie)
+	}
+
+	e.Delta = e.Added - e.Removed
+
+	return e, nil
+}
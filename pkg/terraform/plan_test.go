@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/re-cinq/aether/pkg/types/v1"
+	factors "github.com/re-cinq/aether/pkg/types/v1/factors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlan(t *testing.T) {
+	assert := require.New(t)
+
+	f, err := os.Open("testdata/plan.json")
+	assert.NoError(err)
+	defer f.Close()
+
+	changes, err := ParsePlan(f, "default")
+	assert.NoError(err)
+	assert.Equal([]Change{
+		{Address: "aws_instance.web[0]", Action: Create, Provider: v1.AWS, Kind: "t-2", Region: "north-1"},
+		{Address: "aws_instance.batch", Action: Delete, Provider: v1.AWS, Kind: "t-4", Region: "north-1"},
+		{Address: "aws_instance.batch", Action: Create, Provider: v1.AWS, Kind: "t-2", Region: "north-1"},
+		{Address: "google_compute_instance.legacy", Action: Delete, Provider: v1.GCP, Kind: "n1-standard-1", Region: "europe-west1"},
+		{Address: "azurerm_linux_virtual_machine.vm", Action: Delete, Provider: v1.Azure, Kind: "Standard_B1s", Region: "westeurope"},
+		{Address: "azurerm_linux_virtual_machine.vm", Action: Create, Provider: v1.Azure, Kind: "Standard_B2s", Region: "westeurope"},
+	}, changes)
+}
+
+func TestEstimatePlan(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"aws-default.yaml":  "name: aws\naveragePUE: 1.5\n",
+		"aws-grid.yaml":     "- region: north-1\n  co2e: 0.0001\n",
+		"aws-use.yaml":      "- architecture: A\n  minwatts: 10\n  maxwatts: 20\n",
+		"aws-embodied.yaml": "- type: t-2\n  total: 315360\n  vCPU: 2\n  totalVCPU: 4\n  architecture: A\n- type: t-4\n  total: 315360\n  vCPU: 4\n  totalVCPU: 4\n  architecture: A\n",
+	} {
+		assert.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	dataPath := factors.DataPath
+	factors.DataPath = dir
+	defer func() { factors.DataPath = dataPath }()
+
+	e, err := EstimatePlan(context.Background(), []Change{
+		{Address: "aws_instance.a", Action: Create, Provider: v1.AWS, Kind: "t-2", Region: "north-1"},
+		{Address: "aws_instance.b", Action: Delete, Provider: v1.AWS, Kind: "t-4", Region: "north-1"},
+		{Address: "aws_instance.c", Action: Create, Provider: v1.AWS, Kind: "t-8", Region: "north-1"},
+		{Address: "aws_instance.d", Action: Create, Provider: v1.AWS, Region: "north-1"},
+	}, 50, 2*time.Hour)
+	assert.NoError(err)
+	assert.Len(e.Instances, 4)
+
+	// see TestEstimateEmissions of the calculator, the t-4 has twice the
+	// vCPUs of the t-2
+	assert.InDelta(15, e.Added, 0.0001)
+	assert.InDelta(30, e.Removed, 0.0001)
+	assert.InDelta(-15, e.Delta, 0.0001)
+	assert.NotEmpty(e.Instances[2].Error)
+	assert.NotEmpty(e.Instances[3].Error)
+
+	_, err = EstimatePlan(context.Background(), nil, 50, 0)
+	assert.Error(err)
+}
@@ -7,6 +7,9 @@ type ResourceEmissions struct {
 
 	// The unit of the emission
 	Unit EmissionUnit
+
+	// How the value was calculated
+	Quality Quality
 }
 
 // New instance of the resource emission
@@ -3,6 +3,7 @@ package v1
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 // Provider where the resource consumption data is collected from
@@ -20,26 +21,52 @@ const (
 	// Azure cloud API
 	Azure Provider = azureString
 
+	// DigitalOcean API
+	DigitalOcean Provider = digitalOceanString
+
 	// Google cloud platform API
 	GCP Provider = gcpString
 
+	// Oracle cloud infrastructure API
+	OCI Provider = ociString
+
 	// Prometheus API for baremetal and kubernetes support
 	Prometheus Provider = prometheusString
 
+	// Scaleway API
+	Scaleway Provider = scalewayString
+
 	// Constant string definitions
-	awsString        = "aws"
-	azureString      = "azure"
-	gcpString        = "gcp"
-	prometheusString = "prometheus"
+	awsString          = "aws"
+	azureString        = "azure"
+	digitalOceanString = "digitalocean"
+	gcpString          = "gcp"
+	ociString          = "oci"
+	prometheusString   = "prometheus"
+	scalewayString     = "scaleway"
 )
 
 // Providers Lookup map for listing all the supported providers
 // as well as deserializing them
 var Providers = map[string]Provider{
-	awsString:        AWS,
-	azureString:      Azure,
-	gcpString:        GCP,
-	prometheusString: Prometheus,
+	awsString:          AWS,
+	azureString:        Azure,
+	digitalOceanString: DigitalOcean,
+	gcpString:          GCP,
+	ociString:          OCI,
+	prometheusString:   Prometheus,
+	scalewayString:     Scaleway,
+}
+
+// RegisterProvider adds a provider implemented outside of the exporter, e.g.
+// by a plugin, to the supported providers. It must be called at startup
+func RegisterProvider(name string) (Provider, error) {
+	if _, exists := Providers[name]; exists {
+		return "", fmt.Errorf("provider %s already exists", name)
+	}
+
+	Providers[name] = Provider(name)
+	return Provider(name), nil
 }
 
 // Return the provider as string
@@ -22,3 +22,19 @@ func TestProviderParser(t *testing.T) {
 
 	assert.Equal(t, testProvider.TestProvider, Prometheus)
 }
+
+func TestRegisterProvider(t *testing.T) {
+	defer delete(Providers, "mainframe")
+
+	p, err := RegisterProvider("mainframe")
+	assert.Nil(t, err)
+	assert.Equal(t, Provider("mainframe"), p)
+
+	var testProvider testProviderStruct
+	err = json.Unmarshal([]byte(`{"provider": "mainframe"}`), &testProvider)
+	assert.Nil(t, err)
+	assert.Equal(t, p, testProvider.TestProvider)
+
+	_, err = RegisterProvider("aws")
+	assert.EqualError(t, err, "provider aws already exists")
+}
@@ -0,0 +1,70 @@
+package v1
+
+import "errors"
+
+// ErrorCode identifies the cause of an error for the integrators, it's
+// added to the logs, the metrics and the API responses
+type ErrorCode string
+
+// Error codes
+const (
+	// The region has no grid intensity in the emission factors
+	CodeRegionNotFound ErrorCode = "region_not_found"
+
+	// The instance type has no specs in the emission factors
+	CodeKindNotFound ErrorCode = "kind_not_found"
+
+	// The emission factors couldn't be refreshed, the previous ones are
+	// still used
+	CodeDatasetStale ErrorCode = "dataset_stale"
+
+	// The provider API rejected the requests because of their rate
+	CodeProviderThrottled ErrorCode = "provider_throttled"
+
+	// Any other error
+	CodeInternal ErrorCode = "internal"
+)
+
+// Error is an error with a code. The errors are compared by identity,
+// the details are added by wrapping them:
+//
+//	fmt.Errorf("%w: %s", v1.ErrRegionNotFound, region)
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// The errors with a code
+var (
+	ErrRegionNotFound = &Error{
+		Code:    CodeRegionNotFound,
+		Message: "region does not exist in factors for provider",
+	}
+	ErrKindNotFound = &Error{
+		Code:    CodeKindNotFound,
+		Message: "instance type does not exist in factors for provider",
+	}
+	ErrDatasetStale = &Error{
+		Code:    CodeDatasetStale,
+		Message: "emission factors are stale",
+	}
+	ErrProviderThrottled = &Error{
+		Code:    CodeProviderThrottled,
+		Message: "provider API is throttling the requests",
+	}
+)
+
+// Code returns the code of the error, or of the first error it wraps with a
+// code, CodeInternal when none has one
+func Code(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	err := fmt.Errorf("%w: eu-north-9", ErrRegionNotFound)
+	assert.ErrorIs(t, err, ErrRegionNotFound)
+	assert.Equal(t, CodeRegionNotFound, Code(err))
+	assert.Equal(t, "region does not exist in factors for provider: eu-north-9", err.Error())
+
+	err = fmt.Errorf("scraping failed: %w", errors.Join(errors.New("failed"), ErrProviderThrottled))
+	assert.Equal(t, CodeProviderThrottled, Code(err))
+
+	assert.Equal(t, CodeInternal, Code(errors.New("failed")))
+	assert.Equal(t, CodeInternal, Code(nil))
+}
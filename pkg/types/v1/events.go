@@ -11,4 +11,12 @@ const (
 	// used to speicfy the event when emissions for instances have been
 	// calculated
 	EmissionsCalculatedEvent
+
+	// used to specify the event when the collection cycle of an account has
+	// completed, after the metrics of all its instances were published
+	CollectionCompletedEvent
+
+	// used to specify the event when the emissions of all the instances of
+	// a collection cycle have been calculated
+	AccountSummaryEvent
 )
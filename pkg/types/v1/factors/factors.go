@@ -1,9 +1,12 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 
 	git "github.com/go-git/go-git/v5"
 
@@ -12,12 +15,46 @@ import (
 )
 
 const (
-	emissionDataRepoURL = "https://github.com/re-cinq/emissions-data/"
+	// EmissionDataRepoURL is the default repo the emission factors are
+	// pulled from
+	EmissionDataRepoURL = "https://github.com/re-cinq/emissions-data/"
 	repoPath            = "/tmp/emissions-data/"
 )
 
 var DataPath string = fmt.Sprintf("%s/data/v1", repoPath)
 
+// V2DataPath is the directory of the v2 dataset of the local repo, the
+// wattage of the instance types
+var V2DataPath string = fmt.Sprintf("%s/data/v2", repoPath)
+
+// repoURL is the repo the emission factors are pulled from, e.g. a mirror
+// reachable without internet egress
+var repoURL = EmissionDataRepoURL
+
+// SetRepoURL sets the repo the emission factors are pulled from, the
+// default one when empty. It must be called before they're pulled
+func SetRepoURL(url string) {
+	if url == "" {
+		url = EmissionDataRepoURL
+	}
+	repoURL = url
+}
+
+// RepoURL returns the repo the emission factors are pulled from
+func RepoURL() string {
+	return repoURL
+}
+
+// dataPaths are the directories of the emission factors of the providers
+// which aren't in the emissions-data repo, e.g. the plugins
+var dataPaths = map[v1.Provider]string{}
+
+// RegisterDataPath reads the emission factors of the provider from the
+// directory, whatever the data path. It must be called at startup
+func RegisterDataPath(provider v1.Provider, dataPath string) {
+	dataPaths[provider] = dataPath
+}
+
 // Emission data is currently stored as files in our emissions-data repo.
 // Each file is named "{provider}-{emissionFactor}" where emissionFactor
 // may be default, embodied, grid, and use.
@@ -25,6 +62,10 @@ var DataPath string = fmt.Sprintf("%s/data/v1", repoPath)
 // GetProviderEmissionFactors reads in emission data for a specified
 // provider and stores them into the emissionFactors struct for calulating
 func GetProviderEmissionFactors(provider v1.Provider, dataPath string) (*EmissionFactors, error) {
+	if p, ok := dataPaths[provider]; ok {
+		dataPath = p
+	}
+
 	var err error
 	ef := &EmissionFactors{
 		Provider: provider,
@@ -45,6 +86,16 @@ func GetProviderEmissionFactors(provider v1.Provider, dataPath string) (*Emissio
 		return nil, err
 	}
 
+	err = ef.getMemoryData(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ef.getAcceleratorData(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
 	return ef, nil
 }
 
@@ -63,10 +114,12 @@ func (ef *EmissionFactors) getProviderDefaults(dataPath string) error {
 
 // getCoefficeintData reads the {provider}-grid.yaml file into a slice
 // of Coefficient structs, and then converts the data into a map of
-// region: co2e to be returned
+// region: co2e to be returned. The country and the continent of the regions
+// are optional
 func (ef *EmissionFactors) getCoefficientData(dataPath string) error {
 	data := []Coefficient{}
 	ef.Coefficient = make(CoefficientData)
+	ef.Locations = make(LocationData)
 
 	fp := filepath.Join(dataPath, fmt.Sprintf("%s-grid.yaml", ef.Provider))
 	if err := readYamlData(fp, &data); err != nil {
@@ -75,6 +128,9 @@ func (ef *EmissionFactors) getCoefficientData(dataPath string) error {
 
 	for _, c := range data {
 		ef.Coefficient[c.Region] = c.Co2e
+		if c.Country != "" || c.Continent != "" {
+			ef.Locations[c.Region] = c.Location
+		}
 	}
 
 	return nil
@@ -109,6 +165,7 @@ func (ef *EmissionFactors) getEmbodiedData(dataPath string) error {
 	if err != nil {
 		return err
 	}
+	ef.Use = machineSpecsData
 
 	fp := filepath.Join(dataPath, fmt.Sprintf("%s-embodied.yaml", ef.Provider))
 	err = readYamlData(fp, &data)
@@ -139,6 +196,84 @@ func (ef *EmissionFactors) getEmbodiedData(dataPath string) error {
 	return nil
 }
 
+// memoryFile is the {provider}-memory.yaml file, the DIMMs of the hosts of
+// the instance families
+type memoryFile struct {
+	DIMMs    []DIMM        `yaml:"dimms"`
+	Families []MemorySpecs `yaml:"families"`
+}
+
+// getMemoryData maps the DIMMs of the hosts of the instance families by
+// family. The file is optional, the memory emissions of the families
+// without DIMMs are calculated with the memory coefficient of the provider
+func (ef *EmissionFactors) getMemoryData(dataPath string) error {
+	data := memoryFile{}
+	ef.Memory = make(MemoryData)
+
+	fp := filepath.Join(dataPath, fmt.Sprintf("%s-memory.yaml", ef.Provider))
+	err := readYamlData(fp, &data)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dimms := make(map[string]DIMM, len(data.DIMMs))
+	for _, d := range data.DIMMs {
+		dimms[d.Type] = d
+	}
+
+	for _, f := range data.Families {
+		dimm, ok := dimms[f.DIMMType]
+		if !ok || dimm.GB <= 0 {
+			return fmt.Errorf("error: DIMM type (%s) of the family (%s) does not exist", f.DIMMType, f.Family)
+		}
+		f.DIMM = dimm
+		ef.Memory[f.Family] = f
+	}
+
+	return nil
+}
+
+// acceleratorsFile is the {provider}-accelerators.yaml file, the embodied
+// emissions of the accelerators and the accelerators of the machine types
+type acceleratorsFile struct {
+	Accelerators []AcceleratorSpecs `yaml:"accelerators"`
+	Kinds        []KindAccelerators `yaml:"kinds"`
+}
+
+// getAcceleratorData maps the accelerators by type and the accelerators of
+// the machine types by machine type. The file is optional, the accelerators
+// without embodied emissions are accounted for with the default ones
+func (ef *EmissionFactors) getAcceleratorData(dataPath string) error {
+	data := acceleratorsFile{}
+	ef.Accelerators = make(AcceleratorData)
+	ef.KindAccelerators = make(KindAcceleratorData)
+
+	fp := filepath.Join(dataPath, fmt.Sprintf("%s-accelerators.yaml", ef.Provider))
+	err := readYamlData(fp, &data)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, a := range data.Accelerators {
+		ef.Accelerators[a.Type] = a
+	}
+
+	for _, k := range data.Kinds {
+		if _, ok := ef.Accelerators[k.Accelerator]; !ok {
+			return fmt.Errorf("error: accelerator type (%s) of the kind (%s) does not exist", k.Accelerator, k.Kind)
+		}
+		ef.KindAccelerators[k.Kind] = k
+	}
+
+	return nil
+}
+
 // readYamlData reads a yaml file and returns a slice of bytes
 func readYamlData(filePath string, data interface{}) error {
 	yamlFile, err := os.ReadFile(filePath)
@@ -154,18 +289,45 @@ func readYamlData(filePath string, data interface{}) error {
 	return nil
 }
 
+// FactorsDataVersion returns the commit of the local emissions data repo
+func FactorsDataVersion() (string, error) {
+	return RepoVersion(repoPath)
+}
+
+// RepoVersion returns the commit checked out in the local repo
+func RepoVersion(repoPath string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	return head.Hash().String(), nil
+}
+
 // CloneAndUpdateFactorsData wraps the CloneAndUpdateRepo
 // function with private variables passed.
 func CloneAndUpdateFactorsData() error {
-	return CloneAndUpdateRepo(repoPath, emissionDataRepoURL)
+	return CloneAndUpdateRepo(repoPath, repoURL)
 }
 
 // CloneAndUpdateRepo checks if a local repo exists and is
 // up to date with origin. Otherwise, it deletes and clones
-// it to the repoPath.
+// it to the repoPath. The local repo is cloned again when its
+// origin isn't the repoURL anymore
 func CloneAndUpdateRepo(repoPath, repoURL string) error {
 	// Get repo info if it exists locally
 	repo, err := git.PlainOpen(repoPath)
+	if err == nil && !hasOrigin(repo, repoURL) {
+		if err := os.RemoveAll(repoPath); err != nil {
+			return err
+		}
+		err = git.ErrRepositoryNotExists
+	}
 	if err == nil {
 		// repo exists, check if its up to date with upstream
 		errFetch := repo.Fetch(&git.FetchOptions{Depth: 1})
@@ -192,3 +354,13 @@ func CloneAndUpdateRepo(repoPath, repoURL string) error {
 	}
 	return err
 }
+
+// hasOrigin tells whether the origin of the repo is the URL
+func hasOrigin(repo *git.Repository, url string) bool {
+	origin, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(origin.Config().URLs, url)
+}
@@ -63,6 +63,7 @@ func TestGetCoefficientData(t *testing.T) {
 		provider v1.Provider
 		hasError bool
 		expRes   CoefficientData
+		expLocs  LocationData
 		expErr   string
 	}{
 		{
@@ -77,6 +78,10 @@ func TestGetCoefficientData(t *testing.T) {
 				"France Central":  6.7e-05,
 				"Finland Central": 77,
 			},
+			expLocs: LocationData{
+				"us-central1": {Country: "US", Continent: "north-america"},
+				"us-east1":    {Country: "US"},
+			},
 			expErr: "",
 		},
 		{
@@ -84,6 +89,7 @@ func TestGetCoefficientData(t *testing.T) {
 			provider: "bad",
 			hasError: true,
 			expRes:   CoefficientData{},
+			expLocs:  LocationData{},
 			expErr:   "yaml: line 1: mapping values are not allowed in this context",
 		},
 	}
@@ -93,6 +99,7 @@ func TestGetCoefficientData(t *testing.T) {
 			ef := &EmissionFactors{Provider: test.provider}
 			err := ef.getCoefficientData(testDataPath)
 			assert.Equalf(t, ef.Coefficient, test.expRes, "Result should be: %v, got: %v", test.expRes, ef.Coefficient)
+			assert.Equal(t, test.expLocs, ef.Locations)
 			if test.hasError {
 				assert.EqualErrorf(t, err, test.expErr, "Error should be: %v, got: %v", test.expErr, err)
 			} else {
@@ -284,6 +291,120 @@ func TestGetEmbodiedData(t *testing.T) {
 	}
 }
 
+func TestGetMemoryData(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider v1.Provider
+		hasError bool
+		expRes   MemoryData
+		expErr   string
+	}{
+		{
+			name:     "pass: read and set the DIMMs of the families",
+			provider: "fake",
+			hasError: false,
+			expRes: MemoryData{
+				"e2": {
+					Family:   "e2",
+					DIMMType: "DDR4-32GB",
+					DIMMs:    12,
+					DIMM:     DIMM{Type: "DDR4-32GB", GB: 32, IdleWatts: 1.5, ActiveWatts: 4.5},
+				},
+			},
+			expErr: "",
+		},
+		{
+			name:     "pass: the memory data is optional",
+			provider: "fake2",
+			hasError: false,
+			expRes:   MemoryData{},
+			expErr:   "",
+		},
+		{
+			name:     "fail: unknown DIMM type",
+			provider: "bad",
+			hasError: true,
+			expRes:   MemoryData{},
+			expErr:   "error: DIMM type (DDR5-64GB) of the family (e2) does not exist",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ef := &EmissionFactors{Provider: test.provider}
+			err := ef.getMemoryData(testDataPath)
+			assert.Equalf(t, test.expRes, ef.Memory, "Result should be: %v, got: %v", test.expRes, ef.Memory)
+			if test.hasError {
+				assert.EqualErrorf(t, err, test.expErr, "Error should be: %v, got: %v", test.expErr, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestMemorySpecsHostGB(t *testing.T) {
+	m := MemorySpecs{DIMMs: 12, DIMM: DIMM{GB: 32}}
+	assert.Equal(t, 384.0, m.HostGB())
+}
+
+func TestGetAcceleratorData(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider v1.Provider
+		hasError bool
+		expRes   AcceleratorData
+		expKinds KindAcceleratorData
+		expErr   string
+	}{
+		{
+			name:     "pass: read the accelerators and the ones of the kinds",
+			provider: "fake",
+			hasError: false,
+			expRes: AcceleratorData{
+				"nvidia-tesla-a100": {Type: "nvidia-tesla-a100", EmbodiedKgCO2e: 150},
+				"nvidia-h100-80gb":  {Type: "nvidia-h100-80gb", EmbodiedKgCO2e: 200},
+			},
+			expKinds: KindAcceleratorData{
+				"a2-highgpu-1g": {Kind: "a2-highgpu-1g", Accelerator: "nvidia-tesla-a100", Count: 1},
+			},
+			expErr: "",
+		},
+		{
+			name:     "pass: the accelerator data is optional",
+			provider: "fake2",
+			hasError: false,
+			expRes:   AcceleratorData{},
+			expKinds: KindAcceleratorData{},
+			expErr:   "",
+		},
+		{
+			name:     "fail: unknown accelerator type",
+			provider: "bad",
+			hasError: true,
+			expRes: AcceleratorData{
+				"nvidia-tesla-a100": {Type: "nvidia-tesla-a100", EmbodiedKgCO2e: 150},
+			},
+			expKinds: KindAcceleratorData{},
+			expErr:   "error: accelerator type (tpu-v4) of the kind (a2-highgpu-1g) does not exist",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ef := &EmissionFactors{Provider: test.provider}
+			err := ef.getAcceleratorData(testDataPath)
+			assert.Equal(t, test.expRes, ef.Accelerators)
+			assert.Equal(t, test.expKinds, ef.KindAccelerators)
+			if test.hasError {
+				assert.EqualErrorf(t, err, test.expErr, "Error should be: %v, got: %v", test.expErr, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
 func TestGetEmissionFactors(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -316,6 +437,10 @@ func TestGetEmissionFactors(t *testing.T) {
 					"France Central":  6.7e-05,
 					"Finland Central": 77,
 				},
+				Locations: LocationData{
+					"us-central1": {Country: "US", Continent: "north-america"},
+					"us-east1":    {Country: "US"},
+				},
 				Embodied: EmbodiedData{
 					"e2-standard-2": {
 						MachineType:                   "e2-standard-2",
@@ -348,6 +473,47 @@ func TestGetEmissionFactors(t *testing.T) {
 						},
 					},
 				},
+				Use: MachineSpecsData{
+					"Broadwell": {
+						Architecture: "Broadwell",
+						MinWatts:     0.7128342245989304,
+						MaxWatts:     3.3857473048128344,
+						GBPerChip:    69.6470588235294,
+					},
+					"Haswell": {
+						Architecture: "Haswell",
+						MinWatts:     1.9005681818181814,
+						MaxWatts:     5.9688982156043195,
+						GBPerChip:    27.310344827586206,
+					},
+					"Skylake": {
+						Architecture: "Skylake",
+						MinWatts:     0.6446044454253452,
+						MaxWatts:     3.8984738056304855,
+						GBPerChip:    80.43037974683544,
+					},
+					"EPYC 2nd Gen": {
+						Architecture: "EPYC 2nd Gen",
+						MinWatts:     0.4742621527777778,
+						MaxWatts:     1.5751872939814815,
+						GBPerChip:    129.77777777777777,
+					},
+				},
+				Memory: MemoryData{
+					"e2": {
+						Family:   "e2",
+						DIMMType: "DDR4-32GB",
+						DIMMs:    12,
+						DIMM:     DIMM{Type: "DDR4-32GB", GB: 32, IdleWatts: 1.5, ActiveWatts: 4.5},
+					},
+				},
+				Accelerators: AcceleratorData{
+					"nvidia-tesla-a100": {Type: "nvidia-tesla-a100", EmbodiedKgCO2e: 150},
+					"nvidia-h100-80gb":  {Type: "nvidia-h100-80gb", EmbodiedKgCO2e: 200},
+				},
+				KindAccelerators: KindAcceleratorData{
+					"a2-highgpu-1g": {Kind: "a2-highgpu-1g", Accelerator: "nvidia-tesla-a100", Count: 1},
+				},
 			},
 			expErr: "",
 		},
@@ -2,20 +2,38 @@ package v1
 
 import v1 "github.com/re-cinq/aether/pkg/types/v1"
 
-type CoefficientData map[string]float64       // map[region] = co2e
-type EmbodiedData map[string]Embodied         // key = Machine type (n2-standard-
-type MachineSpecsData map[string]MachineSpecs // key = architecture name (Haswell, Skylake, ..)
+type CoefficientData map[string]float64              // map[region] = co2e
+type EmbodiedData map[string]Embodied                // key = Machine type (n2-standard-
+type MachineSpecsData map[string]MachineSpecs        // key = architecture name (Haswell, Skylake, ..)
+type MemoryData map[string]MemorySpecs               // key = instance family (r5, n2, ..)
+type AcceleratorData map[string]AcceleratorSpecs     // key = accelerator type (nvidia-tesla-a100, ..)
+type KindAcceleratorData map[string]KindAccelerators // key = machine type (p4d.24xlarge, ..)
+type LocationData map[string]Location                // key = region
 
 type EmissionFactors struct {
 	Provider    v1.Provider
-	Coefficient CoefficientData // key is region
-	Embodied    EmbodiedData    // key is machineType
+	Coefficient CoefficientData  // key is region
+	Locations   LocationData     // key is region
+	Embodied    EmbodiedData     // key is machineType
+	Use         MachineSpecsData // key is architecture
+	Memory      MemoryData       // key is instance family
+
+	Accelerators     AcceleratorData     // key is accelerator type
+	KindAccelerators KindAcceleratorData // key is machineType
 	*ProviderDefaults
 }
 
 type Coefficient struct {
-	Region string
-	Co2e   float64
+	Region   string
+	Co2e     float64
+	Location `yaml:",inline"`
+}
+
+// Location is where a region is, used to fall back on the average grid
+// intensity of its country or continent when it has none
+type Location struct {
+	Country   string `yaml:"country,omitempty"`
+	Continent string `yaml:"continent,omitempty"`
 }
 
 // TotalEmbodied assumes base manufacturing emissions of 1000 kgCO2e
@@ -50,13 +68,59 @@ type MachineSpecs struct {
 	GBPerChip    float64 `yaml:"chip"`
 }
 
+// DIMM is a type of memory module and its power when idle and fully active
+type DIMM struct {
+	Type        string
+	GB          float64 `yaml:"gb"`
+	IdleWatts   float64 `yaml:"idleWatts"`
+	ActiveWatts float64 `yaml:"activeWatts"`
+}
+
+// MemorySpecs is the memory of the hosts of an instance family: the type
+// and the number of their DIMMs
+type MemorySpecs struct {
+	Family   string
+	DIMMType string  `yaml:"dimm"`
+	DIMMs    float64 `yaml:"dimms"`
+	DIMM     DIMM    `yaml:"-"`
+}
+
+// HostGB returns the memory of the hosts of the family
+func (m *MemorySpecs) HostGB() float64 {
+	return m.DIMMs * m.DIMM.GB
+}
+
+// AcceleratorSpecs are the manufacturing emissions of an accelerator, a GPU
+// or a TPU, attached to the instances on top of their share of the server
+type AcceleratorSpecs struct {
+	Type           string
+	EmbodiedKgCO2e float64 `yaml:"embodied"`
+}
+
+// KindAccelerators are the accelerators the instances of a machine type come
+// with, e.g. the 8 A100 of the p4d.24xlarge
+type KindAccelerators struct {
+	Kind        string
+	Accelerator string `yaml:"accelerator"`
+	Count       float64
+}
+
 type ProviderDefaults struct {
 	Provider                 string  `yaml:"name"`
 	MinWatts                 float64 `yaml:"minWatts"`
 	MaxWatts                 float64 `yaml:"maxWatts"`
-	HDDStorageWatts          float64 `yaml:"hddStorageWatts"`
-	SSDStorageWatts          float64 `yaml:"ssdStorageWatts"`
+	HDDStorageWatts          float64 `yaml:"hddStorageWatts"` // per TB
+	SSDStorageWatts          float64 `yaml:"ssdStorageWatts"` // per TB
+	HDDIOJoules              float64 `yaml:"hddIOJoules"`     // per read or write operation
+	SSDIOJoules              float64 `yaml:"ssdIOJoules"`     // per read or write operation
+	HDDGBJoules              float64 `yaml:"hddGBJoules"`     // per GB read or written
+	SSDGBJoules              float64 `yaml:"ssdGBJoules"`     // per GB read or written
 	NetworkingKilloWattHours float64 `yaml:"networkingKilloWattHours"`
 	MemoryKilloWattHours     float64 `yaml:"memoryKilloWattHours"`
 	AveragePUE               float64 `yaml:"averagePUE"`
+
+	// per GB transferred to another region and to the internet, the
+	// networking coefficient when unset
+	InterRegionNetworkingKilloWattHours float64 `yaml:"interRegionNetworkingKilloWattHours"`
+	InternetNetworkingKilloWattHours    float64 `yaml:"internetNetworkingKilloWattHours"`
 }
@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// The directory of the emission factors in the emissions data repo
+const dataDir = "data/v1"
+
+// CheckoutFactorsVersion wraps the CheckoutVersion function with the local
+// emissions data repo
+func CheckoutFactorsVersion(version, dir string) error {
+	return CheckoutVersion(repoPath, version, dir)
+}
+
+// CheckoutVersion writes the emission factors of a version of the repo, a
+// tag or a branch, to dir. The version is fetched when it's not in the local
+// repo, which is a shallow clone of the default branch
+func CheckoutVersion(repoPath, version, dir string) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(version))
+	if err != nil {
+		if err := fetchVersion(repo, version); err != nil {
+			return fmt.Errorf("failed fetching the emission factors version %s: %w", version, err)
+		}
+
+		hash, err = repo.ResolveRevision(plumbing.Revision(version))
+		if err != nil {
+			return fmt.Errorf("unknown emission factors version %s: %w", version, err)
+		}
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	data, err := tree.Tree(dataDir)
+	if err != nil {
+		return fmt.Errorf("the version %s has no %s directory: %w", version, dataDir, err)
+	}
+
+	return data.Files().ForEach(func(f *object.File) error {
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+
+		fp := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+			return err
+		}
+
+		return os.WriteFile(fp, []byte(contents), 0o644)
+	})
+}
+
+// fetchVersion fetches the tag, or else the branch, named version
+func fetchVersion(repo *git.Repository, version string) error {
+	var err error
+	for _, spec := range []string{
+		fmt.Sprintf("+refs/tags/%[1]s:refs/tags/%[1]s", version),
+		fmt.Sprintf("+refs/heads/%[1]s:refs/heads/%[1]s", version),
+	} {
+		err = repo.Fetch(&git.FetchOptions{
+			RefSpecs: []gitconfig.RefSpec{gitconfig.RefSpec(spec)},
+			Depth:    1,
+			Tags:     git.NoTags,
+		})
+		if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckoutVersion(t *testing.T) {
+	assert := require.New(t)
+
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	assert.NoError(err)
+	wt, err := repo.Worktree()
+	assert.NoError(err)
+
+	grid := filepath.Join(repoPath, "data", "v1", "fake-grid.yaml")
+	assert.NoError(os.MkdirAll(filepath.Dir(grid), 0o755))
+
+	commit := func(contents string) {
+		assert.NoError(os.WriteFile(grid, []byte(contents), 0o644))
+		_, err := wt.Add("data/v1/fake-grid.yaml")
+		assert.NoError(err)
+		_, err = wt.Commit("update", &git.CommitOptions{
+			Author: &object.Signature{Name: "test", When: time.Now()},
+		})
+		assert.NoError(err)
+	}
+
+	commit("- region: eu\n  co2e: 0.0001\n")
+	head, err := repo.Head()
+	assert.NoError(err)
+	_, err = repo.CreateTag("v1.0.0", head.Hash(), nil)
+	assert.NoError(err)
+
+	commit("- region: eu\n  co2e: 0.0002\n")
+
+	// the tagged version is written, not the checked out one
+	dir := t.TempDir()
+	assert.NoError(CheckoutVersion(repoPath, "v1.0.0", dir))
+	b, err := os.ReadFile(filepath.Join(dir, "fake-grid.yaml"))
+	assert.NoError(err)
+	assert.Equal("- region: eu\n  co2e: 0.0001\n", string(b))
+
+	// the repo has no remote to fetch unknown versions from
+	assert.Error(CheckoutVersion(repoPath, "v9.9.9", t.TempDir()))
+}
+
+func TestCloneAndUpdateRepoMirror(t *testing.T) {
+	assert := require.New(t)
+
+	// two mirrors of the repo
+	source := func() string {
+		path := t.TempDir()
+		repo, err := git.PlainInit(path, false)
+		assert.NoError(err)
+		wt, err := repo.Worktree()
+		assert.NoError(err)
+		assert.NoError(os.WriteFile(filepath.Join(path, "README.md"), []byte("factors"), 0o644))
+		_, err = wt.Add("README.md")
+		assert.NoError(err)
+		_, err = wt.Commit("init", &git.CommitOptions{
+			Author: &object.Signature{Name: "test", When: time.Now()},
+		})
+		assert.NoError(err)
+		return path
+	}
+	first, second := source(), source()
+
+	origin := func(repoPath string) []string {
+		repo, err := git.PlainOpen(repoPath)
+		assert.NoError(err)
+		remote, err := repo.Remote(git.DefaultRemoteName)
+		assert.NoError(err)
+		return remote.Config().URLs
+	}
+
+	repoPath := filepath.Join(t.TempDir(), "emissions-data")
+	assert.NoError(CloneAndUpdateRepo(repoPath, first))
+	assert.Equal([]string{first}, origin(repoPath))
+
+	// up to date
+	assert.NoError(CloneAndUpdateRepo(repoPath, first))
+
+	// cloned again from the new mirror
+	assert.NoError(CloneAndUpdateRepo(repoPath, second))
+	assert.Equal([]string{second}, origin(repoPath))
+}
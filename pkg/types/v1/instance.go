@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/re-cinq/aether/pkg/log"
 )
@@ -38,6 +39,23 @@ type Instance struct {
 	// - m6.2xlarge (AWS)
 	Kind string
 
+	// The CPU architecture of the instance, when known
+	// Examples: x86_64, arm64
+	Architecture string
+
+	// The CPU platform the instance runs on, when known, used to select the
+	// wattage of the CPU
+	// Examples: Intel Cascade Lake (GCP), AMD Milan (GCP)
+	CPUPlatform string
+
+	// When the instance was last started, when known
+	StartedAt time.Time
+
+	// The vCPUs of the whole host when the instance is a host dedicated to
+	// a tenant, e.g. a GCP sole-tenant node, zero otherwise. The emissions
+	// of its kind are scaled to all the vCPUs of the host
+	HostVCPU float64
+
 	// The metrics collection for the specific service
 	Metrics Metrics
 
@@ -47,10 +65,29 @@ type Instance struct {
 	// The embodied emissions for the service
 	EmbodiedEmissions ResourceEmissions
 
+	// The grid carbon intensity in gCO2eq/kWh the operational emissions
+	// were calculated with, zero until they're calculated
+	GridIntensity float64
+
 	// Labels associated with the service
 	Labels Labels
 }
 
+// Observed returns how long the instance was observed over the interval, the
+// longest any of its metrics was. It's longer than the interval when its
+// metrics are collected less often than every scrape
+func (i *Instance) Observed(interval time.Duration) time.Duration {
+	if len(i.Metrics) == 0 {
+		return interval
+	}
+
+	var observed time.Duration
+	for _, m := range i.Metrics {
+		observed = max(observed, m.Observed.Span(interval))
+	}
+	return observed
+}
+
 // Create a new instance.
 // We need both the name and the provider
 func NewInstance(name string, provider Provider) *Instance {
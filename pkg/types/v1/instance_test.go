@@ -2,6 +2,7 @@ package v1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -54,3 +55,43 @@ func TestInstanceOperations(t *testing.T) {
 	// Make sure the resource is the same
 	assert.Equal(t, *r, existingResource)
 }
+
+func TestInstanceObserved(t *testing.T) {
+	interval := 5 * time.Minute
+	window := NewWindow(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), interval)
+
+	instance := NewInstance("1234", Prometheus)
+
+	// without metrics the instance is observed over the whole interval
+	assert.Equal(t, interval, instance.Observed(interval))
+
+	// and so are the metrics without a window
+	instance.Metrics.Upsert(&Metric{Name: CPU.String()})
+	assert.Equal(t, interval, instance.Observed(interval))
+
+	// launched 2 minutes into the window
+	launched := window.Intersect(Window{Start: window.Start.Add(2 * time.Minute), End: window.End.Add(time.Hour)})
+	assert.Equal(t, window.Start.Add(2*time.Minute), launched.Start)
+	assert.Equal(t, window.End, launched.End)
+	instance.Metrics.Upsert(&Metric{Name: CPU.String(), Observed: launched})
+	assert.Equal(t, 3*time.Minute, instance.Observed(interval))
+
+	// the longest metric is the instance's
+	instance.Metrics.Upsert(&Metric{Name: Memory.String(), Observed: NewWindow(window.End, 4*time.Minute)})
+	assert.Equal(t, 4*time.Minute, instance.Observed(interval))
+
+	// terminated before the window
+	gone := window.Intersect(NewWindow(window.Start, time.Hour))
+	assert.False(t, gone.IsZero())
+	assert.Equal(t, time.Duration(0), gone.Within(interval))
+
+	// observed longer than the interval
+	assert.Equal(t, interval, NewWindow(window.End, time.Hour).Within(interval))
+	assert.Equal(t, time.Hour, NewWindow(window.End, time.Hour).Span(interval))
+	assert.Equal(t, 3*time.Minute, launched.Span(interval))
+	assert.Equal(t, interval, Window{}.Span(interval))
+
+	// collected every hour, e.g. the network
+	instance.Metrics.Upsert(&Metric{Name: Network.String(), Observed: NewWindow(window.End, time.Hour)})
+	assert.Equal(t, time.Hour, instance.Observed(interval))
+}
@@ -1,8 +1,91 @@
 package v1
 
+// AccountLabel is set on the instances to the account they are scraped from
+const AccountLabel = "account"
+
+// The labels the instances are grouped by across providers, derived by the
+// grouping rules
+const (
+	ApplicationLabel = "application"
+	TeamLabel        = "team"
+)
+
+// ProjectLabel is set on the GCP instances to their project
+const ProjectLabel = "project"
+
+// NameLabel is set on the instances whose name isn't their ID, e.g. to the
+// Name tag of the EC2 instances
+const NameLabel = "Name"
+
+// ProviderIDLabel is set on the instances of the Kubernetes nodes to their
+// provider ID, e.g. aws:///eu-west-1a/i-0123456789abcdef0, so that they
+// can be matched with the instances of the cloud providers
+const ProviderIDLabel = "provider_id"
+
+// MediaLabel is set on the storage metrics to the media of the disk, ssd or
+// hdd. The disks of unknown media are accounted for as SSDs
+const MediaLabel = "media"
+
+// The media of the disks
+const (
+	MediaSSD = "ssd"
+	MediaHDD = "hdd"
+)
+
+// The labels of the network metrics collected from the flow logs, the
+// direction of the flows, egress or ingress, and the class of their
+// destination
+const (
+	DirectionLabel   = "direction"
+	DestinationLabel = "destination"
+)
+
+// The classes of the destinations of the flows
+const (
+	DestinationIntraRegion = "intra-region"
+	DestinationInterRegion = "inter-region"
+	DestinationInternet    = "internet"
+)
+
+// SiteLabel is set on the instances running on premises to their site,
+// e.g. the ID of the AWS Outpost, whose grid intensity is configured
+const SiteLabel = "site"
+
+// The labels of the instances with attached accelerators, the type of the
+// accelerators, e.g. nvidia-tesla-a100, and their number
+const (
+	AcceleratorLabel      = "accelerator"
+	AcceleratorCountLabel = "accelerator_count"
+)
+
+// GridFallbackLabel is set on the metrics of the instances of the regions
+// missing from the emission factors to the level of the average grid
+// intensity used instead: country, continent or global
+const GridFallbackLabel = "grid_fallback"
+
+// SourceLabel is set on the emissions imported from third parties to the
+// source of their records, e.g. the sustainability API of a vendor
+const SourceLabel = "source"
+
+// TagLabelPrefix prefixes the labels of the tags of the instances, e.g. the
+// team tag is the tag_team label
+const TagLabelPrefix = "tag_"
+
 // Labels definition
 type Labels map[string]string
 
+// With returns a copy of the labels with the label set, so that the labels
+// shared with a cache are left unchanged
+func (l Labels) With(key, value string) Labels {
+	out := make(Labels, len(l)+1)
+	for k, v := range l {
+		out[k] = v
+	}
+	out[key] = value
+
+	return out
+}
+
 // Helper method for adding a label
 func (l *Labels) Add(key, value string) {
 	// Initialize the map if it doesn't exist
@@ -25,3 +108,11 @@ func (l *Labels) Delete(key string) {
 	// Delete it
 	delete(*l, key)
 }
+
+// The labels of the normalized taxonomy of the instances, the family of their
+// kind, e.g. memory, and their vCPUs and GB of memory
+const (
+	FamilyLabel   = "family"
+	VCPULabel     = "vcpu"
+	MemoryGBLabel = "memory_gb"
+)
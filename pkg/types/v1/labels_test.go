@@ -58,3 +58,17 @@ func TestDeleteLabelEmptyMap(t *testing.T) {
 	_, exists := instance.Labels[key]
 	assert.False(t, exists)
 }
+
+func TestLabelsWith(t *testing.T) {
+	labels := Labels{"foo": "bar"}
+
+	out := labels.With(AccountLabel, "prod")
+	assert.Equal(t, Labels{"foo": "bar", AccountLabel: "prod"}, out)
+
+	// the labels are left unchanged
+	assert.Equal(t, Labels{"foo": "bar"}, labels)
+
+	// nil labels are copied too
+	var empty Labels
+	assert.Equal(t, Labels{AccountLabel: "prod"}, empty.With(AccountLabel, "prod"))
+}
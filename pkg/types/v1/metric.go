@@ -37,12 +37,31 @@ type Metric struct {
 	// - Gb: in case of Ram
 	Unit ResourceUnit
 
+	// The average power drawn by the resource in watts when it's measured,
+	// e.g. by an in-cluster agent, zero otherwise. The emissions are
+	// calculated from it instead of the wattage curve
+	Power float64
+
+	// The read and write operations per second and bytes per second of
+	// the resource averaged over the window, e.g. of a disk, zero when
+	// unknown. The storage emissions add the energy of the I/O to the one
+	// of the capacity
+	IOPS       float64
+	Throughput float64
+
 	// Emissions at a specific point in time
 	Emissions ResourceEmissions
 
 	// Time of update
 	UpdatedAt time.Time
 
+	// The window the resource was observed in, when it's shorter than the
+	// scraping window because the instance was launched or terminated
+	// during it, or longer because the metric is collected less often than
+	// every scrape, e.g. the network every hour. It's zero when the resource
+	// was observed over the whole scraping window
+	Observed Window
+
 	// The resource specific labels
 	Labels Labels
 }
@@ -0,0 +1,105 @@
+package v1
+
+import "encoding/json"
+
+// PowerSource is where the power drawn by a resource, or its embodied
+// factor, comes from
+type PowerSource string
+
+const (
+	// The power measured on the resource, e.g. by an in-cluster agent
+	PowerMeasured PowerSource = "measured"
+
+	// The wattage curve or the embodied factor of the instance type
+	PowerCurve PowerSource = "curve"
+
+	// The generic min and max wattage of the CPU platform or architecture,
+	// or the embodied factor estimated from the platform
+	PowerFallback PowerSource = "fallback"
+)
+
+// IntensitySource is where the grid carbon intensity comes from
+type IntensitySource string
+
+const (
+	// The grid intensity at the time of the emissions
+	IntensityRealTime IntensitySource = "realtime"
+
+	// The annual average grid intensity of the region
+	IntensityAnnual IntensitySource = "annual"
+)
+
+// Tier ranks the confidence in an emission value
+type Tier string
+
+const (
+	TierHigh   Tier = "high"
+	TierMedium Tier = "medium"
+	TierLow    Tier = "low"
+)
+
+// tierRanks orders the tiers, the best first
+var tierRanks = map[Tier]int{
+	TierHigh:   0,
+	TierMedium: 1,
+	TierLow:    2,
+}
+
+// Lowest returns the lowest of the two tiers, an empty tier is ignored
+func (t Tier) Lowest(other Tier) Tier {
+	if t == "" || tierRanks[other] > tierRanks[t] {
+		return other
+	}
+	return t
+}
+
+// Quality tells how an emission value was calculated, so that the consumers
+// can weight or filter the low confidence values
+type Quality struct {
+	// Where the power or the embodied factor comes from
+	Power PowerSource `json:"power"`
+
+	// Where the grid intensity comes from, empty for the embodied emissions
+	Intensity IntensitySource `json:"intensity,omitempty"`
+}
+
+// Tier returns the confidence in the value: the sources are ranked from 0,
+// the best, to 2 and the tier is the one of their sum. A measured power
+// with the annual intensity is as good as a curve with a real-time one, the
+// embodied emissions are ranked by their factor only
+func (q Quality) Tier() Tier {
+	rank := 0
+
+	switch q.Power {
+	case PowerMeasured:
+	case PowerCurve:
+		rank++
+	default:
+		rank += 2
+	}
+
+	if q.Intensity == IntensityAnnual {
+		rank++
+	}
+
+	switch {
+	case rank <= 1:
+		return TierHigh
+	case rank == 2:
+		return TierMedium
+	default:
+		return TierLow
+	}
+}
+
+// MarshalJSON adds the tier to the sources
+func (q Quality) MarshalJSON() ([]byte, error) {
+	type quality Quality
+	return json.Marshal(struct {
+		quality
+		Tier Tier `json:"tier"`
+	}{
+		quality: quality(q),
+		Tier:    q.Tier(),
+	})
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualityTier(t *testing.T) {
+	tests := []struct {
+		name     string
+		quality  Quality
+		expected Tier
+	}{
+		{
+			name:     "measured power with a real-time intensity",
+			quality:  Quality{Power: PowerMeasured, Intensity: IntensityRealTime},
+			expected: TierHigh,
+		},
+		{
+			name:     "measured power with the annual intensity",
+			quality:  Quality{Power: PowerMeasured, Intensity: IntensityAnnual},
+			expected: TierHigh,
+		},
+		{
+			name:     "curve with the annual intensity",
+			quality:  Quality{Power: PowerCurve, Intensity: IntensityAnnual},
+			expected: TierMedium,
+		},
+		{
+			name:     "fallback with the annual intensity",
+			quality:  Quality{Power: PowerFallback, Intensity: IntensityAnnual},
+			expected: TierLow,
+		},
+		{
+			name:     "embodied curve",
+			quality:  Quality{Power: PowerCurve},
+			expected: TierHigh,
+		},
+		{
+			name:     "embodied fallback",
+			quality:  Quality{Power: PowerFallback},
+			expected: TierMedium,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.New(t).Equal(test.expected, test.quality.Tier())
+		})
+	}
+}
+
+func TestTierLowest(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(TierLow, TierHigh.Lowest(TierLow))
+	assert.Equal(TierMedium, TierMedium.Lowest(TierHigh))
+	assert.Equal(TierHigh, Tier("").Lowest(TierHigh))
+	assert.Equal(TierMedium, TierMedium.Lowest(""))
+}
+
+func TestQualityJSON(t *testing.T) {
+	assert := require.New(t)
+
+	b, err := json.Marshal(Quality{Power: PowerCurve, Intensity: IntensityAnnual})
+	assert.NoError(err)
+	assert.JSONEq(`{"power":"curve","intensity":"annual","tier":"medium"}`, string(b))
+}
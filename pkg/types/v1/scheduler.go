@@ -1,6 +1,9 @@
 package v1
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Scheduler interface {
 
@@ -11,7 +14,98 @@ type Scheduler interface {
 	Cancel()
 }
 
+// Scraper collects the data for a single provider account.
+// The scheduling is handled by the Scheduler, so a scraper only needs to know
+// how to run a single collection
 type Scraper interface {
-	Start(context.Context)
-	Stop(context.Context)
+	// Scrape runs a single collection cycle for the window, publishes the results
+	// and returns the amount of instances collected
+	Scrape(ctx context.Context, window Window) (int, error)
+
+	// Stop tears down any client or connection used by the scraper
+	Stop(ctx context.Context)
+
+	// Flush drops the cached data, so that it's fetched again by the next scrape
+	Flush()
+
+	// Provider returns the provider the scraper is collecting data from
+	Provider() Provider
+
+	// Account returns the unique identifier of the account being scraped
+	Account() string
+}
+
+// Checker is implemented by the scrapers verifying the credentials of their
+// account and the permissions their scrapes need, without collecting
+// anything
+type Checker interface {
+	// Check makes a dry-run call of every API the scrapes use and returns
+	// the outcome for the permission of each one
+	Check(ctx context.Context) []Permission
+}
+
+// Window is the time range a scrape collects the data for
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NewWindow returns the window of the given duration ending at end
+func NewWindow(end time.Time, duration time.Duration) Window {
+	return Window{
+		Start: end.Add(-duration),
+		End:   end,
+	}
+}
+
+// Duration returns the length of the window
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// IsZero tells if the window is unset
+func (w Window) IsZero() bool {
+	return w.Start.IsZero() && w.End.IsZero()
+}
+
+// Intersect returns the part of the window within the other one, it's empty
+// and not zero when they don't overlap
+func (w Window) Intersect(other Window) Window {
+	start := w.Start
+	if other.Start.After(start) {
+		start = other.Start
+	}
+
+	end := w.End
+	if other.End.Before(end) {
+		end = other.End
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	return Window{
+		Start: start,
+		End:   end,
+	}
+}
+
+// Within returns how much of an interval the window covers, the whole
+// interval when the window is zero
+func (w Window) Within(interval time.Duration) time.Duration {
+	if w.IsZero() {
+		return interval
+	}
+	return min(max(w.Duration(), 0), interval)
+}
+
+// Span returns how long the values observed over the window are accounted
+// for at a scrape of the interval: the part of the interval it covers, or
+// the whole window when it's longer, e.g. the metrics collected every hour
+// by scrapes of 5 minutes
+func (w Window) Span(interval time.Duration) time.Duration {
+	if d := w.Duration(); !w.IsZero() && d > interval {
+		return d
+	}
+	return w.Within(interval)
 }
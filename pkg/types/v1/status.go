@@ -0,0 +1,50 @@
+package v1
+
+import "time"
+
+// ScrapeStatus is the status of the scraping of a provider account
+type ScrapeStatus struct {
+	// The provider account
+	Provider Provider `json:"provider"`
+	Account  string   `json:"account"`
+
+	// Whether the account is scraped normally or paused after too many failures
+	Degraded bool `json:"degraded"`
+
+	// Whether the scraper of the account failed to be created, e.g. because
+	// of broken credentials, and is retried. The failures are the attempts
+	// to create it
+	Initializing bool `json:"initializing,omitempty"`
+
+	// When the last scrape and the last successful one started
+	LastAttempt time.Time `json:"lastAttempt"`
+	LastSuccess time.Time `json:"lastSuccess"`
+
+	// How long the last scrape took
+	LastDuration time.Duration `json:"lastDuration"`
+
+	// The amount of instances collected by the last successful scrape
+	Instances int `json:"instances"`
+
+	// The amount of scrapes that failed in a row, and the last error
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastError           string    `json:"lastError,omitempty"`
+	LastErrorCode       ErrorCode `json:"lastErrorCode,omitempty"`
+
+	// When the permissions of the account were last checked and their
+	// outcome, for the scrapers checking them
+	LastCheck   time.Time    `json:"lastCheck"`
+	Permissions []Permission `json:"permissions,omitempty"`
+}
+
+// Permission is the outcome of the check of a permission the scrapes of an
+// account need, made with a dry-run call of the API needing it
+type Permission struct {
+	// The permission, e.g. ec2:DescribeInstances
+	Name string `json:"name"`
+
+	// Whether the call succeeded, and its error otherwise, e.g. the
+	// permission is missing or the credentials are invalid
+	Granted bool   `json:"granted"`
+	Error   string `json:"error,omitempty"`
+}
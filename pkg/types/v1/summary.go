@@ -0,0 +1,43 @@
+package v1
+
+// Collection is a completed collection cycle of an account
+type Collection struct {
+	Provider Provider
+	Account  string
+
+	// The window collected, it spans the missed intervals collected with it
+	Window Window
+
+	// The amount of instances whose metrics were published
+	Instances int
+
+	// The error of the collection, nil when it succeeded
+	Err error
+}
+
+// AccountSummary rolls up the emissions of the instances of a collection
+// cycle of an account, for the consumers which don't need every instance
+type AccountSummary struct {
+	Provider Provider
+	Account  string
+	Window   Window
+
+	// The instances collected, the ones whose emissions were calculated and
+	// the ones which failed. The instances counted by another collector are
+	// neither calculated nor failed
+	Instances  int
+	Calculated int
+	Failed     int
+
+	// The energy drawn by the instances in kWh, including the overhead of
+	// the data center
+	Energy float64
+
+	// The operational and embodied emissions in gCO2eq
+	Operational float64
+	Embodied    float64
+
+	// The error of the collection, empty when it succeeded
+	Error     string
+	ErrorCode ErrorCode
+}
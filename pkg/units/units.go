@@ -0,0 +1,82 @@
+// Package units converts the emissions, calculated in gCO2e, to the unit and
+// the precision they're output in by the metrics and the API
+package units
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/re-cinq/aether/pkg/config"
+)
+
+// Unit is a unit of mass of CO2 equivalent
+type Unit string
+
+// The supported units
+const (
+	Grams     Unit = "gCO2e"
+	Kilograms Unit = "kgCO2e"
+	Tonnes    Unit = "tCO2e"
+)
+
+// grams is the amount of grams of every unit
+var grams = map[Unit]float64{
+	Grams:     1,
+	Kilograms: 1e3,
+	Tonnes:    1e6,
+}
+
+// The most decimal places the emissions can be rounded to, a float64 has
+// about 15 significant digits
+const maxPrecision = 15
+
+// Output is how the emissions are output. The zero value outputs them in
+// gCO2e without rounding them
+type Output struct {
+	unit      Unit
+	precision int
+	round     bool
+}
+
+// New returns the output configured by the config
+func New(cfg *config.OutputConfig) (Output, error) {
+	o := Output{
+		unit: Grams,
+	}
+
+	if cfg.Unit != "" {
+		if _, ok := grams[Unit(cfg.Unit)]; !ok {
+			return o, fmt.Errorf("unknown unit %q, must be one of gCO2e, kgCO2e or tCO2e", cfg.Unit)
+		}
+		o.unit = Unit(cfg.Unit)
+	}
+
+	if cfg.Precision != nil {
+		if *cfg.Precision < 0 || *cfg.Precision > maxPrecision {
+			return o, fmt.Errorf("invalid precision %d, must be between 0 and %d", *cfg.Precision, maxPrecision)
+		}
+		o.precision = *cfg.Precision
+		o.round = true
+	}
+
+	return o, nil
+}
+
+// Unit returns the unit of the output
+func (o Output) Unit() Unit {
+	if o.unit == "" {
+		return Grams
+	}
+	return o.unit
+}
+
+// Convert converts the emissions in gCO2e to the unit and rounds them
+func (o Output) Convert(g float64) float64 {
+	v := g / grams[o.Unit()]
+	if !o.round {
+		return v
+	}
+
+	p := math.Pow10(o.precision)
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,68 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/re-cinq/aether/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	precision := func(p int) *int { return &p }
+
+	tests := []struct {
+		name  string
+		cfg   config.OutputConfig
+		unit  Unit
+		value float64
+		err   bool
+	}{
+		{name: "default", unit: Grams, value: 1234.5678901},
+		{name: "kilograms", cfg: config.OutputConfig{Unit: "kgCO2e"}, unit: Kilograms, value: 1.2345678901},
+		{name: "tonnes rounded", cfg: config.OutputConfig{Unit: "tCO2e", Precision: precision(4)}, unit: Tonnes, value: 0.0012},
+		{name: "rounded to integers", cfg: config.OutputConfig{Precision: precision(0)}, unit: Grams, value: 1235},
+		{name: "unknown unit", cfg: config.OutputConfig{Unit: "lbCO2e"}, err: true},
+		{name: "negative precision", cfg: config.OutputConfig{Precision: precision(-1)}, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			o, err := New(&test.cfg)
+			if test.err {
+				assert.Error(err)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Equal(test.unit, o.Unit())
+			assert.InDelta(test.value, o.Convert(1234.5678901), 1e-12)
+		})
+	}
+
+	// the zero value outputs the emissions as calculated
+	var o Output
+	assert := require.New(t)
+	assert.Equal(Grams, o.Unit())
+	assert.Equal(0.1+0.2, o.Convert(0.1+0.2))
+
+	// the rounding removes the float noise
+	o, err := New(&config.OutputConfig{Precision: precision(6)})
+	assert.NoError(err)
+	assert.Equal(0.3, o.Convert(0.1+0.2))
+}
+
+func TestOverride(t *testing.T) {
+	assert := require.New(t)
+
+	six, two := 6, 2
+	cfg := config.UnitsConfig{
+		OutputConfig: config.OutputConfig{Unit: "kgCO2e", Precision: &six},
+		Metrics:      config.OutputConfig{Unit: "gCO2e"},
+		API:          config.OutputConfig{Precision: &two},
+	}
+
+	assert.Equal(config.OutputConfig{Unit: "gCO2e", Precision: &six}, cfg.MetricsOutput())
+	assert.Equal(config.OutputConfig{Unit: "kgCO2e", Precision: &two}, cfg.APIOutput())
+}